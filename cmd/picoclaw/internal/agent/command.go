@@ -22,7 +22,7 @@ func NewAgentCommand() *cobra.Command {
 		Short: "Interact with the agent directly",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return agentCmd(message, sessionKey, model, debug, useTUI, webUIAddr, autoOpenWebUI, workflowName, target)
+			return agentCmd(message, sessionKey, model, debug, useTUI, webUIAddr, autoOpenWebUI, workflowName, target, "")
 		},
 	}
 
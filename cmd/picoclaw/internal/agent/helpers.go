@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,11 +15,34 @@ import (
 
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/monitor"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tui"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
-func agentCmd(message, sessionKey, model string, debug, useTUI bool, webUIAddr string, autoOpenWebUI bool, workflowName, target string) error {
+// ResumeMission starts an agent session on the saved mission at statePath.
+// The mission's workflow engine is restored and the session continues the
+// conversation the mission ran in.
+func ResumeMission(statePath, message, model string, debug, useTUI bool) error {
+	return agentCmd(message, "", model, debug, useTUI, "", false, "", "", statePath)
+}
+
+func agentCmd(message, sessionKey, model string, debug, useTUI bool, webUIAddr string, autoOpenWebUI bool, workflowName, target, missionState string) error {
+	if missionState != "" {
+		state, err := workflow.ReadMissionState(missionState)
+		if err != nil {
+			return fmt.Errorf("failed to read mission %s: %w", missionState, err)
+		}
+		workflowName, target = state.WorkflowName, state.Target
+		if sessionKey == "" {
+			sessionKey, _ = state.Metadata[workflow.MetaSessionKey].(string)
+		}
+		if sessionKey == "" {
+			sessionKey = "cli:mission_" + strings.TrimSuffix(filepath.Base(missionState), "_state.json")
+		}
+	}
 	if sessionKey == "" {
 		sessionKey = "cli:default"
 	}
@@ -59,19 +83,37 @@ func agentCmd(message, sessionKey, model string, debug, useTUI bool, webUIAddr s
 			return fmt.Errorf("failed to get default agent for workflow loading")
 		}
 
-		err := defaultAgent.LoadWorkflow(workflowName, target)
-		if err != nil {
-			return fmt.Errorf("failed to load workflow '%s': %w", workflowName, err)
+		if missionState != "" {
+			if err := defaultAgent.LoadExistingMission(workflowName, missionState); err != nil {
+				return fmt.Errorf("failed to resume mission: %w", err)
+			}
+			fmt.Printf("📋 Resumed mission: %s (workflow: %s, phase: %s)\n",
+				target, workflowName, defaultAgent.WorkflowEngine.CurrentPhaseName())
+		} else {
+			if target != "" {
+				if _, err := os.Stat(workflow.MissionStatePath(defaultAgent.Workspace, target)); err == nil {
+					fmt.Printf("⚠ Starting over: the saved mission for %s will be replaced. Use 'picoclaw mission resume --target %s' to continue it instead.\n", target, target)
+				}
+			}
+			err := defaultAgent.LoadWorkflow(workflowName, target)
+			if err != nil {
+				return fmt.Errorf("failed to load workflow '%s': %w", workflowName, err)
+			}
+
+			logger.InfoCF("agent", "Workflow loaded", map[string]any{
+				"workflow": workflowName,
+				"target":   target,
+			})
+			if target != "" {
+				fmt.Printf("📋 Loaded workflow: %s (target: %s)\n", workflowName, target)
+			} else {
+				fmt.Printf("📋 Loaded workflow: %s\n", workflowName)
+			}
 		}
 
-		logger.InfoCF("agent", "Workflow loaded", map[string]any{
-			"workflow": workflowName,
-			"target":   target,
-		})
-		if target != "" {
-			fmt.Printf("📋 Loaded workflow: %s (target: %s)\n", workflowName, target)
-		} else {
-			fmt.Printf("📋 Loaded workflow: %s\n", workflowName)
+		// Remember the session so resuming the mission continues it
+		if err := defaultAgent.WorkflowEngine.SetMetadata(workflow.MetaSessionKey, sessionKey); err != nil {
+			logger.WarnCF("agent", "Failed to save mission session", map[string]any{"error": err.Error()})
 		}
 
 		assessment, assessErr := internal.AssessWorkflowProfileReadiness(workflowName, defaultAgent.Workspace, runtime.ProfileReadiness)
@@ -124,7 +166,7 @@ func agentCmd(message, sessionKey, model string, debug, useTUI bool, webUIAddr s
 		if preflightSummary == nil {
 			preflightSummary = globalPreflight
 		}
-		return tuiMode(agentLoop, sessionKey, runtime.ProfileReadiness, preflightSummary)
+		return tuiMode(agentLoop, sessionKey, runtime.ProfileReadiness, preflightSummary, runtime.Config)
 	}
 
 	// Traditional readline mode
@@ -139,10 +181,13 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt:          prompt,
-		HistoryFile:     filepath.Join(os.TempDir(), ".picoclaw_history"),
+		HistoryFile:     tui.DefaultHistoryPath(),
 		HistoryLimit:    100,
 		InterruptPrompt: "^C",
 		EOFPrompt:       "exit",
+
+		// Saved manually so /set secrets stay out of the history file
+		DisableAutoSaveHistory: true,
 	})
 	if err != nil {
 		fmt.Printf("Error initializing readline: %v\n", err)
@@ -167,6 +212,9 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 		if input == "" {
 			continue
 		}
+		if !tui.IsSensitiveInput(input) {
+			rl.SaveHistory(input)
+		}
 
 		if input == "exit" || input == "quit" {
 			fmt.Println("Goodbye!")
@@ -219,7 +267,7 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 	}
 }
 
-func tuiMode(agentLoop *agent.AgentLoop, sessionKey string, readiness *internal.ProfileReadiness, preflightSummary *internal.PreflightSummary) error {
+func tuiMode(agentLoop *agent.AgentLoop, sessionKey string, readiness *internal.ProfileReadiness, preflightSummary *internal.PreflightSummary, cfg *config.Config) error {
 	// Create TUI program
 	program := tui.NewProgram()
 	if readiness != nil {
@@ -236,8 +284,14 @@ func tuiMode(agentLoop *agent.AgentLoop, sessionKey string, readiness *internal.
 	// Set up input handler with closure
 	var programRef *tui.Program = program
 	handler := func(input string) {
-		// Send user message to chat
-		programRef.Send(tui.SendChatMessage("user", input, ""))
+		// Send user message to chat, hiding /set values
+		shown := input
+		if tui.IsSensitiveInput(input) {
+			if fields := strings.Fields(input); len(fields) >= 2 {
+				shown = fields[0] + " " + fields[1] + " ••••••"
+			}
+		}
+		programRef.Send(tui.SendChatMessage("user", shown, ""))
 
 		// Process with agent
 		ctx := context.Background()
@@ -249,10 +303,32 @@ func tuiMode(agentLoop *agent.AgentLoop, sessionKey string, readiness *internal.
 
 		// Send assistant response
 		programRef.Send(tui.SendChatMessage("assistant", response, ""))
+
+		// Refresh session cost and routing savings
+		if tierRouter := agentLoop.GetTierRouter(); tierRouter != nil {
+			if session := tierRouter.GetCostTracker().GetSessionCost(sessionKey); session != nil {
+				saved, percent := session.Savings()
+				programRef.Send(tui.SendCostSavings(session.TotalCost, saved, percent))
+			}
+		}
 	}
 
-	// Set the handler
-	program.SetInputHandler(handler)
+	// Set the handler. It runs off the UI goroutine: it sends messages back to
+	// the program and may block on operator prompts such as correction reviews.
+	program.SetInputHandler(func(input string) {
+		go handler(input)
+	})
+
+	// Let the operator approve supervisor corrections inline
+	agentLoop.SetCorrectionReviewer(program.ReviewCorrection)
+
+	// Surface ask_operator questions as answer cards
+	agentLoop.SetOperatorAsker(program.AskOperator)
+
+	// Preview screenshots and other image artifacts inline as tools produce them
+	agentLoop.SetToolImageHandler(func(toolName string, paths []string) {
+		programRef.Send(tui.SendToolImages(toolName, paths))
+	})
 
 	// Set up workflow engine if loaded
 	defaultAgent := agentLoop.GetRegistry().GetDefaultAgent()
@@ -263,8 +339,28 @@ func tuiMode(agentLoop *agent.AgentLoop, sessionKey string, readiness *internal.
 	// Set up tier router if enabled
 	if tierRouter := agentLoop.GetTierRouter(); tierRouter != nil {
 		program.SetTierRouter(tierRouter)
+		tierRouter.SetModelSwitchHandler(func(tierName, modelName string) {
+			programRef.Send(tui.SendModelSwitch(modelName, tierName))
+		})
 	}
 
+	// Show continuous monitoring alerts in the chat
+	if cfg != nil && cfg.Monitor.Enabled {
+		inventoryMonitor := monitor.New(cfg, nil)
+		inventoryMonitor.SetOnAlert(func(alert monitor.Alert) {
+			programRef.Send(tui.SendChatMessage("system", alert.Summary(), ""))
+		})
+		if err := inventoryMonitor.Start(context.Background()); err != nil {
+			program.AddSystemMessage(fmt.Sprintf("Continuous monitoring not started: %v", err))
+		} else {
+			defer inventoryMonitor.Stop()
+		}
+	}
+
+	// Route log output to the logs pane while the TUI owns the screen
+	log.SetOutput(program.LogWriter())
+	defer log.SetOutput(os.Stderr)
+
 	// Run TUI
 	return program.Run()
 }
@@ -14,6 +14,7 @@ func NewConfigCommand() *cobra.Command {
 	cmd.AddCommand(newTestCommand())
 	cmd.AddCommand(newModelsCommand())
 	cmd.AddCommand(newDiscoverCommand())
+	cmd.AddCommand(newStatusCommand())
 
 	return cmd
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -34,18 +35,30 @@ Supported providers:
   - lmstudio: Local LM Studio instance
   - openrouter: OpenRouter API
   - anthropic: Anthropic API
+  - groq: Groq API (GROQ_API_KEY)
+  - together: Together.ai API (TOGETHER_API_KEY)
+  - xai: xAI API (XAI_API_KEY)
+  - mistral: Mistral La Plateforme (MISTRAL_API_KEY)
+  - deepseek: DeepSeek API (DEEPSEEK_API_KEY)
+
+Models added interactively get their context window from the provider, and
+routing tiers that use them get the discovered pricing as cost_per_m. For
+xAI, Mistral and DeepSeek the context windows and prices come from picoclaw's
+built-in provider presets.
 
 Examples:
   picoclaw config discover --provider lmstudio    # List LM Studio models
   picoclaw config discover --provider openrouter  # List OpenRouter models
   picoclaw config discover --provider anthropic   # List Anthropic models
+  picoclaw config discover --provider groq        # List Groq models
+  picoclaw config discover --provider xai         # List xAI Grok models
   picoclaw config discover --interactive          # Discover all and select interactively`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return discoverCmd(provider, interactive, outputConfig)
 		},
 	}
 
-	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider to query (lmstudio, openrouter, anthropic)")
+	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider to query (lmstudio, openrouter, anthropic, groq, together, xai, mistral, deepseek)")
 	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive mode to select models")
 	cmd.Flags().StringVarP(&outputConfig, "output", "o", "", "Output updated config to file (default: update config.json)")
 
@@ -66,6 +79,7 @@ type DiscoveredModel struct {
 	Pricing     *ModelPricing
 }
 
+// ModelPricing is in USD per million tokens
 type ModelPricing struct {
 	Prompt     float64
 	Completion float64
@@ -91,7 +105,7 @@ func discoverCmd(provider string, interactive bool, outputConfig string) error {
 		})
 	} else {
 		// Discover from all available providers
-		for _, providerName := range []string{"lmstudio", "openrouter", "anthropic"} {
+		for _, providerName := range []string{"lmstudio", "openrouter", "anthropic", "groq", "together", "xai", "mistral", "deepseek"} {
 			models, err := discoverProvider(cfg, providerName)
 			results = append(results, ProviderModels{
 				Provider: providerName,
@@ -121,6 +135,12 @@ func discoverProvider(cfg *pkgconfig.Config, provider string) ([]DiscoveredModel
 		return discoverOpenRouter(cfg)
 	case "anthropic":
 		return discoverAnthropic(cfg)
+	case "groq":
+		return discoverGroq(cfg)
+	case "together":
+		return discoverTogether(cfg)
+	case "xai", "mistral", "deepseek":
+		return discoverPreset(cfg, strings.ToLower(provider))
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -248,17 +268,232 @@ func discoverOpenRouter(cfg *pkgconfig.Config) ([]DiscoveredModel, error) {
 
 	models := make([]DiscoveredModel, 0, len(result.Data))
 	for _, m := range result.Data {
-		models = append(models, DiscoveredModel{
+		model := DiscoveredModel{
 			ID:          m.ID,
 			Name:        m.Name,
 			Description: m.Description,
 			Context:     m.Context,
-		})
+		}
+		// OpenRouter prices are USD per token, as strings
+		prompt, promptErr := strconv.ParseFloat(m.Pricing.Prompt, 64)
+		completion, completionErr := strconv.ParseFloat(m.Pricing.Completion, 64)
+		if promptErr == nil && completionErr == nil && prompt >= 0 && completion >= 0 {
+			model.Pricing = &ModelPricing{Prompt: prompt * 1_000_000, Completion: completion * 1_000_000}
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// groqPricing lists Groq's published on-demand prices (USD per million
+// tokens); the models API reports context windows but not prices.
+var groqPricing = map[string]ModelPricing{
+	"llama-3.1-8b-instant":                          {Prompt: 0.05, Completion: 0.08},
+	"llama-3.3-70b-versatile":                       {Prompt: 0.59, Completion: 0.79},
+	"meta-llama/llama-4-scout-17b-16e-instruct":     {Prompt: 0.11, Completion: 0.34},
+	"meta-llama/llama-4-maverick-17b-128e-instruct": {Prompt: 0.20, Completion: 0.60},
+	"openai/gpt-oss-20b":                            {Prompt: 0.10, Completion: 0.50},
+	"openai/gpt-oss-120b":                           {Prompt: 0.15, Completion: 0.75},
+	"qwen/qwen3-32b":                                {Prompt: 0.29, Completion: 0.59},
+	"moonshotai/kimi-k2-instruct":                   {Prompt: 1.00, Completion: 3.00},
+	"deepseek-r1-distill-llama-70b":                 {Prompt: 0.75, Completion: 0.99},
+}
+
+func discoverGroq(cfg *pkgconfig.Config) ([]DiscoveredModel, error) {
+	apiKey := discoverAPIKey(cfg, "GROQ_API_KEY", "groq")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GROQ_API_KEY not found in environment or config")
+	}
+
+	body, err := fetchModels("Groq", "https://api.groq.com/openai/v1/models", apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ID            string `json:"id"`
+			OwnedBy       string `json:"owned_by"`
+			Active        bool   `json:"active"`
+			ContextWindow int    `json:"context_window"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	models := make([]DiscoveredModel, 0, len(result.Data))
+	for _, m := range result.Data {
+		if !m.Active || strings.Contains(m.ID, "whisper") || strings.Contains(m.ID, "tts") {
+			continue // Speech models can't serve chat requests
+		}
+		model := DiscoveredModel{
+			ID:          m.ID,
+			Name:        m.ID,
+			Description: m.OwnedBy,
+			Context:     m.ContextWindow,
+		}
+		if pricing, ok := groqPricing[m.ID]; ok {
+			model.Pricing = &pricing
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+func discoverTogether(cfg *pkgconfig.Config) ([]DiscoveredModel, error) {
+	apiKey := discoverAPIKey(cfg, "TOGETHER_API_KEY", "together")
+	if apiKey == "" {
+		return nil, fmt.Errorf("TOGETHER_API_KEY not found in environment or config")
+	}
+
+	body, err := fetchModels("Together", "https://api.together.xyz/v1/models", apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Together returns a bare array with prices in USD per million tokens
+	var result []struct {
+		ID            string `json:"id"`
+		Type          string `json:"type"`
+		DisplayName   string `json:"display_name"`
+		Organization  string `json:"organization"`
+		ContextLength int    `json:"context_length"`
+		Pricing       *struct {
+			Input  float64 `json:"input"`
+			Output float64 `json:"output"`
+		} `json:"pricing"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	models := make([]DiscoveredModel, 0, len(result))
+	for _, m := range result {
+		if m.Type != "chat" && m.Type != "language" && m.Type != "code" {
+			continue // Embedding, image, audio and rerank models
+		}
+		model := DiscoveredModel{
+			ID:          m.ID,
+			Name:        m.DisplayName,
+			Description: m.Organization,
+			Context:     m.ContextLength,
+		}
+		if m.Pricing != nil && (m.Pricing.Input > 0 || m.Pricing.Output > 0) {
+			model.Pricing = &ModelPricing{Prompt: m.Pricing.Input, Completion: m.Pricing.Output}
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// discoverPreset lists the chat models of a provider with a built-in preset.
+// The models endpoints don't report prices, so context windows and prices
+// come from the preset; models it doesn't know get Mistral's reported context
+// length, or none.
+func discoverPreset(cfg *pkgconfig.Config, protocol string) ([]DiscoveredModel, error) {
+	preset, ok := pkgconfig.LookupProviderPreset(protocol)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", protocol)
+	}
+	apiKey := discoverAPIKey(cfg, preset.APIKeyEnv, protocol)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s not found in environment or config", preset.APIKeyEnv)
+	}
+
+	body, err := fetchModels(preset.Name, preset.APIBase+"/models", apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return presetModels(preset, body)
+}
+
+func presetModels(preset pkgconfig.ProviderPreset, body []byte) ([]DiscoveredModel, error) {
+	var result struct {
+		Data []struct {
+			ID               string `json:"id"`
+			OwnedBy          string `json:"owned_by"`
+			Description      string `json:"description"`
+			MaxContextLength int    `json:"max_context_length"` // Mistral
+			Capabilities     *struct {
+				CompletionChat bool `json:"completion_chat"`
+			} `json:"capabilities"` // Mistral
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	models := make([]DiscoveredModel, 0, len(result.Data))
+	for _, m := range result.Data {
+		if m.Capabilities != nil && !m.Capabilities.CompletionChat {
+			continue // Embedding, OCR and moderation models
+		}
+		if strings.Contains(m.ID, "image") {
+			continue // xAI image generation
+		}
+		model := DiscoveredModel{
+			ID:          m.ID,
+			Name:        m.ID,
+			Description: m.Description,
+			Context:     m.MaxContextLength,
+		}
+		if model.Description == "" {
+			model.Description = m.OwnedBy
+		}
+		if pm, ok := preset.Model(m.ID); ok {
+			model.Context = pm.ContextWindow
+			model.Pricing = &ModelPricing{Prompt: pm.Pricing.Input, Completion: pm.Pricing.Output}
+		}
+		models = append(models, model)
 	}
 
 	return models, nil
 }
 
+// discoverAPIKey returns the key from envVar, or the key of a configured
+// model whose API base or model contains hint.
+func discoverAPIKey(cfg *pkgconfig.Config, envVar, hint string) string {
+	if apiKey := os.Getenv(envVar); apiKey != "" {
+		return apiKey
+	}
+	for _, m := range cfg.ModelList {
+		if m.APIKey == "" {
+			continue
+		}
+		if strings.Contains(strings.ToLower(m.APIBase), hint) || strings.HasPrefix(m.Model, hint+"/") {
+			return m.APIKey
+		}
+	}
+	return ""
+}
+
+// fetchModels GETs an OpenAI-style models endpoint with a bearer key.
+func fetchModels(providerName, url, apiKey string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s returned status %d", providerName, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 func discoverAnthropic(cfg *pkgconfig.Config) ([]DiscoveredModel, error) {
 	// Anthropic doesn't have a models list API, so return known models
 	return []DiscoveredModel{
@@ -462,6 +697,10 @@ func interactiveSelection(cfg *pkgconfig.Config, results []ProviderModels, outpu
 			cfg.ModelList = append(cfg.ModelList, modelConfig)
 			fmt.Printf("➕ Added: %s\n", modelConfig.ModelName)
 		}
+
+		for _, tierName := range applyTierPricing(cfg, modelConfig.ModelName, item.Model.Pricing) {
+			fmt.Printf("💲 Tier %s: $%.2f/$%.2f per M tokens\n", tierName, item.Model.Pricing.Prompt, item.Model.Pricing.Completion)
+		}
 	}
 
 	// Save config
@@ -488,10 +727,32 @@ func interactiveSelection(cfg *pkgconfig.Config, results []ProviderModels, outpu
 	return nil
 }
 
+// applyTierPricing sets cost_per_m on the routing tiers that use modelName
+// and have no pricing yet, returning their names. Prices set by hand are
+// kept.
+func applyTierPricing(cfg *pkgconfig.Config, modelName string, pricing *ModelPricing) []string {
+	if pricing == nil {
+		return nil
+	}
+	var updated []string
+	for name, tier := range cfg.Routing.Tiers {
+		if tier.ModelName != modelName || tier.CostPerM.Input != 0 || tier.CostPerM.Output != 0 {
+			continue
+		}
+		tier.CostPerM.Input = pricing.Prompt
+		tier.CostPerM.Output = pricing.Completion
+		cfg.Routing.Tiers[name] = tier
+		updated = append(updated, name)
+	}
+	sort.Strings(updated)
+	return updated
+}
+
 func createModelConfig(provider string, model DiscoveredModel) pkgconfig.ModelConfig {
 	config := pkgconfig.ModelConfig{
-		ModelName: sanitizeModelName(model.ID),
-		Model:     model.ID,
+		ModelName:     sanitizeModelName(model.ID),
+		Model:         model.ID,
+		ContextWindow: model.Context,
 	}
 
 	switch strings.ToLower(provider) {
@@ -510,6 +771,20 @@ func createModelConfig(provider string, model DiscoveredModel) pkgconfig.ModelCo
 	case "anthropic":
 		// Anthropic uses default settings, just need API key
 		config.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+
+	case "groq":
+		config.Model = "groq/" + model.ID
+		config.APIKey = os.Getenv("GROQ_API_KEY")
+
+	case "together":
+		config.Model = "together/" + model.ID
+		config.APIKey = os.Getenv("TOGETHER_API_KEY")
+
+	case "xai", "mistral", "deepseek":
+		// The API base and output limit field come from the preset
+		preset, _ := pkgconfig.LookupProviderPreset(provider)
+		config.Model = preset.Protocol + "/" + model.ID
+		config.APIKey = os.Getenv(preset.APIKeyEnv)
 	}
 
 	return config
@@ -135,6 +135,11 @@ func detectProvider(m pkgconfig.ModelConfig) string {
 		if strings.Contains(apiBase, "openai") {
 			return "OpenAI"
 		}
+		for _, preset := range pkgconfig.ProviderPresets() {
+			if strings.HasPrefix(apiBase, preset.APIBase) {
+				return preset.Name
+			}
+		}
 		return "Custom API"
 	}
 
@@ -149,6 +154,11 @@ func detectProvider(m pkgconfig.ModelConfig) string {
 	if strings.Contains(modelID, "openrouter") {
 		return "OpenRouter"
 	}
+	if protocol, _, found := strings.Cut(modelID, "/"); found {
+		if preset, ok := pkgconfig.LookupProviderPreset(protocol); ok {
+			return preset.Name
+		}
+	}
 	if strings.Contains(modelID, "gemini") || strings.Contains(modelID, "google") {
 		return "Google"
 	}
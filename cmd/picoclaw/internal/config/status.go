@@ -0,0 +1,109 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/health"
+)
+
+func newStatusCommand() *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show provider health",
+		Long: `Show the latest provider health check results.
+
+The gateway checks every configured model in the background when
+provider_health.enabled is set. Use --check to run the checks now.
+
+Examples:
+  picoclaw config status            # Show results from the gateway
+  picoclaw config status --check    # Check all providers now`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return statusCmd(check)
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Check all providers now instead of showing saved results")
+
+	return cmd
+}
+
+func statusCmd(check bool) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(cfg.ModelList) == 0 {
+		return fmt.Errorf("no models configured in config.json")
+	}
+
+	fmt.Print("🩺 Provider Health\n\n")
+
+	var statuses []health.ProviderStatus
+	if check {
+		checker := health.NewProviderChecker(cfg, nil)
+		defer checker.Stop()
+		fmt.Printf("🔄 Checking %d models...\n\n", len(cfg.ModelList))
+		statuses = checker.CheckAll(context.Background())
+	} else {
+		statuses, err = health.LoadProviderStatuses(cfg.WorkspacePath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if len(statuses) == 0 {
+			fmt.Println("No health checks recorded yet.")
+			fmt.Println("Enable provider_health in config.json and run the gateway, or use --check.")
+			return nil
+		}
+	}
+
+	byModel := make(map[string]health.ProviderStatus, len(statuses))
+	for _, s := range statuses {
+		byModel[s.Model] = s
+	}
+
+	var healthy int
+	for _, modelCfg := range cfg.ModelList {
+		s, ok := byModel[modelCfg.ModelName]
+		if !ok {
+			fmt.Printf("❔ %s\n", modelCfg.ModelName)
+			fmt.Printf("  Status: unknown (not checked yet)\n")
+			continue
+		}
+
+		if s.Healthy {
+			healthy++
+			fmt.Printf("✅ %s\n", s.Model)
+		} else {
+			fmt.Printf("❌ %s\n", s.Model)
+		}
+		fmt.Printf("  Provider: %s\n", s.Provider)
+		if s.Method != "" {
+			fmt.Printf("  Check: %s, %dms\n", s.Method, s.LatencyMS)
+		}
+		if !s.Healthy {
+			fmt.Printf("  Error: %s\n", truncate(s.Error, 100))
+			if s.Reason != "" {
+				fmt.Printf("  Reason: %s\n", s.Reason)
+			}
+			if s.Failures > 1 {
+				fmt.Printf("  Consecutive failures: %d\n", s.Failures)
+			}
+		}
+		fmt.Printf("  Checked: %s ago\n", time.Since(s.CheckedAt).Round(time.Second))
+	}
+
+	fmt.Printf("\nSummary: %d/%d models healthy\n", healthy, len(cfg.ModelList))
+	return nil
+}
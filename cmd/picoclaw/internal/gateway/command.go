@@ -13,7 +13,7 @@ func NewGatewayCommand() *cobra.Command {
 		Short:   "Start picoclaw gateway",
 		Args:    cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return gatewayCmd(debug)
+			return gatewayCmd(debug, false, 0)
 		},
 	}
 
@@ -21,3 +21,32 @@ func NewGatewayCommand() *cobra.Command {
 
 	return cmd
 }
+
+// NewServeCommand starts the gateway with the web dashboard, whether or
+// not gateway.dashboard_port is set
+func NewServeCommand() *cobra.Command {
+	var (
+		debug bool
+		port  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start picoclaw gateway with the web dashboard",
+		Long: `Start the gateway and serve a read-only web dashboard of the active mission:
+phase progress, findings by severity, model spend per phase and a live
+event stream. Findings are shown as in the client report, without evidence.
+
+The dashboard listens on gateway.host at --port, gateway.dashboard_port, or
+18791 when neither is set.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return gatewayCmd(debug, true, port)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+	cmd.Flags().IntVarP(&port, "port", "p", 0, "Dashboard port (overrides gateway.dashboard_port)")
+
+	return cmd
+}
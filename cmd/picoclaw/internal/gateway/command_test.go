@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
 )
 
 func TestNewGatewayCommand(t *testing.T) {
@@ -29,3 +31,47 @@ func TestNewGatewayCommand(t *testing.T) {
 	assert.True(t, cmd.HasFlags())
 	assert.NotNil(t, cmd.Flags().Lookup("debug"))
 }
+
+func TestNewServeCommand(t *testing.T) {
+	cmd := NewServeCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "serve", cmd.Use)
+	assert.Equal(t, "Start picoclaw gateway with the web dashboard", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.NotNil(t, cmd.Flags().Lookup("debug"))
+	port := cmd.Flags().Lookup("port")
+	require.NotNil(t, port)
+	assert.Equal(t, "p", port.Shorthand)
+}
+
+func TestListenGRPC_RequiresOperatorOffLoopback(t *testing.T) {
+	_, _, err := listenGRPC(config.GatewayConfig{Host: "0.0.0.0"}, nil, "127.0.0.1:0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not loopback")
+
+	viewer := []config.GatewayUser{{Name: "client", Token: "t", Role: "viewer"}}
+	_, _, err = listenGRPC(config.GatewayConfig{Host: "0.0.0.0", Users: viewer}, nil, "127.0.0.1:0")
+	require.Error(t, err)
+
+	srv, lis, err := listenGRPC(config.GatewayConfig{Host: "127.0.0.1"}, nil, "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.False(t, srv.Authenticated())
+	lis.Close()
+
+	operator := []config.GatewayUser{{Name: "alice", Token: "t", Role: "operator"}}
+	srv, lis, err = listenGRPC(config.GatewayConfig{Host: "0.0.0.0", Users: operator}, nil, "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.True(t, srv.Authenticated())
+	lis.Close()
+
+	_, _, err = listenGRPC(config.GatewayConfig{Host: "127.0.0.1", GRPCTLSCert: "missing.pem", GRPCTLSKey: "missing.key"}, nil, "127.0.0.1:0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TLS certificate")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,23 +12,35 @@ import (
 	"strings"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/channels"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/cron"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/dashboard"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/devices"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/grpcapi"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/health"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/heartbeat"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/monitor"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/state"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/voice"
 )
 
-func gatewayCmd(debug bool) error {
+// defaultDashboardPort is where picoclaw serve puts the dashboard when the
+// config doesn't name a port
+const defaultDashboardPort = 18791
+
+// gatewayCmd runs the gateway until interrupted. The dashboard starts when
+// gateway.dashboard_port or dashboardPort is set, or always with serve.
+func gatewayCmd(debug, serve bool, dashboardPort int) error {
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 		fmt.Println("🔍 Debug mode enabled")
@@ -184,6 +197,24 @@ func gatewayCmd(debug bool) error {
 		fmt.Println("✓ Device event service started")
 	}
 
+	providerChecker := health.NewProviderChecker(cfg, stateManager)
+	providerChecker.SetBus(msgBus)
+	providerChecker.SetCooldown(agentLoop.ProviderCooldown())
+	if err := providerChecker.Start(ctx); err != nil {
+		fmt.Printf("Error starting provider health checks: %v\n", err)
+	} else if cfg.ProviderHealth.Enabled {
+		fmt.Println("✓ Provider health checks started")
+	}
+
+	inventoryMonitor := monitor.New(cfg, stateManager)
+	inventoryMonitor.SetBus(msgBus)
+	inventoryMonitor.SetProvider(provider, modelID)
+	if err := inventoryMonitor.Start(ctx); err != nil {
+		fmt.Printf("Error starting continuous monitoring: %v\n", err)
+	} else if cfg.Monitor.Enabled {
+		fmt.Println("✓ Continuous monitoring started")
+	}
+
 	if err := channelManager.StartAll(ctx); err != nil {
 		fmt.Printf("Error starting channels: %v\n", err)
 	}
@@ -196,6 +227,56 @@ func gatewayCmd(debug bool) error {
 	}()
 	fmt.Printf("✓ Health endpoints available at http://%s:%d/health and /ready\n", cfg.Gateway.Host, cfg.Gateway.Port)
 
+	var grpcServer *grpcapi.Server
+	if cfg.Gateway.GRPCPort > 0 {
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.Gateway.Host, cfg.Gateway.GRPCPort)
+		srv, lis, err := listenGRPC(cfg.Gateway, agentLoop, grpcAddr)
+		if err != nil {
+			fmt.Printf("Error starting gRPC API: %v\n", err)
+		} else {
+			grpcServer = srv
+			go func() {
+				if err := grpcServer.Serve(lis); err != nil {
+					logger.ErrorCF("grpcapi", "gRPC server error", map[string]any{"error": err.Error()})
+				}
+			}()
+			fmt.Printf("✓ gRPC API available at %s\n", grpcAddr)
+			if cfg.Gateway.GRPCTLSCert == "" && !grpcapi.IsLoopback(cfg.Gateway.Host) {
+				fmt.Println("  No gateway.grpc_tls_cert configured: tokens and traffic cross the network in plaintext")
+			}
+		}
+	}
+
+	if dashboardPort == 0 {
+		dashboardPort = cfg.Gateway.DashboardPort
+	}
+	if serve && dashboardPort == 0 {
+		dashboardPort = defaultDashboardPort
+	}
+	var dashboardServer *dashboard.Server
+	if dashboardPort > 0 {
+		dashboardAddr := fmt.Sprintf("%s:%d", cfg.Gateway.Host, dashboardPort)
+		srv, err := dashboard.NewServer(agentLoop, cfg.Gateway.Users)
+		var lis net.Listener
+		if err == nil {
+			lis, err = net.Listen("tcp", dashboardAddr)
+		}
+		if err != nil {
+			fmt.Printf("Error starting dashboard: %v\n", err)
+		} else {
+			dashboardServer = srv
+			go func() {
+				if err := dashboardServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.ErrorCF("dashboard", "Dashboard server error", map[string]any{"error": err.Error()})
+				}
+			}()
+			fmt.Printf("✓ Dashboard available at http://%s\n", dashboardAddr)
+			if len(cfg.Gateway.Users) == 0 {
+				fmt.Println("  No gateway.users configured: anyone who can reach it sees the mission")
+			}
+		}
+	}
+
 	go agentLoop.Run(ctx)
 
 	sigChan := make(chan os.Signal, 1)
@@ -208,6 +289,14 @@ func gatewayCmd(debug bool) error {
 	}
 	cancel()
 	healthServer.Stop(context.Background())
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+	if dashboardServer != nil {
+		dashboardServer.Stop(context.Background())
+	}
+	inventoryMonitor.Stop()
+	providerChecker.Stop()
 	deviceService.Stop()
 	heartbeatService.Stop()
 	cronService.Stop()
@@ -243,3 +332,26 @@ func setupCronTool(
 
 	return cronService
 }
+
+// listenGRPC sets up the gRPC API on addr. It refuses a host other than
+// loopback unless a gateway user with the operator role holds a token: the
+// API runs agent turns, so anyone who can reach it can drive the agent.
+func listenGRPC(gw config.GatewayConfig, agentLoop *agent.AgentLoop, addr string) (*grpcapi.Server, net.Listener, error) {
+	var opts []grpc.ServerOption
+	if gw.GRPCTLSCert != "" || gw.GRPCTLSKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(gw.GRPCTLSCert, gw.GRPCTLSKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading the gRPC TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpcapi.NewServer(agentLoop, gw.Users, opts...)
+	if !srv.Authenticated() && !grpcapi.IsLoopback(gw.Host) {
+		return nil, nil, fmt.Errorf("gateway.host %q is not loopback and no gateway user has the %s role; add one with a token to expose the gRPC API", gw.Host, grpcapi.OperatorRole)
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return srv, lis, nil
+}
@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
 )
 
 const Logo = "🦞"
@@ -24,7 +25,14 @@ func GetConfigPath() string {
 }
 
 func LoadConfig() (*config.Config, error) {
-	return config.LoadConfig(GetConfigPath())
+	cfg, err := config.LoadConfig(GetConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Determinism.Enabled {
+		determinism.Enable(cfg.Determinism.Seed)
+	}
+	return cfg, nil
 }
 
 // FormatVersion returns the version string with optional git commit
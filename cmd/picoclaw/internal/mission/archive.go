@@ -0,0 +1,54 @@
+package mission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newArchiveCommand() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move a finished mission out of the way",
+		Long: `Move a saved mission, and its working directory, to missions/archive.
+Archived missions no longer appear in 'picoclaw mission list' or as the most
+recently saved mission, and a new mission can be started on the same target.
+List them with 'picoclaw mission list --archived'.`,
+		Example: `  picoclaw mission archive --target 10.0.0.0/24`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if target == "" {
+				return fmt.Errorf("--target is required")
+			}
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			path, err := archiveMission(cfg.WorkspacePath(), target)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Mission for %s archived to %s\n", target, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target")
+
+	return cmd
+}
+
+// archiveMission archives the saved mission for target
+func archiveMission(workspace, target string) (string, error) {
+	statePath, err := missionStatePath(workspace, target)
+	if err != nil {
+		return "", err
+	}
+	return workflow.ArchiveMission(workspace, statePath)
+}
@@ -0,0 +1,25 @@
+package mission
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewMissionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mission",
+		Short: "Work with saved missions",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		newListCommand(),
+		newShowCommand(),
+		newResumeCommand(),
+		newArchiveCommand(),
+		newReportCommand(),
+		newExportCommand(),
+		newRollbackCommand(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,318 @@
+package mission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func TestNewMissionCommand(t *testing.T) {
+	cmd := NewMissionCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "mission", cmd.Use)
+	assert.Equal(t, "Work with saved missions", cmd.Short)
+	assert.True(t, cmd.HasSubCommands())
+
+	report, _, err := cmd.Find([]string{"report"})
+	require.NoError(t, err)
+	assert.Equal(t, "report", report.Use)
+	assert.NotNil(t, report.RunE)
+	for _, name := range []string{"target", "format"} {
+		assert.NotNil(t, report.Flags().Lookup(name), "missing --%s", name)
+	}
+
+	export, _, err := cmd.Find([]string{"export"})
+	require.NoError(t, err)
+	assert.Equal(t, "export", export.Use)
+	for _, name := range []string{"target", "format", "audience", "output"} {
+		assert.NotNil(t, export.Flags().Lookup(name), "missing --%s", name)
+	}
+
+	for sub, flags := range map[string][]string{
+		"list":     {"archived"},
+		"show":     {"target"},
+		"resume":   {"target", "message", "model", "tui", "debug"},
+		"archive":  {"target"},
+		"rollback": {"target"},
+	} {
+		c, _, err := cmd.Find([]string{sub})
+		require.NoError(t, err)
+		assert.Equal(t, sub, c.Name())
+		assert.NotNil(t, c.RunE)
+		for _, name := range flags {
+			assert.NotNil(t, c.Flags().Lookup(name), "%s: missing --%s", sub, name)
+		}
+	}
+}
+
+const testWorkflow = `---
+name: network-scan
+phases: [discovery, enumeration]
+---
+
+## Phase: discovery
+
+### Steps
+
+- ping_sweep: Ping sweep (required)
+- port_scan: Port scan (required)
+
+## Phase: enumeration
+
+### Steps
+
+- service_detection: Service detection
+`
+
+func TestListShowAndArchiveMissions(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "workflows"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "workflows", "network-scan.md"), []byte(testWorkflow), 0o644))
+	wf, err := workflow.LoadWorkflow(workspace, "network-scan")
+	require.NoError(t, err)
+
+	engine := workflow.NewEngine(wf, "10.0.0.1", workspace)
+	require.NoError(t, engine.SetMetadata(workflow.MetaSessionKey, "cli:mission_10.0.0.1"))
+	require.NoError(t, engine.MarkStepComplete("ping_sweep"))
+	require.NoError(t, engine.AddFinding("Anonymous FTP", "Login as anonymous succeeds.", workflow.SeverityHigh, "230 Login successful"))
+	require.NoError(t, engine.AddFinding("SSH banner", "OpenSSH version disclosed.", workflow.SeverityInformational, "OpenSSH_8.2p1"))
+	other := workflow.NewEngine(wf, "10.0.0.2", workspace)
+	require.NoError(t, other.SaveState())
+
+	missions, err := workflow.ListMissions(workspace, false)
+	require.NoError(t, err)
+	require.Len(t, missions, 2)
+	var out bytes.Buffer
+	printMissions(&out, missions)
+	assert.Contains(t, out.String(), "TARGET    WORKFLOW      PHASE")
+	assert.Regexp(t, `10\.0\.0\.1 +network-scan +1/2 discovery +2 \(1 high, 1 informational\)`, out.String())
+	assert.Regexp(t, `10\.0\.0\.2 +network-scan +1/2 discovery +0 `, out.String())
+
+	loaded, err := loadMission(workspace, "10.0.0.1")
+	require.NoError(t, err)
+	out.Reset()
+	printMission(&out, workspace, loaded)
+	assert.Contains(t, out.String(), "Mission: 10.0.0.1 (network-scan)\n")
+	assert.Contains(t, out.String(), "Session: cli:mission_10.0.0.1\n")
+	assert.Contains(t, out.String(), "  [x] ping_sweep: Ping sweep (required)\n  [ ] port_scan: Port scan (required)\n")
+	assert.Contains(t, out.String(), "Findings: 2 (1 high, 1 informational)")
+	assert.Regexp(t, `high +- +Anonymous FTP +discovery`, out.String())
+
+	archived, err := archiveMission(workspace, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "missions", "archive"), filepath.Dir(archived))
+	missions, err = workflow.ListMissions(workspace, false)
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, "10.0.0.2", missions[0].Target)
+	missions, err = workflow.ListMissions(workspace, true)
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, "10.0.0.1", missions[0].Target)
+	assert.True(t, missions[0].Archived)
+
+	_, err = archiveMission(workspace, "10.0.0.1")
+	assert.ErrorContains(t, err, "no saved mission for target 10.0.0.1")
+
+	out.Reset()
+	printMissions(&out, nil)
+	assert.Equal(t, "No saved missions.\n", out.String())
+}
+
+func TestGenerateReport(t *testing.T) {
+	workspace := t.TempDir()
+	wf := &workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{
+		{Name: "discovery", Steps: []workflow.Step{{ID: "ping", Name: "Ping sweep", Required: true}}},
+		{Name: "enumeration"},
+	}}
+	engine := workflow.NewEngine(wf, "10.0.0.1", workspace)
+	require.NoError(t, engine.MarkStepComplete("ping"))
+	require.NoError(t, engine.AddFinding("Reflected XSS", "The q parameter is echoed unescaped.",
+		workflow.SeverityHigh, "GET /?q=<script>alert(1)</script>"))
+	require.NoError(t, engine.RecordCost(0.42, 1200))
+	require.NoError(t, engine.AdvancePhase())
+
+	internalPath, clientPath, err := generateReport(workspace, "", workflow.ReportMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "reports", "10.0.0.1_internal.md"), internalPath)
+
+	internalReport := readFile(t, internalPath)
+	assert.Contains(t, internalReport, "## Phases\n\n| Phase | Started | Ended | Steps completed |")
+	assert.Contains(t, internalReport, "| enumeration |")
+	assert.Contains(t, internalReport, "## Timeline")
+	assert.Contains(t, internalReport, "| Finding | [high] Reflected XSS |")
+	assert.Contains(t, internalReport, "## Model Cost")
+	assert.Contains(t, internalReport, "$0.42")
+
+	clientReport := readFile(t, clientPath)
+	assert.Contains(t, clientReport, "## Timeline")
+	assert.NotContains(t, clientReport, "$0.42", "the client report leaves out what the mission cost")
+
+	internalPath, _, err = generateReport(workspace, "10.0.0.1", workflow.ReportHTML)
+	require.NoError(t, err)
+	assert.Equal(t, ".html", filepath.Ext(internalPath))
+	page := readFile(t, internalPath)
+	assert.Contains(t, page, `<html lang="en">`)
+	assert.Contains(t, page, "<h2>Timeline</h2>")
+	assert.Contains(t, page, "<table>")
+	assert.Contains(t, page, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, page, "<script>")
+
+	_, _, err = generateReport(workspace, "10.9.9.9", workflow.ReportMarkdown)
+	assert.ErrorContains(t, err, "no saved mission for target 10.9.9.9")
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExportFindings(t *testing.T) {
+	workspace := t.TempDir()
+	wf := &workflow.Workflow{Name: "web-app", Phases: []workflow.Phase{{Name: "testing"}}}
+	engine := workflow.NewEngine(wf, "https://app.example.com", workspace)
+	_, err := engine.RecordFinding(workflow.Finding{
+		Title: "Reflected XSS in search", Evidence: "GET /?q=<svg/onload=alert(1)>",
+		CWE: "CWE-79", CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N",
+	})
+	require.NoError(t, err)
+	_, err = engine.RecordFinding(workflow.Finding{
+		Title: "Stored XSS in comments", Severity: workflow.SeverityHigh, CWE: "CWE-79", CVSSScore: 8.2,
+		Redaction: workflow.RedactionPartial, Evidence: "<script>fetch('//evil')</script>",
+		Asset: "https://app.example.com/comments",
+	})
+	require.NoError(t, err)
+	require.NoError(t, engine.AddFinding("Server banner", "nginx version disclosed.", workflow.SeverityInformational, "nginx/1.18.0"))
+
+	opts := workflow.ExportOptions{Audience: workflow.ReportInternal, ToolVersion: "1.2.3"}
+	path, err := exportFindings(workspace, "", "", workflow.ExportSARIF, opts)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "reports", "https___app.example.com_findings.sarif"), path)
+
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Version string `json:"version"`
+					Rules   []struct {
+						ID         string         `json:"id"`
+						HelpURI    string         `json:"helpUri"`
+						Properties map[string]any `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID              string            `json:"ruleId"`
+				RuleIndex           int               `json:"ruleIndex"`
+				Level               string            `json:"level"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+				Locations           []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, path)), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	run := sarif.Runs[0]
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	xss := run.Tool.Driver.Rules[0]
+	assert.Equal(t, "CWE-79", xss.ID)
+	assert.Equal(t, "https://cwe.mitre.org/data/definitions/79.html", xss.HelpURI)
+	assert.Equal(t, "8.2", xss.Properties["security-severity"], "a rule is as severe as its worst finding")
+	assert.Contains(t, xss.Properties["tags"], "external/cwe/cwe-79")
+	assert.Equal(t, "picoclaw/server-banner", run.Tool.Driver.Rules[1].ID)
+	assert.NotContains(t, run.Tool.Driver.Rules[1].Properties, "security-severity")
+
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "CWE-79", run.Results[1].RuleID)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "warning", run.Results[1].Level, "rated medium from its CVSS vector")
+	assert.Equal(t, "note", run.Results[2].Level)
+	assert.Equal(t, 1, run.Results[2].RuleIndex)
+	assert.Equal(t, "https://app.example.com/comments", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "https://app.example.com", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.NotEqual(t, run.Results[0].PartialFingerprints, run.Results[1].PartialFingerprints)
+
+	out := filepath.Join(t.TempDir(), "findings.json")
+	opts.Audience = workflow.ReportClient
+	path, err = exportFindings(workspace, "https://app.example.com", out, workflow.ExportJSON, opts)
+	require.NoError(t, err)
+	assert.Equal(t, out, path)
+
+	var export workflow.FindingsExport
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, out)), &export))
+	assert.Equal(t, workflow.FindingsSchema, export.Schema)
+	assert.Equal(t, "web-app", export.Mission.Workflow)
+	assert.Equal(t, workflow.ReportClient, export.Mission.Audience)
+	require.Len(t, export.Findings, 3)
+	assert.Equal(t, "Stored XSS in comments", export.Findings[0].Title, "highest CVSS score first")
+	assert.Empty(t, export.Findings[0].Evidence, "client exports withhold redacted evidence")
+	assert.Equal(t, "CWE-79", export.Findings[1].CWE)
+	assert.Equal(t, 6.1, export.Findings[1].CVSSScore)
+}
+
+func TestRollbackMission(t *testing.T) {
+	workspace := t.TempDir()
+	wf := &workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{
+		{Name: "discovery", Steps: []workflow.Step{{ID: "ping", Name: "Ping sweep", Required: true}}},
+		{Name: "enumeration"},
+	}}
+	engine := workflow.NewEngine(wf, "10.0.0.1", workspace)
+	engine.SetKeepRevisions(3)
+	require.NoError(t, engine.MarkStepComplete("ping"))
+	require.NoError(t, engine.AddFinding("Anonymous FTP", "Login as anonymous succeeds.", workflow.SeverityHigh, "230 Login successful"))
+	beforeAdvance := engine.GetState().Revision
+	require.NoError(t, engine.AdvancePhase())
+	require.Equal(t, "enumeration", engine.CurrentPhaseName())
+
+	revisions, err := engine.Revisions()
+	require.NoError(t, err)
+	require.Len(t, revisions, 3, "only the last three revisions are kept")
+	assert.Equal(t, engine.GetState().Revision, revisions[0].Revision, "newest first")
+	assert.Equal(t, "enumeration", revisions[0].Phase)
+	assert.Equal(t, workflow.Revision{Revision: beforeAdvance, SavedAt: revisions[1].SavedAt, Phase: "discovery", Steps: 1, Findings: 1}, revisions[1])
+
+	var out bytes.Buffer
+	printRevisions(&out, revisions)
+	assert.Contains(t, out.String(), "REVISION  SAVED")
+	assert.Regexp(t, fmt.Sprintf(`(?m)^%d +\S+ \S+ +discovery +1 +1 +0$`, beforeAdvance), out.String())
+
+	require.NoError(t, engine.Rollback(beforeAdvance))
+	state := engine.GetState()
+	assert.Equal(t, "discovery", engine.CurrentPhaseName())
+	assert.Equal(t, revisions[0].Revision+1, state.Revision, "a rollback is a new revision")
+	assert.Contains(t, state.Journal[len(state.Journal)-1].Text, fmt.Sprintf("to revision %d", beforeAdvance))
+
+	// The saved state is the restored one
+	loaded, err := loadMission(workspace, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded.GetState().CurrentPhase)
+	assert.Equal(t, state.Revision, loaded.GetState().Revision)
+
+	assert.ErrorContains(t, engine.Rollback(1), "revision 1 is no longer kept")
+	assert.ErrorContains(t, engine.Rollback(state.Revision), "is not before the current revision")
+
+	out.Reset()
+	printRevisions(&out, nil)
+	assert.Equal(t, "No revisions kept.\n", out.String())
+}
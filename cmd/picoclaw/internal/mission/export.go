@@ -0,0 +1,94 @@
+package mission
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newExportCommand() *cobra.Command {
+	var (
+		target   string
+		format   string
+		audience string
+		output   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a saved mission's findings as SARIF or JSON",
+		Long: `Export a saved mission's findings for vulnerability management tools.
+
+SARIF 2.1.0 can be uploaded to GitHub code scanning or imported into
+DefectDojo. Findings are grouped into rules by CWE and ranked by their CVSS
+score. JSON uses picoclaw's own schema (picoclaw-findings/v1), which only
+gains fields between versions.
+
+Client exports honor each finding's redaction level, like client reports.`,
+		Example: `  picoclaw mission export
+  picoclaw mission export --target 10.0.0.0/24 --format json
+  picoclaw mission export --audience client --output - > findings.sarif`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			exportFormat, err := workflow.ParseExportFormat(format)
+			if err != nil {
+				return err
+			}
+			opts := workflow.ExportOptions{Audience: workflow.ReportAudience(audience), ToolVersion: internal.GetVersion()}
+			if opts.Audience != workflow.ReportInternal && opts.Audience != workflow.ReportClient {
+				return fmt.Errorf("unknown audience %q (want internal or client)", audience)
+			}
+			cmd.SilenceUsage = true
+
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			path, err := exportFindings(cfg.WorkspacePath(), target, output, exportFormat, opts)
+			if err != nil {
+				return err
+			}
+			if path != "" {
+				fmt.Printf("Findings exported to %s\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+	cmd.Flags().StringVarP(&format, "format", "f", "sarif", "Export format: sarif or json")
+	cmd.Flags().StringVar(&audience, "audience", "internal", "internal, or client to honor redaction levels")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write, or - for stdout (default: the workspace's reports directory)")
+
+	return cmd
+}
+
+// exportFindings exports the findings of the saved mission for target to
+// output, stdout for "-", or the reports directory, and returns the path
+// written, if any
+func exportFindings(workspace, target, output string, format workflow.ExportFormat, opts workflow.ExportOptions) (string, error) {
+	engine, err := loadMission(workspace, target)
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		return engine.ExportFindings(format, opts)
+	}
+
+	data, err := workflow.ExportFindings(engine.GetState(), format, opts)
+	if err != nil {
+		return "", err
+	}
+	if output == "-" {
+		_, err := os.Stdout.Write(data)
+		return "", err
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write findings: %w", err)
+	}
+	return output, nil
+}
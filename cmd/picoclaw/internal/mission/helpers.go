@@ -0,0 +1,48 @@
+package mission
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// loadMission loads the saved mission for target, or the most recently
+// saved one
+func loadMission(workspace, target string) (*workflow.Engine, error) {
+	statePath, err := missionStatePath(workspace, target)
+	if err != nil {
+		return nil, err
+	}
+	state, err := workflow.ReadMissionState(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", statePath, err)
+	}
+
+	// Reports and exports only need the workflow for its name and step
+	// counts, so a mission whose workflow file is gone still loads
+	wf, err := workflow.LoadWorkflow(workspace, state.WorkflowName)
+	if err != nil {
+		wf = &workflow.Workflow{Name: state.WorkflowName}
+	}
+	return workflow.LoadEngine(wf, statePath, workspace)
+}
+
+func missionStatePath(workspace, target string) (string, error) {
+	if target != "" {
+		path := workflow.MissionStatePath(workspace, target)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("no saved mission for target %s", target)
+		}
+		return path, nil
+	}
+	paths, err := workflow.MissionStatePaths(workspace)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no saved missions in %s", filepath.Join(workspace, "missions"))
+	}
+	return paths[0], nil
+}
@@ -0,0 +1,77 @@
+package mission
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newListCommand() *cobra.Command {
+	var archived bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List saved missions",
+		Long: `List the missions saved in the workspace, most recently saved first, with
+their workflow, current phase and findings. Archived missions are only shown
+with --archived.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			missions, err := workflow.ListMissions(cfg.WorkspacePath(), archived)
+			if err != nil {
+				return err
+			}
+			printMissions(cmd.OutOrStdout(), missions)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&archived, "archived", false, "List archived missions instead")
+
+	return cmd
+}
+
+func printMissions(w io.Writer, missions []workflow.MissionSummary) {
+	if len(missions) == 0 {
+		fmt.Fprintln(w, "No saved missions.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tWORKFLOW\tPHASE\tFINDINGS\tSAVED")
+	for _, m := range missions {
+		target := m.Target
+		if target == "" {
+			target = "-"
+		}
+		findings := fmt.Sprint(m.FindingCount())
+		if breakdown := m.FindingBreakdown(); breakdown != "" {
+			findings += " (" + breakdown + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", target, m.Workflow, phaseProgress(m), findings, m.SavedAt.Format("2006-01-02 15:04"))
+	}
+	tw.Flush()
+}
+
+// phaseProgress renders the current phase, e.g. "2/4 webapp"
+func phaseProgress(m workflow.MissionSummary) string {
+	phase := m.Phase
+	if phase == "" {
+		phase = "-"
+	}
+	if m.Phases > 0 {
+		return fmt.Sprintf("%d/%d %s", m.PhaseIndex+1, m.Phases, phase)
+	}
+	return phase
+}
@@ -0,0 +1,65 @@
+package mission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newReportCommand() *cobra.Command {
+	var (
+		target string
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Render a saved mission's report",
+		Long: `Render the internal and client reports for a saved mission from its
+findings, timeline, phases, evidence and model cost. The reports are written
+to the workspace's reports directory.
+
+PDF reports are printed from the HTML report with wkhtmltopdf or headless
+Chrome/Chromium, whichever is installed.`,
+		Example: `  picoclaw mission report
+  picoclaw mission report --target 10.0.0.0/24 --format html
+  picoclaw mission report --format pdf`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			reportFormat, err := workflow.ParseReportFormat(format)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			internalPath, clientPath, err := generateReport(cfg.WorkspacePath(), target, reportFormat)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Internal report: %s\nClient report:   %s\n", internalPath, clientPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Report format: markdown, html or pdf")
+
+	return cmd
+}
+
+// generateReport writes the reports for the saved mission for target, or
+// the most recently saved one, in format
+func generateReport(workspace, target string, format workflow.ReportFormat) (string, string, error) {
+	engine, err := loadMission(workspace, target)
+	if err != nil {
+		return "", "", err
+	}
+	return engine.GenerateReport(format)
+}
@@ -0,0 +1,52 @@
+package mission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/agent"
+)
+
+func newResumeCommand() *cobra.Command {
+	var (
+		target  string
+		message string
+		model   string
+		useTUI  bool
+		debug   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Continue a saved mission in an agent session",
+		Long: `Start the agent on a saved mission. Its workflow, phase, findings and
+variables are restored, and the session continues the conversation the
+mission ran in, so the agent picks up where it left off.`,
+		Example: `  picoclaw mission resume
+  picoclaw mission resume --target 10.0.0.0/24 --tui
+  picoclaw mission resume -m "Continue with the next required step"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			statePath, err := missionStatePath(cfg.WorkspacePath(), target)
+			if err != nil {
+				return err
+			}
+			return agent.ResumeMission(statePath, message, model, debug, useTUI)
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Send a single message (non-interactive mode)")
+	cmd.Flags().StringVar(&model, "model", "", "Model to use")
+	cmd.Flags().BoolVar(&useTUI, "tui", false, "Use terminal UI (interactive mode only)")
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+
+	return cmd
+}
@@ -0,0 +1,91 @@
+package mission
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newRollbackCommand() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "rollback [revision]",
+		Short: "Undo mission state changes",
+		Long: `Restore a saved mission to an earlier revision of its state, to undo a bad
+phase advance or a branch completed by mistake. Every save of the mission is a
+revision; the most recent ones are kept (workflows.keep_revisions, default 50).
+Without a revision, list the kept revisions.
+
+The restored state is saved as a new revision, so a rollback can be undone
+too. Stop the agent working on the mission first, or its next save
+overwrites the rollback.`,
+		Example: `  picoclaw mission rollback --target 10.0.0.0/24
+  picoclaw mission rollback 41 --target 10.0.0.0/24`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revision := -1
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid revision %q", args[0])
+				}
+				revision = n
+			}
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			engine, err := loadMission(cfg.WorkspacePath(), target)
+			if err != nil {
+				return err
+			}
+			engine.SetKeepRevisions(cfg.Workflows.KeepRevisions)
+
+			if revision < 0 {
+				revisions, err := engine.Revisions()
+				if err != nil {
+					return err
+				}
+				printRevisions(cmd.OutOrStdout(), revisions)
+				return nil
+			}
+			if err := engine.Rollback(revision); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Rolled back to revision %d, saved as revision %d\n",
+				revision, engine.GetState().Revision)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+
+	return cmd
+}
+
+func printRevisions(w io.Writer, revisions []workflow.Revision) {
+	if len(revisions) == 0 {
+		fmt.Fprintln(w, "No revisions kept.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REVISION\tSAVED\tPHASE\tSTEPS\tFINDINGS\tOPEN BRANCHES")
+	for _, r := range revisions {
+		phase := r.Phase
+		if phase == "" {
+			phase = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\n",
+			r.Revision, r.SavedAt.Format("2006-01-02 15:04:05"), phase, r.Steps, r.Findings, r.Branches)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,118 @@
+package mission
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newShowCommand() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show a saved mission's progress and findings",
+		Long: `Show a saved mission's phases, the steps of its current phase, open
+branches, findings and model cost, without starting the agent.`,
+		Example: `  picoclaw mission show
+  picoclaw mission show --target 10.0.0.0/24`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			engine, err := loadMission(cfg.WorkspacePath(), target)
+			if err != nil {
+				return err
+			}
+			printMission(cmd.OutOrStdout(), cfg.WorkspacePath(), engine)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+
+	return cmd
+}
+
+func printMission(w io.Writer, workspace string, engine *workflow.Engine) {
+	state := engine.GetState()
+	wf := engine.GetWorkflow()
+	summary := workflow.SummarizeMission(workspace, state)
+
+	target := state.Target
+	if target == "" {
+		target = state.WorkflowName
+	}
+	fmt.Fprintf(w, "Mission: %s (%s)\n", target, state.WorkflowName)
+	fmt.Fprintf(w, "Started: %s\n", state.StartTime.Format("2006-01-02 15:04"))
+	fmt.Fprintf(w, "Phase:   %s\n", phaseProgress(summary))
+	if session, _ := state.Metadata[workflow.MetaSessionKey].(string); session != "" {
+		fmt.Fprintf(w, "Session: %s\n", session)
+	}
+
+	// Steps of the current phase
+	if state.CurrentPhase < len(wf.Phases) && len(state.PhaseHistory) > 0 {
+		phase := wf.Phases[state.CurrentPhase]
+		done := state.PhaseHistory[len(state.PhaseHistory)-1].StepsComplete
+		if len(phase.Steps) > 0 {
+			fmt.Fprintf(w, "\nSteps in %s:\n", phase.Name)
+			for _, step := range phase.Steps {
+				mark := " "
+				if slices.Contains(done, step.ID) {
+					mark = "x"
+				}
+				required := ""
+				if step.Required {
+					required = " (required)"
+				}
+				fmt.Fprintf(w, "  [%s] %s: %s%s\n", mark, step.ID, step.Name, required)
+			}
+		}
+	}
+
+	var open []workflow.ActiveBranch
+	for _, b := range state.ActiveBranches {
+		if b.CompletedAt == nil {
+			open = append(open, b)
+		}
+	}
+	if len(open) > 0 {
+		fmt.Fprintln(w, "\nOpen branches:")
+		for _, b := range open {
+			fmt.Fprintf(w, "  - %s: %s\n", b.Condition, b.Description)
+		}
+	}
+
+	findings := workflow.ReportFindings(state, workflow.ReportInternal)
+	fmt.Fprintf(w, "\nFindings: %d", len(findings))
+	if breakdown := summary.FindingBreakdown(); breakdown != "" {
+		fmt.Fprintf(w, " (%s)", breakdown)
+	}
+	fmt.Fprintln(w)
+	if len(findings) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  SEVERITY\tCVSS\tTITLE\tPHASE")
+		for _, f := range findings {
+			cvss := "-"
+			if f.CVSSScore > 0 {
+				cvss = fmt.Sprintf("%.1f", f.CVSSScore)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", f.Severity, cvss, f.Title, f.Phase)
+		}
+		tw.Flush()
+	}
+
+	if total := workflow.TotalEffort(state.Effort()); total.Cost > 0 || total.Tokens > 0 || total.Total() > 0 {
+		fmt.Fprintf(w, "\nEffort: %s operator, %s agent; $%.2f, %d tokens\n",
+			workflow.FormatEffortDuration(total.Operator), workflow.FormatEffortDuration(total.Agent), total.Cost, total.Tokens)
+	}
+}
@@ -0,0 +1,29 @@
+package monitor
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewMonitorCommand() *cobra.Command {
+	var (
+		targets []string
+		once    bool
+		reset   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "monitor",
+		Aliases: []string{"mon"},
+		Short:   "Continuously monitor targets for inventory changes",
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return monitorCmd(targets, once, reset)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&targets, "target", "t", nil, "Target to monitor (repeatable, overrides monitor.targets)")
+	cmd.Flags().BoolVar(&once, "once", false, "Run the checks once and exit")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Discard saved baselines and golden responses before the first run")
+
+	return cmd
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMonitorCommand(t *testing.T) {
+	cmd := NewMonitorCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "monitor", cmd.Use)
+	assert.True(t, cmd.HasAlias("mon"))
+	assert.Equal(t, "Continuously monitor targets for inventory changes", cmd.Short)
+
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for _, name := range []string{"target", "once", "reset"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing --%s flag", name)
+	}
+}
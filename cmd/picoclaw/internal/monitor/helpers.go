@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/monitor"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+func monitorCmd(targets []string, once, reset bool) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if len(targets) > 0 {
+		cfg.Monitor.Targets = targets
+	}
+	if len(cfg.Monitor.Targets) == 0 || len(cfg.Monitor.Checks)+len(cfg.Monitor.Endpoints) == 0 {
+		return fmt.Errorf("configure monitor.targets and monitor.checks or monitor.endpoints first")
+	}
+	// Running the command is the opt-in
+	cfg.Monitor.Enabled = true
+
+	if reset {
+		for _, target := range cfg.Monitor.Targets {
+			if err := os.Remove(monitor.BaselinePath(cfg.WorkspacePath(), target)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to reset baseline for %s: %w", target, err)
+			}
+			if err := os.RemoveAll(monitor.GoldenDir(cfg.WorkspacePath(), target)); err != nil {
+				return fmt.Errorf("failed to reset golden responses for %s: %w", target, err)
+			}
+		}
+	}
+
+	mon := monitor.New(cfg, nil)
+	if cfg.Monitor.Assess && len(cfg.Monitor.Endpoints) > 0 {
+		provider, modelID, err := providers.CreateProvider(cfg)
+		if err != nil {
+			return fmt.Errorf("error creating provider for monitor.assess: %w", err)
+		}
+		mon.SetProvider(provider, modelID)
+	}
+	mon.SetOnAlert(func(alert monitor.Alert) {
+		fmt.Printf("\n%s\n", alert.Summary())
+	})
+
+	if once {
+		alerts := mon.RunOnce(context.Background())
+		if len(alerts) == 0 {
+			fmt.Println("✓ No changes")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := mon.Start(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("%s Monitoring %d target(s) (Ctrl+C to stop)\n", internal.Logo, len(cfg.Monitor.Targets))
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	<-sigChan
+
+	fmt.Println("\nStopping monitor...")
+	mon.Stop()
+	return nil
+}
@@ -0,0 +1,17 @@
+package routing
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewRoutingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routing",
+		Short: "Inspect tier routing decisions",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newExplainCommand())
+
+	return cmd
+}
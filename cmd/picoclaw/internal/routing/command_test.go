@@ -0,0 +1,28 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRoutingCommand(t *testing.T) {
+	cmd := NewRoutingCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "routing", cmd.Use)
+	assert.Equal(t, "Inspect tier routing decisions", cmd.Short)
+
+	assert.True(t, cmd.HasSubCommands())
+
+	explain, _, err := cmd.Find([]string{"explain"})
+	require.NoError(t, err)
+	assert.Equal(t, "explain <message>", explain.Use)
+	assert.NotNil(t, explain.RunE)
+
+	for _, flag := range []string{"turn", "tool-output", "report", "json"} {
+		assert.NotNil(t, explain.Flags().Lookup(flag), "missing --%s flag", flag)
+	}
+}
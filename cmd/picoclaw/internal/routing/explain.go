@@ -0,0 +1,142 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	pkgrouting "github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+)
+
+type explainOptions struct {
+	turn           int
+	toolOutputFile string
+	report         bool
+	jsonOutput     bool
+}
+
+func newExplainCommand() *cobra.Command {
+	var opts explainOptions
+
+	cmd := &cobra.Command{
+		Use:   "explain <message>",
+		Short: "Explain which tier and model a message would be routed to",
+		Long: `Classify a message with the configured routing rules and show why it lands
+on a given tier: the rules that matched, complexity keyword hits, the chosen
+tier and model, and the alternatives that lost. No request is sent.
+
+Examples:
+  picoclaw routing explain "analyze the nmap output"
+  picoclaw routing explain "summarize this" --tool-output scan.txt
+  picoclaw routing explain "write the report" --report --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return explainCmd(args[0], opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.turn, "turn", 1, "Turn number within the session (0 = session start)")
+	cmd.Flags().StringVar(&opts.toolOutputFile, "tool-output", "", "File holding the last tool output")
+	cmd.Flags().BoolVar(&opts.report, "report", false, "Treat the message as a report request")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "Print the explanation as JSON")
+
+	return cmd
+}
+
+func explainCmd(message string, opts explainOptions) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	agentCtx := pkgrouting.AgentContext{
+		TurnCount:       opts.turn,
+		UserMessage:     message,
+		ReportRequested: opts.report,
+	}
+	if opts.toolOutputFile != "" {
+		data, err := os.ReadFile(opts.toolOutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tool output: %w", err)
+		}
+		agentCtx.LastToolOutput = string(data)
+	}
+
+	// Providers are not needed to classify and select a tier
+	router := pkgrouting.NewTierRouter(&cfg.Routing, cfg.ModelList, nil)
+	ex := router.ExplainRoute(agentCtx)
+
+	if opts.jsonOutput {
+		data, err := json.MarshalIndent(ex, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printExplanation(ex, cfg.Routing.Enabled)
+	return nil
+}
+
+func printExplanation(ex *pkgrouting.RouteExplanation, enabled bool) {
+	fmt.Print("🧭 Routing Explanation\n\n")
+	if !enabled {
+		fmt.Print("⚠️  Routing is disabled; requests use the default tier\n\n")
+	}
+
+	fmt.Printf("Task:        %s (rule: %s)\n", ex.Task, ex.Rule)
+	fmt.Printf("Complexity:  %d/10\n", ex.Complexity)
+	fmt.Printf("Confidence:  %.2f\n", ex.Confidence)
+	fmt.Printf("Supervision: %s\n", yesNo(ex.RequiresSupervision))
+
+	if len(ex.KeywordHits) > 0 {
+		hits := make([]string, len(ex.KeywordHits))
+		for i, h := range ex.KeywordHits {
+			hits[i] = fmt.Sprintf("%s (%+d)", h.Keyword, h.Modifier)
+		}
+		fmt.Printf("Keywords:    %s\n", strings.Join(hits, ", "))
+	} else {
+		fmt.Println("Keywords:    none")
+	}
+
+	fmt.Println()
+	if ex.TierError != "" {
+		fmt.Printf("❌ No tier: %s\n", ex.TierError)
+	} else {
+		fmt.Printf("✓ Tier %s → %s (%s)\n", ex.Tier, ex.Model, ex.TierReason)
+		fmt.Printf("  $%.2f / $%.2f per 1M input/output tokens\n", ex.CostPerM.Input, ex.CostPerM.Output)
+	}
+
+	fmt.Println("\nMatched rules (first wins):")
+	for i, m := range ex.MatchedRules {
+		marker := " "
+		if i == 0 {
+			marker = "→"
+		}
+		fmt.Printf("  %s %s → %s\n", marker, m.Rule, m.Task)
+	}
+
+	if len(ex.Alternatives) > 0 {
+		fmt.Println("\nAlternatives considered:")
+		for _, alt := range ex.Alternatives {
+			if alt.Tier == "" {
+				fmt.Printf("  • %s (%s): no tier\n", alt.Task, alt.Rule)
+				continue
+			}
+			fmt.Printf("  • %s (%s): tier %s → %s, $%.2f / $%.2f per 1M\n",
+				alt.Task, alt.Rule, alt.Tier, alt.Model, alt.CostPerM.Input, alt.CostPerM.Output)
+		}
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
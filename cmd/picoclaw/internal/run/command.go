@@ -0,0 +1,37 @@
+package run
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewRunCommand() *cobra.Command {
+	var (
+		engagementFile string
+		message        string
+		debug          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a mission from an engagement file",
+		Long: `Start a mission from an engagement file: one YAML file with the client,
+workflow, target, scope, rules of engagement, schedule, budget and report
+template.
+
+The mission refuses to start outside the testing window. With tier routing
+on, model requests also stop once the window closes or the budget is spent.`,
+		Example: `  picoclaw run --engagement engagement.yaml
+  picoclaw run -e engagement.yaml -m "Resume from the enumeration phase"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCmd(cmd.Context(), engagementFile, message, debug)
+		},
+	}
+
+	cmd.Flags().StringVarP(&engagementFile, "engagement", "e", "", "Engagement file (YAML)")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "First message to the agent (default: the engagement's kickoff)")
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+	_ = cmd.MarkFlagRequired("engagement")
+
+	return cmd
+}
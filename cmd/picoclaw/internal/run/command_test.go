@@ -0,0 +1,157 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const testEngagement = `
+client: ACME Corp
+id: ACME-2026/014
+workflow: network-scan
+target: 10.0.0.0/24
+scope:
+  include: [10.0.1.0/24, "https://portal.acme.example/login", 10.0.1.0/24]
+  exclude: [10.0.0.1]
+roe:
+  authorization: SOW-2026-014
+  contacts: [soc@acme.example]
+  rules: [No denial of service]
+  deny_tools: [profile:fuzz]
+schedule:
+  start: 2026-10-19
+  end: 2026-10-30
+  days: [mon, tue, wed, thu, friday]
+  hours: "09:00-17:00"
+  timezone: UTC
+budget:
+  max_cost_usd: 0.5
+report:
+  template: acme.md
+`
+
+func writeEngagement(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.md"), []byte("# {client} ({engagement_id})\n\n{report}"), 0o644))
+	path := filepath.Join(dir, "engagement.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestNewRunCommand(t *testing.T) {
+	cmd := NewRunCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "run", cmd.Use)
+	assert.Equal(t, "Run a mission from an engagement file", cmd.Short)
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for _, name := range []string{"engagement", "message", "debug"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing --%s", name)
+	}
+	assert.Equal(t, []string{"true"}, cmd.Flags().Lookup("engagement").Annotations["cobra_annotation_bash_completion_one_required_flag"])
+}
+
+func TestLoadEngagement(t *testing.T) {
+	path := writeEngagement(t, testEngagement)
+
+	eng, err := workflow.LoadEngagement(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"10.0.1.0/24", "portal.acme.example"}, eng.Scope.Include)
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "acme.md"), eng.Report.Template)
+	assert.Equal(t, "2026-10-19 to 2026-10-30, mon, tue, wed, thu, friday, 09:00-17:00, UTC", eng.Window())
+	assert.Equal(t, "ACME-2026_014", sessionSlug(eng))
+	assert.Contains(t, kickoffMessage(eng), "network-scan workflow against 10.0.0.0/24")
+
+	for name, content := range map[string]string{
+		"no workflow":   "target: 10.0.0.1",
+		"bad scope":     "workflow: w\ntarget: 10.0.0.1\nscope:\n  include: [\"bad host\"]",
+		"bad hours":     "workflow: w\ntarget: 10.0.0.1\nschedule:\n  hours: 9-5",
+		"bad day":       "workflow: w\ntarget: 10.0.0.1\nschedule:\n  days: [someday]",
+		"ends early":    "workflow: w\ntarget: 10.0.0.1\nschedule:\n  start: 2026-10-19\n  end: 2026-10-01",
+		"no template":   "workflow: w\ntarget: 10.0.0.1\nreport:\n  template: missing.md",
+		"negative cost": "workflow: w\ntarget: 10.0.0.1\nbudget:\n  max_cost_usd: -1",
+		"bad language":  "workflow: w\ntarget: 10.0.0.1\nreport:\n  language: klingon",
+	} {
+		_, err := workflow.LoadEngagement(writeEngagement(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEngagementPolicy(t *testing.T) {
+	eng, err := workflow.LoadEngagement(writeEngagement(t, testEngagement))
+	require.NoError(t, err)
+
+	costs := routing.NewCostTracker()
+	policy := newEngagementPolicy(eng, costs)
+	now := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC) // Tuesday
+	policy.now = func() time.Time { return now }
+
+	_, err = policy.PreRoute(context.Background(), routing.PolicyRequest{})
+	assert.NoError(t, err)
+
+	for _, outside := range []time.Time{
+		time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC), // After hours
+		time.Date(2026, 10, 24, 10, 0, 0, 0, time.UTC), // Saturday
+		time.Date(2026, 10, 31, 10, 0, 0, 0, time.UTC), // After the end date
+	} {
+		now = outside
+		_, err = policy.PreRoute(context.Background(), routing.PolicyRequest{})
+		assert.ErrorContains(t, err, "outside the testing window", outside.String())
+	}
+
+	now = time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	tier := config.TierConfig{CostPerM: config.CostPerMInfo{Input: 1, Output: 1}}
+	costs.Record("s1", "m", "heavy", tier, providers.UsageInfo{PromptTokens: 300_000}, 0, routing.CostAttribution{})
+	_, err = policy.PreRoute(context.Background(), routing.PolicyRequest{})
+	assert.NoError(t, err)
+	_, err = policy.PreRoute(context.Background(), routing.PolicyRequest{EstimatedCost: 0.25})
+	assert.ErrorContains(t, err, "engagement budget of $0.50 would be exceeded")
+
+	costs.Record("s2", "m", "heavy", tier, providers.UsageInfo{PromptTokens: 200_000}, 0, routing.CostAttribution{})
+	_, err = policy.PreRoute(context.Background(), routing.PolicyRequest{})
+	assert.ErrorContains(t, err, "engagement budget of $0.50 spent")
+}
+
+func TestApplyEngagement(t *testing.T) {
+	eng, err := workflow.LoadEngagement(writeEngagement(t, testEngagement))
+	require.NoError(t, err)
+
+	wf := &workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{{Name: "discovery"}}}
+	engine := workflow.NewEngine(wf, eng.Target, t.TempDir())
+	require.NoError(t, engine.ApplyEngagement(eng))
+
+	state := engine.GetState()
+	assert.Equal(t, "ACME Corp", state.Metadata[workflow.MetaClient])
+	assert.Equal(t, "SOW-2026-014", state.Metadata[workflow.MetaAuthorization])
+	assert.Equal(t, 0.5, state.Metadata[workflow.MetaBudget])
+	assert.Equal(t, []string{"10.0.0.1"}, state.Scope.Exclude)
+	assert.Contains(t, wf.Tools.Deny, "profile:fuzz")
+
+	prompt := engine.GetContextPrompt()
+	assert.Contains(t, prompt, "## Rules of Engagement")
+	assert.Contains(t, prompt, "- No denial of service")
+	assert.Contains(t, prompt, "- Emergency contacts: soc@acme.example")
+
+	_, clientPath, err := engine.GenerateReports()
+	require.NoError(t, err)
+	report, err := os.ReadFile(clientPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "# ACME Corp (ACME-2026/014)\n\n# Security Assessment Report: 10.0.0.0/24")
+	assert.Contains(t, string(report), "- **Client**: ACME Corp")
+}
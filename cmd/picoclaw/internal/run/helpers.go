@@ -0,0 +1,142 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func runCmd(ctx context.Context, engagementFile, message string, debug bool) error {
+	if debug {
+		logger.SetLevel(logger.DEBUG)
+	}
+
+	eng, err := workflow.LoadEngagement(engagementFile)
+	if err != nil {
+		return err
+	}
+	if err := eng.CheckSchedule(time.Now()); err != nil {
+		return fmt.Errorf("outside the testing window: %w", err)
+	}
+
+	runtime, err := internal.BootstrapAgentRuntime("")
+	if err != nil {
+		return err
+	}
+	agentLoop := runtime.AgentLoop
+	defaultAgent := agentLoop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return fmt.Errorf("failed to get default agent for workflow loading")
+	}
+	if err := defaultAgent.LoadWorkflow(eng.Workflow, eng.Target); err != nil {
+		return fmt.Errorf("failed to load workflow '%s': %w", eng.Workflow, err)
+	}
+	if err := defaultAgent.WorkflowEngine.ApplyEngagement(eng); err != nil {
+		return fmt.Errorf("failed to apply engagement: %w", err)
+	}
+
+	fmt.Printf("📋 %s\n", engagementSummary(eng))
+
+	tierRouter := agentLoop.GetTierRouter()
+	if tierRouter != nil {
+		tierRouter.AddPolicy(newEngagementPolicy(eng, tierRouter.GetCostTracker()))
+	} else if eng.Budget.MaxCostUSD > 0 || eng.Window() != "" {
+		fmt.Println("⚠ Tier routing is off: the budget and testing window are only checked at start")
+	}
+
+	if message == "" {
+		message = kickoffMessage(eng)
+	}
+	sessionKey := fmt.Sprintf("cli:engagement_%s_%d", sessionSlug(eng), time.Now().Unix())
+	response, err := agentLoop.ProcessDirect(ctx, message, sessionKey)
+	if err != nil {
+		return fmt.Errorf("error processing message: %w", err)
+	}
+	fmt.Printf("\n%s %s\n", internal.Logo, response)
+
+	if tierRouter != nil {
+		fmt.Printf("\n%s\n", tierRouter.GetCostTracker().FormatSessionReport(sessionKey))
+	}
+	return nil
+}
+
+func engagementSummary(eng *workflow.Engagement) string {
+	parts := []string{fmt.Sprintf("workflow %s against %s", eng.Workflow, eng.Target)}
+	if eng.Client != "" {
+		parts = append([]string{eng.Client}, parts...)
+	}
+	if n := len(eng.Scope.Include) + len(eng.Scope.Exclude); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d scope entries", n))
+	}
+	if eng.Budget.MaxCostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("budget $%.2f", eng.Budget.MaxCostUSD))
+	}
+	if window := eng.Window(); window != "" {
+		parts = append(parts, "window "+window)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func kickoffMessage(eng *workflow.Engagement) string {
+	if eng.Kickoff != "" {
+		return eng.Kickoff
+	}
+	return fmt.Sprintf("Start the engagement: work through the %s workflow against %s. "+
+		"Stay within the scope and the rules of engagement in the mission context, "+
+		"and record findings as you go.", eng.Workflow, eng.Target)
+}
+
+var unsafeSessionChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sessionSlug names the session after the engagement ID, or the target
+func sessionSlug(eng *workflow.Engagement) string {
+	name := eng.ID
+	if name == "" {
+		name = eng.Target
+	}
+	return strings.Trim(unsafeSessionChars.ReplaceAllString(name, "_"), "_")
+}
+
+// engagementPolicy stops model requests once the testing window closes or
+// the engagement budget is spent, and refuses a request whose preflight
+// estimate would take spend past the budget. The run owns the process, so
+// all spend counts against the budget.
+type engagementPolicy struct {
+	eng   *workflow.Engagement
+	costs *routing.CostTracker
+	now   func() time.Time
+}
+
+func newEngagementPolicy(eng *workflow.Engagement, costs *routing.CostTracker) *engagementPolicy {
+	return &engagementPolicy{eng: eng, costs: costs, now: time.Now}
+}
+
+func (p *engagementPolicy) PreRoute(_ context.Context, req routing.PolicyRequest) (routing.PolicyDecision, error) {
+	if err := p.eng.CheckSchedule(p.now()); err != nil {
+		return routing.PolicyDecision{}, fmt.Errorf("outside the testing window: %w", err)
+	}
+	if limit := p.eng.Budget.MaxCostUSD; limit > 0 && p.costs != nil {
+		spent := p.costs.GetTotalCost()
+		if spent >= limit {
+			return routing.PolicyDecision{}, fmt.Errorf("engagement budget of $%.2f spent ($%.4f)", limit, spent)
+		}
+		if spent+req.EstimatedCost > limit {
+			return routing.PolicyDecision{}, fmt.Errorf("engagement budget of $%.2f would be exceeded: $%.4f spent, request may cost $%.4f",
+				limit, spent, req.EstimatedCost)
+		}
+	}
+	return routing.PolicyDecision{}, nil
+}
+
+func (p *engagementPolicy) PostRoute(context.Context, routing.PolicyRequest, *providers.LLMResponse) {
+}
+
+func (p *engagementPolicy) OnError(context.Context, routing.PolicyRequest, error) {}
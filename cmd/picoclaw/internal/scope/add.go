@@ -0,0 +1,33 @@
+package scope
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newAddCommand(mission func() (*missionRef, error)) *cobra.Command {
+	var exclude bool
+
+	cmd := &cobra.Command{
+		Use:   "add <entry> [reason]",
+		Short: "Add a scope entry or exclusion",
+		Args:  cobra.MinimumNArgs(1),
+		Example: `picoclaw scope add 10.0.1.0/24 "client confirmed the second subnet"
+picoclaw scope add --exclude 10.0.1.1 "production gateway"`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return changeScope(mission, workflow.ScopeChange{
+				Action:  workflow.ScopeAdd,
+				Entry:   args[0],
+				Exclude: exclude,
+				Reason:  strings.Join(args[1:], " "),
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&exclude, "exclude", false, "Add an exclusion instead of an in-scope entry")
+
+	return cmd
+}
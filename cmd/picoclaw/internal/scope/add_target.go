@@ -0,0 +1,38 @@
+package scope
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newAddTargetCommand(mission func() (*missionRef, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-target <host> [reason]",
+		Short: "Add a further mission target",
+		Long: `Add a further target to the mission. Targets are in scope like the mission
+target, so this also widens the scope unless the host is excluded.`,
+		Args: cobra.MinimumNArgs(1),
+		Example: `picoclaw scope add-target api.example.com "second application in the engagement"
+picoclaw scope add-target --target 10.0.0.0/24 10.0.5.10`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			ref, err := mission()
+			if err != nil {
+				return err
+			}
+			engine, err := ref.engine()
+			if err != nil {
+				return err
+			}
+			if err := engine.AddTarget(args[0], workflow.ScopeByOperator, strings.Join(args[1:], " ")); err != nil {
+				return err
+			}
+			printScope(engine.GetState())
+			return nil
+		},
+	}
+
+	return cmd
+}
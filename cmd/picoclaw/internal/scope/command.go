@@ -0,0 +1,55 @@
+package scope
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+)
+
+func NewScopeCommand() *cobra.Command {
+	var (
+		target    string
+		workspace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "scope",
+		Short: "Show and edit a mission's scope",
+		Long: `Show and edit the scope of a saved mission. Every change is kept in the
+mission's scope history for the audit trail.
+
+Entries are hostnames, *.domain wildcards, IP addresses or CIDR ranges. The
+mission targets are always in scope unless excluded, and exclusions win.
+
+Edits apply to the saved mission state. To change the scope of a mission
+that is running, use /scope in its chat instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		// Resolve the workspace at execution time so it reflects the current
+		// config and is shared across all subcommands.
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			workspace = cfg.WorkspacePath()
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+
+	mission := func() (*missionRef, error) { return findMission(workspace, target) }
+	cmd.AddCommand(
+		newShowCommand(mission),
+		newAddCommand(mission),
+		newRemoveCommand(mission),
+		newAddTargetCommand(mission),
+	)
+
+	return cmd
+}
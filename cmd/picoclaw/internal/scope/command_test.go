@@ -0,0 +1,34 @@
+package scope
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScopeCommand(t *testing.T) {
+	cmd := NewScopeCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "scope", cmd.Use)
+	assert.Equal(t, "Show and edit a mission's scope", cmd.Short)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("target"))
+
+	assert.True(t, cmd.HasSubCommands())
+	allowedCommands := []string{"show", "add", "remove", "add-target"}
+	for _, subcmd := range cmd.Commands() {
+		assert.True(t, slices.Contains(allowedCommands, subcmd.Name()), "unexpected subcommand %q", subcmd.Name())
+		assert.NotNil(t, subcmd.RunE)
+	}
+	assert.Len(t, cmd.Commands(), len(allowedCommands))
+
+	for _, name := range []string{"add", "remove"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.NotNil(t, sub.Flags().Lookup("exclude"), "%s is missing --exclude", name)
+	}
+}
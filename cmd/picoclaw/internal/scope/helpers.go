@@ -0,0 +1,68 @@
+package scope
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// missionRef is a saved mission the scope commands work on
+type missionRef struct {
+	workspace string
+	statePath string
+	state     workflow.MissionState
+}
+
+// findMission locates the saved mission for target, or the most recently
+// saved one when target is empty
+func findMission(workspace, target string) (*missionRef, error) {
+	statePath := ""
+	if target != "" {
+		statePath = workflow.MissionStatePath(workspace, target)
+	} else {
+		paths, _ := workflow.MissionStatePaths(workspace)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no saved missions in %s", filepath.Join(workspace, "missions"))
+		}
+		statePath = paths[0]
+	}
+
+	state, err := workflow.ReadMissionState(statePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no saved mission for target %s", target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &missionRef{workspace: workspace, statePath: statePath, state: *state}, nil
+}
+
+// engine loads the mission for editing
+func (m *missionRef) engine() (*workflow.Engine, error) {
+	wf, err := workflow.LoadWorkflow(m.workspace, m.state.WorkflowName)
+	if err != nil {
+		return nil, err
+	}
+	return workflow.LoadEngine(wf, m.statePath, m.workspace)
+}
+
+// changeScope applies an operator change to the saved mission and prints the
+// resulting scope
+func changeScope(mission func() (*missionRef, error), change workflow.ScopeChange) error {
+	ref, err := mission()
+	if err != nil {
+		return err
+	}
+	engine, err := ref.engine()
+	if err != nil {
+		return err
+	}
+	change.By = workflow.ScopeByOperator
+	if err := engine.ChangeScope(change); err != nil {
+		return err
+	}
+	printScope(engine.GetState())
+	return nil
+}
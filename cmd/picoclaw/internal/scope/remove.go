@@ -0,0 +1,34 @@
+package scope
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newRemoveCommand(mission func() (*missionRef, error)) *cobra.Command {
+	var exclude bool
+
+	cmd := &cobra.Command{
+		Use:     "remove <entry> [reason]",
+		Aliases: []string{"rm"},
+		Short:   "Remove a scope entry or exclusion",
+		Args:    cobra.MinimumNArgs(1),
+		Example: `picoclaw scope remove staging.example.com "decommissioned"
+picoclaw scope remove --exclude 10.0.1.1`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return changeScope(mission, workflow.ScopeChange{
+				Action:  workflow.ScopeRemove,
+				Entry:   args[0],
+				Exclude: exclude,
+				Reason:  strings.Join(args[1:], " "),
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&exclude, "exclude", false, "Remove an exclusion instead of an in-scope entry")
+
+	return cmd
+}
@@ -0,0 +1,48 @@
+package scope
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newShowCommand(mission func() (*missionRef, error)) *cobra.Command {
+	var history bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the scope and recent changes",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw scope show
+picoclaw scope show --target 10.0.0.0/24 --history`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ref, err := mission()
+			if err != nil {
+				return err
+			}
+			printScope(&ref.state)
+			if history {
+				fmt.Println("\nFull history:")
+				if len(ref.state.Scope.History) == 0 {
+					fmt.Println("  (no changes)")
+				}
+				for _, change := range ref.state.Scope.History {
+					fmt.Printf("  %s\n", change)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&history, "history", false, "Show every scope change, not just the latest")
+
+	return cmd
+}
+
+func printScope(state *workflow.MissionState) {
+	fmt.Printf("Mission: %s (%s)\n", state.Target, state.WorkflowName)
+	fmt.Println(tools.FormatScope(state))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/skills"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 )
 
 type deps struct {
@@ -30,6 +31,11 @@ func NewSkillsCommand() *cobra.Command {
 
 			d.workspace = cfg.WorkspacePath()
 			d.installer = skills.NewSkillInstaller(d.workspace)
+			policy, err := trust.NewPolicy(cfg.Trust)
+			if err != nil {
+				return err
+			}
+			d.installer.SetTrustPolicy(policy)
 
 			// get global config directory and builtin skills directory
 			globalDir := filepath.Dir(internal.GetConfigPath())
@@ -12,6 +12,7 @@ import (
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/skills"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/utils"
 )
 
@@ -62,9 +63,14 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 
 	fmt.Printf("Installing skill '%s' from %s registry...\n", slug, registryName)
 
+	policy, err := trust.NewPolicy(cfg.Trust)
+	if err != nil {
+		return fmt.Errorf("✗  %w", err)
+	}
 	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
 		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+		Trust:                 policy,
 	})
 
 	registry := registryMgr.GetRegistry(registryName)
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func NewStatsCommand() *cobra.Command {
+	var (
+		interval string
+		since    string
+		wfName   string
+		active   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show trends across archived missions",
+		Long: `Show what archived missions add up to: findings per severity over time,
+mean time and model spend per workflow phase, spend per finding for each
+workflow, and how often the supervisor rejected each worker model.
+
+Missions count towards the period they started in. Archive finished missions
+with 'picoclaw mission archive' so they show up here.`,
+		Example: `  picoclaw stats
+  picoclaw stats --interval week --since 2026-07-01
+  picoclaw stats --workflow web-app-assessment --active`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			group, err := workflow.ParseTrendInterval(interval)
+			if err != nil {
+				return err
+			}
+			var from time.Time
+			if since != "" {
+				if from, err = time.ParseInLocation("2006-01-02", since, time.Local); err != nil {
+					return fmt.Errorf("invalid --since date %q (want YYYY-MM-DD)", since)
+				}
+			}
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			states, err := loadMissions(cfg.WorkspacePath(), active)
+			if err != nil {
+				return err
+			}
+			states = filterMissions(states, from, wfName)
+			printTrends(cmd.OutOrStdout(), workflow.AnalyzeTrends(states, group), group)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&interval, "interval", string(workflow.TrendMonthly), "Group findings by month or week")
+	cmd.Flags().StringVar(&since, "since", "", "Only missions started on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVarP(&wfName, "workflow", "w", "", "Only missions run with this workflow")
+	cmd.Flags().BoolVar(&active, "active", false, "Include missions that are not archived yet")
+
+	return cmd
+}
@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func TestNewStatsCommand(t *testing.T) {
+	cmd := NewStatsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "stats", cmd.Use)
+	assert.Equal(t, "Show trends across archived missions", cmd.Short)
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for _, name := range []string{"interval", "since", "workflow", "active"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing --%s", name)
+	}
+}
+
+func TestTrends(t *testing.T) {
+	workspace := t.TempDir()
+	archive := filepath.Join(workspace, filepath.FromSlash(workflow.MissionArchiveDir))
+	require.NoError(t, os.MkdirAll(archive, 0o755))
+
+	day := func(month time.Month, d, hour int) time.Time {
+		return time.Date(2026, month, d, hour, 0, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+	states := map[string]*workflow.MissionState{
+		"a": {
+			WorkflowName: "web-app-assessment",
+			Target:       "app.example.com",
+			StartTime:    day(8, 3, 9),
+			PhaseHistory: []workflow.PhaseExecution{
+				{PhaseName: "recon", StartTime: day(8, 3, 9), EndTime: ptr(day(8, 3, 11)), AgentTime: time.Hour, CostUSD: 0.50},
+				{PhaseName: "exploit", StartTime: day(8, 3, 11), AgentTime: 3 * time.Hour, CostUSD: 1.50},
+			},
+			Findings: []workflow.Finding{
+				{Title: "SQL injection", Severity: workflow.SeverityCritical},
+				{Title: "Verbose errors", Severity: workflow.SeverityLow},
+			},
+			Reviews: map[string]workflow.ModelReviews{"qwen3-8b": {Reviewed: 10, Rejected: 3}},
+		},
+		"b": {
+			WorkflowName: "web-app-assessment",
+			Target:       "shop.example.com",
+			StartTime:    day(8, 20, 9),
+			PhaseHistory: []workflow.PhaseExecution{
+				{PhaseName: "recon", StartTime: day(8, 20, 9), EndTime: ptr(day(8, 20, 13)), OperatorTime: time.Hour, AgentTime: time.Hour, CostUSD: 1.00},
+			},
+			Findings: []workflow.Finding{{Title: "Missing HSTS", Severity: workflow.SeverityLow}},
+			Reviews: map[string]workflow.ModelReviews{
+				"qwen3-8b":     {Reviewed: 10, Rejected: 1},
+				"claude-haiku": {Reviewed: 4},
+			},
+		},
+		"c": {
+			WorkflowName: "network-scan",
+			Target:       "10.0.0.0/24",
+			StartTime:    day(9, 1, 9),
+			PhaseHistory: []workflow.PhaseExecution{{PhaseName: "discovery", StartTime: day(9, 1, 9), AgentTime: 30 * time.Minute}},
+		},
+	}
+	for name, state := range states {
+		data, err := json.Marshal(state)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(archive, name+"_state.json"), data, 0o644))
+	}
+	// Not archived, so left out unless asked for
+	engine := workflow.NewEngine(&workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{{Name: "discovery"}}}, "10.0.1.1", workspace)
+	require.NoError(t, engine.AddFinding("Telnet open", "", workflow.SeverityHigh, ""))
+
+	loaded, err := loadMissions(workspace, false)
+	require.NoError(t, err)
+	assert.Len(t, loaded, 3)
+	withActive, err := loadMissions(workspace, true)
+	require.NoError(t, err)
+	assert.Len(t, withActive, 4)
+
+	trends := workflow.AnalyzeTrends(loaded, workflow.TrendMonthly)
+	assert.Equal(t, 3, trends.Missions)
+	assert.Equal(t, 3, trends.Findings)
+	assert.InDelta(t, 1.00, trends.CostPerFinding(), 1e-9)
+	require.Len(t, trends.Periods, 2)
+	assert.Equal(t, "2026-08", trends.Periods[0].Period)
+	assert.Equal(t, map[workflow.Severity]int{workflow.SeverityCritical: 1, workflow.SeverityLow: 2}, trends.Periods[0].Findings)
+	assert.Equal(t, []workflow.PhaseTrend{
+		{Workflow: "network-scan", Phase: "discovery", Missions: 1, Effort: 30 * time.Minute},
+		{Workflow: "web-app-assessment", Phase: "recon", Missions: 2, Elapsed: 3 * time.Hour, Effort: 90 * time.Minute, Cost: 0.75},
+		{Workflow: "web-app-assessment", Phase: "exploit", Missions: 1, Effort: 3 * time.Hour, Cost: 1.50},
+	}, trends.Phases)
+	require.Len(t, trends.Models, 2)
+	assert.Equal(t, "qwen3-8b", trends.Models[0].Model)
+	assert.InDelta(t, 0.2, trends.Models[0].RejectionRate(), 1e-9)
+
+	var out bytes.Buffer
+	printTrends(&out, trends, workflow.TrendMonthly)
+	for _, want := range []string{
+		"Missions: 3  Findings: 3  Model spend: $3.00  Per finding: $1.00",
+		"PERIOD   MISSIONS  CRITICAL  HIGH  MEDIUM  LOW  INFO  SPEND",
+		"2026-08  2         1         0     0       2    0     $3.00",
+		"web-app-assessment  recon      2         3h00m    1h30m   $0.75",
+		"network-scan        1         0         $0.00  -",
+		"qwen3-8b      20        4         20%",
+	} {
+		assert.Contains(t, out.String(), want)
+	}
+
+	weekly := workflow.AnalyzeTrends(loaded, workflow.TrendWeekly)
+	assert.Equal(t, "2026-W32", weekly.Periods[0].Period)
+
+	filtered := filterMissions(loaded, day(8, 10, 0), "web-app-assessment")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "shop.example.com", filtered[0].Target)
+
+	out.Reset()
+	printTrends(&out, workflow.AnalyzeTrends(nil, workflow.TrendMonthly), workflow.TrendMonthly)
+	assert.Equal(t, "No archived missions.\n", out.String())
+}
@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// severities are the finding columns, worst first
+var severities = []workflow.Severity{
+	workflow.SeverityCritical,
+	workflow.SeverityHigh,
+	workflow.SeverityMedium,
+	workflow.SeverityLow,
+	workflow.SeverityInformational,
+}
+
+// loadMissions reads the archived missions, and the saved ones too when
+// active is set
+func loadMissions(workspace string, active bool) ([]*workflow.MissionState, error) {
+	states, err := workflow.LoadMissionStates(workspace, true)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		current, err := workflow.LoadMissionStates(workspace, false)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, current...)
+	}
+	return states, nil
+}
+
+// filterMissions keeps the missions started at or after since that ran
+// wfName; zero values match everything
+func filterMissions(states []*workflow.MissionState, since time.Time, wfName string) []*workflow.MissionState {
+	return slices.DeleteFunc(states, func(s *workflow.MissionState) bool {
+		return s.StartTime.Before(since) || (wfName != "" && s.WorkflowName != wfName)
+	})
+}
+
+func printTrends(w io.Writer, t *workflow.Trends, interval workflow.TrendInterval) {
+	if t.Missions == 0 {
+		fmt.Fprintln(w, "No archived missions.")
+		return
+	}
+	fmt.Fprintf(w, "Missions: %d  Findings: %d  Model spend: $%.2f  Per finding: $%.2f\n",
+		t.Missions, t.Findings, t.Cost, t.CostPerFinding())
+
+	fmt.Fprintf(w, "\nFindings by %s\n", interval)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"PERIOD", "MISSIONS"}
+	for _, sev := range severities {
+		label := strings.ToUpper(string(sev))
+		if sev == workflow.SeverityInformational {
+			label = "INFO"
+		}
+		header = append(header, label)
+	}
+	fmt.Fprintln(tw, strings.Join(append(header, "SPEND"), "\t"))
+	for _, p := range t.Periods {
+		row := []string{p.Period, fmt.Sprint(p.Missions)}
+		for _, sev := range severities {
+			row = append(row, fmt.Sprint(p.Findings[sev]))
+		}
+		fmt.Fprintln(tw, strings.Join(append(row, fmt.Sprintf("$%.2f", p.Cost)), "\t"))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nPhases (means per mission)")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKFLOW\tPHASE\tMISSIONS\tELAPSED\tEFFORT\tSPEND")
+	for _, p := range t.Phases {
+		elapsed := "-"
+		if p.Elapsed > 0 {
+			elapsed = workflow.FormatEffortDuration(p.Elapsed)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t$%.2f\n", p.Workflow, p.Phase, p.Missions, elapsed,
+			workflow.FormatEffortDuration(p.Effort), p.Cost)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nWorkflows")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKFLOW\tMISSIONS\tFINDINGS\tSPEND\tPER FINDING")
+	for _, wf := range t.Workflows {
+		perFinding := "-"
+		if wf.Findings > 0 {
+			perFinding = fmt.Sprintf("$%.2f", wf.CostPerFinding())
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t$%.2f\t%s\n", wf.Workflow, wf.Missions, wf.Findings, wf.Cost, perFinding)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nSupervisor reviews")
+	if len(t.Models) == 0 {
+		fmt.Fprintln(w, "None recorded. Reviews are kept when tier routing runs with supervision.")
+		return
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tREVIEWED\tREJECTED\tREJECTION RATE")
+	for _, m := range t.Models {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f%%\n", m.Model, m.Reviewed, m.Rejected, m.RejectionRate()*100)
+	}
+	tw.Flush()
+}
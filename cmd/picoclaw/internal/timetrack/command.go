@@ -0,0 +1,60 @@
+package timetrack
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+)
+
+func NewTimeCommand() *cobra.Command {
+	var (
+		target string
+		all    bool
+		csv    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "time",
+		Short: "Show operator and agent time per mission phase",
+		Long: `Show how much time a saved mission took, per phase, split into active
+operator time and autonomous agent time.
+
+Operator time is the time between the agent's reply and the operator's next
+message, unless the gap is over 15 minutes, plus time spent answering the
+agent's questions. Agent time is the time the agent spent working on its own.`,
+		Example: `  picoclaw time
+  picoclaw time --target 10.0.0.0/24
+  picoclaw time --all --csv > timesheet.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			states, err := loadMissions(cfg.WorkspacePath(), target, all)
+			if err != nil {
+				return err
+			}
+			if csv {
+				return writeCSV(os.Stdout, states)
+			}
+			for i, state := range states {
+				if i > 0 {
+					fmt.Println()
+				}
+				printEffort(os.Stdout, state)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Mission target (default: the most recently saved mission)")
+	cmd.Flags().BoolVar(&all, "all", false, "Show every saved mission")
+	cmd.Flags().BoolVar(&csv, "csv", false, "Write CSV with decimal hours, for timesheets")
+	cmd.MarkFlagsMutuallyExclusive("target", "all")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package timetrack
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func TestNewTimeCommand(t *testing.T) {
+	cmd := NewTimeCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "time", cmd.Use)
+	assert.Equal(t, "Show operator and agent time per mission phase", cmd.Short)
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for _, name := range []string{"target", "all", "csv"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing --%s", name)
+	}
+}
+
+func TestEffortTracking(t *testing.T) {
+	workspace := t.TempDir()
+	wf := &workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{{Name: "discovery"}, {Name: "enumeration"}}}
+	engine := workflow.NewEngine(wf, "10.0.0.1", workspace)
+	require.NoError(t, engine.SetMetadata(workflow.MetaClient, "ACME Corp"))
+
+	start := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// Kickoff, then 30 minutes of agent work with a 5 minute question
+	require.NoError(t, engine.OperatorMessage(at(0)))
+	require.NoError(t, engine.OperatorWaited(5*time.Minute))
+	require.NoError(t, engine.AgentTurn(at(0), at(30)))
+	// The operator replies after 10 minutes
+	require.NoError(t, engine.OperatorMessage(at(40)))
+	require.NoError(t, engine.AgentTurn(at(40), at(55)))
+	// Back from lunch: the gap is idle time
+	require.NoError(t, engine.OperatorMessage(at(145)))
+	require.NoError(t, engine.AdvancePhase())
+	require.NoError(t, engine.AgentTurn(at(145), at(205)))
+
+	states, err := loadMissions(workspace, "", false)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, []workflow.PhaseEffort{
+		{Phase: "discovery", Operator: 15 * time.Minute, Agent: 40 * time.Minute},
+		{Phase: "enumeration", Agent: time.Hour},
+	}, states[0].Effort())
+
+	var out bytes.Buffer
+	printEffort(&out, states[0])
+	assert.Contains(t, out.String(), "Client: ACME Corp")
+	assert.Contains(t, out.String(), "discovery    0h15m     0h40m  0h55m")
+	assert.Contains(t, out.String(), "Total        0h15m     1h40m  1h55m")
+
+	out.Reset()
+	require.NoError(t, writeCSV(&out, states))
+	assert.Equal(t, "client,engagement,mission,workflow,phase,operator_hours,agent_hours,total_hours\n"+
+		"ACME Corp,,10.0.0.1,network-scan,discovery,0.25,0.67,0.92\n"+
+		"ACME Corp,,10.0.0.1,network-scan,enumeration,0.00,1.00,1.00\n", out.String())
+
+	report := workflow.RenderReport(wf, states[0], workflow.ReportClient)
+	assert.Contains(t, report, "## Effort\n\n| Phase | Operator | Agent | Total |")
+	assert.Contains(t, report, "| Total | 0h15m | 1h40m | 1h55m |")
+
+	_, err = loadMissions(workspace, "10.9.9.9", false)
+	assert.ErrorContains(t, err, "no saved mission for target 10.9.9.9")
+}
@@ -0,0 +1,99 @@
+package timetrack
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// loadMissions reads the saved mission for target, every saved mission, or
+// the most recently saved one
+func loadMissions(workspace, target string, all bool) ([]*workflow.MissionState, error) {
+	var paths []string
+	if target != "" {
+		paths = []string{workflow.MissionStatePath(workspace, target)}
+	} else {
+		var err error
+		if paths, err = workflow.MissionStatePaths(workspace); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no saved missions in %s", filepath.Join(workspace, "missions"))
+		}
+		if !all {
+			paths = paths[:1]
+		}
+	}
+
+	states := make([]*workflow.MissionState, 0, len(paths))
+	for _, path := range paths {
+		state, err := workflow.ReadMissionState(path)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no saved mission for target %s", target)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func printEffort(w io.Writer, state *workflow.MissionState) {
+	fmt.Fprintf(w, "Mission: %s (%s)\n", missionName(state), state.WorkflowName)
+	if client, _ := state.Metadata[workflow.MetaClient].(string); client != "" {
+		fmt.Fprintf(w, "Client: %s\n", client)
+	}
+	if id, _ := state.Metadata[workflow.MetaEngagementID].(string); id != "" {
+		fmt.Fprintf(w, "Engagement: %s\n", id)
+	}
+
+	efforts := state.Effort()
+	total := workflow.TotalEffort(efforts)
+	if total.Total() == 0 {
+		fmt.Fprintln(w, "No time recorded.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tOPERATOR\tAGENT\tTOTAL")
+	for _, e := range append(efforts, total) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Phase, workflow.FormatEffortDuration(e.Operator),
+			workflow.FormatEffortDuration(e.Agent), workflow.FormatEffortDuration(e.Total()))
+	}
+	tw.Flush()
+}
+
+// writeCSV writes one row per mission phase, with times in decimal hours
+func writeCSV(w io.Writer, states []*workflow.MissionState) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"client", "engagement", "mission", "workflow", "phase", "operator_hours", "agent_hours", "total_hours"})
+	for _, state := range states {
+		client, _ := state.Metadata[workflow.MetaClient].(string)
+		id, _ := state.Metadata[workflow.MetaEngagementID].(string)
+		for _, e := range state.Effort() {
+			cw.Write([]string{client, id, missionName(state), state.WorkflowName, e.Phase,
+				hours(e.Operator), hours(e.Agent), hours(e.Total())})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func missionName(state *workflow.MissionState) string {
+	if state.Target != "" {
+		return state.Target
+	}
+	return state.WorkflowName
+}
+
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
@@ -0,0 +1,19 @@
+package tools
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewToolsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Check the agent's tools",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		newTestCommand(),
+	)
+
+	return cmd
+}
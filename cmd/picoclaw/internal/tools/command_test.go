@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	pkgtools "github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+)
+
+func TestNewToolsCommand(t *testing.T) {
+	cmd := NewToolsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "tools", cmd.Use)
+	assert.Equal(t, "Check the agent's tools", cmd.Short)
+	assert.True(t, cmd.HasSubCommands())
+
+	test, _, err := cmd.Find([]string{"test"})
+	require.NoError(t, err)
+	assert.Equal(t, "test [tool...]", test.Use)
+	assert.NotNil(t, test.RunE)
+	for _, name := range []string{"loopback", "timeout"} {
+		assert.NotNil(t, test.Flags().Lookup(name), "missing --%s", name)
+	}
+}
+
+func TestAgentTools(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+
+	registry, err := agentTools(cfg)
+	require.NoError(t, err)
+
+	results := registry.SelfTest(context.Background(), pkgtools.SelfTestOptions{}, "exec", "web_fetch", "encode")
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.NoError(t, r.Err, r.Tool)
+	}
+	assert.False(t, results[0].Tested, "encode has no self-test")
+	assert.True(t, results[1].Tested)
+	assert.True(t, results[2].Tested)
+}
+
+func TestPrintSelfTests(t *testing.T) {
+	results := []pkgtools.SelfTestResult{
+		{Tool: "encode"},
+		{Tool: "exec", Tested: true, Note: "shell runs commands in /work", Elapsed: 12 * time.Millisecond},
+		{Tool: "python", Tested: true, Err: errors.New("python3 not found")},
+		{Tool: "spi", Tested: true, Err: &pkgtools.NoHardwareError{Reason: "no SPI devices found"}},
+		{Tool: "web_search"},
+	}
+
+	var out bytes.Buffer
+	failed := printSelfTests(&out, results)
+
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, `TOOL    RESULT   TIME  DETAILS
+exec    ok       12ms  shell runs commands in /work
+python  FAILED   0s    python3 not found
+spi     skipped  0s    no SPI devices found
+
+No self-test: encode, web_search
+`, out.String())
+}
+
+func TestPrintProfiles(t *testing.T) {
+	var out bytes.Buffer
+	printProfiles(&out, &internal.ProfileReadiness{
+		ReadyProfiles:   []string{"port-scan"},
+		MissingProfiles: []string{"fuzz"},
+		ProfileTools:    map[string][]string{"port-scan": {"nmap", "naabu"}},
+	})
+
+	assert.Equal(t, `Security tool profiles
+port-scan  ready    nmap, naabu
+fuzz       missing  Try one of: ffuf, gobuster, feroxbuster, wfuzz
+`, out.String())
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	pkgtools "github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+)
+
+// agentTools returns the tools the default agent registers. Self-tests
+// never call a model, so no provider is set up.
+func agentTools(cfg *config.Config) (*pkgtools.ToolRegistry, error) {
+	loop := agent.NewAgentLoop(cfg, bus.NewMessageBus(), nil)
+	defaultAgent := loop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return nil, fmt.Errorf("no agent configured")
+	}
+	return defaultAgent.Tools, nil
+}
+
+// printSelfTests writes one line per self-test and the tools without one,
+// and returns how many failed
+func printSelfTests(w io.Writer, results []pkgtools.SelfTestResult) int {
+	var untested []string
+	failed := 0
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tRESULT\tTIME\tDETAILS")
+	for _, r := range results {
+		if !r.Tested && r.Err == nil {
+			untested = append(untested, r.Tool)
+			continue
+		}
+		status, details := "ok", r.Note
+		switch {
+		case r.NoHardware():
+			status, details = "skipped", r.Err.Error()
+		case r.Err != nil:
+			status, details = "FAILED", r.Err.Error()
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Tool, status, r.Elapsed.Round(time.Millisecond), details)
+	}
+	tw.Flush()
+
+	if len(untested) > 0 {
+		fmt.Fprintf(w, "\nNo self-test: %s\n", strings.Join(untested, ", "))
+	}
+	return failed
+}
+
+// printProfiles writes which security tool profiles have a binary installed
+func printProfiles(w io.Writer, readiness *internal.ProfileReadiness) {
+	fmt.Fprintln(w, "Security tool profiles")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range readiness.ReadyProfiles {
+		fmt.Fprintf(tw, "%s\tready\t%s\n", name, strings.Join(readiness.ProfileTools[name], ", "))
+	}
+	for _, name := range readiness.MissingProfiles {
+		fmt.Fprintf(tw, "%s\tmissing\t%s\n", name, internal.GetProfileGuidance(name).InstallHints[0])
+	}
+	tw.Flush()
+}
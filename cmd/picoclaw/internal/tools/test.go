@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	pkgtools "github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+)
+
+func newTestCommand() *cobra.Command {
+	var (
+		loopback bool
+		timeout  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test [tool...]",
+		Short: "Run the tools' self-tests",
+		Long: `Run the self-test of each tool the agent registers, or of the named tools,
+so a mission doesn't find out mid-phase that a sandbox, proxy or device is
+missing. Self-tests only touch mock targets and loopback hardware: exec runs
+a harmless command, web_fetch fetches a local page, python runs a one-line
+script, and i2c and spi open their devices.
+
+Without tool names it also reports which security tool profiles (port-scan,
+fuzz, ...) have a binary installed, e.g. nmap for port-scan.
+
+With --loopback, SPI devices are expected to have MOSI wired to MISO, and a
+test pattern is sent through each one. Hosts without I2C or SPI hardware
+report those tools as skipped rather than failed.`,
+		Example: `  picoclaw tools test
+  picoclaw tools test exec web_fetch
+  picoclaw tools test spi --loopback`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			registry, err := agentTools(cfg)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			results := registry.SelfTest(cmd.Context(), pkgtools.SelfTestOptions{Loopback: loopback, Timeout: timeout}, args...)
+			failed := printSelfTests(out, results)
+			if len(args) == 0 {
+				fmt.Fprintln(out)
+				printProfiles(out, internal.CollectProfileReadiness())
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d tool self-test(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&loopback, "loopback", false, "Send test data through SPI devices wired MOSI to MISO")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "Time limit for each self-test")
+
+	return cmd
+}
@@ -0,0 +1,21 @@
+package workflow
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewWorkflowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "Create, check and fetch workflow definitions",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		newInitCommand(),
+		newLintCommand(),
+		newFetchCommand(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgworkflow "github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const brokenWorkflow = `---
+name: broken
+phases: [recon, exploit, cleanup]
+---
+
+## Phase: recon
+
+### Steps
+
+- scan: Port scan (required)
+- scan: Service scan
+
+### Completion Criteria
+
+All required steps complete
+
+### Branches
+
+- web_found → Test the web app
+  phase: webapp
+
+## Phase: exploit
+
+### Steps
+
+- exploit: Exploit it (required)
+
+### Completion Criteria
+
+script: len(findings) >
+
+## Phase: cleanup
+`
+
+func TestNewWorkflowCommand(t *testing.T) {
+	cmd := NewWorkflowCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "workflow", cmd.Use)
+	assert.Equal(t, "Create, check and fetch workflow definitions", cmd.Short)
+
+	assert.True(t, cmd.HasSubCommands())
+
+	lint, _, err := cmd.Find([]string{"lint"})
+	require.NoError(t, err)
+	assert.Equal(t, "lint <file>...", lint.Use)
+	assert.NotNil(t, lint.RunE)
+	assert.NotNil(t, lint.Flags().Lookup("json"))
+
+	initCmd, _, err := cmd.Find([]string{"init"})
+	require.NoError(t, err)
+	assert.NotNil(t, initCmd.Flags().Lookup("name"))
+
+	fetch, _, err := cmd.Find([]string{"fetch"})
+	require.NoError(t, err)
+	for _, flag := range []string{"name", "sha256", "update", "force", "registry"} {
+		assert.NotNil(t, fetch.Flags().Lookup(flag), flag)
+	}
+}
+
+func TestLintFiles(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.md")
+	require.NoError(t, os.WriteFile(broken, []byte(brokenWorkflow), 0o644))
+
+	issues := lintFile(broken)
+	var checks []string
+	for _, issue := range issues {
+		checks = append(checks, issue.Check)
+	}
+	assert.Equal(t, []string{
+		"duplicate_step_id",
+		"unknown_target_phase",
+		"invalid_script",
+		"no_completion",
+		"empty_phase",
+	}, checks)
+	assert.Equal(t, "error: phase recon: branch web_found jumps to phase \"webapp\", which does not exist", issues[1].String())
+	assert.Equal(t, pkgworkflow.LevelWarning, issues[4].Level)
+
+	// The same validator rejects the workflow at load time
+	_, err := pkgworkflow.NewParser().ParseFile(broken)
+	var verr *pkgworkflow.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.True(t, verr.HasErrors())
+
+	results := lintFiles([]string{broken, filepath.Join("..", "..", "..", "..", "examples", "workflows", "network-scan.md"), filepath.Join(dir, "missing.md")})
+	assert.Empty(t, results[1].Issues)
+	require.Len(t, results[2].Issues, 1)
+	assert.Equal(t, "parse", results[2].Issues[0].Check)
+	assert.Equal(t, 4, countErrors(results))
+
+	var out bytes.Buffer
+	printResults(&out, results)
+	assert.Contains(t, out.String(), broken+": warning: phase cleanup: no steps or branches\n")
+	assert.Contains(t, out.String(), "network-scan.md: ok\n")
+}
+
+func TestInitTemplates(t *testing.T) {
+	templates, err := pkgworkflow.Templates()
+	require.NoError(t, err)
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	assert.Equal(t, []string{"api-testing", "external-network", "hardware-iot", "web-app-assessment"}, names)
+
+	// Every template lints clean once copied into the workspace
+	workspace := t.TempDir()
+	for _, name := range names {
+		path, err := pkgworkflow.InitTemplate(workspace, name, "", false)
+		require.NoError(t, err)
+		assert.Empty(t, lintFile(path), name)
+	}
+
+	_, err = pkgworkflow.InitTemplate(workspace, "web-app-assessment", "", false)
+	assert.ErrorContains(t, err, "--force")
+	_, err = pkgworkflow.InitTemplate(workspace, "web-app-assessment", "", true)
+	assert.NoError(t, err)
+	_, err = pkgworkflow.InitTemplate(workspace, "mobile", "", false)
+	assert.ErrorContains(t, err, `unknown workflow template "mobile"`)
+	_, err = pkgworkflow.InitTemplate(workspace, "api-testing", "../escape", false)
+	assert.Error(t, err)
+
+	// A custom name renames the workflow so it loads under that name
+	_, err = pkgworkflow.InitTemplate(workspace, "external-network", "acme-external", false)
+	require.NoError(t, err)
+	wf, err := pkgworkflow.LoadWorkflow(workspace, "acme-external")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-external", wf.Name)
+	assert.Len(t, wf.Phases, 5)
+
+	var out bytes.Buffer
+	printTemplates(&out, templates[:1])
+	assert.Equal(t, "api-testing          5 phases  REST and GraphQL API security testing following the OWASP API Security Top 10\n", out.String())
+}
+
+func TestLintFiles_Includes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name+".md")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	write("triage", "---\nname: triage\n---\n\n## Phase: fingerprint\n\n### Steps\n\n- headers: Grab headers\n")
+	parent := write("parent", "---\nname: parent\n---\n\n## Phase: web\n\ninclude: triage\n")
+	missing := write("missing-include", "---\nname: missing-include\n---\n\n## Phase: web\n\ninclude: mobile\n")
+
+	// Issues in the included workflow are reported under the included phase
+	issues := lintFile(parent)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "warning: phase web/fingerprint: no completion criteria; the phase only ends when advanced by hand", issues[0].String())
+
+	issues = lintFile(missing)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "include", issues[0].Check)
+	assert.Contains(t, issues[0].Message, "phase web:")
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
+	pkgworkflow "github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newFetchCommand() *cobra.Command {
+	var (
+		opts     pkgworkflow.FetchOptions
+		registry string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fetch [<registry-ref>|<url>|<git-url>//<file>[@<rev>]]",
+		Short: "Download shared workflows into the workspace",
+		Long: `Download a workflow into the workspace's workflows directory and pin it in
+` + pkgworkflow.LockfileName + `. A ref is one of:
+
+  name[@version]                      a workflow from the registry (workflows.registry)
+  https://host/path/workflow.md       a plain URL
+  https://host/org/repo.git//path.md  a file in a git repository, optionally @tag, @branch or @commit
+
+Downloads are checked against the registry's SHA-256, --sha256, or the
+lockfile's checksum when the workflow was fetched before. A URL or git ref
+without a known checksum is pinned on first use. Refetching a locked
+workflow gets the locked version unless --update is given.
+
+When trust.workflows is warn or require, the workflow's minisign signature
+(<file>.minisig next to it) must verify against a key in trust.keys; in
+require mode a workflow without one is not installed.
+
+Without a ref, every workflow in the lockfile is fetched at its locked
+version, so committing the lockfile lets a team share the same
+methodologies across machines.`,
+		Example: `  picoclaw workflow fetch web-app-assessment@1.2.0
+  picoclaw workflow fetch https://github.com/acme/methodology.git//workflows/api.md@v2
+  picoclaw workflow fetch --sha256 3f9a... https://example.com/workflows/ad.md
+  picoclaw workflow fetch`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			policy, err := trust.NewPolicy(cfg.Trust)
+			if err != nil {
+				return err
+			}
+			fetcher := &pkgworkflow.Fetcher{Registry: cfg.Workflows.Registry, Trust: policy}
+			if registry != "" {
+				fetcher.Registry = registry
+			}
+
+			if len(args) == 0 {
+				entries, err := fetcher.Sync(cmd.Context(), cfg.WorkspacePath())
+				for _, entry := range entries {
+					printFetched(os.Stdout, entry)
+				}
+				if err == nil && len(entries) == 0 {
+					fmt.Printf("No workflows in %s.\n", pkgworkflow.LockfileName)
+				}
+				return err
+			}
+
+			entry, err := fetcher.Fetch(cmd.Context(), cfg.WorkspacePath(), args[0], opts)
+			if err != nil {
+				return err
+			}
+			printFetched(os.Stdout, entry)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Install as workflows/<name>.md")
+	cmd.Flags().StringVar(&opts.SHA256, "sha256", "", "Expected SHA-256 of the workflow file")
+	cmd.Flags().BoolVar(&opts.Update, "update", false, "Fetch the latest version instead of the locked one")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite a workflow file that was not fetched")
+	cmd.Flags().StringVar(&registry, "registry", "", "Registry index URL (overrides workflows.registry)")
+
+	return cmd
+}
+
+func printFetched(w io.Writer, entry pkgworkflow.LockEntry) {
+	version := ""
+	if entry.Version != "" {
+		version = "@" + entry.Version
+	}
+	signed := ""
+	if entry.SignedBy != "" {
+		signed = ", signed by " + entry.SignedBy
+	}
+	fmt.Fprintf(w, "%s%s: workflows/%s.md (sha256 %s%s)\n", entry.Source, version, entry.Name, entry.SHA256, signed)
+}
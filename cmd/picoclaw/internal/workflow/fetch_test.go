@@ -0,0 +1,234 @@
+package workflow
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
+	pkgworkflow "github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func workflowVersion(version string) string {
+	return fmt.Sprintf(`---
+name: shared
+description: Shared methodology %s
+phases: [recon]
+---
+
+## Phase: recon
+
+### Steps
+
+- scan: Port scan (required)
+
+### Completion Criteria
+
+All required steps complete
+`, version)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchFromRegistryAndURL(t *testing.T) {
+	files := map[string]string{
+		"/v1.md":     workflowVersion("1.0.0"),
+		"/v1.10.md":  workflowVersion("1.10.0"),
+		"/plain.md":  workflowVersion("plain"),
+		"/broken.md": brokenWorkflow,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			fmt.Fprintf(w, `{"workflows": [
+				{"name": "shared", "version": "1.10.0", "url": "%[1]s/v1.10.md", "sha256": "%[2]s"},
+				{"name": "shared", "version": "1.0.0", "url": "%[1]s/v1.md", "sha256": "%[3]s"},
+				{"name": "tampered", "version": "1.0.0", "url": "%[1]s/v1.md", "sha256": "%[2]s"}
+			]}`, "http://"+r.Host, sha256Hex(files["/v1.10.md"]), sha256Hex(files["/v1.md"]))
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	fetcher := &pkgworkflow.Fetcher{Registry: srv.URL + "/index.json"}
+
+	// A pinned version, then the locked version again without one
+	entry, err := fetcher.Fetch(ctx, workspace, "shared@1.0.0", pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", entry.Version)
+	entry, err = fetcher.Fetch(ctx, workspace, "shared", pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", entry.Version, "refetch keeps the locked version")
+
+	// --update picks the highest version numerically
+	entry, err = fetcher.Fetch(ctx, workspace, "shared", pkgworkflow.FetchOptions{Update: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", entry.Version)
+	data, err := os.ReadFile(filepath.Join(workspace, "workflows", "shared.md"))
+	require.NoError(t, err)
+	assert.Equal(t, files["/v1.10.md"], string(data))
+
+	// Content that doesn't match the registry checksum is never installed
+	_, err = fetcher.Fetch(ctx, workspace, "tampered", pkgworkflow.FetchOptions{})
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, filepath.Join(workspace, "workflows", "tampered.md"))
+
+	// URLs are checked against --sha256, and against the lockfile afterwards
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/plain.md", pkgworkflow.FetchOptions{SHA256: sha256Hex("other")})
+	assert.ErrorContains(t, err, "checksum mismatch")
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/plain.md", pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	files["/plain.md"] = workflowVersion("changed upstream")
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/plain.md", pkgworkflow.FetchOptions{})
+	assert.ErrorContains(t, err, "checksum mismatch")
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/plain.md", pkgworkflow.FetchOptions{Update: true})
+	require.NoError(t, err)
+
+	// Invalid workflows and local files are left alone
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/broken.md", pkgworkflow.FetchOptions{})
+	assert.ErrorContains(t, err, "not a valid workflow")
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "workflows", "local.md"), []byte("mine"), 0o644))
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/v1.md", pkgworkflow.FetchOptions{Name: "local"})
+	assert.ErrorContains(t, err, "--force")
+
+	lock, err := pkgworkflow.ReadLockfile(workspace)
+	require.NoError(t, err)
+	require.Len(t, lock.Workflows, 2)
+	assert.Equal(t, "plain", lock.Workflows[0].Name)
+	assert.Equal(t, "shared", lock.Workflows[1].Name)
+
+	// A fresh workspace with the lockfile gets the same workflows
+	other := t.TempDir()
+	lockData, err := os.ReadFile(filepath.Join(workspace, pkgworkflow.LockfileName))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(other, pkgworkflow.LockfileName), lockData, 0o644))
+	synced, err := fetcher.Sync(ctx, other)
+	require.NoError(t, err)
+	assert.Len(t, synced, 2)
+	data, err = os.ReadFile(filepath.Join(other, "workflows", "shared.md"))
+	require.NoError(t, err)
+	assert.Equal(t, files["/v1.10.md"], string(data))
+
+	var out strings.Builder
+	printFetched(&out, synced[1])
+	assert.Equal(t, "shared@1.10.0: workflows/shared.md (sha256 "+sha256Hex(files["/v1.10.md"])+")\n", out.String())
+}
+
+// minisign signs content the way minisign -S does and returns the public
+// key line and the .minisig file
+func minisign(t *testing.T, content string) (string, string) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	id := []byte("keyid123")
+	sum := blake2b.Sum512([]byte(content))
+	sig := ed25519.Sign(priv, sum[:])
+	comment := "file:shared.md"
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), comment...))
+	enc := base64.StdEncoding.EncodeToString
+	return enc(append(append([]byte("Ed"), id...), pub...)),
+		fmt.Sprintf("untrusted comment: sig\n%s\ntrusted comment: %s\n%s\n",
+			enc(append(append([]byte("ED"), id...), sig...)), comment, enc(global))
+}
+
+func TestFetchVerifiesSignatures(t *testing.T) {
+	content := workflowVersion("signed")
+	key, sig := minisign(t, content)
+	files := map[string]string{"/shared.md": content, "/shared.md.minisig": sig, "/unsigned.md": content}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, ok := files[r.URL.Path]; ok {
+			fmt.Fprint(w, data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	policy, err := trust.NewPolicy(config.TrustConfig{
+		Keys:      []config.TrustedKeyConfig{{Name: "team", PublicKey: key}},
+		Workflows: "require",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	workspace := t.TempDir()
+	fetcher := &pkgworkflow.Fetcher{Trust: policy}
+
+	entry, err := fetcher.Fetch(ctx, workspace, srv.URL+"/shared.md", pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "team", entry.SignedBy)
+
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/unsigned.md", pkgworkflow.FetchOptions{})
+	assert.ErrorIs(t, err, trust.ErrUnsigned)
+	assert.NoFileExists(t, filepath.Join(workspace, "workflows", "unsigned.md"))
+
+	files["/shared.md"] = workflowVersion("tampered")
+	_, err = fetcher.Fetch(ctx, workspace, srv.URL+"/shared.md", pkgworkflow.FetchOptions{Update: true})
+	assert.ErrorIs(t, err, trust.ErrUntrusted)
+}
+
+func TestFetchFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := filepath.Join(t.TempDir(), "methodology.git")
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(version string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "workflows", "shared.md"), []byte(workflowVersion(version)), 0o644))
+		git("add", "-A")
+		git("commit", "--quiet", "-m", version)
+		return git("rev-parse", "HEAD")
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "workflows"), 0o755))
+	git("init", "--quiet")
+	first := commit("1")
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	fetcher := &pkgworkflow.Fetcher{}
+	ref := repo + "//workflows/shared.md"
+
+	entry, err := fetcher.Fetch(ctx, workspace, ref, pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "shared", entry.Name)
+	assert.Equal(t, first, entry.Version)
+
+	// The lockfile pins the commit even after the branch moves on
+	second := commit("2")
+	entry, err = fetcher.Fetch(ctx, workspace, ref, pkgworkflow.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, first, entry.Version)
+	entry, err = fetcher.Fetch(ctx, workspace, ref, pkgworkflow.FetchOptions{Update: true})
+	require.NoError(t, err)
+	assert.Equal(t, second, entry.Version)
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal"
+	pkgworkflow "github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newInitCommand() *cobra.Command {
+	var (
+		name  string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "init [<template>]",
+		Short: "Copy a built-in workflow template into the workspace",
+		Long: `Copy one of the built-in workflow templates into the workspace's workflows
+directory, where it can be edited like any other workflow. Without a
+template, list the templates.
+
+The copy is not tracked in ` + pkgworkflow.LockfileName + `; later picoclaw releases don't
+change it.`,
+		Example: `  picoclaw workflow init
+  picoclaw workflow init web-app-assessment
+  picoclaw workflow init --name acme-external external-network`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if len(args) == 0 {
+				templates, err := pkgworkflow.Templates()
+				if err != nil {
+					return err
+				}
+				printTemplates(os.Stdout, templates)
+				return nil
+			}
+
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			path, err := pkgworkflow.InitTemplate(cfg.WorkspacePath(), args[0], name, force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created %s. Edit it, then start a mission with it.\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Install as workflows/<name>.md and rename the workflow")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing workflow file")
+
+	return cmd
+}
+
+func printTemplates(w io.Writer, templates []pkgworkflow.TemplateInfo) {
+	for _, t := range templates {
+		fmt.Fprintf(w, "%-20s %d phases  %s\n", t.Name, t.Phases, t.Description)
+	}
+}
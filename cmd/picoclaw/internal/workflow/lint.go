@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	pkgworkflow "github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// lintResult is the outcome of linting one workflow file
+type lintResult struct {
+	File   string                        `json:"file"`
+	Issues []pkgworkflow.ValidationIssue `json:"issues"`
+}
+
+func newLintCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "lint <file>...",
+		Short: "Check workflow files for mistakes",
+		Long: `Check workflow files for duplicate phases and step IDs, branches that jump
+to phases that don't exist, phases without completion criteria or anything
+to do, and scripts that don't parse. Workflows a phase includes are looked
+up next to the file and checked as part of it.
+
+Errors stop a workflow from loading; warnings don't. The command fails if
+any file has errors.`,
+		Example: `  picoclaw workflow lint workflows/network-scan.md
+  picoclaw workflow lint --json workflows/*.md`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			results := lintFiles(args)
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else {
+				printResults(os.Stdout, results)
+			}
+			if n := countErrors(results); n > 0 {
+				return fmt.Errorf("%d error(s) found", n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the issues as JSON")
+
+	return cmd
+}
+
+func lintFiles(paths []string) []lintResult {
+	results := make([]lintResult, 0, len(paths))
+	for _, path := range paths {
+		results = append(results, lintResult{File: path, Issues: lintFile(path)})
+	}
+	return results
+}
+
+// lintFile returns every issue in the workflow at path, with the workflows
+// it includes from the same directory. Files that can't be read or parsed
+// at all get a single parse error, and includes that can't be resolved a
+// single include error.
+func lintFile(path string) []pkgworkflow.ValidationIssue {
+	parser := pkgworkflow.NewParser()
+	wf, err := parser.ParseFile(path)
+	if err == nil {
+		err = pkgworkflow.ResolveIncludes(wf, func(name string) (*pkgworkflow.Workflow, error) {
+			return parser.ParseFile(filepath.Join(filepath.Dir(path), name+".md"))
+		})
+		if err != nil {
+			return []pkgworkflow.ValidationIssue{{Level: pkgworkflow.LevelError, Check: "include", Message: err.Error()}}
+		}
+		err = pkgworkflow.Validate(wf)
+	}
+
+	var verr *pkgworkflow.ValidationError
+	switch {
+	case err == nil:
+		return []pkgworkflow.ValidationIssue{}
+	case errors.As(err, &verr):
+		return verr.Issues
+	default:
+		return []pkgworkflow.ValidationIssue{{Level: pkgworkflow.LevelError, Check: "parse", Message: err.Error()}}
+	}
+}
+
+func printResults(w io.Writer, results []lintResult) {
+	for _, result := range results {
+		if len(result.Issues) == 0 {
+			fmt.Fprintf(w, "%s: ok\n", result.File)
+			continue
+		}
+		for _, issue := range result.Issues {
+			fmt.Fprintf(w, "%s: %s\n", result.File, issue)
+		}
+	}
+}
+
+func countErrors(results []lintResult) int {
+	n := 0
+	for _, result := range results {
+		for _, issue := range result.Issues {
+			if issue.Level == pkgworkflow.LevelError {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -20,10 +20,19 @@ import (
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/cron"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/gateway"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/migrate"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/mission"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/monitor"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/onboard"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/routing"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/run"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/scope"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/skills"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/stats"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/status"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/timetrack"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/tools"
 	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/version"
+	"github.com/ResistanceIsUseless/picoclaw/cmd/picoclaw/internal/workflow"
 	pkgConfig "github.com/ResistanceIsUseless/picoclaw/pkg/config"
 )
 
@@ -39,14 +48,24 @@ func NewPicoclawCommand() *cobra.Command {
 	cmd.AddCommand(
 		onboard.NewOnboardCommand(),
 		agent.NewAgentCommand(),
+		run.NewRunCommand(),
 		claw.NewClawCommand(), // Structured security assessments (opt-in)
 		auth.NewAuthCommand(),
 		config.NewConfigCommand(),
+		routing.NewRoutingCommand(),
 		gateway.NewGatewayCommand(),
+		gateway.NewServeCommand(),
 		status.NewStatusCommand(),
+		monitor.NewMonitorCommand(),
+		scope.NewScopeCommand(),
+		workflow.NewWorkflowCommand(),
+		mission.NewMissionCommand(),
+		timetrack.NewTimeCommand(),
+		stats.NewStatsCommand(),
 		cron.NewCronCommand(),
 		migrate.NewMigrateCommand(),
 		skills.NewSkillsCommand(),
+		tools.NewToolsCommand(),
 		version.NewVersionCommand(),
 	)
 
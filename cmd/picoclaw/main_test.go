@@ -40,10 +40,20 @@ func TestNewPicoclawCommand(t *testing.T) {
 		"cron",
 		"gateway",
 		"migrate",
+		"mission",
+		"monitor",
 		"onboard",
+		"routing",
+		"run",
+		"scope",
+		"serve",
 		"skills",
+		"stats",
 		"status",
+		"time",
+		"tools",
 		"version",
+		"workflow",
 	}
 
 	subcommands := cmd.Commands()
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+)
+
+// argRepairLLM returns the LLM step of tool argument repair, or nil when
+// tools.arg_repair.llm is off. With tier routing the repair is a formatting
+// task, so it lands on the cheapest tier.
+func (al *AgentLoop) argRepairLLM(agent *AgentInstance, sessionKey string) providers.ArgRepairFunc {
+	if !al.cfg.Tools.ArgRepair.LLM {
+		return nil
+	}
+	return func(ctx context.Context, toolName, raw string, schema map[string]any) (string, error) {
+		messages := []providers.Message{{Role: "user", Content: providers.ArgRepairPrompt(toolName, raw, schema)}}
+		options := map[string]any{
+			"max_tokens":  1024,
+			"temperature": 0.0,
+		}
+		if schema != nil {
+			options[providers.ResponseSchemaOption] = &providers.ResponseSchema{
+				Name:        "tool_arguments",
+				Description: "Arguments for the " + toolName + " tool",
+				Schema:      schema,
+			}
+		}
+
+		var resp *providers.LLMResponse
+		var err error
+		if al.tierRouter != nil && al.tierRouter.IsEnabled() {
+			resp, err = al.tierRouter.RouteChat(ctx, routing.TaskFormatting, messages, nil, options, sessionKey)
+		} else {
+			resp, err = agent.Provider.Chat(ctx, messages, nil, agent.Model, options)
+		}
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/blackboard"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools/profiles"
 )
 
@@ -106,3 +107,26 @@ func resolveArtifactToolName(toolCallName string, args map[string]any) string {
 
 	return filepath.Base(first)
 }
+
+// storeToolResult keeps the full output of a large tool result so the model
+// can search it later with result_extract, and returns a note naming it
+func storeToolResult(agent *AgentInstance, toolName string, result *tools.ToolResult) string {
+	if agent.Results == nil || result.Async || toolName == "result_extract" {
+		return ""
+	}
+	output := result.RawOutput
+	if len(output) < tools.MinStoredResultChars {
+		return ""
+	}
+
+	id, err := agent.Results.Save(toolName, output)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to store tool result", map[string]any{
+			"tool":  toolName,
+			"error": err.Error(),
+		})
+		return ""
+	}
+	return fmt.Sprintf("[Full output stored as result %s (%d lines, %d chars); search it with result_extract]",
+		id, strings.Count(output, "\n")+1, len(output))
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// branchExcludedTools are left out of branch sub-agents: they steer the
+// mission, talk to the operator, start more agents or schedule later work,
+// all of which stay with the main agent
+var branchExcludedTools = map[string]bool{
+	"spawn":                true,
+	"subagent":             true,
+	"batch_submit":         true,
+	"cron":                 true,
+	"message":              true,
+	"ask_operator":         true,
+	"scope_change_request": true,
+	"pin":                  true,
+}
+
+// registerBranchTools lets each agent investigate branches in parallel
+// sub-agents. It runs after the tier router exists so every sub-agent gets
+// its own routed session, which is its cost sub-account.
+func registerBranchTools(registry *AgentRegistry, tierRouter *routing.TierRouter, msgBus *bus.MessageBus, cfg config.WorkflowsConfig) {
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil {
+			continue
+		}
+		getEngine := func() *workflow.Engine {
+			return agent.WorkflowEngine
+		}
+		agent.Tools.Register(tools.NewWorkflowInvestigateBranchTool(
+			getEngine,
+			branchAgent(agent, tierRouter),
+			msgBus,
+			cfg.MaxParallelBranches,
+			time.Duration(cfg.BranchTimeoutMinutes)*time.Minute,
+		))
+	}
+}
+
+// branchAgent runs a sub-agent on a delegated branch with a fresh context:
+// the branch brief and the agent's tools, minus the ones that steer the
+// mission, plus a workflow_add_finding that holds findings for the branch.
+// The mission's tool policy and scope still apply.
+func branchAgent(agent *AgentInstance, tierRouter *routing.TierRouter) tools.BranchAgent {
+	return func(ctx context.Context, condition, session, brief string) (tools.BranchRun, error) {
+		getEngine := func() *workflow.Engine {
+			return agent.WorkflowEngine
+		}
+		registry := tools.NewToolRegistry()
+		for _, name := range agent.Tools.List() {
+			if branchExcludedTools[name] || strings.HasPrefix(name, "workflow_") {
+				continue
+			}
+			if tool, ok := agent.Tools.Get(name); ok {
+				registry.Register(tool)
+			}
+		}
+		registry.Register(tools.NewWorkflowBranchFindingTool(getEngine, condition))
+		registry.SetPolicy(func() tools.ToolPolicy {
+			if engine := getEngine(); engine != nil {
+				return engine
+			}
+			return nil
+		})
+
+		provider := &branchProvider{
+			agent:      agent,
+			tierRouter: tierRouter,
+			session:    fmt.Sprintf("%s:%s", agent.ID, session),
+		}
+		costBefore, tokensBefore := provider.spend()
+
+		messages := []providers.Message{
+			{Role: "system", Content: brief},
+			{Role: "user", Content: "Investigate this branch now, then summarize what you checked and found."},
+		}
+		result, err := tools.RunToolLoop(ctx, tools.ToolLoopConfig{
+			Provider:      provider,
+			Model:         agent.Model,
+			Tools:         registry,
+			MaxIterations: agent.MaxIterations,
+			LLMOptions: map[string]any{
+				"max_tokens":  agent.MaxTokens,
+				"temperature": agent.Temperature,
+			},
+		}, messages, "system", session)
+
+		run := tools.BranchRun{Tokens: provider.tokens}
+		if provider.routed() {
+			cost, tokens := provider.spend()
+			run.CostUSD, run.Tokens = cost-costBefore, tokens-tokensBefore
+		}
+		if err != nil {
+			return run, err
+		}
+		run.Summary = result.Content
+		return run, nil
+	}
+}
+
+// branchProvider sends a branch sub-agent's calls through the tier router
+// under its own session when routing is enabled, otherwise to the agent's
+// own model. A sub-agent makes one call at a time.
+type branchProvider struct {
+	agent      *AgentInstance
+	tierRouter *routing.TierRouter
+	session    string
+	turns      int
+	tokens     int // Counted from responses, for spend the router doesn't track
+}
+
+func (p *branchProvider) routed() bool {
+	return p.tierRouter != nil && p.tierRouter.IsEnabled()
+}
+
+// spend returns the model spend and tokens the tier router has recorded for
+// the sub-agent's session so far
+func (p *branchProvider) spend() (float64, int) {
+	if !p.routed() {
+		return 0, 0
+	}
+	session := p.tierRouter.GetCostTracker().GetSessionCost(p.session)
+	if session == nil {
+		return 0, 0
+	}
+	tokens := 0
+	for _, model := range session.ByModel {
+		tokens += model.InputTokens + model.OutputTokens
+	}
+	return session.TotalCost, tokens
+}
+
+func (p *branchProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	defs []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	p.turns++
+
+	var resp *providers.LLMResponse
+	var err error
+	if p.routed() {
+		task := p.tierRouter.ClassifyTask(routing.AgentContext{
+			TurnCount:      p.turns,
+			ToolsAvailable: len(defs),
+			SessionStarted: p.turns == 1,
+		})
+		routeCtx := routing.WithCostAttribution(ctx, missionPhase(p.agent.WorkflowEngine), "workflow_investigate_branch")
+		resp, err = p.tierRouter.RouteChat(routeCtx, task, messages, defs, options, p.session)
+	} else {
+		resp, err = p.agent.Provider.Chat(ctx, messages, defs, model, options)
+	}
+	if err == nil && resp != nil && resp.Usage != nil {
+		p.tokens += resp.Usage.TotalTokens
+	}
+	return resp, err
+}
+
+func (p *branchProvider) GetDefaultModel() string {
+	return p.agent.Model
+}
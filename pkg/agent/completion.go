@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// registerCompletionJudge lets workflow_advance_phase ask a model whether a
+// phase's custom completion criteria are met. It runs after the tier router
+// exists so the question can go to the supervisor tier.
+func registerCompletionJudge(registry *AgentRegistry, tierRouter *routing.TierRouter, cfg config.CompletionJudgeConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil {
+			continue
+		}
+		tool, ok := agent.Tools.Get("workflow_advance_phase")
+		if !ok {
+			continue
+		}
+		advance, ok := tool.(*tools.WorkflowAdvancePhaseTool)
+		if !ok {
+			continue
+		}
+		advance.SetCompletionEvaluator(workflow.NewCompletionEvaluator(completionJudge(agent, tierRouter), cfg.MinConfidence))
+	}
+}
+
+// completionJudge sends completion prompts to the supervisor tier when
+// routing is enabled, otherwise to the agent's own model. Verdicts should be
+// repeatable, so sampling stays cold.
+func completionJudge(agent *AgentInstance, tierRouter *routing.TierRouter) workflow.CompletionJudge {
+	return func(ctx context.Context, prompt string) (string, error) {
+		messages := []providers.Message{{Role: "user", Content: prompt}}
+		options := map[string]any{
+			"max_tokens":  512,
+			"temperature": 0.0,
+		}
+
+		var resp *providers.LLMResponse
+		var err error
+		if tierRouter != nil && tierRouter.IsEnabled() {
+			routeCtx := routing.WithCostAttribution(ctx, missionPhase(agent.WorkflowEngine), "workflow_advance_phase")
+			resp, err = tierRouter.RouteToSupervisor(routeCtx, messages, options, "completion:"+agent.ID)
+		} else {
+			resp, err = agent.Provider.Chat(ctx, messages, nil, agent.Model, options)
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(resp.Content), nil
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/skills"
@@ -116,6 +117,11 @@ The following skills extend your capabilities. To use a skill, read its SKILL.md
 %s`, skillsSummary))
 	}
 
+	// Pinned facts (scope, credentials policy, contact rules) - never compacted
+	if pinned := cb.memory.GetPinnedContext(); pinned != "" {
+		parts = append(parts, pinned)
+	}
+
 	// Memory context
 	memoryContext := cb.memory.GetMemoryContext()
 	if memoryContext != "" {
@@ -201,6 +207,7 @@ func (cb *ContextBuilder) sourcePaths() []string {
 		filepath.Join(cb.workspace, "USER.md"),
 		filepath.Join(cb.workspace, "IDENTITY.md"),
 		filepath.Join(cb.workspace, "memory", "MEMORY.md"),
+		filepath.Join(cb.workspace, "memory", "PINNED.md"),
 	}
 }
 
@@ -215,10 +222,8 @@ type cacheBaseline struct {
 // the latest mtime across all tracked files + skills directory contents.
 // Called under write lock when the cache is built.
 func (cb *ContextBuilder) buildCacheBaseline() cacheBaseline {
-	skillsDir := filepath.Join(cb.workspace, "skills")
-
-	// All paths whose existence we track: source files + skills dir.
-	allPaths := append(cb.sourcePaths(), skillsDir)
+	// All paths whose existence we track: source files + watched dirs.
+	allPaths := append(cb.sourcePaths(), cb.watchedDirs()...)
 
 	existed := make(map[string]bool, len(allPaths))
 	var maxMtime time.Time
@@ -234,14 +239,16 @@ func (cb *ContextBuilder) buildCacheBaseline() cacheBaseline {
 	// Walk skills files to capture their mtimes too.
 	// Use os.Stat (not d.Info) to match the stat method used in
 	// fileChangedSince / skillFilesModifiedSince for consistency.
-	_ = filepath.WalkDir(skillsDir, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr == nil && !d.IsDir() {
-			if info, err := os.Stat(path); err == nil && info.ModTime().After(maxMtime) {
-				maxMtime = info.ModTime()
+	for _, dir := range cb.watchedDirs() {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr == nil && !d.IsDir() {
+				if info, err := os.Stat(path); err == nil && info.ModTime().After(maxMtime) {
+					maxMtime = info.ModTime()
+				}
 			}
-		}
-		return nil
-	})
+			return nil
+		})
+	}
 
 	// If no tracked files exist yet (empty workspace), maxMtime is zero.
 	// Use a very old non-zero time so that:
@@ -275,27 +282,37 @@ func (cb *ContextBuilder) sourceFilesChangedLocked() bool {
 		}
 	}
 
-	// --- Skills directory (handled separately from sourcePaths) ---
-	//
-	// 1. Creation/deletion: tracked via existedAtCache, same as bootstrap files.
-	skillsDir := filepath.Join(cb.workspace, "skills")
-	if cb.fileChangedSince(skillsDir) {
-		return true
-	}
+	// --- Watched directories (handled separately from sourcePaths) ---
+	for _, dir := range cb.watchedDirs() {
+		// 1. Creation/deletion: tracked via existedAtCache, same as bootstrap files.
+		if cb.fileChangedSince(dir) {
+			return true
+		}
 
-	// 2. Structural changes (add/remove entries inside the dir) are reflected
-	//    in the directory's own mtime, which fileChangedSince already checks.
-	//
-	// 3. Content-only edits to files inside skills/ do NOT update the parent
-	//    directory mtime on most filesystems, so we recursively walk to check
-	//    individual file mtimes at any nesting depth.
-	if skillFilesModifiedSince(skillsDir, cb.cachedAt) {
-		return true
+		// 2. Structural changes (add/remove entries inside the dir) are reflected
+		//    in the directory's own mtime, which fileChangedSince already checks.
+		//
+		// 3. Content-only edits to files inside the dir do NOT update the parent
+		//    directory mtime on most filesystems, so we recursively walk to check
+		//    individual file mtimes at any nesting depth.
+		if skillFilesModifiedSince(dir, cb.cachedAt) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// watchedDirs returns directories whose contents feed the system prompt:
+// skills, and mission state (the workflow context, including target aliases,
+// is rebuilt whenever the engine saves state).
+func (cb *ContextBuilder) watchedDirs() []string {
+	return []string{
+		filepath.Join(cb.workspace, "skills"),
+		filepath.Join(cb.workspace, "missions"),
+	}
+}
+
 // fileChangedSince returns true if a tracked source file has been modified,
 // newly created, or deleted since the cache was built.
 //
@@ -382,7 +399,7 @@ func (cb *ContextBuilder) LoadBootstrapFiles() string {
 // See: https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching
 // See: https://platform.openai.com/docs/guides/prompt-caching
 func (cb *ContextBuilder) buildDynamicContext(channel, chatID string) string {
-	now := time.Now().Format("2006-01-02 15:04 (Monday)")
+	now := determinism.Now().Format("2006-01-02 15:04 (Monday)")
 	rt := fmt.Sprintf("%s %s, Go %s", runtime.GOOS, runtime.GOARCH, runtime.Version())
 
 	var sb strings.Builder
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
 // setupWorkspace creates a temporary workspace with standard directories and optional files.
@@ -383,6 +384,35 @@ Updated content.`
 	}
 }
 
+// TestMissionStateChangeInvalidatesCache verifies that saving mission state
+// (here, adding a target alias) refreshes the cached workflow context.
+func TestMissionStateChangeInvalidatesCache(t *testing.T) {
+	tmpDir := setupWorkspace(t, nil)
+	defer os.RemoveAll(tmpDir)
+
+	wf := &workflow.Workflow{Name: "web-recon", Phases: []workflow.Phase{{Name: "discovery"}}}
+	engine := workflow.NewEngine(wf, "example.com", tmpDir)
+
+	cb := NewContextBuilder(tmpDir)
+	cb.SetWorkflowContextFunc(engine.GetContextPrompt)
+
+	if sp := cb.BuildSystemPromptWithCache(); strings.Contains(sp, "Target Aliases") {
+		t.Fatal("unexpected aliases before any were set")
+	}
+
+	if err := engine.SetAlias("The Admin Portal", "https://admin.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	sp := cb.BuildSystemPromptWithCache()
+	if !strings.Contains(sp, "- the admin portal → https://admin.example.com") {
+		t.Errorf("cached prompt missing alias after state save:\n%s", sp)
+	}
+	if got := engine.ResolveAlias("the  ADMIN portal"); got != "https://admin.example.com" {
+		t.Errorf("ResolveAlias() = %q", got)
+	}
+}
+
 // TestConcurrentBuildSystemPromptWithCache verifies that multiple goroutines
 // can safely call BuildSystemPromptWithCache concurrently without producing
 // empty results, panics, or data races.
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// recordOperatorMessage counts the operator's time since the agent last
+// replied towards the mission's effort
+func recordOperatorMessage(agent *AgentInstance, at time.Time) {
+	if agent.WorkflowEngine == nil {
+		return
+	}
+	if err := agent.WorkflowEngine.OperatorMessage(at); err != nil {
+		logger.WarnCF("agent", "Failed to record operator time", map[string]any{"error": err.Error()})
+	}
+}
+
+// recordAgentTurn counts a turn that started at start towards the mission's
+// autonomous agent time
+func recordAgentTurn(agent *AgentInstance, start time.Time) {
+	if agent.WorkflowEngine == nil {
+		return
+	}
+	if err := agent.WorkflowEngine.AgentTurn(start, time.Now()); err != nil {
+		logger.WarnCF("agent", "Failed to record agent time", map[string]any{"error": err.Error()})
+	}
+}
+
+// sessionSpend returns the model spend and tokens the tier router has
+// recorded for the session so far
+func (al *AgentLoop) sessionSpend(sessionKey string) (float64, int) {
+	if al.tierRouter == nil {
+		return 0, 0
+	}
+	session := al.tierRouter.GetCostTracker().GetSessionCost(sessionKey)
+	if session == nil {
+		return 0, 0
+	}
+	tokens := 0
+	for _, model := range session.ByModel {
+		tokens += model.InputTokens + model.OutputTokens
+	}
+	return session.TotalCost, tokens
+}
+
+// recordTurnCost counts the spend since costBefore and tokensBefore towards
+// the phase the mission is in when the turn ends
+func (al *AgentLoop) recordTurnCost(agent *AgentInstance, sessionKey string, costBefore float64, tokensBefore int) {
+	if agent.WorkflowEngine == nil {
+		return
+	}
+	cost, tokens := al.sessionSpend(sessionKey)
+	if err := agent.WorkflowEngine.RecordCost(cost-costBefore, tokens-tokensBefore); err != nil {
+		logger.WarnCF("agent", "Failed to record mission cost", map[string]any{"error": err.Error()})
+	}
+}
+
+// sessionReviews returns the supervisor verdicts per worker model the tier
+// router has recorded for the session so far
+func (al *AgentLoop) sessionReviews(sessionKey string) map[string]workflow.ModelReviews {
+	if al.tierRouter == nil {
+		return nil
+	}
+	session := al.tierRouter.GetCostTracker().GetSessionCost(sessionKey)
+	if session == nil {
+		return nil
+	}
+	reviews := make(map[string]workflow.ModelReviews)
+	for name, model := range session.ByModel {
+		if model.Reviewed > 0 {
+			reviews[name] = workflow.ModelReviews{Reviewed: model.Reviewed, Rejected: model.Rejected}
+		}
+	}
+	return reviews
+}
+
+// recordTurnReviews counts the verdicts since before towards the mission
+func (al *AgentLoop) recordTurnReviews(agent *AgentInstance, sessionKey string, before map[string]workflow.ModelReviews) {
+	if agent.WorkflowEngine == nil {
+		return
+	}
+	delta := make(map[string]workflow.ModelReviews)
+	for name, r := range al.sessionReviews(sessionKey) {
+		prev := before[name]
+		if r.Reviewed > prev.Reviewed {
+			delta[name] = workflow.ModelReviews{Reviewed: r.Reviewed - prev.Reviewed, Rejected: r.Rejected - prev.Rejected}
+		}
+	}
+	if err := agent.WorkflowEngine.RecordReviews(delta); err != nil {
+		logger.WarnCF("agent", "Failed to record supervisor reviews", map[string]any{"error": err.Error()})
+	}
+}
+
+// timedAsker wraps asker so the time the operator takes to answer counts as
+// operator rather than agent time
+func timedAsker(agent *AgentInstance, asker tools.OperatorAsker) tools.OperatorAsker {
+	if asker == nil {
+		return nil
+	}
+	return func(ctx context.Context, q tools.OperatorQuestion) (string, error) {
+		start := time.Now()
+		answer, err := asker(ctx, q)
+		if engine := agent.WorkflowEngine; engine != nil {
+			if werr := engine.OperatorWaited(time.Since(start)); werr != nil {
+				logger.WarnCF("agent", "Failed to record operator time", map[string]any{"error": werr.Error()})
+			}
+		}
+		return answer, err
+	}
+}
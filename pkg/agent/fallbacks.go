@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/registry"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// toolFallbacks is the built-in fallback matrix with the configured entries
+// laid over it
+func toolFallbacks(cfg *config.Config) workflow.FallbackMatrix {
+	matrix := workflow.DefaultFallbacks()
+	if cfg == nil || len(cfg.Workflows.Fallbacks) == 0 {
+		return matrix
+	}
+	overrides := make(workflow.FallbackMatrix, len(cfg.Workflows.Fallbacks))
+	for tool, entries := range cfg.Workflows.Fallbacks {
+		fallbacks := make([]workflow.ToolFallback, 0, len(entries))
+		for _, entry := range entries {
+			fallbacks = append(fallbacks, workflow.ToolFallback{Tool: entry.Tool, Hint: entry.Hint})
+		}
+		overrides[tool] = fallbacks
+	}
+	return matrix.Merge(overrides)
+}
+
+// toolAvailable reports whether name is one of the agent's tools or a
+// program exec can run
+func (ai *AgentInstance) toolAvailable(name string) bool {
+	if _, ok := ai.Tools.Get(name); ok {
+		return true
+	}
+	_, err := registry.GetToolPath(name)
+	return err == nil
+}
+
+// missingToolNote explains an exec call that failed because a program isn't
+// installed, naming what to use instead, so the agent doesn't keep retrying
+func missingToolNote(agent *AgentInstance, toolName string, args map[string]any, result *tools.ToolResult) string {
+	if toolName != "exec" || !result.IsError || agent.WorkflowEngine == nil {
+		return ""
+	}
+	command, _ := args["command"].(string)
+
+	var notFound []string
+	for _, name := range tools.CommandNames(command) {
+		if strings.Contains(result.ForLLM, name+": not found") || strings.Contains(result.ForLLM, name+": command not found") {
+			notFound = append(notFound, name)
+		}
+	}
+	if len(notFound) == 0 {
+		return ""
+	}
+
+	var notes []string
+	for _, missing := range agent.WorkflowEngine.CheckTools(notFound...) {
+		notes = append(notes, missing.Advice())
+	}
+	return strings.Join(notes, "\n")
+}
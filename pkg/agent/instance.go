@@ -1,11 +1,13 @@
 package agent
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/integration"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
@@ -26,14 +28,18 @@ type AgentInstance struct {
 	MaxTokens       int
 	Temperature     float64
 	ContextWindow   int
+	Vision          bool // Tool images are attached to tool results
 	Provider        providers.LLMProvider
 	Sessions        *session.SessionManager
 	ContextBuilder  *ContextBuilder
 	Tools           *tools.ToolRegistry
+	Results         *tools.ResultStore // Full output of large tool results, by ID
 	Subagents       *config.SubagentsConfig
 	SkillsFilter    []string
 	Candidates      []providers.FallbackCandidate
 	WorkflowEngine  *workflow.Engine           // Optional workflow/mission state
+	ToolFallbacks   workflow.FallbackMatrix    // Stand-ins for missing tools, offered by missions
+	KeepRevisions   int                        // Mission state snapshots kept for rollback; 0 = workflow.DefaultKeepRevisions
 	CLAWAdapter     *integration.CLAWAdapter   // Optional CLAW orchestrator adapter
 }
 
@@ -89,6 +95,9 @@ func NewAgentInstance(
 	temperature := 0.7
 	if defaults.Temperature != nil {
 		temperature = *defaults.Temperature
+	} else if determinism.Enabled() {
+		// Seeded runs sample greedily unless a temperature is configured
+		temperature = 0
 	}
 
 	// Context window: use configured value, or default to 128k tokens.
@@ -122,14 +131,18 @@ func NewAgentInstance(
 		MaxTokens:      maxTokens,
 		Temperature:    temperature,
 		ContextWindow:  contextWindow,
+		Vision:         resolveAgentVision(cfg, model),
 		Provider:       provider,
 		Sessions:       sessionsManager,
 		ContextBuilder: contextBuilder,
 		Tools:          toolsRegistry,
+		Results:        tools.NewResultStore(filepath.Join(workspace, tools.ResultsDir)),
 		Subagents:      subagents,
 		SkillsFilter:   skillsFilter,
 		Candidates:     candidates,
 		CLAWAdapter:    clawAdapter,
+		ToolFallbacks:  toolFallbacks(cfg),
+		KeepRevisions:  keepRevisions(cfg),
 	}
 }
 
@@ -154,6 +167,25 @@ func resolveAgentModel(agentCfg *config.AgentConfig, defaults *config.AgentDefau
 	return defaults.GetModelName()
 }
 
+// resolveAgentVision reports whether the agent's model takes image input.
+// With tier routing on, any vision-enabled model in model_list counts: the
+// router strips images before they reach models without vision.
+func resolveAgentVision(cfg *config.Config, model string) bool {
+	if cfg == nil {
+		return false
+	}
+	for _, m := range cfg.ModelList {
+		if m.Vision == nil || !*m.Vision {
+			continue
+		}
+		_, modelID := providers.ExtractProtocol(m.Model)
+		if cfg.Routing.Enabled || m.ModelName == model || modelID == model {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveAgentFallbacks resolves the fallback models for an agent.
 func resolveAgentFallbacks(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) []string {
 	if agentCfg != nil && agentCfg.Model != nil && agentCfg.Model.Fallbacks != nil {
@@ -178,15 +210,22 @@ func expandHome(path string) string {
 
 // LoadWorkflow loads a workflow definition and creates a new mission.
 // Returns error if workflow cannot be loaded or mission already exists for target.
+// Pins of the previous mission are cleared, so one engagement's rules never
+// reach another's prompt.
 func (ai *AgentInstance) LoadWorkflow(workflowName string, target string) error {
 	// Load workflow definition
 	wf, err := workflow.LoadWorkflow(ai.Workspace, workflowName)
 	if err != nil {
 		return err
 	}
+	if err := ai.ContextBuilder.memory.ClearPins(); err != nil {
+		return fmt.Errorf("failed to clear pins of the previous mission: %w", err)
+	}
 
 	// Create new workflow engine
+	ai.stopBranches()
 	ai.WorkflowEngine = workflow.NewEngine(wf, target, ai.Workspace)
+	ai.configureMission()
 
 	// Wire up workflow context injection
 	ai.ContextBuilder.SetWorkflowContextFunc(func() string {
@@ -216,7 +255,9 @@ func (ai *AgentInstance) LoadExistingMission(workflowName string, stateFile stri
 		return err
 	}
 
+	ai.stopBranches()
 	ai.WorkflowEngine = engine
+	ai.configureMission()
 
 	// Wire up workflow context injection
 	ai.ContextBuilder.SetWorkflowContextFunc(func() string {
@@ -232,8 +273,36 @@ func (ai *AgentInstance) LoadExistingMission(workflowName string, stateFile stri
 	return nil
 }
 
+// configureMission applies the agent's mission settings to a newly loaded
+// engine
+func (ai *AgentInstance) configureMission() {
+	ai.WorkflowEngine.SetToolFallbacks(ai.ToolFallbacks, ai.toolAvailable)
+	ai.WorkflowEngine.SetKeepRevisions(ai.KeepRevisions)
+}
+
+// stopBranches cancels the branch sub-agents of the current mission
+func (ai *AgentInstance) stopBranches() {
+	if ai.Tools == nil {
+		return
+	}
+	if tool, ok := ai.Tools.Get("workflow_investigate_branch"); ok {
+		if branches, ok := tool.(*tools.WorkflowInvestigateBranchTool); ok {
+			branches.StopBranches()
+		}
+	}
+}
+
+func keepRevisions(cfg *config.Config) int {
+	if cfg == nil {
+		return 0
+	}
+	return cfg.Workflows.KeepRevisions
+}
+
 // UnloadWorkflow clears the workflow engine and stops injecting workflow context.
+// Branch sub-agents still running for the mission are stopped.
 func (ai *AgentInstance) UnloadWorkflow() {
+	ai.stopBranches()
 	ai.WorkflowEngine = nil
 	ai.ContextBuilder.SetWorkflowContextFunc(nil)
 	ai.ContextBuilder.InvalidateCache()
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
 func TestNewAgentInstance_UsesDefaultsTemperatureAndMaxTokens(t *testing.T) {
@@ -93,3 +95,43 @@ func TestNewAgentInstance_DefaultsTemperatureWhenUnset(t *testing.T) {
 		t.Fatalf("Temperature = %f, want %f", agent.Temperature, 0.7)
 	}
 }
+
+func TestMissingToolNote_ExecNotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{Workspace: tmpDir, Model: "test-model"},
+		},
+	}
+	cfg.Workflows.Fallbacks = map[string][]config.ToolFallbackConfig{
+		"picoclaw-missing-scanner": {{Tool: "exec", Hint: "probe the ports by hand"}},
+	}
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	if _, ok := agent.ToolFallbacks["nuclei"]; !ok {
+		t.Fatalf("built-in fallbacks lost: %v", agent.ToolFallbacks)
+	}
+
+	wf, err := workflow.NewParser().Parse("---\nname: scan\nphases: [scan]\n---\n\n## Phase: scan\n\n### Steps\n\n- ports: Scan (required)\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	args := map[string]any{"command": "picoclaw-missing-scanner 10.0.0.1 | grep open"}
+	failed := &tools.ToolResult{
+		ForLLM:  "sh: 1: picoclaw-missing-scanner: not found\n\nExit code: exit status 127",
+		IsError: true,
+	}
+	if note := missingToolNote(agent, "exec", args, failed); note != "" {
+		t.Errorf("note without a mission = %q", note)
+	}
+
+	agent.WorkflowEngine = workflow.NewEngine(wf, "10.0.0.1", tmpDir)
+	agent.configureMission()
+	want := "**picoclaw-missing-scanner** is not available. Use **exec** instead: probe the ports by hand. Coverage is reduced, so say so in findings and notes."
+	if note := missingToolNote(agent, "exec", args, failed); note != want {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+	other := &tools.ToolResult{ForLLM: "connection refused\n\nExit code: exit status 1", IsError: true}
+	if note := missingToolNote(agent, "exec", args, other); note != "" {
+		t.Errorf("note for an unrelated failure = %q", note)
+	}
+}
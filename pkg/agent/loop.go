@@ -9,13 +9,14 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
-	"unicode/utf8"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/blackboard"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
@@ -24,12 +25,16 @@ import (
 	"github.com/ResistanceIsUseless/picoclaw/pkg/constants"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/integration"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/pipeline"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 	metadataregistry "github.com/ResistanceIsUseless/picoclaw/pkg/registry"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/secrets"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/skills"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/state"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/utils"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
@@ -46,6 +51,13 @@ type AgentLoop struct {
 	tierRouter     *routing.TierRouter // Optional tier-based routing
 	blackboard     *blackboard.Blackboard
 	toolMetadata   *metadataregistry.ToolRegistry
+	onToolImages   func(toolName string, paths []string)
+	sessionVars    *secrets.Store // Encrypted per-session variables set with /set
+	argRepairer    *providers.ArgRepairer
+
+	// Supervisor corrections awaiting the end of the turn: session key ->
+	// providers.Message, appended to history after the final answer
+	supervisorFeedback sync.Map
 }
 
 // processOptions configures how a message is processed
@@ -73,8 +85,13 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 	// Create state manager using default agent's workspace for channel recording
 	defaultAgent := registry.GetDefaultAgent()
 	var stateManager *state.Manager
+	var sessionVars *secrets.Store
 	if defaultAgent != nil {
 		stateManager = state.NewManager(defaultAgent.Workspace)
+		sessionVars = secrets.NewStore(
+			filepath.Join(defaultAgent.Workspace, "state", "session_vars.enc"),
+			secrets.DefaultKeyPath(),
+		)
 	}
 
 	// Initialize tier router if routing is enabled
@@ -83,12 +100,17 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 		// Build provider map from model_list
 		providerMap := buildProviderMap(cfg, provider)
 		tierRouter = routing.NewTierRouter(&cfg.Routing, cfg.ModelList, providerMap)
+		tierRouter.SetPipelines(pipeline.Build(cfg.Routing.Pipelines, cfg.WorkspacePath()))
 		logger.InfoCF("agent", "Tier routing enabled", map[string]any{
 			"tiers":        len(cfg.Routing.Tiers),
 			"default_tier": cfg.Routing.DefaultTier,
 		})
 	}
 
+	registerReportTools(registry, tierRouter)
+	registerCompletionJudge(registry, tierRouter, cfg.Workflows.CompletionJudge)
+	registerBranchTools(registry, tierRouter, msgBus, cfg.Workflows)
+
 	bb := blackboard.New(nil)
 	metadataRegistry := metadataregistry.NewToolRegistry()
 	if err := metadataregistry.RegisterAllTools(metadataRegistry); err != nil {
@@ -107,17 +129,27 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 		})
 	}
 
-	return &AgentLoop{
+	al := &AgentLoop{
 		bus:          msgBus,
 		cfg:          cfg,
 		registry:     registry,
 		state:        stateManager,
+		sessionVars:  sessionVars,
 		summarizing:  sync.Map{},
 		fallback:     fallbackChain,
 		tierRouter:   tierRouter,
 		blackboard:   bb,
 		toolMetadata: metadataRegistry,
+		argRepairer:  providers.NewArgRepairer(),
 	}
+
+	if tierRouter != nil && cfg.Preamble.Enabled {
+		tierRouter.SetPreambleFunc(func(sessionKey, modelName string) string {
+			return al.preambleFor(al.agentForSession(sessionKey), al.modelProtocol(modelName))
+		})
+	}
+
+	return al
 }
 
 // buildProviderMap creates a map of model_name -> provider for tier routing
@@ -186,16 +218,25 @@ func registerSharedTools(
 		agent.Tools.Register(messageTool)
 
 		// Skill discovery and installation tools
+		trustPolicy, trustErr := trust.NewPolicy(cfg.Trust)
 		registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
 			MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 			ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+			Trust:                 trustPolicy,
 		})
 		searchCache := skills.NewSearchCache(
 			cfg.Tools.Skills.SearchCache.MaxSize,
 			time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
 		)
 		agent.Tools.Register(tools.NewFindSkillsTool(registryMgr, searchCache))
-		agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
+		if trustErr == nil {
+			agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
+		} else {
+			// Without a valid trust policy, installs cannot be checked
+			logger.ErrorCF("agent", "Invalid trust config, install_skill disabled", map[string]any{
+				"error": trustErr.Error(),
+			})
+		}
 
 		// Spawn tool with allowlist checker
 		subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace, msgBus)
@@ -207,6 +248,32 @@ func registerSharedTools(
 		})
 		agent.Tools.Register(spawnTool)
 
+		// Offline batch submission at half price, when the provider supports it
+		if batcher, ok := provider.(providers.BatchProvider); ok && !cfg.Tools.Batch.Disabled {
+			agent.Tools.Register(tools.NewBatchTool(
+				batcher,
+				agent.Model,
+				agent.MaxTokens,
+				msgBus,
+				time.Duration(cfg.Tools.Batch.PollIntervalSeconds)*time.Second,
+			))
+		}
+
+		// Exact encoding, compression and hashing instead of guesswork
+		agent.Tools.Register(tools.NewEncodeTool())
+
+		// Regex search over stored tool output instead of re-reading it
+		agent.Tools.Register(tools.NewResultExtractTool(agent.Results))
+
+		// Unified diffs of stored results, files and directory trees
+		agent.Tools.Register(tools.NewDiffTool(agent.Workspace, cfg.Agents.Defaults.RestrictToWorkspace, agent.Results))
+
+		// Pinned facts survive compaction for the whole mission
+		agent.Tools.Register(tools.NewPinTool(agent.ContextBuilder.memory))
+
+		// Structured questions for the operator (webhook notification when headless)
+		agent.Tools.Register(tools.NewAskOperatorTool(cfg.Tools.AskOperator.WebhookURL))
+
 		// Workflow tools (only registered if agent has workflow engine)
 		getEngine := func() *workflow.Engine {
 			return agent.WorkflowEngine
@@ -216,6 +283,37 @@ func registerSharedTools(
 		agent.Tools.Register(tools.NewWorkflowCompleteBranchTool(getEngine))
 		agent.Tools.Register(tools.NewWorkflowAddFindingTool(getEngine))
 		agent.Tools.Register(tools.NewWorkflowAdvancePhaseTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowSetAliasTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowSetVariableTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowAddNoteTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowSetRedactionTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowAttachEvidenceTool(getEngine, agent.Workspace, cfg.Agents.Defaults.RestrictToWorkspace, agent.Results))
+		agent.Tools.Register(tools.NewWorkflowGenerateReportTool(getEngine))
+
+		// Analysis scripts in a sandbox confined to the mission directory
+		if !cfg.Tools.Python.Disabled {
+			if pythonTool, err := tools.NewPythonTool(agent.Workspace, getEngine, cfg); err != nil {
+				logger.WarnCF("agent", "Python tool unavailable", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(pythonTool)
+			}
+		}
+
+		// Token decoding and weakness checks that file their own findings
+		agent.Tools.Register(tools.NewTokenAnalyzeTool(agent.Workspace, getEngine))
+
+		// Scope checks, and scope changes the operator has to approve
+		agent.Tools.Register(tools.NewScopeQueryTool(getEngine))
+		agent.Tools.Register(tools.NewScopeChangeRequestTool(getEngine))
+		agent.Tools.Register(tools.NewWorkflowAddTargetTool(getEngine))
+
+		// An active mission limits tools to what its current phase allows
+		agent.Tools.SetPolicy(func() tools.ToolPolicy {
+			if engine := getEngine(); engine != nil {
+				return engine
+			}
+			return nil
+		})
 	}
 }
 
@@ -267,6 +365,11 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 
 func (al *AgentLoop) Stop() {
 	al.running.Store(false)
+	for _, agentID := range al.registry.ListAgentIDs() {
+		if agent, ok := al.registry.GetAgent(agentID); ok {
+			agent.stopBranches()
+		}
+	}
 }
 
 func (al *AgentLoop) RegisterTool(tool tools.Tool) {
@@ -281,6 +384,58 @@ func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
 }
 
+// SetCorrectionReviewer lets an interactive frontend approve, reject, or edit
+// supervisor corrections before they become the final answer.
+func (al *AgentLoop) SetCorrectionReviewer(reviewer routing.CorrectionReviewer) {
+	if al.tierRouter != nil {
+		al.tierRouter.SetCorrectionReviewer(reviewer)
+	}
+}
+
+// SetOperatorAsker lets an interactive frontend answer ask_operator
+// questions and approve scope change requests and MCP tool calls that need
+// approval. Without one, questions go to the configured webhook and the
+// agent proceeds, and requests needing approval are refused.
+func (al *AgentLoop) SetOperatorAsker(asker tools.OperatorAsker) {
+	for _, agentID := range al.registry.ListAgentIDs() {
+		agent, ok := al.registry.GetAgent(agentID)
+		if !ok {
+			continue
+		}
+		timed := timedAsker(agent, asker)
+		for _, name := range agent.Tools.List() {
+			if tool, ok := agent.Tools.Get(name); ok {
+				if ot, ok := tool.(tools.OperatorTool); ok {
+					ot.SetAsker(timed)
+				}
+			}
+		}
+	}
+}
+
+// SetToolImageHandler registers a callback invoked with the image files a tool
+// produced (e.g. web screenshots), so interactive frontends can preview them.
+func (al *AgentLoop) SetToolImageHandler(handler func(toolName string, paths []string)) {
+	al.onToolImages = handler
+}
+
+// ProviderCooldown returns the cooldown tracker the fallback chain consults,
+// so provider health checks can take failing providers out of rotation.
+func (al *AgentLoop) ProviderCooldown() *providers.CooldownTracker {
+	return al.fallback.Cooldown()
+}
+
+// ToolResultHandler receives each tool result produced while a turn runs.
+type ToolResultHandler func(toolName string, args map[string]any, result *tools.ToolResult)
+
+type toolResultHandlerKey struct{}
+
+// WithToolResultHandler attaches a handler to ctx that is called after every
+// tool call in turns processed with it, so API clients can stream progress.
+func WithToolResultHandler(ctx context.Context, handler ToolResultHandler) context.Context {
+	return context.WithValue(ctx, toolResultHandlerKey{}, handler)
+}
+
 // RecordLastChannel records the last active channel for this workspace.
 // This uses the atomic state save mechanism to prevent data loss on crash.
 func (al *AgentLoop) RecordLastChannel(channel string) error {
@@ -318,6 +473,20 @@ func (al *AgentLoop) ProcessDirectWithChannel(
 	return al.processMessage(ctx, msg)
 }
 
+// DirectSessionKey returns the key a direct message's session is stored
+// under. processMessage only honors agent-scoped keys, so other keys are
+// scoped to the default agent.
+func (al *AgentLoop) DirectSessionKey(key string) string {
+	if strings.HasPrefix(key, "agent:") {
+		return key
+	}
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		return key
+	}
+	return "agent:" + defaultAgent.ID + ":" + key
+}
+
 // ProcessHeartbeat processes a heartbeat request without session history.
 // Each heartbeat is independent and doesn't accumulate context.
 func (al *AgentLoop) ProcessHeartbeat(ctx context.Context, content, channel, chatID string) (string, error) {
@@ -337,7 +506,9 @@ func (al *AgentLoop) ProcessHeartbeat(ctx context.Context, content, channel, cha
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
 	// Add message preview to log (show full content for error messages)
 	var logContent string
-	if strings.Contains(msg.Content, "Error:") || strings.Contains(msg.Content, "error") {
+	if isSetCommand(msg.Content) {
+		logContent = "/set [redacted]" // Never log session variable values
+	} else if strings.Contains(msg.Content, "Error:") || strings.Contains(msg.Content, "error") {
 		logContent = msg.Content // Full content for errors
 	} else {
 		logContent = utils.Truncate(msg.Content, 80)
@@ -388,6 +559,14 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			"matched_by":  route.MatchedBy,
 		})
 
+	// Time since the agent's last reply counts as operator time
+	recordOperatorMessage(agent, time.Now())
+
+	// Session variable commands need the routed session key
+	if response, handled := al.handleSessionVarCommand(msg, sessionKey); handled {
+		return response, nil
+	}
+
 	return al.runAgentLoop(ctx, agent, processOptions{
 		SessionKey:      sessionKey,
 		Channel:         msg.Channel,
@@ -457,6 +636,9 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 
 // runAgentLoop is the core message processing logic.
 func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opts processOptions) (string, error) {
+	// Provider transcripts are recorded per session
+	ctx = providers.WithTranscriptSession(ctx, opts.SessionKey)
+
 	// 0. Check for explicit CLAW mode request (per-message, not per-agent)
 	// User can trigger CLAW with: "claw web_quick example.com" or "/claw scan target.com"
 	if detectCLAWRequest(opts.UserMessage) {
@@ -535,13 +717,22 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 		opts.Channel,
 		opts.ChatID,
 	)
+	messages = al.withSessionVarNames(messages, opts.SessionKey)
+	messages = al.withMissionVarNames(messages, agent)
 
 	// 3. Save user message to session
 	agent.Sessions.AddMessage(opts.SessionKey, "user", opts.UserMessage)
 
 	// 4. Run LLM iteration loop
+	turnStart := time.Now()
+	costBefore, tokensBefore := al.sessionSpend(opts.SessionKey)
+	reviewsBefore := al.sessionReviews(opts.SessionKey)
 	finalContent, iteration, err := al.runLLMIteration(ctx, agent, messages, opts)
+	recordAgentTurn(agent, turnStart)
+	al.recordTurnCost(agent, opts.SessionKey, costBefore, tokensBefore)
+	al.recordTurnReviews(agent, opts.SessionKey, reviewsBefore)
 	if err != nil {
+		al.supervisorFeedback.Delete(opts.SessionKey)
 		return "", err
 	}
 
@@ -555,6 +746,9 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 
 	// 6. Save final assistant message to session
 	agent.Sessions.AddMessage(opts.SessionKey, "assistant", finalContent)
+	if feedback, ok := al.supervisorFeedback.LoadAndDelete(opts.SessionKey); ok {
+		agent.Sessions.AddFullMessage(opts.SessionKey, feedback.(providers.Message))
+	}
 	agent.Sessions.Save(opts.SessionKey)
 
 	// 7. Optional: summarization
@@ -594,6 +788,25 @@ func (al *AgentLoop) runLLMIteration(
 	iteration := 0
 	var finalContent string
 	var lastToolOutput string
+	var lastToolName string // Tool whose output the next LLM call responds to
+
+	// Request options for every iteration. One prompt cache key per agent
+	// and session keeps the conversation's prefix cached between iterations
+	// and turns.
+	chatOptions := map[string]any{
+		"max_tokens":       agent.MaxTokens,
+		"temperature":      agent.Temperature,
+		"prompt_cache_key": providers.PromptCacheKey(agent.ID, opts.SessionKey),
+	}
+	if al.parallelToolsConfig().Enabled {
+		chatOptions["parallel_tool_calls"] = true
+	}
+
+	// Tools expand {{NAME}} session variables and have their values redacted
+	toolCtx := ctx
+	if al.sessionVars != nil {
+		toolCtx = tools.WithSessionVars(ctx, al.sessionVars.Vars(opts.SessionKey))
+	}
 
 	for iteration < agent.MaxIterations {
 		iteration++
@@ -633,6 +846,7 @@ func (al *AgentLoop) runLLMIteration(
 		var response *providers.LLMResponse
 		var err error
 
+		servedBy := agent.Model // Model the reply came from, for argument repair stats
 		callLLM := func() (*providers.LLMResponse, error) {
 			// Check if tier routing is enabled
 			if al.tierRouter != nil && al.tierRouter.IsEnabled() {
@@ -647,14 +861,11 @@ func (al *AgentLoop) runLLMIteration(
 					SessionStarted:  iteration == 1,
 				}
 				taskType := al.tierRouter.ClassifyTask(taskCtx)
+				routeCtx := routing.WithCostAttribution(ctx, missionPhase(agent.WorkflowEngine), lastToolName)
 
 				// Use hierarchical supervision for complex tasks
 				if taskCtx.RequiresSupervision {
-					supervisionResult, err := al.tierRouter.RouteWithSupervision(ctx, taskType, messages, providerToolDefs, map[string]any{
-						"max_tokens":       agent.MaxTokens,
-						"temperature":      agent.Temperature,
-						"prompt_cache_key": agent.ID,
-					}, opts.SessionKey, taskCtx)
+					supervisionResult, err := al.tierRouter.RouteWithSupervision(routeCtx, taskType, messages, providerToolDefs, chatOptions, opts.SessionKey, taskCtx)
 					if err != nil {
 						return nil, fmt.Errorf("supervised execution failed: %w", err)
 					}
@@ -664,7 +875,11 @@ func (al *AgentLoop) runLLMIteration(
 						"worker_model":      supervisionResult.WorkerModel,
 						"validated":         supervisionResult.Validated,
 						"corrections_count": len(supervisionResult.Corrections),
+						"operator_decision": supervisionResult.OperatorDecision,
 					})
+					if feedback, ok := supervisionResult.FeedbackMessage(); ok {
+						al.supervisorFeedback.Store(opts.SessionKey, feedback)
+					}
 					// Create response from supervision result
 					resp := &providers.LLMResponse{
 						Content: supervisionResult.FinalOutput,
@@ -678,27 +893,23 @@ func (al *AgentLoop) runLLMIteration(
 				}
 
 				// Route via tier router (non-supervised)
-				return al.tierRouter.RouteChat(ctx, taskType, messages, providerToolDefs, map[string]any{
-					"max_tokens":       agent.MaxTokens,
-					"temperature":      agent.Temperature,
-					"prompt_cache_key": agent.ID,
-				}, opts.SessionKey)
+				return al.tierRouter.RouteChat(routeCtx, taskType, messages, providerToolDefs, chatOptions, opts.SessionKey)
 			}
 
 			// Original behavior: use fallback chain or direct provider
 			if len(agent.Candidates) > 1 && al.fallback != nil {
 				fbResult, fbErr := al.fallback.Execute(ctx, agent.Candidates,
 					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
-						return agent.Provider.Chat(ctx, messages, providerToolDefs, model, map[string]any{
-							"max_tokens":       agent.MaxTokens,
-							"temperature":      agent.Temperature,
-							"prompt_cache_key": agent.ID,
-						})
+						preambled := providers.WithSystemPreamble(messages, al.preambleFor(agent, provider))
+						return agent.Provider.Chat(ctx, preambled, providerToolDefs, model, chatOptions)
 					},
 				)
 				if fbErr != nil {
 					return nil, fbErr
 				}
+				if fbResult.Model != "" {
+					servedBy = fbResult.Model
+				}
 				if fbResult.Provider != "" && len(fbResult.Attempts) > 0 {
 					logger.InfoCF("agent", fmt.Sprintf("Fallback: succeeded with %s/%s after %d attempts",
 						fbResult.Provider, fbResult.Model, len(fbResult.Attempts)+1),
@@ -706,11 +917,8 @@ func (al *AgentLoop) runLLMIteration(
 				}
 				return fbResult.Response, nil
 			}
-			return agent.Provider.Chat(ctx, messages, providerToolDefs, agent.Model, map[string]any{
-				"max_tokens":       agent.MaxTokens,
-				"temperature":      agent.Temperature,
-				"prompt_cache_key": agent.ID,
-			})
+			preambled := providers.WithSystemPreamble(messages, al.preambleFor(agent, al.modelProtocol(agent.Model)))
+			return agent.Provider.Chat(ctx, preambled, providerToolDefs, agent.Model, chatOptions)
 		}
 
 		// Retry loop for context/token errors
@@ -721,11 +929,15 @@ func (al *AgentLoop) runLLMIteration(
 				break
 			}
 
-			errMsg := strings.ToLower(err.Error())
-			isContextError := strings.Contains(errMsg, "token") ||
-				strings.Contains(errMsg, "context") ||
-				strings.Contains(errMsg, "invalidparameter") ||
-				strings.Contains(errMsg, "length")
+			isContextError := errors.Is(err, providers.ErrContextTooLong)
+			if !isContextError && !errors.Is(err, providers.ErrAuthFailed) {
+				// Providers that don't return typed errors yet.
+				errMsg := strings.ToLower(err.Error())
+				isContextError = strings.Contains(errMsg, "token") ||
+					strings.Contains(errMsg, "context") ||
+					strings.Contains(errMsg, "invalidparameter") ||
+					strings.Contains(errMsg, "length")
+			}
 
 			if isContextError && retry < maxRetries {
 				logger.WarnCF("agent", "Context window error detected, attempting compression", map[string]any{
@@ -763,6 +975,18 @@ func (al *AgentLoop) runLLMIteration(
 			return "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
 		}
 
+		// The tier router applies the refusal retry policy; anything still
+		// refused here is annotated so it isn't taken as a real answer.
+		if providers.IsRefusal(response) && !response.Refused {
+			logger.WarnCF("agent", "Provider refused request",
+				map[string]any{
+					"agent_id":      agent.ID,
+					"iteration":     iteration,
+					"finish_reason": response.FinishReason,
+				})
+			providers.AnnotateRefusal(response)
+		}
+
 		// Check if no tool calls - we're done
 		if len(response.ToolCalls) == 0 {
 			finalContent = response.Content
@@ -779,6 +1003,10 @@ func (al *AgentLoop) runLLMIteration(
 		for _, tc := range response.ToolCalls {
 			normalizedToolCalls = append(normalizedToolCalls, providers.NormalizeToolCall(tc))
 		}
+		if response.Model != "" {
+			servedBy = response.Model
+		}
+		al.argRepairer.Repair(ctx, servedBy, normalizedToolCalls, providerToolDefs, al.argRepairLLM(agent, opts.SessionKey))
 
 		// Log tool calls
 		toolNames := make([]string, 0, len(normalizedToolCalls))
@@ -795,9 +1023,10 @@ func (al *AgentLoop) runLLMIteration(
 
 		// Build assistant message with tool calls
 		assistantMsg := providers.Message{
-			Role:             "assistant",
-			Content:          response.Content,
-			ReasoningContent: response.ReasoningContent,
+			Role:              "assistant",
+			Content:           response.Content,
+			ReasoningContent:  response.ReasoningContent,
+			ThinkingSignature: response.ThinkingSignature,
 		}
 		for _, tc := range normalizedToolCalls {
 			argumentsJSON, err := json.Marshal(tc.Arguments)
@@ -831,41 +1060,13 @@ func (al *AgentLoop) runLLMIteration(
 		// Save assistant message with tool calls to session
 		agent.Sessions.AddFullMessage(opts.SessionKey, assistantMsg)
 
-		// Execute tool calls
-		for _, tc := range normalizedToolCalls {
-			argsJSON, _ := json.Marshal(tc.Arguments)
-			argsPreview := utils.Truncate(string(argsJSON), 200)
-			logger.InfoCF("agent", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
-				map[string]any{
-					"agent_id":  agent.ID,
-					"tool":      tc.Name,
-					"iteration": iteration,
-				})
-
-			// Create async callback for tools that implement AsyncTool
-			// NOTE: Following openclaw's design, async tools do NOT send results directly to users.
-			// Instead, they notify the agent via PublishInbound, and the agent decides
-			// whether to forward the result to the user (in processSystemMessage).
-			asyncCallback := func(callbackCtx context.Context, result *tools.ToolResult) {
-				// Log the async completion but don't send directly to user
-				// The agent will handle user notification via processSystemMessage
-				if !result.Silent && result.ForUser != "" {
-					logger.InfoCF("agent", "Async tool completed, agent will handle notification",
-						map[string]any{
-							"tool":        tc.Name,
-							"content_len": len(result.ForUser),
-						})
-				}
-			}
-
-			toolResult := agent.Tools.ExecuteWithContext(
-				ctx,
-				tc.Name,
-				tc.Arguments,
-				opts.Channel,
-				opts.ChatID,
-				asyncCallback,
-			)
+		// Execute tool calls; results come back in call order
+		// Mission variables are read per batch since tools may change the phase
+		missionCtx := tools.WithMissionVars(toolCtx, missionVariables(agent.WorkflowEngine))
+		toolResults := al.executeToolCalls(missionCtx, agent, normalizedToolCalls, opts, iteration)
+		for i, tc := range normalizedToolCalls {
+			toolResult := toolResults[i]
+			recordToolCall(agent, tc, toolResult)
 
 			// Send ForUser content to user immediately if not Silent
 			if !toolResult.Silent && toolResult.ForUser != "" && opts.SendResponse {
@@ -881,6 +1082,16 @@ func (al *AgentLoop) runLLMIteration(
 					})
 			}
 
+			if al.onToolImages != nil {
+				if images := tools.ImageArtifacts(toolResult, agent.Workspace); len(images) > 0 {
+					al.onToolImages(tc.Name, images)
+				}
+			}
+
+			if handler, ok := ctx.Value(toolResultHandlerKey{}).(ToolResultHandler); ok {
+				handler(tc.Name, tc.Arguments, toolResult)
+			}
+
 			// Determine content for LLM based on tool result
 			contentForLLM := toolResult.ForLLM
 			if contentForLLM == "" && toolResult.Err != nil {
@@ -897,10 +1108,17 @@ func (al *AgentLoop) runLLMIteration(
 					}
 				}
 			}
+			if resultNote := storeToolResult(agent, tc.Name, toolResult); resultNote != "" {
+				contentForLLM += "\n\n" + resultNote
+			}
+			if fallbackNote := missingToolNote(agent, tc.Name, tc.Arguments, toolResult); fallbackNote != "" {
+				contentForLLM += "\n\n" + fallbackNote
+			}
 
 			// Track last tool output for task classification
 			if contentForLLM != "" {
 				lastToolOutput = utils.Truncate(contentForLLM, 500) // Limit size for classification
+				lastToolName = tc.Name
 			}
 
 			toolResultMsg := providers.Message{
@@ -908,7 +1126,13 @@ func (al *AgentLoop) runLLMIteration(
 				Content:    contentForLLM,
 				ToolCallID: tc.ID,
 			}
-			messages = append(messages, toolResultMsg)
+			// Vision models see the tool's images for this turn; the session
+			// keeps only the text so history stays small
+			toolResultWithImages := toolResultMsg
+			if agent.Vision {
+				toolResultWithImages.Images = loadToolImages(toolResult, agent.Workspace)
+			}
+			messages = append(messages, toolResultWithImages)
 
 			// Save tool result message to session
 			agent.Sessions.AddFullMessage(opts.SessionKey, toolResultMsg)
@@ -918,6 +1142,27 @@ func (al *AgentLoop) runLLMIteration(
 	return finalContent, iteration, nil
 }
 
+// maxToolImages caps how many images one tool result attaches.
+const maxToolImages = 4
+
+// loadToolImages inlines the images a tool result produced, skipping files
+// that can't be read or are too large.
+func loadToolImages(result *tools.ToolResult, workspace string) []providers.ImagePart {
+	var images []providers.ImagePart
+	for _, path := range tools.ImageArtifacts(result, workspace) {
+		if len(images) == maxToolImages {
+			break
+		}
+		img, err := providers.LoadImage(path)
+		if err != nil {
+			logger.WarnCF("agent", "Skipping tool image", map[string]any{"path": path, "error": err.Error()})
+			continue
+		}
+		images = append(images, img)
+	}
+	return images
+}
+
 // updateToolContexts updates the context for tools that need channel/chatID info.
 func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID string) {
 	// Use ContextualTool interface instead of type assertions
@@ -926,6 +1171,16 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 			mt.SetContext(channel, chatID)
 		}
 	}
+	if tool, ok := agent.Tools.Get("ask_operator"); ok {
+		if at, ok := tool.(tools.ContextualTool); ok {
+			at.SetContext(channel, chatID)
+		}
+	}
+	if tool, ok := agent.Tools.Get("scope_change_request"); ok {
+		if st, ok := tool.(tools.ContextualTool); ok {
+			st.SetContext(channel, chatID)
+		}
+	}
 	if tool, ok := agent.Tools.Get("spawn"); ok {
 		if st, ok := tool.(tools.ContextualTool); ok {
 			st.SetContext(channel, chatID)
@@ -936,6 +1191,11 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 			st.SetContext(channel, chatID)
 		}
 	}
+	if tool, ok := agent.Tools.Get("workflow_investigate_branch"); ok {
+		if bt, ok := tool.(tools.ContextualTool); ok {
+			bt.SetContext(channel, chatID)
+		}
+	}
 }
 
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
@@ -943,7 +1203,7 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 // stops promptly when the agent loop shuts down.
 func (al *AgentLoop) maybeSummarize(ctx context.Context, agent *AgentInstance, sessionKey, channel, chatID string) {
 	newHistory := agent.Sessions.GetHistory(sessionKey)
-	tokenEstimate := al.estimateTokens(newHistory)
+	tokenEstimate := al.estimateTokens(agent, newHistory)
 	threshold := agent.ContextWindow * 75 / 100
 
 	if len(newHistory) > 20 || tokenEstimate > threshold {
@@ -1249,16 +1509,10 @@ func (al *AgentLoop) summarizeBatch(
 	return response.Content, nil
 }
 
-// estimateTokens estimates the number of tokens in a message list.
-// Uses a safe heuristic of 2.5 characters per token to account for CJK and other
-// overheads better than the previous 3 chars/token.
-func (al *AgentLoop) estimateTokens(messages []providers.Message) int {
-	totalChars := 0
-	for _, m := range messages {
-		totalChars += utf8.RuneCountInString(m.Content)
-	}
-	// 2.5 chars per token = totalChars * 2 / 5
-	return totalChars * 2 / 5
+// estimateTokens estimates the number of tokens in a message list for the
+// agent's model (see pkg/tokens).
+func (al *AgentLoop) estimateTokens(agent *AgentInstance, messages []providers.Message) int {
+	return tokens.EstimateMessages(agent.Model, messages)
 }
 
 func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (string, bool) {
@@ -1278,7 +1532,7 @@ func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (s
 	switch cmd {
 	case "/show":
 		if len(args) < 1 {
-			return "Usage: /show [model|channel|agents]", true
+			return "Usage: /show [model|channel|agents|tier|repairs]", true
 		}
 		switch args[0] {
 		case "model":
@@ -1289,16 +1543,23 @@ func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (s
 			return fmt.Sprintf("Current model: %s", defaultAgent.Model), true
 		case "channel":
 			return fmt.Sprintf("Current channel: %s", msg.Channel), true
+		case "tier":
+			if al.tierRouter == nil {
+				return "Tier routing is not enabled", true
+			}
+			return fmt.Sprintf("Current default tier: %s", al.tierRouter.DefaultTier()), true
 		case "agents":
 			agentIDs := al.registry.ListAgentIDs()
 			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
+		case "repairs":
+			return al.argRepairer.FormatStats(), true
 		default:
 			return fmt.Sprintf("Unknown show target: %s", args[0]), true
 		}
 
 	case "/list":
 		if len(args) < 1 {
-			return "Usage: /list [models|channels|agents]", true
+			return "Usage: /list [models|channels|agents|tiers]", true
 		}
 		switch args[0] {
 		case "models":
@@ -1312,6 +1573,11 @@ func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (s
 				return "No channels enabled", true
 			}
 			return fmt.Sprintf("Enabled channels: %s", strings.Join(channels, ", ")), true
+		case "tiers":
+			if al.tierRouter == nil {
+				return "Tier routing is not enabled", true
+			}
+			return fmt.Sprintf("Configured tiers: %s", strings.Join(al.tierRouter.TierNames(), ", ")), true
 		case "agents":
 			agentIDs := al.registry.ListAgentIDs()
 			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
@@ -1319,9 +1585,83 @@ func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (s
 			return fmt.Sprintf("Unknown list target: %s", args[0]), true
 		}
 
+	case "/pin":
+		defaultAgent := al.registry.GetDefaultAgent()
+		if defaultAgent == nil {
+			return "No default agent configured", true
+		}
+		memory := defaultAgent.ContextBuilder.memory
+		if len(args) == 0 {
+			pins := memory.ReadPins()
+			if len(pins) == 0 {
+				return "No pinned items. Usage: /pin <text>", true
+			}
+			var sb strings.Builder
+			sb.WriteString("Pinned items:")
+			for i, pin := range pins {
+				fmt.Fprintf(&sb, "\n%d. %s", i+1, pin)
+			}
+			return sb.String(), true
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(content, cmd))
+		if err := memory.AddPin(text); err != nil {
+			return fmt.Sprintf("Failed to pin: %v", err), true
+		}
+		return fmt.Sprintf("Pinned #%d: %s", len(memory.ReadPins()), text), true
+
+	case "/mission":
+		return al.handleMissionCommand(args, content), true
+
+	case "/scope":
+		return al.handleScopeCommand(args), true
+
+	case "/unpin":
+		if len(args) != 1 {
+			return "Usage: /unpin <number>", true
+		}
+		defaultAgent := al.registry.GetDefaultAgent()
+		if defaultAgent == nil {
+			return "No default agent configured", true
+		}
+		index, err := strconv.Atoi(args[0])
+		if err != nil {
+			return "Usage: /unpin <number>", true
+		}
+		removed, err := defaultAgent.ContextBuilder.memory.RemovePin(index)
+		if err != nil {
+			return fmt.Sprintf("Failed to unpin: %v", err), true
+		}
+		return fmt.Sprintf("Unpinned: %s", removed), true
+
+	case "/tier":
+		if al.tierRouter == nil {
+			return "Tier routing is not enabled", true
+		}
+		if len(args) == 0 {
+			var sb strings.Builder
+			sb.WriteString("Tiers:")
+			for _, name := range al.tierRouter.TierNames() {
+				model, _ := al.tierRouter.TierModel(name)
+				fmt.Fprintf(&sb, "\n%s: %s", name, model)
+			}
+			return sb.String(), true
+		}
+		if args[0] != "set" || len(args) != 3 {
+			return "Usage: /tier [set <tier> <model_name>]", true
+		}
+		tierName, modelName := args[1], args[2]
+		oldModel, _ := al.tierRouter.TierModel(tierName)
+		if err := al.tierRouter.SetTierModel(tierName, modelName); err != nil {
+			return fmt.Sprintf("Failed to set tier model: %v", err), true
+		}
+		if oldModel == modelName {
+			return fmt.Sprintf("Re-initialized %s for tier %s", modelName, tierName), true
+		}
+		return fmt.Sprintf("Switched tier %s from %s to %s", tierName, oldModel, modelName), true
+
 	case "/switch":
 		if len(args) < 3 || args[1] != "to" {
-			return "Usage: /switch [model|channel] to <name>", true
+			return "Usage: /switch [model|channel|tier] to <name>", true
 		}
 		target := args[0]
 		value := args[2]
@@ -1343,6 +1683,15 @@ func (al *AgentLoop) handleCommand(_ context.Context, msg bus.InboundMessage) (s
 				return fmt.Sprintf("Channel '%s' not found or not enabled", value), true
 			}
 			return fmt.Sprintf("Switched target channel to %s", value), true
+		case "tier":
+			if al.tierRouter == nil {
+				return "Tier routing is not enabled", true
+			}
+			oldTier := al.tierRouter.DefaultTier()
+			if err := al.tierRouter.SetDefaultTier(value); err != nil {
+				return fmt.Sprintf("Failed to switch tier: %v", err), true
+			}
+			return fmt.Sprintf("Switched default tier from %s to %s", oldTier, value), true
 		default:
 			return fmt.Sprintf("Unknown switch target: %s", target), true
 		}
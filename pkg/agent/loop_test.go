@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
 func TestRecordLastChannel(t *testing.T) {
@@ -631,3 +636,433 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 		t.Errorf("Expected history to be compressed (len < 8), got %d", len(finalHistory))
 	}
 }
+
+// TestAgentLoop_ScriptedReplay drives tool calls through the loop with a
+// replay script instead of a live model
+func TestAgentLoop_ScriptedReplay(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	notesPath := filepath.Join(tmpDir, "notes.txt")
+	provider, err := replay.NewScriptProvider("test", &replay.Script{
+		Steps: []replay.ScriptStep{
+			{
+				Content: "Saving notes",
+				ToolCalls: []replay.ScriptToolCall{
+					{Name: "write_file", Arguments: map[string]any{"path": notesPath, "content": "port 22 open"}},
+				},
+			},
+			{Expect: "File written", Content: "Notes saved"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptProvider: %v", err)
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	response, err := al.ProcessDirectWithChannel(context.Background(), "save the notes", "test-session", "test", "test-chat")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel: %v", err)
+	}
+	if response != "Notes saved" {
+		t.Errorf("response = %q, want %q", response, "Notes saved")
+	}
+	data, err := os.ReadFile(notesPath)
+	if err != nil || string(data) != "port 22 open" {
+		t.Errorf("notes.txt = %q, %v", data, err)
+	}
+	if provider.Remaining() != 0 {
+		t.Errorf("%d scripted responses unused", provider.Remaining())
+	}
+}
+
+func TestAgentLoop_ParallelTools(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				ParallelTools:     config.ParallelToolsConfig{Enabled: true, MaxConcurrency: 2, TimeoutSeconds: 1},
+			},
+		},
+	}
+
+	call := func(id string, delayMS int) replay.ScriptToolCall {
+		return replay.ScriptToolCall{Name: "slow", Arguments: map[string]any{"id": id, "delay_ms": delayMS}}
+	}
+	provider, err := replay.NewScriptProvider("test", &replay.Script{
+		Steps: []replay.ScriptStep{
+			{ToolCalls: []replay.ScriptToolCall{call("a", 150), call("b", 50), call("c", 100), call("hang", 0)}},
+			{Content: "done"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptProvider: %v", err)
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	slow := &slowTool{release: make(chan struct{})}
+	defer close(slow.release)
+	al.registry.GetDefaultAgent().Tools.Register(slow)
+
+	var results []string
+	ctx := WithToolResultHandler(context.Background(), func(_ string, _ map[string]any, result *tools.ToolResult) {
+		results = append(results, result.ForLLM)
+	})
+	if _, err := al.ProcessDirectWithChannel(ctx, "scan", "test-session", "test", "test-chat"); err != nil {
+		t.Fatalf("ProcessDirectWithChannel: %v", err)
+	}
+	if got := slow.maxActive.Load(); got != 2 {
+		t.Errorf("max concurrent calls = %d, want the limit of 2", got)
+	}
+	want := []string{"result a", "result b", "result c", "Tool slow timed out after 1s"}
+	if strings.Join(results, "|") != strings.Join(want, "|") {
+		t.Errorf("tool results = %q, want %q in call order", results, want)
+	}
+}
+
+// slowTool sleeps for delay_ms and tracks how many calls overlap. The id
+// "hang" blocks until release is closed, ignoring cancellation.
+type slowTool struct {
+	active, maxActive atomic.Int32
+	release           chan struct{}
+}
+
+func (s *slowTool) Name() string        { return "slow" }
+func (s *slowTool) Description() string { return "Sleeps" }
+func (s *slowTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (s *slowTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	id, _ := args["id"].(string)
+	if id == "hang" {
+		<-s.release
+		return tools.NewToolResult("released")
+	}
+	n := s.active.Add(1)
+	defer s.active.Add(-1)
+	for {
+		old := s.maxActive.Load()
+		if n <= old || s.maxActive.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	delay, _ := args["delay_ms"].(int)
+	time.Sleep(time.Duration(delay) * time.Millisecond)
+	return tools.NewToolResult("result " + id)
+}
+
+func TestAgentLoop_ParallelTools_ContextualToolRunsAlone(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				ParallelTools:     config.ParallelToolsConfig{Enabled: true, MaxConcurrency: 4},
+			},
+		},
+	}
+	call := replay.ScriptToolCall{Name: "notify", Arguments: map[string]any{}}
+	provider, err := replay.NewScriptProvider("test", &replay.Script{
+		Steps: []replay.ScriptStep{
+			{ToolCalls: []replay.ScriptToolCall{call, call}},
+			{Content: "done"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptProvider: %v", err)
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	notify := &contextTool{}
+	al.registry.GetDefaultAgent().Tools.Register(notify)
+
+	if _, err := al.ProcessDirectWithChannel(context.Background(), "notify", "test-session", "test", "test-chat"); err != nil {
+		t.Fatalf("ProcessDirectWithChannel: %v", err)
+	}
+	if notify.calls != 2 || notify.maxActive.Load() != 1 {
+		t.Errorf("calls = %d, max concurrent = %d; want 2 calls, one at a time", notify.calls, notify.maxActive.Load())
+	}
+}
+
+// contextTool keeps the chat it was given in plain fields, so overlapping
+// calls show up under -race
+type contextTool struct {
+	channel, chatID   string
+	calls             int
+	active, maxActive atomic.Int32
+}
+
+func (c *contextTool) Name() string        { return "notify" }
+func (c *contextTool) Description() string { return "Notifies the chat" }
+func (c *contextTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (c *contextTool) SetContext(channel, chatID string) {
+	c.channel, c.chatID = channel, chatID
+}
+
+func (c *contextTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	if n := c.active.Add(1); n > c.maxActive.Load() {
+		c.maxActive.Store(n)
+	}
+	defer c.active.Add(-1)
+	time.Sleep(20 * time.Millisecond)
+	c.calls++
+	return tools.NewToolResult("notified " + c.channel + ":" + c.chatID)
+}
+
+func TestAgentLoop_ToolTimeoutSparesAsyncTools(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				ParallelTools:     config.ParallelToolsConfig{TimeoutSeconds: 600},
+			},
+		},
+	}
+	provider, err := replay.NewScriptProvider("test", &replay.Script{
+		Steps: []replay.ScriptStep{
+			{ToolCalls: []replay.ScriptToolCall{{Name: "background", Arguments: map[string]any{}}}},
+			{Content: "started"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptProvider: %v", err)
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	background := &backgroundTool{started: make(chan context.Context, 1)}
+	al.registry.GetDefaultAgent().Tools.Register(background)
+
+	if _, err := al.ProcessDirectWithChannel(context.Background(), "go", "test-session", "test", "test-chat"); err != nil {
+		t.Fatalf("ProcessDirectWithChannel: %v", err)
+	}
+	ctx := <-background.started
+	if err := ctx.Err(); err != nil {
+		t.Errorf("background work context = %v after the call returned, want live", err)
+	}
+}
+
+// backgroundTool is an async tool that hands its context to background work
+type backgroundTool struct {
+	started chan context.Context
+}
+
+func (b *backgroundTool) Name() string        { return "background" }
+func (b *backgroundTool) Description() string { return "Starts background work" }
+func (b *backgroundTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (b *backgroundTool) SetCallback(tools.AsyncCallback) {}
+
+func (b *backgroundTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	b.started <- ctx
+	return tools.AsyncResult("started")
+}
+
+func TestHandleCommand_PinSurvivesCompaction(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	agent := al.registry.GetDefaultAgent()
+
+	resp, handled := al.handleCommand(context.Background(), bus.InboundMessage{Content: "/pin Only 10.0.0.0/24 is in scope"})
+	if !handled || resp != "Pinned #1: Only 10.0.0.0/24 is in scope" {
+		t.Fatalf("/pin = %q, %v", resp, handled)
+	}
+	al.handleCommand(context.Background(), bus.InboundMessage{Content: "/pin Never contact client staff directly"})
+
+	sessionKey := "test-session"
+	for i := 0; i < 10; i++ {
+		agent.Sessions.AddMessage(sessionKey, "user", fmt.Sprintf("message %d", i))
+	}
+	al.forceCompression(agent, sessionKey)
+
+	messages := agent.ContextBuilder.BuildMessages(
+		agent.Sessions.GetHistory(sessionKey), "", "next", nil, "cli", "direct")
+	for _, want := range []string{"Only 10.0.0.0/24 is in scope", "Never contact client staff directly"} {
+		if !strings.Contains(messages[0].Content, want) {
+			t.Errorf("system prompt missing pin %q after compaction", want)
+		}
+	}
+
+	resp, _ = al.handleCommand(context.Background(), bus.InboundMessage{Content: "/unpin 1"})
+	if resp != "Unpinned: Only 10.0.0.0/24 is in scope" {
+		t.Errorf("/unpin = %q", resp)
+	}
+	resp, _ = al.handleCommand(context.Background(), bus.InboundMessage{Content: "/pin"})
+	if resp != "Pinned items:\n1. Never contact client staff directly" {
+		t.Errorf("/pin list = %q", resp)
+	}
+}
+
+func TestLoadWorkflow_NewMissionClearsPins(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	agent := al.registry.GetDefaultAgent()
+
+	dir := filepath.Join(tmpDir, "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wf := "---\nname: recon\nphases: [discovery]\n---\n\n## Phase: discovery\n\n### Steps\n\n- ports: Scan ports (required)\n"
+	if err := os.WriteFile(filepath.Join(dir, "recon.md"), []byte(wf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agent.LoadWorkflow("recon", "acme.example"); err != nil {
+		t.Fatalf("LoadWorkflow: %v", err)
+	}
+	al.handleCommand(context.Background(), bus.InboundMessage{Content: "/pin Only 10.0.0.0/24 is in scope for ACME"})
+
+	if err := agent.LoadWorkflow("recon", "other.example"); err != nil {
+		t.Fatalf("LoadWorkflow: %v", err)
+	}
+	if pins := agent.ContextBuilder.memory.ReadPins(); len(pins) != 0 {
+		t.Errorf("new mission pins = %v, want none", pins)
+	}
+	if prompt := agent.ContextBuilder.BuildSystemPromptWithCache(); strings.Contains(prompt, "ACME") {
+		t.Error("previous mission's pin reached the new mission's prompt")
+	}
+}
+
+func TestMissionVariables(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	agent := al.registry.GetDefaultAgent()
+
+	resp, _ := al.handleCommand(context.Background(), bus.InboundMessage{Content: "/mission"})
+	if resp != "No active mission" {
+		t.Errorf("/mission without mission = %q", resp)
+	}
+
+	wf := &workflow.Workflow{Name: "internal-net", Phases: []workflow.Phase{{Name: "discovery"}}}
+	agent.WorkflowEngine = workflow.NewEngine(wf, "corp.example", tmpDir)
+	if err := agent.WorkflowEngine.SetAlias("the vpn", "vpn.corp.example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.WorkflowEngine.SetMetadata("ports", []any{22, 443}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, _ = al.handleCommand(context.Background(), bus.InboundMessage{Content: "/mission set scope.cidr 10.0.0.0/24"})
+	if resp != "Set scope.cidr. Tools can reference it as {{scope.cidr}}." {
+		t.Errorf("/mission set = %q", resp)
+	}
+	resp, _ = al.handleCommand(context.Background(), bus.InboundMessage{Content: "/mission set target other.example"})
+	if resp != "target is set by the mission itself" {
+		t.Errorf("/mission set target = %q", resp)
+	}
+
+	vars := missionVariables(agent.WorkflowEngine)
+	want := map[string]string{
+		"target":        "corp.example",
+		"workflow":      "internal-net",
+		"phase":         "discovery",
+		"scope.cidr":    "10.0.0.0/24",
+		"ports":         "22,443",
+		"alias.the_vpn": "vpn.corp.example",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("missionVariables() = %v, want %v", vars, want)
+	}
+
+	messages := al.withMissionVarNames([]providers.Message{{Role: "system", Content: "base"}}, agent)
+	if !strings.Contains(messages[0].Content, "{{scope.cidr}}") || strings.Contains(messages[0].Content, "10.0.0.0/24") {
+		t.Errorf("system prompt should list variable names only:\n%s", messages[0].Content)
+	}
+}
+
+func TestSessionVarCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	agent := al.registry.GetDefaultAgent()
+	sessionKey := "agent:main:test"
+
+	resp, err := al.ProcessDirect(context.Background(), "/set AUTH_TOKEN Bearer abc.def", sessionKey)
+	if err != nil || resp != "Set AUTH_TOKEN for this session. Tools can reference it as {{AUTH_TOKEN}}." {
+		t.Fatalf("/set = %q, %v", resp, err)
+	}
+	if got := al.sessionVars.Vars(sessionKey)["AUTH_TOKEN"]; got != "Bearer abc.def" {
+		t.Errorf("stored value = %q", got)
+	}
+	if history := agent.Sessions.GetHistory(sessionKey); len(history) != 0 {
+		t.Errorf("/set must not be stored in session history, got %d messages", len(history))
+	}
+
+	resp, _ = al.ProcessDirect(context.Background(), "/set", sessionKey)
+	if resp != "Session variables: AUTH_TOKEN" {
+		t.Errorf("/set list = %q", resp)
+	}
+
+	// The model learns the names, never the values
+	messages := al.withSessionVarNames(agent.ContextBuilder.BuildMessages(nil, "", "hi", nil, "cli", "direct"), sessionKey)
+	if !strings.Contains(messages[0].Content, "{{AUTH_TOKEN}}") || strings.Contains(messages[0].Content, "abc.def") {
+		t.Errorf("system prompt should reference the variable by name only")
+	}
+
+	resp, _ = al.ProcessDirect(context.Background(), "/unset AUTH_TOKEN", sessionKey)
+	if resp != "Unset AUTH_TOKEN" || len(al.sessionVars.Names(sessionKey)) != 0 {
+		t.Errorf("/unset = %q", resp)
+	}
+}
@@ -16,11 +16,13 @@ import (
 
 // MemoryStore manages persistent memory for the agent.
 // - Long-term memory: memory/MEMORY.md
+// - Pinned facts: memory/PINNED.md
 // - Daily notes: memory/YYYYMM/YYYYMMDD.md
 type MemoryStore struct {
 	workspace  string
 	memoryDir  string
 	memoryFile string
+	pinsFile   string
 }
 
 // NewMemoryStore creates a new MemoryStore with the given workspace path.
@@ -36,6 +38,7 @@ func NewMemoryStore(workspace string) *MemoryStore {
 		workspace:  workspace,
 		memoryDir:  memoryDir,
 		memoryFile: memoryFile,
+		pinsFile:   filepath.Join(memoryDir, "PINNED.md"),
 	}
 }
 
@@ -149,3 +152,79 @@ func (ms *MemoryStore) GetMemoryContext() string {
 
 	return sb.String()
 }
+
+// ReadPins returns the pinned facts, one per "- " line in PINNED.md.
+func (ms *MemoryStore) ReadPins() []string {
+	data, err := os.ReadFile(ms.pinsFile)
+	if err != nil {
+		return nil
+	}
+
+	var pins []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if pin, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok && pin != "" {
+			pins = append(pins, pin)
+		}
+	}
+	return pins
+}
+
+// AddPin pins a fact so it is carried in every system prompt and never
+// dropped by compaction or summarization. Newlines are collapsed so each pin
+// stays a single line.
+func (ms *MemoryStore) AddPin(text string) error {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return fmt.Errorf("pin text is empty")
+	}
+	return ms.writePins(append(ms.ReadPins(), text))
+}
+
+// RemovePin removes the pin at the 1-based index shown by /pin.
+func (ms *MemoryStore) RemovePin(index int) (string, error) {
+	pins := ms.ReadPins()
+	if index < 1 || index > len(pins) {
+		return "", fmt.Errorf("no pin #%d (have %d)", index, len(pins))
+	}
+	removed := pins[index-1]
+	pins = append(pins[:index-1], pins[index:]...)
+	return removed, ms.writePins(pins)
+}
+
+func (ms *MemoryStore) writePins(pins []string) error {
+	if len(pins) == 0 {
+		if err := os.Remove(ms.pinsFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Pinned\n\n")
+	for _, pin := range pins {
+		sb.WriteString("- " + pin + "\n")
+	}
+	return os.WriteFile(ms.pinsFile, []byte(sb.String()), 0o644)
+}
+
+// ClearPins removes every pin. Pins belong to one mission, so a new mission
+// starts without the last one's.
+func (ms *MemoryStore) ClearPins() error {
+	return ms.writePins(nil)
+}
+
+// GetPinnedContext returns the pinned facts formatted for the agent prompt.
+func (ms *MemoryStore) GetPinnedContext() string {
+	pins := ms.ReadPins()
+	if len(pins) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Pinned Context\n\n")
+	sb.WriteString("These items were pinned for the entire mission. They always apply, even if earlier conversation was compacted.\n\n")
+	for i, pin := range pins {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, pin)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
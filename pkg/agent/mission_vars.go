@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// missionVarName matches a mission variable name that /mission set accepts.
+var missionVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_-]+)*$`)
+
+// missionVariables collects the values tool arguments can reference as
+// {{name}}: target, workflow and phase, every metadata key and variable
+// (nested maps flattened to dotted names such as scope.cidr) and alias.NAME
+// for each target alias.
+func missionVariables(engine *workflow.Engine) map[string]string {
+	if engine == nil {
+		return make(map[string]string)
+	}
+	return engine.TemplateVars()
+}
+
+// withMissionVarNames tells the model which mission variables it can use in
+// tool arguments. Only the names are listed; values are filled in when the
+// tool runs.
+func (al *AgentLoop) withMissionVarNames(messages []providers.Message, agent *AgentInstance) []providers.Message {
+	if agent == nil || len(messages) == 0 || messages[0].Role != "system" {
+		return messages
+	}
+	vars := missionVariables(agent.WorkflowEngine)
+	if len(vars) == 0 {
+		return messages
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, "{{"+name+"}}")
+	}
+	sort.Strings(names)
+	messages[0].Content += fmt.Sprintf(
+		"\n\n## Mission Variables\n\nTool arguments may reference %s. They are resolved when the tool runs, so prefer them over typing hosts and ranges out. Session variables can also be written as {{creds.NAME}}.",
+		strings.Join(names, ", "))
+	return messages
+}
+
+// handleMissionCommand handles /mission for the default agent's mission.
+//
+//	/mission                  list mission variables and their values
+//	/mission set NAME value   set a mission variable, e.g. scope.cidr
+func (al *AgentLoop) handleMissionCommand(args []string, content string) string {
+	agent := al.registry.GetDefaultAgent()
+	if agent == nil || agent.WorkflowEngine == nil {
+		return "No active mission"
+	}
+
+	if len(args) == 0 {
+		vars := missionVariables(agent.WorkflowEngine)
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var sb strings.Builder
+		sb.WriteString("Mission variables:")
+		for _, name := range names {
+			fmt.Fprintf(&sb, "\n{{%s}} = %s", name, vars[name])
+		}
+		return sb.String()
+	}
+
+	if args[0] != "set" || len(args) < 3 {
+		return "Usage: /mission [set <NAME> <value>]"
+	}
+	name := args[1]
+	if !missionVarName.MatchString(name) {
+		return fmt.Sprintf("Invalid variable name %q", name)
+	}
+	switch name {
+	case "target", "workflow", "phase":
+		return fmt.Sprintf("%s is set by the mission itself", name)
+	}
+
+	// The value is everything after the name, so it may contain spaces
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "/mission"))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "set"))
+	value := strings.TrimSpace(rest[len(name):])
+	if err := agent.WorkflowEngine.SetVariable(name, value); err != nil {
+		return fmt.Sprintf("Failed to set %s: %v", name, err)
+	}
+	return fmt.Sprintf("Set %s. Tools can reference it as {{%s}}.", name, name)
+}
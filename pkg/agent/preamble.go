@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+var preamblePlaceholder = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+
+// missionPreambleValues collects placeholder values from the active mission:
+// its metadata (engagement_id, authorization, scope, ...) plus target,
+// workflow and phase.
+func missionPreambleValues(engine *workflow.Engine) map[string]string {
+	values := make(map[string]string)
+	if engine == nil {
+		return values
+	}
+
+	state := engine.GetState()
+	if state == nil {
+		return values
+	}
+	for key, value := range state.Metadata {
+		if value == nil {
+			continue
+		}
+		if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+			values[key] = s
+		}
+	}
+	if _, ok := values["scope"]; !ok && (len(state.Scope.Include) > 0 || len(state.Scope.Exclude) > 0 || len(state.Targets) > 0) {
+		values["scope"] = state.Scope.Summary(append([]string{state.Target}, state.Targets...)...)
+	}
+	if state.Target != "" {
+		values["target"] = state.Target
+	}
+	if wf := engine.GetWorkflow(); wf != nil {
+		values["workflow"] = wf.Name
+	}
+	if phase := missionPhase(engine); phase != "" {
+		values["phase"] = phase
+	}
+	return values
+}
+
+// missionPhase returns the name of the active mission's current phase, or
+// "" without a mission.
+func missionPhase(engine *workflow.Engine) string {
+	if engine == nil || engine.GetWorkflow() == nil {
+		return ""
+	}
+	return engine.CurrentPhaseName()
+}
+
+// renderPreamble fills {placeholders} from values. Lines referencing a value
+// that is not set are dropped rather than sent half-filled.
+func renderPreamble(tmpl string, values map[string]string) string {
+	var kept []string
+	for _, line := range strings.Split(tmpl, "\n") {
+		missing := false
+		rendered := preamblePlaceholder.ReplaceAllStringFunc(line, func(m string) string {
+			value, ok := values[m[1:len(m)-1]]
+			if !ok {
+				missing = true
+			}
+			return value
+		})
+		if !missing {
+			kept = append(kept, rendered)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// preambleFor composes the configured preamble for a provider protocol from
+// the agent's active mission. Returns "" when preambles are disabled.
+func (al *AgentLoop) preambleFor(agent *AgentInstance, protocol string) string {
+	cfg := al.cfg.Preamble
+	if !cfg.Enabled || agent == nil {
+		return ""
+	}
+	tmpl, ok := cfg.Providers[protocol]
+	if !ok {
+		tmpl = cfg.Default
+	}
+	if tmpl == "" {
+		return ""
+	}
+	return renderPreamble(tmpl, missionPreambleValues(agent.WorkflowEngine))
+}
+
+// modelProtocol resolves the provider protocol for a model_list name or a
+// raw "protocol/model" reference.
+func (al *AgentLoop) modelProtocol(modelName string) string {
+	for _, model := range al.cfg.ModelList {
+		if model.ModelName == modelName {
+			modelName = model.Model
+			break
+		}
+	}
+	ref := providers.ParseModelRef(modelName, al.cfg.Agents.Defaults.Provider)
+	if ref == nil {
+		return ""
+	}
+	return ref.Provider
+}
+
+// agentForSession returns the agent owning a session key, or the default agent.
+func (al *AgentLoop) agentForSession(sessionKey string) *AgentInstance {
+	if parsed := routing.ParseAgentSessionKey(sessionKey); parsed != nil {
+		if agent, ok := al.registry.GetAgent(parsed.AgentID); ok {
+			return agent
+		}
+	}
+	return al.registry.GetDefaultAgent()
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func TestPreambleFor_ComposesFromMission(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name:   "web-recon",
+		Phases: []workflow.Phase{{Name: "discovery"}},
+	}
+	engine := workflow.NewEngine(wf, "example.com", t.TempDir())
+	if err := engine.SetMetadata("engagement_id", "ENG-42"); err != nil {
+		t.Fatal(err)
+	}
+
+	al := &AgentLoop{cfg: &config.Config{
+		Preamble: config.PreambleConfig{
+			Enabled: true,
+			Default: "Authorized assessment of {target}.",
+			Providers: map[string]string{
+				"anthropic": "Authorized engagement {engagement_id} against {target} ({workflow}/{phase}).\nScope: {scope}",
+			},
+		},
+	}}
+	agent := &AgentInstance{WorkflowEngine: engine}
+
+	got := al.preambleFor(agent, "anthropic")
+	want := "Authorized engagement ENG-42 against example.com (web-recon/discovery)."
+	if got != want {
+		t.Errorf("anthropic preamble = %q, want %q", got, want)
+	}
+
+	if got := al.preambleFor(agent, "openai"); got != "Authorized assessment of example.com." {
+		t.Errorf("default preamble = %q", got)
+	}
+
+	al.cfg.Preamble.Enabled = false
+	if got := al.preambleFor(agent, "anthropic"); got != "" {
+		t.Errorf("disabled preamble = %q, want empty", got)
+	}
+}
+
+func TestWithSystemPreamble(t *testing.T) {
+	messages := []providers.Message{
+		{
+			Role:        "system",
+			Content:     "You are picoclaw.",
+			SystemParts: []providers.ContentBlock{{Type: "text", Text: "You are picoclaw."}},
+		},
+		{Role: "user", Content: "scan"},
+	}
+
+	out := providers.WithSystemPreamble(messages, "Engagement ENG-42.")
+	if out[0].Content != "Engagement ENG-42.\n\n---\n\nYou are picoclaw." {
+		t.Errorf("system content = %q", out[0].Content)
+	}
+	if len(out[0].SystemParts) != 2 || out[0].SystemParts[0].Text != "Engagement ENG-42." {
+		t.Errorf("system parts = %+v", out[0].SystemParts)
+	}
+	if messages[0].Content != "You are picoclaw." || len(messages[0].SystemParts) != 1 {
+		t.Error("input messages were modified")
+	}
+}
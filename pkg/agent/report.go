@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// registerReportTools registers the report drafting and translation tools.
+// It runs after the tier router exists, unlike registerSharedTools, so
+// sections can be routed.
+func registerReportTools(registry *AgentRegistry, tierRouter *routing.TierRouter) {
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil {
+			continue
+		}
+		getEngine := func() *workflow.Engine {
+			return agent.WorkflowEngine
+		}
+		drafter := reportDrafter(agent, tierRouter)
+		agent.Tools.Register(tools.NewWorkflowDraftReportTool(getEngine, drafter))
+		agent.Tools.Register(tools.NewWorkflowTranslateReportTool(getEngine, drafter))
+	}
+}
+
+// reportDrafter sends report sections to the tier router when routing is
+// enabled: routine sections as report_section, the executive summary and
+// risk narrative as report_writing. Translations also go out as
+// report_writing, always supervised, since the client reads them as they
+// come back. Without routing every section goes to the agent's own model.
+func reportDrafter(agent *AgentInstance, tierRouter *routing.TierRouter) workflow.ReportDrafter {
+	return func(ctx context.Context, section workflow.ReportSection, prompt string) (string, error) {
+		messages := []providers.Message{{Role: "user", Content: prompt}}
+		options := map[string]any{
+			"max_tokens":  agent.MaxTokens,
+			"temperature": agent.Temperature,
+		}
+
+		var resp *providers.LLMResponse
+		var err error
+		if tierRouter != nil && tierRouter.IsEnabled() {
+			task := routing.TaskReportWriting
+			if section.Routine() {
+				task = routing.TaskReportSection
+			}
+			if section == workflow.SectionTranslation {
+				routeCtx := routing.WithCostAttribution(ctx, missionPhase(agent.WorkflowEngine), "workflow_translate_report")
+				result, err := tierRouter.RouteWithSupervision(routeCtx, task, messages, nil, options, "report:"+agent.ID,
+					routing.AgentContext{ReportRequested: true, RequiresSupervision: true})
+				if err != nil {
+					return "", err
+				}
+				return strings.TrimSpace(result.FinalOutput), nil
+			}
+			routeCtx := routing.WithCostAttribution(ctx, missionPhase(agent.WorkflowEngine), "workflow_draft_report")
+			resp, err = tierRouter.RouteChat(routeCtx, task, messages, nil, options, "report:"+agent.ID)
+		} else {
+			resp, err = agent.Provider.Chat(ctx, messages, nil, agent.Model, options)
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(resp.Content), nil
+	}
+}
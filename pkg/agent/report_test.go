@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// promptRecorder records the prompts each model received
+type promptRecorder struct {
+	mu      sync.Mutex
+	prompts map[string][]string
+}
+
+func (p *promptRecorder) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prompts[model] = append(p.prompts[model], messages[len(messages)-1].Content)
+	return &providers.LLMResponse{
+		Content: "drafted by " + model,
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	}, nil
+}
+
+func (p *promptRecorder) GetDefaultModel() string {
+	return "heavy-model"
+}
+
+func TestReportDrafter_RoutesSectionsByTier(t *testing.T) {
+	recorder := &promptRecorder{prompts: make(map[string][]string)}
+	routingCfg := &config.RoutingConfig{
+		Enabled:     true,
+		DefaultTier: "heavy",
+		Tiers: map[string]config.TierConfig{
+			"heavy":  {ModelName: "heavy-model", UseFor: []string{"report_writing"}},
+			"medium": {ModelName: "medium-model", UseFor: []string{"report_section"}},
+		},
+	}
+	models := []config.ModelConfig{
+		{ModelName: "heavy-model", Model: "heavy-model"},
+		{ModelName: "medium-model", Model: "medium-model"},
+	}
+	router := routing.NewTierRouter(routingCfg, models, map[string]providers.LLMProvider{
+		"heavy-model":  recorder,
+		"medium-model": recorder,
+	})
+
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{{Name: "recon"}}}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	agent := &AgentInstance{ID: "main", Model: "heavy-model", Provider: recorder, WorkflowEngine: engine}
+
+	if err := engine.AddFinding("Open redirect", "Login redirects anywhere", workflow.SeverityLow, "GET /login?next=evil"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddRedactedFinding("SQL injection", "Search is injectable", workflow.SeverityCritical,
+		"sqlmap dumped users table", workflow.RedactionPartial); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := engine.DraftReport(context.Background(), workflow.NewReportPipeline(reportDrafter(agent, router)), workflow.ReportClient)
+	if err != nil {
+		t.Fatalf("DraftReport() error: %v", err)
+	}
+	if !strings.HasSuffix(path, "app.example.com_client_draft.md") {
+		t.Errorf("path = %q, want client draft", path)
+	}
+
+	// One findings chunk plus methodology go to the medium tier
+	if got := len(recorder.prompts["medium-model"]); got != 2 {
+		t.Errorf("medium tier got %d prompts, want 2", got)
+	}
+	// Executive summary and risk narrative go to the heavy tier
+	if got := len(recorder.prompts["heavy-model"]); got != 2 {
+		t.Errorf("heavy tier got %d prompts, want 2", got)
+	}
+	for model, prompts := range recorder.prompts {
+		for _, prompt := range prompts {
+			if strings.Contains(prompt, "sqlmap dumped users table") {
+				t.Errorf("redacted evidence sent to %s in client draft", model)
+			}
+		}
+	}
+}
+
+func TestReportDrafter_TranslatesOnHeavyTier(t *testing.T) {
+	recorder := &promptRecorder{prompts: make(map[string][]string)}
+	routingCfg := &config.RoutingConfig{
+		Enabled:     true,
+		DefaultTier: "heavy",
+		Tiers: map[string]config.TierConfig{
+			"heavy":  {ModelName: "heavy-model", UseFor: []string{"report_writing"}},
+			"medium": {ModelName: "medium-model", UseFor: []string{"report_section"}},
+		},
+	}
+	models := []config.ModelConfig{
+		{ModelName: "heavy-model", Model: "heavy-model"},
+		{ModelName: "medium-model", Model: "medium-model"},
+	}
+	router := routing.NewTierRouter(routingCfg, models, map[string]providers.LLMProvider{
+		"heavy-model":  recorder,
+		"medium-model": recorder,
+	})
+
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{{Name: "recon"}}}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	agent := &AgentInstance{ID: "main", Model: "heavy-model", Provider: recorder, WorkflowEngine: engine}
+	pipeline := workflow.NewReportPipeline(reportDrafter(agent, router))
+
+	if err := engine.AddRedactedFinding("SQL injection", "Search is injectable", workflow.SeverityCritical,
+		"sqlmap dumped users table", workflow.RedactionPartial); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := engine.TranslateReport(context.Background(), pipeline, workflow.ReportClient, ""); err == nil {
+		t.Error("TranslateReport() without a report language succeeded")
+	}
+	if _, err := engine.TranslateReport(context.Background(), pipeline, workflow.ReportClient, "tlh"); err == nil {
+		t.Error("TranslateReport() into an unsupported language succeeded")
+	}
+
+	if err := engine.SetMetadata(workflow.MetaReportLanguage, "de-AT"); err != nil {
+		t.Fatal(err)
+	}
+	path, err := engine.TranslateReport(context.Background(), pipeline, workflow.ReportClient, "")
+	if err != nil {
+		t.Fatalf("TranslateReport() error: %v", err)
+	}
+	if !strings.HasSuffix(path, "app.example.com_client_de.md") {
+		t.Errorf("path = %q, want German client report", path)
+	}
+
+	if got := len(recorder.prompts["medium-model"]); got != 0 {
+		t.Errorf("medium tier got %d translation prompts, want none", got)
+	}
+	prompts := recorder.prompts["heavy-model"]
+	if len(prompts) != 1 {
+		t.Fatalf("heavy tier got %d prompts, want 1", len(prompts))
+	}
+	for _, want := range []string{"into German", "# Bericht zur Sicherheitsüberprüfung: app.example.com", "| kritisch | 1 |", "- **Schweregrad**: kritisch"} {
+		if !strings.Contains(prompts[0], want) {
+			t.Errorf("translation prompt is missing %q:\n%s", want, prompts[0])
+		}
+	}
+	if strings.Contains(prompts[0], "sqlmap dumped users table") {
+		t.Error("redacted evidence sent for translation of the client report")
+	}
+
+	// Drafts are written in the report language directly
+	if _, err := engine.DraftReport(context.Background(), pipeline, workflow.ReportClient); err != nil {
+		t.Fatalf("DraftReport() error: %v", err)
+	}
+	for _, prompt := range recorder.prompts["medium-model"] {
+		if !strings.Contains(prompt, "Write the section in German") {
+			t.Errorf("draft prompt does not ask for German:\n%s", prompt)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// handleScopeCommand edits the default agent's mission scope from chat, so a
+// running mission picks the change up immediately.
+//
+//	/scope                                     show the scope and recent changes
+//	/scope add|remove [--exclude] ENTRY [reason]
+//	/scope check HOST...
+func (al *AgentLoop) handleScopeCommand(args []string) string {
+	const usage = "Usage: /scope [add|remove [--exclude] <entry> [reason] | target <host> [reason] | check <host>...]"
+
+	agent := al.registry.GetDefaultAgent()
+	if agent == nil || agent.WorkflowEngine == nil {
+		return "No active mission"
+	}
+	engine := agent.WorkflowEngine
+
+	if len(args) == 0 {
+		return tools.FormatScope(engine.GetState())
+	}
+
+	switch args[0] {
+	case "check":
+		if len(args) < 2 {
+			return usage
+		}
+		var sb strings.Builder
+		for _, host := range args[1:] {
+			inScope, reason := engine.CheckScope(host)
+			verdict := "out of scope"
+			if inScope {
+				verdict = "in scope"
+			}
+			fmt.Fprintf(&sb, "%s: %s (%s)\n", host, verdict, reason)
+		}
+		return strings.TrimSpace(sb.String())
+
+	case "add", "remove":
+		change := workflow.ScopeChange{Action: workflow.ScopeAction(args[0]), By: workflow.ScopeByOperator}
+		rest := args[1:]
+		if len(rest) > 0 && rest[0] == "--exclude" {
+			change.Exclude = true
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return usage
+		}
+		change.Entry = rest[0]
+		change.Reason = strings.Join(rest[1:], " ")
+		if err := engine.ChangeScope(change); err != nil {
+			return fmt.Sprintf("Scope unchanged: %v", err)
+		}
+		return tools.FormatScope(engine.GetState())
+
+	case "target":
+		if len(args) < 2 {
+			return usage
+		}
+		if err := engine.AddTarget(args[1], workflow.ScopeByOperator, strings.Join(args[2:], " ")); err != nil {
+			return fmt.Sprintf("Target not added: %v", err)
+		}
+		return tools.FormatScope(engine.GetState())
+	}
+	return usage
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// isSetCommand reports whether content is a /set command, whose value must
+// never be logged or stored in session history.
+func isSetCommand(content string) bool {
+	content = strings.TrimSpace(content)
+	return content == "/set" || strings.HasPrefix(content, "/set ")
+}
+
+// handleSessionVarCommand handles /set and /unset for the routed session.
+//
+//	/set                 list variable names (values are never shown)
+//	/set NAME value      set a variable, referenced by tools as {{NAME}}
+//	/unset NAME          remove a variable
+func (al *AgentLoop) handleSessionVarCommand(msg bus.InboundMessage, sessionKey string) (string, bool) {
+	content := strings.TrimSpace(msg.Content)
+	parts := strings.Fields(content)
+	if len(parts) == 0 || (parts[0] != "/set" && parts[0] != "/unset") {
+		return "", false
+	}
+	if al.sessionVars == nil {
+		return "Session variables are not available", true
+	}
+
+	if parts[0] == "/unset" {
+		if len(parts) != 2 {
+			return "Usage: /unset <NAME>", true
+		}
+		removed, err := al.sessionVars.Unset(sessionKey, parts[1])
+		if err != nil {
+			return fmt.Sprintf("Failed to unset %s: %v", parts[1], err), true
+		}
+		if !removed {
+			return fmt.Sprintf("%s is not set", parts[1]), true
+		}
+		return fmt.Sprintf("Unset %s", parts[1]), true
+	}
+
+	if len(parts) == 1 {
+		names := al.sessionVars.Names(sessionKey)
+		if len(names) == 0 {
+			return "No session variables. Usage: /set <NAME> <value>", true
+		}
+		return fmt.Sprintf("Session variables: %s", strings.Join(names, ", ")), true
+	}
+	if len(parts) < 3 {
+		return "Usage: /set <NAME> <value>", true
+	}
+
+	// The value is everything after the name, so it may contain spaces
+	name := parts[1]
+	rest := strings.TrimSpace(strings.TrimPrefix(content, "/set"))
+	value := strings.TrimSpace(rest[len(name):])
+	if err := al.sessionVars.Set(sessionKey, name, value); err != nil {
+		return fmt.Sprintf("Failed to set %s: %v", name, err), true
+	}
+	return fmt.Sprintf("Set %s for this session. Tools can reference it as {{%s}}.", name, name), true
+}
+
+// withSessionVarNames tells the model which session variables exist, by name
+// only, so it can reference them instead of asking for the secrets.
+func (al *AgentLoop) withSessionVarNames(messages []providers.Message, sessionKey string) []providers.Message {
+	if al.sessionVars == nil || len(messages) == 0 || messages[0].Role != "system" {
+		return messages
+	}
+	names := al.sessionVars.Names(sessionKey)
+	if len(names) == 0 {
+		return messages
+	}
+
+	refs := make([]string, len(names))
+	for i, name := range names {
+		refs[i] = "{{" + name + "}}"
+	}
+	messages[0].Content += fmt.Sprintf(
+		"\n\n## Session Variables\n\nThe operator has set: %s. Use these references in tool arguments such as web_fetch URLs and headers or exec commands (exec also exports them as environment variables). Values are substituted at execution time and redacted from tool output; never ask for them or try to print them.",
+		strings.Join(refs, ", "))
+	return messages
+}
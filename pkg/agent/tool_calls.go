@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/utils"
+)
+
+// defaultParallelTools caps concurrent tool calls when parallel tools are
+// enabled without a limit
+const defaultParallelTools = 4
+
+// executeToolCalls runs the tool calls of one response and returns their
+// results in call order. With agents.defaults.parallel_tools enabled,
+// consecutive calls run concurrently up to the configured limit. Calls to
+// stateful tools run alone, in order; see isSerialTool.
+func (al *AgentLoop) executeToolCalls(
+	ctx context.Context,
+	agent *AgentInstance,
+	calls []providers.ToolCall,
+	opts processOptions,
+	iteration int,
+) []*tools.ToolResult {
+	results := make([]*tools.ToolResult, len(calls))
+	cfg := al.parallelToolsConfig()
+	if !cfg.Enabled || len(calls) < 2 {
+		for i, tc := range calls {
+			results[i] = al.executeToolCall(ctx, agent, tc, opts, iteration)
+		}
+		return results
+	}
+
+	limit := cfg.MaxConcurrency
+	if limit <= 0 {
+		limit = defaultParallelTools
+	}
+	logger.DebugCF("agent", "Executing tool calls in parallel",
+		map[string]any{
+			"agent_id":  agent.ID,
+			"count":     len(calls),
+			"limit":     limit,
+			"iteration": iteration,
+		})
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, tc := range calls {
+		if isSerialTool(agent.Tools, tc.Name) {
+			wg.Wait()
+			results[i] = al.executeToolCall(ctx, agent, tc, opts, iteration)
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, tc providers.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = al.executeToolCall(ctx, agent, tc, opts, iteration)
+		}(i, tc)
+	}
+	wg.Wait()
+	return results
+}
+
+// serialTools change state that a later call in the same response may read:
+// a file the next exec runs, or a pinned fact
+var serialTools = map[string]bool{
+	"write_file":  true,
+	"edit_file":   true,
+	"append_file": true,
+	"pin":         true,
+}
+
+// isSerialTool reports whether calls to name must not overlap other calls.
+// Mission calls depend on each other (complete the step, then advance the
+// phase), and contextual and async tools get their chat and callback set on
+// the shared instance for every call, so two overlapping calls would race.
+func isSerialTool(registry *tools.ToolRegistry, name string) bool {
+	if strings.HasPrefix(name, "workflow_") || serialTools[name] {
+		return true
+	}
+	tool, ok := registry.Get(name)
+	if !ok {
+		return false
+	}
+	_, ok = tool.(tools.ContextualTool)
+	return ok || isAsyncTool(registry, name)
+}
+
+// isAsyncTool reports whether name is a tool that finishes in the background
+func isAsyncTool(registry *tools.ToolRegistry, name string) bool {
+	tool, ok := registry.Get(name)
+	if !ok {
+		return false
+	}
+	_, ok = tool.(tools.AsyncTool)
+	return ok
+}
+
+// executeToolCall runs one tool call, giving up after the configured per-tool
+// timeout. A tool that ignores cancellation keeps running in the background,
+// but the agent moves on with a timeout error. Async tools are not timed:
+// they return at once and hand the call's context to their background work,
+// which the timeout would cancel as soon as the call returned.
+func (al *AgentLoop) executeToolCall(
+	ctx context.Context,
+	agent *AgentInstance,
+	tc providers.ToolCall,
+	opts processOptions,
+	iteration int,
+) *tools.ToolResult {
+	argsJSON, _ := json.Marshal(tc.Arguments)
+	argsPreview := utils.Truncate(string(argsJSON), 200)
+	logger.InfoCF("agent", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
+		map[string]any{
+			"agent_id":  agent.ID,
+			"tool":      tc.Name,
+			"iteration": iteration,
+		})
+
+	// Create async callback for tools that implement AsyncTool
+	// NOTE: Following openclaw's design, async tools do NOT send results directly to users.
+	// Instead, they notify the agent via PublishInbound, and the agent decides
+	// whether to forward the result to the user (in processSystemMessage).
+	asyncCallback := func(callbackCtx context.Context, result *tools.ToolResult) {
+		// Log the async completion but don't send directly to user
+		// The agent will handle user notification via processSystemMessage
+		if !result.Silent && result.ForUser != "" {
+			logger.InfoCF("agent", "Async tool completed, agent will handle notification",
+				map[string]any{
+					"tool":        tc.Name,
+					"content_len": len(result.ForUser),
+				})
+		}
+	}
+
+	timeout := time.Duration(al.parallelToolsConfig().TimeoutSeconds) * time.Second
+	if timeout <= 0 || isAsyncTool(agent.Tools, tc.Name) {
+		return agent.Tools.ExecuteWithContext(ctx, tc.Name, tc.Arguments, opts.Channel, opts.ChatID, asyncCallback)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan *tools.ToolResult, 1)
+	go func() {
+		done <- agent.Tools.ExecuteWithContext(callCtx, tc.Name, tc.Arguments, opts.Channel, opts.ChatID, asyncCallback)
+	}()
+	select {
+	case result := <-done:
+		return result
+	case <-callCtx.Done():
+		logger.WarnCF("agent", "Tool call timed out",
+			map[string]any{
+				"agent_id": agent.ID,
+				"tool":     tc.Name,
+				"timeout":  timeout.String(),
+			})
+		result := tools.ErrorResult(fmt.Sprintf("Tool %s timed out after %s", tc.Name, timeout))
+		result.Err = callCtx.Err()
+		return result
+	}
+}
+
+// recordToolCall adds a finished tool call to the mission journal. The
+// arguments are the ones the model wrote, so variable references such as
+// {{creds.admin}} stay unexpanded.
+func recordToolCall(agent *AgentInstance, tc providers.ToolCall, result *tools.ToolResult) {
+	if agent.WorkflowEngine == nil {
+		return
+	}
+	argsJSON, _ := json.Marshal(tc.Arguments)
+	summary := fmt.Sprintf("%s %s", tc.Name, utils.Truncate(string(argsJSON), 200))
+	if err := agent.WorkflowEngine.RecordToolCall(summary, result.IsError); err != nil {
+		logger.WarnCF("agent", "Failed to record tool call", map[string]any{"error": err.Error()})
+	}
+}
+
+// parallelToolsConfig returns the parallel tool settings, zero without config
+func (al *AgentLoop) parallelToolsConfig() config.ParallelToolsConfig {
+	if al.cfg == nil {
+		return config.ParallelToolsConfig{}
+	}
+	return al.cfg.Agents.Defaults.ParallelTools
+}
@@ -47,17 +47,24 @@ func (f *FlexibleStringSlice) UnmarshalJSON(data []byte) error {
 }
 
 type Config struct {
-	Agents    AgentsConfig    `json:"agents"`
-	Bindings  []AgentBinding  `json:"bindings,omitempty"`
-	Session   SessionConfig   `json:"session"`
-	Channels  ChannelsConfig  `json:"channels"`
-	Providers ProvidersConfig `json:"providers"`
-	ModelList []ModelConfig   `json:"model_list"` // New model-centric provider configuration
-	Routing   RoutingConfig   `json:"routing" env:"-"` // Tier-based model routing
-	Gateway   GatewayConfig   `json:"gateway"`
-	Tools     ToolsConfig     `json:"tools"`
-	Heartbeat HeartbeatConfig `json:"heartbeat"`
-	Devices   DevicesConfig   `json:"devices"`
+	Agents         AgentsConfig         `json:"agents"`
+	Bindings       []AgentBinding       `json:"bindings,omitempty"`
+	Session        SessionConfig        `json:"session"`
+	Channels       ChannelsConfig       `json:"channels"`
+	Providers      ProvidersConfig      `json:"providers"`
+	ModelList      []ModelConfig        `json:"model_list"`      // New model-centric provider configuration
+	Routing        RoutingConfig        `json:"routing" env:"-"` // Tier-based model routing
+	Gateway        GatewayConfig        `json:"gateway"`
+	Tools          ToolsConfig          `json:"tools"`
+	Heartbeat      HeartbeatConfig      `json:"heartbeat"`
+	ProviderHealth ProviderHealthConfig `json:"provider_health,omitempty"`
+	Devices        DevicesConfig        `json:"devices"`
+	Preamble       PreambleConfig       `json:"preamble,omitempty"`
+	Determinism    DeterminismConfig    `json:"determinism,omitempty"`
+	Monitor        MonitorConfig        `json:"monitor,omitempty"`
+	Transcripts    TranscriptConfig     `json:"transcripts,omitempty"`
+	Workflows      WorkflowsConfig      `json:"workflows,omitempty"`
+	Trust          TrustConfig          `json:"trust,omitempty"`
 }
 
 // MarshalJSON implements custom JSON marshaling for Config
@@ -181,6 +188,15 @@ type AgentDefaults struct {
 	Temperature         *float64 `json:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations   int      `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
 	CLAWMode            *CLAWConfig `json:"claw,omitempty"`
+	ParallelTools       ParallelToolsConfig `json:"parallel_tools,omitempty"`
+}
+
+// ParallelToolsConfig runs the tool calls of one model response concurrently
+// instead of one after another. Results are still returned in call order.
+type ParallelToolsConfig struct {
+	Enabled        bool `json:"enabled"                   env:"PICOCLAW_AGENTS_DEFAULTS_PARALLEL_TOOLS_ENABLED"`
+	MaxConcurrency int  `json:"max_concurrency,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_PARALLEL_TOOLS_MAX_CONCURRENCY"` // 0 = 4
+	TimeoutSeconds int  `json:"timeout_seconds,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_PARALLEL_TOOLS_TIMEOUT_SECONDS"` // Per tool call, also when not parallel; 0 = no limit
 }
 
 // CLAWConfig configures CLAW orchestrator mode
@@ -321,6 +337,151 @@ type HeartbeatConfig struct {
 	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
 }
 
+// ProviderHealthConfig controls the gateway's background provider health
+// checker. Every model in model_list is probed each Interval, by listing
+// models where the provider supports it and with a 1-token request
+// otherwise. Failing providers are put in cooldown so fallback skips them,
+// and degradation and recovery are announced on the last active channel.
+type ProviderHealthConfig struct {
+	Enabled  bool `json:"enabled"            env:"PICOCLAW_PROVIDER_HEALTH_ENABLED"`
+	Interval int  `json:"interval,omitempty" env:"PICOCLAW_PROVIDER_HEALTH_INTERVAL"` // seconds, default 300
+	Timeout  int  `json:"timeout,omitempty"  env:"PICOCLAW_PROVIDER_HEALTH_TIMEOUT"`  // seconds per probe, default 15
+}
+
+// MonitorConfig controls continuous monitoring. Every Interval each check
+// is run against each target and its output parsed into an inventory of
+// subdomains, open ports, web endpoints and vulnerabilities. Changes from
+// the previous run are alerted on the last active channel, WebhookURL and
+// Email, and with Findings set are recorded on the target's mission.
+// Endpoints are fetched each run and their responses compared with the
+// golden copy saved on the first run; with Assess set, only the diff of a
+// changed response is sent to the model for assessment.
+type MonitorConfig struct {
+	Enabled    bool               `json:"enabled"                env:"PICOCLAW_MONITOR_ENABLED"`
+	Interval   int                `json:"interval,omitempty"     env:"PICOCLAW_MONITOR_INTERVAL"` // seconds, default 3600
+	Targets    []string           `json:"targets,omitempty"      env:"PICOCLAW_MONITOR_TARGETS"`
+	Checks     []MonitorCheck     `json:"checks,omitempty"`
+	Endpoints  []MonitorEndpoint  `json:"endpoints,omitempty"`
+	Assess     bool               `json:"assess,omitempty"       env:"PICOCLAW_MONITOR_ASSESS"`
+	Removals   bool               `json:"removals,omitempty"     env:"PICOCLAW_MONITOR_REMOVALS"` // Also alert when items disappear
+	Findings   bool               `json:"findings,omitempty"     env:"PICOCLAW_MONITOR_FINDINGS"`
+	WebhookURL string             `json:"webhook_url,omitempty"  env:"PICOCLAW_MONITOR_WEBHOOK_URL"`
+	Email      MonitorEmailConfig `json:"email,omitempty"`
+}
+
+// MonitorCheck is one lightweight recon command. {target} in Command is
+// replaced with the shell-quoted target; Parser names how its output is read:
+// nmap_xml, subdomains, httpx, nuclei or lines.
+type MonitorCheck struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Parser  string `json:"parser"`
+	Timeout int    `json:"timeout,omitempty"` // seconds, default 600
+}
+
+// MonitorEndpoint is a key endpoint whose response is compared with a
+// golden copy. {target} in URL is replaced with the target. Ignore holds
+// regular expressions for text that changes on every request, such as
+// timestamps and CSRF tokens.
+type MonitorEndpoint struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // default GET
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Ignore  []string          `json:"ignore,omitempty"`
+	Timeout int               `json:"timeout,omitempty"` // seconds, default 30
+}
+
+// MonitorEmailConfig sends monitoring alerts over SMTP. Alerts are emailed
+// when SMTPHost and To are set.
+type MonitorEmailConfig struct {
+	SMTPHost string   `json:"smtp_host,omitempty" env:"PICOCLAW_MONITOR_EMAIL_SMTP_HOST"`
+	SMTPPort int      `json:"smtp_port,omitempty" env:"PICOCLAW_MONITOR_EMAIL_SMTP_PORT"` // default 587
+	Username string   `json:"username,omitempty"  env:"PICOCLAW_MONITOR_EMAIL_USERNAME"`
+	Password string   `json:"password,omitempty"  env:"PICOCLAW_MONITOR_EMAIL_PASSWORD"`
+	From     string   `json:"from,omitempty"      env:"PICOCLAW_MONITOR_EMAIL_FROM"`
+	To       []string `json:"to,omitempty"        env:"PICOCLAW_MONITOR_EMAIL_TO"`
+}
+
+// PreambleConfig defines the system prompt preamble placed ahead of the agent
+// prompt, such as an authorization statement, engagement ID and scope summary.
+// Templates are keyed by provider protocol (e.g. "anthropic", "openai") and
+// fall back to Default. Placeholders like {target}, {workflow}, {phase},
+// {engagement_id}, {authorization} and {scope} are filled from the active
+// mission; any other {key} is looked up in the mission metadata. Lines whose
+// placeholders cannot be resolved are dropped.
+type PreambleConfig struct {
+	Enabled   bool              `json:"enabled"             env:"PICOCLAW_PREAMBLE_ENABLED"`
+	Default   string            `json:"default,omitempty"   env:"PICOCLAW_PREAMBLE_DEFAULT"`
+	Providers map[string]string `json:"providers,omitempty"`
+}
+
+// DeterminismConfig turns on seeded mode for demos and regression tests.
+// IDs, random draws and timestamps come from Seed, the default temperature
+// drops to 0 and the seed is sent to providers that accept one. With a
+// cassette, LLM calls are recorded to the file ("record") or served from it
+// ("replay") so whole runs reproduce without network access.
+type DeterminismConfig struct {
+	Enabled      bool   `json:"enabled"                 env:"PICOCLAW_DETERMINISM_ENABLED"`
+	Seed         int64  `json:"seed"                    env:"PICOCLAW_DETERMINISM_SEED"`
+	Cassette     string `json:"cassette,omitempty"      env:"PICOCLAW_DETERMINISM_CASSETTE"`
+	CassetteMode string `json:"cassette_mode,omitempty" env:"PICOCLAW_DETERMINISM_CASSETTE_MODE"`
+}
+
+// TranscriptConfig turns on the wire recorder for debugging provider
+// traffic. Every request and response is appended to <dir>/<session>.jsonl
+// with API keys and other secrets redacted. Transcripts can be served back
+// with a "replay/<file>" model.
+type TranscriptConfig struct {
+	Enabled bool     `json:"enabled"          env:"PICOCLAW_TRANSCRIPTS_ENABLED"`
+	Dir     string   `json:"dir,omitempty"    env:"PICOCLAW_TRANSCRIPTS_DIR"` // Default <workspace>/transcripts
+	Redact  []string `json:"redact,omitempty"`                                // Extra regular expressions to redact; a first capture group is kept
+}
+
+// WorkflowsConfig sets where 'picoclaw workflow fetch' resolves registry
+// refs such as "web-app-assessment@1.2.0". The registry is a JSON index of
+// workflow names, versions, download URLs and SHA-256 checksums.
+type WorkflowsConfig struct {
+	Registry        string                `json:"registry,omitempty"         env:"PICOCLAW_WORKFLOWS_REGISTRY"`
+	CompletionJudge CompletionJudgeConfig `json:"completion_judge,omitempty"`
+	MaxParallelBranches int               `json:"max_parallel_branches,omitempty" env:"PICOCLAW_WORKFLOWS_MAX_PARALLEL_BRANCHES"` // Branch sub-agents running at once (0 = 3)
+	BranchTimeoutMinutes int              `json:"branch_timeout_minutes,omitempty" env:"PICOCLAW_WORKFLOWS_BRANCH_TIMEOUT_MINUTES"` // Per branch sub-agent (0 = 30)
+	Fallbacks       map[string][]ToolFallbackConfig `json:"fallbacks,omitempty" env:"-"` // Stand-ins for missing tools, over the built-in ones; an empty list means none
+	KeepRevisions   int                   `json:"keep_revisions,omitempty" env:"PICOCLAW_WORKFLOWS_KEEP_REVISIONS"` // Mission state snapshots kept for rollback (0 = 50)
+}
+
+// ToolFallbackConfig is a tool to use when a workflow step's tool is missing
+type ToolFallbackConfig struct {
+	Tool string `json:"tool"`
+	Hint string `json:"hint,omitempty"` // How to use it for the same job
+}
+
+// CompletionJudgeConfig lets the supervisor tier decide whether a phase's
+// custom (prose) completion criteria are met, which nothing else can check
+type CompletionJudgeConfig struct {
+	Enabled       bool    `json:"enabled"                  env:"PICOCLAW_WORKFLOWS_COMPLETION_JUDGE_ENABLED"`
+	MinConfidence float64 `json:"min_confidence,omitempty" env:"PICOCLAW_WORKFLOWS_COMPLETION_JUDGE_MIN_CONFIDENCE"` // Verdicts below it don't count (0 = 0.8)
+}
+
+// TrustConfig is the signature policy for workflows and skills fetched from
+// outside the workspace, and for the binaries of MCP tool servers (plugins).
+// Each kind is off (default), warn or require; signatures are minisign
+// .minisig files published next to the artifact.
+type TrustConfig struct {
+	Keys      []TrustedKeyConfig `json:"keys,omitempty"      env:"-"`
+	Workflows string             `json:"workflows,omitempty" env:"PICOCLAW_TRUST_WORKFLOWS"`
+	Skills    string             `json:"skills,omitempty"    env:"PICOCLAW_TRUST_SKILLS"`
+	Plugins   string             `json:"plugins,omitempty"   env:"PICOCLAW_TRUST_PLUGINS"`
+}
+
+// TrustedKeyConfig is a minisign public key whose signatures are trusted
+type TrustedKeyConfig struct {
+	Name      string   `json:"name,omitempty"`
+	PublicKey string   `json:"public_key"`       // The base64 line of the minisign .pub file
+	Scopes    []string `json:"scopes,omitempty"` // workflows, skills, plugins; empty = all
+}
+
 type DevicesConfig struct {
 	Enabled    bool `json:"enabled"     env:"PICOCLAW_DEVICES_ENABLED"`
 	MonitorUSB bool `json:"monitor_usb" env:"PICOCLAW_DEVICES_MONITOR_USB"`
@@ -396,7 +557,7 @@ type OpenAIProviderConfig struct {
 // ModelConfig represents a model-centric provider configuration.
 // It allows adding new providers (especially OpenAI-compatible ones) via configuration only.
 // The model field uses protocol prefix format: [protocol/]model-identifier
-// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot, llamacpp
 // Default protocol is "openai" if no prefix is specified.
 type ModelConfig struct {
 	// Required fields
@@ -404,9 +565,13 @@ type ModelConfig struct {
 	Model     string `json:"model"`      // Protocol/model-identifier (e.g., "openai/gpt-4o", "anthropic/claude-sonnet-4.6")
 
 	// HTTP-based providers
-	APIBase string `json:"api_base,omitempty"` // API endpoint URL
-	APIKey  string `json:"api_key"`            // API authentication key
-	Proxy   string `json:"proxy,omitempty"`    // HTTP proxy URL
+	APIBase            string            `json:"api_base,omitempty"`             // API endpoint URL
+	APIKey             string            `json:"api_key"`                        // API authentication key
+	Proxy              string            `json:"proxy,omitempty"`                // HTTP proxy URL
+	Timeout            int               `json:"timeout,omitempty"`              // Request timeout in seconds; 0 = 120
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"` // Skip TLS verification, e.g. behind Burp
+	CACert             string            `json:"ca_cert,omitempty"`              // PEM CA bundle trusted in addition to system roots
+	Headers            map[string]string `json:"headers,omitempty"`              // Extra headers sent with every request
 
 	// Special providers (CLI-based, OAuth, etc.)
 	AuthMethod  string `json:"auth_method,omitempty"`  // Authentication method: oauth, token
@@ -414,8 +579,55 @@ type ModelConfig struct {
 	Workspace   string `json:"workspace,omitempty"`    // Workspace path for CLI-based providers
 
 	// Optional optimizations
-	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit
-	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
+	RPM               int    `json:"rpm,omitempty"`                // Requests per minute limit
+	TPM               int    `json:"tpm,omitempty"`                // Tokens per minute limit (prompt estimate + max_tokens)
+	MaxTokensField    string `json:"max_tokens_field,omitempty"`   // Field name for max tokens (e.g., "max_completion_tokens")
+	StructuredOutput  *bool  `json:"structured_output,omitempty"`  // Supports response_format json_schema; inferred from protocol when unset
+	ContextWindow     int    `json:"context_window,omitempty"`     // Max input+output tokens; 0 = unknown (no fit check)
+	Vision            *bool  `json:"vision,omitempty"`             // Accepts image input; images are stripped for other models
+	ToolCallFormat    string `json:"tool_call_format,omitempty"`   // How a local model writes tool calls in text: auto, functioncall, hermes, llama3, mistral, json_block or none
+	RequestValidation string `json:"request_validation,omitempty"` // Pre-flight message checks: repair (default), strict or off
+
+	// OpenRouter provider routing and model fallbacks
+	OpenRouter *OpenRouterConfig `json:"openrouter,omitempty"`
+
+	// llama.cpp server endpoint and grammar settings
+	LlamaCpp *LlamaCppConfig `json:"llamacpp,omitempty"`
+}
+
+// LlamaCppConfig tunes a "llamacpp/" model served by llama-server.
+type LlamaCppConfig struct {
+	Endpoint       string `json:"endpoint,omitempty"`        // "chat" (/v1/chat/completions, default) or "completion" (/completion)
+	DisableGrammar bool   `json:"disable_grammar,omitempty"` // Don't constrain tool calls with a GBNF grammar
+}
+
+// OpenRouterConfig is sent with every request to an OpenRouter model as its
+// provider routing block and models fallback array, so OpenRouter picks the
+// upstream provider and falls back to other models itself.
+type OpenRouterConfig struct {
+	Order             []string `json:"order,omitempty"`              // Provider slugs to try first, in order
+	Only              []string `json:"only,omitempty"`               // Allow list of provider slugs
+	Ignore            []string `json:"ignore,omitempty"`             // Deny list of provider slugs
+	AllowFallbacks    *bool    `json:"allow_fallbacks,omitempty"`    // Use providers beyond Order; OpenRouter defaults to true
+	RequireParameters bool     `json:"require_parameters,omitempty"` // Only providers supporting every request parameter
+	DataCollection    string   `json:"data_collection,omitempty"`    // "allow" or "deny" providers that store prompts
+	Sort              string   `json:"sort,omitempty"`               // "price", "throughput" or "latency"
+	Fallbacks         []string `json:"fallbacks,omitempty"`          // OpenRouter model IDs tried after the primary model
+}
+
+// Validate checks the enumerated OpenRouter settings.
+func (c *OpenRouterConfig) Validate() error {
+	switch c.DataCollection {
+	case "", "allow", "deny":
+	default:
+		return fmt.Errorf("openrouter.data_collection must be \"allow\" or \"deny\", got %q", c.DataCollection)
+	}
+	switch c.Sort {
+	case "", "price", "throughput", "latency":
+	default:
+		return fmt.Errorf("openrouter.sort must be price, throughput or latency, got %q", c.Sort)
+	}
+	return nil
 }
 
 // Validate checks if the ModelConfig has all required fields.
@@ -426,12 +638,44 @@ func (c *ModelConfig) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	switch c.ToolCallFormat {
+	case "", "auto", "functioncall", "hermes", "llama3", "mistral", "json_block", "none":
+	default:
+		return fmt.Errorf("tool_call_format must be auto, functioncall, hermes, llama3, mistral, json_block or none, got %q", c.ToolCallFormat)
+	}
+	if c.LlamaCpp != nil {
+		switch c.LlamaCpp.Endpoint {
+		case "", "chat", "completion":
+		default:
+			return fmt.Errorf("llamacpp.endpoint must be chat or completion, got %q", c.LlamaCpp.Endpoint)
+		}
+	}
+	if c.OpenRouter != nil {
+		return c.OpenRouter.Validate()
+	}
 	return nil
 }
 
 type GatewayConfig struct {
-	Host string `json:"host" env:"PICOCLAW_GATEWAY_HOST"`
-	Port int    `json:"port" env:"PICOCLAW_GATEWAY_PORT"`
+	Host     string `json:"host"                env:"PICOCLAW_GATEWAY_HOST"`
+	Port     int    `json:"port"                env:"PICOCLAW_GATEWAY_PORT"`
+	GRPCPort int    `json:"grpc_port,omitempty" env:"PICOCLAW_GATEWAY_GRPC_PORT"` // gRPC API port; 0 disables it
+
+	// TLS certificate and key for the gRPC API; without them it is plaintext
+	GRPCTLSCert string `json:"grpc_tls_cert,omitempty" env:"PICOCLAW_GATEWAY_GRPC_TLS_CERT"`
+	GRPCTLSKey  string `json:"grpc_tls_key,omitempty"  env:"PICOCLAW_GATEWAY_GRPC_TLS_KEY"`
+
+	DashboardPort int `json:"dashboard_port,omitempty" env:"PICOCLAW_GATEWAY_DASHBOARD_PORT"` // Web dashboard port; 0 disables it
+
+	Users []GatewayUser `json:"users,omitempty"` // Dashboard and gRPC API users; without any the dashboard is open to viewers
+}
+
+// GatewayUser is a bearer token for the web dashboard and the role it grants:
+// viewer, client or operator. Operator tokens also open the gRPC API.
+type GatewayUser struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	Role  string `json:"role"`
 }
 
 type BraveConfig struct {
@@ -476,13 +720,68 @@ type ExecConfig struct {
 	EnableDenyPatterns bool     `json:"enable_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_ENABLE_DENY_PATTERNS"`
 	CustomDenyPatterns []string `json:"custom_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
 	TimeoutSeconds     int      `json:"timeout_seconds,omitempty" env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"` // Default 300 (5 min); 0 = no timeout
+	Record             bool     `json:"record,omitempty" env:"PICOCLAW_TOOLS_EXEC_RECORD"`                           // Save every command as an asciinema cast under evidence/recordings
+}
+
+// AskOperatorConfig configures the ask_operator tool. Without an interactive
+// UI, questions are POSTed to WebhookURL (if set) as JSON notifications.
+type AskOperatorConfig struct {
+	WebhookURL string `json:"webhook_url,omitempty" env:"PICOCLAW_TOOLS_ASK_OPERATOR_WEBHOOK_URL"`
+}
+
+// BatchToolConfig configures the batch_submit tool, registered when the
+// provider has an offline batch API (Anthropic Message Batches).
+type BatchToolConfig struct {
+	Disabled            bool `json:"disabled,omitempty"              env:"PICOCLAW_TOOLS_BATCH_DISABLED"`
+	PollIntervalSeconds int  `json:"poll_interval_seconds,omitempty" env:"PICOCLAW_TOOLS_BATCH_POLL_INTERVAL_SECONDS"`
+}
+
+// TruncationConfig controls how oversized tool output is cut down before it
+// reaches the model. Strategies: head, head_tail (default), structured
+// (sampling JSON/JSONL/XML records) and sections (a budget per section).
+type TruncationConfig struct {
+	Strategy string                    `json:"strategy,omitempty"  env:"PICOCLAW_TOOLS_TRUNCATION_STRATEGY"`
+	MaxChars int                       `json:"max_chars,omitempty" env:"PICOCLAW_TOOLS_TRUNCATION_MAX_CHARS"` // 0 = 10000
+	Tools    map[string]TruncationRule `json:"tools,omitempty"     env:"-"`                                  // Keyed by tool or exec'd command name (nmap, nuclei)
+}
+
+// TruncationRule overrides the truncation strategy for one tool. Zero fields
+// inherit the tools.truncation defaults.
+type TruncationRule struct {
+	Strategy string `json:"strategy,omitempty"`
+	MaxChars int    `json:"max_chars,omitempty"`
 }
 
 type ToolsConfig struct {
-	Web    WebToolsConfig    `json:"web"`
-	Cron   CronToolsConfig   `json:"cron"`
-	Exec   ExecConfig        `json:"exec"`
-	Skills SkillsToolsConfig `json:"skills"`
+	Web         WebToolsConfig    `json:"web"`
+	Cron        CronToolsConfig   `json:"cron"`
+	Exec        ExecConfig        `json:"exec"`
+	Skills      SkillsToolsConfig `json:"skills"`
+	AskOperator AskOperatorConfig `json:"ask_operator"`
+	Truncation  TruncationConfig  `json:"truncation"`
+	Batch       BatchToolConfig   `json:"batch"`
+	ArgRepair   ArgRepairConfig   `json:"arg_repair"`
+	Python      PythonConfig      `json:"python"`
+}
+
+// PythonConfig configures the python tool, which runs the agent's scripts in
+// a sandbox whose only writable directory is the mission directory. Sandbox
+// is auto (docker if installed, else subprocess), docker or subprocess.
+type PythonConfig struct {
+	Disabled       bool   `json:"disabled,omitempty"        env:"PICOCLAW_TOOLS_PYTHON_DISABLED"`
+	Sandbox        string `json:"sandbox,omitempty"         env:"PICOCLAW_TOOLS_PYTHON_SANDBOX"`
+	Image          string `json:"image,omitempty"           env:"PICOCLAW_TOOLS_PYTHON_IMAGE"`           // docker sandbox; default python:3.12-slim
+	Interpreter    string `json:"interpreter,omitempty"     env:"PICOCLAW_TOOLS_PYTHON_INTERPRETER"`     // subprocess sandbox; default python3
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" env:"PICOCLAW_TOOLS_PYTHON_TIMEOUT_SECONDS"` // Default 120
+	MemoryMB       int    `json:"memory_mb,omitempty"       env:"PICOCLAW_TOOLS_PYTHON_MEMORY_MB"`       // Default 512
+	AllowNetwork   bool   `json:"allow_network,omitempty"   env:"PICOCLAW_TOOLS_PYTHON_ALLOW_NETWORK"`
+}
+
+// ArgRepairConfig controls repair of tool calls whose arguments a model sent
+// as malformed JSON. Lenient parsing always runs; LLM enables a follow-up
+// repair call on the cheapest tier for what lenient parsing cannot fix.
+type ArgRepairConfig struct {
+	LLM bool `json:"llm,omitempty" env:"PICOCLAW_TOOLS_ARG_REPAIR_LLM"`
 }
 
 type SkillsToolsConfig struct {
@@ -555,6 +854,8 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	cfg.ApplyProviderPresets()
+
 	return cfg, nil
 }
 
@@ -709,17 +1010,142 @@ type RoutingConfig struct {
 	SupervisorTier              string                 `json:"supervisor_tier" env:"PICOCLAW_ROUTING_SUPERVISOR_TIER"`
 	ValidationConfidenceThreshold float64              `json:"validation_confidence_threshold" env:"PICOCLAW_ROUTING_VALIDATION_CONFIDENCE_THRESHOLD"`
 	MinTaskComplexityForSupervision int                 `json:"min_task_complexity_for_supervision" env:"PICOCLAW_ROUTING_MIN_TASK_COMPLEXITY"`
+	SupervisionRejectionLimit   int                    `json:"supervision_rejection_limit,omitempty" env:"PICOCLAW_ROUTING_SUPERVISION_REJECTION_LIMIT"` // Consecutive rejections before a task type is escalated to the supervisor (0 = default 3, <0 = disabled)
+	Refusal                     RefusalConfig          `json:"refusal,omitempty"`
+	ToolEnforcement             ToolEnforcementConfig  `json:"tool_enforcement,omitempty"`
+	ResponseCache               ResponseCacheConfig    `json:"response_cache,omitempty"`
+	TaskOverrides               map[string]TaskOverride `json:"task_overrides,omitempty" env:"-"` // Keyed by task type (parsing, planning, ...)
+	Speculative                 SpeculativeConfig      `json:"speculative,omitempty"`
+	ProviderRateLimits          map[string]RateLimitConfig `json:"provider_rate_limits,omitempty" env:"-"` // Keyed by protocol (openai, anthropic, ...); shared by all its models
+	Hedging                     HedgingConfig          `json:"hedging,omitempty"`
+	Policies                    []RoutingPolicyConfig  `json:"policies,omitempty" env:"-"`
+	Embeddings                  EmbeddingsConfig       `json:"embeddings,omitempty"`
+	SpendGovernor               SpendGovernorConfig    `json:"spend_governor,omitempty"`
+	Pipelines                   map[string][]PipelineStageConfig `json:"pipelines,omitempty" env:"-"` // Named output pipelines, attached to task types by task_overrides
+}
+
+// PipelineStageConfig is one stage of an output pipeline: strip_markdown,
+// extract_json, validate_schema or store_artifact (see pkg/pipeline)
+type PipelineStageConfig struct {
+	Type   string         `json:"type"`
+	Schema map[string]any `json:"schema,omitempty"` // validate_schema: JSON Schema the output must match
+	Dir    string         `json:"dir,omitempty"`    // store_artifact: directory, relative to the workspace (default artifacts/pipelines)
+}
+
+// SpendGovernorConfig paces routed provider spend against a daily budget
+// shared by every session of the process (all chats in gateway mode), so one
+// runaway mission cannot use up the day's budget in an hour.
+type SpendGovernorConfig struct {
+	DailyBudget    float64 `json:"daily_budget,omitempty"     env:"PICOCLAW_ROUTING_SPEND_GOVERNOR_DAILY_BUDGET"`     // USD per 24h across all sessions (0 = disabled)
+	Burst          float64 `json:"burst,omitempty"            env:"PICOCLAW_ROUTING_SPEND_GOVERNOR_BURST"`            // USD that may be spent at once before pacing starts (0 = 1/24 of daily_budget)
+	SessionShare   float64 `json:"session_share,omitempty"    env:"PICOCLAW_ROUTING_SPEND_GOVERNOR_SESSION_SHARE"`    // Largest fraction of daily_budget one session may spend per 24h (0 = no per-session cap)
+	MaxWaitSeconds int     `json:"max_wait_seconds,omitempty" env:"PICOCLAW_ROUTING_SPEND_GOVERNOR_MAX_WAIT_SECONDS"` // Longest a request queues for budget before it is refused (0 = 60)
+}
+
+// EmbeddingsConfig selects the model_list entry used for text embeddings.
+// Its spend is tracked with the routed chat calls.
+type EmbeddingsConfig struct {
+	ModelName string  `json:"model_name,omitempty" env:"PICOCLAW_ROUTING_EMBEDDINGS_MODEL_NAME"`
+	CostPerM  float64 `json:"cost_per_m,omitempty" env:"PICOCLAW_ROUTING_EMBEDDINGS_COST_PER_M"` // USD per million input tokens
+}
+
+// RoutingPolicyConfig runs an external program as a routing policy hook. It
+// receives the routing decision as JSON on stdin and may veto tiers or force
+// a tier or model; see routing.ExecPolicy for the protocol.
+type RoutingPolicyConfig struct {
+	Name      string   `json:"name,omitempty"`
+	Command   []string `json:"command"`
+	Hooks     []string `json:"hooks,omitempty"`      // pre_route, post_route, on_error (default: pre_route)
+	TimeoutMs int      `json:"timeout_ms,omitempty"` // Per hook call (0 = 2000)
+}
+
+// HedgingConfig fires a duplicate request to a tier's hedge_model when the
+// primary request runs past the tier's observed p95 latency; the first
+// successful response wins and the other request is cancelled.
+type HedgingConfig struct {
+	Enabled    bool `json:"enabled"               env:"PICOCLAW_ROUTING_HEDGING_ENABLED"`
+	MinSamples int  `json:"min_samples,omitempty" env:"PICOCLAW_ROUTING_HEDGING_MIN_SAMPLES"` // Latency samples needed before hedging a model (0 = 20)
+}
+
+// RateLimitConfig caps requests and tokens per minute. Zero means unlimited.
+type RateLimitConfig struct {
+	RPM int `json:"rpm,omitempty"`
+	TPM int `json:"tpm,omitempty"`
+}
+
+// SpeculativeConfig lets the supervisor answer high-stakes tasks in parallel
+// with the worker so a rejected worker output costs no extra round-trip.
+type SpeculativeConfig struct {
+	Enabled      bool    `json:"enabled"                  env:"PICOCLAW_ROUTING_SPECULATIVE_ENABLED"`
+	MaxExtraCost float64 `json:"max_extra_cost,omitempty" env:"PICOCLAW_ROUTING_SPECULATIVE_MAX_EXTRA_COST"` // USD per session reserved for speculative answers (0 = unlimited)
+}
+
+// TaskOverride pins request options for one task type regardless of what the
+// caller passed, e.g. parsing at temperature 0 with a small output budget.
+type TaskOverride struct {
+	Temperature  *float64 `json:"temperature,omitempty"`   // nil keeps the caller's temperature
+	MaxTokens    int      `json:"max_tokens,omitempty"`    // 0 keeps the caller's max_tokens
+	SystemPrompt string   `json:"system_prompt,omitempty"` // Appended to the system prompt
+	Tools        []string `json:"tools,omitempty"`         // Tools offered for this task type (names or prefix*); empty = all
+	Pipeline     string   `json:"pipeline,omitempty"`      // Output pipeline run on the response, from routing.pipelines
+
+	// Override the tier's reasoning settings for this task type
+	ReasoningEffort string `json:"reasoning_effort,omitempty"` // "" keeps the tier's
+	ThinkingBudget  int    `json:"thinking_budget,omitempty"`  // 0 keeps the tier's, <0 turns thinking off
+}
+
+// ResponseCacheConfig enables caching of deterministic (temperature 0) routed
+// responses so repeated parsing/formatting of identical tool output is not
+// billed twice.
+type ResponseCacheConfig struct {
+	Enabled    bool `json:"enabled"`
+	TTLSeconds int  `json:"ttl_seconds,omitempty"` // 0 = 10 minutes
+	MaxEntries int  `json:"max_entries,omitempty"` // 0 = 256
+
+	// Parses and summaries of a tool output hash are reused at any
+	// temperature, across reruns and resumed missions.
+	ToolOutputTTLSeconds int `json:"tool_output_ttl_seconds,omitempty"` // 0 = 24 hours
+}
+
+// RefusalConfig controls what happens when a provider refuses or
+// content-filters a request. Refusals are always annotated; rephrasing and
+// retrying are opt-in.
+type RefusalConfig struct {
+	Rephrase    bool     `json:"rephrase,omitempty"     env:"PICOCLAW_ROUTING_REFUSAL_REPHRASE"` // Ask the refusing model once more with the request rephrased
+	Retry       bool     `json:"retry"                  env:"PICOCLAW_ROUTING_REFUSAL_RETRY"`
+	RetryModels []string `json:"retry_models,omitempty" env:"PICOCLAW_ROUTING_REFUSAL_RETRY_MODELS"` // model_list names tried in order, e.g. a local model
+}
+
+// ToolEnforcementConfig makes tool-selection turns answer with a tool call.
+// Enforced turns send tool_choice "required" (plus any stop sequences), and a
+// response without a tool call counts as a violation and is retried with a
+// reminder to call a tool.
+type ToolEnforcementConfig struct {
+	Enabled       bool     `json:"enabled"                  env:"PICOCLAW_ROUTING_TOOL_ENFORCEMENT_ENABLED"`
+	TaskTypes     []string `json:"task_types,omitempty"     env:"PICOCLAW_ROUTING_TOOL_ENFORCEMENT_TASK_TYPES"` // Enforced task types (default: tool_selection)
+	MaxRetries    int      `json:"max_retries,omitempty"    env:"PICOCLAW_ROUTING_TOOL_ENFORCEMENT_MAX_RETRIES"` // Retries per violating turn (0 = 1, <0 = none)
+	StopSequences []string `json:"stop_sequences,omitempty" env:"-"`                                                // Sent on enforced turns to cut prose short
 }
 
 // TierConfig defines a model tier with its associated model and task types
 type TierConfig struct {
-	ModelName string       `json:"model_name"` // Reference to model_list entry
-	UseFor    []string     `json:"use_for"`    // Task types: planning, parsing, analysis, etc.
-	CostPerM  CostPerMInfo `json:"cost_per_m"` // Cost per million tokens
+	ModelName     string       `json:"model_name"`               // Reference to model_list entry
+	UseFor        []string     `json:"use_for"`                  // Task types: planning, parsing, analysis, etc.
+	CostPerM      CostPerMInfo `json:"cost_per_m"`               // Cost per million tokens
+	ContextWindow int          `json:"context_window,omitempty"` // Overrides the model_list context_window for this tier
+	HedgeModel    string       `json:"hedge_model,omitempty"`    // model_name raced against this tier once a request outlasts its p95 latency
+
+	// Deeper reasoning for tiers that plan or exploit, none for tiers that
+	// parse. Each is sent only to the providers that understand it.
+	ReasoningEffort string `json:"reasoning_effort,omitempty"` // OpenAI-compatible reasoning_effort (none, minimal, low, medium, high), sent as is
+	ThinkingBudget  int    `json:"thinking_budget,omitempty"`  // Anthropic extended thinking budget in tokens (min 1024); 0 = off
 }
 
 // CostPerMInfo tracks cost per million tokens for input/output
 type CostPerMInfo struct {
-	Input  float64 `json:"input"`  // Cost per 1M input tokens
-	Output float64 `json:"output"` // Cost per 1M output tokens
+	Input       float64 `json:"input"`                  // Cost per 1M input tokens
+	Output      float64 `json:"output"`                 // Cost per 1M output tokens
+	CachedInput float64 `json:"cached_input,omitempty"` // Cost per 1M prompt tokens read from cache; 0 = Input
+	CacheWrite  float64 `json:"cache_write,omitempty"`  // Cost per 1M prompt tokens written to cache; 0 = Input
+	Reasoning   float64 `json:"reasoning,omitempty"`    // Cost per 1M reasoning tokens; 0 = Output
 }
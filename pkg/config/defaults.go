@@ -260,6 +260,14 @@ func DefaultConfig() *Config {
 				APIKey:    "ollama",
 			},
 
+			// xAI Grok - https://console.x.ai
+			{
+				ModelName: "grok-4",
+				Model:     "xai/grok-4",
+				APIBase:   "https://api.x.ai/v1",
+				APIKey:    "",
+			},
+
 			// Mistral AI - https://console.mistral.ai/api-keys
 			{
 				ModelName: "mistral-small",
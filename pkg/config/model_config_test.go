@@ -279,6 +279,42 @@ func TestModelConfig_Validate(t *testing.T) {
 			config:  ModelConfig{},
 			wantErr: true,
 		},
+		{
+			name: "valid openrouter routing",
+			config: ModelConfig{
+				ModelName:  "test",
+				Model:      "openrouter/openai/gpt-4o",
+				OpenRouter: &OpenRouterConfig{DataCollection: "deny", Sort: "price"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid openrouter data_collection",
+			config: ModelConfig{
+				ModelName:  "test",
+				Model:      "openrouter/openai/gpt-4o",
+				OpenRouter: &OpenRouterConfig{DataCollection: "never"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid tool_call_format",
+			config: ModelConfig{
+				ModelName:      "test",
+				Model:          "ollama/qwen2.5",
+				ToolCallFormat: "hermes",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid tool_call_format",
+			config: ModelConfig{
+				ModelName:      "test",
+				Model:          "ollama/qwen2.5",
+				ToolCallFormat: "xml",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
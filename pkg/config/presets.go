@@ -0,0 +1,160 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package config
+
+import (
+	"strings"
+)
+
+// ProviderPreset describes a hosted OpenAI-compatible provider well enough
+// that a model_list entry for it needs only the model and a key. All presets
+// authenticate with a bearer API key.
+type ProviderPreset struct {
+	Protocol       string // Prefix of model_list "model", e.g. "xai/grok-4"
+	Name           string // Display name
+	APIBase        string
+	APIKeyEnv      string // Environment variable the key is read from when api_key is empty
+	MaxTokensField string // Request field for the output limit; "" = max_tokens
+	Models         []PresetModel
+}
+
+// PresetModel is a model's published limits and list prices
+type PresetModel struct {
+	ID            string
+	ContextWindow int
+	MaxOutput     int          // Largest output limit the API accepts; 0 = unlimited
+	Pricing       CostPerMInfo // USD per million tokens
+}
+
+var providerPresets = []ProviderPreset{
+	{
+		Protocol:       "xai",
+		Name:           "xAI",
+		APIBase:        "https://api.x.ai/v1",
+		APIKeyEnv:      "XAI_API_KEY",
+		MaxTokensField: "max_completion_tokens", // max_tokens is deprecated
+		Models: []PresetModel{
+			{ID: "grok-4", ContextWindow: 256000, Pricing: CostPerMInfo{Input: 3.00, Output: 15.00, CachedInput: 0.75}},
+			{ID: "grok-4-fast-reasoning", ContextWindow: 2000000, Pricing: CostPerMInfo{Input: 0.20, Output: 0.50, CachedInput: 0.05}},
+			{ID: "grok-4-fast-non-reasoning", ContextWindow: 2000000, Pricing: CostPerMInfo{Input: 0.20, Output: 0.50, CachedInput: 0.05}},
+			{ID: "grok-code-fast-1", ContextWindow: 256000, Pricing: CostPerMInfo{Input: 0.20, Output: 1.50, CachedInput: 0.02}},
+			{ID: "grok-3", ContextWindow: 131072, Pricing: CostPerMInfo{Input: 3.00, Output: 15.00, CachedInput: 0.75}},
+			{ID: "grok-3-mini", ContextWindow: 131072, Pricing: CostPerMInfo{Input: 0.30, Output: 0.50, CachedInput: 0.075}},
+		},
+	},
+	{
+		Protocol:  "mistral",
+		Name:      "Mistral La Plateforme",
+		APIBase:   "https://api.mistral.ai/v1",
+		APIKeyEnv: "MISTRAL_API_KEY",
+		Models: []PresetModel{
+			{ID: "mistral-large-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 2.00, Output: 6.00}},
+			{ID: "mistral-medium-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.40, Output: 2.00}},
+			{ID: "mistral-small-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.10, Output: 0.30}},
+			{ID: "magistral-medium-latest", ContextWindow: 40000, Pricing: CostPerMInfo{Input: 2.00, Output: 5.00}},
+			{ID: "magistral-small-latest", ContextWindow: 40000, Pricing: CostPerMInfo{Input: 0.50, Output: 1.50}},
+			{ID: "codestral-latest", ContextWindow: 256000, Pricing: CostPerMInfo{Input: 0.30, Output: 0.90}},
+			{ID: "devstral-medium-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.40, Output: 2.00}},
+			{ID: "devstral-small-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.10, Output: 0.30}},
+			{ID: "ministral-8b-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.10, Output: 0.10}},
+			{ID: "ministral-3b-latest", ContextWindow: 128000, Pricing: CostPerMInfo{Input: 0.04, Output: 0.04}},
+		},
+	},
+	{
+		Protocol:  "deepseek",
+		Name:      "DeepSeek",
+		APIBase:   "https://api.deepseek.com/v1",
+		APIKeyEnv: "DEEPSEEK_API_KEY",
+		// Requests asking for more than MaxOutput are rejected, not capped
+		Models: []PresetModel{
+			{ID: "deepseek-chat", ContextWindow: 128000, MaxOutput: 8192, Pricing: CostPerMInfo{Input: 0.28, Output: 0.42, CachedInput: 0.028}},
+			{ID: "deepseek-reasoner", ContextWindow: 128000, MaxOutput: 65536, Pricing: CostPerMInfo{Input: 0.28, Output: 0.42, CachedInput: 0.028}},
+		},
+	},
+}
+
+// ProviderPresets returns the built-in provider presets
+func ProviderPresets() []ProviderPreset {
+	presets := make([]ProviderPreset, len(providerPresets))
+	copy(presets, providerPresets)
+	return presets
+}
+
+// LookupProviderPreset returns the preset for a model_list protocol prefix
+func LookupProviderPreset(protocol string) (ProviderPreset, bool) {
+	protocol = strings.ToLower(protocol)
+	for _, preset := range providerPresets {
+		if preset.Protocol == protocol {
+			return preset, true
+		}
+	}
+	return ProviderPreset{}, false
+}
+
+// Model finds a model by its API ID. Dated and versioned IDs match their
+// family, e.g. "mistral-large-2411" matches "mistral-large-latest" and
+// "grok-4-0709" matches "grok-4"; the most specific family wins.
+func (p ProviderPreset) Model(id string) (PresetModel, bool) {
+	var (
+		best    PresetModel
+		bestLen int
+	)
+	for _, m := range p.Models {
+		family := strings.TrimSuffix(m.ID, "-latest")
+		if id != m.ID && id != family && !strings.HasPrefix(id, family+"-") {
+			continue
+		}
+		if len(family) > bestLen {
+			best, bestLen = m, len(family)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// LookupPresetModel returns the preset and model for a model_list "model"
+// such as "deepseek/deepseek-chat"
+func LookupPresetModel(model string) (ProviderPreset, PresetModel, bool) {
+	protocol, id, found := strings.Cut(model, "/")
+	if !found {
+		return ProviderPreset{}, PresetModel{}, false
+	}
+	preset, ok := LookupProviderPreset(protocol)
+	if !ok {
+		return ProviderPreset{}, PresetModel{}, false
+	}
+	m, ok := preset.Model(id)
+	return preset, m, ok
+}
+
+// ApplyProviderPresets fills in what the presets know and the config leaves
+// unset: the context window of preset models and the pricing of routing
+// tiers that use them. Values set in the config are kept.
+func (c *Config) ApplyProviderPresets() {
+	for i := range c.ModelList {
+		if c.ModelList[i].ContextWindow > 0 {
+			continue
+		}
+		if _, m, ok := LookupPresetModel(c.ModelList[i].Model); ok {
+			c.ModelList[i].ContextWindow = m.ContextWindow
+		}
+	}
+
+	for name, tier := range c.Routing.Tiers {
+		if tier.CostPerM.Input != 0 || tier.CostPerM.Output != 0 {
+			continue
+		}
+		for _, model := range c.ModelList {
+			if model.ModelName != tier.ModelName {
+				continue
+			}
+			if _, m, ok := LookupPresetModel(model.Model); ok {
+				tier.CostPerM = m.Pricing
+				c.Routing.Tiers[name] = tier
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package config
+
+import (
+	"testing"
+)
+
+func TestLookupPresetModel(t *testing.T) {
+	tests := []struct {
+		model       string
+		wantID      string
+		wantContext int
+	}{
+		{"xai/grok-4", "grok-4", 256000},
+		{"xai/grok-4-0709", "grok-4", 256000},
+		{"xai/grok-4-fast-reasoning", "grok-4-fast-reasoning", 2000000},
+		{"xai/grok-3-mini", "grok-3-mini", 131072},
+		{"mistral/mistral-large-latest", "mistral-large-latest", 128000},
+		{"mistral/mistral-large-2411", "mistral-large-latest", 128000},
+		{"deepseek/deepseek-reasoner", "deepseek-reasoner", 128000},
+	}
+	for _, tt := range tests {
+		_, m, ok := LookupPresetModel(tt.model)
+		if !ok {
+			t.Errorf("LookupPresetModel(%q) found nothing", tt.model)
+			continue
+		}
+		if m.ID != tt.wantID || m.ContextWindow != tt.wantContext {
+			t.Errorf("LookupPresetModel(%q) = %s (%d), want %s (%d)", tt.model, m.ID, m.ContextWindow, tt.wantID, tt.wantContext)
+		}
+	}
+
+	for _, model := range []string{"deepseek-chat", "groq/llama-3.3-70b-versatile", "xai/grok-imagine", "mistral/mistral-embed"} {
+		if _, m, ok := LookupPresetModel(model); ok {
+			t.Errorf("LookupPresetModel(%q) = %s, want no match", model, m.ID)
+		}
+	}
+}
+
+func TestApplyProviderPresets(t *testing.T) {
+	cfg := &Config{
+		ModelList: []ModelConfig{
+			{ModelName: "grok", Model: "xai/grok-4-fast-non-reasoning"},
+			{ModelName: "deepseek", Model: "deepseek/deepseek-chat", ContextWindow: 64000},
+			{ModelName: "local", Model: "vllm/custom-model"},
+		},
+		Routing: RoutingConfig{Tiers: map[string]TierConfig{
+			"light":  {ModelName: "grok"},
+			"medium": {ModelName: "deepseek", CostPerM: CostPerMInfo{Input: 0.27, Output: 1.10}},
+			"heavy":  {ModelName: "local"},
+		}},
+	}
+	cfg.ApplyProviderPresets()
+
+	if got := cfg.ModelList[0].ContextWindow; got != 2000000 {
+		t.Errorf("grok context_window = %d, want 2000000", got)
+	}
+	if got := cfg.ModelList[1].ContextWindow; got != 64000 {
+		t.Errorf("deepseek context_window = %d, want the configured 64000", got)
+	}
+	if got := cfg.ModelList[2].ContextWindow; got != 0 {
+		t.Errorf("local context_window = %d, want 0", got)
+	}
+
+	want := CostPerMInfo{Input: 0.20, Output: 0.50, CachedInput: 0.05}
+	if got := cfg.Routing.Tiers["light"].CostPerM; got != want {
+		t.Errorf("light cost_per_m = %+v, want %+v", got, want)
+	}
+	if got := cfg.Routing.Tiers["medium"].CostPerM.Input; got != 0.27 {
+		t.Errorf("medium cost_per_m input = %v, want the configured 0.27", got)
+	}
+	if got := cfg.Routing.Tiers["heavy"].CostPerM; got != (CostPerMInfo{}) {
+		t.Errorf("heavy cost_per_m = %+v, want none", got)
+	}
+}
@@ -0,0 +1,154 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Mission is the dashboard's view of the active mission. Viewers and
+// clients see the findings a client report shows, operators all of them;
+// evidence is only included for roles that may open it.
+type Mission struct {
+	Role     Role           `json:"role"`
+	Active   bool           `json:"active"`
+	Workflow string         `json:"workflow,omitempty"`
+	Target   string         `json:"target,omitempty"`
+	Started  *time.Time     `json:"started,omitempty"`
+	Phase    string         `json:"phase,omitempty"`
+	Phases   []PhaseStatus  `json:"phases"`
+	Findings []Finding      `json:"findings"`
+	Severity map[string]int `json:"severity"`
+	Costs    []PhaseCost    `json:"costs"`
+	Spend    float64        `json:"spend"` // Routed model spend across all sessions, USD
+}
+
+// PhaseStatus is a workflow phase and how far the mission got with it
+type PhaseStatus struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // done, current or pending
+	StepsDone  int    `json:"steps_done"`
+	StepsTotal int    `json:"steps_total"`
+}
+
+// Finding is a finding as the dashboard shows it
+type Finding struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Severity string    `json:"severity"`
+	CVSS     float64   `json:"cvss,omitempty"`
+	Phase    string    `json:"phase"`
+	Asset    string    `json:"asset,omitempty"`
+	Created  time.Time `json:"created"`
+
+	Evidence  string                      `json:"evidence,omitempty"`
+	Artifacts []workflow.EvidenceArtifact `json:"artifacts,omitempty"`
+}
+
+// PhaseCost is the time and model spend of one phase
+type PhaseCost struct {
+	Phase           string  `json:"phase"`
+	Cost            float64 `json:"cost"`
+	Tokens          int     `json:"tokens"`
+	OperatorSeconds float64 `json:"operator_seconds"`
+	AgentSeconds    float64 `json:"agent_seconds"`
+}
+
+// Event is a mission event as the dashboard streams it. Notes and tool
+// calls carry no details, since they are internal working material, and
+// internal findings only have them for operators.
+type Event struct {
+	Type   workflow.EventType `json:"type"`
+	Time   time.Time          `json:"time"`
+	Phase  string             `json:"phase,omitempty"`
+	Detail string             `json:"detail,omitempty"`
+}
+
+// missionView builds the role's view of the engine's mission
+func missionView(engine *workflow.Engine, role Role) Mission {
+	m := Mission{
+		Role:     role,
+		Phases:   []PhaseStatus{},
+		Findings: []Finding{},
+		Severity: map[string]int{},
+		Costs:    []PhaseCost{},
+	}
+	if engine == nil || engine.GetWorkflow() == nil {
+		return m
+	}
+	wf := engine.GetWorkflow()
+	state := engine.GetState()
+
+	m.Active = true
+	m.Workflow = state.WorkflowName
+	m.Target = state.Target
+	m.Started = &state.StartTime
+	if state.CurrentPhase < len(wf.Phases) {
+		m.Phase = wf.Phases[state.CurrentPhase].Name
+	}
+
+	done := make(map[string]int)
+	for _, exec := range state.PhaseHistory {
+		done[exec.PhaseName] = max(done[exec.PhaseName], len(exec.StepsComplete))
+	}
+	for i, phase := range wf.Phases {
+		status := "pending"
+		switch {
+		case i == state.CurrentPhase:
+			status = "current"
+		case i < state.CurrentPhase:
+			status = "done"
+		}
+		m.Phases = append(m.Phases, PhaseStatus{
+			Name:       phase.Name,
+			Status:     status,
+			StepsDone:  done[phase.Name],
+			StepsTotal: len(phase.Steps),
+		})
+	}
+
+	for _, f := range workflow.ReportFindings(state, role.audience()) {
+		finding := Finding{
+			ID:       f.ID,
+			Title:    f.Title,
+			Severity: string(f.Severity),
+			CVSS:     f.CVSSScore,
+			Phase:    f.Phase,
+			Asset:    f.Asset,
+			Created:  f.CreatedAt,
+		}
+		if role.canViewEvidence() {
+			finding.Evidence = f.Evidence
+			finding.Artifacts = workflow.FindingEvidence(f)
+		}
+		m.Findings = append(m.Findings, finding)
+		m.Severity[string(f.Severity)]++
+	}
+
+	for _, e := range state.Effort() {
+		m.Costs = append(m.Costs, PhaseCost{
+			Phase:           e.Phase,
+			Cost:            e.Cost,
+			Tokens:          e.Tokens,
+			OperatorSeconds: e.Operator.Seconds(),
+			AgentSeconds:    e.Agent.Seconds(),
+		})
+	}
+	return m
+}
+
+// eventView builds the role's streamed form of a mission event
+func eventView(event workflow.Event, role Role) Event {
+	e := Event{Type: event.Type, Time: event.Time, Phase: event.Phase}
+	switch event.Type {
+	case workflow.EventStepComplete:
+		e.Detail = event.Step
+	case workflow.EventBranchCreated, workflow.EventBranchCompleted:
+		e.Detail = event.Branch
+	case workflow.EventFinding:
+		if event.Finding != nil && (role == RoleOperator || event.Finding.Redaction != workflow.RedactionInternal) {
+			e.Detail = string(event.Finding.Severity) + ": " + event.Finding.Title
+		}
+	}
+	return e
+}
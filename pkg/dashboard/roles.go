@@ -0,0 +1,107 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Role is what a dashboard user may see
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Mission status and client-visible findings
+	RoleClient   Role = "client"   // Also the evidence a client report shows
+	RoleOperator Role = "operator" // Every finding with all its evidence
+)
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(s))); role {
+	case RoleViewer, RoleClient, RoleOperator:
+		return role, nil
+	}
+	return "", fmt.Errorf("invalid dashboard role %q (want viewer, client or operator)", s)
+}
+
+// audience is the report audience whose findings the role sees
+func (r Role) audience() workflow.ReportAudience {
+	if r == RoleOperator {
+		return workflow.ReportInternal
+	}
+	return workflow.ReportClient
+}
+
+// canViewEvidence reports whether the role may open evidence artifacts
+func (r Role) canViewEvidence() bool {
+	return r == RoleClient || r == RoleOperator
+}
+
+// canViewStats reports whether the role may see the trends across archived
+// missions
+func (r Role) canViewStats() bool {
+	return r == RoleOperator
+}
+
+// user is a configured dashboard token
+type user struct {
+	name  string
+	token string
+	role  Role
+}
+
+func parseUsers(users []config.GatewayUser) ([]user, error) {
+	parsed := make([]user, 0, len(users))
+	for _, u := range users {
+		role, err := ParseRole(u.Role)
+		if err != nil {
+			return nil, fmt.Errorf("gateway user %q: %w", u.Name, err)
+		}
+		if u.Token == "" {
+			return nil, fmt.Errorf("gateway user %q has no token", u.Name)
+		}
+		parsed = append(parsed, user{name: u.Name, token: u.Token, role: role})
+	}
+	return parsed, nil
+}
+
+// authorize returns the user whose bearer token the request carries.
+// Browsers can't set headers on event streams, images or links, so the
+// token may also be given as the token query parameter. Without configured
+// users everyone is an anonymous viewer.
+func (s *Server) authorize(r *http.Request) (user, bool) {
+	if len(s.users) == 0 {
+		return user{role: RoleViewer}, true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return user{}, false
+	}
+	for _, u := range s.users {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(u.token)) == 1 {
+			return u, true
+		}
+	}
+	return user{}, false
+}
+
+// requireUser wraps a handler so it only runs for authorized requests, and
+// passes it the caller
+func (s *Server) requireUser(next func(http.ResponseWriter, *http.Request, user)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.authorize(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="picoclaw"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r, u)
+	}
+}
@@ -0,0 +1,231 @@
+// Package dashboard serves a read-only web page showing the gateway's
+// active mission: phase progress, findings and their evidence, model spend
+// and a live event stream, for stakeholders who don't use the TUI or a
+// chat channel. Operators also see trends across archived missions.
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// enginePollInterval is how often the event stream checks whether another
+// mission was loaded, and keeps idle connections alive
+const enginePollInterval = 5 * time.Second
+
+// Server serves the dashboard for an agent loop's default agent
+type Server struct {
+	agentLoop *agent.AgentLoop
+	users     []user
+	server    *http.Server
+	cancel    context.CancelFunc // Ends open event streams on Stop
+}
+
+// NewServer creates a dashboard server for agentLoop. Requests need the
+// token of one of users, unless there are none.
+func NewServer(agentLoop *agent.AgentLoop, users []config.GatewayUser) (*Server, error) {
+	parsed, err := parseUsers(users)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{agentLoop: agentLoop, users: parsed, cancel: cancel}
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	return s, nil
+}
+
+// Handler returns the dashboard's routes: the page itself, /api/mission,
+// the /api/events stream, evidence artifacts under /api/evidence/ and
+// /api/stats
+func (s *Server) Handler() http.Handler {
+	static, _ := fs.Sub(staticFiles, "static")
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/mission", s.requireUser(s.handleMission))
+	mux.HandleFunc("GET /api/events", s.requireUser(s.handleEvents))
+	mux.HandleFunc("GET /api/evidence/{sha256}", s.requireUser(s.handleEvidence))
+	mux.HandleFunc("GET /api/stats", s.requireUser(s.handleStats))
+	return mux
+}
+
+// Serve accepts connections on lis until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	logger.InfoCF("dashboard", "Dashboard listening", map[string]any{"addr": lis.Addr().String()})
+	return s.server.Serve(lis)
+}
+
+// Stop shuts the server down, closing open event streams
+func (s *Server) Stop(ctx context.Context) error {
+	s.cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// engine returns the default agent's mission engine, or nil without one
+func (s *Server) engine() *workflow.Engine {
+	defaultAgent := s.agentLoop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return nil
+	}
+	return defaultAgent.WorkflowEngine
+}
+
+func (s *Server) handleMission(w http.ResponseWriter, r *http.Request, u user) {
+	m := missionView(s.engine(), u.role)
+	if router := s.agentLoop.GetTierRouter(); router != nil && router.IsEnabled() {
+		m.Spend = router.GetCostTracker().GetTotalCost()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		logger.WarnCF("dashboard", "Failed to write mission", map[string]any{"error": err.Error()})
+	}
+}
+
+// handleEvents streams mission events as server-sent events. When another
+// mission is loaded the stream follows it and sends a "mission" event so
+// the page reloads the whole view.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request, u user) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var (
+		engine *workflow.Engine
+		events <-chan workflow.Event
+		cancel context.CancelFunc = func() {}
+	)
+	defer func() { cancel() }()
+	follow := func() {
+		cancel()
+		engine, events, cancel = s.engine(), nil, func() {}
+		if engine != nil {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(r.Context())
+			events = engine.Subscribe(ctx)
+		}
+	}
+	follow()
+
+	// Subscribed before the headers go out, so a client that has them sees
+	// every later event
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(enginePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			data, _ := json.Marshal(eventView(event, u.role))
+			fmt.Fprintf(w, "event: mission_event\ndata: %s\n\n", data)
+		case <-ticker.C:
+			if current := s.engine(); current != engine {
+				follow()
+				fmt.Fprint(w, "event: mission\ndata: {}\n\n")
+			} else {
+				fmt.Fprint(w, ": keepalive\n\n")
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// handleEvidence serves an evidence artifact attached to a finding the
+// user may see, inline or with ?download=1 as an attachment. Ranges are
+// supported, so the page can fetch the start of a large dump.
+func (s *Server) handleEvidence(w http.ResponseWriter, r *http.Request, u user) {
+	if !u.role.canViewEvidence() {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	engine := s.engine()
+	if engine == nil {
+		http.NotFound(w, r)
+		return
+	}
+	artifact, ok := findArtifact(engine.GetState(), u.role, r.PathValue("sha256"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Stored under its hash; the recorded path is only trusted for the
+	// extension
+	name := artifact.SHA256 + filepath.Ext(artifact.Path)
+	if !strings.HasPrefix(filepath.Base(artifact.Path), artifact.SHA256) {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(filepath.Join(s.agentLoop.GetRegistry().GetDefaultAgent().Workspace, workflow.EvidenceDir, name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	logger.InfoCF("dashboard", "Evidence opened", map[string]any{
+		"user":   u.name,
+		"name":   artifact.Name,
+		"sha256": artifact.SHA256,
+	})
+
+	// Evidence is untrusted content: never let the browser run it
+	contentType := "application/octet-stream"
+	if artifact.IsImage() && artifact.MediaType != "image/svg+xml" {
+		contentType = artifact.MediaType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+	}
+	http.ServeContent(w, r, "", artifact.AddedAt, f)
+}
+
+// findArtifact looks up an artifact by hash among the evidence of the
+// findings the role sees
+func findArtifact(state *workflow.MissionState, role Role, sha256 string) (workflow.EvidenceArtifact, bool) {
+	for _, f := range workflow.ReportFindings(state, role.audience()) {
+		for _, a := range workflow.FindingEvidence(f) {
+			if a.SHA256 == sha256 {
+				return a, true
+			}
+		}
+	}
+	return workflow.EvidenceArtifact{}, false
+}
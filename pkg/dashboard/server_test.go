@@ -0,0 +1,312 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+type idleProvider struct{}
+
+func (p *idleProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (p *idleProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// newTestServer serves the dashboard for an agent on a two-phase mission,
+// and returns the agent's workspace too
+func newTestServer(t *testing.T, users ...config.GatewayUser) (*httptest.Server, *workflow.Engine, string) {
+	t.Helper()
+
+	workspace := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 5,
+			},
+		},
+	}
+	agentLoop := agent.NewAgentLoop(cfg, bus.NewMessageBus(), &idleProvider{})
+
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{
+		{Name: "recon", Steps: []workflow.Step{{ID: "scan", Name: "Scan", Required: true}}},
+		{Name: "exploit"},
+	}}
+	engine := workflow.NewEngine(wf, "app.example.com", workspace)
+	agentLoop.GetRegistry().GetDefaultAgent().WorkflowEngine = engine
+
+	dash, err := NewServer(agentLoop, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(dash.Handler())
+	t.Cleanup(srv.Close)
+	return srv, engine, workspace
+}
+
+func TestServer_Mission(t *testing.T) {
+	srv, engine, _ := newTestServer(t)
+	if err := engine.MarkStepComplete("scan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddRedactedFinding("Admin panel exposed", "desc", workflow.SeverityHigh, "curl output", workflow.RedactionPartial); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddRedactedFinding("Internal host names", "desc", workflow.SeverityLow, "", workflow.RedactionInternal); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.RecordCost(0.25, 1200); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/mission")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var m Mission
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Role != RoleViewer || !m.Active || m.Target != "app.example.com" || m.Phase != "recon" {
+		t.Errorf("mission = %+v", m)
+	}
+	if len(m.Phases) != 2 || m.Phases[0].Status != "current" || m.Phases[0].StepsDone != 1 || m.Phases[1].Status != "pending" {
+		t.Errorf("phases = %+v", m.Phases)
+	}
+	// Stakeholders see what the client report shows, without evidence
+	if len(m.Findings) != 1 || m.Findings[0].Title != "Admin panel exposed" || m.Findings[0].Evidence != "" || m.Severity["low"] != 0 {
+		t.Errorf("findings = %+v, severity = %v", m.Findings, m.Severity)
+	}
+	if len(m.Costs) != 1 || m.Costs[0].Cost != 0.25 || m.Costs[0].Tokens != 1200 {
+		t.Errorf("costs = %+v", m.Costs)
+	}
+
+	page, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(page.Body)
+	page.Body.Close()
+	if !strings.Contains(string(body), "<title>PicoClaw Mission Dashboard</title>") {
+		t.Errorf("index page = %.200s", body)
+	}
+}
+
+func TestServer_EventStream(t *testing.T) {
+	srv, engine, _ := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	if err := engine.AddNote("private reasoning"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.MarkStepComplete("scan"); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []Event
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 2 && scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			var e Event
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, e)
+		}
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v (%v)", events, scanner.Err())
+	}
+	// Notes are streamed without their text
+	if events[0].Type != workflow.EventNoteAdded || events[0].Detail != "" {
+		t.Errorf("note event = %+v", events[0])
+	}
+	if events[1].Type != workflow.EventStepComplete || events[1].Detail != "scan" || events[1].Phase != "recon" {
+		t.Errorf("step event = %+v", events[1])
+	}
+}
+
+func TestServer_Evidence(t *testing.T) {
+	srv, engine, workspace := newTestServer(t,
+		config.GatewayUser{Name: "board", Token: "view-token", Role: "viewer"},
+		config.GatewayUser{Name: "acme", Token: "client-token", Role: "client"},
+		config.GatewayUser{Name: "lead", Token: "op-token", Role: "Operator"},
+	)
+	attach := func(title string, redaction workflow.RedactionLevel, name, content string) workflow.EvidenceArtifact {
+		t.Helper()
+		if err := engine.AddRedactedFinding(title, "desc", workflow.SeverityMedium, "", redaction); err != nil {
+			t.Fatal(err)
+		}
+		artifact, err := workflow.StoreEvidence(workspace, name, strings.NewReader(content), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := engine.AttachEvidence(title, artifact); err != nil {
+			t.Fatal(err)
+		}
+		return artifact
+	}
+	shared := attach("Open redirect", workflow.RedactionFull, "redirect.txt", "HTTP/1.1 302 Found")
+	withheld := attach("Debug endpoint", workflow.RedactionPartial, "debug.txt", "stack trace")
+	internal := attach("Lab credentials", workflow.RedactionInternal, "creds.bin", "\x00\x01secret")
+
+	get := func(path, token string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{"no token", "/api/mission", "", http.StatusUnauthorized},
+		{"wrong token", "/api/mission", "nope", http.StatusUnauthorized},
+		{"query token", "/api/mission?token=view-token", "", http.StatusOK},
+		{"viewer", "/api/evidence/" + shared.SHA256, "view-token", http.StatusForbidden},
+		{"client shared", "/api/evidence/" + shared.SHA256, "client-token", http.StatusOK},
+		{"client withheld", "/api/evidence/" + withheld.SHA256, "client-token", http.StatusNotFound},
+		{"client internal", "/api/evidence/" + internal.SHA256, "client-token", http.StatusNotFound},
+		{"operator internal", "/api/evidence/" + internal.SHA256, "op-token", http.StatusOK},
+		{"unknown artifact", "/api/evidence/0123", "op-token", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := get(tt.path, tt.token); resp.StatusCode != tt.wantCode {
+				t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+
+	resp := get("/api/evidence/"+shared.SHA256+"?download=1", "client-token")
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "HTTP/1.1 302 Found" {
+		t.Errorf("artifact body = %q", body)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != "attachment; filename=redirect.txt" {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var m Mission
+	if err := json.NewDecoder(get("/api/mission", "client-token").Body).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := map[string]int{}
+	for _, f := range m.Findings {
+		artifacts[f.Title] = len(f.Artifacts)
+	}
+	if m.Role != RoleClient || len(m.Findings) != 2 || artifacts["Open redirect"] != 1 || artifacts["Debug endpoint"] != 0 {
+		t.Errorf("client mission = %+v", m)
+	}
+}
+
+func TestServer_Stats(t *testing.T) {
+	srv, engine, workspace := newTestServer(t,
+		config.GatewayUser{Name: "board", Token: "view-token", Role: "viewer"},
+		config.GatewayUser{Name: "lead", Token: "op-token", Role: "operator"},
+	)
+	if err := engine.AddFinding("Open redirect", "desc", workflow.SeverityMedium, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.RecordCost(0.30, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.RecordReviews(map[string]workflow.ModelReviews{"qwen3-8b": {Reviewed: 4, Rejected: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workflow.ArchiveMission(workspace, workflow.MissionStatePath(workspace, "app.example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(token string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	if resp := get("view-token"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("viewer GET /api/stats = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+
+	resp := get("op-token")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("operator GET /api/stats = %d", resp.StatusCode)
+	}
+	var s Stats
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Missions != 1 || s.Findings != 1 || s.CostPerFinding != 0.30 {
+		t.Errorf("stats = %d missions, %d findings, $%.2f per finding", s.Missions, s.Findings, s.CostPerFinding)
+	}
+	if len(s.Periods) != 1 || s.Periods[0].Severity["medium"] != 1 {
+		t.Errorf("periods = %+v", s.Periods)
+	}
+	if len(s.Phases) != 1 || s.Phases[0].Phase != "recon" || s.Phases[0].Cost != 0.30 {
+		t.Errorf("phases = %+v", s.Phases)
+	}
+	if len(s.Models) != 1 || s.Models[0].RejectionRate != 0.25 {
+		t.Errorf("models = %+v", s.Models)
+	}
+}
+
+func TestNewServer_InvalidRole(t *testing.T) {
+	users := []config.GatewayUser{{Name: "x", Token: "t", Role: "admin"}}
+	if _, err := NewServer(nil, users); err == nil || !strings.Contains(err.Error(), "invalid dashboard role") {
+		t.Errorf("NewServer() error = %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+"use strict";
+
+// Severities in report order, with the colors the stylesheet uses
+const SEVERITIES = ["critical", "high", "medium", "low", "informational"];
+const MAX_EVENTS = 200;
+// How much of a non-image artifact the viewer fetches
+const PREVIEW_BYTES = 64 * 1024;
+const TOKEN_KEY = "picoclaw-dashboard-token";
+
+const $ = (id) => document.getElementById(id);
+
+// The access token comes from the sign-in form, or once from a shared
+// link's #token= fragment, and lasts for the browser session
+let token = sessionStorage.getItem(TOKEN_KEY) || "";
+const linkToken = new URLSearchParams(location.hash.slice(1)).get("token");
+if (linkToken) {
+  token = linkToken;
+  sessionStorage.setItem(TOKEN_KEY, token);
+  history.replaceState(null, "", location.pathname + location.search);
+}
+
+// withToken adds the token to URLs the browser loads itself: event
+// streams, images and download links can't carry headers
+function withToken(url) {
+  if (!token) {
+    return url;
+  }
+  return url + (url.includes("?") ? "&" : "?") + "token=" + encodeURIComponent(token);
+}
+
+function api(url, headers) {
+  return fetch(url, {
+    cache: "no-store",
+    headers: Object.assign(token ? { Authorization: "Bearer " + token } : {}, headers),
+  });
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    node.setAttribute(key, value);
+  }
+  for (const child of children) {
+    node.append(child);
+  }
+  return node;
+}
+
+function svg(tag, attrs, text) {
+  const node = document.createElementNS("http://www.w3.org/2000/svg", tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    node.setAttribute(key, value);
+  }
+  if (text !== undefined) {
+    node.textContent = text;
+  }
+  return node;
+}
+
+function formatTime(iso) {
+  const d = new Date(iso);
+  return isNaN(d) ? "" : d.toLocaleString();
+}
+
+function formatUSD(n) {
+  return "$" + (n || 0).toFixed(n >= 1 ? 2 : 4);
+}
+
+function formatBytes(n) {
+  if (n < 1024) {
+    return n + " B";
+  }
+  return n < 1024 * 1024 ? (n / 1024).toFixed(1) + " KiB" : (n / 1024 / 1024).toFixed(1) + " MiB";
+}
+
+// barChart draws labelled vertical bars into an SVG element
+function barChart(target, bars, format) {
+  target.replaceChildren();
+  const width = target.clientWidth || 400;
+  const height = target.clientHeight || 180;
+  const max = Math.max(...bars.map((b) => b.value), 0);
+  if (bars.length === 0 || max === 0) {
+    target.append(svg("text", { x: 8, y: 20 }, "Nothing recorded yet"));
+    return;
+  }
+  const slot = width / bars.length;
+  const barWidth = Math.min(48, slot * 0.6);
+  const plot = height - 36;
+  bars.forEach((bar, i) => {
+    const h = Math.max(1, (bar.value / max) * plot);
+    const x = i * slot + (slot - barWidth) / 2;
+    target.append(svg("rect", { x, y: plot - h + 14, width: barWidth, height: h, rx: 4, fill: bar.color || "#0f766e" }));
+    target.append(svg("text", { x: x + barWidth / 2, y: plot - h + 10, "text-anchor": "middle" }, format(bar.value)));
+    target.append(svg("text", { x: x + barWidth / 2, y: height - 4, "text-anchor": "middle" }, bar.label));
+  });
+}
+
+function renderSummary(m) {
+  const summary = $("summary");
+  if (!m.active) {
+    summary.replaceChildren(el("p", { class: "muted" }, "No active mission."));
+    return;
+  }
+  const total = m.findings.length;
+  const cost = m.costs.reduce((sum, c) => sum + c.cost, 0);
+  const item = (label, value) => el("div", {}, el("span", {}, label), el("strong", {}, value));
+  summary.replaceChildren(el("div", { class: "summary" },
+    item("Target", m.target || "—"),
+    item("Workflow", m.workflow),
+    item("Current phase", m.phase || "complete"),
+    item("Started", formatTime(m.started)),
+    item("Findings", String(total)),
+    item("Mission spend", formatUSD(cost)),
+  ));
+}
+
+function renderPhases(m) {
+  $("phases").replaceChildren(...m.phases.map((p) => {
+    const steps = p.steps_total > 0 ? `${p.steps_done}/${p.steps_total} steps` : p.status;
+    return el("li", { class: p.status }, p.name, el("small", {}, steps));
+  }));
+}
+
+function renderFindings(m) {
+  $("findings").replaceChildren(...m.findings.map((f) => el("tr", {},
+    el("td", {}, el("span", { class: "sev " + f.severity }, f.severity)),
+    el("td", {}, f.cvss ? f.cvss.toFixed(1) : ""),
+    el("td", {}, f.title),
+    el("td", {}, f.asset || ""),
+    el("td", {}, f.phase),
+    el("td", {}, formatTime(f.created)),
+  )));
+}
+
+function renderCharts(m) {
+  const styles = getComputedStyle(document.documentElement);
+  barChart($("severity-chart"), SEVERITIES.map((s) => ({
+    label: s === "informational" ? "info" : s,
+    value: m.severity[s] || 0,
+    color: styles.getPropertyValue("--" + s).trim(),
+  })), (v) => String(v));
+  barChart($("cost-chart"), m.costs.map((c) => ({ label: c.phase, value: c.cost })), formatUSD);
+  $("spend").textContent = m.spend > 0 ? `Routed spend across all sessions: ${formatUSD(m.spend)}` : "";
+}
+
+function isImage(a) {
+  // SVG can carry script, so it is shown as text instead
+  return (a.media_type || "").startsWith("image/") && a.media_type !== "image/svg+xml";
+}
+
+// looksText reports whether bytes decode as UTF-8 without NULs; a
+// multi-byte character cut off at the end of a preview is allowed
+function looksText(bytes) {
+  if (bytes.includes(0)) {
+    return false;
+  }
+  try {
+    new TextDecoder("utf-8", { fatal: true }).decode(bytes, { stream: true });
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+function hexDump(bytes) {
+  const lines = [];
+  for (let off = 0; off < bytes.length; off += 16) {
+    const row = bytes.subarray(off, off + 16);
+    const hex = Array.from(row, (b) => b.toString(16).padStart(2, "0"));
+    const ascii = Array.from(row, (b) => (b >= 0x20 && b < 0x7f ? String.fromCharCode(b) : ".")).join("");
+    const left = hex.slice(0, 8).join(" ");
+    const right = hex.slice(8).join(" ");
+    lines.push(`${off.toString(16).padStart(8, "0")}  ${left.padEnd(23)}  ${right.padEnd(23)}  |${ascii}|`);
+  }
+  return lines.join("\n");
+}
+
+let selected = null;
+
+async function showArtifact(a) {
+  const url = `api/evidence/${a.sha256}`;
+  const viewer = $("viewer");
+  const title = el("header", {},
+    el("strong", {}, `${a.name} `, el("small", { class: "muted" }, `${a.kind}, ${formatBytes(a.size)}`)),
+    el("a", { href: withToken(url + "?download=1") }, "Download"));
+  if (isImage(a)) {
+    viewer.replaceChildren(title, el("img", { src: withToken(url), alt: a.description || a.name }));
+    return;
+  }
+
+  viewer.replaceChildren(title, el("p", { class: "muted" }, "Loading…"));
+  const resp = await api(url, { Range: `bytes=0-${PREVIEW_BYTES - 1}` });
+  if (selected !== a.sha256) {
+    return;
+  }
+  if (!resp.ok) {
+    viewer.replaceChildren(title, el("p", { class: "muted" }, `Couldn't load the artifact (${resp.status}).`));
+    return;
+  }
+  const bytes = new Uint8Array(await resp.arrayBuffer());
+  const body = looksText(bytes) ? new TextDecoder().decode(bytes) : hexDump(bytes);
+  const parts = [title, el("pre", {}, body)];
+  if (a.size > bytes.length) {
+    parts.push(el("p", { class: "muted" }, `Showing the first ${formatBytes(bytes.length)}; download the artifact for all of it.`));
+  }
+  viewer.replaceChildren(...parts);
+}
+
+function showInlineEvidence(f) {
+  $("viewer").replaceChildren(
+    el("header", {}, el("strong", {}, `${f.title} `, el("small", { class: "muted" }, "recorded evidence"))),
+    el("pre", {}, f.evidence));
+}
+
+function renderEvidence(m) {
+  const panel = $("evidence-panel");
+  const findings = m.findings.filter((f) => f.evidence || (f.artifacts || []).length > 0);
+  panel.hidden = m.role === "viewer";
+  if (panel.hidden) {
+    return;
+  }
+  if (findings.length === 0) {
+    $("artifacts").replaceChildren(el("li", { class: "muted" }, "No evidence recorded yet."));
+    return;
+  }
+
+  const button = (key, label, detail, open) => {
+    const b = el("button", { type: "button", class: key === selected ? "selected" : "" }, label, el("small", {}, detail));
+    b.addEventListener("click", () => {
+      selected = key;
+      for (const other of $("artifacts").querySelectorAll("button")) {
+        other.classList.toggle("selected", other === b);
+      }
+      open();
+    });
+    return el("li", {}, b);
+  };
+  const items = [];
+  for (const f of findings) {
+    items.push(el("li", { class: "finding" }, f.title));
+    if (f.evidence) {
+      items.push(button("text:" + f.id, "Recorded evidence", "text", () => showInlineEvidence(f)));
+    }
+    for (const a of f.artifacts || []) {
+      items.push(button(a.sha256, a.name, `${a.kind}, ${formatBytes(a.size)}`, () => showArtifact(a)));
+    }
+  }
+  $("artifacts").replaceChildren(...items);
+}
+
+function formatHours(seconds) {
+  const minutes = Math.round(seconds / 60);
+  return `${Math.floor(minutes / 60)}h${String(minutes % 60).padStart(2, "0")}m`;
+}
+
+// Archived missions only change when one is archived, so the trends are
+// loaded once and again when the gateway switches missions
+let statsLoaded = false;
+
+async function loadStats() {
+  const resp = await api("api/stats");
+  if (!resp.ok) {
+    return;
+  }
+  statsLoaded = true;
+  const s = await resp.json();
+  const item = (label, value) => el("div", {}, el("span", {}, label), el("strong", {}, value));
+  $("stats-summary").replaceChildren(el("div", { class: "summary" },
+    item("Missions", String(s.missions)),
+    item("Findings", String(s.findings)),
+    item("Model spend", formatUSD(s.cost)),
+    item("Spend per finding", s.findings > 0 ? formatUSD(s.cost_per_finding) : "—"),
+  ));
+  barChart($("trend-chart"), s.periods.map((p) => ({
+    label: p.period,
+    value: SEVERITIES.reduce((sum, sev) => sum + (p.severity[sev] || 0), 0),
+  })), (v) => String(v));
+  const row = (...cells) => el("tr", {}, ...cells.map((c) => el("td", {}, c)));
+  $("stats-models").replaceChildren(...(s.models.length > 0
+    ? s.models.map((m) => row(m.model, String(m.reviewed), String(m.rejected), Math.round(m.rejection_rate * 100) + "%"))
+    : [row("No supervisor reviews recorded.")]));
+  $("stats-phases").replaceChildren(...s.phases.map((p) => row(p.workflow, p.phase, String(p.missions),
+    p.elapsed_seconds > 0 ? formatHours(p.elapsed_seconds) : "—", formatHours(p.effort_seconds), formatUSD(p.cost))));
+  $("stats-workflows").replaceChildren(...s.workflows.map((w) => row(w.workflow, String(w.missions), String(w.findings),
+    formatUSD(w.cost), w.findings > 0 ? formatUSD(w.cost_per_finding) : "—")));
+}
+
+function renderStats(m) {
+  const panel = $("stats-panel");
+  panel.hidden = m.role !== "operator";
+  if (!panel.hidden && !statsLoaded) {
+    loadStats();
+  }
+}
+
+function showLogin() {
+  $("login").hidden = false;
+  $("connection").textContent = token ? "access denied" : "sign in required";
+  $("connection").classList.remove("live");
+}
+
+async function refresh() {
+  const resp = await api("api/mission");
+  if (resp.status === 401) {
+    showLogin();
+    return;
+  }
+  if (!resp.ok) {
+    return;
+  }
+  const m = await resp.json();
+  renderSummary(m);
+  renderPhases(m);
+  renderFindings(m);
+  renderCharts(m);
+  renderEvidence(m);
+  renderStats(m);
+}
+
+function addEvent(e) {
+  const text = [e.type.replace(/_/g, " "), e.phase ? `[${e.phase}]` : "", e.detail || ""].filter(Boolean).join(" ");
+  const list = $("events");
+  list.prepend(el("li", {}, el("time", {}, new Date(e.time).toLocaleTimeString()), text));
+  while (list.children.length > MAX_EVENTS) {
+    list.lastChild.remove();
+  }
+}
+
+// Refreshes are coalesced so a burst of events fetches the mission once
+let pending = null;
+function scheduleRefresh() {
+  if (pending === null) {
+    pending = setTimeout(() => { pending = null; refresh(); }, 250);
+  }
+}
+
+let source = null;
+function connect() {
+  if (source !== null) {
+    source.close();
+  }
+  source = new EventSource(withToken("api/events"));
+  const status = $("connection");
+  source.onopen = () => { status.textContent = "live"; status.classList.add("live"); };
+  source.onerror = () => {
+    status.textContent = "reconnecting…";
+    status.classList.remove("live");
+    // The browser gives up on a refused stream; find out whether the token
+    // was the reason
+    if (source.readyState === EventSource.CLOSED) {
+      refresh();
+    }
+  };
+  source.addEventListener("mission_event", (msg) => {
+    addEvent(JSON.parse(msg.data));
+    scheduleRefresh();
+  });
+  source.addEventListener("mission", () => {
+    $("events").replaceChildren();
+    statsLoaded = false;
+    scheduleRefresh();
+  });
+}
+
+$("login").addEventListener("submit", (ev) => {
+  ev.preventDefault();
+  token = $("token").value.trim();
+  sessionStorage.setItem(TOKEN_KEY, token);
+  statsLoaded = false;
+  $("login").hidden = true;
+  $("token").value = "";
+  refresh();
+  connect();
+});
+
+refresh();
+connect();
+// Spend and time change without mission events, so poll slowly as well
+setInterval(refresh, 30000);
@@ -0,0 +1,125 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Stats is the dashboard's view of the trends across archived missions
+type Stats struct {
+	Missions       int             `json:"missions"`
+	Findings       int             `json:"findings"`
+	Cost           float64         `json:"cost"`
+	CostPerFinding float64         `json:"cost_per_finding"`
+	Periods        []StatsPeriod   `json:"periods"`
+	Phases         []StatsPhase    `json:"phases"`
+	Workflows      []StatsWorkflow `json:"workflows"`
+	Models         []StatsModel    `json:"models"`
+}
+
+// StatsPeriod is the findings and spend of the missions started in a month
+type StatsPeriod struct {
+	Period   string         `json:"period"`
+	Missions int            `json:"missions"`
+	Severity map[string]int `json:"severity"`
+	Cost     float64        `json:"cost"`
+}
+
+// StatsPhase is the mean time and spend of a workflow phase
+type StatsPhase struct {
+	Workflow       string  `json:"workflow"`
+	Phase          string  `json:"phase"`
+	Missions       int     `json:"missions"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	EffortSeconds  float64 `json:"effort_seconds"`
+	Cost           float64 `json:"cost"`
+}
+
+// StatsWorkflow is the findings and spend of the missions run with a
+// workflow
+type StatsWorkflow struct {
+	Workflow       string  `json:"workflow"`
+	Missions       int     `json:"missions"`
+	Findings       int     `json:"findings"`
+	Cost           float64 `json:"cost"`
+	CostPerFinding float64 `json:"cost_per_finding"`
+}
+
+// StatsModel is how often the supervisor rejected a worker model's output
+type StatsModel struct {
+	Model         string  `json:"model"`
+	Reviewed      int     `json:"reviewed"`
+	Rejected      int     `json:"rejected"`
+	RejectionRate float64 `json:"rejection_rate"`
+}
+
+// statsView converts trends for the dashboard
+func statsView(t *workflow.Trends) Stats {
+	s := Stats{
+		Missions:       t.Missions,
+		Findings:       t.Findings,
+		Cost:           t.Cost,
+		CostPerFinding: t.CostPerFinding(),
+		Periods:        []StatsPeriod{},
+		Phases:         []StatsPhase{},
+		Workflows:      []StatsWorkflow{},
+		Models:         []StatsModel{},
+	}
+	for _, p := range t.Periods {
+		severity := make(map[string]int, len(p.Findings))
+		for sev, n := range p.Findings {
+			severity[string(sev)] = n
+		}
+		s.Periods = append(s.Periods, StatsPeriod{Period: p.Period, Missions: p.Missions, Severity: severity, Cost: p.Cost})
+	}
+	for _, p := range t.Phases {
+		s.Phases = append(s.Phases, StatsPhase{
+			Workflow:       p.Workflow,
+			Phase:          p.Phase,
+			Missions:       p.Missions,
+			ElapsedSeconds: p.Elapsed.Seconds(),
+			EffortSeconds:  p.Effort.Seconds(),
+			Cost:           p.Cost,
+		})
+	}
+	for _, w := range t.Workflows {
+		s.Workflows = append(s.Workflows, StatsWorkflow{
+			Workflow:       w.Workflow,
+			Missions:       w.Missions,
+			Findings:       w.Findings,
+			Cost:           w.Cost,
+			CostPerFinding: w.CostPerFinding(),
+		})
+	}
+	for _, m := range t.Models {
+		s.Models = append(s.Models, StatsModel{
+			Model:         m.Model,
+			Reviewed:      m.Reviewed,
+			Rejected:      m.Rejected,
+			RejectionRate: m.RejectionRate(),
+		})
+	}
+	return s
+}
+
+// handleStats serves the trends across the workspace's archived missions,
+// by month. They span every client, so only operators see them.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request, u user) {
+	if !u.role.canViewStats() {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	states, err := workflow.LoadMissionStates(s.agentLoop.GetRegistry().GetDefaultAgent().Workspace, true)
+	if err != nil {
+		http.Error(w, "failed to load archived missions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(statsView(workflow.AnalyzeTrends(states, workflow.TrendMonthly))); err != nil {
+		logger.WarnCF("dashboard", "Failed to write stats", map[string]any{"error": err.Error()})
+	}
+}
@@ -0,0 +1,109 @@
+// Package determinism makes runs reproducible for demos and regression tests.
+// Once seeded, IDs and random draws come from the seed and timestamps come
+// from a fake clock that starts at a fixed epoch and ticks forward on every
+// read, so two runs with the same seed and the same replayed LLM responses
+// produce the same mission state byte for byte. Unseeded, every function
+// falls through to the real clock and random sources.
+package determinism
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Epoch is the first timestamp the fake clock returns.
+var Epoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Tick is how far the fake clock advances per read. Every read moves it so
+// timestamps stay strictly increasing and orderings survive.
+const Tick = time.Millisecond
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	seed    int64
+	rng     *rand.Rand
+	clock   time.Time
+)
+
+// Enable switches the process into seeded mode and resets the fake clock.
+func Enable(s int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	seed = s
+	rng = rand.New(rand.NewSource(s))
+	clock = Epoch
+}
+
+// Disable restores the real clock and random sources.
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	rng = nil
+}
+
+// Enabled reports whether seeded mode is on.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Seed returns the active seed and whether seeded mode is on.
+func Seed() (int64, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	return seed, enabled
+}
+
+// Now returns the current time, or the next fake-clock reading when seeded.
+func Now() time.Time {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return time.Now()
+	}
+	clock = clock.Add(Tick)
+	return clock
+}
+
+// NewUUID returns a random UUID, drawn from the seed when seeded.
+func NewUUID() uuid.UUID {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return uuid.New()
+	}
+	id, err := uuid.NewRandomFromReader(rng)
+	if err != nil {
+		// rand.Rand reads never fail
+		panic(err)
+	}
+	return id
+}
+
+// Float64 returns a pseudo-random number in [0.0, 1.0), for jitter and
+// sampling.
+func Float64() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+// Intn returns a pseudo-random number in [0, n).
+func Intn(n int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return rand.Intn(n)
+	}
+	return rng.Intn(n)
+}
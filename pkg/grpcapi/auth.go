@@ -0,0 +1,83 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// OperatorRole is the gateway user role allowed to call the API. Every
+// method can run agent turns or read full findings, so there is no
+// read-only access.
+const OperatorRole = "operator"
+
+// operatorTokens returns the tokens of the gateway users with the operator
+// role
+func operatorTokens(users []config.GatewayUser) []string {
+	var tokens []string
+	for _, u := range users {
+		if strings.EqualFold(strings.TrimSpace(u.Role), OperatorRole) && u.Token != "" {
+			tokens = append(tokens, u.Token)
+		}
+	}
+	return tokens
+}
+
+// authorized reports whether ctx carries one of tokens as a bearer token in
+// the authorization metadata
+func authorized(ctx context.Context, tokens []string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok || token == "" {
+			continue
+		}
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "an operator bearer token from gateway.users is required")
+
+// authInterceptors reject calls without one of tokens
+func authInterceptors(tokens []string) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if !authorized(ctx, tokens) {
+				return nil, errUnauthenticated
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if !authorized(ss.Context(), tokens) {
+				return errUnauthenticated
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// IsLoopback reports whether host, a gateway.host value, only accepts
+// connections from this machine. An empty host listens everywhere.
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
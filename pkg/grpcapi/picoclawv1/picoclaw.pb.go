@@ -0,0 +1,1224 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: picoclaw/v1/picoclaw.proto
+
+// PicoClaw exposes the gateway's agent over gRPC so other tooling can embed
+// it with typed clients: sessions and their history, streamed message
+// turns, mission findings and routed model spend.
+
+package picoclawv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{0}
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type GetSessionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resolved like SendMessageRequest.session_key.
+	Key           string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSessionRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type Session struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Key          string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Summary      string                 `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	MessageCount int32                  `protobuf:"varint,3,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	Created      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created,proto3" json:"created,omitempty"`
+	Updated      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated,proto3" json:"updated,omitempty"`
+	// Only set by GetSession.
+	Messages      []*Message `protobuf:"bytes,6,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Session) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Session) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Session) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+func (x *Session) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *Session) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *Session) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	ToolCalls     []*ToolCall            `protobuf:"bytes,3,rep,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`
+	ToolCallId    string                 `protobuf:"bytes,4,opt,name=tool_call_id,json=toolCallId,proto3" json:"tool_call_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Message) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Message) GetToolCalls() []*ToolCall {
+	if x != nil {
+		return x.ToolCalls
+	}
+	return nil
+}
+
+func (x *Message) GetToolCallId() string {
+	if x != nil {
+		return x.ToolCallId
+	}
+	return ""
+}
+
+type ToolCall struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Arguments as a JSON object.
+	ArgumentsJson string `protobuf:"bytes,3,opt,name=arguments_json,json=argumentsJson,proto3" json:"arguments_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCall) Reset() {
+	*x = ToolCall{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCall) ProtoMessage() {}
+
+func (x *ToolCall) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCall.ProtoReflect.Descriptor instead.
+func (*ToolCall) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ToolCall) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ToolCall) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolCall) GetArgumentsJson() string {
+	if x != nil {
+		return x.ArgumentsJson
+	}
+	return ""
+}
+
+type SendMessageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to "grpc:default". Keys without an "agent:" prefix belong to
+	// the default agent.
+	SessionKey    string `protobuf:"bytes,1,opt,name=session_key,json=sessionKey,proto3" json:"session_key,omitempty"`
+	Content       string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageRequest) ProtoMessage() {}
+
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SendMessageRequest) GetSessionKey() string {
+	if x != nil {
+		return x.SessionKey
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type MessageEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*MessageEvent_ToolResult
+	//	*MessageEvent_Reply
+	Event         isMessageEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageEvent) Reset() {
+	*x = MessageEvent{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageEvent) ProtoMessage() {}
+
+func (x *MessageEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageEvent.ProtoReflect.Descriptor instead.
+func (*MessageEvent) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MessageEvent) GetEvent() isMessageEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *MessageEvent) GetToolResult() *ToolResult {
+	if x != nil {
+		if x, ok := x.Event.(*MessageEvent_ToolResult); ok {
+			return x.ToolResult
+		}
+	}
+	return nil
+}
+
+func (x *MessageEvent) GetReply() *Reply {
+	if x != nil {
+		if x, ok := x.Event.(*MessageEvent_Reply); ok {
+			return x.Reply
+		}
+	}
+	return nil
+}
+
+type isMessageEvent_Event interface {
+	isMessageEvent_Event()
+}
+
+type MessageEvent_ToolResult struct {
+	ToolResult *ToolResult `protobuf:"bytes,1,opt,name=tool_result,json=toolResult,proto3,oneof"`
+}
+
+type MessageEvent_Reply struct {
+	Reply *Reply `protobuf:"bytes,2,opt,name=reply,proto3,oneof"`
+}
+
+func (*MessageEvent_ToolResult) isMessageEvent_Event() {}
+
+func (*MessageEvent_Reply) isMessageEvent_Event() {}
+
+type ToolResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tool          string                 `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	ArgumentsJson string                 `protobuf:"bytes,2,opt,name=arguments_json,json=argumentsJson,proto3" json:"arguments_json,omitempty"`
+	// What the tool showed the user; empty for silent tools.
+	Output        string `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	IsError       bool   `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolResult) Reset() {
+	*x = ToolResult{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolResult) ProtoMessage() {}
+
+func (x *ToolResult) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolResult.ProtoReflect.Descriptor instead.
+func (*ToolResult) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ToolResult) GetTool() string {
+	if x != nil {
+		return x.Tool
+	}
+	return ""
+}
+
+func (x *ToolResult) GetArgumentsJson() string {
+	if x != nil {
+		return x.ArgumentsJson
+	}
+	return ""
+}
+
+func (x *ToolResult) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ToolResult) GetIsError() bool {
+	if x != nil {
+		return x.IsError
+	}
+	return false
+}
+
+type Reply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reply) Reset() {
+	*x = Reply{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reply) ProtoMessage() {}
+
+func (x *Reply) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reply.ProtoReflect.Descriptor instead.
+func (*Reply) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Reply) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type ListFindingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters by severity (critical, high, medium, low, informational) when
+	// set.
+	Severity      string `protobuf:"bytes,1,opt,name=severity,proto3" json:"severity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFindingsRequest) Reset() {
+	*x = ListFindingsRequest{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFindingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFindingsRequest) ProtoMessage() {}
+
+func (x *ListFindingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFindingsRequest.ProtoReflect.Descriptor instead.
+func (*ListFindingsRequest) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListFindingsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+type ListFindingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workflow      string                 `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Findings      []*Finding             `protobuf:"bytes,3,rep,name=findings,proto3" json:"findings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFindingsResponse) Reset() {
+	*x = ListFindingsResponse{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFindingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFindingsResponse) ProtoMessage() {}
+
+func (x *ListFindingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFindingsResponse.ProtoReflect.Descriptor instead.
+func (*ListFindingsResponse) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListFindingsResponse) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+func (x *ListFindingsResponse) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ListFindingsResponse) GetFindings() []*Finding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+type Finding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Severity      string                 `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"`
+	Phase         string                 `protobuf:"bytes,5,opt,name=phase,proto3" json:"phase,omitempty"`
+	Evidence      string                 `protobuf:"bytes,6,opt,name=evidence,proto3" json:"evidence,omitempty"`
+	Created       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Finding) Reset() {
+	*x = Finding{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Finding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Finding) ProtoMessage() {}
+
+func (x *Finding) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Finding.ProtoReflect.Descriptor instead.
+func (*Finding) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Finding) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Finding) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Finding) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Finding) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Finding) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Finding) GetEvidence() string {
+	if x != nil {
+		return x.Evidence
+	}
+	return ""
+}
+
+func (x *Finding) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+type GetCostRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resolved like SendMessageRequest.session_key.
+	SessionKey    string `protobuf:"bytes,1,opt,name=session_key,json=sessionKey,proto3" json:"session_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCostRequest) Reset() {
+	*x = GetCostRequest{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCostRequest) ProtoMessage() {}
+
+func (x *GetCostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCostRequest.ProtoReflect.Descriptor instead.
+func (*GetCostRequest) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetCostRequest) GetSessionKey() string {
+	if x != nil {
+		return x.SessionKey
+	}
+	return ""
+}
+
+type CostReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionKey    string                 `protobuf:"bytes,1,opt,name=session_key,json=sessionKey,proto3" json:"session_key,omitempty"`
+	TotalCost     float64                `protobuf:"fixed64,2,opt,name=total_cost,json=totalCost,proto3" json:"total_cost,omitempty"`
+	BaselineCost  float64                `protobuf:"fixed64,3,opt,name=baseline_cost,json=baselineCost,proto3" json:"baseline_cost,omitempty"`
+	BaselineTier  string                 `protobuf:"bytes,4,opt,name=baseline_tier,json=baselineTier,proto3" json:"baseline_tier,omitempty"`
+	ByModel       []*CostLine            `protobuf:"bytes,5,rep,name=by_model,json=byModel,proto3" json:"by_model,omitempty"`
+	ByTier        []*CostLine            `protobuf:"bytes,6,rep,name=by_tier,json=byTier,proto3" json:"by_tier,omitempty"`
+	ByPhase       []*CostLine            `protobuf:"bytes,7,rep,name=by_phase,json=byPhase,proto3" json:"by_phase,omitempty"`
+	ByTool        []*CostLine            `protobuf:"bytes,8,rep,name=by_tool,json=byTool,proto3" json:"by_tool,omitempty"`
+	ByPurpose     []*CostLine            `protobuf:"bytes,9,rep,name=by_purpose,json=byPurpose,proto3" json:"by_purpose,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CostReport) Reset() {
+	*x = CostReport{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CostReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CostReport) ProtoMessage() {}
+
+func (x *CostReport) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CostReport.ProtoReflect.Descriptor instead.
+func (*CostReport) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CostReport) GetSessionKey() string {
+	if x != nil {
+		return x.SessionKey
+	}
+	return ""
+}
+
+func (x *CostReport) GetTotalCost() float64 {
+	if x != nil {
+		return x.TotalCost
+	}
+	return 0
+}
+
+func (x *CostReport) GetBaselineCost() float64 {
+	if x != nil {
+		return x.BaselineCost
+	}
+	return 0
+}
+
+func (x *CostReport) GetBaselineTier() string {
+	if x != nil {
+		return x.BaselineTier
+	}
+	return ""
+}
+
+func (x *CostReport) GetByModel() []*CostLine {
+	if x != nil {
+		return x.ByModel
+	}
+	return nil
+}
+
+func (x *CostReport) GetByTier() []*CostLine {
+	if x != nil {
+		return x.ByTier
+	}
+	return nil
+}
+
+func (x *CostReport) GetByPhase() []*CostLine {
+	if x != nil {
+		return x.ByPhase
+	}
+	return nil
+}
+
+func (x *CostReport) GetByTool() []*CostLine {
+	if x != nil {
+		return x.ByTool
+	}
+	return nil
+}
+
+func (x *CostReport) GetByPurpose() []*CostLine {
+	if x != nil {
+		return x.ByPurpose
+	}
+	return nil
+}
+
+type CostLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Calls         int32                  `protobuf:"varint,2,opt,name=calls,proto3" json:"calls,omitempty"`
+	InputTokens   int64                  `protobuf:"varint,3,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
+	OutputTokens  int64                  `protobuf:"varint,4,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	Cost          float64                `protobuf:"fixed64,5,opt,name=cost,proto3" json:"cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CostLine) Reset() {
+	*x = CostLine{}
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CostLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CostLine) ProtoMessage() {}
+
+func (x *CostLine) ProtoReflect() protoreflect.Message {
+	mi := &file_picoclaw_v1_picoclaw_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CostLine.ProtoReflect.Descriptor instead.
+func (*CostLine) Descriptor() ([]byte, []int) {
+	return file_picoclaw_v1_picoclaw_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CostLine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CostLine) GetCalls() int32 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *CostLine) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *CostLine) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *CostLine) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+var File_picoclaw_v1_picoclaw_proto protoreflect.FileDescriptor
+
+const file_picoclaw_v1_picoclaw_proto_rawDesc = "" +
+	"\n" +
+	"\x1apicoclaw/v1/picoclaw.proto\x12\vpicoclaw.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x15\n" +
+	"\x13ListSessionsRequest\"H\n" +
+	"\x14ListSessionsResponse\x120\n" +
+	"\bsessions\x18\x01 \x03(\v2\x14.picoclaw.v1.SessionR\bsessions\"%\n" +
+	"\x11GetSessionRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xf8\x01\n" +
+	"\aSession\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\asummary\x18\x02 \x01(\tR\asummary\x12#\n" +
+	"\rmessage_count\x18\x03 \x01(\x05R\fmessageCount\x124\n" +
+	"\acreated\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x124\n" +
+	"\aupdated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\x120\n" +
+	"\bmessages\x18\x06 \x03(\v2\x14.picoclaw.v1.MessageR\bmessages\"\x8f\x01\n" +
+	"\aMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x124\n" +
+	"\n" +
+	"tool_calls\x18\x03 \x03(\v2\x15.picoclaw.v1.ToolCallR\ttoolCalls\x12 \n" +
+	"\ftool_call_id\x18\x04 \x01(\tR\n" +
+	"toolCallId\"U\n" +
+	"\bToolCall\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12%\n" +
+	"\x0earguments_json\x18\x03 \x01(\tR\rargumentsJson\"O\n" +
+	"\x12SendMessageRequest\x12\x1f\n" +
+	"\vsession_key\x18\x01 \x01(\tR\n" +
+	"sessionKey\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"\x7f\n" +
+	"\fMessageEvent\x12:\n" +
+	"\vtool_result\x18\x01 \x01(\v2\x17.picoclaw.v1.ToolResultH\x00R\n" +
+	"toolResult\x12*\n" +
+	"\x05reply\x18\x02 \x01(\v2\x12.picoclaw.v1.ReplyH\x00R\x05replyB\a\n" +
+	"\x05event\"z\n" +
+	"\n" +
+	"ToolResult\x12\x12\n" +
+	"\x04tool\x18\x01 \x01(\tR\x04tool\x12%\n" +
+	"\x0earguments_json\x18\x02 \x01(\tR\rargumentsJson\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\tR\x06output\x12\x19\n" +
+	"\bis_error\x18\x04 \x01(\bR\aisError\"!\n" +
+	"\x05Reply\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\"1\n" +
+	"\x13ListFindingsRequest\x12\x1a\n" +
+	"\bseverity\x18\x01 \x01(\tR\bseverity\"|\n" +
+	"\x14ListFindingsResponse\x12\x1a\n" +
+	"\bworkflow\x18\x01 \x01(\tR\bworkflow\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x120\n" +
+	"\bfindings\x18\x03 \x03(\v2\x14.picoclaw.v1.FindingR\bfindings\"\xd5\x01\n" +
+	"\aFinding\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\tR\bseverity\x12\x14\n" +
+	"\x05phase\x18\x05 \x01(\tR\x05phase\x12\x1a\n" +
+	"\bevidence\x18\x06 \x01(\tR\bevidence\x124\n" +
+	"\acreated\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\acreated\"1\n" +
+	"\x0eGetCostRequest\x12\x1f\n" +
+	"\vsession_key\x18\x01 \x01(\tR\n" +
+	"sessionKey\"\x90\x03\n" +
+	"\n" +
+	"CostReport\x12\x1f\n" +
+	"\vsession_key\x18\x01 \x01(\tR\n" +
+	"sessionKey\x12\x1d\n" +
+	"\n" +
+	"total_cost\x18\x02 \x01(\x01R\ttotalCost\x12#\n" +
+	"\rbaseline_cost\x18\x03 \x01(\x01R\fbaselineCost\x12#\n" +
+	"\rbaseline_tier\x18\x04 \x01(\tR\fbaselineTier\x120\n" +
+	"\bby_model\x18\x05 \x03(\v2\x15.picoclaw.v1.CostLineR\abyModel\x12.\n" +
+	"\aby_tier\x18\x06 \x03(\v2\x15.picoclaw.v1.CostLineR\x06byTier\x120\n" +
+	"\bby_phase\x18\a \x03(\v2\x15.picoclaw.v1.CostLineR\abyPhase\x12.\n" +
+	"\aby_tool\x18\b \x03(\v2\x15.picoclaw.v1.CostLineR\x06byTool\x124\n" +
+	"\n" +
+	"by_purpose\x18\t \x03(\v2\x15.picoclaw.v1.CostLineR\tbyPurpose\"\x90\x01\n" +
+	"\bCostLine\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05calls\x18\x02 \x01(\x05R\x05calls\x12!\n" +
+	"\finput_tokens\x18\x03 \x01(\x03R\vinputTokens\x12#\n" +
+	"\routput_tokens\x18\x04 \x01(\x03R\foutputTokens\x12\x12\n" +
+	"\x04cost\x18\x05 \x01(\x01R\x04cost2\x86\x03\n" +
+	"\bPicoClaw\x12S\n" +
+	"\fListSessions\x12 .picoclaw.v1.ListSessionsRequest\x1a!.picoclaw.v1.ListSessionsResponse\x12B\n" +
+	"\n" +
+	"GetSession\x12\x1e.picoclaw.v1.GetSessionRequest\x1a\x14.picoclaw.v1.Session\x12K\n" +
+	"\vSendMessage\x12\x1f.picoclaw.v1.SendMessageRequest\x1a\x19.picoclaw.v1.MessageEvent0\x01\x12S\n" +
+	"\fListFindings\x12 .picoclaw.v1.ListFindingsRequest\x1a!.picoclaw.v1.ListFindingsResponse\x12?\n" +
+	"\aGetCost\x12\x1b.picoclaw.v1.GetCostRequest\x1a\x17.picoclaw.v1.CostReportBKZIgithub.com/ResistanceIsUseless/picoclaw/pkg/grpcapi/picoclawv1;picoclawv1b\x06proto3"
+
+var (
+	file_picoclaw_v1_picoclaw_proto_rawDescOnce sync.Once
+	file_picoclaw_v1_picoclaw_proto_rawDescData []byte
+)
+
+func file_picoclaw_v1_picoclaw_proto_rawDescGZIP() []byte {
+	file_picoclaw_v1_picoclaw_proto_rawDescOnce.Do(func() {
+		file_picoclaw_v1_picoclaw_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_picoclaw_v1_picoclaw_proto_rawDesc), len(file_picoclaw_v1_picoclaw_proto_rawDesc)))
+	})
+	return file_picoclaw_v1_picoclaw_proto_rawDescData
+}
+
+var file_picoclaw_v1_picoclaw_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_picoclaw_v1_picoclaw_proto_goTypes = []any{
+	(*ListSessionsRequest)(nil),   // 0: picoclaw.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),  // 1: picoclaw.v1.ListSessionsResponse
+	(*GetSessionRequest)(nil),     // 2: picoclaw.v1.GetSessionRequest
+	(*Session)(nil),               // 3: picoclaw.v1.Session
+	(*Message)(nil),               // 4: picoclaw.v1.Message
+	(*ToolCall)(nil),              // 5: picoclaw.v1.ToolCall
+	(*SendMessageRequest)(nil),    // 6: picoclaw.v1.SendMessageRequest
+	(*MessageEvent)(nil),          // 7: picoclaw.v1.MessageEvent
+	(*ToolResult)(nil),            // 8: picoclaw.v1.ToolResult
+	(*Reply)(nil),                 // 9: picoclaw.v1.Reply
+	(*ListFindingsRequest)(nil),   // 10: picoclaw.v1.ListFindingsRequest
+	(*ListFindingsResponse)(nil),  // 11: picoclaw.v1.ListFindingsResponse
+	(*Finding)(nil),               // 12: picoclaw.v1.Finding
+	(*GetCostRequest)(nil),        // 13: picoclaw.v1.GetCostRequest
+	(*CostReport)(nil),            // 14: picoclaw.v1.CostReport
+	(*CostLine)(nil),              // 15: picoclaw.v1.CostLine
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_picoclaw_v1_picoclaw_proto_depIdxs = []int32{
+	3,  // 0: picoclaw.v1.ListSessionsResponse.sessions:type_name -> picoclaw.v1.Session
+	16, // 1: picoclaw.v1.Session.created:type_name -> google.protobuf.Timestamp
+	16, // 2: picoclaw.v1.Session.updated:type_name -> google.protobuf.Timestamp
+	4,  // 3: picoclaw.v1.Session.messages:type_name -> picoclaw.v1.Message
+	5,  // 4: picoclaw.v1.Message.tool_calls:type_name -> picoclaw.v1.ToolCall
+	8,  // 5: picoclaw.v1.MessageEvent.tool_result:type_name -> picoclaw.v1.ToolResult
+	9,  // 6: picoclaw.v1.MessageEvent.reply:type_name -> picoclaw.v1.Reply
+	12, // 7: picoclaw.v1.ListFindingsResponse.findings:type_name -> picoclaw.v1.Finding
+	16, // 8: picoclaw.v1.Finding.created:type_name -> google.protobuf.Timestamp
+	15, // 9: picoclaw.v1.CostReport.by_model:type_name -> picoclaw.v1.CostLine
+	15, // 10: picoclaw.v1.CostReport.by_tier:type_name -> picoclaw.v1.CostLine
+	15, // 11: picoclaw.v1.CostReport.by_phase:type_name -> picoclaw.v1.CostLine
+	15, // 12: picoclaw.v1.CostReport.by_tool:type_name -> picoclaw.v1.CostLine
+	15, // 13: picoclaw.v1.CostReport.by_purpose:type_name -> picoclaw.v1.CostLine
+	0,  // 14: picoclaw.v1.PicoClaw.ListSessions:input_type -> picoclaw.v1.ListSessionsRequest
+	2,  // 15: picoclaw.v1.PicoClaw.GetSession:input_type -> picoclaw.v1.GetSessionRequest
+	6,  // 16: picoclaw.v1.PicoClaw.SendMessage:input_type -> picoclaw.v1.SendMessageRequest
+	10, // 17: picoclaw.v1.PicoClaw.ListFindings:input_type -> picoclaw.v1.ListFindingsRequest
+	13, // 18: picoclaw.v1.PicoClaw.GetCost:input_type -> picoclaw.v1.GetCostRequest
+	1,  // 19: picoclaw.v1.PicoClaw.ListSessions:output_type -> picoclaw.v1.ListSessionsResponse
+	3,  // 20: picoclaw.v1.PicoClaw.GetSession:output_type -> picoclaw.v1.Session
+	7,  // 21: picoclaw.v1.PicoClaw.SendMessage:output_type -> picoclaw.v1.MessageEvent
+	11, // 22: picoclaw.v1.PicoClaw.ListFindings:output_type -> picoclaw.v1.ListFindingsResponse
+	14, // 23: picoclaw.v1.PicoClaw.GetCost:output_type -> picoclaw.v1.CostReport
+	19, // [19:24] is the sub-list for method output_type
+	14, // [14:19] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_picoclaw_v1_picoclaw_proto_init() }
+func file_picoclaw_v1_picoclaw_proto_init() {
+	if File_picoclaw_v1_picoclaw_proto != nil {
+		return
+	}
+	file_picoclaw_v1_picoclaw_proto_msgTypes[7].OneofWrappers = []any{
+		(*MessageEvent_ToolResult)(nil),
+		(*MessageEvent_Reply)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_picoclaw_v1_picoclaw_proto_rawDesc), len(file_picoclaw_v1_picoclaw_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_picoclaw_v1_picoclaw_proto_goTypes,
+		DependencyIndexes: file_picoclaw_v1_picoclaw_proto_depIdxs,
+		MessageInfos:      file_picoclaw_v1_picoclaw_proto_msgTypes,
+	}.Build()
+	File_picoclaw_v1_picoclaw_proto = out.File
+	file_picoclaw_v1_picoclaw_proto_goTypes = nil
+	file_picoclaw_v1_picoclaw_proto_depIdxs = nil
+}
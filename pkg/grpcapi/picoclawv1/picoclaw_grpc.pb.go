@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.29.3
+// source: picoclaw/v1/picoclaw.proto
+
+// PicoClaw exposes the gateway's agent over gRPC so other tooling can embed
+// it with typed clients: sessions and their history, streamed message
+// turns, mission findings and routed model spend.
+
+package picoclawv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PicoClaw_ListSessions_FullMethodName = "/picoclaw.v1.PicoClaw/ListSessions"
+	PicoClaw_GetSession_FullMethodName   = "/picoclaw.v1.PicoClaw/GetSession"
+	PicoClaw_SendMessage_FullMethodName  = "/picoclaw.v1.PicoClaw/SendMessage"
+	PicoClaw_ListFindings_FullMethodName = "/picoclaw.v1.PicoClaw/ListFindings"
+	PicoClaw_GetCost_FullMethodName      = "/picoclaw.v1.PicoClaw/GetCost"
+)
+
+// PicoClawClient is the client API for PicoClaw service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PicoClawClient interface {
+	// ListSessions returns every session the gateway knows, newest first.
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// GetSession returns one session with its message history.
+	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	// SendMessage runs one agent turn and streams its tool results followed
+	// by the final reply.
+	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageEvent], error)
+	// ListFindings returns the active mission's findings.
+	ListFindings(ctx context.Context, in *ListFindingsRequest, opts ...grpc.CallOption) (*ListFindingsResponse, error)
+	// GetCost returns routed model spend for a session.
+	GetCost(ctx context.Context, in *GetCostRequest, opts ...grpc.CallOption) (*CostReport, error)
+}
+
+type picoClawClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPicoClawClient(cc grpc.ClientConnInterface) PicoClawClient {
+	return &picoClawClient{cc}
+}
+
+func (c *picoClawClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, PicoClaw_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoClawClient) GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, PicoClaw_GetSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoClawClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MessageEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PicoClaw_ServiceDesc.Streams[0], PicoClaw_SendMessage_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SendMessageRequest, MessageEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PicoClaw_SendMessageClient = grpc.ServerStreamingClient[MessageEvent]
+
+func (c *picoClawClient) ListFindings(ctx context.Context, in *ListFindingsRequest, opts ...grpc.CallOption) (*ListFindingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFindingsResponse)
+	err := c.cc.Invoke(ctx, PicoClaw_ListFindings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoClawClient) GetCost(ctx context.Context, in *GetCostRequest, opts ...grpc.CallOption) (*CostReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CostReport)
+	err := c.cc.Invoke(ctx, PicoClaw_GetCost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PicoClawServer is the server API for PicoClaw service.
+// All implementations must embed UnimplementedPicoClawServer
+// for forward compatibility.
+type PicoClawServer interface {
+	// ListSessions returns every session the gateway knows, newest first.
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// GetSession returns one session with its message history.
+	GetSession(context.Context, *GetSessionRequest) (*Session, error)
+	// SendMessage runs one agent turn and streams its tool results followed
+	// by the final reply.
+	SendMessage(*SendMessageRequest, grpc.ServerStreamingServer[MessageEvent]) error
+	// ListFindings returns the active mission's findings.
+	ListFindings(context.Context, *ListFindingsRequest) (*ListFindingsResponse, error)
+	// GetCost returns routed model spend for a session.
+	GetCost(context.Context, *GetCostRequest) (*CostReport, error)
+	mustEmbedUnimplementedPicoClawServer()
+}
+
+// UnimplementedPicoClawServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPicoClawServer struct{}
+
+func (UnimplementedPicoClawServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedPicoClawServer) GetSession(context.Context, *GetSessionRequest) (*Session, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedPicoClawServer) SendMessage(*SendMessageRequest, grpc.ServerStreamingServer[MessageEvent]) error {
+	return status.Error(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (UnimplementedPicoClawServer) ListFindings(context.Context, *ListFindingsRequest) (*ListFindingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListFindings not implemented")
+}
+func (UnimplementedPicoClawServer) GetCost(context.Context, *GetCostRequest) (*CostReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCost not implemented")
+}
+func (UnimplementedPicoClawServer) mustEmbedUnimplementedPicoClawServer() {}
+func (UnimplementedPicoClawServer) testEmbeddedByValue()                  {}
+
+// UnsafePicoClawServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PicoClawServer will
+// result in compilation errors.
+type UnsafePicoClawServer interface {
+	mustEmbedUnimplementedPicoClawServer()
+}
+
+func RegisterPicoClawServer(s grpc.ServiceRegistrar, srv PicoClawServer) {
+	// If the following call panics, it indicates UnimplementedPicoClawServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PicoClaw_ServiceDesc, srv)
+}
+
+func _PicoClaw_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoClawServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PicoClaw_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoClawServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PicoClaw_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoClawServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PicoClaw_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoClawServer).GetSession(ctx, req.(*GetSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PicoClaw_SendMessage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SendMessageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PicoClawServer).SendMessage(m, &grpc.GenericServerStream[SendMessageRequest, MessageEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PicoClaw_SendMessageServer = grpc.ServerStreamingServer[MessageEvent]
+
+func _PicoClaw_ListFindings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFindingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoClawServer).ListFindings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PicoClaw_ListFindings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoClawServer).ListFindings(ctx, req.(*ListFindingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PicoClaw_GetCost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoClawServer).GetCost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PicoClaw_GetCost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoClawServer).GetCost(ctx, req.(*GetCostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PicoClaw_ServiceDesc is the grpc.ServiceDesc for PicoClaw service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PicoClaw_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "picoclaw.v1.PicoClaw",
+	HandlerType: (*PicoClawServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSessions",
+			Handler:    _PicoClaw_ListSessions_Handler,
+		},
+		{
+			MethodName: "GetSession",
+			Handler:    _PicoClaw_GetSession_Handler,
+		},
+		{
+			MethodName: "ListFindings",
+			Handler:    _PicoClaw_ListFindings_Handler,
+		},
+		{
+			MethodName: "GetCost",
+			Handler:    _PicoClaw_GetCost_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendMessage",
+			Handler:       _PicoClaw_SendMessage_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "picoclaw/v1/picoclaw.proto",
+}
@@ -0,0 +1,321 @@
+// Package grpcapi serves the picoclaw.v1.PicoClaw gRPC API, giving other
+// tooling typed access to the gateway's sessions, agent turns, findings and
+// spend. The service is defined in api/proto/picoclaw/v1/picoclaw.proto.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	pb "github.com/ResistanceIsUseless/picoclaw/pkg/grpcapi/picoclawv1"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/session"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Channel is the channel name turns sent over the API are processed under.
+const Channel = "grpc"
+
+const defaultSessionKey = "grpc:default"
+
+// Server implements the PicoClaw service on top of an agent loop.
+type Server struct {
+	pb.UnimplementedPicoClawServer
+
+	agentLoop     *agent.AgentLoop
+	server        *grpc.Server
+	authenticated bool
+}
+
+// NewServer creates a gRPC server exposing agentLoop. Calls must carry the
+// token of one of the users with the operator role as a bearer token in
+// the authorization metadata. Without such users every call is accepted,
+// so the server must only listen on loopback; see Authenticated.
+func NewServer(agentLoop *agent.AgentLoop, users []config.GatewayUser, opts ...grpc.ServerOption) *Server {
+	s := &Server{agentLoop: agentLoop}
+	if tokens := operatorTokens(users); len(tokens) > 0 {
+		s.authenticated = true
+		opts = append(opts, authInterceptors(tokens)...)
+	}
+	s.server = grpc.NewServer(opts...)
+	pb.RegisterPicoClawServer(s.server, s)
+	return s
+}
+
+// Authenticated reports whether calls need an operator token
+func (s *Server) Authenticated() bool {
+	return s.authenticated
+}
+
+// Serve accepts connections on lis until Stop is called.
+func (s *Server) Serve(lis net.Listener) error {
+	logger.InfoCF("grpcapi", "gRPC API listening", map[string]any{"addr": lis.Addr().String()})
+	return s.server.Serve(lis)
+}
+
+// Stop closes all connections, cancelling in-flight turns.
+func (s *Server) Stop() {
+	s.server.Stop()
+}
+
+// ListSessions returns the sessions of every agent, newest first.
+func (s *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	var infos []session.SessionInfo
+	seen := make(map[string]bool)
+	for _, manager := range s.sessionManagers() {
+		for _, info := range manager.List() {
+			if !seen[info.Key] {
+				seen[info.Key] = true
+				infos = append(infos, info)
+			}
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Updated.After(infos[j].Updated)
+	})
+
+	resp := &pb.ListSessionsResponse{Sessions: make([]*pb.Session, 0, len(infos))}
+	for _, info := range infos {
+		resp.Sessions = append(resp.Sessions, sessionProto(info))
+	}
+	return resp, nil
+}
+
+// GetSession returns a session and its message history.
+func (s *Server) GetSession(ctx context.Context, req *pb.GetSessionRequest) (*pb.Session, error) {
+	key, err := s.sessionKey(req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	for _, manager := range s.sessionManagers() {
+		for _, info := range manager.List() {
+			if info.Key != key {
+				continue
+			}
+			sess := sessionProto(info)
+			for _, msg := range manager.GetHistory(key) {
+				sess.Messages = append(sess.Messages, messageProto(msg))
+			}
+			return sess, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "session %q not found", key)
+}
+
+// SendMessage runs one agent turn, streaming each tool result as it
+// completes and the final reply last.
+func (s *Server) SendMessage(req *pb.SendMessageRequest, stream pb.PicoClaw_SendMessageServer) error {
+	if strings.TrimSpace(req.GetContent()) == "" {
+		return status.Error(codes.InvalidArgument, "content is required")
+	}
+	key, err := s.sessionKey(req.GetSessionKey())
+	if err != nil {
+		return err
+	}
+
+	// A failed send means the client went away, which cancels the stream
+	// context and ends the turn. Sends are serialized as gRPC streams do not
+	// allow concurrent writers.
+	var sendMu sync.Mutex
+	send := func(event *pb.MessageEvent) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(event)
+	}
+	ctx := agent.WithToolResultHandler(stream.Context(),
+		func(toolName string, args map[string]any, result *tools.ToolResult) {
+			event := &pb.ToolResult{
+				Tool:          toolName,
+				ArgumentsJson: argumentsJSON(args),
+				IsError:       result.IsError,
+			}
+			if !result.Silent {
+				event.Output = result.ForUser
+			}
+			if err := send(&pb.MessageEvent{Event: &pb.MessageEvent_ToolResult{ToolResult: event}}); err != nil {
+				logger.WarnCF("grpcapi", "Failed to stream tool result",
+					map[string]any{"tool": toolName, "error": err.Error()})
+			}
+		})
+
+	reply, err := s.agentLoop.ProcessDirectWithChannel(ctx, req.GetContent(), key, Channel, key)
+	if err != nil {
+		return status.Errorf(codes.Internal, "processing message: %v", err)
+	}
+	return send(&pb.MessageEvent{Event: &pb.MessageEvent_Reply{Reply: &pb.Reply{Content: reply}}})
+}
+
+// ListFindings returns the default agent's mission findings.
+func (s *Server) ListFindings(ctx context.Context, req *pb.ListFindingsRequest) (*pb.ListFindingsResponse, error) {
+	defaultAgent := s.agentLoop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil || defaultAgent.WorkflowEngine == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no active mission")
+	}
+	state := defaultAgent.WorkflowEngine.GetState()
+
+	resp := &pb.ListFindingsResponse{
+		Workflow: state.WorkflowName,
+		Target:   state.Target,
+	}
+	for _, f := range state.Findings {
+		if req.GetSeverity() != "" && !strings.EqualFold(string(f.Severity), req.GetSeverity()) {
+			continue
+		}
+		resp.Findings = append(resp.Findings, findingProto(f))
+	}
+	return resp, nil
+}
+
+// GetCost returns a session's routed spend.
+func (s *Server) GetCost(ctx context.Context, req *pb.GetCostRequest) (*pb.CostReport, error) {
+	router := s.agentLoop.GetTierRouter()
+	if router == nil || !router.IsEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "tier routing is disabled")
+	}
+	key, err := s.sessionKey(req.GetSessionKey())
+	if err != nil {
+		return nil, err
+	}
+	cost := router.GetCostTracker().GetSessionCost(key)
+	if cost == nil {
+		return nil, status.Errorf(codes.NotFound, "no spend recorded for session %q", key)
+	}
+	return costProto(cost), nil
+}
+
+// sessionKey resolves a client session key, scoping keys without an
+// "agent:" prefix to the default agent.
+func (s *Server) sessionKey(key string) (string, error) {
+	if key == "" {
+		key = defaultSessionKey
+	}
+	if s.agentLoop.GetRegistry().GetDefaultAgent() == nil {
+		return "", status.Error(codes.FailedPrecondition, "no agent configured")
+	}
+	return s.agentLoop.DirectSessionKey(key), nil
+}
+
+func (s *Server) sessionManagers() []*session.SessionManager {
+	registry := s.agentLoop.GetRegistry()
+	var managers []*session.SessionManager
+	for _, id := range registry.ListAgentIDs() {
+		if instance, ok := registry.GetAgent(id); ok && instance.Sessions != nil {
+			managers = append(managers, instance.Sessions)
+		}
+	}
+	return managers
+}
+
+func sessionProto(info session.SessionInfo) *pb.Session {
+	return &pb.Session{
+		Key:          info.Key,
+		Summary:      info.Summary,
+		MessageCount: int32(info.MessageCount),
+		Created:      timestamppb.New(info.Created),
+		Updated:      timestamppb.New(info.Updated),
+	}
+}
+
+func messageProto(msg providers.Message) *pb.Message {
+	m := &pb.Message{
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCallId: msg.ToolCallID,
+	}
+	for _, tc := range msg.ToolCalls {
+		call := &pb.ToolCall{Id: tc.ID, Name: tc.Name, ArgumentsJson: argumentsJSON(tc.Arguments)}
+		if call.Name == "" && tc.Function != nil {
+			call.Name = tc.Function.Name
+			call.ArgumentsJson = tc.Function.Arguments
+		}
+		m.ToolCalls = append(m.ToolCalls, call)
+	}
+	return m
+}
+
+func findingProto(f workflow.Finding) *pb.Finding {
+	return &pb.Finding{
+		Id:          f.ID,
+		Title:       f.Title,
+		Description: f.Description,
+		Severity:    string(f.Severity),
+		Phase:       f.Phase,
+		Evidence:    f.Evidence,
+		Created:     timestamppb.New(f.CreatedAt),
+	}
+}
+
+func costProto(cost *routing.SessionCost) *pb.CostReport {
+	report := &pb.CostReport{
+		SessionKey:   cost.SessionKey,
+		TotalCost:    cost.TotalCost,
+		BaselineCost: cost.BaselineCost,
+		BaselineTier: cost.BaselineTier,
+	}
+	for name, m := range cost.ByModel {
+		report.ByModel = append(report.ByModel, costLine(name, m.Calls, m.InputTokens, m.OutputTokens, m.TotalCost))
+	}
+	for name, t := range cost.ByTier {
+		report.ByTier = append(report.ByTier, costLine(name, t.Calls, t.InputTokens, t.OutputTokens, t.TotalCost))
+	}
+	report.ByPhase = attributedLines(cost.ByPhase)
+	report.ByTool = attributedLines(cost.ByTool)
+	report.ByPurpose = attributedLines(cost.ByPurpose)
+	sortCostLines(report.ByModel)
+	sortCostLines(report.ByTier)
+	return report
+}
+
+func attributedLines(costs map[string]*routing.AttributedCost) []*pb.CostLine {
+	lines := make([]*pb.CostLine, 0, len(costs))
+	for name, c := range costs {
+		lines = append(lines, costLine(name, c.Calls, c.InputTokens, c.OutputTokens, c.TotalCost))
+	}
+	sortCostLines(lines)
+	return lines
+}
+
+func costLine(name string, calls, inputTokens, outputTokens int, cost float64) *pb.CostLine {
+	return &pb.CostLine{
+		Name:         name,
+		Calls:        int32(calls),
+		InputTokens:  int64(inputTokens),
+		OutputTokens: int64(outputTokens),
+		Cost:         cost,
+	}
+}
+
+// sortCostLines orders lines by cost, highest first.
+func sortCostLines(lines []*pb.CostLine) {
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Cost != lines[j].Cost {
+			return lines[i].Cost > lines[j].Cost
+		}
+		return lines[i].Name < lines[j].Name
+	})
+}
+
+func argumentsJSON(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,230 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	pb "github.com/ResistanceIsUseless/picoclaw/pkg/grpcapi/picoclawv1"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// listDirProvider lists the workspace once, then answers.
+type listDirProvider struct{}
+
+func (p *listDirProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Role == "tool" {
+		return &providers.LLMResponse{Content: "The workspace is listed."}, nil
+	}
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:        "call_1",
+			Name:      "list_dir",
+			Arguments: map[string]any{"path": "."},
+		}},
+	}, nil
+}
+
+func (p *listDirProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func newTestClient(t *testing.T, users ...config.GatewayUser) pb.PicoClawClient {
+	t.Helper()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 5,
+			},
+		},
+	}
+	agentLoop := agent.NewAgentLoop(cfg, bus.NewMessageBus(), &listDirProvider{})
+
+	lis := bufconn.Listen(1 << 20)
+	server := NewServer(agentLoop, users)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPicoClawClient(conn)
+}
+
+func TestServer_SendMessageStreamsToolResults(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	stream, err := client.SendMessage(ctx, &pb.SendMessageRequest{SessionKey: "ci", Content: "what is here?"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	var events []*pb.MessageEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want tool result then reply: %v", len(events), events)
+	}
+	if tr := events[0].GetToolResult(); tr == nil || tr.Tool != "list_dir" || tr.ArgumentsJson != `{"path":"."}` {
+		t.Errorf("first event = %v, want list_dir tool result", events[0])
+	}
+	if reply := events[1].GetReply(); reply.GetContent() != "The workspace is listed." {
+		t.Errorf("reply = %q", reply.GetContent())
+	}
+
+	sessions, err := client.ListSessions(ctx, &pb.ListSessionsRequest{})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions.Sessions) != 1 || sessions.Sessions[0].Key != "agent:main:ci" {
+		t.Fatalf("sessions = %v, want agent:main:ci", sessions.Sessions)
+	}
+
+	sess, err := client.GetSession(ctx, &pb.GetSessionRequest{Key: "ci"})
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	roles := make([]string, 0, len(sess.Messages))
+	for _, m := range sess.Messages {
+		roles = append(roles, m.Role)
+	}
+	if len(roles) != 4 || roles[0] != "user" || roles[2] != "tool" || roles[3] != "assistant" {
+		t.Errorf("history roles = %v, want user, assistant, tool, assistant", roles)
+	}
+	if calls := sess.Messages[1].ToolCalls; len(calls) != 1 || calls[0].Name != "list_dir" {
+		t.Errorf("assistant tool calls = %v", calls)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"unknown session", func() error {
+			_, err := client.GetSession(ctx, &pb.GetSessionRequest{Key: "missing"})
+			return err
+		}, codes.NotFound},
+		{"no mission", func() error {
+			_, err := client.ListFindings(ctx, &pb.ListFindingsRequest{})
+			return err
+		}, codes.FailedPrecondition},
+		{"routing disabled", func() error {
+			_, err := client.GetCost(ctx, &pb.GetCostRequest{})
+			return err
+		}, codes.FailedPrecondition},
+		{"empty message", func() error {
+			stream, err := client.SendMessage(ctx, &pb.SendMessageRequest{})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_RequiresOperatorToken(t *testing.T) {
+	client := newTestClient(t,
+		config.GatewayUser{Name: "alice", Token: "op-token", Role: "operator"},
+		config.GatewayUser{Name: "client", Token: "viewer-token", Role: "viewer"})
+
+	call := func(ctx context.Context) error {
+		_, err := client.GetSession(ctx, &pb.GetSessionRequest{Key: "missing"})
+		return err
+	}
+	stream := func(ctx context.Context) error {
+		s, err := client.SendMessage(ctx, &pb.SendMessageRequest{Content: "list the workspace"})
+		if err != nil {
+			return err
+		}
+		_, err = s.Recv()
+		return err
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          codes.Code
+	}{
+		{"no token", "", codes.Unauthenticated},
+		{"wrong token", "Bearer nope", codes.Unauthenticated},
+		{"viewer token", "Bearer viewer-token", codes.Unauthenticated},
+		{"token without bearer", "op-token", codes.Unauthenticated},
+		{"operator token", "Bearer op-token", codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.authorization)
+			}
+			if got := status.Code(call(ctx)); got != tt.want {
+				t.Errorf("unary code = %v, want %v", got, tt.want)
+			}
+			if tt.want == codes.Unauthenticated {
+				if got := status.Code(stream(ctx)); got != codes.Unauthenticated {
+					t.Errorf("stream code = %v, want Unauthenticated", got)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, want := range map[string]bool{
+		"localhost": true, "127.0.0.1": true, "::1": true, "[::1]": true,
+		"": false, "0.0.0.0": false, "::": false, "192.168.1.10": false, "gateway.lan": false,
+	} {
+		if got := IsLoopback(host); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/constants"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/state"
+)
+
+const (
+	defaultProbeInterval = 5 * time.Minute
+	defaultProbeTimeout  = 15 * time.Second
+
+	// ProbeModels and ProbeChat name how a provider was checked
+	ProbeModels = "models"
+	ProbeChat   = "chat"
+)
+
+// ProviderStatus is the latest health check result for one configured model.
+type ProviderStatus struct {
+	Model     string                   `json:"model"`    // model_name from model_list
+	Provider  string                   `json:"provider"` // Provider protocol, the cooldown key
+	Healthy   bool                     `json:"healthy"`
+	Method    string                   `json:"method,omitempty"`
+	LatencyMS int64                    `json:"latency_ms"`
+	Error     string                   `json:"error,omitempty"`
+	Reason    providers.FailoverReason `json:"reason,omitempty"`
+	Failures  int                      `json:"consecutive_failures,omitempty"`
+	CheckedAt time.Time                `json:"checked_at"`
+}
+
+// CooldownRecorder receives health check outcomes so failover skips
+// providers that are down. *providers.CooldownTracker implements it.
+type CooldownRecorder interface {
+	MarkFailure(provider string, reason providers.FailoverReason)
+	MarkSuccess(provider string)
+}
+
+type providerTarget struct {
+	model    string
+	provider string
+	modelID  string
+	llm      providers.LLMProvider
+	err      error // Set when the provider could not be created
+}
+
+// ProviderChecker periodically probes every model in model_list and keeps
+// the latest status of each.
+type ProviderChecker struct {
+	targets    []providerTarget
+	enabled    bool
+	interval   time.Duration
+	timeout    time.Duration
+	statusPath string
+
+	mu       sync.RWMutex
+	statuses map[string]ProviderStatus
+	bus      *bus.MessageBus
+	state    *state.Manager
+	cooldown CooldownRecorder
+	onChange func(ProviderStatus)
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewProviderChecker creates a checker for the models in cfg.ModelList.
+// stateMgr supplies the last active channel for notifications and may be nil.
+func NewProviderChecker(cfg *config.Config, stateMgr *state.Manager) *ProviderChecker {
+	c := &ProviderChecker{
+		enabled:    cfg.ProviderHealth.Enabled,
+		interval:   time.Duration(cfg.ProviderHealth.Interval) * time.Second,
+		timeout:    time.Duration(cfg.ProviderHealth.Timeout) * time.Second,
+		statusPath: ProviderStatusPath(cfg.WorkspacePath()),
+		statuses:   make(map[string]ProviderStatus),
+		state:      stateMgr,
+	}
+	if c.interval <= 0 {
+		c.interval = defaultProbeInterval
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultProbeTimeout
+	}
+
+	for i := range cfg.ModelList {
+		mc := &cfg.ModelList[i]
+		protocol, _ := providers.ExtractProtocol(mc.Model)
+		target := providerTarget{model: mc.ModelName, provider: providers.NormalizeProvider(protocol)}
+		target.llm, target.modelID, target.err = providers.CreateProviderFromConfig(mc)
+		c.targets = append(c.targets, target)
+	}
+	return c
+}
+
+// ProviderStatusPath is where the checker saves its latest results for
+// `picoclaw config status`.
+func ProviderStatusPath(workspace string) string {
+	return filepath.Join(workspace, "state", "provider_health.json")
+}
+
+// LoadProviderStatuses reads the results saved by the last health check.
+func LoadProviderStatuses(workspace string) ([]ProviderStatus, error) {
+	data, err := os.ReadFile(ProviderStatusPath(workspace))
+	if err != nil {
+		return nil, err
+	}
+	var statuses []ProviderStatus
+	if err := json.Unmarshal(data, &statuses); err != nil {
+		return nil, fmt.Errorf("failed to parse provider health: %w", err)
+	}
+	return statuses, nil
+}
+
+// SetBus sets the message bus degradation and recovery notices go to.
+func (c *ProviderChecker) SetBus(msgBus *bus.MessageBus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bus = msgBus
+}
+
+// SetCooldown feeds check outcomes into failover: failed checks put the
+// provider in cooldown and a recovery clears it.
+func (c *ProviderChecker) SetCooldown(cooldown CooldownRecorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cooldown = cooldown
+}
+
+// SetOnChange registers fn to be called when a model is first checked and
+// whenever it turns healthy or unhealthy.
+func (c *ProviderChecker) SetOnChange(fn func(ProviderStatus)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// Start checks every provider now and then every interval until Stop.
+func (c *ProviderChecker) Start(ctx context.Context) error {
+	if !c.enabled || len(c.targets) == 0 {
+		logger.InfoC("health", "Provider health checks disabled or no models configured")
+		return nil
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			c.CheckAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logger.InfoCF("health", "Provider health checks started", map[string]any{
+		"models":   len(c.targets),
+		"interval": c.interval.String(),
+	})
+	return nil
+}
+
+// Stop ends periodic checks and releases provider resources.
+func (c *ProviderChecker) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	for _, target := range c.targets {
+		if sp, ok := target.llm.(providers.StatefulProvider); ok {
+			sp.Close()
+		}
+	}
+}
+
+// CheckAll probes every model concurrently, records the results and saves
+// them for `picoclaw config status`. It returns the results sorted by model.
+func (c *ProviderChecker) CheckAll(ctx context.Context) []ProviderStatus {
+	results := make([]ProviderStatus, len(c.targets))
+	var wg sync.WaitGroup
+	for i, target := range c.targets {
+		wg.Add(1)
+		go func(i int, target providerTarget) {
+			defer wg.Done()
+			results[i] = c.probe(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		// Shutting down: cancelled probes say nothing about the providers
+		return c.Statuses()
+	}
+	for _, status := range results {
+		c.record(status)
+	}
+
+	statuses := c.Statuses()
+	if err := c.save(statuses); err != nil {
+		logger.WarnCF("health", "Failed to save provider health", map[string]any{"error": err.Error()})
+	}
+	return statuses
+}
+
+// Statuses returns the latest result for each model, sorted by model name.
+func (c *ProviderChecker) Statuses() []ProviderStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	statuses := make([]ProviderStatus, 0, len(c.statuses))
+	for _, status := range c.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Model < statuses[j].Model })
+	return statuses
+}
+
+// probe checks one model, listing models when the provider supports it and
+// sending a 1-token request otherwise
+func (c *ProviderChecker) probe(ctx context.Context, target providerTarget) ProviderStatus {
+	status := ProviderStatus{Model: target.model, Provider: target.provider}
+	if target.err != nil {
+		status.Error = target.err.Error()
+		status.Reason = providers.FailoverAuth
+		status.CheckedAt = time.Now()
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	if prober, ok := target.llm.(providers.HealthProber); ok {
+		status.Method = ProbeModels
+		err = prober.Probe(ctx)
+	} else {
+		status.Method = ProbeChat
+		_, err = target.llm.Chat(ctx, []providers.Message{{Role: "user", Content: "ping"}}, nil, target.modelID, map[string]any{
+			"max_tokens":  1,
+			"temperature": 0.0,
+		})
+	}
+	status.LatencyMS = time.Since(start).Milliseconds()
+	status.CheckedAt = time.Now()
+
+	if err == nil {
+		status.Healthy = true
+		return status
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = context.DeadlineExceeded
+	}
+	status.Error = err.Error()
+	status.Reason = providers.FailoverUnknown
+	if classified := providers.ClassifyError(err, target.provider, target.modelID); classified != nil {
+		status.Reason = classified.Reason
+	}
+	return status
+}
+
+// record stores a result, feeds the cooldown tracker and announces changes
+func (c *ProviderChecker) record(status ProviderStatus) {
+	c.mu.Lock()
+	prev, known := c.statuses[status.Model]
+	if !status.Healthy {
+		status.Failures = prev.Failures + 1
+	}
+	c.statuses[status.Model] = status
+	cooldown, onChange := c.cooldown, c.onChange
+	c.mu.Unlock()
+
+	if cooldown != nil {
+		if !status.Healthy {
+			cooldown.MarkFailure(status.Provider, status.Reason)
+		} else if known && !prev.Healthy {
+			cooldown.MarkSuccess(status.Provider)
+		}
+	}
+
+	if known && prev.Healthy == status.Healthy {
+		return
+	}
+	if onChange != nil {
+		onChange(status)
+	}
+
+	fields := map[string]any{
+		"model":    status.Model,
+		"provider": status.Provider,
+	}
+	switch {
+	case !status.Healthy:
+		fields["error"] = status.Error
+		fields["reason"] = status.Reason
+		logger.WarnCF("health", "Provider unhealthy", fields)
+		c.notify(fmt.Sprintf("⚠️ Provider degraded: %s (%s) failed its health check: %s",
+			status.Model, status.Provider, strings.Join(strings.Fields(status.Error), " ")))
+	case known:
+		logger.InfoCF("health", "Provider recovered", fields)
+		c.notify(fmt.Sprintf("✅ Provider recovered: %s (%s)", status.Model, status.Provider))
+	}
+}
+
+// notify sends a message to the last active channel, like heartbeat results
+func (c *ProviderChecker) notify(content string) {
+	c.mu.RLock()
+	msgBus, stateMgr := c.bus, c.state
+	c.mu.RUnlock()
+	if msgBus == nil || stateMgr == nil {
+		return
+	}
+
+	platform, chatID, ok := strings.Cut(stateMgr.GetLastChannel(), ":")
+	if !ok || platform == "" || chatID == "" || constants.IsInternalChannel(platform) {
+		return
+	}
+	msgBus.PublishOutbound(bus.OutboundMessage{
+		Channel: platform,
+		ChatID:  chatID,
+		Content: content,
+	})
+}
+
+func (c *ProviderChecker) save(statuses []ProviderStatus) error {
+	if err := os.MkdirAll(filepath.Dir(c.statusPath), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.statusPath, data, 0o644)
+}
@@ -0,0 +1,176 @@
+package llmjson
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Lenient rewrites JSON5-style text, as models often produce for tool call
+// arguments, into strict JSON: single-quoted strings, unquoted keys,
+// trailing commas, comments and Python or JavaScript literals (True, None,
+// undefined). Values wrapped in a code fence or cut off at the end are
+// recovered as by Extract. It reports whether the result is valid JSON.
+func Lenient(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if json.Valid([]byte(s)) {
+		return s, true
+	}
+
+	normalized := normalizeJSON5(s)
+	if json.Valid([]byte(normalized)) {
+		return normalized, true
+	}
+	if repaired, ok := Repair(normalized); ok {
+		return repaired, true
+	}
+	if candidate := Extract(normalized); candidate != "" {
+		return candidate, true
+	}
+	return "", false
+}
+
+// json5Literals maps bare identifiers to their JSON equivalents
+var json5Literals = map[string]string{
+	"true":      "true",
+	"True":      "true",
+	"false":     "false",
+	"False":     "false",
+	"null":      "null",
+	"None":      "null",
+	"undefined": "null",
+	"NaN":       "null",
+	"Infinity":  "null",
+}
+
+func normalizeJSON5(s string) string {
+	var out strings.Builder
+	out.Grow(len(s) + 16)
+
+	for i := 0; i < len(s); {
+		ch := s[i]
+		switch {
+		case ch == '"':
+			end, _ := stringEnd(s, i, '"')
+			out.WriteString(s[i:end])
+			i = end
+
+		case ch == '\'':
+			end, closed := stringEnd(s, i, '\'')
+			if closed {
+				out.WriteString(requote(s[i+1:end-1], true))
+			} else {
+				out.WriteString(requote(s[i+1:], false))
+			}
+			i = end
+
+		case ch == '/' && i+1 < len(s) && (s[i+1] == '/' || s[i+1] == '*'):
+			i = commentEnd(s, i)
+
+		case ch == ',':
+			if next := skipSpaceAndComments(s, i+1); next < len(s) && (s[next] == '}' || s[next] == ']') {
+				i++ // Trailing comma
+				continue
+			}
+			out.WriteByte(ch)
+			i++
+
+		case isIdentStart(ch):
+			j := i + 1
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			word := s[i:j]
+			if next := skipSpaceAndComments(s, j); next < len(s) && s[next] == ':' {
+				out.WriteString(`"` + word + `"`)
+			} else if literal, ok := json5Literals[word]; ok {
+				out.WriteString(literal)
+			} else {
+				out.WriteString(word)
+			}
+			i = j
+
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// stringEnd returns the index just past the string opening at s[start] with
+// quote and whether it was closed; an unclosed string runs to len(s)
+func stringEnd(s string, start int, quote byte) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// requote turns the body of a single-quoted string into a JSON string. An
+// unclosed string stays open so Repair can close it.
+func requote(body string, closed bool) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(body); i++ {
+		switch ch := body[i]; {
+		case ch == '\\' && i+1 < len(body) && body[i+1] == '\'':
+			sb.WriteByte('\'')
+			i++
+		case ch == '\\' && i+1 < len(body):
+			sb.WriteByte(ch)
+			sb.WriteByte(body[i+1])
+			i++
+		case ch == '"':
+			sb.WriteString(`\"`)
+		case ch == '\n':
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	if closed {
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// commentEnd returns the index just past the comment starting at s[start]
+func commentEnd(s string, start int) int {
+	if s[start+1] == '/' {
+		if nl := strings.IndexByte(s[start:], '\n'); nl != -1 {
+			return start + nl
+		}
+		return len(s)
+	}
+	if end := strings.Index(s[start+2:], "*/"); end != -1 {
+		return start + 2 + end + 2
+	}
+	return len(s)
+}
+
+func skipSpaceAndComments(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r':
+			i++
+		case s[i] == '/' && i+1 < len(s) && (s[i+1] == '/' || s[i+1] == '*'):
+			i = commentEnd(s, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentPart(ch byte) bool {
+	return isIdentStart(ch) || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,307 @@
+// Package llmjson pulls JSON out of free-form model output. Models wrap JSON
+// in markdown fences, put commentary before and after it, emit several
+// objects in one reply, or stop mid-object when they hit max_tokens. Every
+// function here tolerates all of that, never panics, and only returns text
+// that is valid JSON.
+package llmjson
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// maxFailedStarts bounds how many unparseable '{' or '[' positions are tried
+// per text, so pathological input (a wall of braces) stays linear-ish.
+const maxFailedStarts = 64
+
+// maxRepairCuts bounds how far Repair backs up through a truncated value.
+const maxRepairCuts = 32
+
+// ErrNoJSON is returned by Decode when text contains no JSON value.
+var ErrNoJSON = errors.New("no JSON found in model output")
+
+// fencePattern matches ``` code fences, with or without a language tag.
+var fencePattern = regexp.MustCompile("(?s)```[a-zA-Z0-9_-]*[ \t]*\n?(.*?)```")
+
+// Candidates returns every top-level JSON object or array in text, in order
+// of preference: the contents of code fences first, then the rest of the
+// text. Values inside a larger value are not returned separately. A value
+// cut off at the end of text is repaired when possible. Duplicates are
+// dropped.
+func Candidates(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(values []string) {
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+
+	for _, match := range fencePattern.FindAllStringSubmatch(text, -1) {
+		add(scan(match[1]))
+	}
+	add(scan(text))
+	return out
+}
+
+// Extract returns the first JSON object or array in text, or "" if there
+// is none.
+func Extract(text string) string {
+	if candidates := Candidates(text); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// ExtractObject returns the first JSON object in text, or "" if there is
+// none.
+func ExtractObject(text string) string {
+	for _, candidate := range Candidates(text) {
+		if candidate[0] == '{' {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Decode unmarshals the first JSON value in text that decodes into v
+// without error. It returns the error from the last attempt, or
+// ErrNoJSON when text holds no JSON at all.
+func Decode(text string, v any) error {
+	candidates := Candidates(text)
+	if len(candidates) == 0 {
+		return ErrNoJSON
+	}
+	var err error
+	for _, candidate := range candidates {
+		if err = json.Unmarshal([]byte(candidate), v); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// End returns the index just past the value that opens at s[start] (a '{'
+// or '['), or -1 when it is never closed. Brackets inside strings are
+// ignored. It does not check that the value is valid JSON.
+func End(s string, start int) int {
+	if start < 0 || start >= len(s) || (s[start] != '{' && s[start] != '[') {
+		return -1
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// Balanced returns the first complete, valid JSON object in s, or "" if
+// there is none. Unlike ExtractObject it ignores code fences and does not
+// repair truncated values, so it suits text where the JSON position is
+// already known, such as after a tool-call tag.
+func Balanced(s string) string {
+	failed := 0
+	for i := 0; i < len(s) && failed < maxFailedStarts; i++ {
+		if s[i] != '{' {
+			continue
+		}
+		end := End(s, i)
+		if end == -1 {
+			failed++
+			continue
+		}
+		if json.Valid([]byte(s[i:end])) {
+			return s[i:end]
+		}
+		failed++
+	}
+	return ""
+}
+
+// Repair closes a JSON value that was cut off, as happens when a model runs
+// out of tokens mid-object. It closes an open string, drops a dangling
+// comma or key and appends the missing closers, backing up to the previous
+// element when that is not enough. It reports whether the result is valid
+// JSON. Members that were cut off may be lost.
+func Repair(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '{' && s[0] != '[') {
+		return "", false
+	}
+	if json.Valid([]byte(s)) {
+		return s, true
+	}
+
+	// Structural commas and nested openers are the points we can back up
+	// to. Backing up to the outermost opener would always "succeed" with an
+	// empty value, which says nothing about the input, so it isn't one.
+	cuts := cutPoints(s)
+	if len(cuts) > 0 && cuts[0] == 1 {
+		cuts = cuts[1:]
+	}
+	prefix := s
+	for attempt := 0; attempt <= maxRepairCuts; attempt++ {
+		if closed, ok := closeValue(prefix); ok {
+			return closed, true
+		}
+		if len(cuts) == 0 {
+			break
+		}
+		prefix = s[:cuts[len(cuts)-1]]
+		cuts = cuts[:len(cuts)-1]
+	}
+	return "", false
+}
+
+// scan finds top-level JSON values in s, left to right
+func scan(s string) []string {
+	var out []string
+	failed := 0
+	triedRepair := false
+	for i := 0; i < len(s) && failed < maxFailedStarts; i++ {
+		if s[i] != '{' && s[i] != '[' {
+			continue
+		}
+		end := End(s, i)
+		if end == -1 {
+			// Cut off at the end of the text: salvage what is there. Only
+			// the first unclosed value is worth repairing; later openers
+			// are nested inside it.
+			if !triedRepair {
+				triedRepair = true
+				if repaired, ok := Repair(s[i:]); ok {
+					return append(out, repaired)
+				}
+			}
+			failed++
+			continue
+		}
+		if json.Valid([]byte(s[i:end])) {
+			out = append(out, s[i:end])
+			i = end - 1
+			continue
+		}
+		failed++
+	}
+	return out
+}
+
+// cutPoints returns the offsets of structural commas and of the positions
+// just after openers (outside strings), in order
+func cutPoints(s string) []int {
+	var cuts []int
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case ',':
+			cuts = append(cuts, i)
+		case '{', '[':
+			cuts = append(cuts, i+1)
+		}
+	}
+	return cuts
+}
+
+// closeValue terminates a truncated prefix and reports whether the result
+// is valid JSON
+func closeValue(prefix string) (string, bool) {
+	var closers []byte
+	inString := false
+	escaped := false
+	for i := 0; i < len(prefix); i++ {
+		ch := prefix[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			closers = append(closers, '}')
+		case '[':
+			closers = append(closers, ']')
+		case '}', ']':
+			if len(closers) == 0 || closers[len(closers)-1] != ch {
+				return "", false
+			}
+			closers = closers[:len(closers)-1]
+		}
+	}
+
+	out := prefix
+	if inString {
+		if escaped {
+			// Drop a dangling backslash so the closing quote isn't escaped
+			out = out[:len(out)-1]
+		}
+		out += `"`
+	}
+
+	out = strings.TrimRight(out, " \t\r\n")
+	out = strings.TrimSuffix(out, ",")
+	if strings.HasSuffix(out, ":") {
+		out += "null"
+	}
+	buf := make([]byte, 0, len(out)+len(closers))
+	buf = append(buf, out...)
+	for i := len(closers) - 1; i >= 0; i-- {
+		buf = append(buf, closers[i])
+	}
+	if !json.Valid(buf) {
+		return "", false
+	}
+	return string(buf), true
+}
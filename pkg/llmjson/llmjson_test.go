@@ -0,0 +1,219 @@
+package llmjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"bare object", `{"a":1}`, `{"a":1}`},
+		{"bare array", `[1,2]`, `[1,2]`},
+		{"json fence", "Here you go:\n```json\n{\"a\":1}\n```\nDone.", `{"a":1}`},
+		{"plain fence", "```\n{\"a\":1}\n```", `{"a":1}`},
+		{"fence preferred over prose", "I considered {\"x\":0} but\n```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"leading and trailing commentary", `Sure! {"a":"b"} Hope that helps.`, `{"a":"b"}`},
+		{"braces in strings", `{"a":"b{c}d"} trailing }`, `{"a":"b{c}d"}`},
+		{"escaped quotes", `x {"a":"say \"hi\" {"} y`, `{"a":"say \"hi\" {"}`},
+		{"skips invalid first", `{not json} {"a":1}`, `{"a":1}`},
+		{"truncated object", `Result: {"approved": true, "reason": "looks go`, `{"approved": true, "reason": "looks go"}`},
+		{"truncated after key", `{"a":1,"b":`, `{"a":1,"b":null}`},
+		{"truncated mid key", `{"a":1,"b`, `{"a":1}`},
+		{"truncated nested", `{"a":[1,2,{"b":`, `{"a":[1,2,{"b":null}]}`},
+		{"none", `no json here`, ``},
+		{"unclosed garbage", `{{{{`, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.text); got != tt.want {
+				t.Errorf("Extract(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCandidates_MultipleObjects(t *testing.T) {
+	text := `First {"step":1} then {"step":2} and [3] with {"nested":{"x":1}}`
+	got := Candidates(text)
+	want := []string{`{"step":1}`, `{"step":2}`, `[3]`, `{"nested":{"x":1}}`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Candidates = %v, want %v", got, want)
+	}
+
+	// The same value fenced and in prose is only returned once
+	fenced := "```json\n{\"a\":1}\n```"
+	if got := Candidates(fenced); len(got) != 1 {
+		t.Errorf("Candidates(fenced) = %v, want one value", got)
+	}
+}
+
+func TestExtractObject(t *testing.T) {
+	if got := ExtractObject(`[1,2] then {"a":1}`); got != `{"a":1}` {
+		t.Errorf("ExtractObject = %q, want the object", got)
+	}
+	if got := ExtractObject(`[1,2]`); got != "" {
+		t.Errorf("ExtractObject = %q, want empty", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var out struct {
+		Approved bool `json:"approved"`
+	}
+	// The array can't decode into the struct, so Decode moves on
+	if err := Decode(`[1] {"approved":true}`, &out); err != nil || !out.Approved {
+		t.Errorf("Decode = %v, %+v", err, out)
+	}
+	if err := Decode("nothing", &out); !errors.Is(err, ErrNoJSON) {
+		t.Errorf("Decode(nothing) error = %v, want ErrNoJSON", err)
+	}
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		s     string
+		start int
+		want  int
+	}{
+		{`{"a":1}`, 0, 7},
+		{`text {"a":1} more`, 5, 12},
+		{`{unclosed`, 0, -1},
+		{`{"a":"b{c}d"}`, 0, 13},
+		{`[1,[2]]`, 0, 7},
+		{`abc`, 0, -1},
+		{`{}`, 5, -1},
+	}
+	for _, tt := range tests {
+		if got := End(tt.s, tt.start); got != tt.want {
+			t.Errorf("End(%q, %d) = %d, want %d", tt.s, tt.start, got, tt.want)
+		}
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	if got := Balanced(`{"name":"x","arguments":{"a":1}}</tool_call>`); got != `{"name":"x","arguments":{"a":1}}` {
+		t.Errorf("Balanced = %q", got)
+	}
+	// Truncated values are not repaired
+	if got := Balanced(`{"name":"x"`); got != "" {
+		t.Errorf("Balanced(truncated) = %q, want empty", got)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{`{"a":1}`, `{"a":1}`, true},
+		{`{"a":1,`, `{"a":1}`, true},
+		{`{"a":"x\`, `{"a":"x"}`, true},
+		{`[1,2,`, `[1,2]`, true},
+		{`{"a":1,"b":tru`, `{"a":1}`, true},
+		{`{"a":tru`, ``, false},
+		{`not json`, ``, false},
+		{`{"a":1}}`, ``, false},
+	}
+	for _, tt := range tests {
+		got, ok := Repair(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Repair(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+var fuzzSeeds = []string{
+	`{"a":1}`,
+	"```json\n{\"approved\": false, \"confidence\": 0.9}\n```",
+	"Some text\n```\n[1,2,3]\n```\nmore text {\"b\":2}",
+	`<tool_call>{"name":"exec","arguments":{"cmd":"ls"}}</tool_call>`,
+	`{"a":"unterminated`,
+	`{"a":[{"b":{"c":`,
+	`{"x":"é\n"} {"y":` + "`" + `}`,
+	`}}}{{{[[[`,
+	`"just a string"`,
+	`{"a":"b\\"}`,
+}
+
+func FuzzExtract(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		for _, candidate := range Candidates(text) {
+			if !json.Valid([]byte(candidate)) {
+				t.Fatalf("Candidates(%q) returned invalid JSON %q", text, candidate)
+			}
+		}
+		if got := Extract(text); got != "" && !json.Valid([]byte(got)) {
+			t.Fatalf("Extract(%q) = invalid JSON %q", text, got)
+		}
+		if got := ExtractObject(text); got != "" && (!json.Valid([]byte(got)) || got[0] != '{') {
+			t.Fatalf("ExtractObject(%q) = %q, not an object", text, got)
+		}
+		if got := Balanced(text); got != "" && !strings.Contains(text, got) {
+			t.Fatalf("Balanced(%q) = %q, not a substring", text, got)
+		}
+	})
+}
+
+func FuzzRepair(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		got, ok := Repair(text)
+		if ok && !json.Valid([]byte(got)) {
+			t.Fatalf("Repair(%q) = %q reported ok but is invalid", text, got)
+		}
+		if !ok && got != "" {
+			t.Fatalf("Repair(%q) failed but returned %q", text, got)
+		}
+		// Valid JSON comes back as is
+		if trimmed := strings.TrimSpace(text); json.Valid([]byte(trimmed)) && ok && got != trimmed {
+			t.Fatalf("Repair(%q) = %q, want the input unchanged", text, got)
+		}
+	})
+}
+
+func TestLenient(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"already valid", `{"a":1}`, `{"a":1}`},
+		{"single quotes", `{'path': 'README.md'}`, `{"path": "README.md"}`},
+		{"single quotes with double quote inside", `{'q': 'say "hi"'}`, `{"q": "say \"hi\""}`},
+		{"escaped single quote", `{'q': 'it\'s'}`, `{"q": "it's"}`},
+		{"unquoted keys", `{command: "ls -la", timeout: 30}`, `{"command": "ls -la", "timeout": 30}`},
+		{"trailing commas", `{"a": [1, 2,], "b": 3,}`, `{"a": [1, 2], "b": 3}`},
+		{"comments", "{\"a\": 1, // the count\n \"b\": /* inline */ 2}", "{\"a\": 1, \n \"b\":  2}"},
+		{"python literals", `{'recursive': True, 'filter': None}`, `{"recursive": true, "filter": null}`},
+		{"strings untouched", `{"note": "True, // not a comment,}"}`, `{"note": "True, // not a comment,}"}`},
+		{"truncated", `{'url': 'https://example.com', 'depth': 2, 'note': 'cut of`, `{"url": "https://example.com", "depth": 2, "note": "cut of"}`},
+		{"fenced", "```json\n{target: '10.0.0.1',}\n```", `{"target": "10.0.0.1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lenient(tt.text)
+			if !ok {
+				t.Fatalf("Lenient(%q) failed", tt.text)
+			}
+			if got != tt.want {
+				t.Errorf("Lenient(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if got, ok := Lenient(`path=README.md`); ok {
+		t.Errorf("Lenient(not json) = %q, want failure", got)
+	}
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ProtocolVersion is the MCP protocol revision picoclaw offers in the
+// initialize handshake
+const ProtocolVersion = "2025-03-26"
+
+// SupportedProtocolVersions lists the revisions a server may answer with
+var SupportedProtocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// Features a server can declare. Nested capabilities are flattened with a
+// dot, e.g. {"tools": {"listChanged": true}} declares "tools" and
+// "tools.listChanged".
+const (
+	FeatureTools            = "tools"
+	FeatureToolsListChanged = "tools.listChanged"
+	FeatureResources        = "resources"
+	FeaturePrompts          = "prompts"
+	FeatureLogging          = "logging"
+)
+
+// ApproveAll in RequiredApprovals means every tool needs operator approval
+const ApproveAll = "*"
+
+// MismatchPolicy decides what happens to a server whose capabilities don't
+// match what picoclaw supports or the config requires
+type MismatchPolicy string
+
+const (
+	MismatchRefuse  MismatchPolicy = "refuse"  // Disconnect and register none of its tools
+	MismatchSandbox MismatchPolicy = "sandbox" // Keep it, but every tool call needs operator approval
+)
+
+// Capabilities is what a server declared during the handshake
+type Capabilities struct {
+	ProtocolVersion   string   `json:"protocol_version"`
+	Features          []string `json:"features,omitempty"`
+	RequiredApprovals []string `json:"required_approvals,omitempty"` // Tools that need operator approval before each call
+	ServerName        string   `json:"server_name,omitempty"`
+	ServerVersion     string   `json:"server_version,omitempty"`
+}
+
+// HasFeature reports whether the server declared feature
+func (c *Capabilities) HasFeature(feature string) bool {
+	return c != nil && slices.Contains(c.Features, feature)
+}
+
+// RequiresApproval reports whether the server asked for operator approval
+// before tool runs
+func (c *Capabilities) RequiresApproval(tool string) bool {
+	if c == nil {
+		return false
+	}
+	return slices.Contains(c.RequiredApprovals, ApproveAll) || slices.Contains(c.RequiredApprovals, tool)
+}
+
+// Negotiator is implemented by connections that support the capability
+// handshake. Negotiate returns nil capabilities if the server doesn't
+// implement the handshake at all.
+type Negotiator interface {
+	Negotiate(ctx context.Context) (*Capabilities, error)
+}
+
+// Negotiation is the outcome of the handshake with one server
+type Negotiation struct {
+	Capabilities *Capabilities // nil if the server predates the handshake
+	Problems     []string      // Why the server doesn't match, empty if it does
+	Sandboxed    bool          // Every tool call needs operator approval
+}
+
+// NeedsApproval reports whether calling tool needs operator approval
+func (n *Negotiation) NeedsApproval(tool string) bool {
+	return n != nil && (n.Sandboxed || n.Capabilities.RequiresApproval(tool))
+}
+
+// CheckCapabilities lists the ways caps falls short of what picoclaw
+// supports and config requires. A server that predates the handshake (nil
+// caps) is accepted as it always was, unless the config requires features
+// it can't have declared.
+func CheckCapabilities(caps *Capabilities, config *MCPServerConfig) []string {
+	if caps == nil {
+		if len(config.RequiredFeatures) == 0 {
+			return nil
+		}
+		return []string{"server did not declare its capabilities"}
+	}
+
+	var problems []string
+	if !slices.Contains(SupportedProtocolVersions, caps.ProtocolVersion) {
+		problems = append(problems, fmt.Sprintf("unsupported protocol version %q (supported: %v)",
+			caps.ProtocolVersion, SupportedProtocolVersions))
+	}
+	if !caps.HasFeature(FeatureTools) {
+		problems = append(problems, "server does not provide tools")
+	}
+	for _, feature := range config.RequiredFeatures {
+		if !caps.HasFeature(feature) {
+			problems = append(problems, fmt.Sprintf("missing required feature %q", feature))
+		}
+	}
+	return problems
+}
+
+// parseInitializeResult reads the result of an MCP initialize request
+func parseInitializeResult(result map[string]any) *Capabilities {
+	caps := &Capabilities{
+		ProtocolVersion: getString(result, "protocolVersion"),
+	}
+
+	if declared, ok := result["capabilities"].(map[string]any); ok {
+		for name, value := range declared {
+			if name == "experimental" {
+				continue
+			}
+			caps.Features = append(caps.Features, name)
+			if sub, ok := value.(map[string]any); ok {
+				for subName, enabled := range sub {
+					if b, ok := enabled.(bool); ok && b {
+						caps.Features = append(caps.Features, name+"."+subName)
+					}
+				}
+			}
+		}
+		sort.Strings(caps.Features)
+
+		if experimental, ok := declared["experimental"].(map[string]any); ok {
+			if approvals, ok := experimental["requiredApprovals"].([]any); ok {
+				for _, a := range approvals {
+					if tool, ok := a.(string); ok {
+						caps.RequiredApprovals = append(caps.RequiredApprovals, tool)
+					}
+				}
+			}
+		}
+	}
+
+	if info, ok := result["serverInfo"].(map[string]any); ok {
+		caps.ServerName = getString(info, "name")
+		caps.ServerVersion = getString(info, "version")
+	}
+	return caps
+}
+
+// initializeParams is the client half of the handshake
+func initializeParams() map[string]any {
+	return map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo": map[string]any{
+			"name": "picoclaw",
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseInitializeResult(t *testing.T) {
+	var result map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"protocolVersion": "2025-03-26",
+		"capabilities": {
+			"tools": {"listChanged": true},
+			"logging": {},
+			"resources": {"subscribe": false},
+			"experimental": {"requiredApprovals": ["delete_host", 7]}
+		},
+		"serverInfo": {"name": "recon-mcp", "version": "1.2.0"}
+	}`), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	caps := parseInitializeResult(result)
+	if caps.ProtocolVersion != "2025-03-26" || caps.ServerName != "recon-mcp" || caps.ServerVersion != "1.2.0" {
+		t.Errorf("caps = %+v", caps)
+	}
+	if got := strings.Join(caps.Features, ","); got != "logging,resources,tools,tools.listChanged" {
+		t.Errorf("Features = %s", got)
+	}
+	if !caps.RequiresApproval("delete_host") || caps.RequiresApproval("list_hosts") {
+		t.Errorf("RequiredApprovals = %v", caps.RequiredApprovals)
+	}
+
+	if caps := parseInitializeResult(map[string]any{}); caps.ProtocolVersion != "" || len(caps.Features) != 0 {
+		t.Errorf("empty result = %+v", caps)
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	good := &Capabilities{ProtocolVersion: "2024-11-05", Features: []string{"tools", "tools.listChanged"}}
+
+	tests := []struct {
+		name     string
+		caps     *Capabilities
+		required []string
+		want     string
+	}{
+		{"compatible", good, nil, ""},
+		{"required feature present", good, []string{"tools.listChanged"}, ""},
+		{"legacy server", nil, nil, ""},
+		{"legacy server with requirements", nil, []string{"tools"}, "server did not declare its capabilities"},
+		{"old protocol", &Capabilities{ProtocolVersion: "2023-01-01", Features: []string{"tools"}}, nil,
+			`unsupported protocol version "2023-01-01" (supported: [2025-03-26 2024-11-05])`},
+		{"no tools", &Capabilities{ProtocolVersion: ProtocolVersion}, nil, "server does not provide tools"},
+		{"missing required feature", good, []string{"resources"}, `missing required feature "resources"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := CheckCapabilities(tt.caps, &MCPServerConfig{RequiredFeatures: tt.required})
+			if got := strings.Join(problems, "; "); got != tt.want {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiation_NeedsApproval(t *testing.T) {
+	var none *Negotiation
+	if none.NeedsApproval("scan") {
+		t.Error("nil negotiation needs approval")
+	}
+	declared := &Negotiation{Capabilities: &Capabilities{RequiredApprovals: []string{"exploit"}}}
+	if !declared.NeedsApproval("exploit") || declared.NeedsApproval("scan") {
+		t.Error("declared approvals not applied")
+	}
+	if sandboxed := (&Negotiation{Sandboxed: true}); !sandboxed.NeedsApproval("scan") {
+		t.Error("sandboxed server's tool runs without approval")
+	}
+}
@@ -74,6 +74,46 @@ func (h *HTTPConnection) ListTools(ctx context.Context) ([]MCPToolDefinition, er
 	return result.Tools, nil
 }
 
+// Negotiate fetches the server's declared capabilities. Servers without a
+// /capabilities endpoint predate the handshake and declare nothing.
+func (h *HTTPConnection) Negotiate(ctx context.Context) (*Capabilities, error) {
+	data, err := json.Marshal(initializeParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.url+"/capabilities", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to negotiate capabilities: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return parseInitializeResult(result), nil
+}
+
 func (h *HTTPConnection) CallTool(ctx context.Context, name string, args map[string]any) ([]byte, error) {
 	payload := map[string]any{
 		"tool": name,
@@ -2,12 +2,18 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools/filters"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 )
 
 // TransportType defines the MCP connection transport
@@ -31,8 +37,20 @@ type MCPServerConfig struct {
 	APIKey     string            `json:"api_key,omitempty"`    // Authentication
 	AutoStart  bool              `json:"auto_start,omitempty"` // Launch if not running
 	ProjectDir string            `json:"project_dir,omitempty"` // Working directory
+
+	// Capability negotiation
+	RequiredFeatures []string       `json:"required_features,omitempty"` // Features the server must declare, e.g. "tools.listChanged"
+	OnMismatch       MismatchPolicy `json:"on_mismatch,omitempty"`       // "refuse" (default) or "sandbox"
 }
 
+// negotiateTimeout bounds the capability handshake, so a server that never
+// answers doesn't hang the connect
+const negotiateTimeout = 30 * time.Second
+
+// ErrIncompatible is returned when a server's declared capabilities don't
+// match and its policy is to refuse it
+var ErrIncompatible = errors.New("incompatible MCP server")
+
 // MCPConnection represents an active connection to an MCP server
 type MCPConnection interface {
 	// ListTools returns available tools from this MCP server
@@ -60,7 +78,10 @@ type MCPToolDefinition struct {
 type MCPManager struct {
 	configs        map[string]*MCPServerConfig
 	connections    map[string]MCPConnection
+	negotiations   map[string]*Negotiation
 	filterRegistry *filters.FilterRegistry
+	approver       tools.OperatorAsker
+	trust          *trust.Policy
 	mu             sync.RWMutex
 }
 
@@ -69,6 +90,7 @@ func NewMCPManager(filterRegistry *filters.FilterRegistry) *MCPManager {
 	return &MCPManager{
 		configs:        make(map[string]*MCPServerConfig),
 		connections:    make(map[string]MCPConnection),
+		negotiations:   make(map[string]*Negotiation),
 		filterRegistry: filterRegistry,
 	}
 }
@@ -116,6 +138,10 @@ func (m *MCPManager) ConnectServer(ctx context.Context, name string) error {
 		return nil
 	}
 
+	if err := m.checkTrust(config); err != nil {
+		return err
+	}
+
 	// Auto-start if configured
 	if config.AutoStart {
 		if err := m.autoStartServer(ctx, config); err != nil {
@@ -146,17 +172,100 @@ func (m *MCPManager) ConnectServer(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to connect to %s: %w", name, err)
 	}
 
+	negotiation, err := m.negotiate(ctx, config, conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
 	m.connections[name] = conn
+	m.negotiations[name] = negotiation
 
-	logger.InfoCF("mcp", "Connected to MCP server",
-		map[string]any{
-			"server":    name,
-			"transport": config.Transport,
-		})
+	fields := map[string]any{
+		"server":    name,
+		"transport": config.Transport,
+		"sandboxed": negotiation.Sandboxed,
+	}
+	if negotiation.Capabilities != nil {
+		fields["protocol_version"] = negotiation.Capabilities.ProtocolVersion
+	}
+	logger.InfoCF("mcp", "Connected to MCP server", fields)
 
 	return nil
 }
 
+// SetTrustPolicy makes connects check the signature of stdio server
+// binaries (binary.minisig next to the binary) against the policy's plugins
+// mode
+func (m *MCPManager) SetTrustPolicy(policy *trust.Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trust = policy
+}
+
+// checkTrust applies the plugins trust policy before a server is started.
+// Only the binary is verified: scripts or packages it loads (npx, python)
+// are not. HTTP and SSE servers have no artifact to verify, so they count
+// as unsigned.
+func (m *MCPManager) checkTrust(config *MCPServerConfig) error {
+	if m.trust.Mode(trust.KindPlugin) == trust.ModeOff {
+		return nil
+	}
+	var content, sig []byte
+	if config.Transport == TransportStdio && config.Binary != "" {
+		path, err := exec.LookPath(config.Binary)
+		if err != nil {
+			return fmt.Errorf("failed to find binary of %s: %w", config.Name, err)
+		}
+		if content, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("failed to read binary of %s: %w", config.Name, err)
+		}
+		if sig, err = os.ReadFile(path + trust.SignatureExt); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read signature of %s: %w", config.Name, err)
+		}
+	}
+	_, err := m.trust.Check(trust.KindPlugin, config.Name, content, sig)
+	return err
+}
+
+// negotiate runs the capability handshake and applies the server's mismatch
+// policy, so incompatible servers are refused before any tool is called
+func (m *MCPManager) negotiate(ctx context.Context, config *MCPServerConfig, conn MCPConnection) (*Negotiation, error) {
+	negotiation := &Negotiation{}
+	if negotiator, ok := conn.(Negotiator); ok {
+		ctx, cancel := context.WithTimeout(ctx, negotiateTimeout)
+		defer cancel()
+		caps, err := negotiator.Negotiate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("capability negotiation with %s failed: %w", config.Name, err)
+		}
+		negotiation.Capabilities = caps
+	}
+	if negotiation.Capabilities == nil {
+		logger.InfoCF("mcp", "MCP server predates the capability handshake",
+			map[string]any{
+				"server": config.Name,
+			})
+	}
+
+	negotiation.Problems = CheckCapabilities(negotiation.Capabilities, config)
+	if len(negotiation.Problems) == 0 {
+		return negotiation, nil
+	}
+
+	if config.OnMismatch != MismatchSandbox {
+		return nil, fmt.Errorf("%w %s: %s", ErrIncompatible, config.Name, strings.Join(negotiation.Problems, "; "))
+	}
+
+	negotiation.Sandboxed = true
+	logger.WarnCF("mcp", "MCP server capabilities don't match, sandboxing its tools",
+		map[string]any{
+			"server":   config.Name,
+			"problems": negotiation.Problems,
+		})
+	return negotiation, nil
+}
+
 // DisconnectServer closes the connection to an MCP server
 func (m *MCPManager) DisconnectServer(name string) error {
 	m.mu.Lock()
@@ -172,6 +281,7 @@ func (m *MCPManager) DisconnectServer(name string) error {
 	}
 
 	delete(m.connections, name)
+	delete(m.negotiations, name)
 
 	logger.InfoCF("mcp", "Disconnected from MCP server",
 		map[string]any{
@@ -232,12 +342,20 @@ func (m *MCPManager) DisconnectAll() error {
 func (m *MCPManager) CallTool(ctx context.Context, server, tool string, args map[string]any) (*tools.ToolResult, error) {
 	m.mu.RLock()
 	conn, exists := m.connections[server]
+	negotiation := m.negotiations[server]
+	approver := m.approver
 	m.mu.RUnlock()
 
 	if !exists {
 		return nil, fmt.Errorf("not connected to server %q", server)
 	}
 
+	if negotiation.NeedsApproval(tool) {
+		if denied := askApproval(ctx, approver, server, tool, negotiation); denied != nil {
+			return denied, nil
+		}
+	}
+
 	logger.InfoCF("mcp", "Calling MCP tool",
 		map[string]any{
 			"server": server,
@@ -307,6 +425,52 @@ func (m *MCPManager) GetAllTools(ctx context.Context) ([]MCPToolDefinition, erro
 	return allTools, nil
 }
 
+// SetApprover sets who approves calls to sandboxed tools and tools the
+// server marked as needing approval. Without one those calls are refused.
+func (m *MCPManager) SetApprover(approver tools.OperatorAsker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approver = approver
+}
+
+// GetNegotiation returns the capability handshake result for a server
+func (m *MCPManager) GetNegotiation(name string) (*Negotiation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	negotiation, exists := m.negotiations[name]
+	return negotiation, exists
+}
+
+// askApproval asks the operator to allow a tool call. It returns the result
+// to hand back to the agent if the call may not go ahead.
+func askApproval(ctx context.Context, approver tools.OperatorAsker, server, tool string, negotiation *Negotiation) *tools.ToolResult {
+	if approver == nil {
+		return tools.ErrorResult(fmt.Sprintf("MCP tool %s on %s requires operator approval, but no operator is available", tool, server))
+	}
+
+	reason := "the server requires approval for this tool"
+	if negotiation.Sandboxed {
+		reason = "the server is sandboxed: " + strings.Join(negotiation.Problems, "; ")
+	}
+
+	question := tools.OperatorQuestion{
+		Question:   fmt.Sprintf("Allow MCP tool %s on server %s to run?", tool, server),
+		AnswerType: tools.AnswerYesNo,
+		Context:    reason,
+	}
+	answer, err := approver(ctx, question)
+	if err == nil {
+		answer, err = tools.NormalizeOperatorAnswer(question, answer)
+	}
+	if err != nil {
+		return tools.ErrorResult(fmt.Sprintf("MCP tool %s on %s was not approved: %v", tool, server, err))
+	}
+	if answer != "yes" {
+		return tools.ErrorResult(fmt.Sprintf("Operator denied MCP tool %s on %s", tool, server))
+	}
+	return nil
+}
+
 // GetConnection returns a connection to a specific server
 func (m *MCPManager) GetConnection(name string) (MCPConnection, bool) {
 	m.mu.RLock()
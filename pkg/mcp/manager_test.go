@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
+)
+
+// fakeConnection is an MCP server that declares caps and echoes tool calls
+type fakeConnection struct {
+	caps  *Capabilities
+	calls []string
+}
+
+func (f *fakeConnection) Negotiate(ctx context.Context) (*Capabilities, error) {
+	return f.caps, nil
+}
+
+func (f *fakeConnection) ListTools(ctx context.Context) ([]MCPToolDefinition, error) {
+	return []MCPToolDefinition{{Name: "scan"}}, nil
+}
+
+func (f *fakeConnection) CallTool(ctx context.Context, name string, args map[string]any) ([]byte, error) {
+	f.calls = append(f.calls, name)
+	return []byte("ran " + name), nil
+}
+
+func (f *fakeConnection) Close() error                       { return nil }
+func (f *fakeConnection) IsHealthy(ctx context.Context) bool { return true }
+
+func TestMCPManager_Negotiate(t *testing.T) {
+	m := NewMCPManager(nil)
+	ctx := context.Background()
+	incompatible := &Capabilities{ProtocolVersion: "2023-01-01", Features: []string{"tools"}}
+
+	negotiation, err := m.negotiate(ctx, &MCPServerConfig{Name: "legacy"}, &fakeConnection{})
+	if err != nil || negotiation.Sandboxed || negotiation.NeedsApproval("scan") {
+		t.Errorf("legacy server = %+v, %v; want accepted as is", negotiation, err)
+	}
+
+	_, err = m.negotiate(ctx, &MCPServerConfig{Name: "old"}, &fakeConnection{caps: incompatible})
+	if !errors.Is(err, ErrIncompatible) || !strings.Contains(err.Error(), "unsupported protocol version") {
+		t.Errorf("refused server error = %v", err)
+	}
+
+	negotiation, err = m.negotiate(ctx, &MCPServerConfig{Name: "old", OnMismatch: MismatchSandbox}, &fakeConnection{caps: incompatible})
+	if err != nil || !negotiation.Sandboxed || len(negotiation.Problems) != 1 {
+		t.Errorf("sandboxed server = %+v, %v", negotiation, err)
+	}
+}
+
+func TestMCPManager_CallToolApproval(t *testing.T) {
+	m := NewMCPManager(nil)
+	conn := &fakeConnection{}
+	m.connections["old"] = conn
+	m.negotiations["old"] = &Negotiation{Sandboxed: true, Problems: []string{"server does not provide tools"}}
+	ctx := context.Background()
+
+	// Without an operator, sandboxed calls are refused
+	result, err := m.CallTool(ctx, "old", "scan", nil)
+	if err != nil || !result.IsError || !strings.Contains(result.ForLLM, "no operator is available") {
+		t.Fatalf("unapproved call = %+v, %v", result, err)
+	}
+
+	var asked tools.OperatorQuestion
+	answer := "no"
+	wrapper := NewMCPToolWrapper(MCPToolDefinition{Name: "scan", Server: "old"}, m)
+	wrapper.SetAsker(func(ctx context.Context, q tools.OperatorQuestion) (string, error) {
+		asked = q
+		return answer, nil
+	})
+
+	result, _ = m.CallTool(ctx, "old", "scan", nil)
+	if !result.IsError || !strings.Contains(result.ForLLM, "Operator denied") {
+		t.Errorf("denied call = %+v", result)
+	}
+	if !strings.Contains(asked.Question, "scan on server old") || !strings.Contains(asked.Context, "sandboxed") {
+		t.Errorf("question = %+v", asked)
+	}
+
+	answer = "yes"
+	result, _ = m.CallTool(ctx, "old", "scan", nil)
+	if result.IsError || result.ForLLM != "ran scan" {
+		t.Errorf("approved call = %+v", result)
+	}
+	if len(conn.calls) != 1 {
+		t.Errorf("server got %d calls, want 1", len(conn.calls))
+	}
+}
+
+func TestMCPManager_TrustPolicy(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	policy, err := trust.NewPolicy(config.TrustConfig{
+		Keys: []config.TrustedKeyConfig{{
+			Name:      "tools-team",
+			PublicKey: base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...)),
+			Scopes:    []string{"plugins"},
+		}},
+		Plugins: "require",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "recon-mcp")
+	content := []byte("#!/bin/sh\n")
+	if err := os.WriteFile(binary, content, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMCPManager(nil)
+	m.SetTrustPolicy(policy)
+	stdio := &MCPServerConfig{Name: "recon", Transport: TransportStdio, Binary: binary}
+	if err := m.checkTrust(stdio); !errors.Is(err, trust.ErrUnsigned) {
+		t.Errorf("unsigned binary = %v, want ErrUnsigned", err)
+	}
+	remote := &MCPServerConfig{Name: "remote", Enabled: true, Transport: TransportHTTP, URL: "http://127.0.0.1:1"}
+	m.RegisterServer(remote)
+	if err := m.ConnectServer(context.Background(), "remote"); !errors.Is(err, trust.ErrUnsigned) {
+		t.Errorf("HTTP server = %v, want ErrUnsigned", err)
+	}
+
+	// A minisign signature of the binary by the trusted key
+	sig := ed25519.Sign(priv, content)
+	comment := "file:recon-mcp"
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), comment...))
+	minisig := fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), sig...)),
+		comment, base64.StdEncoding.EncodeToString(global))
+	if err := os.WriteFile(binary+trust.SignatureExt, []byte(minisig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.checkTrust(stdio); err != nil {
+		t.Errorf("signed binary = %v", err)
+	}
+
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.checkTrust(stdio); !errors.Is(err, trust.ErrUntrusted) {
+		t.Errorf("modified binary = %v, want ErrUntrusted", err)
+	}
+}
+
+func TestHTTPConnection_NegotiateLegacy(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	conn, err := NewHTTPConnection(&MCPServerConfig{Name: "legacy", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caps, err := conn.Negotiate(context.Background())
+	if err != nil || caps != nil {
+		t.Fatalf("Negotiate() = %+v, %v; want no capabilities", caps, err)
+	}
+	if negotiation, err := NewMCPManager(nil).negotiate(context.Background(), conn.config, conn); err != nil || negotiation.Sandboxed {
+		t.Errorf("legacy HTTP server = %+v, %v; want accepted", negotiation, err)
+	}
+}
+
+func TestStdioConnection_Negotiate(t *testing.T) {
+	script := `read line
+echo '{"jsonrpc":"2.0","method":"notifications/message","params":{}}'
+echo '{"jsonrpc":"2.0","id":0,"result":{"protocolVersion":"2024-11-05","capabilities":{"tools":{}}}}'
+read line`
+	conn, err := NewStdioConnection(&MCPServerConfig{Name: "sh", Binary: "sh", Args: []string{"-c", script}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	caps, err := conn.Negotiate(context.Background())
+	if err != nil {
+		t.Fatalf("Negotiate() error = %v", err)
+	}
+	if caps.ProtocolVersion != "2024-11-05" || !caps.HasFeature(FeatureTools) {
+		t.Errorf("caps = %+v", caps)
+	}
+}
+
+func TestStdioConnection_NegotiateHonoursContext(t *testing.T) {
+	conn, err := NewStdioConnection(&MCPServerConfig{Name: "silent", Binary: "sleep", Args: []string{"30"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = conn.Negotiate(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Negotiate() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Negotiate() took %v after its context ended", elapsed)
+	}
+}
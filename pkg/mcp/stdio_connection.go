@@ -146,6 +146,74 @@ func (s *StdioConnection) readResponse() (map[string]any, error) {
 	return response, nil
 }
 
+// readResponseContext is readResponse that gives up when ctx is done. The
+// read goes on in the background, so the connection must be closed after
+// that.
+func (s *StdioConnection) readResponseContext(ctx context.Context) (map[string]any, error) {
+	type read struct {
+		response map[string]any
+		err      error
+	}
+	done := make(chan read, 1)
+	go func() {
+		response, err := s.readResponse()
+		done <- read{response, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.response, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for the server: %w", ctx.Err())
+	}
+}
+
+// Negotiate performs the MCP initialize handshake. If ctx ends first the
+// connection is left unusable and must be closed.
+func (s *StdioConnection) Negotiate(ctx context.Context) (*Capabilities, error) {
+	req := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "initialize",
+		"id":      0,
+		"params":  initializeParams(),
+	}
+
+	if err := s.sendRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Servers may log notifications before answering
+	var response map[string]any
+	for {
+		var err error
+		response, err = s.readResponseContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := response["id"]; ok {
+			break
+		}
+	}
+
+	if errData, ok := response["error"]; ok {
+		return nil, fmt.Errorf("MCP initialize failed: %v", errData)
+	}
+
+	result, ok := response["result"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid initialize response format")
+	}
+
+	if err := s.sendRequest(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "notifications/initialized",
+	}); err != nil {
+		return nil, err
+	}
+
+	return parseInitializeResult(result), nil
+}
+
 func (s *StdioConnection) ListTools(ctx context.Context) ([]MCPToolDefinition, error) {
 	req := map[string]any{
 		"method": "tools/list",
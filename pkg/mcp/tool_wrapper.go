@@ -26,6 +26,9 @@ func (m *MCPToolWrapper) Name() string {
 }
 
 func (m *MCPToolWrapper) Description() string {
+	if negotiation, ok := m.manager.GetNegotiation(m.definition.Server); ok && negotiation.NeedsApproval(m.definition.Name) {
+		return m.definition.Description + " (requires operator approval)"
+	}
 	return m.definition.Description
 }
 
@@ -41,6 +44,18 @@ func (m *MCPToolWrapper) Execute(ctx context.Context, args map[string]any) *tool
 	return result
 }
 
+// SetAsker lets the operator approve calls to the server's tools that need
+// approval, such as those of a sandboxed server
+func (m *MCPToolWrapper) SetAsker(asker tools.OperatorAsker) {
+	m.manager.SetApprover(asker)
+}
+
+// ScopeText makes every argument count against the mission scope: an MCP
+// server can reach any host it is given
+func (m *MCPToolWrapper) ScopeText(args map[string]any) string {
+	return tools.ArgsScopeText(args)
+}
+
 // RegisterMCPToolsInRegistry discovers and registers all MCP tools
 func RegisterMCPToolsInRegistry(ctx context.Context, registry *tools.ToolRegistry, manager *MCPManager) error {
 	allTools, err := manager.GetAllTools(ctx)
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// ChangeType says how an item differs from the baseline
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change is one difference between a baseline and a new inventory
+type Change struct {
+	Type     ChangeType `json:"type"`
+	Item     Item       `json:"item"`
+	Previous string     `json:"previous,omitempty"` // Baseline detail of a changed item
+
+	// Changed responses only: the unified diff against the golden response,
+	// and the model's assessment of it when monitor.assess is on
+	Diff       string `json:"diff,omitempty"`
+	Assessment string `json:"assessment,omitempty"`
+}
+
+// Diff compares current against baseline. Changes are ordered by item ID.
+func Diff(baseline, current *Inventory) []Change {
+	var changes []Change
+	for _, item := range sortedItems(current.Items) {
+		old, ok := baseline.Items[item.ID()]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeAdded, Item: item})
+		case old.Detail != item.Detail:
+			changes = append(changes, Change{Type: ChangeChanged, Item: item, Previous: old.Detail})
+		}
+	}
+	for _, item := range sortedItems(baseline.Items) {
+		if _, ok := current.Items[item.ID()]; !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, Item: item})
+		}
+	}
+	return changes
+}
+
+// Severity rates a change for alerts and mission findings. New exposure
+// rates higher than changed or vanished assets.
+func (c Change) Severity() workflow.Severity {
+	if c.Type == ChangeRemoved {
+		return workflow.SeverityInformational
+	}
+	switch c.Item.Kind {
+	case KindResponse:
+		if c.Diff == "" {
+			return workflow.SeverityInformational
+		}
+		switch sev := workflow.Severity(c.Item.Severity); sev {
+		case workflow.SeverityCritical, workflow.SeverityHigh, workflow.SeverityMedium, workflow.SeverityLow, workflow.SeverityInformational:
+			return sev
+		}
+		return workflow.SeverityLow
+	case KindVulnerability:
+		switch sev := workflow.Severity(c.Item.Severity); sev {
+		case workflow.SeverityCritical, workflow.SeverityHigh, workflow.SeverityMedium, workflow.SeverityLow:
+			return sev
+		}
+		return workflow.SeverityInformational
+	case KindPort:
+		if c.Type == ChangeAdded {
+			return workflow.SeverityMedium
+		}
+		return workflow.SeverityLow
+	case KindSubdomain, KindEndpoint:
+		if c.Type == ChangeAdded {
+			return workflow.SeverityLow
+		}
+	}
+	return workflow.SeverityInformational
+}
+
+// String describes the change in one line
+func (c Change) String() string {
+	what := fmt.Sprintf("%s %s", c.Item.Kind, c.Item.Key)
+	if c.Item.Kind == KindResponse {
+		if c.Diff == "" {
+			return fmt.Sprintf("Response of %s matches its golden copy again", c.Item.Key)
+		}
+		return fmt.Sprintf("Response of %s differs from its golden copy (%s)", c.Item.Key, c.Item.Detail)
+	}
+	switch c.Type {
+	case ChangeAdded:
+		if c.Item.Detail != "" {
+			return fmt.Sprintf("New %s (%s)", what, c.Item.Detail)
+		}
+		return "New " + what
+	case ChangeRemoved:
+		return "Gone: " + what
+	default:
+		return fmt.Sprintf("Changed %s: %q -> %q", what, c.Previous, c.Item.Detail)
+	}
+}
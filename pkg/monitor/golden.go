@@ -0,0 +1,297 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-udiff"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const (
+	defaultEndpointTimeout = 30 * time.Second
+	maxResponseBody        = 1 << 20
+	maxAssessDiffChars     = 8000 // Diff text sent to the model per change
+)
+
+// volatileHeaders change on every response and are left out of the
+// recorded response
+var volatileHeaders = map[string]bool{
+	"Age":           true,
+	"Cf-Ray":        true,
+	"Date":          true,
+	"Expires":       true,
+	"Nel":           true,
+	"Report-To":     true,
+	"Server-Timing": true,
+	"X-Amz-Cf-Id":   true,
+	"X-Request-Id":  true,
+}
+
+const assessPrompt = `You review changes in HTTP responses found by scheduled monitoring of a target the operator is authorized to test. You get a unified diff between the baseline response and the latest one, not the whole response.
+
+Judge whether the change matters for security: a new version banner or stack trace, removed security headers, changed authentication or redirect behavior, new functionality, or content suggesting defacement or compromise. Routine content updates and rotated tokens are benign.
+
+Reply with JSON only: {"severity": "critical|high|medium|low|informational", "assessment": "one or two sentences on what changed and why it matters"}`
+
+// SetProvider sets the model that assesses changed responses when
+// monitor.assess is on
+func (m *Monitor) SetProvider(provider providers.LLMProvider, model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.provider = provider
+	m.model = model
+}
+
+// GoldenDir is where the golden and latest responses of target's endpoints
+// are saved
+func GoldenDir(workspace, target string) string {
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(target)
+	return filepath.Join(workspace, "monitor", "golden", name)
+}
+
+// checkResponses fetches every endpoint and compares the response with its
+// golden copy. The first response becomes the golden copy; it only changes
+// when the baselines are reset. A difference alerts once, when it first
+// appears or changes again, and once more when the response is back to the
+// golden copy.
+func (m *Monitor) checkResponses(ctx context.Context, target string) []Change {
+	dir := GoldenDir(m.workspace, target)
+	var changes []Change
+	for _, ep := range m.endpoints {
+		if ctx.Err() != nil {
+			break
+		}
+		current, err := m.fetchResponse(ctx, ep, target)
+		if err != nil {
+			logger.WarnCF("monitor", "Endpoint fetch failed", map[string]any{
+				"target":   target,
+				"endpoint": ep.Name,
+				"error":    err.Error(),
+			})
+			continue
+		}
+
+		name := safeEndpointName(ep.Name)
+		goldenPath := filepath.Join(dir, name+".golden.txt")
+		latestPath := filepath.Join(dir, name+".latest.txt")
+		golden, err := os.ReadFile(goldenPath)
+		if errors.Is(err, os.ErrNotExist) {
+			if err := writeResponse(goldenPath, current); err != nil {
+				logger.WarnCF("monitor", "Failed to save golden response", map[string]any{"endpoint": ep.Name, "error": err.Error()})
+				continue
+			}
+			logger.InfoCF("monitor", "Golden response recorded", map[string]any{"target": target, "endpoint": ep.Name})
+			continue
+		}
+		if err != nil {
+			logger.WarnCF("monitor", "Failed to read golden response", map[string]any{"endpoint": ep.Name, "error": err.Error()})
+			continue
+		}
+		latest, err := os.ReadFile(latestPath)
+		if err != nil {
+			latest = golden
+		}
+		if string(latest) == current {
+			continue
+		}
+		if err := writeResponse(latestPath, current); err != nil {
+			logger.WarnCF("monitor", "Failed to save latest response", map[string]any{"endpoint": ep.Name, "error": err.Error()})
+		}
+
+		change := Change{
+			Type:     ChangeChanged,
+			Item:     Item{Kind: KindResponse, Key: ep.Name, Check: "endpoints"},
+			Previous: responseDetail(string(golden), string(latest)),
+		}
+		if string(golden) == current {
+			change.Item.Detail = responseDetail(current, current)
+		} else {
+			change.Item.Detail = responseDetail(string(golden), current)
+			change.Diff = udiff.Unified("golden "+ep.Name, "latest "+ep.Name, string(golden), current)
+			if m.assess {
+				m.assessChange(ctx, target, &change)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// fetchResponse requests the endpoint and renders the response as text:
+// status line, sorted headers and body, with volatile parts masked
+func (m *Monitor) fetchResponse(ctx context.Context, ep config.MonitorEndpoint, target string) (string, error) {
+	ignore := make([]*regexp.Regexp, 0, len(ep.Ignore))
+	for _, pattern := range ep.Ignore {
+		re, err := regexp.Compile("(?m)" + pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+		ignore = append(ignore, re)
+	}
+
+	timeout := time.Duration(ep.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultEndpointTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := strings.ToUpper(ep.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	rawURL := strings.ReplaceAll(ep.URL, "{target}", target)
+	var body io.Reader
+	if ep.Body != "" {
+		body = strings.NewReader(ep.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range ep.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return "", err
+	}
+	return normalizeResponse(resp, data, ignore), nil
+}
+
+// normalizeResponse renders a response so that two fetches of an unchanged
+// endpoint compare equal
+func normalizeResponse(resp *http.Response, body []byte, ignore []*regexp.Regexp) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", resp.Proto, resp.Status)
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		if !volatileHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := resp.Header[name]
+		if name == "Set-Cookie" {
+			// Cookie names and flags matter; session values don't
+			values = make([]string, len(resp.Header[name]))
+			for i, cookie := range resp.Header[name] {
+				cookieName, rest, _ := strings.Cut(cookie, "=")
+				_, attrs, _ := strings.Cut(rest, ";")
+				values[i] = cookieName + "=<value>;" + attrs
+			}
+			sort.Strings(values)
+		}
+		for _, value := range values {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(strings.ReplaceAll(string(body), "\r\n", "\n"))
+
+	text := sb.String()
+	for _, re := range ignore {
+		text = re.ReplaceAllString(text, "<ignored>")
+	}
+	return text
+}
+
+// responseDetail summarizes how response differs from golden
+func responseDetail(golden, response string) string {
+	if golden == response {
+		return "matches golden response"
+	}
+	added, removed := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(udiff.Unified("golden", "latest", golden, response), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return fmt.Sprintf("%d lines added, %d removed vs golden response", added, removed)
+}
+
+// assessChange asks the model what a changed response means. Only the diff
+// is sent, not the responses. Failures leave the change unassessed.
+func (m *Monitor) assessChange(ctx context.Context, target string, change *Change) {
+	m.mu.RLock()
+	provider, model := m.provider, m.model
+	m.mu.RUnlock()
+	if provider == nil {
+		return
+	}
+
+	diff := change.Diff
+	if len(diff) > maxAssessDiffChars {
+		diff = strings.ToValidUTF8(diff[:maxAssessDiffChars], "") + "\n[... diff truncated ...]"
+	}
+	messages := []providers.Message{
+		{Role: "system", Content: assessPrompt},
+		{Role: "user", Content: fmt.Sprintf("Target: %s\nEndpoint: %s\n\n```diff\n%s\n```", target, change.Item.Key, diff)},
+	}
+	if model == "" {
+		model = provider.GetDefaultModel()
+	}
+	resp, err := provider.Chat(ctx, messages, nil, model, map[string]any{
+		"max_tokens":  512,
+		"temperature": 0.0,
+	})
+	if err != nil {
+		logger.WarnCF("monitor", "Change assessment failed", map[string]any{"endpoint": change.Item.Key, "error": err.Error()})
+		return
+	}
+
+	var verdict struct {
+		Severity   string `json:"severity"`
+		Assessment string `json:"assessment"`
+	}
+	if err := llmjson.Decode(resp.Content, &verdict); err != nil {
+		// Keep the prose rather than lose the assessment
+		change.Assessment = strings.TrimSpace(resp.Content)
+		return
+	}
+	change.Assessment = strings.TrimSpace(verdict.Assessment)
+	switch sev := workflow.Severity(strings.ToLower(verdict.Severity)); sev {
+	case workflow.SeverityCritical, workflow.SeverityHigh, workflow.SeverityMedium, workflow.SeverityLow, workflow.SeverityInformational:
+		change.Item.Severity = string(sev)
+	}
+}
+
+func writeResponse(path, response string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(response), 0o644)
+}
+
+func safeEndpointName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_").Replace(name)
+}
@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/parsers"
+)
+
+// Item kinds tracked in an inventory
+const (
+	KindSubdomain     = "subdomain"
+	KindPort          = "port"
+	KindEndpoint      = "endpoint"
+	KindVulnerability = "vulnerability"
+	KindLine          = "line"
+	KindResponse      = "response" // An endpoint response compared with its golden copy
+)
+
+// Output parsers a check can name
+const (
+	ParserNmapXML    = "nmap_xml"
+	ParserSubdomains = "subdomains"
+	ParserHTTPX      = "httpx"
+	ParserNuclei     = "nuclei"
+	ParserLines      = "lines"
+)
+
+// Item is one observed asset. Key identifies it across runs; Detail holds
+// what may change while it stays present, such as a port's service version.
+type Item struct {
+	Kind     string `json:"kind"`
+	Key      string `json:"key"`
+	Detail   string `json:"detail,omitempty"`
+	Severity string `json:"severity,omitempty"` // Vulnerabilities only
+	Check    string `json:"check"`
+}
+
+// ID is the inventory key of the item
+func (i Item) ID() string {
+	return i.Kind + ":" + i.Key
+}
+
+// Inventory is everything the checks observed for one target in one run
+type Inventory struct {
+	Target      string          `json:"target"`
+	CollectedAt time.Time       `json:"collected_at"`
+	Items       map[string]Item `json:"items"`
+}
+
+// NewInventory creates an empty inventory for target
+func NewInventory(target string) *Inventory {
+	return &Inventory{
+		Target:      target,
+		CollectedAt: time.Now(),
+		Items:       make(map[string]Item),
+	}
+}
+
+// Add records items, keeping the first of any duplicates
+func (inv *Inventory) Add(items ...Item) {
+	for _, item := range items {
+		if _, ok := inv.Items[item.ID()]; !ok {
+			inv.Items[item.ID()] = item
+		}
+	}
+}
+
+// Count returns the number of items of each kind
+func (inv *Inventory) Count() map[string]int {
+	counts := make(map[string]int)
+	for _, item := range inv.Items {
+		counts[item.Kind]++
+	}
+	return counts
+}
+
+// ParseItems reads the output of check into inventory items
+func ParseItems(check, parser, target string, output []byte) ([]Item, error) {
+	var items []Item
+	switch parser {
+	case ParserNmapXML:
+		result, err := parsers.ParseNmapXMLOutput(check, output, "monitor")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse nmap XML: %w", err)
+		}
+		for _, host := range result.Hosts {
+			addr := host.IP
+			if addr == "" {
+				addr = host.Hostname
+			}
+			for _, port := range host.Ports {
+				if port.State != "" && port.State != "open" {
+					continue
+				}
+				items = append(items, Item{
+					Kind:   KindPort,
+					Key:    fmt.Sprintf("%s:%d/%s", addr, port.Port, port.Protocol),
+					Detail: strings.TrimSpace(strings.Join([]string{port.Service, port.Product, port.Version}, " ")),
+					Check:  check,
+				})
+			}
+		}
+
+	case ParserSubdomains:
+		result, err := parsers.ParseSubfinderOutput(check, output, target, "monitor")
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range result.Subdomains {
+			items = append(items, Item{Kind: KindSubdomain, Key: strings.ToLower(sub.Name), Check: check})
+		}
+
+	case ParserHTTPX:
+		result, err := parsers.ParseHTTPXOutput(check, output, "monitor")
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range result.Endpoints {
+			items = append(items, Item{
+				Kind:   KindEndpoint,
+				Key:    ep.URL,
+				Detail: strings.TrimSpace(fmt.Sprintf("%d %s", ep.StatusCode, ep.Title)),
+				Check:  check,
+			})
+		}
+
+	case ParserNuclei:
+		result, err := parsers.ParseNucleiOutput(check, output, "monitor")
+		if err != nil {
+			return nil, err
+		}
+		for _, vuln := range result.Vulnerabilities {
+			host := target
+			if len(vuln.Affected) > 0 {
+				host = vuln.Affected[0]
+			}
+			items = append(items, Item{
+				Kind:     KindVulnerability,
+				Key:      vuln.ID + "@" + host,
+				Detail:   vuln.Title,
+				Severity: strings.ToLower(vuln.Severity),
+				Check:    check,
+			})
+		}
+
+	case ParserLines, "":
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				items = append(items, Item{Kind: KindLine, Key: check + ": " + line, Check: check})
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown parser %q", parser)
+	}
+	return items, nil
+}
+
+// sortedItems returns the inventory items ordered by ID
+func sortedItems(items map[string]Item) []Item {
+	out := make([]Item, 0, len(items))
+	for _, item := range items {
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out
+}
@@ -0,0 +1,426 @@
+// Package monitor periodically re-runs lightweight recon checks against a
+// scope and alerts when the observed inventory changes or a key endpoint's
+// response drifts from its golden copy.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/constants"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/state"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const (
+	defaultInterval     = time.Hour
+	defaultCheckTimeout = 10 * time.Minute
+)
+
+// Alert reports the changes found for one target in one run
+type Alert struct {
+	Target  string    `json:"target"`
+	Changes []Change  `json:"changes"`
+	At      time.Time `json:"at"`
+}
+
+// Summary renders the alert as a short message
+func (a Alert) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🔔 Monitoring: %d change(s) on %s", len(a.Changes), a.Target)
+	for _, c := range a.Changes {
+		fmt.Fprintf(&sb, "\n- [%s] %s", c.Severity(), c)
+		if c.Assessment != "" {
+			fmt.Fprintf(&sb, "\n  %s", c.Assessment)
+		}
+	}
+	return sb.String()
+}
+
+// Monitor runs the configured checks against every target each interval,
+// diffs the results against the saved baseline and alerts on changes. The
+// baseline then moves to the new inventory, so each change alerts once.
+type Monitor struct {
+	enabled   bool
+	interval  time.Duration
+	targets   []string
+	checks    []config.MonitorCheck
+	endpoints []config.MonitorEndpoint
+	assess    bool
+	removals  bool
+	findings  bool
+	workspace string
+	notifier  *notifier
+	client    *http.Client
+
+	mu       sync.RWMutex
+	bus      *bus.MessageBus
+	state    *state.Manager
+	provider providers.LLMProvider
+	model    string
+	onAlert  func(Alert)
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New creates a monitor from cfg.Monitor. stateMgr supplies the last active
+// channel for alerts and may be nil.
+func New(cfg *config.Config, stateMgr *state.Manager) *Monitor {
+	m := &Monitor{
+		enabled:   cfg.Monitor.Enabled,
+		interval:  time.Duration(cfg.Monitor.Interval) * time.Second,
+		targets:   cfg.Monitor.Targets,
+		checks:    cfg.Monitor.Checks,
+		endpoints: cfg.Monitor.Endpoints,
+		assess:    cfg.Monitor.Assess,
+		removals:  cfg.Monitor.Removals,
+		findings:  cfg.Monitor.Findings,
+		workspace: cfg.WorkspacePath(),
+		notifier:  newNotifier(cfg.Monitor.WebhookURL, cfg.Monitor.Email),
+		client: &http.Client{
+			// A new redirect is a change worth seeing, not following
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		state: stateMgr,
+	}
+	if m.interval <= 0 {
+		m.interval = defaultInterval
+	}
+	return m
+}
+
+// SetBus sets the message bus alerts are announced on
+func (m *Monitor) SetBus(msgBus *bus.MessageBus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bus = msgBus
+}
+
+// SetOnAlert registers fn to be called with every alert, e.g. to show it in
+// the TUI
+func (m *Monitor) SetOnAlert(fn func(Alert)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onAlert = fn
+}
+
+// Start runs every check now and then every interval until Stop
+func (m *Monitor) Start(ctx context.Context) error {
+	if !m.enabled {
+		logger.InfoC("monitor", "Continuous monitoring disabled")
+		return nil
+	}
+	if len(m.targets) == 0 || len(m.checks)+len(m.endpoints) == 0 {
+		return fmt.Errorf("monitoring needs at least one target and one check or endpoint")
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logger.InfoCF("monitor", "Continuous monitoring started", map[string]any{
+		"targets":   len(m.targets),
+		"checks":    len(m.checks),
+		"endpoints": len(m.endpoints),
+		"interval":  m.interval.String(),
+	})
+	return nil
+}
+
+// Stop ends periodic runs
+func (m *Monitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+	}
+}
+
+// RunOnce checks every target and alerts on changes. It returns the alerts
+// raised.
+func (m *Monitor) RunOnce(ctx context.Context) []Alert {
+	var alerts []Alert
+	for _, target := range m.targets {
+		if ctx.Err() != nil {
+			break
+		}
+		changes, err := m.Check(ctx, target)
+		if err != nil {
+			logger.WarnCF("monitor", "Monitoring run failed", map[string]any{
+				"target": target,
+				"error":  err.Error(),
+			})
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		alert := Alert{Target: target, Changes: changes, At: time.Now()}
+		m.alert(ctx, alert)
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// Check runs the checks against target, saves the new baseline and returns
+// the changes worth alerting on, followed by changed endpoint responses. The
+// first run only records the baselines.
+func (m *Monitor) Check(ctx context.Context, target string) ([]Change, error) {
+	var changes []Change
+	if len(m.checks) > 0 {
+		inventoryChanges, err := m.checkInventory(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		changes = inventoryChanges
+	}
+	if len(m.endpoints) > 0 {
+		changes = append(changes, m.checkResponses(ctx, target)...)
+	}
+	return changes, ctx.Err()
+}
+
+// checkInventory diffs the checks' inventory against the baseline. Items
+// from checks that failed are carried over so an outage does not look like
+// everything disappeared.
+func (m *Monitor) checkInventory(ctx context.Context, target string) ([]Change, error) {
+	current := NewInventory(target)
+	failed := make(map[string]bool)
+	for _, check := range m.checks {
+		items, err := m.runCheck(ctx, check, target)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			logger.WarnCF("monitor", "Check failed", map[string]any{
+				"target": target,
+				"check":  check.Name,
+				"error":  err.Error(),
+			})
+			failed[check.Name] = true
+			continue
+		}
+		current.Add(items...)
+	}
+	if len(failed) == len(m.checks) {
+		return nil, fmt.Errorf("every check failed")
+	}
+
+	baseline, err := LoadBaseline(m.workspace, target)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.InfoCF("monitor", "Baseline recorded", map[string]any{"target": target, "items": len(current.Items)})
+		return nil, SaveBaseline(m.workspace, current)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for id, item := range baseline.Items {
+		if failed[item.Check] {
+			current.Items[id] = item
+		}
+	}
+
+	var changes []Change
+	for _, c := range Diff(baseline, current) {
+		if c.Type == ChangeRemoved && !m.removals {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	if err := SaveBaseline(m.workspace, current); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// runCheck runs one check command and parses its output
+func (m *Monitor) runCheck(ctx context.Context, check config.MonitorCheck, target string) ([]Item, error) {
+	timeout := time.Duration(check.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	command := strings.ReplaceAll(check.Command, "{target}", shellQuote(target))
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = m.workspace
+	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		return nil, fmt.Errorf("%s: %w", check.Name, err)
+	}
+	return ParseItems(check.Name, check.Parser, target, output)
+}
+
+// alert sends an alert to every configured destination
+func (m *Monitor) alert(ctx context.Context, alert Alert) {
+	logger.InfoCF("monitor", "Inventory changed", map[string]any{
+		"target":  alert.Target,
+		"changes": len(alert.Changes),
+	})
+
+	m.mu.RLock()
+	onAlert := m.onAlert
+	m.mu.RUnlock()
+	if onAlert != nil {
+		onAlert(alert)
+	}
+
+	m.announce(alert.Summary())
+	if err := m.notifier.send(ctx, alert); err != nil {
+		logger.WarnCF("monitor", "Failed to send alert", map[string]any{"error": err.Error()})
+	}
+	if m.findings {
+		if err := RecordFindings(m.workspace, alert); err != nil {
+			logger.WarnCF("monitor", "Failed to record findings", map[string]any{
+				"target": alert.Target,
+				"error":  err.Error(),
+			})
+		}
+	}
+	if err := appendChangeLog(m.workspace, alert); err != nil {
+		logger.WarnCF("monitor", "Failed to log changes", map[string]any{"error": err.Error()})
+	}
+}
+
+// announce sends a message to the last active channel, like provider health
+// notices
+func (m *Monitor) announce(content string) {
+	m.mu.RLock()
+	msgBus, stateMgr := m.bus, m.state
+	m.mu.RUnlock()
+	if msgBus == nil || stateMgr == nil {
+		return
+	}
+
+	platform, chatID, ok := strings.Cut(stateMgr.GetLastChannel(), ":")
+	if !ok || platform == "" || chatID == "" || constants.IsInternalChannel(platform) {
+		return
+	}
+	msgBus.PublishOutbound(bus.OutboundMessage{
+		Channel: platform,
+		ChatID:  chatID,
+		Content: content,
+	})
+}
+
+// RecordFindings adds the alert's changes as findings on the mission for
+// its target. Targets without a saved mission are skipped.
+func RecordFindings(workspace string, alert Alert) error {
+	statePath := workflow.MissionStatePath(workspace, alert.Target)
+	mission, err := workflow.ReadMissionState(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	wf, err := workflow.LoadWorkflow(workspace, mission.WorkflowName)
+	if err != nil {
+		return err
+	}
+	engine, err := workflow.LoadEngine(wf, statePath, workspace)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range alert.Changes {
+		if c.Type == ChangeRemoved || (c.Item.Kind == KindResponse && c.Diff == "") {
+			continue
+		}
+		description := "Detected by continuous monitoring."
+		if c.Assessment != "" {
+			description += " " + c.Assessment
+		}
+		evidence := fmt.Sprintf("Observed by monitoring check %q at %s", c.Item.Check, alert.At.Format(time.RFC3339))
+		if c.Diff != "" {
+			evidence += "\n\n" + c.Diff
+		}
+		if err := engine.AddFinding(c.String(), description, c.Severity(), evidence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BaselinePath is where the latest inventory for target is saved
+func BaselinePath(workspace, target string) string {
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(target)
+	return filepath.Join(workspace, "monitor", name+"_baseline.json")
+}
+
+// LoadBaseline reads the saved inventory for target
+func LoadBaseline(workspace, target string) (*Inventory, error) {
+	data, err := os.ReadFile(BaselinePath(workspace, target))
+	if err != nil {
+		return nil, err
+	}
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	if inv.Items == nil {
+		inv.Items = make(map[string]Item)
+	}
+	return &inv, nil
+}
+
+// SaveBaseline saves inv as the baseline for its target
+func SaveBaseline(workspace string, inv *Inventory) error {
+	path := BaselinePath(workspace, inv.Target)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// appendChangeLog appends the alert to monitor/changes.jsonl
+func appendChangeLog(workspace string, alert Alert) error {
+	path := filepath.Join(workspace, "monitor", "changes.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,232 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const nmapXML = `<?xml version="1.0"?>
+<nmaprun><host><address addr="10.0.0.5" addrtype="ipv4"/><ports>
+<port protocol="tcp" portid="22"><state state="open"/><service name="ssh" product="OpenSSH" version="9.6"/></port>
+<port protocol="tcp" portid="8080"><state state="open"/><service name="http"/></port>
+</ports></host></nmaprun>`
+
+func TestParseItems_NmapXML(t *testing.T) {
+	items, err := ParseItems("nmap", ParserNmapXML, "10.0.0.5", []byte(nmapXML))
+	if err != nil {
+		t.Fatalf("ParseItems() error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2: %+v", len(items), items)
+	}
+	if items[0].ID() != "port:10.0.0.5:22/tcp" || items[0].Detail != "ssh OpenSSH 9.6" {
+		t.Errorf("items[0] = %+v", items[0])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	baseline := NewInventory("example.com")
+	baseline.Add(
+		Item{Kind: KindPort, Key: "10.0.0.5:22/tcp", Detail: "ssh OpenSSH 9.6"},
+		Item{Kind: KindSubdomain, Key: "old.example.com"},
+	)
+	current := NewInventory("example.com")
+	current.Add(
+		Item{Kind: KindPort, Key: "10.0.0.5:22/tcp", Detail: "ssh OpenSSH 9.7"},
+		Item{Kind: KindPort, Key: "10.0.0.5:8080/tcp", Detail: "http"},
+	)
+
+	changes := Diff(baseline, current)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3: %+v", len(changes), changes)
+	}
+	if changes[0].Type != ChangeChanged || changes[0].Previous != "ssh OpenSSH 9.6" {
+		t.Errorf("changes[0] = %+v", changes[0])
+	}
+	if changes[1].Type != ChangeAdded || changes[1].Severity() != workflow.SeverityMedium {
+		t.Errorf("changes[1] = %+v, severity %s", changes[1], changes[1].Severity())
+	}
+	if changes[2].Type != ChangeRemoved || changes[2].Item.Key != "old.example.com" {
+		t.Errorf("changes[2] = %+v", changes[2])
+	}
+}
+
+func TestMonitor_CheckAgainstBaseline(t *testing.T) {
+	workspace := t.TempDir()
+	subsFile := filepath.Join(workspace, "subs.txt")
+	if err := os.WriteFile(subsFile, []byte("www.example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Monitor = config.MonitorConfig{
+		Enabled: true,
+		Targets: []string{"example.com"},
+		Checks: []config.MonitorCheck{
+			{Name: "subs", Command: "cat " + subsFile, Parser: ParserSubdomains},
+			{Name: "broken", Command: "exit 1", Parser: ParserLines},
+		},
+	}
+	m := New(cfg, nil)
+	ctx := context.Background()
+
+	// The first run records the baseline without alerting
+	if alerts := m.RunOnce(ctx); len(alerts) != 0 {
+		t.Fatalf("first run alerted: %+v", alerts)
+	}
+	if _, err := LoadBaseline(workspace, "example.com"); err != nil {
+		t.Fatalf("baseline not saved: %v", err)
+	}
+
+	if err := os.WriteFile(subsFile, []byte("www.example.com\nvpn.example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var got []Alert
+	m.SetOnAlert(func(a Alert) { got = append(got, a) })
+	alerts := m.RunOnce(ctx)
+	if len(alerts) != 1 || len(got) != 1 {
+		t.Fatalf("got %d alerts (%d via callback), want 1", len(alerts), len(got))
+	}
+	if changes := alerts[0].Changes; len(changes) != 1 || changes[0].Item.Key != "vpn.example.com" {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	// The baseline moved on, so the same inventory raises nothing
+	if alerts := m.RunOnce(ctx); len(alerts) != 0 {
+		t.Errorf("unchanged run alerted: %+v", alerts)
+	}
+}
+
+func TestRecordFindings(t *testing.T) {
+	workspace := t.TempDir()
+	wfDir := filepath.Join(workspace, "workflows")
+	if err := os.MkdirAll(wfDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wfDoc := "---\nname: recon\nphases: [discovery]\n---\n\n## Phase: discovery\n\n### Steps\n\n- enumerate: Enumerate hosts (required)\n"
+	if err := os.WriteFile(filepath.Join(wfDir, "recon.md"), []byte(wfDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wf, err := workflow.LoadWorkflow(workspace, "recon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workflow.NewEngine(wf, "example.com", workspace).SaveState(); err != nil {
+		t.Fatal(err)
+	}
+
+	alert := Alert{Target: "example.com", Changes: []Change{
+		{Type: ChangeAdded, Item: Item{Kind: KindSubdomain, Key: "vpn.example.com", Check: "subs"}},
+		{Type: ChangeRemoved, Item: Item{Kind: KindSubdomain, Key: "old.example.com", Check: "subs"}},
+	}}
+	if err := RecordFindings(workspace, alert); err != nil {
+		t.Fatalf("RecordFindings() error: %v", err)
+	}
+
+	engine, err := workflow.LoadEngine(wf, workflow.MissionStatePath(workspace, "example.com"), workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := engine.GetState().Findings
+	if len(findings) != 1 || findings[0].Title != "New subdomain vpn.example.com" {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	// Targets without a mission are skipped
+	if err := RecordFindings(workspace, Alert{Target: "other.com", Changes: alert.Changes}); err != nil {
+		t.Errorf("RecordFindings() without mission: %v", err)
+	}
+}
+
+// assessProvider answers every assessment with a fixed verdict and keeps
+// the prompt it was sent
+type assessProvider struct {
+	prompt string
+}
+
+func (p *assessProvider) Chat(_ context.Context, messages []providers.Message, _ []providers.ToolDefinition, _ string, _ map[string]any) (*providers.LLMResponse, error) {
+	p.prompt = messages[len(messages)-1].Content
+	return &providers.LLMResponse{Content: `{"severity": "high", "assessment": "The server now discloses its version."}`}, nil
+}
+
+func (p *assessProvider) GetDefaultModel() string { return "test" }
+
+func TestMonitor_GoldenResponses(t *testing.T) {
+	var version, nonce atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", fmt.Sprintf("session=%d; HttpOnly", nonce.Add(1)))
+		if version.Load() > 0 {
+			w.Header().Set("Server", "Apache/2.4.49")
+		}
+		fmt.Fprintf(w, "<html>\n<p>Welcome</p>\n<input name=csrf value=%d>\n</html>\n", nonce.Load())
+	}))
+	defer srv.Close()
+
+	workspace := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Monitor = config.MonitorConfig{
+		Enabled: true,
+		Targets: []string{"example.com"},
+		Endpoints: []config.MonitorEndpoint{
+			{Name: "home", URL: srv.URL + "/?host={target}", Ignore: []string{`csrf value=\d+`}},
+		},
+		Assess: true,
+	}
+	m := New(cfg, nil)
+	provider := &assessProvider{}
+	m.SetProvider(provider, "")
+	ctx := context.Background()
+
+	// The first run records the golden response; volatile values don't alert
+	for run := 0; run < 2; run++ {
+		if alerts := m.RunOnce(ctx); len(alerts) != 0 {
+			t.Fatalf("run %d alerted: %+v", run, alerts)
+		}
+	}
+	golden, err := os.ReadFile(filepath.Join(GoldenDir(workspace, "example.com"), "home.golden.txt"))
+	if err != nil {
+		t.Fatalf("golden response not saved: %v", err)
+	}
+	if !strings.Contains(string(golden), "Set-Cookie: session=<value>; HttpOnly") {
+		t.Errorf("golden response = %q", golden)
+	}
+
+	version.Store(1)
+	alerts := m.RunOnce(ctx)
+	if len(alerts) != 1 || len(alerts[0].Changes) != 1 {
+		t.Fatalf("got %+v, want one change", alerts)
+	}
+	c := alerts[0].Changes[0]
+	if c.Item.Kind != KindResponse || !strings.Contains(c.Diff, "+Server: Apache/2.4.49") {
+		t.Errorf("change = %+v", c)
+	}
+	if c.Severity() != workflow.SeverityHigh || c.Assessment != "The server now discloses its version." {
+		t.Errorf("assessment = %q, severity %s", c.Assessment, c.Severity())
+	}
+	if strings.Contains(provider.prompt, "Welcome") {
+		t.Errorf("the model was sent more than the changed lines: %q", provider.prompt)
+	}
+
+	// The same drift alerts once; going back to the golden copy alerts again
+	if alerts := m.RunOnce(ctx); len(alerts) != 0 {
+		t.Errorf("unchanged drift alerted again: %+v", alerts)
+	}
+	version.Store(0)
+	alerts = m.RunOnce(ctx)
+	if len(alerts) != 1 || alerts[0].Changes[0].Diff != "" || alerts[0].Changes[0].Severity() != workflow.SeverityInformational {
+		t.Errorf("got %+v, want a change back to the golden copy", alerts)
+	}
+}
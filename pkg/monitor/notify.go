@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+const defaultSMTPPort = 587
+
+// notifier delivers alerts to a webhook and by email
+type notifier struct {
+	webhookURL string
+	email      config.MonitorEmailConfig
+	client     *http.Client
+}
+
+func newNotifier(webhookURL string, email config.MonitorEmailConfig) *notifier {
+	if email.SMTPPort == 0 {
+		email.SMTPPort = defaultSMTPPort
+	}
+	return &notifier{
+		webhookURL: webhookURL,
+		email:      email,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// send delivers alert to every configured destination
+func (n *notifier) send(ctx context.Context, alert Alert) error {
+	var errs []error
+	if n.webhookURL != "" {
+		if err := n.postWebhook(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if n.email.SMTPHost != "" && len(n.email.To) > 0 {
+		if err := n.sendEmail(alert); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postWebhook POSTs the alert as JSON, like ask_operator notifications
+func (n *notifier) postWebhook(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]any{
+		"type":      "monitor_alert",
+		"target":    alert.Target,
+		"changes":   alert.Changes,
+		"summary":   alert.Summary(),
+		"timestamp": alert.At.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *notifier) sendEmail(alert Alert) error {
+	from := n.email.From
+	if from == "" {
+		from = n.email.Username
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.email.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [picoclaw] %d monitoring change(s) on %s\r\n", len(alert.Changes), alert.Target)
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.Summary(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if n.email.Username != "" {
+		auth = smtp.PlainAuth("", n.email.Username, n.email.Password, n.email.SMTPHost)
+	}
+	addr := net.JoinHostPort(n.email.SMTPHost, strconv.Itoa(n.email.SMTPPort))
+	return smtp.SendMail(addr, auth, from, n.email.To, []byte(msg.String()))
+}
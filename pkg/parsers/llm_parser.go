@@ -9,6 +9,7 @@ import (
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/artifacts"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/blackboard"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 )
@@ -56,10 +57,13 @@ func (p *LLMParser) ParseOutput(ctx context.Context, toolName, toolDescription s
 		},
 	}
 
-	// Call LLM for parsing (no tools needed)
+	// Call LLM for parsing (no tools needed). Providers with structured output
+	// return the schema's object directly; others still get the prompt's
+	// instructions and their reply goes through extractJSON.
 	options := map[string]any{
-		"temperature": 0.1, // Low temperature for consistent parsing
-		"max_tokens":  4096,
+		"temperature":                  0.1, // Low temperature for consistent parsing
+		"max_tokens":                   4096,
+		providers.ResponseSchemaOption: extractionSchema(expectedArtifactType),
 	}
 
 	logger.DebugCF("llm_parser", "Calling LLM to parse tool output",
@@ -331,64 +335,5 @@ func (p *LLMParser) createRawOutputArtifact(toolName string, rawOutput []byte, p
 
 // extractJSON extracts JSON from markdown-wrapped text or plain response
 func extractJSON(text string) string {
-	// Try to find JSON in markdown code blocks
-	if strings.Contains(text, "```json") {
-		start := strings.Index(text, "```json")
-		if start != -1 {
-			start += 7 // Skip past ```json
-			end := strings.Index(text[start:], "```")
-			if end != -1 {
-				return strings.TrimSpace(text[start : start+end])
-			}
-		}
-	}
-
-	// Try to find JSON in plain code blocks
-	if strings.Contains(text, "```") {
-		start := strings.Index(text, "```")
-		if start != -1 {
-			start += 3 // Skip past ```
-			// Skip optional language tag
-			if newline := strings.Index(text[start:], "\n"); newline != -1 {
-				start += newline + 1
-			}
-			end := strings.Index(text[start:], "```")
-			if end != -1 {
-				return strings.TrimSpace(text[start : start+end])
-			}
-		}
-	}
-
-	// Try to find JSON object/array in plain text
-	if idx := strings.Index(text, "{"); idx != -1 {
-		// Find matching closing brace
-		braceCount := 0
-		for i := idx; i < len(text); i++ {
-			if text[i] == '{' {
-				braceCount++
-			} else if text[i] == '}' {
-				braceCount--
-				if braceCount == 0 {
-					return strings.TrimSpace(text[idx : i+1])
-				}
-			}
-		}
-	}
-
-	if idx := strings.Index(text, "["); idx != -1 {
-		// Find matching closing bracket
-		bracketCount := 0
-		for i := idx; i < len(text); i++ {
-			if text[i] == '[' {
-				bracketCount++
-			} else if text[i] == ']' {
-				bracketCount--
-				if bracketCount == 0 {
-					return strings.TrimSpace(text[idx : i+1])
-				}
-			}
-		}
-	}
-
-	return ""
+	return llmjson.Extract(text)
 }
@@ -0,0 +1,94 @@
+package parsers
+
+import "github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+
+// extractionSchema returns the response schema requested from the LLM for an
+// artifact type. It mirrors the structure described in the extraction prompt,
+// so providers with structured output return exactly that object.
+func extractionSchema(artifactType string) *providers.ResponseSchema {
+	str := map[string]any{"type": "string"}
+	strList := map[string]any{"type": "array", "items": str}
+
+	var properties map[string]any
+	var required []string
+
+	switch artifactType {
+	case "SubdomainList":
+		properties = map[string]any{
+			"subdomains": strList,
+			"count":      map[string]any{"type": "integer"},
+		}
+		required = []string{"subdomains"}
+
+	case "PortScanResult":
+		properties = map[string]any{
+			"host": str,
+			"open_ports": map[string]any{
+				"type": "array",
+				"items": object(map[string]any{
+					"port":     map[string]any{"type": "integer"},
+					"protocol": str,
+					"service":  str,
+					"version":  str,
+				}, "port"),
+			},
+			"os": str,
+		}
+		required = []string{"open_ports"}
+
+	case "WebFindings":
+		properties = map[string]any{
+			"endpoints": map[string]any{
+				"type": "array",
+				"items": object(map[string]any{
+					"url":         str,
+					"status_code": map[string]any{"type": "integer"},
+					"title":       str,
+				}, "url"),
+			},
+			"technologies":        strList,
+			"interesting_headers": strList,
+		}
+		required = []string{"endpoints"}
+
+	case "VulnerabilityList":
+		properties = map[string]any{
+			"vulnerabilities": map[string]any{
+				"type": "array",
+				"items": object(map[string]any{
+					"title":       str,
+					"severity":    map[string]any{"type": "string", "enum": []string{"critical", "high", "medium", "low", "info"}},
+					"url":         str,
+					"description": str,
+				}, "title", "severity"),
+			},
+		}
+		required = []string{"vulnerabilities"}
+
+	default:
+		properties = map[string]any{
+			"summary":          str,
+			"key_findings":     strList,
+			"interesting_data": map[string]any{"type": "object"},
+		}
+		required = []string{"summary", "key_findings"}
+		artifactType = "ToolOutput"
+	}
+
+	return &providers.ResponseSchema{
+		Name:        "extract_" + artifactType,
+		Description: "Structured " + artifactType + " extracted from raw tool output",
+		Schema:      object(properties, required...),
+	}
+}
+
+func object(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
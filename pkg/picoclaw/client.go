@@ -0,0 +1,252 @@
+// Package picoclaw embeds the picoclaw engine in other Go programs.
+//
+// A Client wraps the agent loop, tier routing and workflow engine behind a
+// small API: SendMessage runs one agent turn, RunMission loads a workflow
+// and starts it, and Subscribe streams tool results, findings and replies
+// as they happen.
+//
+// The exported API of this package follows semantic versioning: within a
+// major version it only changes in backwards compatible ways. The packages
+// it wraps (pkg/agent, pkg/routing, pkg/workflow, ...) carry no such
+// guarantee, so embedders should not need to import them.
+package picoclaw
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/agent"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Channel is the channel name turns sent through a Client are processed
+// under.
+const Channel = "sdk"
+
+const (
+	defaultSessionKey = "sdk:default"
+	subscriberBuffer  = 64
+)
+
+// Options configures NewClient.
+type Options struct {
+	// Config is used as is when set; otherwise it is loaded from ConfigPath.
+	Config *config.Config
+	// ConfigPath defaults to ~/.picoclaw/config.json.
+	ConfigPath string
+	// Model overrides agents.defaults.model_name.
+	Model string
+	// Provider replaces the provider created from the config.
+	Provider providers.LLMProvider
+}
+
+// EventType identifies what an Event reports.
+type EventType string
+
+const (
+	EventToolResult EventType = "tool_result" // A tool call finished
+	EventFinding    EventType = "finding"     // A tool call recorded a mission finding
+	EventReply      EventType = "reply"       // The agent's final answer for a turn
+)
+
+// Event is published to subscribers while a turn runs.
+type Event struct {
+	Type       EventType
+	SessionKey string
+	Time       time.Time
+
+	Tool    string         // EventToolResult
+	Args    map[string]any // EventToolResult
+	Output  string         // EventToolResult: what the tool showed the user
+	IsError bool           // EventToolResult
+	Finding *Finding       // EventFinding
+	Content string         // EventReply
+}
+
+// Client is an embedded picoclaw engine. It is safe for concurrent use.
+type Client struct {
+	agentLoop     *agent.AgentLoop
+	provider      providers.LLMProvider
+	ownsProvider  bool
+	done          chan struct{} // Closed by Close
+	subscribersMu sync.Mutex
+	subscribers   map[chan Event]struct{}
+	closed        bool
+}
+
+// NewClient creates a client from opts, loading the config and creating
+// the provider the same way the picoclaw CLI does.
+func NewClient(opts Options) (*Client, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		path := opts.ConfigPath
+		if path == "" {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, ".picoclaw", "config.json")
+		}
+		var err error
+		if cfg, err = config.LoadConfig(path); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+	}
+	if opts.Model != "" {
+		cfg.Agents.Defaults.ModelName = opts.Model
+	}
+	if cfg.Determinism.Enabled {
+		determinism.Enable(cfg.Determinism.Seed)
+	}
+
+	provider, ownsProvider := opts.Provider, false
+	if provider == nil {
+		created, modelID, err := providers.CreateProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating provider: %w", err)
+		}
+		if modelID != "" {
+			cfg.Agents.Defaults.ModelName = modelID
+		}
+		provider, ownsProvider = created, true
+	}
+
+	return &Client{
+		agentLoop:    agent.NewAgentLoop(cfg, bus.NewMessageBus(), provider),
+		provider:     provider,
+		ownsProvider: ownsProvider,
+		done:         make(chan struct{}),
+		subscribers:  make(map[chan Event]struct{}),
+	}, nil
+}
+
+// SendMessage runs one agent turn in the session and returns the reply. An
+// empty session key uses "sdk:default".
+func (c *Client) SendMessage(ctx context.Context, sessionKey, content string) (string, error) {
+	if sessionKey == "" {
+		sessionKey = defaultSessionKey
+	}
+	return c.turn(ctx, sessionKey, content)
+}
+
+// Subscribe returns a channel of events from every turn the client runs.
+// The channel is closed when ctx is done or the client is closed. Events are
+// dropped for subscribers that fall too far behind.
+func (c *Client) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+	if c.closed {
+		close(ch)
+		return ch
+	}
+	c.subscribers[ch] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.done:
+		}
+		c.subscribersMu.Lock()
+		defer c.subscribersMu.Unlock()
+		if _, ok := c.subscribers[ch]; ok {
+			delete(c.subscribers, ch)
+			close(ch)
+		}
+	}()
+	return ch
+}
+
+// Close stops the engine and closes all subscriptions.
+func (c *Client) Close() {
+	c.subscribersMu.Lock()
+	if c.closed {
+		c.subscribersMu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.done)
+	for ch := range c.subscribers {
+		delete(c.subscribers, ch)
+		close(ch)
+	}
+	c.subscribersMu.Unlock()
+
+	c.agentLoop.Stop()
+	if sp, ok := c.provider.(providers.StatefulProvider); ok && c.ownsProvider {
+		sp.Close()
+	}
+}
+
+// turn processes content in the session, publishing tool results, new
+// mission findings and the reply as events.
+func (c *Client) turn(ctx context.Context, sessionKey, content string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("message content is empty")
+	}
+	sessionKey = c.agentLoop.DirectSessionKey(sessionKey)
+
+	// Findings already recorded before this turn are not re-announced.
+	seenFindings := len(c.missionFindings())
+
+	ctx = agent.WithToolResultHandler(ctx, func(toolName string, args map[string]any, result *tools.ToolResult) {
+		event := Event{
+			Type:       EventToolResult,
+			SessionKey: sessionKey,
+			Tool:       toolName,
+			Args:       args,
+			IsError:    result.IsError,
+		}
+		if !result.Silent {
+			event.Output = result.ForUser
+		}
+		c.publish(event)
+
+		findings := c.missionFindings()
+		for i := seenFindings; i < len(findings); i++ {
+			c.publish(Event{Type: EventFinding, SessionKey: sessionKey, Finding: &findings[i]})
+		}
+		seenFindings = len(findings)
+	})
+
+	reply, err := c.agentLoop.ProcessDirectWithChannel(ctx, content, sessionKey, Channel, sessionKey)
+	if err != nil {
+		return "", err
+	}
+	c.publish(Event{Type: EventReply, SessionKey: sessionKey, Content: reply})
+	return reply, nil
+}
+
+func (c *Client) publish(event Event) {
+	event.Time = time.Now()
+
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+	for ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logger.WarnCF("sdk", "Dropping event for slow subscriber",
+				map[string]any{"type": string(event.Type), "session_key": event.SessionKey})
+		}
+	}
+}
+
+// missionEngine returns the default agent's workflow engine, or nil when no
+// mission is loaded.
+func (c *Client) missionEngine() *workflow.Engine {
+	defaultAgent := c.agentLoop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return nil
+	}
+	return defaultAgent.WorkflowEngine
+}
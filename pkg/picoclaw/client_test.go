@@ -0,0 +1,136 @@
+package picoclaw
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+const smokeWorkflow = `---
+name: smoke
+description: Smoke test mission
+phases: [discovery]
+---
+
+## Phase: discovery
+
+### Steps
+
+- probe: Probe the target (required)
+`
+
+// findingProvider records one finding, then answers.
+type findingProvider struct{}
+
+func (p *findingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Role == "tool" {
+		return &providers.LLMResponse{Content: "Recorded the open redis port."}, nil
+	}
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:   "call_1",
+			Name: "workflow_add_finding",
+			Arguments: map[string]any{
+				"title":       "Unauthenticated redis",
+				"description": "redis on 6379 accepts commands without AUTH",
+				"severity":    "high",
+				"evidence":    "PING -> PONG",
+			},
+		}},
+	}, nil
+}
+
+func (p *findingProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "workflows"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "workflows", "smoke.md"), []byte(smokeWorkflow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(Options{
+		Config: &config.Config{
+			Agents: config.AgentsConfig{
+				Defaults: config.AgentDefaults{
+					Workspace:         workspace,
+					Model:             "test-model",
+					MaxTokens:         4096,
+					MaxToolIterations: 5,
+				},
+			},
+		},
+		Provider: &findingProvider{},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestClient_RunMission(t *testing.T) {
+	client := newTestClient(t)
+	events := client.Subscribe(context.Background())
+
+	result, err := client.RunMission(context.Background(), MissionOptions{Workflow: "smoke", Target: "10.0.0.5"})
+	if err != nil {
+		t.Fatalf("RunMission() failed: %v", err)
+	}
+	if result.Reply != "Recorded the open redis port." || result.Phase != "discovery" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Severity != "high" {
+		t.Fatalf("findings = %+v, want one high finding", result.Findings)
+	}
+
+	var types []EventType
+	for len(types) < 3 {
+		event := <-events
+		if event.SessionKey != result.SessionKey {
+			t.Errorf("event session %q, want %q", event.SessionKey, result.SessionKey)
+		}
+		types = append(types, event.Type)
+		if event.Type == EventFinding && event.Finding.Title != "Unauthenticated redis" {
+			t.Errorf("finding event = %+v", event.Finding)
+		}
+	}
+	if types[0] != EventToolResult || types[1] != EventFinding || types[2] != EventReply {
+		t.Errorf("event types = %v, want tool_result, finding, reply", types)
+	}
+}
+
+func TestClient_SubscribeClosesOnClose(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := client.Subscribe(ctx)
+	open := client.Subscribe(context.Background())
+
+	cancel()
+	if _, ok := <-cancelled; ok {
+		t.Error("subscription stayed open after its context was cancelled")
+	}
+	client.Close()
+	if _, ok := <-open; ok {
+		t.Error("subscription stayed open after Close")
+	}
+	if _, err := client.SendMessage(context.Background(), "", " "); err == nil {
+		t.Error("expected error for empty message")
+	}
+}
@@ -0,0 +1,39 @@
+package picoclaw_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/picoclaw"
+)
+
+func Example() {
+	client, err := picoclaw.NewClient(picoclaw.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	go func() {
+		for event := range client.Subscribe(ctx) {
+			if event.Type == picoclaw.EventFinding {
+				fmt.Printf("[%s] %s\n", event.Finding.Severity, event.Finding.Title)
+			}
+		}
+	}()
+
+	result, err := client.RunMission(ctx, picoclaw.MissionOptions{
+		Workflow: "network-scan",
+		Target:   "10.0.0.0/24",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	reply, err := client.SendMessage(ctx, result.SessionKey, "Continue with enumeration.")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(reply)
+}
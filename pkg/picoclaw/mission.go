@@ -0,0 +1,122 @@
+package picoclaw
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// MissionOptions configures RunMission.
+type MissionOptions struct {
+	// Workflow names a workflow in the workspace, e.g. "network-scan" for
+	// workflows/network-scan.md.
+	Workflow string
+	Target   string
+	// Prompt is the first message; it defaults to asking the agent to work
+	// through the first phase.
+	Prompt string
+	// SessionKey defaults to a fresh "sdk:mission_<workflow>_<unix>" session.
+	SessionKey string
+}
+
+// MissionResult is the state of a mission after RunMission's turn.
+type MissionResult struct {
+	SessionKey string
+	Reply      string
+	Phase      string // Phase the mission is in
+	Findings   []Finding
+}
+
+// Finding is a discovery recorded during a mission.
+type Finding struct {
+	ID          string
+	Title       string
+	Description string
+	Severity    string // critical, high, medium, low or informational
+	Phase       string
+	Evidence    string
+	Redaction   string // full, partial or internal; empty means full
+	Created     time.Time
+}
+
+// RunMission loads a workflow against a target, replacing any mission in
+// progress, and runs the first agent turn. Continue the mission with
+// SendMessage on the returned session key.
+func (c *Client) RunMission(ctx context.Context, opts MissionOptions) (*MissionResult, error) {
+	if opts.Workflow == "" {
+		return nil, fmt.Errorf("workflow is required")
+	}
+	defaultAgent := c.agentLoop.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return nil, fmt.Errorf("no default agent configured")
+	}
+	if err := defaultAgent.LoadWorkflow(opts.Workflow, opts.Target); err != nil {
+		return nil, fmt.Errorf("failed to load workflow '%s': %w", opts.Workflow, err)
+	}
+
+	sessionKey := opts.SessionKey
+	if sessionKey == "" {
+		// A fresh session keeps history from earlier runs out of the mission
+		sessionKey = fmt.Sprintf("sdk:mission_%s_%d", opts.Workflow, time.Now().Unix())
+	}
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = "Start the mission: work through the steps of the current phase."
+		if opts.Target != "" {
+			prompt = fmt.Sprintf("Start the mission against %s: work through the steps of the current phase.", opts.Target)
+		}
+	}
+
+	reply, err := c.turn(ctx, sessionKey, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &MissionResult{
+		SessionKey: c.agentLoop.DirectSessionKey(sessionKey),
+		Reply:      reply,
+		Phase:      c.missionPhase(),
+		Findings:   c.missionFindings(),
+	}, nil
+}
+
+// Findings returns the findings of the loaded mission, or nil when none is
+// loaded.
+func (c *Client) Findings() []Finding {
+	return c.missionFindings()
+}
+
+func (c *Client) missionFindings() []Finding {
+	engine := c.missionEngine()
+	if engine == nil {
+		return nil
+	}
+	state := engine.GetState()
+	findings := make([]Finding, 0, len(state.Findings))
+	for _, f := range state.Findings {
+		findings = append(findings, findingFrom(f))
+	}
+	return findings
+}
+
+func (c *Client) missionPhase() string {
+	engine := c.missionEngine()
+	if engine == nil {
+		return ""
+	}
+	return engine.CurrentPhaseName()
+}
+
+func findingFrom(f workflow.Finding) Finding {
+	return Finding{
+		ID:          f.ID,
+		Title:       f.Title,
+		Description: f.Description,
+		Severity:    string(f.Severity),
+		Phase:       f.Phase,
+		Evidence:    f.Evidence,
+		Redaction:   string(f.Redaction),
+		Created:     f.CreatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+// Package pipeline post-processes model output into machine-consumable
+// artifacts. A pipeline is a named list of stages, such as strip markdown,
+// extract JSON, validate against a schema and store as an artifact, set up
+// in config and attached to task types, so callers get clean output instead
+// of each trimming fences and hunting for JSON on its own.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Stage types
+const (
+	StageStripMarkdown  = "strip_markdown"  // Unwrap code fences, drop heading and emphasis markers
+	StageExtractJSON    = "extract_json"    // Keep only the first JSON value, repaired if cut off
+	StageValidateSchema = "validate_schema" // Fail unless the output is JSON matching a schema
+	StageStoreArtifact  = "store_artifact"  // Write the output to a file
+)
+
+// DefaultArtifactDir is where store_artifact writes, relative to the
+// workspace, when its stage names no directory
+const DefaultArtifactDir = "artifacts/pipelines"
+
+// Output is model output on its way through a pipeline
+type Output struct {
+	Task      string   // Task type the output answers, used to name artifacts
+	Text      string   // The output as transformed so far
+	Artifacts []string // Files written by store_artifact stages
+}
+
+// Stage transforms, checks or stores output
+type Stage interface {
+	Name() string
+	Apply(ctx context.Context, out *Output) error
+}
+
+// Pipeline runs its stages in order
+type Pipeline struct {
+	Name   string
+	Stages []Stage
+}
+
+// New builds a pipeline from config. Relative store_artifact directories
+// are taken from workspace.
+func New(name string, stages []config.PipelineStageConfig, workspace string) (*Pipeline, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("pipeline %s has no stages", name)
+	}
+	p := &Pipeline{Name: name}
+	for i, cfg := range stages {
+		stage, err := newStage(cfg, workspace)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s, stage %d: %w", name, i+1, err)
+		}
+		p.Stages = append(p.Stages, stage)
+	}
+	return p, nil
+}
+
+// Build builds every configured pipeline, by name. Invalid pipelines are
+// logged and left out, so one typo doesn't stop the agent.
+func Build(configs map[string][]config.PipelineStageConfig, workspace string) map[string]*Pipeline {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pipelines := make(map[string]*Pipeline, len(configs))
+	for _, name := range names {
+		p, err := New(name, configs[name], workspace)
+		if err != nil {
+			logger.ErrorCF("pipeline", "Skipping invalid output pipeline", map[string]any{
+				"pipeline": name,
+				"error":    err.Error(),
+			})
+			continue
+		}
+		pipelines[name] = p
+	}
+	return pipelines
+}
+
+// Run passes text, the output of a task of the given type, through the
+// pipeline. The first stage to fail stops it.
+func (p *Pipeline) Run(ctx context.Context, task, text string) (*Output, error) {
+	out := &Output{Task: task, Text: text}
+	for _, stage := range p.Stages {
+		if err := stage.Apply(ctx, out); err != nil {
+			return out, fmt.Errorf("pipeline %s: %s: %w", p.Name, stage.Name(), err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+func TestPipeline_Run(t *testing.T) {
+	dir := t.TempDir()
+	p, err := New("hosts", []config.PipelineStageConfig{
+		{Type: StageStripMarkdown},
+		{Type: StageExtractJSON},
+		{Type: StageValidateSchema, Schema: map[string]any{
+			"type":     "object",
+			"required": []any{"hosts"},
+			"properties": map[string]any{
+				"hosts": map[string]any{"type": "array", "minItems": 1, "items": map[string]any{"type": "string"}},
+			},
+		}},
+		{Type: StageStoreArtifact, Dir: dir},
+	}, "")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	text := "## Hosts\n\nI found these:\n\n```json\n{\"hosts\": [\"a.example.com\", \"b.example.com\"]}\n```"
+	out, err := p.Run(context.Background(), "parsing", text)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if out.Text != `{"hosts": ["a.example.com", "b.example.com"]}` {
+		t.Errorf("text = %q", out.Text)
+	}
+	if len(out.Artifacts) != 1 {
+		t.Fatalf("artifacts = %v, want one", out.Artifacts)
+	}
+	name := filepath.Base(out.Artifacts[0])
+	if filepath.Dir(out.Artifacts[0]) != dir || !strings.HasPrefix(name, "parsing-") || !strings.HasSuffix(name, ".json") {
+		t.Errorf("artifact path = %s", out.Artifacts[0])
+	}
+	data, err := os.ReadFile(out.Artifacts[0])
+	if err != nil || string(data) != out.Text {
+		t.Errorf("artifact = %q, %v", data, err)
+	}
+
+	// The same output is stored in the same file
+	again, err := p.Run(context.Background(), "parsing", text)
+	if err != nil || again.Artifacts[0] != out.Artifacts[0] {
+		t.Errorf("second run = %v, %v", again.Artifacts, err)
+	}
+
+	_, err = p.Run(context.Background(), "parsing", `{"hosts": []}`)
+	if err == nil || err.Error() != "pipeline hosts: validate_schema: $.hosts: expected at least 1 items, got 0" {
+		t.Errorf("invalid output error = %v", err)
+	}
+	_, err = p.Run(context.Background(), "parsing", "No hosts found.")
+	if err == nil || !strings.Contains(err.Error(), "pipeline hosts: extract_json:") {
+		t.Errorf("no JSON error = %v", err)
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	out := &Output{Text: "# Summary\n\n> **Port 22** runs `ssh`\n\n```\n# kept as is **here**\n```\n"}
+	if err := (stripMarkdown{}).Apply(context.Background(), out); err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	want := "Summary\n\nPort 22 runs ssh\n\n# kept as is **here**"
+	if out.Text != want {
+		t.Errorf("text = %q, want %q", out.Text, want)
+	}
+}
+
+func TestStoreArtifact_Text(t *testing.T) {
+	workspace := t.TempDir()
+	p, err := New("notes", []config.PipelineStageConfig{{Type: StageStoreArtifact}}, workspace)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	out, err := p.Run(context.Background(), "", "plain notes")
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if len(out.Artifacts) != 1 {
+		t.Fatalf("artifacts = %v, want one", out.Artifacts)
+	}
+	path := out.Artifacts[0]
+	if filepath.Dir(path) != filepath.Join(workspace, DefaultArtifactDir) ||
+		!strings.HasPrefix(filepath.Base(path), "output-") || !strings.HasSuffix(path, ".txt") {
+		t.Errorf("artifact path = %s", path)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := map[string]any{
+		"type":                 "object",
+		"required":             []any{"host", "ports"},
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"host":     map[string]any{"type": "string"},
+			"severity": map[string]any{"enum": []any{"low", "medium", "high"}},
+			"ports": map[string]any{
+				"type":     "array",
+				"maxItems": 2,
+				"items":    map[string]any{"type": []any{"integer", "null"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"host": "a", "ports": [22, null], "severity": "high"}`, ""},
+		{`{"host": "a"}`, `$: missing required property "ports"`},
+		{`{"host": 1, "ports": []}`, "$.host: expected string, got number"},
+		{`{"host": "a", "ports": [22.5]}`, "$.ports[0]: expected integer or null, got number"},
+		{`{"host": "a", "ports": [1, 2, 3]}`, "$.ports: expected at most 2 items, got 3"},
+		{`{"host": "a", "ports": [], "severity": "critical"}`, "$.severity: critical is not one of [low medium high]"},
+		{`{"host": "a", "ports": [], "extra": true}`, `$: unexpected property "extra"`},
+		{`[]`, "$: expected object, got array"},
+	}
+	for _, tt := range tests {
+		var value any
+		if err := json.Unmarshal([]byte(tt.input), &value); err != nil {
+			t.Fatalf("bad test input %s: %v", tt.input, err)
+		}
+		err := Validate(schema, value)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("Validate(%s) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBuild_SkipsInvalid(t *testing.T) {
+	configs := map[string][]config.PipelineStageConfig{
+		"ok":        {{Type: StageExtractJSON}},
+		"empty":     {},
+		"unknown":   {{Type: "translate"}},
+		"no_schema": {{Type: StageExtractJSON}, {Type: StageValidateSchema}},
+	}
+	pipelines := Build(configs, t.TempDir())
+	if len(pipelines) != 1 || pipelines["ok"] == nil {
+		t.Errorf("pipelines = %v, want only ok", pipelines)
+	}
+
+	for name, want := range map[string]string{
+		"empty":     "pipeline empty has no stages",
+		"unknown":   `pipeline unknown, stage 1: unknown stage type "translate"`,
+		"no_schema": "pipeline no_schema, stage 2: validate_schema needs a schema",
+	} {
+		if _, err := New(name, configs[name], ""); err == nil || err.Error() != want {
+			t.Errorf("New(%s) error = %v, want %q", name, err, want)
+		}
+	}
+}
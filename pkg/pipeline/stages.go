@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
+)
+
+func newStage(cfg config.PipelineStageConfig, workspace string) (Stage, error) {
+	switch cfg.Type {
+	case StageStripMarkdown:
+		return stripMarkdown{}, nil
+	case StageExtractJSON:
+		return extractJSON{}, nil
+	case StageValidateSchema:
+		if len(cfg.Schema) == 0 {
+			return nil, fmt.Errorf("%s needs a schema", StageValidateSchema)
+		}
+		return validateSchema{schema: cfg.Schema}, nil
+	case StageStoreArtifact:
+		dir := cfg.Dir
+		if dir == "" {
+			dir = DefaultArtifactDir
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workspace, dir)
+		}
+		return storeArtifact{dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown stage type %q", cfg.Type)
+	}
+}
+
+// headingPattern matches a markdown heading or blockquote marker
+var headingPattern = regexp.MustCompile(`^(#{1,6}|>)\s+`)
+
+// stripMarkdown unwraps fenced code blocks, keeping their contents as they
+// are, and drops heading, blockquote, bold and inline code markers from the
+// rest
+type stripMarkdown struct{}
+
+func (stripMarkdown) Name() string { return StageStripMarkdown }
+
+func (stripMarkdown) Apply(_ context.Context, out *Output) error {
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(out.Text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			line = headingPattern.ReplaceAllString(line, "")
+			line = strings.ReplaceAll(line, "**", "")
+			line = strings.ReplaceAll(line, "`", "")
+		}
+		lines = append(lines, line)
+	}
+	out.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+	return nil
+}
+
+// extractJSON keeps the first JSON object or array in the output, repairing
+// one cut off at max_tokens and JSON5-style syntax
+type extractJSON struct{}
+
+func (extractJSON) Name() string { return StageExtractJSON }
+
+func (extractJSON) Apply(_ context.Context, out *Output) error {
+	if value := llmjson.Extract(out.Text); value != "" {
+		out.Text = value
+		return nil
+	}
+	if value, ok := llmjson.Lenient(out.Text); ok {
+		out.Text = value
+		return nil
+	}
+	return llmjson.ErrNoJSON
+}
+
+// validateSchema fails unless the output is JSON matching the schema
+type validateSchema struct {
+	schema map[string]any
+}
+
+func (validateSchema) Name() string { return StageValidateSchema }
+
+func (s validateSchema) Apply(_ context.Context, out *Output) error {
+	var value any
+	if err := json.Unmarshal([]byte(out.Text), &value); err != nil {
+		return fmt.Errorf("output is not JSON: %w", err)
+	}
+	return Validate(s.schema, value)
+}
+
+// storeArtifact writes the output to a file named after the task and the
+// output's hash, so storing the same output twice writes one file
+type storeArtifact struct {
+	dir string
+}
+
+func (storeArtifact) Name() string { return StageStoreArtifact }
+
+func (s storeArtifact) Apply(_ context.Context, out *Output) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(out.Text))
+	ext := ".txt"
+	if json.Valid([]byte(out.Text)) {
+		ext = ".json"
+	}
+	task := out.Task
+	if task == "" {
+		task = "output"
+	}
+	path := filepath.Join(s.dir, task+"-"+hex.EncodeToString(sum[:6])+ext)
+	if err := os.WriteFile(path, []byte(out.Text), 0o644); err != nil {
+		return err
+	}
+	out.Artifacts = append(out.Artifacts, path)
+	return nil
+}
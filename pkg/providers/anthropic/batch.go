@@ -0,0 +1,133 @@
+package anthropicprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	BatchRequest = protocoltypes.BatchRequest
+	BatchResult  = protocoltypes.BatchResult
+)
+
+// SubmitBatch sends requests to the Message Batches API, which bills them at
+// half the interactive price in exchange for results arriving within 24
+// hours. It returns the batch ID to poll with BatchDone.
+func (p *Provider) SubmitBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	if len(requests) == 0 {
+		return "", fmt.Errorf("batch has no requests")
+	}
+	opts, err := p.requestOptions()
+	if err != nil {
+		return "", err
+	}
+
+	body := anthropic.MessageBatchNewParams{
+		Requests: make([]anthropic.MessageBatchNewParamsRequest, 0, len(requests)),
+	}
+	for _, req := range requests {
+		params, err := buildParams(req.Messages, req.Tools, req.Model, req.Options)
+		if err != nil {
+			return "", fmt.Errorf("batch request %s: %w", req.CustomID, err)
+		}
+		body.Requests = append(body.Requests, anthropic.MessageBatchNewParamsRequest{
+			CustomID: req.CustomID,
+			Params:   batchParams(params),
+		})
+	}
+
+	batch, err := p.client.Messages.Batches.New(ctx, body, opts...)
+	if err != nil {
+		return "", fmt.Errorf("claude batch submit: %w", wrapAPIError(err))
+	}
+	return batch.ID, nil
+}
+
+// BatchDone reports whether the batch has finished processing, successfully
+// or not, so its results can be fetched.
+func (p *Provider) BatchDone(ctx context.Context, batchID string) (bool, error) {
+	opts, err := p.requestOptions()
+	if err != nil {
+		return false, err
+	}
+	batch, err := p.client.Messages.Batches.Get(ctx, batchID, opts...)
+	if err != nil {
+		return false, fmt.Errorf("claude batch status: %w", wrapAPIError(err))
+	}
+	return batch.ProcessingStatus == anthropic.MessageBatchProcessingStatusEnded, nil
+}
+
+// BatchResults fetches the results of a finished batch. Results are not in
+// request order; match them on CustomID. options is applied the same way
+// Chat applies it, e.g. to unwrap structured output.
+func (p *Provider) BatchResults(ctx context.Context, batchID string, options map[string]any) ([]BatchResult, error) {
+	opts, err := p.requestOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := p.client.Messages.Batches.ResultsStreaming(ctx, batchID, opts...)
+	defer stream.Close()
+
+	schemaTool, structured := structuredOutputTool(options)
+	var results []BatchResult
+	for stream.Next() {
+		line := stream.Current()
+		result := BatchResult{CustomID: line.CustomID}
+		switch line.Result.Type {
+		case "succeeded":
+			message := line.Result.AsSucceeded().Message
+			result.Response = parseResponse(&message)
+			if structured {
+				unwrapStructuredOutput(result.Response, schemaTool.Function.Name)
+			}
+		case "errored":
+			result.Error = line.Result.AsErrored().Error.Error.Message
+			if result.Error == "" {
+				result.Error = "request errored"
+			}
+		default:
+			result.Error = fmt.Sprintf("request %s", line.Result.Type)
+		}
+		results = append(results, result)
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("claude batch results: %w", wrapAPIError(err))
+	}
+	return results, nil
+}
+
+// batchParams copies Messages API parameters into the batch request shape,
+// which has the same fields under a different type.
+func batchParams(params anthropic.MessageNewParams) anthropic.MessageBatchNewParamsRequestParams {
+	return anthropic.MessageBatchNewParamsRequestParams{
+		MaxTokens:     params.MaxTokens,
+		Messages:      params.Messages,
+		Model:         params.Model,
+		InferenceGeo:  params.InferenceGeo,
+		Temperature:   params.Temperature,
+		TopK:          params.TopK,
+		TopP:          params.TopP,
+		Metadata:      params.Metadata,
+		OutputConfig:  params.OutputConfig,
+		ServiceTier:   string(params.ServiceTier),
+		StopSequences: params.StopSequences,
+		System:        params.System,
+		Thinking:      params.Thinking,
+		ToolChoice:    params.ToolChoice,
+		Tools:         params.Tools,
+	}
+}
+
+func wrapAPIError(err error) error {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		return protocoltypes.WrapAPIError(apiErr.StatusCode, err)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package anthropicprovider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProvider_BatchRoundTrip(t *testing.T) {
+	var submitted []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batch := map[string]any{
+			"id":                "msgbatch_test",
+			"type":              "message_batch",
+			"processing_status": "ended",
+			"created_at":        "2026-01-01T00:00:00Z",
+			"expires_at":        "2026-01-02T00:00:00Z",
+			"request_counts":    map[string]any{"succeeded": 1, "errored": 1},
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches":
+			var body struct {
+				Requests []map[string]any `json:"requests"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			submitted = body.Requests
+			batch["processing_status"] = "in_progress"
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(batch)
+		case r.URL.Path == "/v1/messages/batches/msgbatch_test":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(batch)
+		case r.URL.Path == "/v1/messages/batches/msgbatch_test/results":
+			w.Header().Set("Content-Type", "application/x-jsonl")
+			enc := json.NewEncoder(w)
+			enc.Encode(map[string]any{
+				"custom_id": "b",
+				"result": map[string]any{
+					"type":  "errored",
+					"error": map[string]any{"type": "error", "error": map[string]any{"type": "invalid_request_error", "message": "prompt too long"}},
+				},
+			})
+			enc.Encode(map[string]any{
+				"custom_id": "a",
+				"result": map[string]any{
+					"type": "succeeded",
+					"message": map[string]any{
+						"id":          "msg_a",
+						"type":        "message",
+						"role":        "assistant",
+						"model":       "claude-sonnet-4.6",
+						"stop_reason": "end_turn",
+						"content":     []map[string]any{{"type": "text", "text": "Summary of finding A"}},
+						"usage":       map[string]any{"input_tokens": 20, "output_tokens": 5},
+					},
+				},
+			})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithClient(createAnthropicTestClient(server.URL, "test-token"))
+	batchID, err := provider.SubmitBatch(t.Context(), []BatchRequest{
+		{CustomID: "a", Messages: []Message{{Role: "user", Content: "Summarize A"}}, Model: "claude-sonnet-4.6"},
+		{CustomID: "b", Messages: []Message{{Role: "user", Content: "Summarize B"}}, Model: "claude-sonnet-4.6"},
+	})
+	if err != nil {
+		t.Fatalf("SubmitBatch() error: %v", err)
+	}
+	if batchID != "msgbatch_test" {
+		t.Errorf("batch ID = %q, want msgbatch_test", batchID)
+	}
+	if len(submitted) != 2 || submitted[0]["custom_id"] != "a" {
+		t.Fatalf("submitted requests = %v", submitted)
+	}
+	if params, _ := submitted[0]["params"].(map[string]any); params["model"] != "claude-sonnet-4.6" || params["max_tokens"] != float64(4096) {
+		t.Errorf("params = %v", params)
+	}
+
+	done, err := provider.BatchDone(t.Context(), batchID)
+	if err != nil || !done {
+		t.Fatalf("BatchDone() = %v, %v; want true", done, err)
+	}
+
+	results, err := provider.BatchResults(t.Context(), batchID, nil)
+	if err != nil {
+		t.Fatalf("BatchResults() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].CustomID != "b" || results[0].Response != nil || results[0].Error != "prompt too long" {
+		t.Errorf("errored result = %+v", results[0])
+	}
+	if results[1].Response == nil || results[1].Response.Content != "Summary of finding A" {
+		t.Errorf("succeeded result = %+v", results[1])
+	}
+}
+
+func TestProvider_SubmitBatchRejectsEmpty(t *testing.T) {
+	provider := NewProvider("test-token")
+	if _, err := provider.SubmitBatch(t.Context(), nil); err == nil {
+		t.Error("expected error for empty batch")
+	}
+}
@@ -21,6 +21,7 @@ type (
 	Message                = protocoltypes.Message
 	ToolDefinition         = protocoltypes.ToolDefinition
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+	ImagePart              = protocoltypes.ImagePart
 )
 
 const defaultBaseURL = "https://api.anthropic.com"
@@ -71,13 +72,9 @@ func (p *Provider) Chat(
 	model string,
 	options map[string]any,
 ) (*LLMResponse, error) {
-	var opts []option.RequestOption
-	if p.tokenSource != nil {
-		tok, err := p.tokenSource()
-		if err != nil {
-			return nil, fmt.Errorf("refreshing token: %w", err)
-		}
-		opts = append(opts, option.WithAuthToken(tok))
+	opts, err := p.requestOptions()
+	if err != nil {
+		return nil, err
 	}
 
 	params, err := buildParams(messages, tools, model, options)
@@ -87,10 +84,27 @@ func (p *Provider) Chat(
 
 	resp, err := p.client.Messages.New(ctx, params, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("claude API call: %w", err)
+		return nil, fmt.Errorf("claude API call: %w", wrapAPIError(err))
+	}
+
+	result := parseResponse(resp)
+	if schemaTool, ok := structuredOutputTool(options); ok {
+		unwrapStructuredOutput(result, schemaTool.Function.Name)
 	}
+	return result, nil
+}
 
-	return parseResponse(resp), nil
+// requestOptions returns per-request options, refreshing the auth token when
+// the provider has a token source.
+func (p *Provider) requestOptions() ([]option.RequestOption, error) {
+	if p.tokenSource == nil {
+		return nil, nil
+	}
+	tok, err := p.tokenSource()
+	if err != nil {
+		return nil, fmt.Errorf("refreshing token: %w", err)
+	}
+	return []option.RequestOption{option.WithAuthToken(tok)}, nil
 }
 
 func (p *Provider) GetDefaultModel() string {
@@ -101,6 +115,31 @@ func (p *Provider) BaseURL() string {
 	return p.baseURL
 }
 
+// toolResultBlock builds a tool_result block; images the tool returned
+// (screenshots, rendered pages) go inside it next to the text.
+func toolResultBlock(msg Message) anthropic.ContentBlockParamUnion {
+	block := anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)
+	for _, img := range msg.Images {
+		block.OfToolResult.Content = append(block.OfToolResult.Content,
+			anthropic.ToolResultBlockParamContentUnion{OfImage: imageBlock(img)})
+	}
+	return block
+}
+
+func imageBlock(img ImagePart) *anthropic.ImageBlockParam {
+	if img.URL != "" {
+		return &anthropic.ImageBlockParam{Source: anthropic.ImageBlockParamSourceUnion{
+			OfURL: &anthropic.URLImageSourceParam{URL: img.URL},
+		}}
+	}
+	return &anthropic.ImageBlockParam{Source: anthropic.ImageBlockParamSourceUnion{
+		OfBase64: &anthropic.Base64ImageSourceParam{
+			Data:      img.Data,
+			MediaType: anthropic.Base64ImageSourceMediaType(img.MediaType),
+		},
+	}}
+}
+
 func buildParams(
 	messages []Message,
 	tools []ToolDefinition,
@@ -109,6 +148,7 @@ func buildParams(
 ) (anthropic.MessageNewParams, error) {
 	var system []anthropic.TextBlockParam
 	var anthropicMessages []anthropic.MessageParam
+	budget := thinkingBudget(messages, options)
 
 	for _, msg := range messages {
 		switch msg.Role {
@@ -130,8 +170,17 @@ func buildParams(
 		case "user":
 			if msg.ToolCallID != "" {
 				anthropicMessages = append(anthropicMessages,
-					anthropic.NewUserMessage(anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)),
+					anthropic.NewUserMessage(toolResultBlock(msg)),
 				)
+			} else if len(msg.Images) > 0 {
+				var blocks []anthropic.ContentBlockParamUnion
+				if msg.Content != "" {
+					blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+				}
+				for _, img := range msg.Images {
+					blocks = append(blocks, anthropic.ContentBlockParamUnion{OfImage: imageBlock(img)})
+				}
+				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(blocks...))
 			} else {
 				anthropicMessages = append(anthropicMessages,
 					anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
@@ -140,6 +189,11 @@ func buildParams(
 		case "assistant":
 			if len(msg.ToolCalls) > 0 {
 				var blocks []anthropic.ContentBlockParamUnion
+				// With thinking on, a tool call must be replayed after the
+				// thinking that led to it
+				if budget > 0 && msg.ThinkingSignature != "" {
+					blocks = append(blocks, anthropic.NewThinkingBlock(msg.ThinkingSignature, msg.ReasoningContent))
+				}
 				if msg.Content != "" {
 					blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
 				}
@@ -154,7 +208,7 @@ func buildParams(
 			}
 		case "tool":
 			anthropicMessages = append(anthropicMessages,
-				anthropic.NewUserMessage(anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)),
+				anthropic.NewUserMessage(toolResultBlock(msg)),
 			)
 		}
 	}
@@ -174,20 +228,179 @@ func buildParams(
 		params.System = system
 	}
 
-	if temp, ok := options["temperature"].(float64); ok {
+	if budget > 0 {
+		// The budget is part of max_tokens, so leave room for the answer
+		if maxTokens <= budget {
+			params.MaxTokens = budget + maxTokens
+		}
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(budget)
+	} else if temp, ok := options["temperature"].(float64); ok {
+		// Thinking only runs at the default temperature
 		params.Temperature = anthropic.Float(temp)
 	}
 
 	if len(tools) > 0 {
 		params.Tools = translateTools(tools)
+		serial := false
+		if parallel, ok := options["parallel_tool_calls"].(bool); ok && !parallel {
+			serial = true
+		}
+		// tool_choice takes the OpenAI values; "required" is Anthropic's
+		// "any", which thinking does not allow
+		switch options["tool_choice"] {
+		case "required":
+			if budget > 0 {
+				params.ToolChoice = anthropic.ToolChoiceUnionParam{
+					OfAuto: &anthropic.ToolChoiceAutoParam{},
+				}
+				if serial {
+					params.ToolChoice.OfAuto.DisableParallelToolUse = anthropic.Bool(true)
+				}
+				break
+			}
+			params.ToolChoice = anthropic.ToolChoiceUnionParam{
+				OfAny: &anthropic.ToolChoiceAnyParam{},
+			}
+			if serial {
+				params.ToolChoice.OfAny.DisableParallelToolUse = anthropic.Bool(true)
+			}
+		case "none":
+			params.ToolChoice = anthropic.ToolChoiceUnionParam{
+				OfNone: &anthropic.ToolChoiceNoneParam{},
+			}
+		default:
+			params.ToolChoice = anthropic.ToolChoiceUnionParam{
+				OfAuto: &anthropic.ToolChoiceAutoParam{},
+			}
+			if serial {
+				params.ToolChoice.OfAuto.DisableParallelToolUse = anthropic.Bool(true)
+			}
+		}
+	}
+
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		params.StopSequences = stop
+	}
+
+	// A prompt cache key marks a conversation whose prefix repeats across
+	// requests. Cache it up to the last tool definition and up to the last
+	// message, so the next request only pays full price for its new turn.
+	// With the static system block that makes three of the four breakpoints
+	// Anthropic allows.
+	if cacheKey, ok := options["prompt_cache_key"].(string); ok && cacheKey != "" {
+		if len(params.Tools) > 0 {
+			if cc := params.Tools[len(params.Tools)-1].GetCacheControl(); cc != nil {
+				*cc = anthropic.NewCacheControlEphemeralParam()
+			}
+		}
+		markCacheBreakpoint(params.Messages)
+	}
+
+	// Anthropic has no response_format; emulate it by forcing a tool whose
+	// input schema is the requested response schema.
+	if schemaTool, ok := structuredOutputTool(options); ok {
+		params.Tools = append(params.Tools, translateTools([]ToolDefinition{schemaTool})...)
 		params.ToolChoice = anthropic.ToolChoiceUnionParam{
-			OfAuto: &anthropic.ToolChoiceAutoParam{},
+			OfTool: &anthropic.ToolChoiceToolParam{Name: schemaTool.Function.Name},
 		}
 	}
 
 	return params, nil
 }
 
+// minThinkingBudget is the smallest extended thinking budget Anthropic accepts
+const minThinkingBudget = 1024
+
+// thinkingBudget returns the extended thinking budget requested by the
+// thinking_budget option, or 0 when thinking must stay off: structured
+// output forces a tool call, which thinking does not allow, and a tool call
+// still awaiting its results must be replayed with the thinking that made
+// it, which a call from another model or a run without thinking lacks.
+func thinkingBudget(messages []Message, options map[string]any) int64 {
+	budget, ok := options["thinking_budget"].(int)
+	if !ok || budget <= 0 {
+		return 0
+	}
+	if _, structured := structuredOutputTool(options); structured {
+		return 0
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		if len(messages[i].ToolCalls) > 0 && messages[i].ThinkingSignature == "" {
+			return 0
+		}
+		break
+	}
+	return int64(max(budget, minThinkingBudget))
+}
+
+// markCacheBreakpoint sets cache_control on the last block of the last
+// message. Empty text blocks cannot carry one and are left alone.
+func markCacheBreakpoint(messages []anthropic.MessageParam) {
+	if len(messages) == 0 {
+		return
+	}
+	blocks := messages[len(messages)-1].Content
+	if len(blocks) == 0 {
+		return
+	}
+	last := blocks[len(blocks)-1]
+	if last.OfText != nil && last.OfText.Text == "" {
+		return
+	}
+	if cc := last.GetCacheControl(); cc != nil {
+		*cc = anthropic.NewCacheControlEphemeralParam()
+	}
+}
+
+// structuredOutputTool converts a requested ResponseSchema (or an
+// OpenAI-style json_schema response_format) into a tool definition used for
+// tool-forced structured output.
+func structuredOutputTool(options map[string]any) (ToolDefinition, bool) {
+	rs, ok := protocoltypes.ResponseSchemaFromOptions(options)
+	if !ok {
+		return ToolDefinition{}, false
+	}
+	name := rs.Name
+	if name == "" {
+		name = "structured_output"
+	}
+	desc := rs.Description
+	if desc == "" {
+		desc = "Respond by calling this tool with the requested structured output."
+	}
+	return ToolDefinition{
+		Type: "function",
+		Function: ToolFunctionDefinition{
+			Name:        name,
+			Description: desc,
+			Parameters:  rs.Schema,
+		},
+	}, true
+}
+
+// unwrapStructuredOutput moves the forced tool call's input into Content so
+// callers see the same JSON body an OpenAI-compatible endpoint would return.
+func unwrapStructuredOutput(resp *LLMResponse, toolName string) {
+	for i, tc := range resp.ToolCalls {
+		if tc.Name != toolName {
+			continue
+		}
+		data, err := json.Marshal(tc.Arguments)
+		if err != nil {
+			return
+		}
+		resp.Content = string(data)
+		resp.ToolCalls = append(resp.ToolCalls[:i], resp.ToolCalls[i+1:]...)
+		if len(resp.ToolCalls) == 0 {
+			resp.FinishReason = "stop"
+		}
+		return
+	}
+}
+
 func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 	result := make([]anthropic.ToolUnionParam, 0, len(tools))
 	for _, t := range tools {
@@ -200,7 +413,8 @@ func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 		if desc := t.Function.Description; desc != "" {
 			tool.Description = anthropic.String(desc)
 		}
-		if req, ok := t.Function.Parameters["required"].([]any); ok {
+		switch req := t.Function.Parameters["required"].(type) {
+		case []any:
 			required := make([]string, 0, len(req))
 			for _, r := range req {
 				if s, ok := r.(string); ok {
@@ -208,6 +422,8 @@ func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 				}
 			}
 			tool.InputSchema.Required = required
+		case []string:
+			tool.InputSchema.Required = req
 		}
 		result = append(result, anthropic.ToolUnionParam{OfTool: &tool})
 	}
@@ -215,11 +431,17 @@ func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 }
 
 func parseResponse(resp *anthropic.Message) *LLMResponse {
-	var content string
+	var content, thinking, signature string
 	var toolCalls []ToolCall
+	thinkingBlocks := 0
 
 	for _, block := range resp.Content {
 		switch block.Type {
+		case "thinking":
+			tb := block.AsThinking()
+			thinking += tb.Thinking
+			signature = tb.Signature
+			thinkingBlocks++
 		case "text":
 			tb := block.AsText()
 			content += tb.Text
@@ -246,17 +468,35 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		finishReason = "length"
 	case anthropic.StopReasonEndTurn:
 		finishReason = "stop"
+	case anthropic.StopReasonRefusal:
+		finishReason = "content_filter"
+	}
+
+	// A signature covers one block, so only a single block can be replayed
+	if thinkingBlocks != 1 {
+		signature = ""
 	}
 
 	return &LLMResponse{
-		Content:      content,
-		ToolCalls:    toolCalls,
-		FinishReason: finishReason,
-		Usage: &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
-		},
+		Content:           content,
+		ReasoningContent:  thinking,
+		ThinkingSignature: signature,
+		ToolCalls:         toolCalls,
+		FinishReason:      finishReason,
+		Usage:             usageInfo(resp.Usage),
+	}
+}
+
+// usageInfo normalizes Anthropic usage, whose input_tokens excludes cache
+// reads and writes, so PromptTokens counts the whole prompt
+func usageInfo(u anthropic.Usage) *UsageInfo {
+	prompt := int(u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens)
+	return &UsageInfo{
+		PromptTokens:       prompt,
+		CompletionTokens:   int(u.OutputTokens),
+		TotalTokens:        prompt + int(u.OutputTokens),
+		CachedPromptTokens: int(u.CacheReadInputTokens),
+		CacheWriteTokens:   int(u.CacheCreationInputTokens),
 	}
 }
 
@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestBuildParams_BasicMessage(t *testing.T) {
@@ -103,6 +106,234 @@ func TestBuildParams_WithTools(t *testing.T) {
 	}
 }
 
+func TestBuildParams_CacheBreakpoints(t *testing.T) {
+	tools := []ToolDefinition{
+		{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}},
+		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file"}},
+	}
+	messages := []Message{
+		{Role: "user", Content: "scan 10.0.0.5"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "exec", Arguments: map[string]any{"command": "nmap"}}}},
+		{Role: "tool", Content: "22/tcp open", ToolCallID: "c1"},
+	}
+
+	countBreakpoints := func(options map[string]any) int {
+		params, err := buildParams(messages, tools, "claude-sonnet-4.6", options)
+		if err != nil {
+			t.Fatalf("buildParams() error: %v", err)
+		}
+		data, err := json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(string(data), `"cache_control"`)
+	}
+
+	if got := countBreakpoints(map[string]any{}); got != 0 {
+		t.Errorf("breakpoints without a cache key = %d, want 0", got)
+	}
+	// The last tool and the trailing tool result
+	if got := countBreakpoints(map[string]any{"prompt_cache_key": "agent-1"}); got != 2 {
+		t.Errorf("breakpoints with a cache key = %d, want 2", got)
+	}
+}
+
+func TestBuildParams_DisableParallelToolUse(t *testing.T) {
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}}}
+	msgs := []Message{{Role: "user", Content: "Hi"}}
+
+	params, err := buildParams(msgs, tools, "claude-sonnet-4.6", map[string]any{"parallel_tool_calls": false})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if !params.ToolChoice.OfAuto.DisableParallelToolUse.Value {
+		t.Error("DisableParallelToolUse not set for parallel_tool_calls false")
+	}
+
+	params, err = buildParams(msgs, tools, "claude-sonnet-4.6", map[string]any{"parallel_tool_calls": true})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if params.ToolChoice.OfAuto.DisableParallelToolUse.Valid() {
+		t.Error("DisableParallelToolUse set for parallel_tool_calls true")
+	}
+}
+
+func TestBuildParams_ToolChoiceRequired(t *testing.T) {
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}}}
+	msgs := []Message{{Role: "user", Content: "Hi"}}
+	options := map[string]any{
+		"tool_choice":         "required",
+		"parallel_tool_calls": false,
+		"stop":                []string{"\n\n"},
+	}
+
+	params, err := buildParams(msgs, tools, "claude-sonnet-4.6", options)
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if params.ToolChoice.OfAny == nil {
+		t.Fatalf("ToolChoice = %+v, want any", params.ToolChoice)
+	}
+	if !params.ToolChoice.OfAny.DisableParallelToolUse.Value {
+		t.Error("DisableParallelToolUse not set for parallel_tool_calls false")
+	}
+	if len(params.StopSequences) != 1 || params.StopSequences[0] != "\n\n" {
+		t.Errorf("StopSequences = %q, want [\\n\\n]", params.StopSequences)
+	}
+}
+
+func TestBuildParams_ThinkingBudget(t *testing.T) {
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}}}
+	msgs := []Message{
+		{Role: "user", Content: "Scan it"},
+		{
+			Role:              "assistant",
+			ReasoningContent:  "Start with a port scan.",
+			ThinkingSignature: "sig-1",
+			ToolCalls:         []ToolCall{{ID: "call_1", Name: "exec", Arguments: map[string]any{"command": "nmap"}}},
+		},
+		{Role: "tool", Content: "22/tcp open", ToolCallID: "call_1"},
+	}
+	options := map[string]any{"thinking_budget": 500, "max_tokens": 1000, "temperature": 0.2, "tool_choice": "required"}
+
+	params, err := buildParams(msgs, tools, "claude-sonnet-4.6", options)
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if params.Thinking.OfEnabled == nil || params.Thinking.OfEnabled.BudgetTokens != 1024 {
+		t.Fatalf("Thinking = %+v, want enabled with the minimum budget", params.Thinking)
+	}
+	if params.MaxTokens != 2024 {
+		t.Errorf("MaxTokens = %d, want the budget plus room for the answer", params.MaxTokens)
+	}
+	if params.Temperature.Valid() {
+		t.Error("Temperature set with thinking on")
+	}
+	if params.ToolChoice.OfAuto == nil {
+		t.Errorf("ToolChoice = %+v, want auto since thinking rules out any", params.ToolChoice)
+	}
+	blocks := params.Messages[1].Content
+	if len(blocks) != 2 || blocks[0].OfThinking == nil || blocks[0].OfThinking.Signature != "sig-1" ||
+		blocks[0].OfThinking.Thinking != "Start with a port scan." {
+		t.Errorf("assistant blocks = %+v, want the thinking replayed before the tool call", blocks)
+	}
+
+	// A pending tool call without its thinking, e.g. from another model,
+	// keeps thinking off
+	msgs[1].ThinkingSignature = ""
+	params, err = buildParams(msgs, tools, "claude-sonnet-4.6", options)
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if params.Thinking.OfEnabled != nil || params.ToolChoice.OfAny == nil || !params.Temperature.Valid() {
+		t.Errorf("Thinking = %+v, ToolChoice = %+v, want thinking off", params.Thinking, params.ToolChoice)
+	}
+}
+
+func TestBuildParams_ResponseFormatForcesSchemaTool(t *testing.T) {
+	options := map[string]any{
+		"response_format": map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name": "verdict",
+				"schema": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"approved": map[string]any{"type": "boolean"},
+					},
+					"required": []string{"approved"},
+				},
+			},
+		},
+	}
+	params, err := buildParams([]Message{{Role: "user", Content: "Hi"}}, nil, "claude-sonnet-4.6", options)
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if len(params.Tools) != 1 || params.Tools[0].OfTool.Name != "verdict" {
+		t.Fatalf("expected forced schema tool, got %+v", params.Tools)
+	}
+	if params.Tools[0].OfTool.InputSchema.Required[0] != "approved" {
+		t.Errorf("Required = %v, want [approved]", params.Tools[0].OfTool.InputSchema.Required)
+	}
+	if params.ToolChoice.OfTool == nil || params.ToolChoice.OfTool.Name != "verdict" {
+		t.Errorf("expected tool_choice forced to verdict")
+	}
+
+	resp := &LLMResponse{
+		ToolCalls:    []ToolCall{{ID: "t1", Name: "verdict", Arguments: map[string]any{"approved": true}}},
+		FinishReason: "tool_calls",
+	}
+	unwrapStructuredOutput(resp, "verdict")
+	if resp.Content != `{"approved":true}` {
+		t.Errorf("Content = %q, want structured JSON", resp.Content)
+	}
+	if len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("expected forced tool call to be consumed, got %+v", resp)
+	}
+}
+
+func TestBuildParams_ResponseSchemaForcesSchemaTool(t *testing.T) {
+	options := map[string]any{
+		protocoltypes.ResponseSchemaOption: &protocoltypes.ResponseSchema{
+			Name:        "extract_findings",
+			Description: "Findings from tool output",
+			Schema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"findings": map[string]any{"type": "array"}},
+				"required":   []string{"findings"},
+			},
+		},
+	}
+	params, err := buildParams([]Message{{Role: "user", Content: "Hi"}}, nil, "claude-sonnet-4.6", options)
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if len(params.Tools) != 1 || params.Tools[0].OfTool.Name != "extract_findings" {
+		t.Fatalf("expected forced schema tool, got %+v", params.Tools)
+	}
+	if params.ToolChoice.OfTool == nil || params.ToolChoice.OfTool.Name != "extract_findings" {
+		t.Errorf("expected tool_choice forced to extract_findings")
+	}
+}
+
+func TestBuildParams_Images(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "Describe", Images: []ImagePart{
+			{MediaType: "image/png", Data: "aGVsbG8="},
+			{URL: "https://example.com/shot.jpg"},
+		}},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "screenshot", Arguments: map[string]any{}}}},
+		{Role: "tool", Content: "captured", ToolCallID: "call_1", Images: []ImagePart{{MediaType: "image/jpeg", Data: "aGk="}}},
+	}
+	params, err := buildParams(messages, nil, "claude-sonnet-4.6", map[string]any{})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+
+	user := params.Messages[0].Content
+	if len(user) != 3 {
+		t.Fatalf("user blocks = %d, want text + 2 images", len(user))
+	}
+	if user[1].OfImage == nil || user[1].OfImage.Source.OfBase64 == nil ||
+		user[1].OfImage.Source.OfBase64.MediaType != "image/png" {
+		t.Errorf("first image block = %+v, want base64 png", user[1].OfImage)
+	}
+	if user[2].OfImage == nil || user[2].OfImage.Source.OfURL == nil ||
+		user[2].OfImage.Source.OfURL.URL != "https://example.com/shot.jpg" {
+		t.Errorf("second image block = %+v, want URL source", user[2].OfImage)
+	}
+
+	result := params.Messages[2].Content[0].OfToolResult
+	if result == nil {
+		t.Fatal("expected a tool_result block")
+	}
+	if len(result.Content) != 2 || result.Content[1].OfImage == nil {
+		t.Fatalf("tool_result content = %+v, want text + image", result.Content)
+	}
+}
+
 func TestParseResponse_TextOnly(t *testing.T) {
 	resp := &anthropic.Message{
 		Content: []anthropic.ContentBlockUnion{},
@@ -123,6 +354,45 @@ func TestParseResponse_TextOnly(t *testing.T) {
 	}
 }
 
+func TestParseResponse_Thinking(t *testing.T) {
+	var resp anthropic.Message
+	raw := `{"content":[` +
+		`{"type":"thinking","thinking":"Port 22 is open.","signature":"sig-1"},` +
+		`{"type":"tool_use","id":"call_1","name":"exec","input":{"command":"ssh-audit"}}],` +
+		`"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":20}}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	result := parseResponse(&resp)
+	if result.ReasoningContent != "Port 22 is open." || result.ThinkingSignature != "sig-1" {
+		t.Errorf("ReasoningContent = %q, ThinkingSignature = %q", result.ReasoningContent, result.ThinkingSignature)
+	}
+	if result.Content != "" || len(result.ToolCalls) != 1 {
+		t.Errorf("Content = %q, ToolCalls = %+v", result.Content, result.ToolCalls)
+	}
+}
+
+func TestParseResponse_CacheUsage(t *testing.T) {
+	resp := &anthropic.Message{
+		Content: []anthropic.ContentBlockUnion{},
+		Usage: anthropic.Usage{
+			InputTokens:              100,
+			CacheReadInputTokens:     800,
+			CacheCreationInputTokens: 50,
+			OutputTokens:             20,
+		},
+	}
+	usage := parseResponse(resp).Usage
+	if usage.PromptTokens != 950 || usage.TotalTokens != 970 {
+		t.Errorf("PromptTokens = %d, TotalTokens = %d, want 950 and 970", usage.PromptTokens, usage.TotalTokens)
+	}
+	if usage.CachedPromptTokens != 800 || usage.CacheWriteTokens != 50 {
+		t.Errorf("CachedPromptTokens = %d, CacheWriteTokens = %d, want 800 and 50",
+			usage.CachedPromptTokens, usage.CacheWriteTokens)
+	}
+}
+
 func TestParseResponse_StopReasons(t *testing.T) {
 	tests := []struct {
 		stopReason anthropic.StopReason
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/auth"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
 const (
@@ -30,6 +31,7 @@ const (
 type AntigravityProvider struct {
 	tokenSource func() (string, string, error) // Returns (accessToken, projectID, error)
 	httpClient  *http.Client
+	validator   RequestValidator
 }
 
 // NewAntigravityProvider creates a new Antigravity provider using stored auth credentials.
@@ -52,6 +54,11 @@ func (p *AntigravityProvider) Chat(
 	model string,
 	options map[string]any,
 ) (*LLMResponse, error) {
+	messages, err := p.validator.Apply(messages, model)
+	if err != nil {
+		return nil, err
+	}
+
 	accessToken, projectID, err := p.tokenSource()
 	if err != nil {
 		return nil, fmt.Errorf("antigravity auth: %w", err)
@@ -127,7 +134,7 @@ func (p *AntigravityProvider) Chat(
 			"model":       model,
 		})
 
-		return nil, p.parseAntigravityError(resp.StatusCode, respBody)
+		return nil, protocoltypes.WrapAPIError(resp.StatusCode, p.parseAntigravityError(resp.StatusCode, respBody))
 	}
 
 	// Response is always SSE from streamGenerateContent — each line is "data: {...}"
@@ -147,6 +154,11 @@ func (p *AntigravityProvider) Chat(
 	return llmResp, nil
 }
 
+// SetRequestValidator replaces the pre-flight checks run on every request
+func (p *AntigravityProvider) SetRequestValidator(v RequestValidator) {
+	p.validator = v
+}
+
 // GetDefaultModel returns the default model identifier.
 func (p *AntigravityProvider) GetDefaultModel() string {
 	return antigravityDefaultModel
@@ -199,8 +211,10 @@ type antigravitySystemPrompt struct {
 }
 
 type antigravityGenConfig struct {
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
-	Temperature     float64 `json:"temperature,omitempty"`
+	MaxOutputTokens  int            `json:"maxOutputTokens,omitempty"`
+	Temperature      float64        `json:"temperature,omitempty"`
+	ResponseMIMEType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
 }
 
 func (p *AntigravityProvider) buildRequest(
@@ -321,7 +335,12 @@ func (p *AntigravityProvider) buildRequest(
 	if temp, ok := options["temperature"].(float64); ok {
 		config.Temperature = temp
 	}
-	if config.MaxOutputTokens > 0 || config.Temperature > 0 {
+	// Structured output: Gemini takes the schema in the generation config
+	if schema, ok := protocoltypes.ResponseSchemaFromOptions(options); ok {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseSchema = sanitizeSchemaForGemini(schema.Schema)
+	}
+	if config.MaxOutputTokens > 0 || config.Temperature > 0 || config.ResponseSchema != nil {
 		req.Config = config
 	}
 
@@ -398,9 +417,11 @@ type antigravityJSONResponse struct {
 		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
 	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-		TotalTokenCount      int `json:"totalTokenCount"`
+		PromptTokenCount        int `json:"promptTokenCount"`
+		CandidatesTokenCount    int `json:"candidatesTokenCount"`
+		TotalTokenCount         int `json:"totalTokenCount"`
+		CachedContentTokenCount int `json:"cachedContentTokenCount"`
+		ThoughtsTokenCount      int `json:"thoughtsTokenCount"` // Not included in candidatesTokenCount
 	} `json:"usageMetadata"`
 }
 
@@ -462,9 +483,11 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 
 		if resp.UsageMetadata.TotalTokenCount > 0 {
 			usage = &UsageInfo{
-				PromptTokens:     resp.UsageMetadata.PromptTokenCount,
-				CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
-				TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+				PromptTokens:       resp.UsageMetadata.PromptTokenCount,
+				CompletionTokens:   resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount,
+				TotalTokens:        resp.UsageMetadata.TotalTokenCount,
+				CachedPromptTokens: resp.UsageMetadata.CachedContentTokenCount,
+				ReasoningTokens:    resp.UsageMetadata.ThoughtsTokenCount,
 			}
 		}
 	}
@@ -473,8 +496,11 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 	if len(toolCalls) > 0 {
 		mappedFinish = "tool_calls"
 	}
-	if finishReason == "MAX_TOKENS" {
+	switch finishReason {
+	case "MAX_TOKENS":
 		mappedFinish = "length"
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII":
+		mappedFinish = "content_filter"
 	}
 
 	return &LLMResponse{
@@ -54,3 +54,33 @@ func TestResolveToolResponseNameInfersNameFromGeneratedCallID(t *testing.T) {
 		t.Fatalf("expected inferred tool name search_docs, got %q", got)
 	}
 }
+
+func TestBuildRequestSetsGeminiResponseSchema(t *testing.T) {
+	p := &AntigravityProvider{}
+
+	options := map[string]any{
+		ResponseSchemaOption: &ResponseSchema{
+			Name: "verdict",
+			Schema: map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"confidence": map[string]any{"type": "number", "minimum": 0}},
+				"additionalProperties": false,
+			},
+		},
+	}
+
+	req := p.buildRequest([]Message{{Role: "user", Content: "hi"}}, nil, "", options)
+	if req.Config == nil {
+		t.Fatal("expected generationConfig for structured output")
+	}
+	if req.Config.ResponseMIMEType != "application/json" {
+		t.Fatalf("responseMimeType = %q, want application/json", req.Config.ResponseMIMEType)
+	}
+	if _, ok := req.Config.ResponseSchema["additionalProperties"]; ok {
+		t.Fatal("expected unsupported keywords to be stripped from responseSchema")
+	}
+	props := req.Config.ResponseSchema["properties"].(map[string]any)
+	if _, ok := props["confidence"].(map[string]any)["minimum"]; ok {
+		t.Fatal("expected nested unsupported keywords to be stripped")
+	}
+}
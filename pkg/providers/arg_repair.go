@@ -0,0 +1,218 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// ArgRepairFunc asks a model to rewrite malformed tool call arguments as a
+// JSON object matching the tool's parameter schema. Use a cheap model; the
+// reply may still be wrapped in prose.
+type ArgRepairFunc func(ctx context.Context, toolName, raw string, schema map[string]any) (string, error)
+
+// ArgRepairStats counts malformed tool call arguments from one model and how
+// they were repaired
+type ArgRepairStats struct {
+	Malformed int // Calls whose arguments were not valid JSON
+	Lenient   int // Repaired by lenient parsing
+	LLM       int // Repaired by an LLM call
+	Failed    int // Left unrepaired
+}
+
+// RepairRate is the fraction of malformed calls that were repaired
+func (s ArgRepairStats) RepairRate() float64 {
+	if s.Malformed == 0 {
+		return 0
+	}
+	return float64(s.Lenient+s.LLM) / float64(s.Malformed)
+}
+
+// ArgRepairer repairs tool calls whose arguments providers could not decode
+// and left in arguments["raw"]. Lenient JSON5-style parsing is tried first;
+// an optional LLM repair call handles the rest. Safe for concurrent use.
+type ArgRepairer struct {
+	mu    sync.Mutex
+	stats map[string]*ArgRepairStats
+}
+
+// NewArgRepairer creates a repairer with empty statistics
+func NewArgRepairer() *ArgRepairer {
+	return &ArgRepairer{stats: make(map[string]*ArgRepairStats)}
+}
+
+// MalformedArguments returns the undecoded argument text of a tool call whose
+// provider failed to parse it
+func MalformedArguments(tc ToolCall) (string, bool) {
+	if len(tc.Arguments) != 1 {
+		return "", false
+	}
+	raw, ok := tc.Arguments["raw"].(string)
+	return raw, ok
+}
+
+// Repair fixes the malformed calls among calls in place, counting them
+// against model. llm may be nil to skip the LLM repair step. Calls that
+// cannot be repaired are left as they are.
+func (r *ArgRepairer) Repair(
+	ctx context.Context,
+	model string,
+	calls []ToolCall,
+	tools []ToolDefinition,
+	llm ArgRepairFunc,
+) {
+	for i := range calls {
+		raw, ok := MalformedArguments(calls[i])
+		if !ok {
+			continue
+		}
+		// A tool that really takes a single "raw" string parameter is fine
+		if schema := toolSchema(tools, calls[i].Name); hasProperty(schema, "raw") {
+			continue
+		}
+
+		method := "lenient"
+		args, err := decodeArguments(raw)
+		if err != nil && llm != nil {
+			method = "llm"
+			args, err = repairWithLLM(ctx, llm, calls[i].Name, raw, toolSchema(tools, calls[i].Name))
+		}
+		r.record(model, method, err == nil)
+
+		if err != nil {
+			logger.WarnCF("providers", "Could not repair tool call arguments", map[string]any{
+				"tool":  calls[i].Name,
+				"model": model,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		logger.InfoCF("providers", "Repaired malformed tool call arguments", map[string]any{
+			"tool":   calls[i].Name,
+			"model":  model,
+			"method": method,
+		})
+		calls[i].Arguments = args
+		if calls[i].Function != nil {
+			fixed, _ := json.Marshal(args)
+			calls[i].Function.Arguments = string(fixed)
+		}
+	}
+}
+
+// Stats returns a copy of the repair statistics, keyed by model
+func (r *ArgRepairer) Stats() map[string]ArgRepairStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]ArgRepairStats, len(r.stats))
+	for model, s := range r.stats {
+		out[model] = *s
+	}
+	return out
+}
+
+// FormatStats renders the repair statistics, one model per line
+func (r *ArgRepairer) FormatStats() string {
+	stats := r.Stats()
+	if len(stats) == 0 {
+		return "No malformed tool call arguments seen"
+	}
+	models := make([]string, 0, len(stats))
+	for model := range stats {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	out := "Tool argument repairs:"
+	for _, model := range models {
+		s := stats[model]
+		out += fmt.Sprintf("\n  %s: %d malformed, %d lenient, %d llm, %d failed (%.0f%% repaired)",
+			model, s.Malformed, s.Lenient, s.LLM, s.Failed, s.RepairRate()*100)
+	}
+	return out
+}
+
+func (r *ArgRepairer) record(model, method string, repaired bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[model]
+	if !ok {
+		s = &ArgRepairStats{}
+		r.stats[model] = s
+	}
+	s.Malformed++
+	switch {
+	case !repaired:
+		s.Failed++
+	case method == "llm":
+		s.LLM++
+	default:
+		s.Lenient++
+	}
+}
+
+func decodeArguments(raw string) (map[string]any, error) {
+	fixed, ok := llmjson.Lenient(raw)
+	if !ok {
+		return nil, fmt.Errorf("arguments are not JSON")
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(fixed), &args); err != nil || args == nil {
+		return nil, fmt.Errorf("arguments are not a JSON object")
+	}
+	return args, nil
+}
+
+func repairWithLLM(ctx context.Context, llm ArgRepairFunc, toolName, raw string, schema map[string]any) (map[string]any, error) {
+	reply, err := llm(ctx, toolName, raw, schema)
+	if err != nil {
+		return nil, fmt.Errorf("llm repair: %w", err)
+	}
+	args, err := decodeArguments(reply)
+	if err != nil {
+		if obj := llmjson.ExtractObject(reply); obj != "" {
+			return decodeArguments(obj)
+		}
+		return nil, fmt.Errorf("llm repair: %w", err)
+	}
+	return args, nil
+}
+
+// ArgRepairPrompt builds the prompt for an LLM repair call
+func ArgRepairPrompt(toolName, raw string, schema map[string]any) string {
+	schemaJSON, _ := json.Marshal(schema)
+	return fmt.Sprintf(`The arguments for a call to the tool %q are not valid JSON.
+Rewrite them as a single JSON object matching the tool's parameter schema. Keep every value exactly as intended; do not add or invent parameters.
+
+Schema:
+%s
+
+Malformed arguments:
+%s
+
+Reply with only the JSON object.`, toolName, schemaJSON, raw)
+}
+
+func toolSchema(tools []ToolDefinition, name string) map[string]any {
+	for _, t := range tools {
+		if t.Function.Name == name {
+			return t.Function.Parameters
+		}
+	}
+	return nil
+}
+
+func hasProperty(schema map[string]any, name string) bool {
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = props[name]
+	return ok
+}
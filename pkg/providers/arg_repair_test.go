@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArgRepairer_Repair(t *testing.T) {
+	tools := []ToolDefinition{
+		{Type: "function", Function: ToolFunctionDefinition{
+			Name: "read_file",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"path": map[string]any{"type": "string"}},
+			},
+		}},
+		{Type: "function", Function: ToolFunctionDefinition{
+			Name: "exec",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"command": map[string]any{"type": "string"}},
+			},
+		}},
+		{Type: "function", Function: ToolFunctionDefinition{
+			Name: "echo",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"raw": map[string]any{"type": "string"}},
+			},
+		}},
+	}
+
+	calls := []ToolCall{
+		{ID: "1", Name: "read_file", Arguments: map[string]any{"raw": `{'path': 'README.md',}`},
+			Function: &FunctionCall{Name: "read_file", Arguments: `{'path': 'README.md',}`}},
+		{ID: "2", Name: "exec", Arguments: map[string]any{"raw": `command=ls -la`}},
+		{ID: "3", Name: "echo", Arguments: map[string]any{"raw": `not json`}},
+		{ID: "4", Name: "read_file", Arguments: map[string]any{"path": "go.mod"}},
+	}
+
+	var llmCalls []string
+	llm := func(ctx context.Context, toolName, raw string, schema map[string]any) (string, error) {
+		llmCalls = append(llmCalls, toolName)
+		if schema == nil {
+			t.Errorf("expected the tool schema for %s", toolName)
+		}
+		return "Here you go:\n```json\n{\"command\": \"ls -la\"}\n```", nil
+	}
+
+	r := NewArgRepairer()
+	r.Repair(context.Background(), "local-qwen", calls, tools, llm)
+
+	if calls[0].Arguments["path"] != "README.md" {
+		t.Errorf("lenient repair: Arguments = %v", calls[0].Arguments)
+	}
+	if calls[0].Function.Arguments != `{"path":"README.md"}` {
+		t.Errorf("lenient repair: Function.Arguments = %q", calls[0].Function.Arguments)
+	}
+	if calls[1].Arguments["command"] != "ls -la" {
+		t.Errorf("llm repair: Arguments = %v", calls[1].Arguments)
+	}
+	if calls[2].Arguments["raw"] != "not json" {
+		t.Errorf("tool with a raw parameter should be left alone, got %v", calls[2].Arguments)
+	}
+	if len(llmCalls) != 1 || llmCalls[0] != "exec" {
+		t.Errorf("llm called for %v, want only exec", llmCalls)
+	}
+
+	stats := r.Stats()["local-qwen"]
+	if stats.Malformed != 2 || stats.Lenient != 1 || stats.LLM != 1 || stats.Failed != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.RepairRate() != 1 {
+		t.Errorf("RepairRate() = %v, want 1", stats.RepairRate())
+	}
+}
+
+func TestArgRepairer_RecordsFailures(t *testing.T) {
+	r := NewArgRepairer()
+	calls := []ToolCall{{ID: "1", Name: "exec", Arguments: map[string]any{"raw": `command=ls`}}}
+
+	// Without an LLM step the call stays malformed
+	r.Repair(context.Background(), "m", calls, nil, nil)
+	if _, ok := MalformedArguments(calls[0]); !ok {
+		t.Fatalf("expected call to stay malformed, got %v", calls[0].Arguments)
+	}
+
+	failing := func(ctx context.Context, toolName, raw string, schema map[string]any) (string, error) {
+		return "", errors.New("rate limited")
+	}
+	r.Repair(context.Background(), "m", calls, nil, failing)
+
+	stats := r.Stats()["m"]
+	if stats.Malformed != 2 || stats.Failed != 2 || stats.RepairRate() != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if out := r.FormatStats(); !strings.Contains(out, "m: 2 malformed") {
+		t.Errorf("FormatStats() = %q", out)
+	}
+}
@@ -0,0 +1,49 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// maxBatchPollErrors is how many status checks in a row may fail before
+// WaitForBatch gives up; batches run for hours, so one network blip should
+// not lose the results.
+const maxBatchPollErrors = 5
+
+// WaitForBatch polls the batch every interval until it has finished, then
+// returns its results. It returns early with ctx's error when ctx is done.
+func WaitForBatch(
+	ctx context.Context,
+	provider BatchProvider,
+	batchID string,
+	interval time.Duration,
+	options map[string]any,
+) ([]BatchResult, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		done, err := provider.BatchDone(ctx, batchID)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			failures++
+			if failures >= maxBatchPollErrors {
+				return nil, err
+			}
+		case err != nil:
+			return nil, ctx.Err()
+		default:
+			failures = 0
+		}
+		if done {
+			return provider.BatchResults(ctx, batchID, options)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
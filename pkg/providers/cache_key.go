@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+)
+
+// PromptCacheKey returns the prompt_cache_key for one conversation: prefix
+// plus a short hash of the session key, so keys stay short and do not leak
+// channel or chat IDs. Requests with the same key share a prompt prefix.
+// OpenAI routes them to the same prefix cache, and the Anthropic adapter
+// adds conversation cache breakpoints when a key is set.
+func PromptCacheKey(prefix, sessionKey string) string {
+	if sessionKey == "" {
+		return prefix
+	}
+	sum := sha256.Sum256([]byte(sessionKey))
+	return prefix + "-" + hex.EncodeToString(sum[:8])
+}
+
+// WithPromptCacheKey returns options with prompt_cache_key set to key unless
+// the caller already set one. options is not modified.
+func WithPromptCacheKey(options map[string]any, key string) map[string]any {
+	if key == "" {
+		return options
+	}
+	if existing, ok := options["prompt_cache_key"].(string); ok && existing != "" {
+		return options
+	}
+	merged := make(map[string]any, len(options)+1)
+	maps.Copy(merged, options)
+	merged["prompt_cache_key"] = key
+	return merged
+}
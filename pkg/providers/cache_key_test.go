@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptCacheKey(t *testing.T) {
+	a := PromptCacheKey("main", "telegram:12345")
+	if !strings.HasPrefix(a, "main-") || len(a) != len("main-")+16 || strings.Contains(a, "12345") {
+		t.Errorf("PromptCacheKey() = %q, want main- plus a 16 character hash", a)
+	}
+	if a != PromptCacheKey("main", "telegram:12345") || a == PromptCacheKey("main", "telegram:99") {
+		t.Error("keys must be stable per session and differ between sessions")
+	}
+	if got := PromptCacheKey("main", ""); got != "main" {
+		t.Errorf("PromptCacheKey() without a session = %q, want main", got)
+	}
+}
+
+func TestWithPromptCacheKey(t *testing.T) {
+	opts := map[string]any{"temperature": 0.2}
+	got := WithPromptCacheKey(opts, "k")
+	if got["prompt_cache_key"] != "k" || got["temperature"] != 0.2 {
+		t.Errorf("WithPromptCacheKey() = %v", got)
+	}
+	if _, ok := opts["prompt_cache_key"]; ok {
+		t.Error("caller options were modified")
+	}
+	if got := WithPromptCacheKey(map[string]any{"prompt_cache_key": "mine"}, "k"); got["prompt_cache_key"] != "mine" {
+		t.Errorf("existing key replaced: %v", got)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
 )
 
 // ClaudeCliProvider implements LLMProvider using the claude CLI as a subprocess.
@@ -161,9 +163,11 @@ func (p *ClaudeCliProvider) parseClaudeCliResponse(output string) (*LLMResponse,
 	var usage *UsageInfo
 	if resp.Usage.InputTokens > 0 || resp.Usage.OutputTokens > 0 {
 		usage = &UsageInfo{
-			PromptTokens:     resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens,
-			CompletionTokens: resp.Usage.OutputTokens,
-			TotalTokens:      resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens + resp.Usage.OutputTokens,
+			PromptTokens:       resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens,
+			CompletionTokens:   resp.Usage.OutputTokens,
+			TotalTokens:        resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens + resp.Usage.OutputTokens,
+			CachedPromptTokens: resp.Usage.CacheReadInputTokens,
+			CacheWriteTokens:   resp.Usage.CacheCreationInputTokens,
 		}
 	}
 
@@ -186,17 +190,10 @@ func (p *ClaudeCliProvider) stripToolCallsJSON(text string) string {
 }
 
 // findMatchingBrace finds the index after the closing brace matching the opening brace at pos.
+// Braces inside JSON strings are ignored. Returns pos if the brace is never closed.
 func findMatchingBrace(text string, pos int) int {
-	depth := 0
-	for i := pos; i < len(text); i++ {
-		if text[i] == '{' {
-			depth++
-		} else if text[i] == '}' {
-			depth--
-			if depth == 0 {
-				return i + 1
-			}
-		}
+	if end := llmjson.End(text, pos); end != -1 {
+		return end
 	}
 	return pos
 }
@@ -8,7 +8,8 @@ import (
 )
 
 type ClaudeProvider struct {
-	delegate *anthropicprovider.Provider
+	delegate  *anthropicprovider.Provider
+	validator RequestValidator
 }
 
 func NewClaudeProvider(token string) *ClaudeProvider {
@@ -44,6 +45,10 @@ func newClaudeProviderWithDelegate(delegate *anthropicprovider.Provider) *Claude
 func (p *ClaudeProvider) Chat(
 	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
 ) (*LLMResponse, error) {
+	messages, err := p.validator.Apply(messages, model)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
 	if err != nil {
 		return nil, err
@@ -51,6 +56,11 @@ func (p *ClaudeProvider) Chat(
 	return resp, nil
 }
 
+// SetRequestValidator replaces the pre-flight checks run on every request
+func (p *ClaudeProvider) SetRequestValidator(v RequestValidator) {
+	p.validator = v
+}
+
 func (p *ClaudeProvider) GetDefaultModel() string {
 	return p.delegate.GetDefaultModel()
 }
@@ -67,3 +77,17 @@ func createClaudeTokenSource() func() (string, error) {
 		return cred.AccessToken, nil
 	}
 }
+
+func (p *ClaudeProvider) SubmitBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	return p.delegate.SubmitBatch(ctx, requests)
+}
+
+func (p *ClaudeProvider) BatchDone(ctx context.Context, batchID string) (bool, error) {
+	return p.delegate.BatchDone(ctx, batchID)
+}
+
+func (p *ClaudeProvider) BatchResults(
+	ctx context.Context, batchID string, options map[string]any,
+) ([]BatchResult, error) {
+	return p.delegate.BatchResults(ctx, batchID, options)
+}
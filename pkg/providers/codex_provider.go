@@ -10,9 +10,11 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/auth"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
 const (
@@ -25,6 +27,7 @@ type CodexProvider struct {
 	accountID       string
 	tokenSource     func() (string, string, error)
 	enableWebSearch bool
+	validator       RequestValidator
 }
 
 const defaultCodexInstructions = "You are Codex, a coding assistant."
@@ -58,6 +61,11 @@ func NewCodexProviderWithTokenSource(
 func (p *CodexProvider) Chat(
 	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
 ) (*LLMResponse, error) {
+	messages, err := p.validator.Apply(messages, model)
+	if err != nil {
+		return nil, err
+	}
+
 	var opts []option.RequestOption
 	accountID := p.accountID
 	resolvedModel, fallbackReason := resolveCodexModel(model)
@@ -111,7 +119,7 @@ func (p *CodexProvider) Chat(
 			}
 		}
 	}
-	err := stream.Err()
+	err = stream.Err()
 	if err != nil {
 		fields := map[string]any{
 			"requested_model":    model,
@@ -136,6 +144,9 @@ func (p *CodexProvider) Chat(
 			}
 		}
 		logger.ErrorCF("provider.codex", "Codex API call failed", fields)
+		if apiErr != nil {
+			err = protocoltypes.WrapAPIError(apiErr.StatusCode, err)
+		}
 		return nil, fmt.Errorf("codex API call: %w", err)
 	}
 	if resp == nil {
@@ -153,6 +164,11 @@ func (p *CodexProvider) Chat(
 	return parseCodexResponse(resp), nil
 }
 
+// SetRequestValidator replaces the pre-flight checks run on every request
+func (p *CodexProvider) SetRequestValidator(v RequestValidator) {
+	p.validator = v
+}
+
 func (p *CodexProvider) GetDefaultModel() string {
 	return codexDefaultModel
 }
@@ -301,6 +317,10 @@ func buildCodexParams(
 		params.PromptCacheKey = openai.Opt(cacheKey)
 	}
 
+	if effort, ok := options["reasoning_effort"].(string); ok && effort != "" {
+		params.Reasoning = shared.ReasoningParam{Effort: shared.ReasoningEffort(effort)}
+	}
+
 	if len(tools) > 0 || enableWebSearch {
 		params.Tools = translateToolsForCodex(tools, enableWebSearch)
 	}
@@ -397,9 +417,11 @@ func parseCodexResponse(resp *responses.Response) *LLMResponse {
 	var usage *UsageInfo
 	if resp.Usage.TotalTokens > 0 {
 		usage = &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
+			PromptTokens:       int(resp.Usage.InputTokens),
+			CompletionTokens:   int(resp.Usage.OutputTokens),
+			TotalTokens:        int(resp.Usage.TotalTokens),
+			CachedPromptTokens: int(resp.Usage.InputTokensDetails.CachedTokens),
+			ReasoningTokens:    int(resp.Usage.OutputTokensDetails.ReasoningTokens),
 		}
 	}
 
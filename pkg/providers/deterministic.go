@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrCassetteMiss is returned in replay mode when the cassette has no
+// recorded response for a request. It usually means the run diverged from
+// the recording (a changed prompt, tool list or workflow).
+var ErrCassetteMiss = errors.New("no recorded response in cassette")
+
+// Cassette holds recorded LLM interactions keyed by a hash of the request.
+type Cassette struct {
+	Version      int                   `json:"version"`
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// CassetteInteraction is one recorded Chat call.
+type CassetteInteraction struct {
+	Key      string       `json:"key"`
+	Model    string       `json:"model"`
+	Response *LLMResponse `json:"response,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// DeterministicProvider wraps a provider for seeded runs. It adds the seed
+// to every request and, with a cassette, records calls to it or replays them
+// from it. Identical requests are served in the order they were recorded.
+type DeterministicProvider struct {
+	inner LLMProvider
+	seed  int64
+	path  string
+	mode  string
+
+	mu       sync.Mutex
+	cassette Cassette
+	served   map[string]int // Replay cursor per request key
+}
+
+// NewDeterministicProvider wraps inner according to cfg. In replay mode the
+// cassette must exist; inner may then be nil, so replays need no API keys.
+func NewDeterministicProvider(inner LLMProvider, cfg config.DeterminismConfig) (*DeterministicProvider, error) {
+	p := &DeterministicProvider{
+		inner:  inner,
+		seed:   cfg.Seed,
+		path:   cfg.Cassette,
+		mode:   cfg.CassetteMode,
+		served: make(map[string]int),
+	}
+	if p.path == "" {
+		if inner == nil {
+			return nil, fmt.Errorf("deterministic provider needs a provider or a cassette")
+		}
+		return p, nil
+	}
+
+	switch p.mode {
+	case CassetteReplay:
+		data, err := os.ReadFile(p.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &p.cassette); err != nil {
+			return nil, fmt.Errorf("parsing cassette %s: %w", p.path, err)
+		}
+	case CassetteRecord:
+		if inner == nil {
+			return nil, fmt.Errorf("recording a cassette needs a provider")
+		}
+		p.cassette.Version = 1
+	default:
+		return nil, fmt.Errorf("unknown cassette_mode %q (expected %q or %q)", p.mode, CassetteRecord, CassetteReplay)
+	}
+	return p, nil
+}
+
+func (p *DeterministicProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	seeded := make(map[string]any, len(options)+1)
+	for k, v := range options {
+		seeded[k] = v
+	}
+	if _, ok := seeded["seed"]; !ok {
+		seeded["seed"] = p.seed
+	}
+
+	if p.path == "" {
+		return p.inner.Chat(ctx, messages, tools, model, seeded)
+	}
+
+	key, err := cassetteKey(messages, tools, model, seeded)
+	if err != nil {
+		return nil, err
+	}
+	if p.mode == CassetteReplay {
+		return p.replay(key, model)
+	}
+
+	resp, chatErr := p.inner.Chat(ctx, messages, tools, model, seeded)
+	interaction := CassetteInteraction{Key: key, Model: model, Response: resp}
+	if chatErr != nil {
+		interaction.Error = chatErr.Error()
+	}
+	if err := p.record(interaction); err != nil {
+		return nil, err
+	}
+	return resp, chatErr
+}
+
+func (p *DeterministicProvider) GetDefaultModel() string {
+	if p.inner == nil {
+		return ""
+	}
+	return p.inner.GetDefaultModel()
+}
+
+func (p *DeterministicProvider) replay(key, model string) (*LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	skip := p.served[key]
+	for _, interaction := range p.cassette.Interactions {
+		if interaction.Key != key {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		p.served[key]++
+		if interaction.Error != "" {
+			return nil, errors.New(interaction.Error)
+		}
+		resp := *interaction.Response
+		return &resp, nil
+	}
+	return nil, fmt.Errorf("%w (model %s, key %s)", ErrCassetteMiss, model, key[:12])
+}
+
+// record appends an interaction and rewrites the cassette, so a crashed run
+// still leaves everything recorded up to that point
+func (p *DeterministicProvider) record(interaction CassetteInteraction) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cassette.Interactions = append(p.cassette.Interactions, interaction)
+	data, err := json.MarshalIndent(p.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if dir := filepath.Dir(p.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating cassette directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(p.path, data, 0o644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+// cassetteKey hashes everything that shapes a response. Maps marshal with
+// sorted keys, so the key is stable across runs.
+func cassetteKey(messages []Message, tools []ToolDefinition, model string, options map[string]any) (string, error) {
+	data, err := json.Marshal(struct {
+		Model    string           `json:"model"`
+		Messages []Message        `json:"messages"`
+		Tools    []ToolDefinition `json:"tools"`
+		Options  map[string]any   `json:"options"`
+	}{model, messages, tools, options})
+	if err != nil {
+		return "", fmt.Errorf("hashing request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// countingProvider answers with the call number and remembers the options
+// it was sent.
+type countingProvider struct {
+	calls   int
+	options map[string]any
+}
+
+func (p *countingProvider) Chat(_ context.Context, _ []Message, _ []ToolDefinition, _ string, options map[string]any) (*LLMResponse, error) {
+	p.calls++
+	p.options = options
+	return &LLMResponse{Content: fmt.Sprintf("answer %d", p.calls), FinishReason: "stop"}, nil
+}
+
+func (p *countingProvider) GetDefaultModel() string { return "mock" }
+
+func TestDeterministicProvider_AddsSeed(t *testing.T) {
+	inner := &countingProvider{}
+	p, err := NewDeterministicProvider(inner, config.DeterminismConfig{Enabled: true, Seed: 7})
+	if err != nil {
+		t.Fatalf("NewDeterministicProvider: %v", err)
+	}
+
+	if _, err := p.Chat(context.Background(), nil, nil, "m", map[string]any{"temperature": 0.0}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if inner.options["seed"] != int64(7) {
+		t.Errorf("seed option = %v, want 7", inner.options["seed"])
+	}
+
+	if _, err := p.Chat(context.Background(), nil, nil, "m", map[string]any{"seed": 3}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if inner.options["seed"] != 3 {
+		t.Errorf("explicit seed overridden: %v", inner.options["seed"])
+	}
+}
+
+func TestDeterministicProvider_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "run.json")
+	msgs := []Message{{Role: "user", Content: "scan it"}}
+	opts := map[string]any{"max_tokens": 100}
+
+	recorder, err := NewDeterministicProvider(&countingProvider{}, config.DeterminismConfig{
+		Enabled: true, Seed: 1, Cassette: path, CassetteMode: CassetteRecord,
+	})
+	if err != nil {
+		t.Fatalf("record provider: %v", err)
+	}
+	var recorded []string
+	for _, m := range []string{"m", "m", "other"} {
+		resp, err := recorder.Chat(context.Background(), msgs, nil, m, opts)
+		if err != nil {
+			t.Fatalf("record Chat: %v", err)
+		}
+		recorded = append(recorded, resp.Content)
+	}
+
+	// Replay needs no inner provider
+	player, err := NewDeterministicProvider(nil, config.DeterminismConfig{
+		Enabled: true, Seed: 1, Cassette: path, CassetteMode: CassetteReplay,
+	})
+	if err != nil {
+		t.Fatalf("replay provider: %v", err)
+	}
+	for i, m := range []string{"m", "m", "other"} {
+		resp, err := player.Chat(context.Background(), msgs, nil, m, opts)
+		if err != nil {
+			t.Fatalf("replay Chat %d: %v", i, err)
+		}
+		if resp.Content != recorded[i] {
+			t.Errorf("replay %d = %q, want %q", i, resp.Content, recorded[i])
+		}
+	}
+
+	// A third identical request was never recorded
+	if _, err := player.Chat(context.Background(), msgs, nil, "m", opts); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("exhausted key error = %v, want ErrCassetteMiss", err)
+	}
+	// Neither was a different prompt
+	other := []Message{{Role: "user", Content: "scan something else"}}
+	if _, err := player.Chat(context.Background(), other, nil, "m", opts); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("unknown request error = %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestDeterministicProvider_InvalidConfig(t *testing.T) {
+	if _, err := NewDeterministicProvider(nil, config.DeterminismConfig{Enabled: true}); err == nil {
+		t.Error("expected an error without a provider or cassette")
+	}
+	if _, err := NewDeterministicProvider(&countingProvider{}, config.DeterminismConfig{Cassette: "x.json", CassetteMode: "rewind"}); err == nil {
+		t.Error("expected an error for an unknown cassette mode")
+	}
+	if _, err := NewDeterministicProvider(nil, config.DeterminismConfig{Cassette: filepath.Join(t.TempDir(), "missing.json"), CassetteMode: CassetteReplay}); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+}
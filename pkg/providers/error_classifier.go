@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
 )
@@ -116,6 +117,27 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Rejected by request validation: another model gets the same request
+	if errors.Is(err, ErrInvalidRequest) {
+		return &FailoverError{
+			Reason:   FailoverFormat,
+			Provider: provider,
+			Model:    model,
+			Wrapped:  err,
+		}
+	}
+
+	// Typed provider errors carry their kind; trust it over message heuristics.
+	if reason, status := classifyByKind(err); reason != "" {
+		return &FailoverError{
+			Reason:   reason,
+			Provider: provider,
+			Model:    model,
+			Status:   status,
+			Wrapped:  err,
+		}
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Image dimension/size errors: non-retriable, non-fallback.
@@ -154,6 +176,27 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 	return nil
 }
 
+// classifyByKind maps a typed provider error to a FailoverReason.
+func classifyByKind(err error) (FailoverReason, int) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind == nil {
+		return "", 0
+	}
+	switch apiErr.Kind {
+	case ErrAuthFailed:
+		return FailoverAuth, apiErr.StatusCode
+	case ErrRateLimited:
+		return FailoverRateLimit, apiErr.StatusCode
+	case ErrOverloaded:
+		return FailoverOverloaded, apiErr.StatusCode
+	case ErrContextTooLong:
+		return FailoverContext, apiErr.StatusCode
+	case ErrContentFiltered:
+		return FailoverFiltered, apiErr.StatusCode
+	}
+	return "", 0
+}
+
 // classifyByStatus maps HTTP status codes to FailoverReason.
 func classifyByStatus(status int) FailoverReason {
 	switch {
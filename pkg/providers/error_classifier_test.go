@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestClassifyError_Nil(t *testing.T) {
@@ -335,3 +337,77 @@ func TestIsImageSizeError(t *testing.T) {
 		t.Error("should not match normal error")
 	}
 }
+
+func TestClassifyError_TypedAPIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		reason FailoverReason
+		kind   error
+	}{
+		{"auth", 401, `{"error":"bad key"}`, FailoverAuth, ErrAuthFailed},
+		{"rate limit", 429, `{"error":"slow down"}`, FailoverRateLimit, ErrRateLimited},
+		{"overloaded", 529, `{"type":"overloaded_error"}`, FailoverOverloaded, ErrOverloaded},
+		{"context", 400, `{"error":{"code":"context_length_exceeded"}}`, FailoverContext, ErrContextTooLong},
+		{"filtered", 400, `{"error":{"code":"content_filter"}}`, FailoverFiltered, ErrContentFiltered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("chat: %w", protocoltypes.NewAPIError(tt.status, tt.body))
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("errors.Is(%v) = false", tt.kind)
+			}
+			result := ClassifyError(err, "openai", "gpt-4")
+			if result == nil {
+				t.Fatal("expected non-nil")
+			}
+			if result.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", result.Reason, tt.reason)
+			}
+			if result.Status != tt.status {
+				t.Errorf("status = %d, want %d", result.Status, tt.status)
+			}
+			if !result.IsRetriable() {
+				t.Error("typed provider errors should allow fallback")
+			}
+		})
+	}
+}
+
+func TestClassifyError_UntypedBadRequestStaysFormat(t *testing.T) {
+	err := protocoltypes.NewAPIError(400, `{"error":"invalid request format"}`)
+	if err.Kind != nil {
+		t.Fatalf("kind = %v, want nil", err.Kind)
+	}
+	result := ClassifyError(err, "openai", "gpt-4")
+	if result == nil || result.Reason != FailoverFormat {
+		t.Fatalf("result = %+v, want format", result)
+	}
+}
+
+func TestFailoverError_IsSentinel(t *testing.T) {
+	// Reason inferred from message text still matches the typed sentinel.
+	result := ClassifyError(errors.New("invalid api key"), "openai", "gpt-4")
+	if result == nil {
+		t.Fatal("expected non-nil")
+	}
+	if !errors.Is(result, ErrAuthFailed) {
+		t.Error("expected auth failover to match ErrAuthFailed")
+	}
+	if errors.Is(result, ErrRateLimited) {
+		t.Error("auth failover should not match ErrRateLimited")
+	}
+	if !IsPermanentError(result) {
+		t.Error("auth failure should be permanent")
+	}
+
+	exhausted := &FallbackExhaustedError{Attempts: []FallbackAttempt{
+		{Provider: "openai", Error: &FailoverError{Reason: FailoverTimeout}},
+		{Provider: "anthropic", Error: &FailoverError{Reason: FailoverContext}},
+	}}
+	if !errors.Is(exhausted, ErrContextTooLong) {
+		t.Error("exhausted chain should expose ErrContextTooLong from its attempts")
+	}
+}
@@ -7,9 +7,13 @@ package providers
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/llamacpp"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
 )
 
 // createClaudeAuthProvider creates a Claude provider using OAuth credentials from auth store.
@@ -53,9 +57,30 @@ func ExtractProtocol(model string) (protocol, modelID string) {
 
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
-// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot, llamacpp, replay
 // Returns the provider, the model ID (without protocol prefix), and any error.
+// Providers that call a remote API validate every request first, as set by
+// the model's request_validation.
 func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
+	if cfg != nil {
+		switch cfg.RequestValidation {
+		case "", ValidationRepair, ValidationStrict, ValidationOff:
+		default:
+			return nil, "", fmt.Errorf("model %s: unknown request_validation %q (expected repair, strict or off)",
+				cfg.ModelName, cfg.RequestValidation)
+		}
+	}
+	provider, modelID, err := createProviderFromConfig(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	if v, ok := provider.(interface{ SetRequestValidator(RequestValidator) }); ok {
+		v.SetRequestValidator(NewRequestValidator(cfg))
+	}
+	return provider, modelID, nil
+}
+
+func createProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	if cfg == nil {
 		return nil, "", fmt.Errorf("config is nil")
 	}
@@ -84,12 +109,17 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return NewHTTPProviderWithMaxTokensField(cfg.APIKey, apiBase, cfg.Proxy, cfg.MaxTokensField), modelID, nil
+		provider, err := newHTTPProviderFromConfig(cfg, apiBase)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
 
 	case "openrouter", "groq", "zhipu", "gemini", "nvidia",
 		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
-		"volcengine", "vllm", "qwen", "mistral":
+		"volcengine", "vllm", "qwen", "mistral", "together", "xai":
 		// All other OpenAI-compatible HTTP providers
+		cfg = withProviderPreset(cfg, protocol)
 		if cfg.APIKey == "" && cfg.APIBase == "" {
 			return nil, "", fmt.Errorf("api_key or api_base is required for HTTP-based protocol %q", protocol)
 		}
@@ -97,7 +127,11 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return NewHTTPProviderWithMaxTokensField(cfg.APIKey, apiBase, cfg.Proxy, cfg.MaxTokensField), modelID, nil
+		provider, err := newHTTPProviderFromConfig(cfg, apiBase)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
 
 	case "anthropic":
 		if cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token" {
@@ -116,7 +150,11 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic protocol (model: %s)", cfg.Model)
 		}
-		return NewHTTPProviderWithMaxTokensField(cfg.APIKey, apiBase, cfg.Proxy, cfg.MaxTokensField), modelID, nil
+		provider, err := newHTTPProviderFromConfig(cfg, apiBase)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
 
 	case "antigravity":
 		return NewAntigravityProvider(), modelID, nil
@@ -150,11 +188,83 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return provider, modelID, nil
 
+	case "llamacpp", "llama.cpp":
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase("llamacpp")
+		}
+		opts := llamacpp.Options{Client: HTTPClientOptions{
+			Timeout:            time.Duration(cfg.Timeout) * time.Second,
+			Proxy:              cfg.Proxy,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			CACertFile:         cfg.CACert,
+			Headers:            cfg.Headers,
+		}}
+		if cfg.LlamaCpp != nil {
+			opts.Endpoint = cfg.LlamaCpp.Endpoint
+			opts.DisableGrammar = cfg.LlamaCpp.DisableGrammar
+		}
+		provider, err := llamacpp.NewProvider(cfg.APIKey, apiBase, opts)
+		if err != nil {
+			return nil, "", fmt.Errorf("model %s: %w", cfg.ModelName, err)
+		}
+		return provider, modelID, nil
+
+	case "replay":
+		// The model ID is the path of a recorded transcript
+		provider, err := replay.NewProvider(modelID)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
+
 	default:
 		return nil, "", fmt.Errorf("unknown protocol %q in model %q", protocol, cfg.Model)
 	}
 }
 
+// withProviderPreset returns cfg with the gaps a built-in provider preset
+// knows how to fill: the API key from the provider's environment variable
+// and the field the provider expects the output limit in.
+func withProviderPreset(cfg *config.ModelConfig, protocol string) *config.ModelConfig {
+	preset, ok := config.LookupProviderPreset(protocol)
+	if !ok {
+		return cfg
+	}
+	filled := *cfg
+	if filled.APIKey == "" && preset.APIKeyEnv != "" {
+		filled.APIKey = os.Getenv(preset.APIKeyEnv)
+	}
+	if filled.MaxTokensField == "" {
+		filled.MaxTokensField = preset.MaxTokensField
+	}
+	return &filled
+}
+
+// newHTTPProviderFromConfig creates an OpenAI-compatible provider with the
+// model's HTTP client settings and OpenRouter routing preferences.
+func newHTTPProviderFromConfig(cfg *config.ModelConfig, apiBase string) (LLMProvider, error) {
+	_, modelID := ExtractProtocol(cfg.Model)
+	var maxOutput int
+	if _, m, ok := config.LookupPresetModel(cfg.Model); ok {
+		maxOutput = m.MaxOutput
+	}
+	provider, err := NewHTTPProviderWithOptions(cfg.APIKey, apiBase, cfg.MaxTokensField, HTTPClientOptions{
+		Timeout:            time.Duration(cfg.Timeout) * time.Second,
+		Proxy:              cfg.Proxy,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		CACertFile:         cfg.CACert,
+		Headers:            cfg.Headers,
+		ExtraBody:          openRouterFields(cfg.OpenRouter, modelID),
+		ToolCallFormat:     cfg.ToolCallFormat,
+		MaxOutputTokens:    maxOutput,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("model %s: %w", cfg.ModelName, err)
+	}
+	return provider, nil
+}
+
 // getDefaultAPIBase returns the default API base URL for a given protocol.
 func getDefaultAPIBase(protocol string) string {
 	switch protocol {
@@ -186,8 +296,14 @@ func getDefaultAPIBase(protocol string) string {
 		return "https://dashscope.aliyuncs.com/compatible-mode/v1"
 	case "vllm":
 		return "http://localhost:8000/v1"
+	case "llamacpp":
+		return "http://localhost:8080"
 	case "mistral":
 		return "https://api.mistral.ai/v1"
+	case "together":
+		return "https://api.together.xyz/v1"
+	case "xai":
+		return "https://api.x.ai/v1"
 	default:
 		return ""
 	}
@@ -6,9 +6,18 @@
 package providers
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/llamacpp"
 )
 
 func TestExtractProtocol(t *testing.T) {
@@ -108,6 +117,9 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 		{"vllm", "vllm"},
 		{"deepseek", "deepseek"},
 		{"ollama", "ollama"},
+		{"together", "together"},
+		{"xai", "xai"},
+		{"mistral", "mistral"},
 	}
 
 	for _, tt := range tests {
@@ -204,6 +216,54 @@ func TestCreateProviderFromConfig_CodexCLI(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	line := `{"model":"gpt-4o","response":{"content":"recorded","finish_reason":"stop"}}`
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{ModelName: "offline", Model: "replay/" + path})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != path {
+		t.Errorf("modelID = %q, want %q", modelID, path)
+	}
+	resp, err := provider.Chat(context.Background(), nil, nil, modelID, nil)
+	if err != nil || resp.Content != "recorded" {
+		t.Errorf("Chat() = %+v, %v", resp, err)
+	}
+
+	if _, _, err := CreateProviderFromConfig(&config.ModelConfig{ModelName: "offline", Model: "replay/missing.jsonl"}); err == nil {
+		t.Error("expected an error for a missing transcript")
+	}
+}
+
+func TestCreateProviderFromConfig_LlamaCpp(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "local",
+		Model:     "llamacpp/qwen2.5-7b-instruct",
+		LlamaCpp:  &config.LlamaCppConfig{Endpoint: "completion"},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*llamacpp.Provider); !ok {
+		t.Fatalf("expected *llamacpp.Provider, got %T", provider)
+	}
+	if modelID != "qwen2.5-7b-instruct" {
+		t.Errorf("modelID = %q, want %q", modelID, "qwen2.5-7b-instruct")
+	}
+
+	cfg.LlamaCpp.Endpoint = "infill"
+	if _, _, err := CreateProviderFromConfig(cfg); err == nil {
+		t.Error("expected an error for an unknown endpoint")
+	}
+}
+
 func TestCreateProviderFromConfig_MissingAPIKey(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-no-key",
@@ -216,6 +276,120 @@ func TestCreateProviderFromConfig_MissingAPIKey(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_InvalidCACert(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-bad-ca",
+		Model:     "openai/gpt-4o",
+		APIKey:    "test-key",
+		CACert:    filepath.Join(t.TempDir(), "missing.pem"),
+	}
+
+	_, _, err := CreateProviderFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "test-bad-ca") {
+		t.Fatalf("CreateProviderFromConfig() error = %v, want CA bundle error naming the model", err)
+	}
+}
+
+func TestCreateProviderFromConfig_OpenRouterRouting(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	allowFallbacks := false
+	cfg := &config.ModelConfig{
+		ModelName: "sonnet-or",
+		Model:     "openrouter/anthropic/claude-sonnet-4.6",
+		APIKey:    "test-key",
+		APIBase:   server.URL,
+		OpenRouter: &config.OpenRouterConfig{
+			Order:          []string{"anthropic", "amazon-bedrock"},
+			Ignore:         []string{"together"},
+			AllowFallbacks: &allowFallbacks,
+			DataCollection: "deny",
+			Fallbacks:      []string{"openai/gpt-4o"},
+		},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	want := map[string]any{
+		"order":           []any{"anthropic", "amazon-bedrock"},
+		"ignore":          []any{"together"},
+		"allow_fallbacks": false,
+		"data_collection": "deny",
+	}
+	if !reflect.DeepEqual(requestBody["provider"], want) {
+		t.Errorf("provider = %v, want %v", requestBody["provider"], want)
+	}
+	models := []any{"anthropic/claude-sonnet-4.6", "openai/gpt-4o"}
+	if !reflect.DeepEqual(requestBody["models"], models) {
+		t.Errorf("models = %v, want %v", requestBody["models"], models)
+	}
+	if requestBody["model"] != "anthropic/claude-sonnet-4.6" {
+		t.Errorf("model = %v", requestBody["model"])
+	}
+}
+
+func TestCreateProviderFromConfig_ProviderPreset(t *testing.T) {
+	var requestBody map[string]any
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+	t.Setenv("XAI_API_KEY", "xai-env-key")
+
+	tests := []struct {
+		model     string
+		apiKey    string
+		wantAuth  string
+		wantField string
+		wantMax   float64
+	}{
+		// The key comes from XAI_API_KEY and the limit goes in max_completion_tokens
+		{"xai/grok-4-0709", "", "Bearer xai-env-key", "max_completion_tokens", 16384},
+		// deepseek-chat rejects more than 8192 output tokens
+		{"deepseek/deepseek-chat", "ds-key", "Bearer ds-key", "max_tokens", 8192},
+		{"mistral/mistral-small-latest", "ms-key", "Bearer ms-key", "max_tokens", 16384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			cfg := &config.ModelConfig{ModelName: "test", Model: tt.model, APIKey: tt.apiKey, APIBase: server.URL}
+			provider, modelID, err := CreateProviderFromConfig(cfg)
+			if err != nil {
+				t.Fatalf("CreateProviderFromConfig() error = %v", err)
+			}
+			if _, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID,
+				map[string]any{"max_tokens": 16384}); err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if auth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", auth, tt.wantAuth)
+			}
+			if requestBody[tt.wantField] != tt.wantMax {
+				t.Errorf("%s = %v, want %v (body %v)", tt.wantField, requestBody[tt.wantField], tt.wantMax, requestBody)
+			}
+		})
+	}
+}
+
 func TestCreateProviderFromConfig_UnknownProtocol(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-unknown",
@@ -41,6 +41,12 @@ func NewFallbackChain(cooldown *CooldownTracker) *FallbackChain {
 	return &FallbackChain{cooldown: cooldown}
 }
 
+// Cooldown returns the tracker the chain consults, so other failure signals
+// such as background health checks can feed it.
+func (fc *FallbackChain) Cooldown() *CooldownTracker {
+	return fc.cooldown
+}
+
 // ResolveCandidates parses model config into a deduplicated candidate list.
 func ResolveCandidates(cfg ModelConfig, defaultProvider string) []FallbackCandidate {
 	seen := make(map[string]bool)
@@ -172,7 +178,11 @@ func (fc *FallbackChain) Execute(
 		}
 
 		// Retriable error: mark failure and continue to next candidate.
-		fc.cooldown.MarkFailure(candidate.Provider, failErr.Reason)
+		// Context-length and content-filter failures say nothing about the
+		// provider's health, so they don't count toward its cooldown.
+		if failErr.Reason != FailoverContext && failErr.Reason != FailoverFiltered {
+			fc.cooldown.MarkFailure(candidate.Provider, failErr.Reason)
+		}
 		result.Attempts = append(result.Attempts, FallbackAttempt{
 			Provider: candidate.Provider,
 			Model:    candidate.Model,
@@ -272,6 +282,17 @@ type FallbackExhaustedError struct {
 	Attempts []FallbackAttempt
 }
 
+// Unwrap exposes each attempt's error so errors.Is can find typed failures.
+func (e *FallbackExhaustedError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		if a.Error != nil {
+			errs = append(errs, a.Error)
+		}
+	}
+	return errs
+}
+
 func (e *FallbackExhaustedError) Error() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("fallback: all %d candidates failed:", len(e.Attempts)))
@@ -13,7 +13,8 @@ import (
 )
 
 type HTTPProvider struct {
-	delegate *openai_compat.Provider
+	delegate  *openai_compat.Provider
+	validator RequestValidator
 }
 
 func NewHTTPProvider(apiKey, apiBase, proxy string) *HTTPProvider {
@@ -28,6 +29,18 @@ func NewHTTPProviderWithMaxTokensField(apiKey, apiBase, proxy, maxTokensField st
 	}
 }
 
+// HTTPClientOptions configures timeouts, proxying, TLS trust and extra
+// headers for an HTTPProvider.
+type HTTPClientOptions = openai_compat.ClientOptions
+
+func NewHTTPProviderWithOptions(apiKey, apiBase, maxTokensField string, opts HTTPClientOptions) (*HTTPProvider, error) {
+	delegate, err := openai_compat.NewProviderWithOptions(apiKey, apiBase, maxTokensField, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPProvider{delegate: delegate}, nil
+}
+
 func (p *HTTPProvider) Chat(
 	ctx context.Context,
 	messages []Message,
@@ -35,9 +48,26 @@ func (p *HTTPProvider) Chat(
 	model string,
 	options map[string]any,
 ) (*LLMResponse, error) {
+	messages, err := p.validator.Apply(messages, model)
+	if err != nil {
+		return nil, err
+	}
 	return p.delegate.Chat(ctx, messages, tools, model, options)
 }
 
+// SetRequestValidator replaces the pre-flight checks run on every request
+func (p *HTTPProvider) SetRequestValidator(v RequestValidator) {
+	p.validator = v
+}
+
 func (p *HTTPProvider) GetDefaultModel() string {
 	return ""
 }
+
+func (p *HTTPProvider) Probe(ctx context.Context) error {
+	return p.delegate.Probe(ctx)
+}
+
+func (p *HTTPProvider) Embed(ctx context.Context, inputs []string, model string) (*EmbeddingResponse, error) {
+	return p.delegate.Embed(ctx, inputs, model)
+}
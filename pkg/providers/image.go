@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxImageBytes is the largest image file LoadImage inlines. Anthropic
+// rejects base64 images over 5 MB and OpenAI over 20 MB.
+const MaxImageBytes = 5 << 20
+
+var imageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// LoadImage reads an image file into an inline ImagePart for vision models.
+func LoadImage(path string) (ImagePart, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ImagePart{}, fmt.Errorf("reading image: %w", err)
+	}
+	if info.Size() > MaxImageBytes {
+		return ImagePart{}, fmt.Errorf("image %s is %d bytes, over the %d byte limit", path, info.Size(), MaxImageBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImagePart{}, fmt.Errorf("reading image: %w", err)
+	}
+
+	mediaType, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		mediaType = http.DetectContentType(data)
+		if !strings.HasPrefix(mediaType, "image/") {
+			return ImagePart{}, fmt.Errorf("%s is not an image (%s)", path, mediaType)
+		}
+	}
+
+	return ImagePart{
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// WithoutImages returns messages with image parts replaced by a short note,
+// for models that can't take image input. The input slice is not modified.
+func WithoutImages(messages []Message) []Message {
+	var out []Message
+	for i, m := range messages {
+		if len(m.Images) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make([]Message, len(messages))
+			copy(out, messages)
+		}
+		note := fmt.Sprintf("[%d image(s) omitted: this model does not accept images]", len(m.Images))
+		if m.Content != "" {
+			note = m.Content + "\n\n" + note
+		}
+		out[i].Content = note
+		out[i].Images = nil
+	}
+	if out == nil {
+		return messages
+	}
+	return out
+}
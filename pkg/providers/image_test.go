@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Smallest valid PNG header, enough for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(path, pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img, err := LoadImage(path)
+	if err != nil {
+		t.Fatalf("LoadImage: %v", err)
+	}
+	if img.MediaType != "image/png" || img.Data == "" {
+		t.Errorf("image = %+v, want inline png", img)
+	}
+
+	// No extension: sniffed from content
+	sniffed := filepath.Join(dir, "capture")
+	if err := os.WriteFile(sniffed, pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if img, err := LoadImage(sniffed); err != nil || img.MediaType != "image/png" {
+		t.Errorf("LoadImage(sniffed) = %+v, %v", img, err)
+	}
+
+	text := filepath.Join(dir, "notes")
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadImage(text); err == nil {
+		t.Error("expected an error for a non-image file")
+	}
+}
+
+func TestWithoutImages(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "look", Images: []ImagePart{{URL: "https://example.com/a.png"}}},
+		{Role: "tool", Images: []ImagePart{{URL: "a"}, {URL: "b"}}},
+		{Role: "assistant", Content: "ok"},
+	}
+
+	out := WithoutImages(messages)
+	if len(messages[0].Images) != 1 {
+		t.Fatal("WithoutImages modified its input")
+	}
+	for i, m := range out {
+		if len(m.Images) != 0 {
+			t.Errorf("message %d still has images", i)
+		}
+	}
+	if out[0].Content != "look\n\n[1 image(s) omitted: this model does not accept images]" {
+		t.Errorf("user content = %q", out[0].Content)
+	}
+	if out[1].Content != "[2 image(s) omitted: this model does not accept images]" {
+		t.Errorf("tool content = %q", out[1].Content)
+	}
+
+	plain := []Message{{Role: "user", Content: "hi"}}
+	if got := WithoutImages(plain); &got[0] != &plain[0] {
+		t.Error("messages without images should be returned as is")
+	}
+}
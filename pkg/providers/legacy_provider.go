@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
 )
 
 // CreateProvider creates a provider based on the configuration.
@@ -18,6 +19,18 @@ import (
 func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 	model := cfg.Agents.Defaults.GetModelName()
 
+	// A replay model needs no model_list entry or credentials, so demos and
+	// CI can run with --model replay/<file>
+	if protocol, path := ExtractProtocol(model); protocol == "replay" {
+		if _, err := cfg.GetModelConfig(model); err != nil {
+			provider, err := replay.NewProvider(path)
+			if err != nil {
+				return nil, "", err
+			}
+			return provider, path, nil
+		}
+	}
+
 	// Ensure model_list is populated (should be done by LoadConfig, but handle edge cases)
 	if len(cfg.ModelList) == 0 && cfg.HasProvidersConfig() {
 		cfg.ModelList = config.ConvertProvidersToModelList(cfg)
@@ -39,11 +52,38 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 		modelCfg.Workspace = cfg.WorkspacePath()
 	}
 
+	det := cfg.Determinism
+	if det.Enabled && det.Cassette != "" && det.CassetteMode == CassetteReplay {
+		// Replays are served from the cassette alone, so they need no credentials
+		_, modelID := ExtractProtocol(modelCfg.Model)
+		deterministic, err := NewDeterministicProvider(nil, det)
+		if err != nil {
+			return nil, "", err
+		}
+		provider, err := recordTranscripts(cfg, deterministic)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
+	}
+
 	// Use factory to create provider
 	provider, modelID, err := CreateProviderFromConfig(modelCfg)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create provider for model %q: %w", model, err)
 	}
 
+	if det.Enabled {
+		deterministic, err := NewDeterministicProvider(provider, det)
+		if err != nil {
+			return nil, "", err
+		}
+		provider = deterministic
+	}
+
+	provider, err = recordTranscripts(cfg, provider)
+	if err != nil {
+		return nil, "", err
+	}
 	return provider, modelID, nil
 }
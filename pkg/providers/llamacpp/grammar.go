@@ -0,0 +1,292 @@
+package llamacpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Shared GBNF rules for JSON values. Only the ones a grammar references are
+// emitted.
+var jsonRules = map[string]string{
+	"ws":      `[ \t\n]*`,
+	"string":  `"\"" ( [^"\\\x7F\x00-\x1F] | "\\" ( ["\\/bfnrt] | "u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] ) )* "\""`,
+	"number":  `"-"? ( [0-9] | [1-9] [0-9]* ) ( "." [0-9]+ )? ( [eE] [-+]? [0-9]+ )?`,
+	"integer": `"-"? ( [0-9] | [1-9] [0-9]* )`,
+	"boolean": `"true" | "false"`,
+	"null":    `"null"`,
+	"value":   `object | array | string | number | boolean | null`,
+	"object":  `"{" ws ( string ws ":" ws value ( ws "," ws string ws ":" ws value )* )? ws "}"`,
+	"array":   `"[" ws ( value ( ws "," ws value )* )? ws "]"`,
+}
+
+// jsonRuleDeps lists the shared rules each shared rule refers to.
+var jsonRuleDeps = map[string][]string{
+	"value":  {"object", "array", "string", "number", "boolean", "null"},
+	"object": {"ws", "string", "value"},
+	"array":  {"ws", "value"},
+}
+
+var ruleNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// grammarBuilder collects named GBNF rules.
+type grammarBuilder struct {
+	rules map[string]string
+	order []string
+}
+
+func newGrammarBuilder() *grammarBuilder {
+	return &grammarBuilder{rules: make(map[string]string)}
+}
+
+// add defines a rule and returns its name. A name already taken by a
+// different body gets a numeric suffix.
+func (b *grammarBuilder) add(name, body string) string {
+	name = strings.Trim(ruleNameSanitizer.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = "rule"
+	}
+	unique := name
+	for i := 2; ; i++ {
+		existing, taken := b.rules[unique]
+		if !taken {
+			break
+		}
+		if existing == body {
+			return unique
+		}
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	b.rules[unique] = body
+	b.order = append(b.order, unique)
+	return unique
+}
+
+// shared references a built-in JSON rule, defining it and its dependencies.
+func (b *grammarBuilder) shared(name string) string {
+	if _, ok := b.rules[name]; ok {
+		return name
+	}
+	b.rules[name] = jsonRules[name]
+	b.order = append(b.order, name)
+	for _, dep := range jsonRuleDeps[name] {
+		b.shared(dep)
+	}
+	return name
+}
+
+// String renders the grammar with root first.
+func (b *grammarBuilder) String() string {
+	var sb strings.Builder
+	names := slices.Clone(b.order)
+	if i := slices.Index(names, "root"); i > 0 {
+		names = append(append([]string{"root"}, names[:i]...), names[i+1:]...)
+	}
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s ::= %s\n", name, b.rules[name])
+	}
+	return sb.String()
+}
+
+// literal renders s as a GBNF string literal.
+func literal(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// jsonLiteral renders v encoded as JSON as a GBNF string literal.
+func jsonLiteral(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return literal("null")
+	}
+	return literal(string(data))
+}
+
+// schema returns a GBNF expression matching JSON documents valid against
+// schema. It covers the subset tool schemas use: object properties (required
+// ones always present, the rest optional, in a fixed order), arrays, enums,
+// const, anyOf/oneOf, type lists and the primitive types. Anything else
+// matches an arbitrary JSON value.
+func (b *grammarBuilder) schema(name string, schema map[string]any) string {
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		alts := make([]string, len(values))
+		for i, v := range values {
+			alts[i] = jsonLiteral(v)
+		}
+		return b.add(name, strings.Join(alts, " | "))
+	}
+	if v, ok := schema["const"]; ok {
+		return jsonLiteral(v)
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if variants, ok := schema[key].([]any); ok && len(variants) > 0 {
+			alts := make([]string, 0, len(variants))
+			for i, variant := range variants {
+				if vs, ok := variant.(map[string]any); ok {
+					alts = append(alts, b.schema(fmt.Sprintf("%s-%d", name, i), vs))
+				}
+			}
+			if len(alts) > 0 {
+				return b.add(name, strings.Join(alts, " | "))
+			}
+		}
+	}
+
+	switch t := schema["type"].(type) {
+	case string:
+		return b.typed(name, t, schema)
+	case []any:
+		alts := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				alts = append(alts, b.typed(fmt.Sprintf("%s-%s", name, s), s, schema))
+			}
+		}
+		if len(alts) > 0 {
+			return b.add(name, strings.Join(alts, " | "))
+		}
+	}
+	if _, ok := schema["properties"].(map[string]any); ok {
+		return b.typed(name, "object", schema)
+	}
+	return b.shared("value")
+}
+
+// typed returns the expression for one JSON type of schema.
+func (b *grammarBuilder) typed(name, typ string, schema map[string]any) string {
+	switch typ {
+	case "string", "number", "integer", "boolean", "null":
+		return b.shared(typ)
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return b.shared("array")
+		}
+		item := b.schema(name+"-item", items)
+		ws := b.shared("ws")
+		return b.add(name, fmt.Sprintf(`"[" %[1]s ( %[2]s ( %[1]s "," %[1]s %[2]s )* )? %[1]s "]"`, ws, item))
+	case "object":
+		return b.object(name, schema)
+	}
+	return b.shared("value")
+}
+
+// object returns the expression for an object schema with properties.
+func (b *grammarBuilder) object(name string, schema map[string]any) string {
+	props, _ := schema["properties"].(map[string]any)
+	if len(props) == 0 {
+		return b.shared("object")
+	}
+	ws := b.shared("ws")
+
+	var required []string
+	if list, ok := schema["required"].([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok && props[s] != nil && !slices.Contains(required, s) {
+				required = append(required, s)
+			}
+		}
+	} else if list, ok := schema["required"].([]string); ok {
+		for _, s := range list {
+			if props[s] != nil && !slices.Contains(required, s) {
+				required = append(required, s)
+			}
+		}
+	}
+	var optional []string
+	for key := range props {
+		if !slices.Contains(required, key) {
+			optional = append(optional, key)
+		}
+	}
+	sort.Strings(optional)
+
+	member := func(key string) string {
+		propSchema, _ := props[key].(map[string]any)
+		value := b.schema(name+"-"+key, propSchema)
+		return fmt.Sprintf(`%s %s ":" %s %s`, jsonLiteral(key), ws, ws, value)
+	}
+	// tail renders optional members from index i on, each with a leading comma
+	tail := func(members []string, i int) string {
+		var parts []string
+		for _, m := range members[i:] {
+			parts = append(parts, fmt.Sprintf(`( %s "," %s %s )?`, ws, ws, m))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	optMembers := make([]string, len(optional))
+	for i, key := range optional {
+		optMembers[i] = member(key)
+	}
+
+	var body string
+	if len(required) > 0 {
+		parts := make([]string, len(required))
+		for i, key := range required {
+			parts[i] = member(key)
+		}
+		body = strings.Join(parts, fmt.Sprintf(` %s "," %s `, ws, ws))
+		if t := tail(optMembers, 0); t != "" {
+			body += " " + t
+		}
+	} else {
+		// Any subset of the optional members, the first without a comma
+		alts := make([]string, len(optMembers))
+		for i, m := range optMembers {
+			alts[i] = strings.TrimSpace(m + " " + tail(optMembers, i+1))
+		}
+		body = "( " + strings.Join(alts, " | ") + " )?"
+	}
+	return b.add(name, fmt.Sprintf(`"{" %[1]s %[2]s %[1]s "}"`, ws, body))
+}
+
+// ToolCallGrammar returns a GBNF grammar for a reply that is a single tool
+// call, written as {"name": "<tool>", "arguments": {...}} with arguments
+// valid against that tool's parameter schema. Unless required is set, the
+// reply may instead be plain text that does not start with "{".
+func ToolCallGrammar(tools []ToolDefinition, required bool) string {
+	b := newGrammarBuilder()
+	ws := b.shared("ws")
+
+	var calls []string
+	for _, tool := range tools {
+		name := tool.Function.Name
+		if name == "" {
+			continue
+		}
+		params := tool.Function.Parameters
+		if params == nil {
+			params = map[string]any{"type": "object"}
+		}
+		args := b.schema("args-"+name, params)
+		calls = append(calls, b.add("call-"+name, fmt.Sprintf(
+			`"{" %[1]s "\"name\"" %[1]s ":" %[1]s %[2]s %[1]s "," %[1]s "\"arguments\"" %[1]s ":" %[1]s %[3]s %[1]s "}"`,
+			ws, jsonLiteral(name), args)))
+	}
+	if len(calls) == 0 {
+		return ""
+	}
+	call := b.add("tool-call", strings.Join(calls, " | "))
+
+	if required {
+		b.add("root", fmt.Sprintf("%s %s", ws, call))
+	} else {
+		text := b.add("text", `[^{ \t\n] [^\x00]*`)
+		b.add("root", fmt.Sprintf("%s ( %s | %s )", ws, call, text))
+	}
+	return b.String()
+}
+
+// SchemaGrammar returns a GBNF grammar for a reply that is a JSON document
+// valid against schema.
+func SchemaGrammar(schema map[string]any) string {
+	b := newGrammarBuilder()
+	value := b.schema("reply", schema)
+	b.add("root", fmt.Sprintf("%s %s", b.shared("ws"), value))
+	return b.String()
+}
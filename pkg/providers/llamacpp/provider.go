@@ -0,0 +1,456 @@
+// Package llamacpp talks to a llama.cpp server (llama-server) directly. Tool
+// calls are constrained with a GBNF grammar generated from the tool schemas,
+// so small local models produce a valid call instead of prose that has to be
+// scraped for one.
+package llamacpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/openai_compat"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+// Endpoints a Provider can send requests to.
+const (
+	EndpointChat       = "chat"       // /v1/chat/completions
+	EndpointCompletion = "completion" // /completion, prompt rendered by /apply-template
+)
+
+// Options configures a Provider.
+type Options struct {
+	Endpoint       string // EndpointChat (default) or EndpointCompletion
+	DisableGrammar bool   // Describe tools in the prompt only, without a grammar
+	Client         openai_compat.ClientOptions
+}
+
+type Provider struct {
+	apiKey     string
+	apiBase    string
+	endpoint   string
+	grammar    bool
+	headers    map[string]string
+	httpClient *http.Client
+	callSeq    atomic.Int64
+}
+
+// NewProvider creates a provider for the llama.cpp server at apiBase, e.g.
+// http://localhost:8080. A trailing /v1 is accepted and ignored.
+func NewProvider(apiKey, apiBase string, opts Options) (*Provider, error) {
+	switch opts.Endpoint {
+	case "":
+		opts.Endpoint = EndpointChat
+	case EndpointChat, EndpointCompletion:
+	default:
+		return nil, fmt.Errorf("unknown llama.cpp endpoint %q", opts.Endpoint)
+	}
+	client, err := openai_compat.NewHTTPClient(opts.Client)
+	if err != nil {
+		return nil, err
+	}
+	apiBase = strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/v1")
+	return &Provider{
+		apiKey:     apiKey,
+		apiBase:    apiBase,
+		endpoint:   opts.Endpoint,
+		grammar:    !opts.DisableGrammar,
+		headers:    opts.Client.Headers,
+		httpClient: client,
+	}, nil
+}
+
+func (p *Provider) GetDefaultModel() string {
+	return ""
+}
+
+// chatMessage is a message in the plain system/user/assistant form every
+// chat template understands.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// request is what both endpoints need from one Chat call.
+type request struct {
+	messages []chatMessage
+	grammar  string
+	options  map[string]any
+	model    string
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	choice, _ := options["tool_choice"].(string)
+	if choice == "none" {
+		tools = nil
+	}
+	required := choice == "required"
+
+	req := request{options: options, model: model}
+	req.messages = renderMessages(messages, toolPrompt(tools, required))
+	if p.grammar {
+		if len(tools) > 0 {
+			req.grammar = ToolCallGrammar(tools, required)
+		} else if schema, ok := protocoltypes.ResponseSchemaFromOptions(options); ok {
+			req.grammar = SchemaGrammar(schema.Schema)
+		}
+	}
+
+	var (
+		resp *LLMResponse
+		err  error
+	)
+	if p.endpoint == EndpointCompletion {
+		resp, err = p.complete(ctx, req)
+	} else {
+		resp, err = p.chat(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if calls := p.parseToolCall(resp.Content, tools); len(calls) > 0 {
+		resp.Content = ""
+		resp.ToolCalls = calls
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+// chat sends the request to the OpenAI-compatible endpoint.
+func (p *Provider) chat(ctx context.Context, req request) (*LLMResponse, error) {
+	body := map[string]any{
+		"model":    req.model,
+		"messages": req.messages,
+	}
+	setSampling(body, req.options, "max_tokens")
+	if req.grammar != "" {
+		body["grammar"] = req.grammar
+	}
+
+	data, err := p.post(ctx, "/v1/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Choices []struct {
+			Message struct {
+				Content          string `json:"content"`
+				ReasoningContent string `json:"reasoning_content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	resp := &LLMResponse{FinishReason: "stop"}
+	if len(apiResponse.Choices) > 0 {
+		choice := apiResponse.Choices[0]
+		resp.Content = choice.Message.Content
+		resp.ReasoningContent = choice.Message.ReasoningContent
+		if choice.FinishReason != "" {
+			resp.FinishReason = choice.FinishReason
+		}
+	}
+	if u := apiResponse.Usage; u != nil {
+		resp.Usage = &UsageInfo{
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+		}
+	}
+	return resp, nil
+}
+
+// complete renders the conversation with the model's own chat template and
+// sends the prompt to the native completion endpoint.
+func (p *Provider) complete(ctx context.Context, req request) (*LLMResponse, error) {
+	data, err := p.post(ctx, "/apply-template", map[string]any{"messages": req.messages})
+	if err != nil {
+		return nil, fmt.Errorf("apply-template: %w", err)
+	}
+	var rendered struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal apply-template response: %w", err)
+	}
+
+	body := map[string]any{
+		"prompt":       rendered.Prompt,
+		"cache_prompt": true,
+	}
+	setSampling(body, req.options, "n_predict")
+	if req.grammar != "" {
+		body["grammar"] = req.grammar
+	}
+
+	data, err = p.post(ctx, "/completion", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Content         string `json:"content"`
+		StoppedLimit    bool   `json:"stopped_limit"`
+		TokensPredicted int    `json:"tokens_predicted"`
+		TokensEvaluated int    `json:"tokens_evaluated"`
+		TokensCached    int    `json:"tokens_cached"`
+	}
+	if err := json.Unmarshal(data, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	finishReason := "stop"
+	if apiResponse.StoppedLimit {
+		finishReason = "length"
+	}
+	return &LLMResponse{
+		Content:      apiResponse.Content,
+		FinishReason: finishReason,
+		Usage: &UsageInfo{
+			PromptTokens:       apiResponse.TokensEvaluated,
+			CompletionTokens:   apiResponse.TokensPredicted,
+			TotalTokens:        apiResponse.TokensEvaluated + apiResponse.TokensPredicted,
+			CachedPromptTokens: min(apiResponse.TokensCached, apiResponse.TokensEvaluated),
+		},
+	}, nil
+}
+
+// setSampling copies the sampling options into body, with max_tokens sent
+// as maxTokensField.
+func setSampling(body, options map[string]any, maxTokensField string) {
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		body[maxTokensField] = maxTokens
+	}
+	if temperature, ok := options["temperature"].(float64); ok {
+		body["temperature"] = temperature
+	}
+	if seed, ok := asInt(options["seed"]); ok {
+		body["seed"] = seed
+	}
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		body["stop"] = stop
+	}
+}
+
+func asInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// post sends body as JSON to path and returns the response body.
+func (p *Provider) post(ctx context.Context, path string, body map[string]any) ([]byte, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+// setHeaders adds the server API key and the configured custom headers
+func (p *Provider) setHeaders(req *http.Request) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// Probe checks the server's health endpoint, which reports ready once the
+// model is loaded.
+func (p *Provider) Probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// toolPrompt describes tools and the call format the grammar enforces.
+func toolPrompt(tools []ToolDefinition, required bool) string {
+	if len(tools) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("You can call the tools below. To call one, reply with only a JSON object " +
+		`{"name": "<tool name>", "arguments": {<arguments>}} and nothing else.`)
+	if required {
+		sb.WriteString(" This reply must be a tool call.")
+	} else {
+		sb.WriteString(" Otherwise reply in plain text.")
+	}
+	sb.WriteString("\n\nTools:\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&sb, "- %s: %s\n", tool.Function.Name, tool.Function.Description)
+		if params, err := json.Marshal(tool.Function.Parameters); err == nil && tool.Function.Parameters != nil {
+			fmt.Fprintf(&sb, "  parameters: %s\n", params)
+		}
+	}
+	return sb.String()
+}
+
+// renderMessages flattens a conversation into system, user and assistant
+// turns. Earlier tool calls are written in the grammar's JSON form and tool
+// results become user turns, so templates without tool support still work.
+// Consecutive turns of the same role are merged since some templates require
+// strict alternation. toolPrompt is appended to the system prompt.
+func renderMessages(messages []Message, toolPrompt string) []chatMessage {
+	toolNames := make(map[string]string)
+	out := make([]chatMessage, 0, len(messages)+1)
+	appendTurn := func(role, content string) {
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content += "\n\n" + content
+			return
+		}
+		out = append(out, chatMessage{Role: role, Content: content})
+	}
+
+	for _, m := range messages {
+		switch m.Role {
+		case "assistant":
+			parts := []string{}
+			if m.Content != "" {
+				parts = append(parts, m.Content)
+			}
+			for _, tc := range m.ToolCalls {
+				toolNames[tc.ID] = tc.Name
+				call, _ := json.Marshal(struct {
+					Name      string         `json:"name"`
+					Arguments map[string]any `json:"arguments"`
+				}{tc.Name, tc.Arguments})
+				parts = append(parts, string(call))
+			}
+			appendTurn("assistant", strings.Join(parts, "\n"))
+		case "tool":
+			name := toolNames[m.ToolCallID]
+			if name == "" {
+				name = "tool"
+			}
+			appendTurn("user", fmt.Sprintf("Result of %s:\n%s", name, m.Content))
+		default:
+			appendTurn(m.Role, m.Content)
+		}
+	}
+
+	if toolPrompt != "" {
+		if len(out) > 0 && out[0].Role == "system" {
+			out[0].Content += "\n\n" + toolPrompt
+		} else {
+			out = append([]chatMessage{{Role: "system", Content: toolPrompt}}, out...)
+		}
+	}
+	return out
+}
+
+// parseToolCall decodes a reply in the grammar's tool call form. Replies
+// that are not a call to one of tools are left as text.
+func (p *Provider) parseToolCall(content string, tools []ToolDefinition) []ToolCall {
+	content = strings.TrimSpace(content)
+	if len(tools) == 0 || !strings.HasPrefix(content, "{") {
+		return nil
+	}
+	var call struct {
+		Name      string `json:"name"`
+		Arguments any    `json:"arguments"`
+	}
+	if err := json.NewDecoder(strings.NewReader(content)).Decode(&call); err != nil {
+		return nil
+	}
+	known := false
+	for _, tool := range tools {
+		if tool.Function.Name == call.Name {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil
+	}
+
+	arguments := make(map[string]any)
+	switch args := call.Arguments.(type) {
+	case map[string]any:
+		arguments = args
+	case string:
+		// Without a grammar some models stringify the arguments JSON
+		if err := json.Unmarshal([]byte(args), &arguments); err != nil {
+			arguments["raw"] = args
+		}
+	}
+	id := fmt.Sprintf("llamacpp_call_%d", p.callSeq.Add(1))
+	return []ToolCall{{ID: id, Name: call.Name, Arguments: arguments}}
+}
@@ -0,0 +1,233 @@
+package llamacpp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+var testTools = []ToolDefinition{
+	{Type: "function", Function: protocoltypes.ToolFunctionDefinition{
+		Name:        "exec",
+		Description: "Run a shell command",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command": map[string]any{"type": "string"},
+				"timeout": map[string]any{"type": "integer"},
+			},
+			"required": []string{"command"},
+		},
+	}},
+	{Type: "function", Function: protocoltypes.ToolFunctionDefinition{
+		Name: "scan",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"ports": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+				"mode":  map[string]any{"type": "string", "enum": []any{"fast", "full"}},
+			},
+		},
+	}},
+}
+
+func TestToolCallGrammar(t *testing.T) {
+	grammar := ToolCallGrammar(testTools, false)
+	for _, want := range []string{
+		`root ::= ws ( tool-call | text )`,
+		`tool-call ::= call-exec | call-scan`,
+		`"\"exec\""`,
+		`args-exec ::= "{" ws "\"command\"" ws ":" ws string ( ws "," ws "\"timeout\"" ws ":" ws integer )? ws "}"`,
+		`args-scan-mode ::= "\"fast\"" | "\"full\""`,
+		`args-scan-ports ::= "[" ws ( integer ( ws "," ws integer )* )? ws "]"`,
+		`integer ::= `,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+	if !strings.HasPrefix(grammar, "root ::= ") {
+		t.Errorf("grammar does not start with root:\n%s", grammar)
+	}
+	// The command is required, so it is not wrapped in an optional group
+	if strings.Contains(grammar, `( ws "," ws "\"command\""`) {
+		t.Errorf("required member made optional:\n%s", grammar)
+	}
+	// Only referenced shared rules are emitted
+	if strings.Contains(grammar, "boolean ::=") {
+		t.Errorf("unused rule emitted:\n%s", grammar)
+	}
+
+	required := ToolCallGrammar(testTools, true)
+	if !strings.HasPrefix(required, "root ::= ws tool-call\n") || strings.Contains(required, "text ::=") {
+		t.Errorf("required grammar allows prose:\n%s", required)
+	}
+
+	if ToolCallGrammar(nil, false) != "" {
+		t.Error("expected no grammar without tools")
+	}
+}
+
+func TestSchemaGrammar(t *testing.T) {
+	grammar := SchemaGrammar(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"approved": map[string]any{"type": "boolean"},
+			"reason":   map[string]any{"type": []any{"string", "null"}},
+		},
+		"required": []any{"approved"},
+	})
+	for _, want := range []string{
+		`root ::= ws reply`,
+		`reply ::= "{" ws "\"approved\"" ws ":" ws boolean ( ws "," ws "\"reason\"" ws ":" ws reply-reason )? ws "}"`,
+		`reply-reason ::= string | null`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
+
+func TestProviderChat_GrammarToolCall(t *testing.T) {
+	var requestBody map[string]any
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		requestBody = nil
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"name\": \"exec\", \"arguments\": {\"command\": \"id\"}}"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":50,"completion_tokens":12,"total_tokens":62}}`))
+	}))
+	defer server.Close()
+
+	p, err := NewProvider("", server.URL+"/v1", Options{})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	msgs := []Message{
+		{Role: "system", Content: "You are a pentest assistant."},
+		{Role: "user", Content: "Who am I?"},
+	}
+	resp, err := p.Chat(t.Context(), msgs, testTools, "qwen", map[string]any{"max_tokens": 256, "tool_choice": "required"})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if path != "/v1/chat/completions" {
+		t.Errorf("path = %q", path)
+	}
+	if _, ok := requestBody["tools"]; ok {
+		t.Error("tools sent natively; the grammar replaces them")
+	}
+	if g, _ := requestBody["grammar"].(string); !strings.HasPrefix(g, "root ::= ws tool-call") {
+		t.Errorf("grammar = %q", g)
+	}
+	if requestBody["max_tokens"] != float64(256) {
+		t.Errorf("max_tokens = %v", requestBody["max_tokens"])
+	}
+	system := requestBody["messages"].([]any)[0].(map[string]any)["content"].(string)
+	if !strings.Contains(system, "- exec: Run a shell command") || !strings.Contains(system, "must be a tool call") {
+		t.Errorf("system prompt lacks tool descriptions:\n%s", system)
+	}
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "exec" || resp.ToolCalls[0].Arguments["command"] != "id" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if resp.Content != "" || resp.FinishReason != "tool_calls" {
+		t.Errorf("Content = %q, FinishReason = %q", resp.Content, resp.FinishReason)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 50 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderChat_CompletionEndpoint(t *testing.T) {
+	var paths []string
+	var completionBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apply-template":
+			w.Write([]byte(`{"prompt":"<|im_start|>user\nhi<|im_end|>\n<|im_start|>assistant\n"}`))
+		case "/completion":
+			completionBody = body
+			w.Write([]byte(`{"content":"Hello there.","stopped_limit":true,"tokens_predicted":3,"tokens_evaluated":20,"tokens_cached":15}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewProvider("", server.URL, Options{Endpoint: EndpointCompletion})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, testTools, "qwen",
+		map[string]any{"max_tokens": 64, "stop": []string{"<|im_end|>"}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if strings.Join(paths, ",") != "/apply-template,/completion" {
+		t.Errorf("paths = %v", paths)
+	}
+	if !strings.HasPrefix(completionBody["prompt"].(string), "<|im_start|>user") {
+		t.Errorf("prompt = %v", completionBody["prompt"])
+	}
+	if completionBody["n_predict"] != float64(64) {
+		t.Errorf("n_predict = %v", completionBody["n_predict"])
+	}
+	if g, _ := completionBody["grammar"].(string); !strings.Contains(g, "text ::=") {
+		t.Errorf("grammar does not allow prose without tool_choice required: %q", g)
+	}
+	if resp.Content != "Hello there." || len(resp.ToolCalls) != 0 || resp.FinishReason != "length" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CachedPromptTokens != 15 || resp.Usage.CompletionTokens != 3 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestRenderMessages(t *testing.T) {
+	msgs := []Message{
+		{Role: "user", Content: "scan it"},
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{ID: "c1", Name: "exec", Arguments: map[string]any{"command": "nmap"}},
+			{ID: "c2", Name: "exec", Arguments: map[string]any{"command": "id"}},
+		}},
+		{Role: "tool", ToolCallID: "c1", Content: "22/tcp open"},
+		{Role: "tool", ToolCallID: "c2", Content: "uid=0"},
+	}
+
+	got := renderMessages(msgs, "TOOLS")
+	if len(got) != 4 {
+		t.Fatalf("got %d messages, want 4: %+v", len(got), got)
+	}
+	if got[0].Role != "system" || got[0].Content != "TOOLS" {
+		t.Errorf("got[0] = %+v", got[0])
+	}
+	if got[2].Content != `{"name":"exec","arguments":{"command":"nmap"}}`+"\n"+`{"name":"exec","arguments":{"command":"id"}}` {
+		t.Errorf("assistant turn = %q", got[2].Content)
+	}
+	// Both results are merged into one user turn
+	if got[3].Role != "user" || got[3].Content != "Result of exec:\n22/tcp open\n\nResult of exec:\nuid=0" {
+		t.Errorf("tool results = %+v", got[3])
+	}
+}
+
+func TestParseToolCall_UnknownToolIsText(t *testing.T) {
+	p, _ := NewProvider("", "http://localhost:8080", Options{})
+	if calls := p.parseToolCall(`{"name": "rm", "arguments": {}}`, testTools); calls != nil {
+		t.Errorf("unknown tool parsed as call: %+v", calls)
+	}
+	calls := p.parseToolCall(`{"name": "scan", "arguments": "{\"mode\": \"fast\"}"}`, testTools)
+	if len(calls) != 1 || calls[0].Arguments["mode"] != "fast" {
+		t.Errorf("stringified arguments: %+v", calls)
+	}
+}
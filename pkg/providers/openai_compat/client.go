@@ -0,0 +1,91 @@
+package openai_compat
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const defaultTimeout = 120 * time.Second
+
+// ClientOptions configures the HTTP client a Provider talks to its endpoint
+// with, e.g. to route requests through an intercepting proxy.
+type ClientOptions struct {
+	Timeout            time.Duration     // Whole-request timeout; 0 means 120s
+	Proxy              string            // Proxy URL; empty uses the environment
+	InsecureSkipVerify bool              // Accept any server certificate
+	CACertFile         string            // PEM bundle trusted in addition to the system roots
+	Headers            map[string]string // Sent with every request, after Authorization
+	ExtraBody          map[string]any    // Added to every chat request body unless already set
+	ToolCallFormat     string            // How the model writes tool calls in text; see ToolCallFormatAuto
+	MaxOutputTokens    int               // Caps max_tokens for APIs that reject larger values; 0 = no cap
+}
+
+// NewHTTPClient builds an HTTP client from opts.
+func NewHTTPClient(opts ClientOptions) (*http.Client, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	if opts.Proxy == "" && !opts.InsecureSkipVerify && opts.CACertFile == "" {
+		return client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		parsed, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", opts.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+
+	if opts.InsecureSkipVerify || opts.CACertFile != "" {
+		tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+		if opts.CACertFile != "" {
+			pool, err := loadCertPool(opts.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	client.Transport = transport
+	return client, nil
+}
+
+// loadCertPool returns the system roots plus the certificates in path
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// setHeaders adds authentication and the configured custom headers to req
+func (p *Provider) setHeaders(req *http.Request) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+}
@@ -0,0 +1,96 @@
+package openai_compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+type EmbeddingResponse = protocoltypes.EmbeddingResponse
+
+// Embed calls the OpenAI-compatible /embeddings endpoint and returns one
+// vector per input, in input order.
+func (p *Provider) Embed(ctx context.Context, inputs []string, model string) (*EmbeddingResponse, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no inputs to embed")
+	}
+
+	model = normalizeModel(model, p.apiBase)
+	jsonData, err := json.Marshal(map[string]any{
+		"model":           model,
+		"input":           inputs,
+		"encoding_format": "float",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/embeddings", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	return parseEmbeddingResponse(body, len(inputs))
+}
+
+func parseEmbeddingResponse(body []byte, inputs int) (*EmbeddingResponse, error) {
+	var apiResponse struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Model string `json:"model"`
+		Usage *struct {
+			PromptTokens int `json:"prompt_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(apiResponse.Data) != inputs {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(apiResponse.Data), inputs)
+	}
+
+	// Entries carry their input index and are not guaranteed to be in order
+	embeddings := make([][]float32, inputs)
+	for _, d := range apiResponse.Data {
+		if d.Index < 0 || d.Index >= inputs || embeddings[d.Index] != nil {
+			return nil, fmt.Errorf("invalid embedding index %d", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+
+	result := &EmbeddingResponse{Embeddings: embeddings, Model: apiResponse.Model}
+	if apiResponse.Usage != nil {
+		result.Usage = &UsageInfo{
+			PromptTokens: apiResponse.Usage.PromptTokens,
+			TotalTokens:  apiResponse.Usage.TotalTokens,
+		}
+	}
+	return result, nil
+}
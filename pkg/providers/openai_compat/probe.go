@@ -0,0 +1,36 @@
+package openai_compat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+// Probe checks that the endpoint is up and the credentials are accepted by
+// listing models, which costs nothing on every OpenAI-compatible API.
+func (p *Provider) Probe(ctx context.Context) error {
+	if p.apiBase == "" {
+		return fmt.Errorf("API base not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -8,11 +8,10 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"regexp"
 	"strings"
-	"time"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
@@ -26,12 +25,17 @@ type (
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
 	ExtraContent           = protocoltypes.ExtraContent
 	GoogleExtra            = protocoltypes.GoogleExtra
+	ImagePart              = protocoltypes.ImagePart
 )
 
 type Provider struct {
 	apiKey         string
 	apiBase        string
 	maxTokensField string // Field name for max tokens (e.g., "max_completion_tokens" for o1/glm models)
+	headers        map[string]string
+	extraBody      map[string]any
+	toolCallFormat string // Text tool-call format hint; see ToolCallFormatAuto
+	maxOutput      int    // Largest max_tokens the API accepts; 0 = no cap
 	httpClient     *http.Client
 }
 
@@ -40,27 +44,35 @@ func NewProvider(apiKey, apiBase, proxy string) *Provider {
 }
 
 func NewProviderWithMaxTokensField(apiKey, apiBase, proxy, maxTokensField string) *Provider {
-	client := &http.Client{
-		Timeout: 120 * time.Second,
+	p, err := NewProviderWithOptions(apiKey, apiBase, maxTokensField, ClientOptions{Proxy: proxy})
+	if err != nil {
+		log.Printf("openai_compat: %v", err)
+		p, _ = NewProviderWithOptions(apiKey, apiBase, maxTokensField, ClientOptions{})
 	}
+	return p
+}
 
-	if proxy != "" {
-		parsed, err := url.Parse(proxy)
-		if err == nil {
-			client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(parsed),
-			}
-		} else {
-			log.Printf("openai_compat: invalid proxy URL %q: %v", proxy, err)
-		}
+// NewProviderWithOptions creates a provider whose HTTP client is configured
+// by opts. It fails if the proxy URL or CA bundle is invalid.
+func NewProviderWithOptions(apiKey, apiBase, maxTokensField string, opts ClientOptions) (*Provider, error) {
+	if !validToolCallFormat(opts.ToolCallFormat) {
+		return nil, fmt.Errorf("unknown tool call format %q", opts.ToolCallFormat)
+	}
+	client, err := NewHTTPClient(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Provider{
 		apiKey:         apiKey,
 		apiBase:        strings.TrimRight(apiBase, "/"),
 		maxTokensField: maxTokensField,
+		headers:        opts.Headers,
+		extraBody:      opts.ExtraBody,
+		toolCallFormat: opts.ToolCallFormat,
+		maxOutput:      opts.MaxOutputTokens,
 		httpClient:     client,
-	}
+	}, nil
 }
 
 func (p *Provider) Chat(
@@ -84,9 +96,23 @@ func (p *Provider) Chat(
 	if len(tools) > 0 {
 		requestBody["tools"] = tools
 		requestBody["tool_choice"] = "auto"
+		if choice, ok := options["tool_choice"].(string); ok && choice != "" {
+			requestBody["tool_choice"] = choice // "required" forces a tool call
+		}
+		// Only sent with tools; the API rejects it otherwise
+		if parallel, ok := options["parallel_tool_calls"].(bool); ok {
+			requestBody["parallel_tool_calls"] = parallel
+		}
+	}
+
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		requestBody["stop"] = stop
 	}
 
 	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		if p.maxOutput > 0 && maxTokens > p.maxOutput {
+			maxTokens = p.maxOutput
+		}
 		// Use configured maxTokensField if specified, otherwise fallback to model-based detection
 		fieldName := p.maxTokensField
 		if fieldName == "" {
@@ -112,14 +138,40 @@ func (p *Provider) Chat(
 		}
 	}
 
+	// Reasoning models: how hard to think (none, minimal, low, medium, high)
+	if effort, ok := options["reasoning_effort"].(string); ok && effort != "" {
+		requestBody["reasoning_effort"] = effort
+	}
+
+	// Seeded sampling: best-effort reproducibility on backends that honor it
+	if seed, ok := asInt(options["seed"]); ok {
+		requestBody["seed"] = seed
+	}
+
 	// Prompt caching: pass a stable cache key so OpenAI can bucket requests
 	// with the same key and reuse prefix KV cache across calls.
-	// The key is typically the agent ID — stable per agent, shared across requests.
+	// The key is typically per agent session (see providers.PromptCacheKey).
 	// See: https://platform.openai.com/docs/guides/prompt-caching
 	if cacheKey, ok := options["prompt_cache_key"].(string); ok && cacheKey != "" {
 		requestBody["prompt_cache_key"] = cacheKey
 	}
 
+	// Structured output: send a ResponseSchema as a json_schema response_format,
+	// or forward a raw OpenAI-style one (e.g. {"type":"json_object"}).
+	if responseFormat, ok := options["response_format"].(map[string]any); ok && len(responseFormat) > 0 {
+		requestBody["response_format"] = responseFormat
+	}
+	if schema, ok := protocoltypes.ResponseSchemaFromOptions(options); ok {
+		requestBody["response_format"] = schema.ResponseFormat()
+	}
+
+	// Provider-specific fields such as OpenRouter's provider routing block
+	for key, value := range p.extraBody {
+		if _, set := requestBody[key]; !set {
+			requestBody[key] = value
+		}
+	}
+
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -131,9 +183,7 @@ func (p *Provider) Chat(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if p.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	}
+	p.setHeaders(req)
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
@@ -147,13 +197,16 @@ func (p *Provider) Chat(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
 	}
 
-	return parseResponse(body)
+	return parseResponse(body, p.toolCallFormat, tools)
 }
 
-func parseResponse(body []byte) (*LLMResponse, error) {
+// parseResponse decodes a chat completion. When the model wrote its tool
+// calls into the text, they are parsed in toolCallFormat; tools are the
+// definitions the request offered.
+func parseResponse(body []byte, toolCallFormat string, tools []ToolDefinition) (*LLMResponse, error) {
 	var apiResponse struct {
 		Choices []struct {
 			Message struct {
@@ -175,7 +228,7 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 			} `json:"message"`
 			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage *UsageInfo `json:"usage"`
+		Usage *openaiUsage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
@@ -232,9 +285,10 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 
 	// Fallback: if no structured tool calls were returned but the content
 	// contains text-formatted tool calls (common with local models like
-	// codestral, qwen, etc.), parse them from the text.
+	// codestral, qwen, hermes, llama, mistral, etc.), parse them from the
+	// text.
 	if len(toolCalls) == 0 && choice.Message.Content != "" {
-		if extracted := extractToolCallsFromText(choice.Message.Content); len(extracted) > 0 {
+		if extracted := extractTextToolCalls(choice.Message.Content, toolCallFormat, tools); len(extracted) > 0 {
 			log.Printf("openai_compat: extracted %d tool call(s) from text output (model did not use structured tool calling)", len(extracted))
 			toolCalls = extracted
 			// Clear the content since it was a tool call, not a real response
@@ -248,36 +302,122 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 		ReasoningContent: choice.Message.ReasoningContent,
 		ToolCalls:        toolCalls,
 		FinishReason:     choice.FinishReason,
-		Usage:            apiResponse.Usage,
+		Usage:            apiResponse.Usage.normalize(),
 	}, nil
 }
 
 // openaiMessage is the wire-format message for OpenAI-compatible APIs.
 // It mirrors protocoltypes.Message but omits SystemParts, which is an
-// internal field that would be unknown to third-party endpoints.
+// internal field that would be unknown to third-party endpoints. Content is
+// a string, or a list of content parts when the message carries images.
+// openaiUsage is the usage block of a chat completion, with the cached and
+// reasoning token breakdowns OpenAI, DeepSeek and OpenRouter report
+type openaiUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+	PromptCacheHitTokens int `json:"prompt_cache_hit_tokens"` // DeepSeek
+}
+
+func (u *openaiUsage) normalize() *UsageInfo {
+	if u == nil {
+		return nil
+	}
+	usage := &UsageInfo{
+		PromptTokens:       u.PromptTokens,
+		CompletionTokens:   u.CompletionTokens,
+		TotalTokens:        u.TotalTokens,
+		CachedPromptTokens: u.PromptCacheHitTokens,
+	}
+	if u.PromptTokensDetails != nil && u.PromptTokensDetails.CachedTokens > 0 {
+		usage.CachedPromptTokens = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil {
+		usage.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return usage
+}
+
 type openaiMessage struct {
 	Role       string     `json:"role"`
-	Content    string     `json:"content"`
+	Content    any        `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
 
+type openaiContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openaiImageURL `json:"image_url,omitempty"`
+}
+
+type openaiImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
 // stripSystemParts converts []Message to []openaiMessage, dropping the
 // SystemParts field so it doesn't leak into the JSON payload sent to
 // OpenAI-compatible APIs (some strict endpoints reject unknown fields).
+//
+// Tool messages can only carry text, so images from tool results are sent
+// in a user message after the run of tool messages they belong to.
 func stripSystemParts(messages []Message) []openaiMessage {
-	out := make([]openaiMessage, len(messages))
-	for i, m := range messages {
-		out[i] = openaiMessage{
+	out := make([]openaiMessage, 0, len(messages))
+	var toolImages []ImagePart
+	flushToolImages := func() {
+		if len(toolImages) > 0 {
+			out = append(out, openaiMessage{
+				Role:    "user",
+				Content: contentParts("Images returned by the tool calls above:", toolImages),
+			})
+			toolImages = nil
+		}
+	}
+
+	for _, m := range messages {
+		if m.Role != "tool" {
+			flushToolImages()
+		}
+		msg := openaiMessage{
 			Role:       m.Role,
 			Content:    m.Content,
 			ToolCalls:  m.ToolCalls,
 			ToolCallID: m.ToolCallID,
 		}
+		if len(m.Images) > 0 {
+			if m.Role == "tool" {
+				toolImages = append(toolImages, m.Images...)
+			} else {
+				msg.Content = contentParts(m.Content, m.Images)
+			}
+		}
+		out = append(out, msg)
 	}
+	flushToolImages()
 	return out
 }
 
+func contentParts(text string, images []ImagePart) []openaiContentPart {
+	parts := make([]openaiContentPart, 0, len(images)+1)
+	if text != "" {
+		parts = append(parts, openaiContentPart{Type: "text", Text: text})
+	}
+	for _, img := range images {
+		parts = append(parts, openaiContentPart{
+			Type:     "image_url",
+			ImageURL: &openaiImageURL{URL: img.DataURL(), Detail: img.Detail},
+		})
+	}
+	return parts
+}
+
 func normalizeModel(model, apiBase string) string {
 	idx := strings.Index(model, "/")
 	if idx == -1 {
@@ -290,7 +430,7 @@ func normalizeModel(model, apiBase string) string {
 
 	prefix := strings.ToLower(model[:idx])
 	switch prefix {
-	case "moonshot", "nvidia", "groq", "ollama", "deepseek", "google", "openrouter", "zhipu", "mistral":
+	case "moonshot", "nvidia", "groq", "ollama", "deepseek", "google", "openrouter", "zhipu", "mistral", "xai":
 		return model[idx+1:]
 	default:
 		return model
@@ -336,101 +476,30 @@ var textToolCallTagPattern = regexp.MustCompile(`<(?:functioncall|tool_call)>\s*
 // text like <functioncall>{"name":"exec","arguments":{"command":"ls"}}
 // rather than using the API's structured tool_calls field.
 //
-// This uses brace-counting to correctly extract nested JSON objects
-// (e.g., {"name":"exec","arguments":{"command":"ls /tmp"}}).
+// Nested and string-embedded braces are handled by llmjson
+// (e.g., {"name":"exec","arguments":{"command":"echo '}'"}}). Truncated
+// calls are skipped rather than repaired, since a repaired call could run
+// with arguments missing.
 func extractToolCallsFromText(content string) []ToolCall {
 	var toolCalls []ToolCall
 
-	// Find all opening tags and extract JSON after each one
+	// Find all opening tags and extract JSON after each one, without reading
+	// past the next tag
 	tagLocs := textToolCallTagPattern.FindAllStringIndex(content, -1)
-	for _, loc := range tagLocs {
+	for i, loc := range tagLocs {
 		remaining := content[loc[1]:]
-
-		// Extract balanced JSON object using brace counting
-		jsonStr := extractBalancedJSON(remaining)
-		if jsonStr == "" {
-			continue
+		if i+1 < len(tagLocs) {
+			remaining = content[loc[1]:tagLocs[i+1][0]]
 		}
 
-		// Try to parse as {"name":"...","arguments":{...}} or
-		// {"name":"...","arguments":"..."} (stringified JSON)
-		var call struct {
-			Name      string `json:"name"`
-			Arguments any    `json:"arguments"`
-		}
-		if err := json.Unmarshal([]byte(jsonStr), &call); err != nil {
-			log.Printf("openai_compat: failed to parse text tool call: %v", err)
-			continue
-		}
-		if call.Name == "" {
+		jsonStr := llmjson.Balanced(remaining)
+		if jsonStr == "" {
 			continue
 		}
-
-		arguments := make(map[string]any)
-		switch args := call.Arguments.(type) {
-		case map[string]any:
-			arguments = args
-		case string:
-			// Some models stringify the arguments JSON
-			if err := json.Unmarshal([]byte(args), &arguments); err != nil {
-				arguments["raw"] = args
-			}
+		if call, ok := decodeTextToolCall(jsonStr, len(toolCalls)); ok {
+			toolCalls = append(toolCalls, call)
 		}
-
-		toolCalls = append(toolCalls, ToolCall{
-			ID:        fmt.Sprintf("textcall_%d", len(toolCalls)),
-			Name:      call.Name,
-			Arguments: arguments,
-		})
 	}
 
 	return toolCalls
 }
-
-// extractBalancedJSON finds the first balanced JSON object in s.
-// Returns the complete JSON string including outer braces, or "" if not found.
-func extractBalancedJSON(s string) string {
-	start := strings.IndexByte(s, '{')
-	if start == -1 {
-		return ""
-	}
-
-	depth := 0
-	inString := false
-	escaped := false
-
-	for i := start; i < len(s); i++ {
-		ch := s[i]
-
-		if escaped {
-			escaped = false
-			continue
-		}
-
-		if ch == '\\' && inString {
-			escaped = true
-			continue
-		}
-
-		if ch == '"' {
-			inString = !inString
-			continue
-		}
-
-		if inString {
-			continue
-		}
-
-		switch ch {
-		case '{':
-			depth++
-		case '}':
-			depth--
-			if depth == 0 {
-				return s[start : i+1]
-			}
-		}
-	}
-
-	return "" // unbalanced braces
-}
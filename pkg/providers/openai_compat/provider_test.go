@@ -2,10 +2,18 @@ package openai_compat
 
 import (
 	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestProviderChat_UsesMaxCompletionTokensForGLM(t *testing.T) {
@@ -145,6 +153,31 @@ func TestProviderChat_ParsesReasoningContent(t *testing.T) {
 	}
 }
 
+func TestParseResponse_UsageDetails(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":1200,"completion_tokens":300,"total_tokens":1500,
+		"prompt_tokens_details":{"cached_tokens":1024},
+		"completion_tokens_details":{"reasoning_tokens":256}}}`
+	resp, err := parseResponse([]byte(body), "", nil)
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if resp.Usage.PromptTokens != 1200 || resp.Usage.CachedPromptTokens != 1024 || resp.Usage.ReasoningTokens != 256 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+
+	// DeepSeek reports cache hits at the top level
+	body = `{"choices":[{"message":{"content":"ok"}}],
+		"usage":{"prompt_tokens":500,"completion_tokens":10,"total_tokens":510,"prompt_cache_hit_tokens":384}}`
+	resp, err = parseResponse([]byte(body), "", nil)
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if resp.Usage.CachedPromptTokens != 384 {
+		t.Errorf("CachedPromptTokens = %d, want 384", resp.Usage.CachedPromptTokens)
+	}
+}
+
 func TestProviderChat_HTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad request", http.StatusBadRequest)
@@ -158,6 +191,36 @@ func TestProviderChat_HTTPError(t *testing.T) {
 	}
 }
 
+func TestProviderProbe(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		if gotAuth != "Bearer key" {
+			http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"gpt-4o"}]}`))
+	}))
+	defer server.Close()
+
+	if err := NewProvider("key", server.URL, "").Probe(t.Context()); err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if gotAuth != "Bearer key" {
+		t.Errorf("Authorization = %q, want Bearer key", gotAuth)
+	}
+
+	err := NewProvider("wrong", server.URL, "").Probe(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Probe() with bad key error = %v, want status 401", err)
+	}
+}
+
 func TestProviderChat_StripsMoonshotPrefixAndNormalizesKimiTemperature(t *testing.T) {
 	var requestBody map[string]any
 
@@ -276,6 +339,120 @@ func TestProvider_ProxyConfigured(t *testing.T) {
 	}
 }
 
+func TestProviderChat_SendsCustomHeaders(t *testing.T) {
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	p, err := NewProviderWithOptions("key", server.URL, "", ClientOptions{
+		Headers: map[string]string{"X-Assessment-ID": "eng-42"},
+	})
+	if err != nil {
+		t.Fatalf("NewProviderWithOptions() error = %v", err)
+	}
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := gotHeaders.Get("X-Assessment-ID"); got != "eng-42" {
+		t.Errorf("X-Assessment-ID = %q, want eng-42", got)
+	}
+	if got := gotHeaders.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q, want Bearer key", got)
+	}
+}
+
+func TestProviderChat_SendsResponseSchemaAsResponseFormat(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": `{"approved":true}`}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	schema := &protocoltypes.ResponseSchema{
+		Name:   "verdict",
+		Strict: true,
+		Schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"approved": map[string]any{"type": "boolean"}},
+		},
+	}
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o",
+		map[string]any{protocoltypes.ResponseSchemaOption: schema})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	rf, ok := requestBody["response_format"].(map[string]any)
+	if !ok || rf["type"] != "json_schema" {
+		t.Fatalf("response_format = %v, want json_schema", requestBody["response_format"])
+	}
+	spec, _ := rf["json_schema"].(map[string]any)
+	if spec["name"] != "verdict" || spec["strict"] != true || spec["schema"] == nil {
+		t.Errorf("json_schema = %v, want named strict schema", spec)
+	}
+}
+
+func TestNewHTTPClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    ClientOptions
+		wantErr bool
+	}{
+		{"untrusted certificate", ClientOptions{}, true},
+		{"custom CA bundle", ClientOptions{CACertFile: caFile}, false},
+		{"insecure skip verify", ClientOptions{InsecureSkipVerify: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProviderWithOptions("key", server.URL, "", tt.opts)
+			if err != nil {
+				t.Fatalf("NewProviderWithOptions() error = %v", err)
+			}
+			err = p.Probe(t.Context())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewHTTPClient(ClientOptions{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("NewHTTPClient() with missing CA bundle expected error")
+	}
+}
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	client, err := NewHTTPClient(ClientOptions{})
+	if err != nil || client.Timeout != 120*time.Second {
+		t.Errorf("default timeout = %v (err %v), want 120s", client.Timeout, err)
+	}
+	client, err = NewHTTPClient(ClientOptions{Timeout: 10 * time.Minute})
+	if err != nil || client.Timeout != 10*time.Minute {
+		t.Errorf("timeout = %v (err %v), want 10m", client.Timeout, err)
+	}
+}
+
 func TestProviderChat_AcceptsNumericOptionTypes(t *testing.T) {
 	var requestBody map[string]any
 
@@ -317,6 +494,58 @@ func TestProviderChat_AcceptsNumericOptionTypes(t *testing.T) {
 	}
 }
 
+func TestStripSystemParts_Images(t *testing.T) {
+	img := ImagePart{MediaType: "image/png", Data: "aGVsbG8="}
+	messages := []Message{
+		{Role: "user", Content: "what is this?", Images: []ImagePart{{URL: "https://example.com/a.png", Detail: "low"}}},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "1", Name: "screenshot"}, {ID: "2", Name: "screenshot"}}},
+		{Role: "tool", Content: "saved a.png", ToolCallID: "1", Images: []ImagePart{img}},
+		{Role: "tool", Content: "saved b.png", ToolCallID: "2", Images: []ImagePart{img}},
+		{Role: "assistant", Content: "done"},
+	}
+
+	out := stripSystemParts(messages)
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var wire []map[string]any
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	roles := make([]string, len(wire))
+	for i, m := range wire {
+		roles[i] = m["role"].(string)
+	}
+	// Tool images follow the whole run of tool messages
+	want := []string{"user", "assistant", "tool", "tool", "user", "assistant"}
+	if strings.Join(roles, ",") != strings.Join(want, ",") {
+		t.Fatalf("roles = %v, want %v", roles, want)
+	}
+
+	parts := wire[0]["content"].([]any)
+	if len(parts) != 2 || parts[0].(map[string]any)["text"] != "what is this?" {
+		t.Fatalf("user content = %v", parts)
+	}
+	imageURL := parts[1].(map[string]any)["image_url"].(map[string]any)
+	if imageURL["url"] != "https://example.com/a.png" || imageURL["detail"] != "low" {
+		t.Errorf("image_url = %v", imageURL)
+	}
+
+	if wire[2]["content"] != "saved a.png" {
+		t.Errorf("tool content = %v, want plain text", wire[2]["content"])
+	}
+	toolParts := wire[4]["content"].([]any)
+	if len(toolParts) != 3 {
+		t.Fatalf("tool image message has %d parts, want text + 2 images", len(toolParts))
+	}
+	url := toolParts[1].(map[string]any)["image_url"].(map[string]any)["url"]
+	if url != "data:image/png;base64,aGVsbG8=" {
+		t.Errorf("inline image url = %v", url)
+	}
+}
+
 func TestNormalizeModel_UsesAPIBase(t *testing.T) {
 	if got := normalizeModel("deepseek/deepseek-chat", "https://api.deepseek.com/v1"); got != "deepseek-chat" {
 		t.Fatalf("normalizeModel(deepseek) = %q, want %q", got, "deepseek-chat")
@@ -325,3 +554,277 @@ func TestNormalizeModel_UsesAPIBase(t *testing.T) {
 		t.Fatalf("normalizeModel(openrouter) = %q, want %q", got, "openrouter/auto")
 	}
 }
+
+func TestProviderEmbed_OrdersByIndex(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]any{
+			"model": "text-embedding-3-small",
+			"data": []map[string]any{
+				{"index": 1, "embedding": []float64{0.3, 0.4}},
+				{"index": 0, "embedding": []float64{0.1, 0.2}},
+			},
+			"usage": map[string]any{"prompt_tokens": 7, "total_tokens": 7},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	resp, err := p.Embed(t.Context(), []string{"nmap output", "nuclei output"}, "text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if requestBody["model"] != "text-embedding-3-small" || len(requestBody["input"].([]any)) != 2 {
+		t.Errorf("request body = %v", requestBody)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[0][0] != 0.1 || resp.Embeddings[1][0] != 0.3 {
+		t.Errorf("embeddings = %v, want input order", resp.Embeddings)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 7 {
+		t.Errorf("usage = %+v, want 7 prompt tokens", resp.Usage)
+	}
+
+	if _, err := parseEmbeddingResponse([]byte(`{"data":[{"index":0,"embedding":[1]}]}`), 2); err == nil {
+		t.Error("expected error when embeddings are missing")
+	}
+}
+
+func TestProviderChat_ParallelToolCalls(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody = nil
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}}}
+	opts := map[string]any{"parallel_tool_calls": true}
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	if _, err := p.Chat(t.Context(), msgs, tools, "gpt-4o", opts); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requestBody["parallel_tool_calls"] != true {
+		t.Errorf("parallel_tool_calls = %v, want true", requestBody["parallel_tool_calls"])
+	}
+
+	// Without tools the field is not sent
+	if _, err := p.Chat(t.Context(), msgs, nil, "gpt-4o", opts); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, ok := requestBody["parallel_tool_calls"]; ok {
+		t.Error("parallel_tool_calls sent without tools")
+	}
+}
+
+func TestProviderChat_ToolChoiceAndStop(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody = nil
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}}}
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	if _, err := p.Chat(t.Context(), msgs, tools, "gpt-4o", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requestBody["tool_choice"] != "auto" {
+		t.Errorf("default tool_choice = %v, want auto", requestBody["tool_choice"])
+	}
+
+	opts := map[string]any{"tool_choice": "required", "stop": []string{"\n\n"}}
+	if _, err := p.Chat(t.Context(), msgs, tools, "gpt-4o", opts); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requestBody["tool_choice"] != "required" {
+		t.Errorf("tool_choice = %v, want required", requestBody["tool_choice"])
+	}
+	if stop, _ := requestBody["stop"].([]any); len(stop) != 1 || stop[0] != "\n\n" {
+		t.Errorf("stop = %v, want [\\n\\n]", requestBody["stop"])
+	}
+}
+
+func TestProviderChat_ReasoningEffort(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody = nil
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	if _, err := p.Chat(t.Context(), msgs, nil, "o4-mini", map[string]any{"reasoning_effort": "high", "thinking_budget": 8000}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requestBody["reasoning_effort"] != "high" {
+		t.Errorf("reasoning_effort = %v, want high", requestBody["reasoning_effort"])
+	}
+	// The Anthropic thinking budget is not an OpenAI field
+	if _, ok := requestBody["thinking_budget"]; ok {
+		t.Error("thinking_budget sent to an OpenAI-compatible API")
+	}
+
+	if _, err := p.Chat(t.Context(), msgs, nil, "gpt-4o", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, ok := requestBody["reasoning_effort"]; ok {
+		t.Error("reasoning_effort sent without the option")
+	}
+}
+
+func TestExtractToolCallsFromText(t *testing.T) {
+	content := `<tool_call>{"name":"exec","arguments":{"command":"echo '}'"}}</tool_call>` +
+		`<tool_call>{"name":"broken",` +
+		`[TOOL_CALL] {"name":"read_file","arguments":"{\"path\":\"a.txt\"}"}`
+
+	calls := extractToolCallsFromText(content)
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls, want 2: %+v", len(calls), calls)
+	}
+	if calls[0].Name != "exec" || calls[0].Arguments["command"] != "echo '}'" {
+		t.Errorf("calls[0] = %+v", calls[0])
+	}
+	// The truncated call is skipped, not merged with the next one
+	if calls[1].Name != "read_file" || calls[1].Arguments["path"] != "a.txt" {
+		t.Errorf("calls[1] = %+v", calls[1])
+	}
+}
+
+func TestExtractTextToolCalls_Formats(t *testing.T) {
+	tools := []ToolDefinition{
+		{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}},
+		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file"}},
+	}
+	tests := []struct {
+		name     string
+		format   string
+		content  string
+		wantName []string
+		wantArg  string
+	}{
+		{
+			name:     "hermes yaml",
+			format:   ToolCallFormatHermes,
+			content:  "<tool_call>\nname: exec\narguments:\n  command: nmap -F 10.0.0.5\n</tool_call>",
+			wantName: []string{"exec"},
+			wantArg:  "nmap -F 10.0.0.5",
+		},
+		{
+			name:     "hermes yaml without closing tag is skipped",
+			format:   ToolCallFormatHermes,
+			content:  "<tool_call>\nname: exec\narguments:\n  command: nmap",
+			wantName: nil,
+		},
+		{
+			name:     "llama3 json",
+			format:   ToolCallFormatLlama3,
+			content:  `<|python_tag|>{"name": "exec", "parameters": {"command": "id"}}; {"name": "read_file", "parameters": {"path": "a"}}<|eom_id|>`,
+			wantName: []string{"exec", "read_file"},
+			wantArg:  "id",
+		},
+		{
+			name:     "llama3 builtin call",
+			format:   ToolCallFormatLlama3,
+			content:  `<|python_tag|>brave_search.call(query="cve-2024-3094", count=5)<|eom_id|>`,
+			wantName: []string{"brave_search"},
+		},
+		{
+			name:     "mistral array",
+			format:   ToolCallFormatMistral,
+			content:  `[TOOL_CALLS] [{"name": "exec", "arguments": {"command": "id"}, "id": "a1b2c3d4e"}, {"name": "read_file", "arguments": "{\"path\":\"x\"}"}]`,
+			wantName: []string{"exec", "read_file"},
+			wantArg:  "id",
+		},
+		{
+			name:     "mistral args form",
+			format:   ToolCallFormatMistral,
+			content:  `[TOOL_CALLS]exec[ARGS]{"command": "id"}`,
+			wantName: []string{"exec"},
+			wantArg:  "id",
+		},
+		{
+			name:     "json block",
+			format:   ToolCallFormatJSONBlock,
+			content:  "I'll run it:\n```json\n{\"name\": \"exec\", \"arguments\": {\"command\": \"id\"}}\n```",
+			wantName: []string{"exec"},
+			wantArg:  "id",
+		},
+		{
+			name:     "auto ignores json blocks for unknown tools",
+			format:   ToolCallFormatAuto,
+			content:  "Example:\n```json\n{\"name\": \"alice\", \"arguments\": {}}\n```",
+			wantName: nil,
+		},
+		{
+			name:     "auto finds mistral",
+			format:   "",
+			content:  `[TOOL_CALLS] [{"name": "exec", "arguments": {"command": "id"}}]`,
+			wantName: []string{"exec"},
+			wantArg:  "id",
+		},
+		{
+			name:     "hint excludes other formats",
+			format:   ToolCallFormatLlama3,
+			content:  `<tool_call>{"name":"exec","arguments":{"command":"id"}}</tool_call>`,
+			wantName: nil,
+		},
+		{
+			name:     "none",
+			format:   ToolCallFormatNone,
+			content:  `<tool_call>{"name":"exec","arguments":{"command":"id"}}</tool_call>`,
+			wantName: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := extractTextToolCalls(tt.content, tt.format, tools)
+			var names []string
+			for _, c := range calls {
+				names = append(names, c.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantName) {
+				t.Fatalf("names = %v, want %v (%+v)", names, tt.wantName, calls)
+			}
+			if tt.wantArg != "" && calls[0].Arguments["command"] != tt.wantArg {
+				t.Errorf("arguments = %+v, want command %q", calls[0].Arguments, tt.wantArg)
+			}
+		})
+	}
+
+	calls := extractTextToolCalls(`<|python_tag|>brave_search.call(query="cve-2024-3094", count=5, fresh=True)`, ToolCallFormatLlama3, nil)
+	if args := calls[0].Arguments; args["query"] != "cve-2024-3094" || args["count"] != float64(5) || args["fresh"] != true {
+		t.Errorf("builtin call arguments = %+v", args)
+	}
+}
+
+func TestNewProviderWithOptions_ToolCallFormat(t *testing.T) {
+	if _, err := NewProviderWithOptions("", "http://localhost", "", ClientOptions{ToolCallFormat: "xml"}); err == nil {
+		t.Error("expected an error for an unknown tool call format")
+	}
+	if _, err := NewProviderWithOptions("", "http://localhost", "", ClientOptions{ToolCallFormat: ToolCallFormatMistral}); err != nil {
+		t.Errorf("mistral format: %v", err)
+	}
+}
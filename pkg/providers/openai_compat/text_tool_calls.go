@@ -0,0 +1,352 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
+)
+
+// Text tool-call formats, set per model with ClientOptions.ToolCallFormat.
+// They only matter when a model writes its tool calls into the reply text
+// instead of the structured tool_calls field.
+const (
+	ToolCallFormatAuto         = "auto"         // Try every format below (default)
+	ToolCallFormatFunctionCall = "functioncall" // <functioncall>, <tool_call> or [TOOL_CALL] followed by JSON
+	ToolCallFormatHermes       = "hermes"       // <tool_call>...</tool_call> with a JSON or YAML body
+	ToolCallFormatLlama3       = "llama3"       // <|python_tag|> followed by JSON or name.call(key=value)
+	ToolCallFormatMistral      = "mistral"      // [TOOL_CALLS] followed by a JSON array or name[ARGS]{...}
+	ToolCallFormatJSONBlock    = "json_block"   // ```json fences holding {"name","arguments"} objects
+	ToolCallFormatNone         = "none"         // Never read tool calls from text
+)
+
+// textToolCallParsers maps each format to its parser
+var textToolCallParsers = map[string]func(string) []ToolCall{
+	ToolCallFormatFunctionCall: extractToolCallsFromText,
+	ToolCallFormatHermes:       extractHermesToolCalls,
+	ToolCallFormatLlama3:       extractLlama3ToolCalls,
+	ToolCallFormatMistral:      extractMistralToolCalls,
+	ToolCallFormatJSONBlock:    extractJSONBlockToolCalls,
+}
+
+// autoToolCallFormats is the order formats are tried in without a hint.
+// Fenced JSON comes last since plenty of ordinary replies contain it.
+var autoToolCallFormats = []string{
+	ToolCallFormatFunctionCall,
+	ToolCallFormatHermes,
+	ToolCallFormatMistral,
+	ToolCallFormatLlama3,
+	ToolCallFormatJSONBlock,
+}
+
+// validToolCallFormat reports whether format is a known format or empty
+func validToolCallFormat(format string) bool {
+	_, ok := textToolCallParsers[format]
+	return ok || format == "" || format == ToolCallFormatAuto || format == ToolCallFormatNone
+}
+
+// extractTextToolCalls parses tool calls written into content in the given
+// format. Without a hint every format is tried and the first one that finds
+// calls wins; fenced JSON then only counts when it names one of tools, so a
+// reply that merely shows an example object is left alone.
+func extractTextToolCalls(content, format string, tools []ToolDefinition) []ToolCall {
+	switch format {
+	case ToolCallFormatNone:
+		return nil
+	case "", ToolCallFormatAuto:
+		for _, f := range autoToolCallFormats {
+			calls := textToolCallParsers[f](content)
+			if f == ToolCallFormatJSONBlock {
+				calls = knownToolCalls(calls, tools)
+			}
+			if len(calls) > 0 {
+				return calls
+			}
+		}
+		return nil
+	}
+	if parse, ok := textToolCallParsers[format]; ok {
+		return parse(content)
+	}
+	return nil
+}
+
+// knownToolCalls drops calls to tools that were not offered
+func knownToolCalls(calls []ToolCall, tools []ToolDefinition) []ToolCall {
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Function.Name] = true
+	}
+	var kept []ToolCall
+	for _, call := range calls {
+		if known[call.Name] {
+			call.ID = fmt.Sprintf("textcall_%d", len(kept))
+			kept = append(kept, call)
+		}
+	}
+	return kept
+}
+
+// textToolCallBody is a tool call as models write it in text. Llama 3 names
+// the arguments "parameters"; either may be a stringified JSON object.
+type textToolCallBody struct {
+	ID         string `json:"id"         yaml:"id"`
+	Name       string `json:"name"       yaml:"name"`
+	Arguments  any    `json:"arguments"  yaml:"arguments"`
+	Parameters any    `json:"parameters" yaml:"parameters"`
+}
+
+func (b textToolCallBody) toolCall(index int) (ToolCall, bool) {
+	if b.Name == "" {
+		return ToolCall{}, false
+	}
+	args := b.Arguments
+	if args == nil {
+		args = b.Parameters
+	}
+
+	arguments := make(map[string]any)
+	switch args := args.(type) {
+	case map[string]any:
+		arguments = args
+	case string:
+		// Some models stringify the arguments JSON
+		if err := json.Unmarshal([]byte(args), &arguments); err != nil {
+			arguments["raw"] = args
+		}
+	}
+
+	id := b.ID
+	if id == "" {
+		id = fmt.Sprintf("textcall_%d", index)
+	}
+	return ToolCall{ID: id, Name: b.Name, Arguments: arguments}, true
+}
+
+// decodeTextToolCall decodes a {"name":...,"arguments":...} object
+func decodeTextToolCall(jsonStr string, index int) (ToolCall, bool) {
+	var body textToolCallBody
+	if err := json.Unmarshal([]byte(jsonStr), &body); err != nil {
+		log.Printf("openai_compat: failed to parse text tool call: %v", err)
+		return ToolCall{}, false
+	}
+	return body.toolCall(index)
+}
+
+// decodeTextToolCallList decodes a JSON array of tool call objects, or a
+// single object, appending the calls to toolCalls
+func decodeTextToolCallList(jsonStr string, toolCalls []ToolCall) []ToolCall {
+	var bodies []textToolCallBody
+	if err := json.Unmarshal([]byte(jsonStr), &bodies); err != nil {
+		var body textToolCallBody
+		if err := json.Unmarshal([]byte(jsonStr), &body); err != nil {
+			log.Printf("openai_compat: failed to parse text tool calls: %v", err)
+			return toolCalls
+		}
+		bodies = []textToolCallBody{body}
+	}
+	for _, body := range bodies {
+		if call, ok := body.toolCall(len(toolCalls)); ok {
+			toolCalls = append(toolCalls, call)
+		}
+	}
+	return toolCalls
+}
+
+// firstJSONValue returns the complete JSON array or object at the start of
+// s, ignoring leading whitespace, or "" if there is none
+func firstJSONValue(s string) string {
+	start := len(s) - len(strings.TrimLeft(s, " \t\r\n"))
+	end := llmjson.End(s, start)
+	if end == -1 || !json.Valid([]byte(s[start:end])) {
+		return ""
+	}
+	return s[start:end]
+}
+
+var (
+	hermesOpenPattern  = regexp.MustCompile(`<tool_call>`)
+	yamlFencePattern   = regexp.MustCompile("(?s)^```(?:ya?ml)?[ \t]*\n(.*?)\n?```$")
+	llama3TagPattern   = regexp.MustCompile(`<\|python_tag\|>`)
+	llama3EndPattern   = regexp.MustCompile(`<\|(?:eom_id|eot_id|eom|end_of_text)\|>`)
+	llama3CallPattern  = regexp.MustCompile(`(?m)^\s*([A-Za-z_]\w*)\.call\((.*)\)\s*$`)
+	llama3KwargPattern = regexp.MustCompile(`(\w+)\s*=\s*("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|[^,]+)`)
+	mistralTagPattern  = regexp.MustCompile(`\[TOOL_CALLS\]`)
+	mistralArgsPattern = regexp.MustCompile(`^\s*([A-Za-z_][\w.-]*)\s*\[ARGS\]`)
+	jsonBlockPattern   = regexp.MustCompile("(?s)```(?:json|tool_call|tool)?[ \t]*\n(.*?)```")
+)
+
+// extractHermesToolCalls parses Hermes-style calls, where each call sits
+// between <tool_call> and </tool_call>. Bodies are JSON or, as some
+// fine-tunes write them, YAML with name and arguments keys. A YAML body
+// without its closing tag is skipped, since YAML cut off mid-way still
+// parses and the call could run with arguments missing.
+func extractHermesToolCalls(content string) []ToolCall {
+	var toolCalls []ToolCall
+
+	tagLocs := hermesOpenPattern.FindAllStringIndex(content, -1)
+	for i, loc := range tagLocs {
+		segment := content[loc[1]:]
+		if i+1 < len(tagLocs) {
+			segment = content[loc[1]:tagLocs[i+1][0]]
+		}
+		body, _, closed := strings.Cut(segment, "</tool_call>")
+		body = strings.TrimSpace(body)
+
+		if strings.HasPrefix(body, "{") {
+			if jsonStr := llmjson.Balanced(body); jsonStr != "" {
+				if call, ok := decodeTextToolCall(jsonStr, len(toolCalls)); ok {
+					toolCalls = append(toolCalls, call)
+				}
+			}
+			continue
+		}
+		if !closed {
+			continue
+		}
+		if m := yamlFencePattern.FindStringSubmatch(body); m != nil {
+			body = m[1]
+		}
+		var call textToolCallBody
+		if err := yaml.Unmarshal([]byte(body), &call); err != nil {
+			log.Printf("openai_compat: failed to parse YAML tool call: %v", err)
+			continue
+		}
+		if tc, ok := call.toolCall(len(toolCalls)); ok {
+			toolCalls = append(toolCalls, tc)
+		}
+	}
+
+	return toolCalls
+}
+
+// extractLlama3ToolCalls parses Llama 3.x calls that follow <|python_tag|>:
+// one or more {"name":...,"parameters":...} objects separated by ";" or
+// built-in tool calls like brave_search.call(query="...").
+func extractLlama3ToolCalls(content string) []ToolCall {
+	var toolCalls []ToolCall
+
+	tagLocs := llama3TagPattern.FindAllStringIndex(content, -1)
+	for i, loc := range tagLocs {
+		segment := content[loc[1]:]
+		if i+1 < len(tagLocs) {
+			segment = content[loc[1]:tagLocs[i+1][0]]
+		}
+		if end := llama3EndPattern.FindStringIndex(segment); end != nil {
+			segment = segment[:end[0]]
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(segment), "{") {
+			for rest := segment; ; {
+				start := strings.IndexByte(rest, '{')
+				if start == -1 {
+					break
+				}
+				end := llmjson.End(rest, start)
+				if end == -1 {
+					break
+				}
+				if json.Valid([]byte(rest[start:end])) {
+					if call, ok := decodeTextToolCall(rest[start:end], len(toolCalls)); ok {
+						toolCalls = append(toolCalls, call)
+					}
+				}
+				rest = rest[end:]
+			}
+			continue
+		}
+
+		for _, m := range llama3CallPattern.FindAllStringSubmatch(segment, -1) {
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        fmt.Sprintf("textcall_%d", len(toolCalls)),
+				Name:      m[1],
+				Arguments: parseLlama3Kwargs(m[2]),
+			})
+		}
+	}
+
+	return toolCalls
+}
+
+// parseLlama3Kwargs parses the key=value arguments of a built-in tool call.
+// Quoted values are strings; numbers and booleans keep their type.
+func parseLlama3Kwargs(s string) map[string]any {
+	arguments := make(map[string]any)
+	for _, m := range llama3KwargPattern.FindAllStringSubmatch(s, -1) {
+		value := strings.TrimSpace(m[2])
+		switch {
+		case strings.HasPrefix(value, `"`):
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				arguments[m[1]] = unquoted
+				continue
+			}
+			arguments[m[1]] = strings.Trim(value, `"`)
+		case strings.HasPrefix(value, "'"):
+			arguments[m[1]] = strings.ReplaceAll(strings.Trim(value, "'"), `\'`, "'")
+		case value == "True" || value == "False":
+			arguments[m[1]] = value == "True"
+		default:
+			var v any
+			if err := json.Unmarshal([]byte(value), &v); err == nil {
+				arguments[m[1]] = v
+			} else {
+				arguments[m[1]] = value
+			}
+		}
+	}
+	return arguments
+}
+
+// extractMistralToolCalls parses Mistral calls: [TOOL_CALLS] followed by a
+// JSON array of {"name","arguments","id"} objects, or the newer
+// [TOOL_CALLS]name[ARGS]{...} form with one call per tag.
+func extractMistralToolCalls(content string) []ToolCall {
+	var toolCalls []ToolCall
+
+	tagLocs := mistralTagPattern.FindAllStringIndex(content, -1)
+	for i, loc := range tagLocs {
+		segment := content[loc[1]:]
+		if i+1 < len(tagLocs) {
+			segment = content[loc[1]:tagLocs[i+1][0]]
+		}
+
+		if m := mistralArgsPattern.FindStringSubmatchIndex(segment); m != nil {
+			name := segment[m[2]:m[3]]
+			args := firstJSONValue(segment[m[1]:])
+			if args == "" {
+				continue
+			}
+			body := textToolCallBody{Name: name, Arguments: args}
+			if call, ok := body.toolCall(len(toolCalls)); ok {
+				toolCalls = append(toolCalls, call)
+			}
+			continue
+		}
+
+		if jsonStr := firstJSONValue(segment); jsonStr != "" {
+			toolCalls = decodeTextToolCallList(jsonStr, toolCalls)
+		}
+	}
+
+	return toolCalls
+}
+
+// extractJSONBlockToolCalls parses ```json fences that hold a tool call
+// object or an array of them
+func extractJSONBlockToolCalls(content string) []ToolCall {
+	var toolCalls []ToolCall
+	for _, m := range jsonBlockPattern.FindAllStringSubmatch(content, -1) {
+		body := strings.TrimSpace(m[1])
+		if !json.Valid([]byte(body)) {
+			continue
+		}
+		toolCalls = decodeTextToolCallList(body, toolCalls)
+	}
+	return toolCalls
+}
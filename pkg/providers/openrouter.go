@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// openRouterFields builds the request fields for an OpenRouter model: the
+// "provider" routing block and, with fallbacks, the "models" array led by
+// the primary model. It returns nil when there is nothing to send.
+func openRouterFields(cfg *config.OpenRouterConfig, modelID string) map[string]any {
+	if cfg == nil {
+		return nil
+	}
+	fields := make(map[string]any)
+
+	routing := make(map[string]any)
+	if len(cfg.Order) > 0 {
+		routing["order"] = cfg.Order
+	}
+	if len(cfg.Only) > 0 {
+		routing["only"] = cfg.Only
+	}
+	if len(cfg.Ignore) > 0 {
+		routing["ignore"] = cfg.Ignore
+	}
+	if cfg.AllowFallbacks != nil {
+		routing["allow_fallbacks"] = *cfg.AllowFallbacks
+	}
+	if cfg.RequireParameters {
+		routing["require_parameters"] = true
+	}
+	if cfg.DataCollection != "" {
+		routing["data_collection"] = cfg.DataCollection
+	}
+	if cfg.Sort != "" {
+		routing["sort"] = cfg.Sort
+	}
+	if len(routing) > 0 {
+		fields["provider"] = routing
+	}
+
+	if len(cfg.Fallbacks) > 0 {
+		models := append([]string{modelID}, cfg.Fallbacks...)
+		fields["models"] = models
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
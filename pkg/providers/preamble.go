@@ -0,0 +1,55 @@
+package providers
+
+// WithSystemPreamble returns messages with preamble placed ahead of the system
+// prompt. The input slice is not modified. If the conversation has no leading
+// system message, one is inserted.
+func WithSystemPreamble(messages []Message, preamble string) []Message {
+	if preamble == "" {
+		return messages
+	}
+
+	if len(messages) == 0 || messages[0].Role != "system" {
+		out := make([]Message, 0, len(messages)+1)
+		out = append(out, Message{Role: "system", Content: preamble})
+		return append(out, messages...)
+	}
+
+	out := make([]Message, len(messages))
+	copy(out, messages)
+
+	system := messages[0]
+	system.Content = preamble + "\n\n---\n\n" + system.Content
+	if len(system.SystemParts) > 0 {
+		parts := make([]ContentBlock, 0, len(system.SystemParts)+1)
+		parts = append(parts, ContentBlock{Type: "text", Text: preamble})
+		system.SystemParts = append(parts, system.SystemParts...)
+	}
+	out[0] = system
+	return out
+}
+
+// WithSystemAddendum returns messages with addendum appended to the system
+// prompt. The input slice is not modified. If the conversation has no leading
+// system message, one is inserted.
+func WithSystemAddendum(messages []Message, addendum string) []Message {
+	if addendum == "" {
+		return messages
+	}
+
+	if len(messages) == 0 || messages[0].Role != "system" {
+		return WithSystemPreamble(messages, addendum)
+	}
+
+	out := make([]Message, len(messages))
+	copy(out, messages)
+
+	system := messages[0]
+	system.Content = system.Content + "\n\n---\n\n" + addendum
+	if len(system.SystemParts) > 0 {
+		parts := make([]ContentBlock, 0, len(system.SystemParts)+1)
+		parts = append(parts, system.SystemParts...)
+		system.SystemParts = append(parts, ContentBlock{Type: "text", Text: addendum})
+	}
+	out[0] = system
+	return out
+}
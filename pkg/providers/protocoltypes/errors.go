@@ -0,0 +1,121 @@
+package protocoltypes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Typed provider failures. Providers wrap HTTP and SDK errors in an APIError
+// carrying one of these kinds so callers can branch with errors.Is instead of
+// matching on message text.
+var (
+	ErrRateLimited     = errors.New("rate limited")
+	ErrContextTooLong  = errors.New("context too long")
+	ErrAuthFailed      = errors.New("authentication failed")
+	ErrContentFiltered = errors.New("content filtered")
+	ErrOverloaded      = errors.New("provider overloaded")
+	ErrInvalidRequest  = errors.New("invalid request") // Rejected before sending; see providers.RequestValidator
+)
+
+var (
+	contextTooLongMarkers = []string{
+		"context_length_exceeded",
+		"context length",
+		"context window",
+		"maximum context",
+		"prompt is too long",
+		"input is too long",
+		"too many tokens",
+		"reduce the length",
+	}
+
+	contentFilterMarkers = []string{
+		"content_filter",
+		"content filter",
+		"content management policy",
+		"responsible ai",
+		"safety settings",
+		"blocked due to safety",
+	}
+)
+
+// APIError is a failed provider call annotated with its typed kind.
+type APIError struct {
+	StatusCode int
+	Body       string
+	Kind       error // one of the Err* sentinels, nil when unclassified
+	Err        error // underlying SDK or transport error, if any
+}
+
+// NewAPIError builds an APIError from a raw HTTP status and response body.
+func NewAPIError(statusCode int, body string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Body:       body,
+		Kind:       ClassifyAPIError(statusCode, body),
+	}
+}
+
+// WrapAPIError tags an existing error with the kind derived from the status
+// code and the error's message.
+func WrapAPIError(statusCode int, err error) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Kind:       ClassifyAPIError(statusCode, err.Error()),
+		Err:        err,
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("API request failed:\n  Status: %d\n  Body:   %s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// ClassifyAPIError maps an HTTP status and error body to a typed kind.
+// Returns nil when the failure does not match any known kind.
+func ClassifyAPIError(statusCode int, body string) error {
+	lower := strings.ToLower(body)
+	overloaded := strings.Contains(lower, "overloaded")
+
+	switch {
+	case statusCode == 401 || statusCode == 403:
+		return ErrAuthFailed
+	case statusCode == 529 || (statusCode == 503 && overloaded):
+		return ErrOverloaded
+	case statusCode == 429:
+		if overloaded {
+			return ErrOverloaded
+		}
+		return ErrRateLimited
+	case containsAny(lower, contextTooLongMarkers):
+		return ErrContextTooLong
+	case containsAny(lower, contentFilterMarkers):
+		return ErrContentFiltered
+	case overloaded:
+		return ErrOverloaded
+	}
+	return nil
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
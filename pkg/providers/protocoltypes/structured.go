@@ -0,0 +1,75 @@
+package protocoltypes
+
+// ResponseSchemaOption is the Chat options key for a *ResponseSchema.
+const ResponseSchemaOption = "response_schema"
+
+// ResponseSchema asks a provider to reply with a single JSON object matching
+// Schema. OpenAI-compatible endpoints receive it as a json_schema
+// response_format, Anthropic as a forced tool call and Gemini as a
+// response_schema. Providers without structured output ignore it, so callers
+// should still tolerate prose around the object.
+type ResponseSchema struct {
+	Name        string         // Identifier for the schema, e.g. "validation_decision"
+	Description string         // What the object represents
+	Schema      map[string]any // JSON schema of the reply
+	Strict      bool           // Ask the provider to enforce the schema exactly
+}
+
+// ResponseFormat returns the schema as an OpenAI json_schema response_format.
+func (s *ResponseSchema) ResponseFormat() map[string]any {
+	spec := map[string]any{
+		"name":   s.name(),
+		"schema": s.Schema,
+	}
+	if s.Description != "" {
+		spec["description"] = s.Description
+	}
+	if s.Strict {
+		spec["strict"] = true
+	}
+	return map[string]any{
+		"type":        "json_schema",
+		"json_schema": spec,
+	}
+}
+
+func (s *ResponseSchema) name() string {
+	if s.Name == "" {
+		return "structured_output"
+	}
+	return s.Name
+}
+
+// ResponseSchemaFromOptions returns the structured output schema requested in
+// options, either as a ResponseSchema or as a raw OpenAI json_schema
+// response_format map.
+func ResponseSchemaFromOptions(options map[string]any) (*ResponseSchema, bool) {
+	switch s := options[ResponseSchemaOption].(type) {
+	case *ResponseSchema:
+		if s != nil && s.Schema != nil {
+			return s, true
+		}
+	case ResponseSchema:
+		if s.Schema != nil {
+			return &s, true
+		}
+	}
+
+	rf, ok := options["response_format"].(map[string]any)
+	if !ok || rf["type"] != "json_schema" {
+		return nil, false
+	}
+	spec, ok := rf["json_schema"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	schema, ok := spec["schema"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	s := &ResponseSchema{Schema: schema}
+	s.Name, _ = spec["name"].(string)
+	s.Description, _ = spec["description"].(string)
+	s.Strict, _ = spec["strict"].(bool)
+	return s, true
+}
@@ -25,17 +25,27 @@ type FunctionCall struct {
 }
 
 type LLMResponse struct {
-	Content          string     `json:"content"`
-	ReasoningContent string     `json:"reasoning_content,omitempty"`
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
-	FinishReason     string     `json:"finish_reason"`
-	Usage            *UsageInfo `json:"usage,omitempty"`
+	Content           string     `json:"content"`
+	ReasoningContent  string     `json:"reasoning_content,omitempty"`
+	ThinkingSignature string     `json:"thinking_signature,omitempty"` // Anthropic signature of ReasoningContent, needed to replay it
+	ToolCalls         []ToolCall `json:"tool_calls,omitempty"`
+	FinishReason      string     `json:"finish_reason"`
+	Usage             *UsageInfo `json:"usage,omitempty"`
+	Refused           bool       `json:"refused,omitempty"`   // Provider declined or content-filtered the request
+	Model             string     `json:"model,omitempty"`     // model_name that served the request, when routed
+	Artifacts         []string   `json:"artifacts,omitempty"` // Files the task's output pipeline wrote, when routed
 }
 
 type UsageInfo struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+
+	// Breakdowns already counted in PromptTokens and CompletionTokens, so
+	// cache hits and reasoning can be billed at their own rates
+	CachedPromptTokens int `json:"cached_prompt_tokens,omitempty"` // Prompt tokens read from the prompt cache
+	CacheWriteTokens   int `json:"cache_write_tokens,omitempty"`   // Prompt tokens written to the prompt cache
+	ReasoningTokens    int `json:"reasoning_tokens,omitempty"`     // Completion tokens spent on hidden reasoning
 }
 
 // CacheControl marks a content block for LLM-side prefix caching.
@@ -53,13 +63,34 @@ type ContentBlock struct {
 	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
+// ImagePart is an image attached to a user or tool message, either inline
+// as base64 Data with its MediaType or by URL. Providers without vision
+// support ignore it.
+type ImagePart struct {
+	MediaType string `json:"media_type,omitempty"` // "image/png", "image/jpeg", "image/gif" or "image/webp"
+	Data      string `json:"data,omitempty"`       // base64, without a data: prefix
+	URL       string `json:"url,omitempty"`
+	Detail    string `json:"detail,omitempty"` // OpenAI fidelity hint: "low", "high" or "auto"
+}
+
+// DataURL returns the image as a URL: the URL itself, or a data: URL for
+// inline images.
+func (p ImagePart) DataURL() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return "data:" + p.MediaType + ";base64," + p.Data
+}
+
 type Message struct {
-	Role             string         `json:"role"`
-	Content          string         `json:"content"`
-	ReasoningContent string         `json:"reasoning_content,omitempty"`
-	SystemParts      []ContentBlock `json:"system_parts,omitempty"` // structured system blocks for cache-aware adapters
-	ToolCalls        []ToolCall     `json:"tool_calls,omitempty"`
-	ToolCallID       string         `json:"tool_call_id,omitempty"`
+	Role              string         `json:"role"`
+	Content           string         `json:"content"`
+	ReasoningContent  string         `json:"reasoning_content,omitempty"`
+	ThinkingSignature string         `json:"thinking_signature,omitempty"` // Anthropic signature of ReasoningContent
+	SystemParts       []ContentBlock `json:"system_parts,omitempty"`       // structured system blocks for cache-aware adapters
+	Images            []ImagePart    `json:"images,omitempty"`             // image inputs for vision-capable models
+	ToolCalls         []ToolCall     `json:"tool_calls,omitempty"`
+	ToolCallID        string         `json:"tool_call_id,omitempty"`
 }
 
 // DeepCopy returns a fully independent copy of the Message, including all
@@ -105,6 +136,11 @@ func (m Message) DeepCopy() Message {
 		}
 	}
 
+	if len(m.Images) > 0 {
+		cp.Images = make([]ImagePart, len(m.Images))
+		copy(cp.Images, m.Images)
+	}
+
 	return cp
 }
 
@@ -118,3 +154,28 @@ type ToolFunctionDefinition struct {
 	Description string         `json:"description"`
 	Parameters  map[string]any `json:"parameters"`
 }
+
+// BatchRequest is one request in an offline batch. CustomID matches it to
+// its BatchResult and must be unique within the batch.
+type BatchRequest struct {
+	CustomID string
+	Messages []Message
+	Tools    []ToolDefinition
+	Model    string
+	Options  map[string]any
+}
+
+// BatchResult is the outcome of one BatchRequest: Response when it
+// succeeded, otherwise Error (errored, canceled or expired).
+type BatchResult struct {
+	CustomID string
+	Response *LLMResponse
+	Error    string
+}
+
+// EmbeddingResponse holds one vector per input, in input order.
+type EmbeddingResponse struct {
+	Embeddings [][]float32
+	Model      string
+	Usage      *UsageInfo
+}
@@ -0,0 +1,71 @@
+package providers
+
+import "strings"
+
+// RefusalNotice prefixes refused responses so they are never mistaken for a
+// real answer in history, reports, or the TUI.
+const RefusalNotice = "[provider refused] "
+
+// refusalScanLimit bounds how much of a response is checked for refusal
+// phrasing. Real answers often carry a short disclaimer; a refusal is short
+// and leads with it.
+const refusalScanLimit = 600
+
+var refusalPhrases = []string{
+	"i can't help with",
+	"i cannot help with",
+	"i can't assist with",
+	"i cannot assist with",
+	"i'm not able to help with",
+	"i am not able to help with",
+	"i'm unable to help with",
+	"i am unable to help with",
+	"i won't be able to help",
+	"i can't provide",
+	"i cannot provide",
+	"i'm sorry, but i can't",
+	"i'm sorry, but i cannot",
+	"i apologize, but i can't",
+	"i apologize, but i cannot",
+	"against my guidelines",
+	"violates my guidelines",
+}
+
+// IsRefusal reports whether the response is a provider refusal or a
+// content-filter block rather than an answer.
+func IsRefusal(resp *LLMResponse) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.Refused || resp.FinishReason == "content_filter" || resp.FinishReason == "refusal" {
+		return true
+	}
+	if len(resp.ToolCalls) > 0 {
+		return false
+	}
+
+	content := strings.TrimSpace(resp.Content)
+	if content == "" || len(content) > refusalScanLimit {
+		return false
+	}
+	// Normalize curly apostrophes some models emit.
+	content = strings.ToLower(strings.ReplaceAll(content, "’", "'"))
+	for _, phrase := range refusalPhrases {
+		if strings.Contains(content, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotateRefusal marks a refused response and prefixes its content with
+// RefusalNotice. It is idempotent.
+func AnnotateRefusal(resp *LLMResponse) {
+	if resp == nil {
+		return
+	}
+	resp.Refused = true
+	if !strings.HasPrefix(resp.Content, RefusalNotice) {
+		resp.Content = RefusalNotice + resp.Content
+	}
+}
@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsRefusal(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *LLMResponse
+		want bool
+	}{
+		{"nil", nil, false},
+		{"content filter finish", &LLMResponse{FinishReason: "content_filter"}, true},
+		{"refusal phrase", &LLMResponse{Content: "I can't help with exploiting that service."}, true},
+		{"curly apostrophe", &LLMResponse{Content: "I’m sorry, but I can’t do that."}, true},
+		{"normal answer", &LLMResponse{Content: "Port 22 is open running OpenSSH 8.9."}, false},
+		{
+			"tool call with disclaimer",
+			&LLMResponse{Content: "I cannot provide guarantees, running nmap.", ToolCalls: []ToolCall{{ID: "1"}}},
+			false,
+		},
+		{
+			"long answer with disclaimer",
+			&LLMResponse{Content: "I cannot provide legal advice. " + strings.Repeat("Findings follow. ", 60)},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRefusal(tt.resp); got != tt.want {
+				t.Errorf("IsRefusal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateRefusal_Idempotent(t *testing.T) {
+	resp := &LLMResponse{Content: "I can't help with that."}
+	AnnotateRefusal(resp)
+	AnnotateRefusal(resp)
+
+	if !resp.Refused {
+		t.Error("expected Refused to be set")
+	}
+	if resp.Content != RefusalNotice+"I can't help with that." {
+		t.Errorf("content = %q", resp.Content)
+	}
+}
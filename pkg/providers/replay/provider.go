@@ -0,0 +1,195 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+var (
+	// ErrExhausted is returned once every response for a model has been served
+	ErrExhausted = errors.New("replay transcript exhausted")
+	// ErrUnexpectedRequest is returned when a scripted step's expect text is
+	// not in the request, which means the run left the script
+	ErrUnexpectedRequest = errors.New("request does not match the script")
+)
+
+// step is one response the provider can serve
+type step struct {
+	model    string // Serve only to requests for this model; empty serves any
+	expect   string
+	response *protocoltypes.LLMResponse
+	err      string
+	delay    time.Duration
+	served   bool
+}
+
+// Provider serves canned responses from a recorded transcript or a
+// hand-written script. Each request gets the first unserved response whose
+// model filter allows it, so recordings play back in order and scripts can
+// interleave calls for different models, such as supervision reviews.
+// Recorded errors are returned as errors.
+type Provider struct {
+	path         string
+	defaultModel string
+	fallback     *protocoltypes.LLMResponse
+
+	mu    sync.Mutex
+	steps []*step
+}
+
+// NewProvider loads path. Files ending in .jsonl are recorded transcripts;
+// .json, .yaml and .yml files are scripts.
+func NewProvider(path string) (*Provider, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		script, err := LoadScript(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewScriptProvider(path, script)
+	}
+
+	entries, err := ReadTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("transcript %s has no entries", path)
+	}
+	p := &Provider{path: path, defaultModel: entries[0].Model}
+	for i, entry := range entries {
+		if entry.Response == nil && entry.Error == "" {
+			return nil, fmt.Errorf("transcript %s: entry %d has no response", path, i+1)
+		}
+		p.steps = append(p.steps, &step{response: entry.Response, err: entry.Error})
+	}
+	return p, nil
+}
+
+// NewScriptProvider serves the steps of script. name labels errors.
+func NewScriptProvider(name string, script *Script) (*Provider, error) {
+	if len(script.Steps) == 0 && script.Fallback == "" {
+		return nil, fmt.Errorf("script %s has no steps", name)
+	}
+	p := &Provider{path: name, defaultModel: script.Model}
+	for i, s := range script.Steps {
+		st, err := s.build(i)
+		if err != nil {
+			return nil, fmt.Errorf("script %s: step %d: %w", name, i+1, err)
+		}
+		p.steps = append(p.steps, st)
+	}
+	if script.Fallback != "" {
+		p.fallback = &protocoltypes.LLMResponse{Content: script.Fallback, FinishReason: "stop"}
+	}
+	return p, nil
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []protocoltypes.Message,
+	tools []protocoltypes.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*protocoltypes.LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	st, index, err := p.take(model, messages)
+	if err != nil {
+		return nil, err
+	}
+	if st.delay > 0 {
+		select {
+		case <-time.After(st.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if st.err != "" {
+		return nil, errors.New(st.err)
+	}
+	if st.response == nil {
+		return nil, fmt.Errorf("%s: step %d has no response", p.path, index+1)
+	}
+	// Copy the tool calls, since callers may repair their arguments in place
+	resp := *st.response
+	resp.ToolCalls = nil
+	for _, tc := range st.response.ToolCalls {
+		if tc.Arguments != nil {
+			args := make(map[string]any, len(tc.Arguments))
+			for k, v := range tc.Arguments {
+				args[k] = v
+			}
+			tc.Arguments = args
+		}
+		if tc.Function != nil {
+			fn := *tc.Function
+			tc.Function = &fn
+		}
+		resp.ToolCalls = append(resp.ToolCalls, tc)
+	}
+	// Routers bill every response, so steps without usage report zero
+	resp.Usage = &protocoltypes.UsageInfo{}
+	if st.response.Usage != nil {
+		*resp.Usage = *st.response.Usage
+	}
+	return &resp, nil
+}
+
+// take marks the next response for model as served and returns it
+func (p *Provider) take(model string, messages []protocoltypes.Message) (*step, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, st := range p.steps {
+		if st.served || (st.model != "" && st.model != model) {
+			continue
+		}
+		if st.expect != "" && !strings.Contains(lastInput(messages), st.expect) {
+			return nil, i, fmt.Errorf("%w: %s step %d expects %q", ErrUnexpectedRequest, p.path, i+1, st.expect)
+		}
+		st.served = true
+		return st, i, nil
+	}
+	if p.fallback != nil {
+		return &step{response: p.fallback}, len(p.steps), nil
+	}
+	return nil, 0, fmt.Errorf("%w (%s, model %s)", ErrExhausted, p.path, model)
+}
+
+func (p *Provider) GetDefaultModel() string {
+	return p.defaultModel
+}
+
+// Remaining returns how many responses are left to serve
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, st := range p.steps {
+		if !st.served {
+			n++
+		}
+	}
+	return n
+}
+
+// lastInput is the content of the last user or tool message, which is what
+// a scripted step's expect text is checked against
+func lastInput(messages []protocoltypes.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" || messages[i].Role == "tool" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func userMsg(content string) []protocoltypes.Message {
+	return []protocoltypes.Message{{Role: "user", Content: content}}
+}
+
+func TestScriptProvider_ToolCallsAndExpect(t *testing.T) {
+	path := writeFile(t, "script.yaml", `
+model: demo
+steps:
+  - content: Scanning
+    tool_calls:
+      - name: exec
+        arguments: {command: "nmap -F 10.0.0.5", timeout: 30}
+  - expect: "22/tcp"
+    content: SSH is open
+    usage: {prompt_tokens: 10, completion_tokens: 5}
+`)
+	p, err := NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if p.GetDefaultModel() != "demo" {
+		t.Errorf("GetDefaultModel() = %q", p.GetDefaultModel())
+	}
+
+	ctx := context.Background()
+	resp, err := p.Chat(ctx, userMsg("scan 10.0.0.5"), nil, "demo", nil)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("response = %+v", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "call_1_1" || tc.Name != "exec" || tc.Arguments["command"] != "nmap -F 10.0.0.5" {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tc.Function == nil || tc.Function.Arguments != `{"command":"nmap -F 10.0.0.5","timeout":30}` {
+		t.Errorf("function = %+v", tc.Function)
+	}
+
+	// The next step expects the scan output
+	if _, err := p.Chat(ctx, userMsg("nothing useful"), nil, "demo", nil); !errors.Is(err, ErrUnexpectedRequest) {
+		t.Fatalf("mismatch error = %v, want ErrUnexpectedRequest", err)
+	}
+	msgs := []protocoltypes.Message{
+		{Role: "user", Content: "scan 10.0.0.5"},
+		{Role: "tool", Content: "22/tcp open ssh"},
+	}
+	resp, err = p.Chat(ctx, msgs, nil, "demo", nil)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "SSH is open" || resp.FinishReason != "stop" || resp.Usage.TotalTokens != 15 {
+		t.Errorf("response = %+v", resp)
+	}
+
+	if _, err := p.Chat(ctx, msgs, nil, "demo", nil); !errors.Is(err, ErrExhausted) {
+		t.Errorf("exhausted error = %v, want ErrExhausted", err)
+	}
+}
+
+func TestScriptProvider_ModelFilterAndFallback(t *testing.T) {
+	p, err := NewScriptProvider("inline", &Script{
+		Fallback: "done",
+		Steps: []ScriptStep{
+			{Content: "worker 1"},
+			{Model: "supervisor", Content: "approved"},
+			{Content: "worker 2"},
+			{Error: "rate limited"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, c := range []struct{ model, want string }{
+		{"worker", "worker 1"},
+		{"worker", "worker 2"},
+		{"supervisor", "approved"},
+	} {
+		resp, err := p.Chat(ctx, userMsg("go"), nil, c.model, nil)
+		if err != nil || resp.Content != c.want {
+			t.Errorf("Chat(%s) = %+v, %v; want %q", c.model, resp, err, c.want)
+		}
+	}
+	if _, err := p.Chat(ctx, userMsg("go"), nil, "worker", nil); err == nil || err.Error() != "rate limited" {
+		t.Errorf("scripted error = %v", err)
+	}
+	if p.Remaining() != 0 {
+		t.Errorf("Remaining() = %d, want 0", p.Remaining())
+	}
+	resp, err := p.Chat(ctx, userMsg("go"), nil, "worker", nil)
+	if err != nil || resp.Content != "done" {
+		t.Errorf("fallback = %+v, %v", resp, err)
+	}
+}
+
+func TestScriptProvider_Invalid(t *testing.T) {
+	if _, err := NewScriptProvider("empty", &Script{}); err == nil {
+		t.Error("expected an error for an empty script")
+	}
+	bad := &Script{Steps: []ScriptStep{{ToolCalls: []ScriptToolCall{{Arguments: map[string]any{"x": 1}}}}}}
+	if _, err := NewScriptProvider("bad", bad); err == nil {
+		t.Error("expected an error for a tool call without a name")
+	}
+}
+
+func TestTranscriptProvider(t *testing.T) {
+	path := writeFile(t, "session.jsonl", `{"model":"gpt-4o","response":{"content":"first","finish_reason":"stop"}}
+
+{"model":"gpt-4o","error":"upstream timeout"}
+{"model":"gpt-4o","response":{"content":"","finish_reason":"tool_calls","tool_calls":[{"id":"c1","type":"function","function":{"name":"exec","arguments":"{\"command\":\"id\"}"}}]}}
+`)
+	p, err := NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ctx := context.Background()
+	if resp, err := p.Chat(ctx, nil, nil, "other", nil); err != nil || resp.Content != "first" {
+		t.Errorf("entry 1 = %+v, %v", resp, err)
+	}
+	if _, err := p.Chat(ctx, nil, nil, "other", nil); err == nil || err.Error() != "upstream timeout" {
+		t.Errorf("entry 2 error = %v", err)
+	}
+	resp, err := p.Chat(ctx, nil, nil, "other", nil)
+	if err != nil || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "exec" {
+		t.Errorf("entry 3 = %+v, %v", resp, err)
+	}
+}
+
+// The shipped demo must stay loadable
+func TestDemoScripts(t *testing.T) {
+	paths, err := filepath.Glob("../../../examples/replay/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no demo scripts found")
+	}
+	for _, path := range paths {
+		if _, err := NewProvider(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
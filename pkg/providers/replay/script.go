@@ -0,0 +1,128 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+// Script is a hand-written conversation for demos and CI. It is YAML (or
+// JSON) so tool call arguments can be written as plain objects:
+//
+//	model: demo
+//	fallback: Nothing left to do.
+//	steps:
+//	  - expect: scan
+//	    content: Starting with a port scan.
+//	    tool_calls:
+//	      - name: exec
+//	        arguments: {command: "nmap -F 10.0.0.5"}
+//	  - expect: "22/tcp"
+//	    content: SSH is open on 10.0.0.5.
+type Script struct {
+	Model    string       `yaml:"model"`
+	Fallback string       `yaml:"fallback"` // Served once the steps run out instead of failing
+	Steps    []ScriptStep `yaml:"steps"`
+}
+
+// ScriptStep is one scripted response
+type ScriptStep struct {
+	Model        string           `yaml:"model"`  // Serve only to requests for this model
+	Expect       string           `yaml:"expect"` // Text the last user or tool message must contain
+	Content      string           `yaml:"content"`
+	Reasoning    string           `yaml:"reasoning"`
+	ToolCalls    []ScriptToolCall `yaml:"tool_calls"`
+	FinishReason string           `yaml:"finish_reason"` // Default "tool_calls" with tool calls, else "stop"
+	Usage        *ScriptUsage     `yaml:"usage"`
+	Error        string           `yaml:"error"`    // Fail the call with this message instead
+	DelayMS      int              `yaml:"delay_ms"` // Wait before answering, so demos look live
+}
+
+// ScriptToolCall is a tool call the model makes in a step
+type ScriptToolCall struct {
+	ID        string         `yaml:"id"` // Default "call_<step>_<n>"
+	Name      string         `yaml:"name"`
+	Arguments map[string]any `yaml:"arguments"`
+}
+
+// ScriptUsage is the token usage reported for a step
+type ScriptUsage struct {
+	PromptTokens     int `yaml:"prompt_tokens"`
+	CompletionTokens int `yaml:"completion_tokens"`
+}
+
+// LoadScript reads and parses the script at path
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading script: %w", err)
+	}
+	var script Script
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("parsing script %s: %w", path, err)
+	}
+	return &script, nil
+}
+
+// build turns the step at index into a servable response
+func (s ScriptStep) build(index int) (*step, error) {
+	st := &step{
+		model:  s.Model,
+		expect: s.Expect,
+		err:    s.Error,
+		delay:  time.Duration(s.DelayMS) * time.Millisecond,
+	}
+	if s.Error != "" {
+		return st, nil
+	}
+
+	resp := &protocoltypes.LLMResponse{
+		Content:          s.Content,
+		ReasoningContent: s.Reasoning,
+		FinishReason:     s.FinishReason,
+	}
+	for i, tc := range s.ToolCalls {
+		if tc.Name == "" {
+			return nil, fmt.Errorf("tool call %d has no name", i+1)
+		}
+		args := tc.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		argsJSON, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("tool call %s: %w", tc.Name, err)
+		}
+		id := tc.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d_%d", index+1, i+1)
+		}
+		resp.ToolCalls = append(resp.ToolCalls, protocoltypes.ToolCall{
+			ID:        id,
+			Type:      "function",
+			Name:      tc.Name,
+			Arguments: args,
+			Function:  &protocoltypes.FunctionCall{Name: tc.Name, Arguments: string(argsJSON)},
+		})
+	}
+	if resp.FinishReason == "" {
+		resp.FinishReason = "stop"
+		if len(resp.ToolCalls) > 0 {
+			resp.FinishReason = "tool_calls"
+		}
+	}
+	if s.Usage != nil {
+		resp.Usage = &protocoltypes.UsageInfo{
+			PromptTokens:     s.Usage.PromptTokens,
+			CompletionTokens: s.Usage.CompletionTokens,
+			TotalTokens:      s.Usage.PromptTokens + s.Usage.CompletionTokens,
+		}
+	}
+	st.response = resp
+	return st, nil
+}
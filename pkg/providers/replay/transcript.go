@@ -0,0 +1,58 @@
+// Package replay serves LLM responses from recorded transcripts, so the agent
+// loop can be debugged offline and tested without API keys.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+// Entry is one provider call in a transcript. Transcripts are JSONL files
+// with one entry per line, in call order.
+type Entry struct {
+	Time      time.Time                      `json:"time"`
+	Session   string                         `json:"session,omitempty"`
+	Model     string                         `json:"model"`
+	Messages  []protocoltypes.Message        `json:"messages,omitempty"`
+	Tools     []protocoltypes.ToolDefinition `json:"tools,omitempty"`
+	Options   map[string]any                 `json:"options,omitempty"`
+	Response  *protocoltypes.LLMResponse     `json:"response,omitempty"`
+	Error     string                         `json:"error,omitempty"`
+	LatencyMS int64                          `json:"latency_ms,omitempty"`
+}
+
+// ReadTranscript loads every entry in the transcript at path
+func ReadTranscript(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening transcript: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,224 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
+)
+
+const (
+	defaultTranscriptSession = "default"
+	redactedValue            = "[REDACTED]"
+)
+
+type transcriptSessionKey struct{}
+
+// WithTranscriptSession tags ctx with the session its provider calls belong
+// to, which picks the transcript file they are recorded to.
+func WithTranscriptSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, transcriptSessionKey{}, session)
+}
+
+func transcriptSession(ctx context.Context) string {
+	if session, _ := ctx.Value(transcriptSessionKey{}).(string); session != "" {
+		return session
+	}
+	return defaultTranscriptSession
+}
+
+// secretPatterns match credentials that commonly end up in prompts and tool
+// output. None of them match a double quote, so redacting encoded JSON keeps
+// it valid.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-(?:ant-|proj-|or-)?[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{30,}`),
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9\-]{10,}`),
+	regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+	regexp.MustCompile(`(?i)\b((?:bearer|basic)\s+)[A-Za-z0-9._~+/\-]{8,}=*`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[^"\\]|\\.)*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(?i)((?:api[_-]?key|secret|password|passwd|token|authorization)\\?"?\s*[:=]\s*\\?"?)[^\s"\\,}&]{4,}`),
+}
+
+// redactor removes secrets from encoded transcript entries
+type redactor struct {
+	literals *strings.Replacer
+	patterns []*regexp.Regexp
+}
+
+// newRedactor redacts the literal secrets, the built-in secret patterns and
+// the extra regular expressions
+func newRedactor(secrets []string, extra []string) (*redactor, error) {
+	r := &redactor{patterns: append([]*regexp.Regexp{}, secretPatterns...)}
+	for _, expr := range extra {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	// Longest first, so a key is not partly replaced by one of its prefixes
+	secrets = append([]string{}, secrets...)
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	var pairs []string
+	for _, s := range secrets {
+		if len(s) >= 8 {
+			pairs = append(pairs, s, redactedValue)
+		}
+	}
+	if len(pairs) > 0 {
+		r.literals = strings.NewReplacer(pairs...)
+	}
+	return r, nil
+}
+
+func (r *redactor) redact(s string) string {
+	if r.literals != nil {
+		s = r.literals.Replace(s)
+	}
+	for _, re := range r.patterns {
+		if re.NumSubexp() > 0 {
+			s = re.ReplaceAllString(s, "${1}"+redactedValue)
+		} else {
+			s = re.ReplaceAllString(s, redactedValue)
+		}
+	}
+	return s
+}
+
+// TranscriptRecorder wraps a provider and appends every call, request and
+// response, to a JSONL transcript per session. Secrets are redacted before
+// anything is written. Recording failures are logged and never fail the
+// call.
+type TranscriptRecorder struct {
+	inner    LLMProvider
+	dir      string
+	redactor *redactor
+
+	mu sync.Mutex
+}
+
+// NewTranscriptRecorder wraps inner according to cfg. The API keys in
+// secrets are redacted along with anything matching cfg.Redact.
+func NewTranscriptRecorder(inner LLMProvider, cfg config.TranscriptConfig, secrets []string) (*TranscriptRecorder, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("transcript directory is required")
+	}
+	r, err := newRedactor(secrets, cfg.Redact)
+	if err != nil {
+		return nil, err
+	}
+	return &TranscriptRecorder{inner: inner, dir: cfg.Dir, redactor: r}, nil
+}
+
+func (t *TranscriptRecorder) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	start := time.Now()
+	resp, chatErr := t.inner.Chat(ctx, messages, tools, model, options)
+
+	session := transcriptSession(ctx)
+	entry := replay.Entry{
+		Time:      start.UTC(),
+		Session:   session,
+		Model:     model,
+		Messages:  messages,
+		Tools:     tools,
+		Options:   options,
+		Response:  resp,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if chatErr != nil {
+		entry.Error = chatErr.Error()
+	}
+	if err := t.append(session, entry); err != nil {
+		logger.WarnCF("provider", "Failed to record transcript", map[string]any{
+			"session": session,
+			"error":   err.Error(),
+		})
+	}
+	return resp, chatErr
+}
+
+func (t *TranscriptRecorder) GetDefaultModel() string {
+	return t.inner.GetDefaultModel()
+}
+
+// TranscriptPath is the file the calls of session are recorded to
+func (t *TranscriptRecorder) TranscriptPath(session string) string {
+	name := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(session)
+	return filepath.Join(t.dir, name+".jsonl")
+}
+
+func (t *TranscriptRecorder) append(session string, entry replay.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding transcript entry: %w", err)
+	}
+	line := t.redactor.redact(string(data)) + "\n"
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(t.TranscriptPath(session), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
+
+// recordTranscripts wraps provider in a transcript recorder when cfg turns
+// transcripts on
+func recordTranscripts(cfg *config.Config, provider LLMProvider) (LLMProvider, error) {
+	if !cfg.Transcripts.Enabled {
+		return provider, nil
+	}
+	tc := cfg.Transcripts
+	if tc.Dir == "" {
+		tc.Dir = filepath.Join(cfg.WorkspacePath(), "transcripts")
+	}
+	recorder, err := NewTranscriptRecorder(provider, tc, configSecrets(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("transcripts: %w", err)
+	}
+	logger.InfoCF("provider", "Recording provider transcripts", map[string]any{"dir": tc.Dir})
+	return recorder, nil
+}
+
+// configSecrets collects the API keys in cfg so transcripts never hold them
+func configSecrets(cfg *config.Config) []string {
+	var secrets []string
+	for _, m := range cfg.ModelList {
+		if m.APIKey != "" {
+			secrets = append(secrets, m.APIKey)
+		}
+	}
+	web := cfg.Tools.Web
+	for _, key := range []string{web.Brave.APIKey, web.Tavily.APIKey, web.Perplexity.APIKey} {
+		if key != "" {
+			secrets = append(secrets, key)
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
+)
+
+func TestTranscriptRecorder_RedactsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	apiKey := "my-configured-key-123456"
+	recorder, err := NewTranscriptRecorder(&countingProvider{}, config.TranscriptConfig{
+		Enabled: true,
+		Dir:     dir,
+		Redact:  []string{`hunter\d+`},
+	}, []string{apiKey})
+	if err != nil {
+		t.Fatalf("NewTranscriptRecorder: %v", err)
+	}
+
+	ctx := WithTranscriptSession(context.Background(), "agent:main:cli")
+	msgs := []Message{
+		{Role: "user", Content: "use key " + apiKey + " and sk-ant-REDACTED"},
+		{Role: "tool", Content: `{"api_key": "s3cr3tvalue", "header": "Authorization: Bearer abc.def.ghi123"}`},
+		{Role: "user", Content: "the password is hunter42"},
+	}
+	var recorded []string
+	for range 2 {
+		resp, err := recorder.Chat(ctx, msgs, nil, "m", map[string]any{"max_tokens": 100})
+		if err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+		recorded = append(recorded, resp.Content)
+	}
+
+	path := recorder.TranscriptPath("agent:main:cli")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("transcript not written: %v", err)
+	}
+	for _, secret := range []string{apiKey, "sk-ant-", "s3cr3tvalue", "abc.def.ghi123", "hunter42"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("transcript contains %q:\n%s", secret, data)
+		}
+	}
+
+	entries, err := replay.ReadTranscript(path)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Session != "agent:main:cli" || len(entries[0].Messages) != 3 {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	player, err := replay.NewProvider(path)
+	if err != nil {
+		t.Fatalf("replay.NewProvider: %v", err)
+	}
+	for i, want := range recorded {
+		resp, err := player.Chat(context.Background(), nil, nil, "m", nil)
+		if err != nil {
+			t.Fatalf("replay Chat %d: %v", i, err)
+		}
+		if resp.Content != want {
+			t.Errorf("replay %d = %q, want %q", i, resp.Content, want)
+		}
+	}
+	if _, err := player.Chat(context.Background(), nil, nil, "m", nil); !errors.Is(err, replay.ErrExhausted) {
+		t.Errorf("exhausted error = %v, want ErrExhausted", err)
+	}
+}
+
+func TestTranscriptRecorder_DefaultSession(t *testing.T) {
+	recorder, err := NewTranscriptRecorder(&countingProvider{}, config.TranscriptConfig{Dir: t.TempDir()}, nil)
+	if err != nil {
+		t.Fatalf("NewTranscriptRecorder: %v", err)
+	}
+	if _, err := recorder.Chat(context.Background(), nil, nil, "m", nil); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if _, err := os.Stat(recorder.TranscriptPath(defaultTranscriptSession)); err != nil {
+		t.Errorf("default session transcript missing: %v", err)
+	}
+}
+
+func TestTranscriptRecorder_InvalidPattern(t *testing.T) {
+	_, err := NewTranscriptRecorder(&countingProvider{}, config.TranscriptConfig{Dir: t.TempDir(), Redact: []string{"("}}, nil)
+	if err == nil {
+		t.Error("expected an error for an invalid redact pattern")
+	}
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
@@ -19,6 +20,25 @@ type (
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ContentBlock           = protocoltypes.ContentBlock
 	CacheControl           = protocoltypes.CacheControl
+	ImagePart              = protocoltypes.ImagePart
+	APIError               = protocoltypes.APIError
+	BatchRequest           = protocoltypes.BatchRequest
+	BatchResult            = protocoltypes.BatchResult
+	EmbeddingResponse      = protocoltypes.EmbeddingResponse
+	ResponseSchema         = protocoltypes.ResponseSchema
+)
+
+// ResponseSchemaOption is the Chat options key for a *ResponseSchema.
+const ResponseSchemaOption = protocoltypes.ResponseSchemaOption
+
+// Typed provider failures, re-exported so callers don't import protocoltypes.
+var (
+	ErrRateLimited     = protocoltypes.ErrRateLimited
+	ErrContextTooLong  = protocoltypes.ErrContextTooLong
+	ErrAuthFailed      = protocoltypes.ErrAuthFailed
+	ErrContentFiltered = protocoltypes.ErrContentFiltered
+	ErrOverloaded      = protocoltypes.ErrOverloaded
+	ErrInvalidRequest  = protocoltypes.ErrInvalidRequest
 )
 
 type LLMProvider interface {
@@ -37,6 +57,30 @@ type StatefulProvider interface {
 	Close()
 }
 
+// BatchProvider is implemented by providers with an offline batch API that
+// trades latency (results within hours) for a lower price. Use it for
+// non-interactive work such as report drafting over many findings.
+type BatchProvider interface {
+	LLMProvider
+	SubmitBatch(ctx context.Context, requests []BatchRequest) (string, error)
+	BatchDone(ctx context.Context, batchID string) (bool, error)
+	BatchResults(ctx context.Context, batchID string, options map[string]any) ([]BatchResult, error)
+}
+
+// EmbeddingsProvider is implemented by providers that can embed text, the
+// shared entry point for memory/RAG features and embedding-based task
+// classification. Use routing.TierRouter.Embed to have the spend tracked.
+type EmbeddingsProvider interface {
+	Embed(ctx context.Context, inputs []string, model string) (*EmbeddingResponse, error)
+}
+
+// HealthProber is implemented by providers that can confirm they are
+// reachable and authorized without a billable completion, e.g. by listing
+// models. Health checks fall back to a 1-token request for other providers.
+type HealthProber interface {
+	Probe(ctx context.Context) error
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
@@ -47,6 +91,8 @@ const (
 	FailoverTimeout    FailoverReason = "timeout"
 	FailoverFormat     FailoverReason = "format"
 	FailoverOverloaded FailoverReason = "overloaded"
+	FailoverContext    FailoverReason = "context_length"
+	FailoverFiltered   FailoverReason = "content_filter"
 	FailoverUnknown    FailoverReason = "unknown"
 )
 
@@ -68,6 +114,34 @@ func (e *FailoverError) Unwrap() error {
 	return e.Wrapped
 }
 
+// Is lets errors.Is match the typed sentinels even when the reason was
+// inferred from the message of an untyped provider error.
+func (e *FailoverError) Is(target error) bool {
+	switch e.Reason {
+	case FailoverAuth:
+		return target == ErrAuthFailed
+	case FailoverRateLimit:
+		return target == ErrRateLimited
+	case FailoverOverloaded:
+		return target == ErrOverloaded
+	case FailoverContext:
+		return target == ErrContextTooLong
+	case FailoverFiltered:
+		return target == ErrContentFiltered
+	}
+	return false
+}
+
+// IsPermanentError reports whether resending the same request to the same
+// model cannot succeed: bad credentials, an oversized prompt, a request the
+// provider's content filter rejected, or one that failed validation.
+func IsPermanentError(err error) bool {
+	return errors.Is(err, ErrAuthFailed) ||
+		errors.Is(err, ErrContextTooLong) ||
+		errors.Is(err, ErrContentFiltered) ||
+		errors.Is(err, ErrInvalidRequest)
+}
+
 // IsRetriable returns true if this error should trigger fallback to next candidate.
 // Non-retriable: Format errors (bad request structure, image dimension/size).
 func (e *FailoverError) IsRetriable() bool {
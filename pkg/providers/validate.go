@@ -0,0 +1,250 @@
+package providers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Request validation modes
+const (
+	ValidationRepair = "repair" // Fix what can be fixed and send (default)
+	ValidationStrict = "strict" // Reject any request with issues
+	ValidationOff    = "off"
+)
+
+const (
+	// defaultMaxMessageChars caps a single message when the model's context
+	// window is unknown, roughly 100k tokens
+	defaultMaxMessageChars = 400_000
+
+	missingToolResult = "No result: the tool call was interrupted before it returned."
+	emptyToolResult   = "(no output)"
+)
+
+// ValidationIssue is one problem found in a request before it was sent
+type ValidationIssue struct {
+	Index    int    // Position of the message in the request as given
+	Check    string // role, system_position, alternation, orphan_tool_result, missing_tool_result, empty, oversized, no_messages
+	Detail   string
+	Repaired bool
+}
+
+func (i ValidationIssue) String() string {
+	s := fmt.Sprintf("message %d: %s: %s", i.Index, i.Check, i.Detail)
+	if i.Repaired {
+		s += " (repaired)"
+	}
+	return s
+}
+
+// RequestValidationError rejects a request that would fail at the API. It
+// matches ErrInvalidRequest.
+type RequestValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *RequestValidationError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.String()
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+func (e *RequestValidationError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// RequestValidator checks a conversation before it goes over the wire: role
+// alternation, tool results without a matching call, calls without a result,
+// empty messages and oversized messages. APIs reject such requests with an
+// opaque "400 invalid request". The zero value repairs with default limits.
+type RequestValidator struct {
+	Mode            string // ValidationRepair (default), ValidationStrict or ValidationOff
+	MaxMessageChars int    // Longest message sent; 0 = defaultMaxMessageChars
+}
+
+// NewRequestValidator configures a validator for a model_list entry. A
+// single message may use at most half the model's context window.
+func NewRequestValidator(cfg *config.ModelConfig) RequestValidator {
+	v := RequestValidator{Mode: cfg.RequestValidation}
+	if cfg.ContextWindow > 0 {
+		v.MaxMessageChars = cfg.ContextWindow * 4 / 2 // ~4 characters per token
+	}
+	return v
+}
+
+// Apply returns the messages to send to model. Repairs are logged; in
+// strict mode any issue, and in every mode an issue that cannot be
+// repaired, returns a *RequestValidationError instead.
+func (v RequestValidator) Apply(messages []Message, model string) ([]Message, error) {
+	if v.Mode == ValidationOff {
+		return messages, nil
+	}
+	repaired, issues := v.Validate(messages)
+	if len(issues) == 0 {
+		return messages, nil
+	}
+
+	rejected := v.Mode == ValidationStrict
+	for _, issue := range issues {
+		if !issue.Repaired {
+			rejected = true
+		}
+	}
+	if rejected {
+		return nil, &RequestValidationError{Issues: issues}
+	}
+
+	details := make([]string, len(issues))
+	for i, issue := range issues {
+		details[i] = issue.String()
+	}
+	logger.WarnCF("provider", "Repaired request before sending", map[string]any{
+		"model":  model,
+		"issues": details,
+	})
+	return repaired, nil
+}
+
+// Validate returns a repaired copy of messages and every issue found.
+// messages is not modified.
+func (v RequestValidator) Validate(messages []Message) ([]Message, []ValidationIssue) {
+	maxChars := v.MaxMessageChars
+	if maxChars <= 0 {
+		maxChars = defaultMaxMessageChars
+	}
+
+	var issues []ValidationIssue
+	report := func(index int, check string, repaired bool, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Index: index, Check: check, Detail: fmt.Sprintf(format, args...), Repaired: repaired})
+	}
+
+	out := make([]Message, 0, len(messages))
+	var pending []string // Unanswered tool call IDs of the last assistant turn
+	answerPending := func(index int) {
+		for _, id := range pending {
+			out = append(out, Message{Role: "tool", ToolCallID: id, Content: missingToolResult})
+			report(index, "missing_tool_result", true, "tool call %s has no result", id)
+		}
+		pending = nil
+	}
+
+	for i, msg := range messages {
+		if msg.Role != "tool" && len(pending) > 0 {
+			answerPending(i)
+		}
+
+		switch msg.Role {
+		case "system":
+			if strings.TrimSpace(msg.Content) == "" && len(msg.SystemParts) == 0 {
+				report(i, "empty", true, "empty system message dropped")
+				continue
+			}
+			if n := leadingSystem(out); n < len(out) {
+				// Mid-conversation system messages become part of the system
+				// prompt; several APIs accept only one, at the start
+				if n == 0 {
+					out = slices.Insert(out, 0, Message{Role: "system"})
+				}
+				sys := &out[0]
+				sys.Content = strings.TrimSpace(sys.Content + "\n\n" + msg.Content)
+				sys.SystemParts = slices.Concat(sys.SystemParts, msg.SystemParts)
+				report(i, "system_position", true, "system message after the conversation started moved into the system prompt")
+				continue
+			}
+
+		case "user":
+			if strings.TrimSpace(msg.Content) == "" && len(msg.Images) == 0 {
+				report(i, "empty", true, "empty user message dropped")
+				continue
+			}
+
+		case "assistant":
+			if strings.TrimSpace(msg.Content) == "" && len(msg.ToolCalls) == 0 {
+				report(i, "empty", true, "assistant message with no content or tool calls dropped")
+				continue
+			}
+
+		case "tool":
+			j := slices.Index(pending, msg.ToolCallID)
+			if msg.ToolCallID == "" || j < 0 {
+				report(i, "orphan_tool_result", true, "tool result %q answers no pending tool call, dropped", msg.ToolCallID)
+				continue
+			}
+			pending = slices.Delete(pending, j, j+1)
+			if strings.TrimSpace(msg.Content) == "" {
+				msg.Content = emptyToolResult
+				report(i, "empty", true, "empty tool result for %s filled in", msg.ToolCallID)
+			}
+
+		default:
+			report(i, "role", false, "unknown role %q", msg.Role)
+			continue
+		}
+
+		if len(msg.Content) > maxChars {
+			report(i, "oversized", true, "%d characters truncated to %d", len(msg.Content), maxChars)
+			msg.Content = truncateMiddle(msg.Content, maxChars)
+		}
+
+		if n := len(out); n > 0 && out[n-1].Role == msg.Role && mergeable(out[n-1]) {
+			prev := &out[n-1]
+			prev.Content = strings.TrimSpace(prev.Content + "\n\n" + msg.Content)
+			prev.Images = slices.Concat(prev.Images, msg.Images)
+			prev.ToolCalls = msg.ToolCalls
+			if msg.ReasoningContent != "" {
+				prev.ReasoningContent, prev.ThinkingSignature = msg.ReasoningContent, msg.ThinkingSignature
+			}
+			report(i, "alternation", true, "consecutive %s messages merged", msg.Role)
+		} else {
+			out = append(out, msg)
+		}
+
+		if msg.Role == "assistant" {
+			for _, tc := range msg.ToolCalls {
+				if tc.ID != "" {
+					pending = append(pending, tc.ID)
+				}
+			}
+		}
+	}
+	if len(pending) > 0 {
+		answerPending(len(messages))
+	}
+
+	if leadingSystem(out) == len(out) {
+		report(len(messages), "no_messages", false, "no user or assistant messages to send")
+	}
+	return out, issues
+}
+
+// leadingSystem counts the system messages at the start of messages
+func leadingSystem(messages []Message) int {
+	for i, msg := range messages {
+		if msg.Role != "system" {
+			return i
+		}
+	}
+	return len(messages)
+}
+
+// mergeable reports whether the next message of the same role can be folded
+// into msg: user messages always, assistant messages without tool calls
+func mergeable(msg Message) bool {
+	return msg.Role == "user" || (msg.Role == "assistant" && len(msg.ToolCalls) == 0)
+}
+
+// truncateMiddle keeps the start and end of s within maxChars, since tool
+// output usually has its summary at one end or the other
+func truncateMiddle(s string, maxChars int) string {
+	marker := fmt.Sprintf("\n\n[... %d characters truncated ...]\n\n", len(s)-maxChars)
+	keep := max(maxChars-len(marker), 0)
+	head := keep * 3 / 4
+	tail := keep - head
+	return strings.ToValidUTF8(s[:head], "") + marker + strings.ToValidUTF8(s[len(s)-tail:], "")
+}
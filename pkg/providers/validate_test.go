@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+func TestRequestValidator_Repairs(t *testing.T) {
+	calls := []ToolCall{{ID: "c1", Name: "exec"}, {ID: "c2", Name: "exec"}}
+	messages := []Message{
+		{Role: "system", Content: "You are a pentester."},
+		{Role: "user", Content: "Scan 10.0.0.1"},
+		{Role: "user", Content: "  "},
+		{Role: "user", Content: "Ports first"},
+		{Role: "assistant", Content: "Scanning.", ToolCalls: calls},
+		{Role: "tool", Content: "22/tcp open", ToolCallID: "c1"},
+		{Role: "tool", Content: "stale", ToolCallID: "c9"},
+		{Role: "system", Content: "Phase: discovery"},
+		{Role: "assistant", Content: ""},
+		{Role: "user", Content: "Continue"},
+	}
+	original := make([]Message, len(messages))
+	copy(original, messages)
+
+	got, issues := RequestValidator{}.Validate(messages)
+
+	want := []Message{
+		{Role: "system", Content: "You are a pentester.\n\nPhase: discovery"},
+		{Role: "user", Content: "Scan 10.0.0.1\n\nPorts first"},
+		{Role: "assistant", Content: "Scanning.", ToolCalls: calls},
+		{Role: "tool", Content: "22/tcp open", ToolCallID: "c1"},
+		{Role: "tool", Content: missingToolResult, ToolCallID: "c2"},
+		{Role: "user", Content: "Continue"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() =\n%+v\nwant\n%+v", got, want)
+	}
+	if !reflect.DeepEqual(messages, original) {
+		t.Error("Validate() modified its input")
+	}
+
+	var checks []string
+	for _, issue := range issues {
+		if !issue.Repaired {
+			t.Errorf("issue not repaired: %s", issue)
+		}
+		checks = append(checks, issue.Check)
+	}
+	wantChecks := []string{"empty", "alternation", "orphan_tool_result", "missing_tool_result", "system_position", "empty"}
+	if !reflect.DeepEqual(checks, wantChecks) {
+		t.Errorf("checks = %v, want %v", checks, wantChecks)
+	}
+}
+
+func TestRequestValidator_TrailingToolCallsAndEmptyResults(t *testing.T) {
+	got, _ := RequestValidator{}.Validate([]Message{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "a"}, {ID: "b"}}},
+		{Role: "tool", ToolCallID: "b"},
+	})
+	if len(got) != 4 || got[2].Content != emptyToolResult || got[3].ToolCallID != "a" || got[3].Content != missingToolResult {
+		t.Errorf("Validate() = %+v", got)
+	}
+}
+
+func TestRequestValidator_Oversized(t *testing.T) {
+	content := "HEAD" + strings.Repeat("x", 1000) + "TAIL"
+	got, issues := RequestValidator{MaxMessageChars: 200}.Validate([]Message{{Role: "user", Content: content}})
+
+	if len(issues) != 1 || issues[0].Check != "oversized" {
+		t.Fatalf("issues = %v", issues)
+	}
+	text := got[0].Content
+	if len(text) > 200 || !strings.HasPrefix(text, "HEAD") || !strings.HasSuffix(text, "TAIL") ||
+		!strings.Contains(text, "[... 808 characters truncated ...]") {
+		t.Errorf("truncated content (%d chars) = %q", len(text), text)
+	}
+}
+
+func TestRequestValidator_Apply(t *testing.T) {
+	messy := []Message{{Role: "user", Content: "a"}, {Role: "user", Content: "b"}}
+
+	got, err := RequestValidator{}.Apply(messy, "m")
+	if err != nil || len(got) != 1 {
+		t.Errorf("repair: Apply() = %+v, %v", got, err)
+	}
+
+	got, err = RequestValidator{Mode: ValidationOff}.Apply(messy, "m")
+	if err != nil || len(got) != 2 {
+		t.Errorf("off: Apply() = %+v, %v", got, err)
+	}
+
+	_, err = RequestValidator{Mode: ValidationStrict}.Apply(messy, "m")
+	var verr *RequestValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidRequest) || verr.Issues[0].Check != "alternation" {
+		t.Fatalf("strict: Apply() error = %v", err)
+	}
+	if !IsPermanentError(err) {
+		t.Error("validation error is not permanent")
+	}
+	if fe := ClassifyError(err, "openai", "m"); fe == nil || fe.Reason != FailoverFormat {
+		t.Errorf("ClassifyError() = %+v, want a format error", fe)
+	}
+
+	// Unrepairable issues are rejected in repair mode too
+	for name, messages := range map[string][]Message{
+		"unknown role": {{Role: "user", Content: "a"}, {Role: "developer", Content: "b"}},
+		"only system":  {{Role: "system", Content: "a"}, {Role: "user", Content: ""}},
+	} {
+		if _, err := (RequestValidator{}).Apply(messages, "m"); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%s: Apply() error = %v", name, err)
+		}
+	}
+}
+
+func TestCreateProviderFromConfig_RequestValidation(t *testing.T) {
+	cfg := &config.ModelConfig{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "k", ContextWindow: 1000}
+	provider, _, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := provider.(*HTTPProvider).validator; v.MaxMessageChars != 2000 {
+		t.Errorf("validator = %+v, want half the context window", v)
+	}
+
+	cfg.RequestValidation = "sometimes"
+	if _, _, err := CreateProviderFromConfig(cfg); err == nil {
+		t.Error("unknown request_validation accepted")
+	}
+}
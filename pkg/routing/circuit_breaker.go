@@ -0,0 +1,104 @@
+package routing
+
+import "sync"
+
+// defaultRejectionLimit is the number of consecutive supervisor rejections
+// after which a task type skips the worker tier for the rest of the session.
+const defaultRejectionLimit = 3
+
+// RejectionBreaker tracks consecutive supervisor rejections per session and
+// task type. Once a task type has been rejected limit times in a row, the
+// breaker trips and stays open for the remainder of the session so further
+// instances go straight to the supervisor instead of paying for doomed
+// worker attempts.
+type RejectionBreaker struct {
+	mu      sync.Mutex
+	limit   int
+	streaks map[string]map[TaskType]int
+	tripped map[string]map[TaskType]bool
+}
+
+// NewRejectionBreaker creates a breaker that trips after limit consecutive
+// rejections. A limit of zero uses the default; a negative limit disables it.
+func NewRejectionBreaker(limit int) *RejectionBreaker {
+	if limit == 0 {
+		limit = defaultRejectionLimit
+	}
+	return &RejectionBreaker{
+		limit:   limit,
+		streaks: make(map[string]map[TaskType]int),
+		tripped: make(map[string]map[TaskType]bool),
+	}
+}
+
+// RecordRejection registers a rejected worker output. It returns true only on
+// the call that trips the breaker, so callers can log the escalation once.
+func (b *RejectionBreaker) RecordRejection(sessionKey string, taskType TaskType) bool {
+	if b == nil || b.limit < 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tripped[sessionKey][taskType] {
+		return false
+	}
+	if b.streaks[sessionKey] == nil {
+		b.streaks[sessionKey] = make(map[TaskType]int)
+	}
+	b.streaks[sessionKey][taskType]++
+	if b.streaks[sessionKey][taskType] < b.limit {
+		return false
+	}
+
+	if b.tripped[sessionKey] == nil {
+		b.tripped[sessionKey] = make(map[TaskType]bool)
+	}
+	b.tripped[sessionKey][taskType] = true
+	return true
+}
+
+// RecordApproval resets the rejection streak for a task type.
+func (b *RejectionBreaker) RecordApproval(sessionKey string, taskType TaskType) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.streaks[sessionKey], taskType)
+}
+
+// IsTripped reports whether the task type has been escalated for the session.
+func (b *RejectionBreaker) IsTripped(sessionKey string, taskType TaskType) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tripped[sessionKey][taskType]
+}
+
+// Escalated returns the task types that have been escalated for the session.
+func (b *RejectionBreaker) Escalated(sessionKey string) []TaskType {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make([]TaskType, 0, len(b.tripped[sessionKey]))
+	for taskType := range b.tripped[sessionKey] {
+		result = append(result, taskType)
+	}
+	return result
+}
+
+// Reset clears all breaker state for a session.
+func (b *RejectionBreaker) Reset(sessionKey string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.streaks, sessionKey)
+	delete(b.tripped, sessionKey)
+}
@@ -0,0 +1,150 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
+)
+
+// estimateRequestTokens estimates the total context a request to model
+// occupies: messages, tool schemas, and the output budget reserved via
+// max_tokens.
+func estimateRequestTokens(model string, messages []providers.Message, tools []providers.ToolDefinition, options map[string]any) int {
+	total := tokens.EstimateMessages(model, messages) + tokens.EstimateTools(model, tools)
+	if maxTokens, ok := options["max_tokens"].(int); ok && maxTokens > 0 {
+		total += maxTokens
+	}
+	return total
+}
+
+// modelID returns the provider model ID (e.g. "openai/gpt-4o") for a
+// model_list name, used to pick the token estimator.
+func (tr *TierRouter) modelID(modelName string) string {
+	for _, model := range tr.modelList {
+		if model.ModelName == modelName {
+			return model.Model
+		}
+	}
+	return modelName
+}
+
+// contextWindow returns the context window for a tier: the tier override if
+// set, otherwise the model_list entry's. Zero means unknown.
+func (tr *TierRouter) contextWindow(tierCfg config.TierConfig) int {
+	if tierCfg.ContextWindow > 0 {
+		return tierCfg.ContextWindow
+	}
+	for _, model := range tr.modelList {
+		if model.ModelName == tierCfg.ModelName {
+			return model.ContextWindow
+		}
+	}
+	return 0
+}
+
+// fitContext keeps the selected tier when the request fits its context window
+// (or the window is unknown). Otherwise it reroutes to the tier with the
+// smallest known window that fits. If none fits it returns an error wrapping
+// providers.ErrContextTooLong so the caller compacts history and retries.
+func (tr *TierRouter) fitContext(
+	taskType TaskType,
+	tierName string,
+	tierCfg *config.TierConfig,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+) (string, *config.TierConfig, error) {
+	window := tr.contextWindow(*tierCfg)
+	if window == 0 {
+		return tierName, tierCfg, nil
+	}
+	estimate := estimateRequestTokens(tr.modelID(tierCfg.ModelName), messages, tools, options)
+	if estimate <= window {
+		return tierName, tierCfg, nil
+	}
+
+	tiers := tr.routingConfig().Tiers
+	names := make([]string, 0, len(tiers))
+	for name := range tiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bestName, bestWindow := "", 0
+	for _, name := range names {
+		candidate := tiers[name]
+		if _, ok := tr.provider(candidate.ModelName); !ok {
+			continue
+		}
+		w := tr.contextWindow(candidate)
+		if w < estimate {
+			continue
+		}
+		if bestName == "" || w < bestWindow {
+			bestName, bestWindow = name, w
+		}
+	}
+
+	if bestName == "" {
+		logger.WarnCF(tr.component, "Request exceeds every tier's context window", map[string]any{
+			"task":             taskType,
+			"tier":             tierName,
+			"estimated_tokens": estimate,
+			"context_window":   window,
+		})
+		return "", nil, fmt.Errorf("%w: ~%d tokens exceeds tier %s (%d) and no larger tier is configured",
+			providers.ErrContextTooLong, estimate, tierName, window)
+	}
+
+	best := tiers[bestName]
+	logger.InfoCF(tr.component, "Rerouting to tier with larger context window", map[string]any{
+		"task":             taskType,
+		"from_tier":        tierName,
+		"to_tier":          bestName,
+		"estimated_tokens": estimate,
+		"context_window":   bestWindow,
+	})
+	return bestName, &best, nil
+}
+
+// Preflight is a pre-call estimate of where a request would be routed and
+// what it would cost at most.
+type Preflight struct {
+	Tier         string
+	Model        string
+	PromptTokens int
+	MaxCost      float64 // prompt plus the full max_tokens output budget
+}
+
+// EstimatePreflight estimates the tier, prompt size and worst-case cost of a
+// request without sending it.
+func (tr *TierRouter) EstimatePreflight(
+	taskType TaskType,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+) (*Preflight, error) {
+	tierName, tierCfg, err := tr.SelectTier(taskType)
+	if err != nil {
+		return nil, fmt.Errorf("tier selection failed: %w", err)
+	}
+	messages, tools, options = tr.applyTaskOverrides(taskType, messages, tools, options)
+	tierName, tierCfg, err = tr.fitContext(taskType, tierName, tierCfg, messages, tools, options)
+	if err != nil {
+		return nil, err
+	}
+
+	model := tr.modelID(tierCfg.ModelName)
+	prompt := tokens.EstimateMessages(model, messages) + tokens.EstimateTools(model, tools)
+	maxOutput, _ := options["max_tokens"].(int)
+	return &Preflight{
+		Tier:         tierName,
+		Model:        tierCfg.ModelName,
+		PromptTokens: prompt,
+		MaxCost:      EstimateCallCost(*tierCfg, prompt, maxOutput),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// Operator decisions on a supervisor correction, recorded in
+// SupervisionResult.OperatorDecision.
+const (
+	ReviewAccepted  = "accepted"
+	ReviewRejected  = "rejected"
+	ReviewEdited    = "edited"
+	ReviewDismissed = "dismissed" // No answer: the operator quit or the review was cancelled
+)
+
+// CorrectionReview is a supervisor correction awaiting operator approval.
+type CorrectionReview struct {
+	Task            TaskType
+	WorkerModel     string
+	SupervisorModel string
+	WorkerOutput    string
+	CorrectedOutput string
+	Corrections     []string
+}
+
+// CorrectionDecision is the operator's answer to a CorrectionReview.
+// Output is only used for ReviewEdited.
+type CorrectionDecision struct {
+	Action string
+	Output string
+}
+
+// CorrectionReviewer asks the operator to approve a correction. It should
+// return ReviewDismissed if ctx is cancelled before the operator answers.
+type CorrectionReviewer func(ctx context.Context, review CorrectionReview) CorrectionDecision
+
+// SetCorrectionReviewer installs an interactive reviewer for supervisor
+// corrections. Without one, corrections are applied automatically.
+func (tr *TierRouter) SetCorrectionReviewer(reviewer CorrectionReviewer) {
+	if tr.supervisor != nil {
+		tr.supervisor.reviewer = reviewer
+	}
+}
+
+// reviewCorrection lets the operator accept, reject, or edit a result whose
+// final output differs from what the worker produced. A correction the
+// operator never approved is not applied: dismissed reviews, and unknown
+// answers, keep the worker output like a rejection.
+func (sr *SupervisionRouter) reviewCorrection(ctx context.Context, result *SupervisionResult, workerOutput string) {
+	if sr.reviewer == nil || result == nil || result.FinalOutput == workerOutput {
+		return
+	}
+	result.WorkerOutput = workerOutput
+
+	decision := sr.reviewer(ctx, CorrectionReview{
+		Task:            result.OriginalTask,
+		WorkerModel:     result.WorkerModel,
+		SupervisorModel: result.SupervisorModel,
+		WorkerOutput:    workerOutput,
+		CorrectedOutput: result.FinalOutput,
+		Corrections:     result.Corrections,
+	})
+
+	switch decision.Action {
+	case ReviewAccepted:
+	case ReviewEdited:
+		result.FinalOutput = decision.Output
+	case ReviewRejected:
+		result.FinalOutput = workerOutput
+		result.Validated = false
+	default:
+		decision.Action = ReviewDismissed
+		result.FinalOutput = workerOutput
+		result.Validated = false
+	}
+	result.OperatorDecision = decision.Action
+
+	logger.InfoCF(sr.component, "Operator reviewed supervisor correction", map[string]any{
+		"task":     result.OriginalTask,
+		"decision": decision.Action,
+	})
+}
+
+// FeedbackMessage converts the supervisor's corrections into a message to
+// append to the session after the corrected answer, so later turns learn
+// from the feedback instead of repeating the mistake. It returns false when
+// there is nothing to learn: no corrections, or the operator rejected or
+// dismissed them.
+func (r *SupervisionResult) FeedbackMessage() (providers.Message, bool) {
+	if r == nil || len(r.Corrections) == 0 || r.OperatorDecision == ReviewRejected || r.OperatorDecision == ReviewDismissed {
+		return providers.Message{}, false
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Supervisor feedback] %s reviewed the %s answer above", r.SupervisorModel, r.OriginalTask)
+	if r.WorkerModel != "" {
+		fmt.Fprintf(&sb, " (written by %s)", r.WorkerModel)
+	}
+	sb.WriteString(" and made these corrections:\n")
+	for _, c := range r.Corrections {
+		fmt.Fprintf(&sb, "- %s\n", c)
+	}
+	if r.OperatorDecision == ReviewEdited {
+		sb.WriteString("The operator then edited the corrected answer.\n")
+	}
+	sb.WriteString("Apply these corrections in subsequent turns.")
+
+	// A user turn: history drops extra system messages, and a tool message
+	// needs a matching tool call.
+	return providers.Message{Role: "user", Content: sb.String()}, true
+}
@@ -0,0 +1,53 @@
+package routing
+
+import "context"
+
+// Spend purposes reported by CostAttribution.Purpose.
+const (
+	PurposeSummarization = "summarization" // Parsing, summarizing or formatting tool output
+	PurposeReasoning     = "reasoning"     // Everything else
+	PurposeEmbedding     = "embedding"     // Text embeddings (see TierRouter.Embed)
+)
+
+// unattributed labels spend with no workflow phase or triggering tool.
+const unattributed = "(none)"
+
+type costAttributionKey struct{}
+
+// CostAttribution records what a routed call was spent on: the active
+// workflow phase, the tool whose output triggered the call, and the task.
+type CostAttribution struct {
+	Phase string
+	Tool  string
+	Task  TaskType
+}
+
+// Purpose classifies the call as tool output summarization or reasoning.
+func (a CostAttribution) Purpose() string {
+	switch a.Task {
+	case TaskParsing, TaskSummary, TaskFormatting:
+		return PurposeSummarization
+	}
+	return PurposeReasoning
+}
+
+// WithCostAttribution attaches the active workflow phase and the triggering
+// tool (either may be empty) to ctx, so calls routed with it are broken down
+// by phase and tool in FormatSessionReport.
+func WithCostAttribution(ctx context.Context, phase, tool string) context.Context {
+	attr := costAttributionFrom(ctx)
+	attr.Phase, attr.Tool = phase, tool
+	return context.WithValue(ctx, costAttributionKey{}, attr)
+}
+
+// withCostTask records the routed task type on ctx's attribution.
+func withCostTask(ctx context.Context, task TaskType) context.Context {
+	attr := costAttributionFrom(ctx)
+	attr.Task = task
+	return context.WithValue(ctx, costAttributionKey{}, attr)
+}
+
+func costAttributionFrom(ctx context.Context) CostAttribution {
+	attr, _ := ctx.Value(costAttributionKey{}).(CostAttribution)
+	return attr
+}
@@ -2,10 +2,13 @@ package routing
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 )
 
@@ -13,6 +16,10 @@ import (
 type CostTracker struct {
 	mu       sync.RWMutex
 	sessions map[string]*SessionCost
+
+	// Counterfactual pricing: what every call would have cost on this tier
+	baselineTier string
+	baseline     *config.TierConfig
 }
 
 // SessionCost tracks costs for a single session
@@ -20,31 +27,59 @@ type SessionCost struct {
 	SessionKey string
 	ByModel    map[string]*ModelCost
 	ByTier     map[string]*TierCost
+	ByPhase    map[string]*AttributedCost // Workflow phase active at call time
+	ByTool     map[string]*AttributedCost // Tool whose output triggered the call
+	ByPurpose  map[string]*AttributedCost // summarization or reasoning
 	TotalCost  float64
+	BaselineCost float64 // Cost had every call gone to the baseline tier
+	BaselineTier string
 	StartTime  time.Time
 	LastUpdate time.Time
 	Supervision SupervisionMetrics
+	Hedging     HedgingMetrics
+	Cache       CacheMetrics
+	PromptCache PromptCacheMetrics
+	ToolEnforcement ToolEnforcementMetrics
 }
 
 // ModelCost tracks usage and cost for a specific model
 type ModelCost struct {
-	ModelName    string
+	ModelName       string
+	InputTokens     int
+	OutputTokens    int
+	CachedTokens    int // Of InputTokens, read from the prompt cache
+	ReasoningTokens int // Of OutputTokens, spent on hidden reasoning
+	Calls           int
+	Reviewed        int // Of Calls, worker outputs the supervisor checked
+	Rejected        int // Of Reviewed, outputs the supervisor rejected
+	TotalCost       float64
+	TotalLatency    time.Duration
+	AvgLatency      time.Duration
+}
+
+// TierCost tracks usage and cost for a specific tier
+type TierCost struct {
+	TierName     string
 	InputTokens  int
 	OutputTokens int
 	Calls        int
 	TotalCost    float64
 	TotalLatency time.Duration
-	AvgLatency   time.Duration
 }
 
-// TierCost tracks usage and cost for a specific tier
-type TierCost struct {
-	TierName     string
+// AttributedCost tracks usage and cost for one phase, tool or purpose
+type AttributedCost struct {
 	InputTokens  int
 	OutputTokens int
 	Calls        int
 	TotalCost    float64
-	TotalLatency time.Duration
+}
+
+func (a *AttributedCost) add(usage providers.UsageInfo, cost float64) {
+	a.InputTokens += usage.PromptTokens
+	a.OutputTokens += usage.CompletionTokens
+	a.Calls++
+	a.TotalCost += cost
 }
 
 // SupervisionMetrics tracks supervision-related performance metrics
@@ -59,6 +94,69 @@ type SupervisionMetrics struct {
 	SupervisionSavings   float64 // Cost saved by using worker models
 }
 
+// HedgingMetrics tracks hedged requests (see TierRouter.chatWithHedge)
+type HedgingMetrics struct {
+	Requests   int     // Requests eligible for hedging (p95 known)
+	Hedged     int     // Requests that fired a hedge
+	HedgeWins  int     // Hedges that answered first
+	WastedCost float64 // Estimated prompt cost of cancelled losing requests
+}
+
+// CacheMetrics tracks routed requests answered from cache instead of a model
+type CacheMetrics struct {
+	Hits           int     // Responses served from cache
+	ToolOutputHits int     // Of which re-parses/summaries of a known tool output hash
+	SavedCost      float64 // What the cached responses would have cost again
+}
+
+// PromptCacheMetrics tracks how much of the routed prompts the providers
+// read from their prefix caches
+type PromptCacheMetrics struct {
+	Calls        int     // Routed calls
+	Hits         int     // Calls that read part of their prompt from cache
+	PromptTokens int     // Prompt tokens across all calls
+	ReadTokens   int     // Of PromptTokens, read from cache
+	WriteTokens  int     // Of PromptTokens, written to cache
+	SavedCost    float64 // Cache read discount minus the cache write premium
+}
+
+// ToolEnforcementMetrics tracks tool-selection turns that had to answer with
+// a tool call (see TierRouter.enforceToolCall)
+type ToolEnforcementMetrics struct {
+	Turns      int // Enforced turns
+	Violations int // Responses without a tool call, retries included
+	Retries    int // Retry requests sent
+	Recovered  int // Turns that produced a tool call on retry
+	Failed     int // Turns still without a tool call after all retries
+}
+
+// HitRate returns the fraction of prompt tokens read from cache.
+func (m PromptCacheMetrics) HitRate() float64 {
+	if m.PromptTokens == 0 {
+		return 0
+	}
+	return float64(m.ReadTokens) / float64(m.PromptTokens)
+}
+
+// HedgeRate returns the fraction of eligible requests that were hedged.
+func (h HedgingMetrics) HedgeRate() float64 {
+	if h.Requests == 0 {
+		return 0
+	}
+	return float64(h.Hedged) / float64(h.Requests)
+}
+
+// Savings returns how much routing saved compared to sending every call to
+// the baseline tier, in dollars and as a percentage of the baseline cost.
+// Both are zero when no baseline is configured.
+func (s *SessionCost) Savings() (saved float64, percent float64) {
+	if s.BaselineCost <= 0 {
+		return 0, 0
+	}
+	saved = s.BaselineCost - s.TotalCost
+	return saved, saved / s.BaselineCost * 100
+}
+
 // NewCostTracker creates a new cost tracker
 func NewCostTracker() *CostTracker {
 	return &CostTracker{
@@ -66,7 +164,51 @@ func NewCostTracker() *CostTracker {
 	}
 }
 
-// Record records token usage and calculates cost
+// EstimateCallCost returns the cost of a call with the given token counts at
+// the tier's per-million rates.
+func EstimateCallCost(tierCfg config.TierConfig, promptTokens, completionTokens int) float64 {
+	inputCost := float64(promptTokens) / 1_000_000.0 * tierCfg.CostPerM.Input
+	outputCost := float64(completionTokens) / 1_000_000.0 * tierCfg.CostPerM.Output
+	return inputCost + outputCost
+}
+
+// EstimateUsageCost returns the cost of a call at the tier's per-million
+// rates, billing cache reads, cache writes and reasoning tokens at their own
+// rates when the tier sets them.
+func EstimateUsageCost(tierCfg config.TierConfig, usage providers.UsageInfo) float64 {
+	rates := tierCfg.CostPerM
+	cachedRate, writeRate, reasoningRate := rates.Input, rates.Input, rates.Output
+	if rates.CachedInput > 0 {
+		cachedRate = rates.CachedInput
+	}
+	if rates.CacheWrite > 0 {
+		writeRate = rates.CacheWrite
+	}
+	if rates.Reasoning > 0 {
+		reasoningRate = rates.Reasoning
+	}
+
+	uncached := max(usage.PromptTokens-usage.CachedPromptTokens-usage.CacheWriteTokens, 0)
+	visible := max(usage.CompletionTokens-usage.ReasoningTokens, 0)
+	cost := float64(uncached)*rates.Input +
+		float64(usage.CachedPromptTokens)*cachedRate +
+		float64(usage.CacheWriteTokens)*writeRate +
+		float64(visible)*rates.Output +
+		float64(usage.ReasoningTokens)*reasoningRate
+	return cost / 1_000_000.0
+}
+
+// SetBaseline sets the tier used for counterfactual pricing. Calls recorded
+// afterwards also accumulate what they would have cost on this tier.
+func (ct *CostTracker) SetBaseline(tierName string, tierCfg config.TierConfig) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.baselineTier = tierName
+	ct.baseline = &tierCfg
+}
+
+// Record records token usage and calculates cost, attributed to the
+// call's workflow phase, triggering tool and purpose
 func (ct *CostTracker) Record(
 	sessionKey string,
 	modelName string,
@@ -74,21 +216,13 @@ func (ct *CostTracker) Record(
 	tierCfg config.TierConfig,
 	usage providers.UsageInfo,
 	latency time.Duration,
+	attr CostAttribution,
 ) {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 
 	// Get or create session cost
-	session, ok := ct.sessions[sessionKey]
-	if !ok {
-		session = &SessionCost{
-			SessionKey: sessionKey,
-			ByModel:    make(map[string]*ModelCost),
-			ByTier:     make(map[string]*TierCost),
-			StartTime:  time.Now(),
-		}
-		ct.sessions[sessionKey] = session
-	}
+	session := ct.session(sessionKey)
 
 	// Get or create model cost
 	model, ok := session.ByModel[modelName]
@@ -109,13 +243,13 @@ func (ct *CostTracker) Record(
 	}
 
 	// Calculate cost for this call
-	inputCost := float64(usage.PromptTokens) / 1_000_000.0 * tierCfg.CostPerM.Input
-	outputCost := float64(usage.CompletionTokens) / 1_000_000.0 * tierCfg.CostPerM.Output
-	callCost := inputCost + outputCost
+	callCost := EstimateUsageCost(tierCfg, usage)
 
 	// Update model stats
 	model.InputTokens += usage.PromptTokens
 	model.OutputTokens += usage.CompletionTokens
+	model.CachedTokens += usage.CachedPromptTokens
+	model.ReasoningTokens += usage.ReasoningTokens
 	model.Calls++
 	model.TotalCost += callCost
 	model.TotalLatency += latency
@@ -128,9 +262,30 @@ func (ct *CostTracker) Record(
 	tier.TotalCost += callCost
 	tier.TotalLatency += latency
 
+	// Update attribution breakdowns
+	attributedCost(session.ByPhase, attr.Phase).add(usage, callCost)
+	attributedCost(session.ByTool, attr.Tool).add(usage, callCost)
+	attributedCost(session.ByPurpose, attr.Purpose()).add(usage, callCost)
+
+	// Update prompt cache effectiveness, pricing the call as if uncached
+	uncached := usage
+	uncached.CachedPromptTokens, uncached.CacheWriteTokens = 0, 0
+	session.PromptCache.Calls++
+	if usage.CachedPromptTokens > 0 {
+		session.PromptCache.Hits++
+	}
+	session.PromptCache.PromptTokens += usage.PromptTokens
+	session.PromptCache.ReadTokens += usage.CachedPromptTokens
+	session.PromptCache.WriteTokens += usage.CacheWriteTokens
+	session.PromptCache.SavedCost += EstimateUsageCost(tierCfg, uncached) - callCost
+
 	// Update session totals
 	session.TotalCost += callCost
-	session.LastUpdate = time.Now()
+	if ct.baseline != nil {
+		session.BaselineCost += EstimateUsageCost(*ct.baseline, usage)
+		session.BaselineTier = ct.baselineTier
+	}
+	session.LastUpdate = determinism.Now()
 }
 
 // RecordSupervision records supervision-related metrics
@@ -154,7 +309,7 @@ func (ct *CostTracker) RecordSupervision(
 			SessionKey: sessionKey,
 			ByModel:    make(map[string]*ModelCost),
 			ByTier:     make(map[string]*TierCost),
-			StartTime:  time.Now(),
+			StartTime:  determinism.Now(),
 		}
 		ct.sessions[sessionKey] = session
 	}
@@ -183,6 +338,155 @@ func (ct *CostTracker) RecordSupervision(
 	}
 }
 
+// RecordReview records the supervisor's verdict on a worker model's output
+func (ct *CostTracker) RecordReview(sessionKey, workerModel string, approved bool) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	session, ok := ct.sessions[sessionKey]
+	if !ok {
+		session = &SessionCost{
+			SessionKey: sessionKey,
+			ByModel:    make(map[string]*ModelCost),
+			ByTier:     make(map[string]*TierCost),
+			StartTime:  determinism.Now(),
+		}
+		ct.sessions[sessionKey] = session
+	}
+	model, ok := session.ByModel[workerModel]
+	if !ok {
+		model = &ModelCost{ModelName: workerModel}
+		session.ByModel[workerModel] = model
+	}
+	model.Reviewed++
+	if !approved {
+		model.Rejected++
+	}
+}
+
+// RecordHedge records the outcome of a hedging-eligible request. Wasted cost
+// is the loser's estimated spend and is also added to the session total.
+func (ct *CostTracker) RecordHedge(sessionKey string, hedged, hedgeWon bool, wastedCost float64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	session := ct.session(sessionKey)
+	session.Hedging.Requests++
+	if hedged {
+		session.Hedging.Hedged++
+	}
+	if hedgeWon {
+		session.Hedging.HedgeWins++
+	}
+	session.Hedging.WastedCost += wastedCost
+	session.TotalCost += wastedCost
+	session.LastUpdate = determinism.Now()
+}
+
+// RecordCacheHit records a request served from cache. Cached responses are
+// not billed, so savedCost is reported but not added to the session total.
+func (ct *CostTracker) RecordCacheHit(sessionKey string, toolOutput bool, savedCost float64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	session := ct.session(sessionKey)
+	session.Cache.Hits++
+	if toolOutput {
+		session.Cache.ToolOutputHits++
+	}
+	session.Cache.SavedCost += savedCost
+	session.LastUpdate = determinism.Now()
+}
+
+// RecordToolEnforcement records the outcome of an enforced tool-selection
+// turn that took violations responses without a tool call. Retries are billed
+// through Record like any other call.
+func (ct *CostTracker) RecordToolEnforcement(sessionKey string, violations, retries int, complied bool) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	session := ct.session(sessionKey)
+	m := &session.ToolEnforcement
+	m.Turns++
+	m.Violations += violations
+	m.Retries += retries
+	switch {
+	case violations > 0 && complied:
+		m.Recovered++
+	case !complied:
+		m.Failed++
+	}
+	session.LastUpdate = determinism.Now()
+}
+
+// RecordEmbedding records an embeddings call. It counts towards the model and
+// session totals under the embedding purpose but not the tier breakdown or
+// baseline, since embeddings are not routed by tier.
+func (ct *CostTracker) RecordEmbedding(
+	sessionKey string,
+	modelName string,
+	inputTokens int,
+	cost float64,
+	latency time.Duration,
+	attr CostAttribution,
+) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	session := ct.session(sessionKey)
+	model, ok := session.ByModel[modelName]
+	if !ok {
+		model = &ModelCost{ModelName: modelName}
+		session.ByModel[modelName] = model
+	}
+	model.InputTokens += inputTokens
+	model.Calls++
+	model.TotalCost += cost
+	model.TotalLatency += latency
+	model.AvgLatency = model.TotalLatency / time.Duration(model.Calls)
+
+	usage := providers.UsageInfo{PromptTokens: inputTokens, TotalTokens: inputTokens}
+	attributedCost(session.ByPhase, attr.Phase).add(usage, cost)
+	attributedCost(session.ByTool, attr.Tool).add(usage, cost)
+	attributedCost(session.ByPurpose, PurposeEmbedding).add(usage, cost)
+
+	session.TotalCost += cost
+	session.LastUpdate = determinism.Now()
+}
+
+// session returns the session's cost record, creating it if needed.
+// The caller must hold ct.mu.
+func (ct *CostTracker) session(sessionKey string) *SessionCost {
+	session, ok := ct.sessions[sessionKey]
+	if !ok {
+		session = &SessionCost{
+			SessionKey: sessionKey,
+			ByModel:    make(map[string]*ModelCost),
+			ByTier:     make(map[string]*TierCost),
+			ByPhase:    make(map[string]*AttributedCost),
+			ByTool:     make(map[string]*AttributedCost),
+			ByPurpose:  make(map[string]*AttributedCost),
+			StartTime:  determinism.Now(),
+		}
+		ct.sessions[sessionKey] = session
+	}
+	return session
+}
+
+// attributedCost returns the entry for key in costs, creating it if needed.
+// An empty key is recorded as unattributed.
+func attributedCost(costs map[string]*AttributedCost, key string) *AttributedCost {
+	if key == "" {
+		key = unattributed
+	}
+	cost, ok := costs[key]
+	if !ok {
+		cost = &AttributedCost{}
+		costs[key] = cost
+	}
+	return cost
+}
+
 // GetSessionCost returns cost information for a session
 func (ct *CostTracker) GetSessionCost(sessionKey string) *SessionCost {
 	ct.mu.RLock()
@@ -199,9 +503,15 @@ func (ct *CostTracker) GetSessionCost(sessionKey string) *SessionCost {
 		ByModel:    make(map[string]*ModelCost),
 		ByTier:     make(map[string]*TierCost),
 		TotalCost:  session.TotalCost,
+		BaselineCost: session.BaselineCost,
+		BaselineTier: session.BaselineTier,
 		StartTime:  session.StartTime,
 		LastUpdate: session.LastUpdate,
 		Supervision: session.Supervision,
+		Hedging:     session.Hedging,
+		Cache:       session.Cache,
+		PromptCache: session.PromptCache,
+		ToolEnforcement: session.ToolEnforcement,
 	}
 
 	for k, v := range session.ByModel {
@@ -214,9 +524,22 @@ func (ct *CostTracker) GetSessionCost(sessionKey string) *SessionCost {
 		copy.ByTier[k] = &tierCopy
 	}
 
+	copy.ByPhase = copyAttributed(session.ByPhase)
+	copy.ByTool = copyAttributed(session.ByTool)
+	copy.ByPurpose = copyAttributed(session.ByPurpose)
+
 	return copy
 }
 
+func copyAttributed(costs map[string]*AttributedCost) map[string]*AttributedCost {
+	out := make(map[string]*AttributedCost, len(costs))
+	for k, v := range costs {
+		costCopy := *v
+		out[k] = &costCopy
+	}
+	return out
+}
+
 // GetTotalCost returns the total cost across all sessions
 func (ct *CostTracker) GetTotalCost() float64 {
 	ct.mu.RLock()
@@ -242,7 +565,12 @@ func (ct *CostTracker) FormatSessionReport(sessionKey string) string {
 	report += fmt.Sprintf("==================\n")
 	report += fmt.Sprintf("Session: %s\n", sessionKey)
 	report += fmt.Sprintf("Duration: %s\n", duration.Round(time.Second))
-	report += fmt.Sprintf("Total Cost: $%.4f\n\n", session.TotalCost)
+	report += fmt.Sprintf("Total Cost: $%.4f\n", session.TotalCost)
+	if session.BaselineCost > 0 {
+		saved, percent := session.Savings()
+		report += fmt.Sprintf("Saved: $%.2f (%.0f%%) vs all calls on %s ($%.4f)\n", saved, percent, session.BaselineTier, session.BaselineCost)
+	}
+	report += "\n"
 
 	// Add supervision metrics if available
 	if session.Supervision.TotalSupervisions > 0 {
@@ -265,6 +593,27 @@ func (ct *CostTracker) FormatSessionReport(sessionKey string) string {
 		report += fmt.Sprintf("\n")
 	}
 
+	if session.Hedging.Hedged > 0 {
+		report += fmt.Sprintf("Hedging: %d of %d requests hedged (%.0f%%), %d won by hedge, wasted $%.4f\n\n",
+			session.Hedging.Hedged, session.Hedging.Requests, session.Hedging.HedgeRate()*100,
+			session.Hedging.HedgeWins, session.Hedging.WastedCost)
+	}
+
+	if session.Cache.Hits > 0 {
+		report += fmt.Sprintf("Cache: %d responses served from cache (%d tool output re-parses), saved $%.4f\n\n",
+			session.Cache.Hits, session.Cache.ToolOutputHits, session.Cache.SavedCost)
+	}
+
+	if pc := session.PromptCache; pc.ReadTokens > 0 || pc.WriteTokens > 0 {
+		report += fmt.Sprintf("Prompt cache: %.0f%% of %d prompt tokens read from cache (%d of %d calls hit), %d written, saved $%.4f\n\n",
+			pc.HitRate()*100, pc.PromptTokens, pc.Hits, pc.Calls, pc.WriteTokens, pc.SavedCost)
+	}
+
+	if te := session.ToolEnforcement; te.Violations > 0 {
+		report += fmt.Sprintf("Tool enforcement: %d violations in %d tool-only turns, %d retries, %d recovered, %d failed\n\n",
+			te.Violations, te.Turns, te.Retries, te.Recovered, te.Failed)
+	}
+
 	report += fmt.Sprintf("By Tier:\n")
 	report += fmt.Sprintf("--------\n")
 	for tierName, tier := range session.ByTier {
@@ -287,14 +636,69 @@ func (ct *CostTracker) FormatSessionReport(sessionKey string) string {
 		report += fmt.Sprintf("    Calls: %d\n", model.Calls)
 		report += fmt.Sprintf("    Input tokens: %d\n", model.InputTokens)
 		report += fmt.Sprintf("    Output tokens: %d\n", model.OutputTokens)
+		if model.CachedTokens > 0 {
+			report += fmt.Sprintf("    Cached input tokens: %d (%.0f%%)\n", model.CachedTokens,
+				float64(model.CachedTokens)/float64(max(model.InputTokens, 1))*100)
+		}
+		if model.ReasoningTokens > 0 {
+			report += fmt.Sprintf("    Reasoning tokens: %d\n", model.ReasoningTokens)
+		}
 		report += fmt.Sprintf("    Cost: $%.4f\n", model.TotalCost)
 		report += fmt.Sprintf("    Avg latency: %s\n", model.AvgLatency.Round(time.Millisecond))
 		report += fmt.Sprintf("\n")
 	}
 
+	report += formatAttributed("By Purpose", session.ByPurpose, session.TotalCost)
+	if hasAttribution(session.ByPhase) {
+		report += formatAttributed("By Phase", session.ByPhase, session.TotalCost)
+	}
+	if hasAttribution(session.ByTool) {
+		report += formatAttributed("By Tool", session.ByTool, session.TotalCost)
+	}
+
 	return report
 }
 
+// formatAttributed renders one attribution breakdown, largest spend first.
+func formatAttributed(title string, costs map[string]*AttributedCost, total float64) string {
+	if len(costs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(costs))
+	for k := range costs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if costs[keys[i]].TotalCost != costs[keys[j]].TotalCost {
+			return costs[keys[i]].TotalCost > costs[keys[j]].TotalCost
+		}
+		return keys[i] < keys[j]
+	})
+
+	report := fmt.Sprintf("%s:\n%s\n", title, strings.Repeat("-", len(title)+1))
+	for _, k := range keys {
+		c := costs[k]
+		share := 0.0
+		if total > 0 {
+			share = c.TotalCost / total * 100
+		}
+		report += fmt.Sprintf("  %s: %d calls, %d in / %d out tokens, $%.4f (%.0f%%)\n",
+			k, c.Calls, c.InputTokens, c.OutputTokens, c.TotalCost, share)
+	}
+	return report + "\n"
+}
+
+// hasAttribution reports whether any spend was attributed to a named phase
+// or tool.
+func hasAttribution(costs map[string]*AttributedCost) bool {
+	for k := range costs {
+		if k != unattributed {
+			return true
+		}
+	}
+	return false
+}
+
 // Reset clears all cost tracking data
 func (ct *CostTracker) Reset() {
 	ct.mu.Lock()
@@ -0,0 +1,87 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
+)
+
+// Embed embeds inputs with the routing.embeddings model and records the
+// spend for the session, so memory, RAG and embedding-based classification
+// share one integration point.
+func (tr *TierRouter) Embed(ctx context.Context, inputs []string, sessionKey string) (*providers.EmbeddingResponse, error) {
+	embCfg := tr.routingConfig().Embeddings
+	if embCfg.ModelName == "" {
+		return nil, fmt.Errorf("no embeddings model configured (routing.embeddings.model_name)")
+	}
+	embedder, modelID, err := tr.embedder(embCfg.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := embedder.Embed(ctx, inputs, modelID)
+	elapsed := time.Since(start)
+	if err != nil {
+		return nil, fmt.Errorf("embedding with %s: %w", embCfg.ModelName, err)
+	}
+
+	inputTokens := 0
+	if resp.Usage != nil {
+		inputTokens = resp.Usage.PromptTokens
+	} else {
+		for _, input := range inputs {
+			inputTokens += tokens.Estimate(modelID, input)
+		}
+	}
+	cost := float64(inputTokens) / 1_000_000.0 * embCfg.CostPerM
+	tr.costs.RecordEmbedding(sessionKey, embCfg.ModelName, inputTokens, cost, elapsed, costAttributionFrom(ctx))
+
+	logger.DebugCF(tr.component, "Embeddings complete", map[string]any{
+		"model":        embCfg.ModelName,
+		"inputs":       len(inputs),
+		"input_tokens": inputTokens,
+		"latency":      elapsed.String(),
+	})
+	return resp, nil
+}
+
+// embedder returns the embeddings provider for a model_list name and the
+// model ID to send. A routed provider that can embed is reused; otherwise
+// one is created from the model_list entry and kept for later calls.
+func (tr *TierRouter) embedder(modelName string) (providers.EmbeddingsProvider, string, error) {
+	_, modelID := providers.ExtractProtocol(tr.modelID(modelName))
+	routed, _ := tr.provider(modelName)
+	if embedder, ok := routed.(providers.EmbeddingsProvider); ok {
+		return embedder, modelID, nil
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if embedder, ok := tr.embedders[modelName]; ok {
+		return embedder, modelID, nil
+	}
+	for _, modelCfg := range tr.modelList {
+		if modelCfg.ModelName != modelName {
+			continue
+		}
+		provider, _, err := providers.CreateProviderFromConfig(&modelCfg)
+		if err != nil {
+			return nil, "", fmt.Errorf("creating embeddings provider for %s: %w", modelName, err)
+		}
+		embedder, ok := provider.(providers.EmbeddingsProvider)
+		if !ok {
+			return nil, "", fmt.Errorf("model %s does not support embeddings", modelName)
+		}
+		if tr.embedders == nil {
+			tr.embedders = make(map[string]providers.EmbeddingsProvider)
+		}
+		tr.embedders[modelName] = embedder
+		return embedder, modelID, nil
+	}
+	return nil, "", fmt.Errorf("embeddings model %s is not in model_list", modelName)
+}
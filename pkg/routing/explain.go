@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// RuleMatch is a classification rule that matched the agent context.
+type RuleMatch struct {
+	Rule string   `json:"rule"`
+	Task TaskType `json:"task"`
+}
+
+// KeywordHit is a complexity keyword found in the user message.
+type KeywordHit struct {
+	Keyword  string `json:"keyword"`
+	Modifier int    `json:"modifier"`
+}
+
+// RouteAlternative is a task type that also matched but lost to an earlier
+// rule, with the tier it would have been routed to.
+type RouteAlternative struct {
+	Rule     string              `json:"rule"`
+	Task     TaskType            `json:"task"`
+	Tier     string              `json:"tier,omitempty"`
+	Model    string              `json:"model,omitempty"`
+	CostPerM config.CostPerMInfo `json:"cost_per_m"`
+}
+
+// RouteExplanation describes why an agent context is routed where it is.
+type RouteExplanation struct {
+	Task                TaskType     `json:"task"`
+	Rule                string       `json:"rule"` // Rule that decided the task
+	MatchedRules        []RuleMatch  `json:"matched_rules"`
+	KeywordHits         []KeywordHit `json:"keyword_hits,omitempty"`
+	Complexity          int          `json:"complexity"`
+	Confidence          float64      `json:"confidence"`
+	RequiresSupervision bool         `json:"requires_supervision"`
+
+	Tier       string              `json:"tier,omitempty"`
+	TierReason string              `json:"tier_reason,omitempty"`
+	Model      string              `json:"model,omitempty"`
+	CostPerM   config.CostPerMInfo `json:"cost_per_m"`
+	TierError  string              `json:"tier_error,omitempty"`
+
+	Alternatives []RouteAlternative `json:"alternatives,omitempty"`
+}
+
+// ExplainRoute classifies ctx exactly as ClassifyTask does and reports the
+// matched rules, keyword hits, complexity score, chosen tier, and the
+// alternatives that lost, without sending any request.
+func (tr *TierRouter) ExplainRoute(ctx AgentContext) *RouteExplanation {
+	ex := tr.classify(ctx)
+
+	tierName, tierCfg, err := tr.SelectTier(ex.Task)
+	if err != nil {
+		ex.TierError = err.Error()
+	} else {
+		ex.Tier = tierName
+		ex.TierReason = tr.tierReason(tierName, ex.Task)
+		ex.Model = tierCfg.ModelName
+		ex.CostPerM = tierCfg.CostPerM
+	}
+
+	for _, m := range ex.MatchedRules[1:] {
+		alt := RouteAlternative{Rule: m.Rule, Task: m.Task}
+		if name, cfg, err := tr.SelectTier(m.Task); err == nil {
+			alt.Tier, alt.Model, alt.CostPerM = name, cfg.ModelName, cfg.CostPerM
+		}
+		ex.Alternatives = append(ex.Alternatives, alt)
+	}
+	return ex
+}
+
+// tierReason says which part of the routing config sent taskType to tierName,
+// mirroring the checks in SelectTier.
+func (tr *TierRouter) tierReason(tierName string, taskType TaskType) string {
+	routingCfg := tr.routingConfig()
+	if !routingCfg.Enabled {
+		return "routing disabled; default tier"
+	}
+	if strings.EqualFold(tierName, string(taskType)) {
+		return "tier name matches task"
+	}
+	for _, taskName := range routingCfg.Tiers[tierName].UseFor {
+		if strings.EqualFold(taskName, string(taskType)) {
+			return "listed in tier use_for"
+		}
+	}
+	return "no tier handles task; default tier"
+}
@@ -0,0 +1,203 @@
+package routing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
+)
+
+const (
+	// latencyWindowSize is how many recent latencies are kept per model.
+	latencyWindowSize = 100
+
+	// defaultHedgeMinSamples is how many latencies a model needs before its
+	// p95 is trusted as a hedging threshold.
+	defaultHedgeMinSamples = 20
+)
+
+// LatencyTracker keeps a sliding window of recent request latencies per model.
+type LatencyTracker struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration // model_name -> ring buffer
+	next    map[string]int
+}
+
+// NewLatencyTracker creates an empty latency tracker.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		samples: make(map[string][]time.Duration),
+		next:    make(map[string]int),
+	}
+}
+
+// Observe records one request latency for a model.
+func (lt *LatencyTracker) Observe(modelName string, latency time.Duration) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	window := lt.samples[modelName]
+	if len(window) < latencyWindowSize {
+		lt.samples[modelName] = append(window, latency)
+		return
+	}
+	window[lt.next[modelName]] = latency
+	lt.next[modelName] = (lt.next[modelName] + 1) % latencyWindowSize
+}
+
+// P95 returns the model's 95th percentile latency, or false if fewer than
+// minSamples latencies have been observed.
+func (lt *LatencyTracker) P95(modelName string, minSamples int) (time.Duration, bool) {
+	lt.mu.Lock()
+	window := append([]time.Duration(nil), lt.samples[modelName]...)
+	lt.mu.Unlock()
+
+	if len(window) == 0 || len(window) < minSamples {
+		return 0, false
+	}
+	sort.Slice(window, func(i, j int) bool { return window[i] < window[j] })
+	return window[(len(window)*95+99)/100-1], true
+}
+
+// hedgeResult is one leg of a hedged request.
+type hedgeResult struct {
+	resp     *providers.LLMResponse
+	elapsed  time.Duration
+	err      error
+	tierName string
+	tierCfg  config.TierConfig
+	hedge    bool
+}
+
+// chatWithHedge sends the request to the tier's model. When hedging is
+// enabled and the tier has a hedge_model, a duplicate request goes to that
+// model once the primary outlasts the tier model's p95 latency; the first
+// successful response wins and the other is cancelled. It returns the
+// response, its latency, and the tier that actually answered.
+func (tr *TierRouter) chatWithHedge(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	tierName string,
+	tierCfg *config.TierConfig,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) (*providers.LLMResponse, time.Duration, string, *config.TierConfig, error) {
+	prepared := tr.withPreamble(sessionKey, tierCfg.ModelName, messages)
+
+	threshold, hedgeProvider, ok := tr.hedgePlan(tierCfg)
+	if !ok {
+		resp, elapsed, err := tr.chat(ctx, provider, tierCfg.ModelName, prepared, tools, tierCfg.ModelName, options, sessionKey)
+		return resp, elapsed, tierName, tierCfg, err
+	}
+
+	hedgeModel := tierCfg.HedgeModel
+	hedgeTierName, hedgeTierCfg, err := tr.getTierForModel(hedgeModel)
+	if err != nil {
+		// Not routed by any tier: attribute it to the primary tier, unpriced
+		hedgeTierName, hedgeTierCfg = tierName, &config.TierConfig{ModelName: hedgeModel}
+	}
+
+	results := make(chan hedgeResult, 2)
+	launch := func(ctx context.Context, p providers.LLMProvider, name string, cfg config.TierConfig, msgs []providers.Message, hedge bool) {
+		resp, elapsed, err := tr.chat(ctx, p, cfg.ModelName, msgs, tools, cfg.ModelName, options, sessionKey)
+		results <- hedgeResult{resp: resp, elapsed: elapsed, err: err, tierName: name, tierCfg: cfg, hedge: hedge}
+	}
+
+	start := time.Now()
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	go launch(primaryCtx, provider, tierName, *tierCfg, prepared, false)
+
+	timer := time.NewTimer(threshold)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		tr.costs.RecordHedge(sessionKey, false, false, 0)
+		return r.resp, r.elapsed, r.tierName, &r.tierCfg, r.err
+	case <-ctx.Done():
+		return nil, 0, tierName, tierCfg, ctx.Err()
+	case <-timer.C:
+	}
+
+	logger.InfoCF(tr.component, "Primary exceeded p95 latency, hedging", map[string]any{
+		"tier":        tierName,
+		"model":       tierCfg.ModelName,
+		"hedge_model": hedgeModel,
+		"p95":         threshold.String(),
+	})
+
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+	go launch(hedgeCtx, hedgeProvider, hedgeTierName, *hedgeTierCfg, tr.withPreamble(sessionKey, hedgeModel, messages), true)
+
+	// First success wins; an error only counts once both legs have failed
+	var first hedgeResult
+	for i := 0; i < 2; i++ {
+		var r hedgeResult
+		select {
+		case r = <-results:
+		case <-ctx.Done():
+			return nil, 0, tierName, tierCfg, ctx.Err()
+		}
+		if r.err != nil {
+			if i == 0 {
+				first = r
+			}
+			continue
+		}
+
+		// Cancel the loser; its prompt is billed even though it never answers
+		loser := *tierCfg
+		if r.hedge {
+			cancelPrimary()
+			tr.latencies.Observe(tierCfg.ModelName, time.Since(start)) // censored: at least this slow
+		} else {
+			cancelHedge()
+			loser = *hedgeTierCfg
+		}
+		id := tr.modelID(loser.ModelName)
+		wasted := EstimateCallCost(loser, tokens.EstimateMessages(id, messages)+tokens.EstimateTools(id, tools), 0)
+		tr.costs.RecordHedge(sessionKey, true, r.hedge, wasted)
+
+		logger.DebugCF(tr.component, "Hedged request resolved", map[string]any{
+			"winner":      r.tierCfg.ModelName,
+			"hedge_won":   r.hedge,
+			"wasted_cost": wasted,
+		})
+		return r.resp, r.elapsed, r.tierName, &r.tierCfg, nil
+	}
+
+	tr.costs.RecordHedge(sessionKey, true, false, 0)
+	return nil, 0, tierName, tierCfg, first.err
+}
+
+// hedgePlan returns the latency threshold and provider for hedging a tier, or
+// false when hedging does not apply: disabled, no hedge model or provider,
+// or too few latency samples for a p95.
+func (tr *TierRouter) hedgePlan(tierCfg *config.TierConfig) (time.Duration, providers.LLMProvider, bool) {
+	routingCfg := tr.routingConfig()
+	if routingCfg == nil || !routingCfg.Hedging.Enabled || tierCfg.HedgeModel == "" || tierCfg.HedgeModel == tierCfg.ModelName {
+		return 0, nil, false
+	}
+	hedgeProvider, ok := tr.provider(tierCfg.HedgeModel)
+	if !ok {
+		return 0, nil, false
+	}
+	minSamples := routingCfg.Hedging.MinSamples
+	if minSamples <= 0 {
+		minSamples = defaultHedgeMinSamples
+	}
+	threshold, ok := tr.latencies.P95(tierCfg.ModelName, minSamples)
+	if !ok {
+		return 0, nil, false
+	}
+	return threshold, hedgeProvider, true
+}
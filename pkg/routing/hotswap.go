@@ -0,0 +1,142 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// provider returns the provider routed requests for modelName go to.
+func (tr *TierRouter) provider(modelName string) (providers.LLMProvider, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	provider, ok := tr.providers[modelName]
+	return provider, ok
+}
+
+// SetModelSwitchHandler registers a callback invoked after SetTierModel
+// points a tier at a different model, so frontends can show the new model.
+func (tr *TierRouter) SetModelSwitchHandler(fn func(tierName, modelName string)) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.onModelSwitch = fn
+}
+
+// TierModel returns the model_name a tier currently routes to.
+func (tr *TierRouter) TierModel(tierName string) (string, bool) {
+	routingCfg := tr.routingConfig()
+	if routingCfg == nil {
+		return "", false
+	}
+	tierCfg, ok := routingCfg.Tiers[tierName]
+	return tierCfg.ModelName, ok
+}
+
+// SetTierModel points a tier at a model_list entry mid-session and creates a
+// fresh provider for it, e.g. after a local server restarted with a different
+// model, or to move report writing onto a bigger model. Setting a tier to the
+// model it already uses just re-initializes the provider. Requests in flight
+// finish on the old provider; the tier keeps its configured pricing.
+func (tr *TierRouter) SetTierModel(tierName, modelName string) error {
+	current := tr.routingConfig()
+	if current == nil {
+		return fmt.Errorf("tier routing is not configured")
+	}
+	if _, ok := current.Tiers[tierName]; !ok {
+		return fmt.Errorf("tier %q is not defined", tierName)
+	}
+
+	var modelCfg *config.ModelConfig
+	for i := range tr.modelList {
+		if tr.modelList[i].ModelName == modelName {
+			modelCfg = &tr.modelList[i]
+			break
+		}
+	}
+	if modelCfg == nil {
+		return fmt.Errorf("model %q is not in model_list", modelName)
+	}
+
+	llm, modelID, err := tr.newProvider(modelCfg)
+	if err != nil {
+		return fmt.Errorf("initializing provider for %s: %w", modelName, err)
+	}
+
+	updated := *current
+	updated.Tiers = make(map[string]config.TierConfig, len(current.Tiers))
+	for name, tierCfg := range current.Tiers {
+		updated.Tiers[name] = tierCfg
+	}
+	tierCfg := updated.Tiers[tierName]
+	oldModel := tierCfg.ModelName
+	tierCfg.ModelName = modelName
+	updated.Tiers[tierName] = tierCfg
+
+	tr.mu.Lock()
+	old, hadOld := tr.providers[modelName]
+	ownedOld := tr.swapped[modelName]
+	tr.providers[modelName] = &swappedProvider{LLMProvider: llm, modelID: modelID}
+	tr.swapped[modelName] = true
+	onSwitch := tr.onModelSwitch
+	tr.mu.Unlock()
+
+	if err := tr.ReloadConfig(&updated); err != nil {
+		tr.mu.Lock()
+		if hadOld {
+			tr.providers[modelName] = old
+		} else {
+			delete(tr.providers, modelName)
+		}
+		tr.swapped[modelName] = ownedOld
+		tr.mu.Unlock()
+		closeProvider(llm)
+		return err
+	}
+
+	// Providers from the startup map may be shared between models, so only
+	// ones created here are closed
+	if hadOld && ownedOld {
+		closeProvider(old)
+	}
+
+	logger.InfoCF(tr.component, "Tier model switched", map[string]any{
+		"tier":      tierName,
+		"old_model": oldModel,
+		"model":     modelName,
+		"model_id":  modelID,
+	})
+	if onSwitch != nil {
+		onSwitch(tierName, modelName)
+	}
+	return nil
+}
+
+// swappedProvider sends requests with the model_list identifier. Providers
+// passed to NewTierRouter receive the model_name; a re-initialized provider
+// talks to the endpoint directly and needs the real model ID.
+type swappedProvider struct {
+	providers.LLMProvider
+	modelID string
+}
+
+func (p *swappedProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	_ string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	return p.LLMProvider.Chat(ctx, messages, tools, p.modelID, options)
+}
+
+func closeProvider(p providers.LLMProvider) {
+	if swapped, ok := p.(*swappedProvider); ok {
+		p = swapped.LLMProvider
+	}
+	if stateful, ok := p.(providers.StatefulProvider); ok {
+		stateful.Close()
+	}
+}
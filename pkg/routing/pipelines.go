@@ -0,0 +1,51 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/pipeline"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// SetPipelines sets the output pipelines that task_overrides attach to task
+// types by name
+func (tr *TierRouter) SetPipelines(pipelines map[string]*pipeline.Pipeline) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.pipelines = pipelines
+}
+
+// applyPipeline runs the output pipeline attached to taskType, if any, on a
+// text response. Responses with tool calls pass through as they are. The
+// response is copied, since cached responses are shared.
+func (tr *TierRouter) applyPipeline(ctx context.Context, taskType TaskType, resp *providers.LLMResponse) (*providers.LLMResponse, error) {
+	name := tr.routingConfig().TaskOverrides[string(taskType)].Pipeline
+	if name == "" || resp.Refused || len(resp.ToolCalls) > 0 {
+		return resp, nil
+	}
+
+	tr.mu.RLock()
+	p := tr.pipelines[name]
+	tr.mu.RUnlock()
+	if p == nil {
+		return nil, fmt.Errorf("task %s uses output pipeline %s, which isn't configured", taskType, name)
+	}
+
+	out, err := p.Run(ctx, string(taskType), resp.Content)
+	if err != nil {
+		logger.WarnCF(tr.component, "Output pipeline failed", map[string]any{
+			"task":     taskType,
+			"pipeline": name,
+			"model":    resp.Model,
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	processed := *resp
+	processed.Content = out.Text
+	processed.Artifacts = out.Artifacts
+	return &processed, nil
+}
@@ -0,0 +1,197 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// RoutingPolicy lets code outside the router enforce organization-specific
+// rules (no external APIs for a client, a cheaper model after hours, ...)
+// without forking the classifier. Policies run in the order they were added;
+// each sees the decision left by the ones before it.
+type RoutingPolicy interface {
+	// PreRoute runs after tier selection and before the request is sent. An
+	// error aborts the request: policies fail closed.
+	PreRoute(ctx context.Context, req PolicyRequest) (PolicyDecision, error)
+	// PostRoute runs after a successful response.
+	PostRoute(ctx context.Context, req PolicyRequest, resp *providers.LLMResponse)
+	// OnError runs when the routed request fails.
+	OnError(ctx context.Context, req PolicyRequest, err error)
+}
+
+// PolicyRequest describes a routing decision for policies to inspect.
+type PolicyRequest struct {
+	SessionKey  string            `json:"session_key"`
+	Task        TaskType          `json:"task"`
+	Tier        string            `json:"tier"`
+	Model       string            `json:"model"` // model_list name
+	Tiers       []string          `json:"tiers"` // Every configured tier, sorted
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// EstimatedCost is the request's worst-case cost in USD: its prompt plus
+	// the full max_tokens output at the selected tier's rates. See
+	// TierRouter.EstimatePreflight.
+	EstimatedCost float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// PolicyDecision is a policy's verdict. The zero value leaves the route
+// unchanged.
+type PolicyDecision struct {
+	// VetoTiers lists tiers the request must not use. A vetoed choice falls
+	// back to the default tier, then to the first tier left by name.
+	VetoTiers []string `json:"veto_tiers,omitempty"`
+	// ForceTier replaces the selected tier.
+	ForceTier string `json:"force_tier,omitempty"`
+	// ForceModel sends the request to another model_list entry, keeping the
+	// tier's pricing and settings.
+	ForceModel string `json:"force_model,omitempty"`
+	// Annotations are attached to the decision and logged with it.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AddPolicy appends a routing policy.
+func (tr *TierRouter) AddPolicy(policy RoutingPolicy) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.policies = append(tr.policies, policy)
+}
+
+func (tr *TierRouter) routingPolicies() []RoutingPolicy {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.policies
+}
+
+// applyPolicies runs every PreRoute hook over the selected tier and returns
+// the request as the policies left it, with the tier to use. Policies see
+// the request's preflight cost estimate, so budget rules can refuse a call
+// before it overspends.
+func (tr *TierRouter) applyPolicies(
+	ctx context.Context,
+	taskType TaskType,
+	tierName string,
+	tierCfg *config.TierConfig,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) (PolicyRequest, string, *config.TierConfig, error) {
+	routingCfg := tr.routingConfig()
+	req := PolicyRequest{
+		SessionKey: sessionKey,
+		Task:       taskType,
+		Tier:       tierName,
+		Model:      tierCfg.ModelName,
+		Tiers:      tr.TierNames(),
+	}
+	policies := tr.routingPolicies()
+	if len(policies) == 0 {
+		return req, tierName, tierCfg, nil
+	}
+	// A request that fits no tier's context window fails after the policies
+	// run, so it is left without an estimate here
+	if preflight, err := tr.EstimatePreflight(taskType, messages, tools, options); err == nil {
+		req.EstimatedCost = preflight.MaxCost
+	}
+
+	vetoed := make(map[string]bool)
+	forcedModel := ""
+	for i, policy := range policies {
+		decision, err := policy.PreRoute(ctx, req)
+		if err != nil {
+			return req, "", nil, fmt.Errorf("routing policy %d rejected the request: %w", i, err)
+		}
+		for _, name := range decision.VetoTiers {
+			vetoed[name] = true
+		}
+		if decision.ForceTier != "" {
+			forced, ok := routingCfg.Tiers[decision.ForceTier]
+			if !ok {
+				return req, "", nil, fmt.Errorf("routing policy %d forced unknown tier %q", i, decision.ForceTier)
+			}
+			tierName, tierCfg = decision.ForceTier, &forced
+		}
+		if decision.ForceModel != "" {
+			if _, ok := tr.provider(decision.ForceModel); !ok {
+				return req, "", nil, fmt.Errorf("routing policy %d forced model %q, which has no provider", i, decision.ForceModel)
+			}
+			forcedModel = decision.ForceModel
+		}
+		for k, v := range decision.Annotations {
+			if req.Annotations == nil {
+				req.Annotations = make(map[string]string)
+			}
+			req.Annotations[k] = v
+		}
+		req.Tier, req.Model = tierName, tierCfg.ModelName
+		if forcedModel != "" {
+			req.Model = forcedModel
+		}
+	}
+
+	if vetoed[tierName] {
+		replacement, ok := firstAllowedTier(routingCfg, vetoed)
+		if !ok {
+			return req, "", nil, fmt.Errorf("routing policies vetoed every tier for task %s", taskType)
+		}
+		logger.InfoCF(tr.component, "Routing policy vetoed tier", map[string]any{
+			"task":        taskType,
+			"vetoed_tier": tierName,
+			"tier":        replacement,
+		})
+		replacementCfg := routingCfg.Tiers[replacement]
+		tierName, tierCfg = replacement, &replacementCfg
+	}
+	if forcedModel != "" {
+		// The forced model keeps whichever tier survived, vetoes included
+		forced := *tierCfg
+		forced.ModelName = forcedModel
+		tierCfg = &forced
+	}
+	req.Tier, req.Model = tierName, tierCfg.ModelName
+
+	if len(req.Annotations) > 0 {
+		logger.InfoCF(tr.component, "Routing policy annotations", map[string]any{
+			"task":        taskType,
+			"tier":        tierName,
+			"model":       tierCfg.ModelName,
+			"annotations": req.Annotations,
+		})
+	}
+	return req, tierName, tierCfg, nil
+}
+
+// firstAllowedTier returns the default tier unless vetoed, else the first
+// tier by name that is not.
+func firstAllowedTier(routingCfg *config.RoutingConfig, vetoed map[string]bool) (string, bool) {
+	if _, ok := routingCfg.Tiers[routingCfg.DefaultTier]; ok && !vetoed[routingCfg.DefaultTier] {
+		return routingCfg.DefaultTier, true
+	}
+	names := make([]string, 0, len(routingCfg.Tiers))
+	for name := range routingCfg.Tiers {
+		if !vetoed[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+func (tr *TierRouter) policiesPostRoute(ctx context.Context, req PolicyRequest, resp *providers.LLMResponse) {
+	for _, policy := range tr.routingPolicies() {
+		policy.PostRoute(ctx, req, resp)
+	}
+}
+
+func (tr *TierRouter) policiesOnError(ctx context.Context, req PolicyRequest, err error) {
+	for _, policy := range tr.routingPolicies() {
+		policy.OnError(ctx, req, err)
+	}
+}
@@ -0,0 +1,154 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// Hook names sent to script policies.
+const (
+	HookPreRoute  = "pre_route"
+	HookPostRoute = "post_route"
+	HookOnError   = "on_error"
+)
+
+const defaultPolicyTimeout = 2 * time.Second
+
+// ExecPolicy runs a routing policy as an external program, so a policy can
+// be a small Lua, Starlark or Python script. For each hook the program gets
+// one JSON object on stdin:
+//
+//	{"hook": "pre_route", "request": {...}}
+//	{"hook": "post_route", "request": {...}, "response": {...}}
+//	{"hook": "on_error", "request": {...}, "error": "..."}
+//
+// For pre_route it may print a PolicyDecision as JSON; empty output changes
+// nothing. Output from the other hooks is ignored.
+type ExecPolicy struct {
+	name    string
+	command []string
+	timeout time.Duration
+	hooks   []string
+}
+
+// NewExecPolicy creates a script policy from config. Hooks default to
+// pre_route only, so scripts that only veto or force don't run per response.
+func NewExecPolicy(cfg config.RoutingPolicyConfig) (*ExecPolicy, error) {
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("routing policy %q has no command", cfg.Name)
+	}
+	hooks := cfg.Hooks
+	if len(hooks) == 0 {
+		hooks = []string{HookPreRoute}
+	}
+	for _, hook := range hooks {
+		if hook != HookPreRoute && hook != HookPostRoute && hook != HookOnError {
+			return nil, fmt.Errorf("routing policy %q has unknown hook %q", cfg.Name, hook)
+		}
+	}
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultPolicyTimeout
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Command[0]
+	}
+	return &ExecPolicy{name: name, command: cfg.Command, timeout: timeout, hooks: hooks}, nil
+}
+
+type execPolicyInput struct {
+	Hook     string              `json:"hook"`
+	Request  PolicyRequest       `json:"request"`
+	Response *execPolicyResponse `json:"response,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// execPolicyResponse is the part of a response scripts see; content is left
+// out so findings and target data don't leave the process.
+type execPolicyResponse struct {
+	FinishReason string               `json:"finish_reason"`
+	Refused      bool                 `json:"refused"`
+	ToolCalls    int                  `json:"tool_calls"`
+	Usage        *providers.UsageInfo `json:"usage,omitempty"`
+}
+
+func (p *ExecPolicy) PreRoute(ctx context.Context, req PolicyRequest) (PolicyDecision, error) {
+	var decision PolicyDecision
+	if !slices.Contains(p.hooks, HookPreRoute) {
+		return decision, nil
+	}
+	out, err := p.run(ctx, execPolicyInput{Hook: HookPreRoute, Request: req})
+	if err != nil {
+		return decision, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return decision, nil
+	}
+	if err := json.Unmarshal(out, &decision); err != nil {
+		return decision, fmt.Errorf("policy %s printed an invalid decision: %w", p.name, err)
+	}
+	return decision, nil
+}
+
+func (p *ExecPolicy) PostRoute(ctx context.Context, req PolicyRequest, resp *providers.LLMResponse) {
+	if !slices.Contains(p.hooks, HookPostRoute) {
+		return
+	}
+	input := execPolicyInput{Hook: HookPostRoute, Request: req}
+	if resp != nil {
+		input.Response = &execPolicyResponse{
+			FinishReason: resp.FinishReason,
+			Refused:      resp.Refused,
+			ToolCalls:    len(resp.ToolCalls),
+			Usage:        resp.Usage,
+		}
+	}
+	if _, err := p.run(ctx, input); err != nil {
+		logger.WarnCF("tier-router", "Routing policy hook failed",
+			map[string]any{"policy": p.name, "hook": HookPostRoute, "error": err.Error()})
+	}
+}
+
+func (p *ExecPolicy) OnError(ctx context.Context, req PolicyRequest, err error) {
+	if !slices.Contains(p.hooks, HookOnError) {
+		return
+	}
+	if _, runErr := p.run(ctx, execPolicyInput{Hook: HookOnError, Request: req, Error: err.Error()}); runErr != nil {
+		logger.WarnCF("tier-router", "Routing policy hook failed",
+			map[string]any{"policy": p.name, "hook": HookOnError, "error": runErr.Error()})
+	}
+}
+
+func (p *ExecPolicy) run(ctx context.Context, input execPolicyInput) ([]byte, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("policy %s: %w: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("policy %s: %w", p.name, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,258 @@
+package routing
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
+)
+
+// tokenBucket refills at perMinute/60 per second up to perMinute. Reservations
+// may drive the balance negative; the deficit becomes the caller's wait, so
+// concurrent callers queue in arrival order instead of racing.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	perSec   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+// reserve takes n from the bucket and returns how long the caller must wait
+// before the reservation is covered. A request larger than the bucket is
+// clamped to its capacity so it waits for a full bucket rather than forever.
+func (b *tokenBucket) reserve(n float64, now time.Time) (float64, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	n = math.Min(n, b.capacity)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return n, 0
+	}
+	return n, time.Duration(-b.tokens / b.perSec * float64(time.Second))
+}
+
+// refund returns n to the bucket (negative n charges extra).
+func (b *tokenBucket) refund(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens = math.Min(b.capacity, b.tokens+n)
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.perSec)
+		b.last = now
+	}
+}
+
+// rateBuckets are the request and token buckets of one limit scope.
+type rateBuckets struct {
+	rpm *tokenBucket
+	tpm *tokenBucket
+}
+
+// RateLimiter enforces requests/min and tokens/min per model (model_list
+// rpm/tpm) and per provider protocol (routing.provider_rate_limits).
+type RateLimiter struct {
+	models    map[string]*rateBuckets // model_name -> buckets
+	providers map[string]*rateBuckets // protocol -> buckets
+	protocols map[string]string       // model_name -> protocol
+	component string
+}
+
+// NewRateLimiter builds a limiter from the configured limits. It returns nil
+// when no limits are configured; a nil limiter never waits.
+func NewRateLimiter(modelList []config.ModelConfig, providerLimits map[string]config.RateLimitConfig) *RateLimiter {
+	now := time.Now()
+	newBuckets := func(rpm, tpm int) *rateBuckets {
+		if rpm <= 0 && tpm <= 0 {
+			return nil
+		}
+		b := &rateBuckets{}
+		if rpm > 0 {
+			b.rpm = newTokenBucket(rpm, now)
+		}
+		if tpm > 0 {
+			b.tpm = newTokenBucket(tpm, now)
+		}
+		return b
+	}
+
+	rl := &RateLimiter{
+		models:    make(map[string]*rateBuckets),
+		providers: make(map[string]*rateBuckets),
+		protocols: make(map[string]string),
+		component: "rate-limiter",
+	}
+	for _, model := range modelList {
+		protocol, _ := providers.ExtractProtocol(model.Model)
+		rl.protocols[model.ModelName] = protocol
+		if b := newBuckets(model.RPM, model.TPM); b != nil {
+			rl.models[model.ModelName] = b
+		}
+	}
+	for protocol, limit := range providerLimits {
+		if b := newBuckets(limit.RPM, limit.TPM); b != nil {
+			rl.providers[protocol] = b
+		}
+	}
+
+	if len(rl.models) == 0 && len(rl.providers) == 0 {
+		return nil
+	}
+	return rl
+}
+
+// rateReservation records what a request took from the token buckets so the
+// estimate can be settled against actual usage.
+type rateReservation struct {
+	tpm      []*tokenBucket
+	reserved []float64
+}
+
+// settle corrects the token buckets from the estimate to the tokens the
+// provider actually reported.
+func (r *rateReservation) settle(actual int) {
+	if r == nil {
+		return
+	}
+	for i, b := range r.tpm {
+		b.refund(r.reserved[i] - math.Min(float64(actual), b.capacity))
+	}
+}
+
+// Wait blocks until modelName may send a request of estimatedTokens, queueing
+// behind earlier callers. If ctx is cancelled first the reservation is
+// returned to the buckets and ctx's error is returned.
+func (rl *RateLimiter) Wait(ctx context.Context, modelName string, estimatedTokens int) (*rateReservation, error) {
+	if rl == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	res := &rateReservation{}
+	var rpm []*tokenBucket
+	var delay time.Duration
+	for _, b := range []*rateBuckets{rl.models[modelName], rl.providers[rl.protocols[modelName]]} {
+		if b == nil {
+			continue
+		}
+		if b.rpm != nil {
+			_, wait := b.rpm.reserve(1, now)
+			rpm = append(rpm, b.rpm)
+			delay = max(delay, wait)
+		}
+		if b.tpm != nil {
+			n, wait := b.tpm.reserve(float64(estimatedTokens), now)
+			res.tpm = append(res.tpm, b.tpm)
+			res.reserved = append(res.reserved, n)
+			delay = max(delay, wait)
+		}
+	}
+	if delay <= 0 {
+		return res, nil
+	}
+
+	logger.DebugCF(rl.component, "Rate limit reached, queueing request", map[string]any{
+		"model":  modelName,
+		"tokens": estimatedTokens,
+		"wait":   delay.String(),
+	})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return res, nil
+	case <-ctx.Done():
+		for _, b := range rpm {
+			b.refund(1)
+		}
+		for i, b := range res.tpm {
+			b.refund(res.reserved[i])
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// chat sends one request to provider after waiting for the spend governor and
+// the rate limits of modelName (the model_list name), then settles both
+// estimates against the reported usage. The returned latency excludes time
+// spent queued.
+func (tr *TierRouter) chat(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	modelName string,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+	sessionKey string,
+) (*providers.LLMResponse, time.Duration, error) {
+	if !tr.supportsVision(modelName) {
+		messages = providers.WithoutImages(messages)
+	}
+
+	var prompt, maxOutput int
+	if tr.rateLimiter != nil || tr.spendGovernor != nil {
+		id := tr.modelID(modelName)
+		maxOutput, _ = options["max_tokens"].(int)
+		prompt = tokens.EstimateMessages(id, messages) + tokens.EstimateTools(id, tools)
+	}
+
+	var spend *spendReservation
+	var tierCfg *config.TierConfig
+	if tr.spendGovernor != nil {
+		if _, cfg, err := tr.getTierForModel(modelName); err == nil {
+			tierCfg = cfg
+			if spend, err = tr.spendGovernor.Wait(ctx, sessionKey, EstimateCallCost(*cfg, prompt, maxOutput)); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	var res *rateReservation
+	if tr.rateLimiter != nil {
+		var err error
+		if res, err = tr.rateLimiter.Wait(ctx, modelName, prompt+maxOutput); err != nil {
+			spend.release()
+			return nil, 0, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := provider.Chat(ctx, messages, tools, model, options)
+	elapsed := time.Since(start)
+	if err == nil && resp != nil {
+		tr.latencies.Observe(modelName, elapsed)
+		if resp.Usage != nil {
+			res.settle(resp.Usage.PromptTokens + resp.Usage.CompletionTokens)
+			if tierCfg != nil {
+				spend.settle(EstimateUsageCost(*tierCfg, *resp.Usage))
+			}
+		}
+	} else if ctx.Err() == nil {
+		// Failed requests are not billed; cancelled ones may have been
+		spend.release()
+	}
+	return resp, elapsed, err
+}
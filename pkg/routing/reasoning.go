@@ -0,0 +1,33 @@
+package routing
+
+import (
+	"maps"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// withTierReasoning returns options carrying the tier's reasoning_effort and
+// thinking_budget. Values already present, e.g. from a task override, are
+// kept. options is not modified in place.
+func withTierReasoning(options map[string]any, tierCfg *config.TierConfig) map[string]any {
+	if tierCfg == nil || (tierCfg.ReasoningEffort == "" && tierCfg.ThinkingBudget == 0) {
+		return options
+	}
+	_, hasEffort := options["reasoning_effort"]
+	_, hasBudget := options["thinking_budget"]
+	setEffort := tierCfg.ReasoningEffort != "" && !hasEffort
+	setBudget := tierCfg.ThinkingBudget != 0 && !hasBudget
+	if !setEffort && !setBudget {
+		return options
+	}
+
+	merged := make(map[string]any, len(options)+2)
+	maps.Copy(merged, options)
+	if setEffort {
+		merged["reasoning_effort"] = tierCfg.ReasoningEffort
+	}
+	if setBudget {
+		merged["thinking_budget"] = tierCfg.ThinkingBudget
+	}
+	return merged
+}
@@ -0,0 +1,90 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// routingConfig returns the active routing config. Callers read it once per
+// decision so a concurrent ReloadConfig never mixes two configs.
+func (tr *TierRouter) routingConfig() *config.RoutingConfig {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.config
+}
+
+// ReloadConfig swaps in new tier definitions without restarting the agent,
+// e.g. to move work off a provider that degrades mid-engagement. Requests
+// already in flight finish on the old config. New or changed tiers must use a
+// model the router has a provider for; the response cache, rate limits, spend
+// governor and supervision keep their startup settings.
+func (tr *TierRouter) ReloadConfig(newCfg *config.RoutingConfig) error {
+	if newCfg == nil {
+		return fmt.Errorf("routing config is nil")
+	}
+	if newCfg.DefaultTier != "" {
+		if _, ok := newCfg.Tiers[newCfg.DefaultTier]; !ok {
+			return fmt.Errorf("default tier %q is not defined", newCfg.DefaultTier)
+		}
+	}
+	current := tr.routingConfig()
+	for name, tierCfg := range newCfg.Tiers {
+		if current != nil && current.Tiers[name].ModelName == tierCfg.ModelName {
+			continue // Unchanged; already routed to before the reload
+		}
+		if _, ok := tr.provider(tierCfg.ModelName); !ok {
+			return fmt.Errorf("tier %q uses model %q, which has no provider", name, tierCfg.ModelName)
+		}
+	}
+
+	tr.mu.Lock()
+	tr.config = newCfg
+	tr.mu.Unlock()
+
+	if name, tierCfg, ok := mostExpensiveTier(newCfg.Tiers); ok {
+		tr.costs.SetBaseline(name, tierCfg)
+	}
+
+	logger.InfoCF(tr.component, "Routing config reloaded", map[string]any{
+		"tiers":        len(newCfg.Tiers),
+		"default_tier": newCfg.DefaultTier,
+		"enabled":      newCfg.Enabled,
+	})
+	return nil
+}
+
+// SetDefaultTier switches the tier used for tasks no tier claims.
+func (tr *TierRouter) SetDefaultTier(tierName string) error {
+	current := tr.routingConfig()
+	if current == nil {
+		return fmt.Errorf("tier routing is not configured")
+	}
+	updated := *current
+	updated.DefaultTier = tierName
+	return tr.ReloadConfig(&updated)
+}
+
+// DefaultTier returns the name of the current default tier.
+func (tr *TierRouter) DefaultTier() string {
+	if routingCfg := tr.routingConfig(); routingCfg != nil {
+		return routingCfg.DefaultTier
+	}
+	return ""
+}
+
+// TierNames returns the configured tier names, sorted.
+func (tr *TierRouter) TierNames() []string {
+	routingCfg := tr.routingConfig()
+	if routingCfg == nil {
+		return nil
+	}
+	names := make([]string, 0, len(routingCfg.Tiers))
+	for name := range routingCfg.Tiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,213 @@
+package routing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+const (
+	defaultResponseCacheTTL        = 10 * time.Minute
+	defaultResponseCacheMaxEntries = 256
+)
+
+// ResponseCache caches routed responses for deterministic requests, keyed on
+// the model that answered, the messages, the tool definitions and every
+// option that shapes the answer. Only temperature 0 requests are cacheable;
+// anything sampled could legitimately differ.
+// Thread-safe for concurrent access.
+type ResponseCache struct {
+	mu         sync.Mutex
+	entries    map[string]*responseCacheEntry
+	order      []string // LRU order: oldest first.
+	maxEntries int
+	ttl        time.Duration
+	hits       int
+	misses     int
+}
+
+type responseCacheEntry struct {
+	resp      providers.LLMResponse
+	createdAt time.Time
+}
+
+// NewResponseCache creates a response cache. Non-positive limits use defaults.
+func NewResponseCache(maxEntries int, ttl time.Duration) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheMaxEntries
+	}
+	if ttl <= 0 {
+		ttl = defaultResponseCacheTTL
+	}
+	return &ResponseCache{
+		entries:    make(map[string]*responseCacheEntry),
+		order:      make([]string, 0),
+		maxEntries: maxEntries,
+		ttl:        ttl,
+	}
+}
+
+// responseCacheIgnoredOptions don't change what the model answers
+var responseCacheIgnoredOptions = map[string]bool{
+	"prompt_cache_key": true, // Per conversation; only steers provider-side prompt caching
+}
+
+// responseCacheKey returns the cache key for a request, or "" when the
+// request is not cacheable (non-zero or missing temperature, or options
+// that can't be hashed). All options count except
+// responseCacheIgnoredOptions, so max_tokens, response_format, tool_choice
+// and reasoning settings each get their own entry.
+func responseCacheKey(model string, messages []providers.Message, tools []providers.ToolDefinition, options map[string]any) string {
+	if !isZeroTemperature(options["temperature"]) {
+		return ""
+	}
+
+	shaping := make(map[string]any, len(options))
+	for k, v := range options {
+		if !responseCacheIgnoredOptions[k] {
+			shaping[k] = v
+		}
+	}
+	// Map keys are marshaled in sorted order, so equal options hash equally
+	optionsJSON, err := json.Marshal(shaping)
+	if err != nil {
+		return ""
+	}
+
+	msgJSON, err := json.Marshal(messages)
+	if err != nil {
+		return ""
+	}
+	toolsJSON, err := json.Marshal(tools)
+	if err != nil {
+		return ""
+	}
+	msgHash := sha256.Sum256(msgJSON)
+	toolsHash := sha256.Sum256(toolsJSON)
+	optionsHash := sha256.Sum256(optionsJSON)
+
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write(msgHash[:])
+	h.Write(toolsHash[:])
+	h.Write(optionsHash[:])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isZeroTemperature(v any) bool {
+	switch t := v.(type) {
+	case float64:
+		return t == 0
+	case float32:
+		return t == 0
+	case int:
+		return t == 0
+	case *float64:
+		return t != nil && *t == 0
+	}
+	return false
+}
+
+// Get returns a copy of the cached response for key.
+func (rc *ResponseCache) Get(key string) (*providers.LLMResponse, bool) {
+	if rc == nil || key == "" {
+		return nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok || time.Since(entry.createdAt) >= rc.ttl {
+		rc.misses++
+		return nil, false
+	}
+	rc.hits++
+	rc.moveToEndLocked(key)
+	return copyResponse(&entry.resp), true
+}
+
+// Put stores a copy of resp under key, evicting the oldest entry at capacity.
+func (rc *ResponseCache) Put(key string, resp *providers.LLMResponse) {
+	if rc == nil || key == "" || resp == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.evictExpiredLocked()
+
+	if _, ok := rc.entries[key]; ok {
+		rc.entries[key] = &responseCacheEntry{resp: *copyResponse(resp), createdAt: time.Now()}
+		rc.moveToEndLocked(key)
+		return
+	}
+
+	for len(rc.entries) >= rc.maxEntries && len(rc.order) > 0 {
+		oldest := rc.order[0]
+		rc.order = rc.order[1:]
+		delete(rc.entries, oldest)
+	}
+
+	rc.entries[key] = &responseCacheEntry{resp: *copyResponse(resp), createdAt: time.Now()}
+	rc.order = append(rc.order, key)
+}
+
+// Stats returns the hit and miss counts.
+func (rc *ResponseCache) Stats() (hits, misses int) {
+	if rc == nil {
+		return 0, 0
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.hits, rc.misses
+}
+
+// Len returns the number of entries (for testing).
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.entries)
+}
+
+func (rc *ResponseCache) evictExpiredLocked() {
+	now := time.Now()
+	newOrder := make([]string, 0, len(rc.order))
+	for _, key := range rc.order {
+		entry, ok := rc.entries[key]
+		if !ok || now.Sub(entry.createdAt) >= rc.ttl {
+			delete(rc.entries, key)
+			continue
+		}
+		newOrder = append(newOrder, key)
+	}
+	rc.order = newOrder
+}
+
+func (rc *ResponseCache) moveToEndLocked(key string) {
+	for i, k := range rc.order {
+		if k == key {
+			rc.order = append(rc.order[:i], rc.order[i+1:]...)
+			break
+		}
+	}
+	rc.order = append(rc.order, key)
+}
+
+// copyResponse isolates cached responses from caller mutation.
+func copyResponse(resp *providers.LLMResponse) *providers.LLMResponse {
+	cp := *resp
+	if resp.ToolCalls != nil {
+		cp.ToolCalls = make([]providers.ToolCall, len(resp.ToolCalls))
+		copy(cp.ToolCalls, resp.ToolCalls)
+	}
+	if resp.Usage != nil {
+		usage := *resp.Usage
+		cp.Usage = &usage
+	}
+	return &cp
+}
@@ -0,0 +1,163 @@
+package routing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
+)
+
+// SpeculativeBudget caps how much each session may spend on speculative
+// supervisor answers. Spend is reserved up front from a pre-flight estimate
+// (full prompt plus the max_tokens output budget), so the cap is never
+// exceeded even when the speculative answer is discarded.
+type SpeculativeBudget struct {
+	mu    sync.Mutex
+	limit float64 // USD per session; 0 = unlimited
+	spent map[string]float64
+}
+
+// NewSpeculativeBudget creates a budget with the given per-session limit.
+func NewSpeculativeBudget(limit float64) *SpeculativeBudget {
+	return &SpeculativeBudget{
+		limit: limit,
+		spent: make(map[string]float64),
+	}
+}
+
+// Reserve books cost against the session's budget. It returns false, booking
+// nothing, when the reservation would exceed the limit.
+func (b *SpeculativeBudget) Reserve(sessionKey string, cost float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit > 0 && b.spent[sessionKey]+cost > b.limit {
+		return false
+	}
+	b.spent[sessionKey] += cost
+	return true
+}
+
+// Spent returns the estimated speculative spend reserved for a session.
+func (b *SpeculativeBudget) Spent(sessionKey string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent[sessionKey]
+}
+
+// speculation is a supervisor answer generated in parallel with the worker.
+type speculation struct {
+	model  string
+	done   chan struct{}
+	resp   *providers.LLMResponse
+	err    error
+	cancel context.CancelFunc
+}
+
+// wait blocks until the speculative answer is available.
+func (s *speculation) wait() (*providers.LLMResponse, error) {
+	<-s.done
+	return s.resp, s.err
+}
+
+// discard abandons the speculative request once the worker's output passed.
+func (s *speculation) discard() {
+	s.cancel()
+}
+
+// startSpeculation has the supervisor answer a high-stakes task itself while
+// the worker runs, so a failed validation can fall back to it without a
+// second round-trip. Returns nil when speculation is disabled, the task is
+// not high-stakes, or the session's speculative budget is exhausted.
+func (sr *SupervisionRouter) startSpeculation(
+	ctx context.Context,
+	taskType TaskType,
+	workerModel string,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) *speculation {
+	if sr.speculative == nil || !sr.isHighStakesTask(taskType) {
+		return nil
+	}
+	supervisorModel := sr.tierRouter.selectSupervisorModel()
+	if supervisorModel == workerModel {
+		return nil
+	}
+	_, tierCfg, err := sr.tierRouter.getTierForModel(supervisorModel)
+	if err != nil {
+		return nil
+	}
+
+	model := sr.tierRouter.modelID(supervisorModel)
+	prompt := tokens.EstimateMessages(model, messages) + tokens.EstimateTools(model, tools)
+	maxOutput, _ := options["max_tokens"].(int)
+	estimate := EstimateCallCost(*tierCfg, prompt, maxOutput)
+	if !sr.speculative.Reserve(sessionKey, estimate) {
+		logger.DebugCF(sr.component, "Speculative supervisor budget exhausted", map[string]any{
+			"task":          taskType,
+			"estimate":      estimate,
+			"session_spent": sr.speculative.Spent(sessionKey),
+		})
+		return nil
+	}
+
+	specCtx, cancel := context.WithCancel(ctx)
+	spec := &speculation{
+		model:  supervisorModel,
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go func() {
+		defer close(spec.done)
+		spec.resp, spec.err = sr.routeToModel(specCtx, supervisorModel, supervisorModel, messages, tools, options, sessionKey)
+	}()
+
+	logger.DebugCF(sr.component, "Started speculative supervisor answer", map[string]any{
+		"task":     taskType,
+		"model":    supervisorModel,
+		"estimate": estimate,
+	})
+	return spec
+}
+
+// useSpeculation returns the supervisor's parallel answer in place of a worker
+// output that failed or was rejected. If the speculative call failed too,
+// the original worker error is returned.
+func (sr *SupervisionRouter) useSpeculation(
+	spec *speculation,
+	taskType TaskType,
+	workerModel string,
+	workerErr error,
+) (*SupervisionResult, error) {
+	resp, err := spec.wait()
+	if err != nil {
+		logger.WarnCF(sr.component, "Speculative supervisor answer failed", map[string]any{
+			"task":  taskType,
+			"model": spec.model,
+			"error": err.Error(),
+		})
+		return nil, workerErr
+	}
+
+	logger.InfoCF(sr.component, "Using speculative supervisor answer", map[string]any{
+		"task":         taskType,
+		"worker_model": workerModel,
+		"model":        spec.model,
+		"reason":       workerErr.Error(),
+	})
+	return &SupervisionResult{
+		OriginalTask:         taskType,
+		SupervisorTask:       TaskSupervision,
+		Validated:            true,
+		FinalOutput:          resp.Content,
+		SupervisorModel:      spec.model,
+		WorkerModel:          workerModel,
+		ValidationScore:      1.0,
+		SupervisorConfidence: 1.0,
+		Speculative:          true,
+	}, nil
+}
@@ -0,0 +1,217 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// ErrSpendBudgetExhausted is returned when the spend governor would hold a
+// request back longer than routing.spend_governor.max_wait_seconds.
+var ErrSpendBudgetExhausted = errors.New("provider spend budget exhausted")
+
+const (
+	spendWindow         = 24 * time.Hour
+	defaultSpendMaxWait = 60 * time.Second
+	spendPruneInterval  = time.Hour
+)
+
+// SpendGovernor paces routed provider spend with token buckets denominated
+// in USD. One bucket is shared by every session and refills at the daily
+// budget spread evenly over 24 hours, holding at most the burst allowance;
+// with a session share set, each session also draws from its own bucket of
+// that share of the daily budget. Requests reserve their estimated cost up
+// front and queue, in arrival order, while a bucket is in deficit.
+type SpendGovernor struct {
+	mu         sync.Mutex // Guards sessions and lastPrune
+	global     *tokenBucket
+	sessionCap float64 // USD per session bucket; 0 = no per-session cap
+	sessions   map[string]*tokenBucket
+	lastPrune  time.Time
+	maxWait    time.Duration
+	component  string
+}
+
+// NewSpendGovernor builds a governor from the config. It returns nil when no
+// daily budget is set; a nil governor never waits.
+func NewSpendGovernor(cfg config.SpendGovernorConfig) *SpendGovernor {
+	if cfg.DailyBudget <= 0 {
+		return nil
+	}
+	now := time.Now()
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.DailyBudget / 24
+	}
+	g := &SpendGovernor{
+		global:    newSpendBucket(burst, cfg.DailyBudget, now),
+		sessions:  make(map[string]*tokenBucket),
+		lastPrune: now,
+		maxWait:   time.Duration(cfg.MaxWaitSeconds) * time.Second,
+		component: "spend-governor",
+	}
+	if cfg.SessionShare > 0 {
+		g.sessionCap = math.Min(cfg.SessionShare, 1) * cfg.DailyBudget
+	}
+	if g.maxWait <= 0 {
+		g.maxWait = defaultSpendMaxWait
+	}
+	return g
+}
+
+// newSpendBucket returns a full bucket of capacity USD that refills at
+// daily USD per spendWindow.
+func newSpendBucket(capacity, daily float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: capacity,
+		tokens:   capacity,
+		perSec:   daily / spendWindow.Seconds(),
+		last:     now,
+	}
+}
+
+// full reports whether the bucket has refilled to capacity by now.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// spendReservation records what a request took from the spend buckets so
+// the estimate can be settled against the actual cost.
+type spendReservation struct {
+	buckets  []*tokenBucket
+	reserved []float64
+}
+
+// settle corrects the spend buckets from the estimate to the actual cost.
+func (r *spendReservation) settle(actual float64) {
+	if r == nil {
+		return
+	}
+	for i, b := range r.buckets {
+		b.refund(r.reserved[i] - math.Min(actual, b.capacity))
+	}
+}
+
+// release returns the whole reservation, for requests that were never sent.
+func (r *spendReservation) release() {
+	r.settle(0)
+}
+
+// session returns the session's bucket, creating it full. Buckets that have
+// refilled completely carry no state and are dropped periodically so
+// long-running gateways do not accumulate one per chat ever seen.
+func (g *SpendGovernor) session(sessionKey string, now time.Time) *tokenBucket {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now.Sub(g.lastPrune) >= spendPruneInterval {
+		for key, b := range g.sessions {
+			if b.full(now) {
+				delete(g.sessions, key)
+			}
+		}
+		g.lastPrune = now
+	}
+
+	b, ok := g.sessions[sessionKey]
+	if !ok {
+		b = newSpendBucket(g.sessionCap, g.sessionCap, now)
+		g.sessions[sessionKey] = b
+	}
+	return b
+}
+
+// reserve books cost against the shared bucket and, when capped, the
+// session's, returning the reservation and how long the caller must wait.
+// A wait beyond maxWait books nothing and returns ErrSpendBudgetExhausted.
+func (g *SpendGovernor) reserve(sessionKey string, cost float64, now time.Time) (*spendReservation, time.Duration, error) {
+	res := &spendReservation{}
+	var delay time.Duration
+	var limiting string
+
+	buckets := []*tokenBucket{g.global}
+	if g.sessionCap > 0 {
+		buckets = append(buckets, g.session(sessionKey, now))
+	}
+	for i, b := range buckets {
+		n, wait := b.reserve(cost, now)
+		res.buckets = append(res.buckets, b)
+		res.reserved = append(res.reserved, n)
+		if wait > delay {
+			delay = wait
+			limiting = "shared"
+			if i > 0 {
+				limiting = "session"
+			}
+		}
+	}
+
+	if delay > g.maxWait {
+		res.release()
+		return nil, delay, fmt.Errorf("%w: the %s budget frees $%.4f in %s (max wait %s)",
+			ErrSpendBudgetExhausted, limiting, cost, delay.Round(time.Second), g.maxWait)
+	}
+	return res, delay, nil
+}
+
+// Wait blocks until a request of estimated cost (USD) may be sent for the
+// session, queueing behind earlier callers. It fails fast with
+// ErrSpendBudgetExhausted when the wait would exceed max_wait_seconds, and
+// returns ctx's error, refunding the reservation, if ctx ends first.
+func (g *SpendGovernor) Wait(ctx context.Context, sessionKey string, cost float64) (*spendReservation, error) {
+	if g == nil || cost <= 0 {
+		return nil, nil
+	}
+
+	res, delay, err := g.reserve(sessionKey, cost, time.Now())
+	if err != nil {
+		logger.WarnCF(g.component, "Spend budget exhausted, refusing request", map[string]any{
+			"session": sessionKey,
+			"cost":    cost,
+			"error":   err.Error(),
+		})
+		return nil, err
+	}
+	if delay <= 0 {
+		return res, nil
+	}
+
+	logger.InfoCF(g.component, "Spend budget in deficit, pacing request", map[string]any{
+		"session": sessionKey,
+		"cost":    cost,
+		"wait":    delay.String(),
+	})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return res, nil
+	case <-ctx.Done():
+		res.release()
+		return nil, ctx.Err()
+	}
+}
+
+// Available returns the USD the shared bucket can admit right now without
+// pacing; negative while requests are queued behind a deficit.
+func (g *SpendGovernor) Available() float64 {
+	if g == nil {
+		return 0
+	}
+	g.global.mu.Lock()
+	defer g.global.mu.Unlock()
+
+	g.global.refill(time.Now())
+	return g.global.tokens
+}
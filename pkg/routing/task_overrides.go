@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// applyTaskOverrides applies the configured per-task-type overrides to a
+// request. Neither messages, tools nor options are modified in place.
+func (tr *TierRouter) applyTaskOverrides(
+	taskType TaskType,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+) ([]providers.Message, []providers.ToolDefinition, map[string]any) {
+	override, ok := tr.routingConfig().TaskOverrides[string(taskType)]
+	if !ok {
+		return messages, tools, options
+	}
+
+	if override.Temperature != nil || override.MaxTokens > 0 || override.ReasoningEffort != "" || override.ThinkingBudget != 0 {
+		merged := make(map[string]any, len(options)+4)
+		maps.Copy(merged, options)
+		if override.Temperature != nil {
+			merged["temperature"] = *override.Temperature
+		}
+		if override.MaxTokens > 0 {
+			merged["max_tokens"] = override.MaxTokens
+		}
+		// Set here, these take precedence over the tier's (see withTierReasoning)
+		if override.ReasoningEffort != "" {
+			merged["reasoning_effort"] = override.ReasoningEffort
+		}
+		if override.ThinkingBudget != 0 {
+			merged["thinking_budget"] = override.ThinkingBudget
+		}
+		options = merged
+	}
+
+	if len(override.Tools) > 0 && len(tools) > 0 {
+		pruned := pruneTools(tools, override.Tools, messages)
+		if len(pruned) < len(tools) {
+			logger.DebugCF(tr.component, "Pruned tool definitions for task", map[string]any{
+				"task":    taskType,
+				"offered": len(pruned),
+				"dropped": len(tools) - len(pruned),
+			})
+		}
+		tools = pruned
+	}
+
+	return providers.WithSystemAddendum(messages, override.SystemPrompt), tools, options
+}
+
+// pruneTools keeps the tools that match an allow entry: a tool name or a
+// prefix ending in *. The workflow_* tools are always kept so a mission can
+// make progress. If nothing else is left while the conversation already has
+// tool calls, the tools it called are kept too, since some providers reject
+// tool calls in the history when no tools are defined.
+func pruneTools(
+	tools []providers.ToolDefinition,
+	allow []string,
+	messages []providers.Message,
+) []providers.ToolDefinition {
+	keep := func(name string) bool {
+		if strings.HasPrefix(name, "workflow_") {
+			return true
+		}
+		for _, entry := range allow {
+			entry = strings.TrimSpace(entry)
+			if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+				if strings.HasPrefix(name, prefix) {
+					return true
+				}
+			} else if entry == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	pruned := make([]providers.ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		if keep(tool.Function.Name) {
+			pruned = append(pruned, tool)
+		}
+	}
+	if len(pruned) > 0 {
+		return pruned
+	}
+
+	called := make(map[string]bool)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			called[providers.NormalizeToolCall(tc).Name] = true
+		}
+	}
+	for _, tool := range tools {
+		if called[tool.Function.Name] {
+			pruned = append(pruned, tool)
+		}
+	}
+	return pruned
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/pipeline"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tokens"
 )
 
 // SupervisionRouter handles hierarchical oversight where powerful models supervise lighter models
@@ -17,6 +22,9 @@ type SupervisionRouter struct {
 	tierRouter  *TierRouter
 	validator   *TaskValidator
 	costTracker *CostTracker
+	breaker     *RejectionBreaker  // Escalates task types the worker keeps failing
+	speculative *SpeculativeBudget // Non-nil when speculative supervisor answers are enabled
+	reviewer    CorrectionReviewer // Optional operator approval of corrections
 	component   string
 }
 
@@ -46,6 +54,9 @@ type SupervisionResult struct {
 	WorkerModel          string
 	ValidationScore      float64
 	SupervisorConfidence float64
+	Speculative          bool   // FinalOutput is the supervisor's parallel answer
+	WorkerOutput         string // Worker's original output, set when a correction was reviewed
+	OperatorDecision     string // accepted, rejected, edited, or dismissed; empty when not reviewed
 }
 
 // ValidationDecision represents the parsed validation decision from a supervisor
@@ -74,6 +85,7 @@ const (
 	TaskCodeReview    TaskType = "code_review"    // Analyzing JavaScript, code, configs
 	TaskJSAnalysis    TaskType = "js_analysis"    // Specific JavaScript analysis
 	TaskValidation    TaskType = "validation"     // Validating lighter model outputs
+	TaskReportSection TaskType = "report_section" // Drafting routine report sections from findings
 
 	// Lightweight tasks (can use local/lighter models)
 	TaskParsing    TaskType = "parsing"    // Parsing tool output
@@ -99,12 +111,34 @@ type AgentContext struct {
 
 // TierRouter handles task classification and routing to appropriate model tiers
 type TierRouter struct {
+	mu         sync.RWMutex // Guards config, which ReloadConfig swaps
 	config     *config.RoutingConfig
 	modelList  []config.ModelConfig
-	providers  map[string]providers.LLMProvider
+	providers  map[string]providers.LLMProvider // Guarded by mu; see provider and SetTierModel
 	costs      *CostTracker
 	component  string             // Component name for logging
 	supervisor *SupervisionRouter // Hierarchical oversight routing
+
+	// preambleFunc optionally returns a system prompt preamble for the
+	// session and the model actually selected, so provider-specific framing
+	// follows the request through tier selection and rerouting.
+	preambleFunc func(sessionKey, modelName string) string
+
+	responseCache   *ResponseCache                // nil unless routing.response_cache is enabled
+	toolOutputCache *ResponseCache                // Parses/summaries keyed on tool output hash; nil when responseCache is
+	rateLimiter     *RateLimiter                  // nil unless rpm/tpm limits are configured
+	spendGovernor   *SpendGovernor                // nil unless routing.spend_governor has a daily budget
+	latencies       *LatencyTracker               // Recent latencies per model, for hedging thresholds
+	policies        []RoutingPolicy               // Guarded by mu; see AddPolicy
+	pipelines       map[string]*pipeline.Pipeline // Output pipelines by name, guarded by mu; see SetPipelines
+
+	// Embeddings providers created from model_list on first Embed; guarded by mu
+	embedders map[string]providers.EmbeddingsProvider
+
+	// Hot-swap state, guarded by mu; see SetTierModel
+	newProvider   func(*config.ModelConfig) (providers.LLMProvider, string, error)
+	swapped       map[string]bool // Models whose provider SetTierModel created
+	onModelSwitch func(tierName, modelName string)
 }
 
 // NewTaskValidator creates a new task validator with default rules
@@ -152,6 +186,7 @@ func NewTaskValidator() *TaskValidator {
 			TaskToolSelection: 0.75,
 			TaskCodeReview:    0.7,
 			TaskJSAnalysis:    0.75,
+			TaskReportSection: 0.8,
 			TaskParsing:       0.9,
 			TaskSummary:       0.8,
 			TaskFormatting:    0.95,
@@ -168,12 +203,59 @@ func NewTierRouter(
 	modelList []config.ModelConfig,
 	providerMap map[string]providers.LLMProvider,
 ) *TierRouter {
+	if providerMap == nil {
+		providerMap = make(map[string]providers.LLMProvider)
+	}
 	router := &TierRouter{
-		config:    routingCfg,
-		modelList: modelList,
-		providers: providerMap,
-		costs:     NewCostTracker(),
-		component: "tier-router",
+		config:      routingCfg,
+		modelList:   modelList,
+		providers:   providerMap,
+		costs:       NewCostTracker(),
+		latencies:   NewLatencyTracker(),
+		component:   "tier-router",
+		newProvider: providers.CreateProviderFromConfig,
+		swapped:     make(map[string]bool),
+	}
+
+	if routingCfg != nil {
+		if name, tierCfg, ok := mostExpensiveTier(routingCfg.Tiers); ok {
+			router.costs.SetBaseline(name, tierCfg)
+		}
+	}
+
+	var providerLimits map[string]config.RateLimitConfig
+	if routingCfg != nil {
+		providerLimits = routingCfg.ProviderRateLimits
+	}
+	router.rateLimiter = NewRateLimiter(modelList, providerLimits)
+	if routingCfg != nil {
+		router.spendGovernor = NewSpendGovernor(routingCfg.SpendGovernor)
+	}
+
+	if routingCfg != nil && routingCfg.ResponseCache.Enabled {
+		router.responseCache = NewResponseCache(
+			routingCfg.ResponseCache.MaxEntries,
+			time.Duration(routingCfg.ResponseCache.TTLSeconds)*time.Second,
+		)
+		toolOutputTTL := time.Duration(routingCfg.ResponseCache.ToolOutputTTLSeconds) * time.Second
+		if toolOutputTTL <= 0 {
+			toolOutputTTL = defaultToolOutputCacheTTL
+		}
+		router.toolOutputCache = NewResponseCache(routingCfg.ResponseCache.MaxEntries, toolOutputTTL)
+	}
+
+	if routingCfg != nil {
+		for _, policyCfg := range routingCfg.Policies {
+			policy, err := NewExecPolicy(policyCfg)
+			if err != nil {
+				logger.ErrorCF(router.component, "Skipping invalid routing policy", map[string]any{
+					"policy": policyCfg.Name,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			router.policies = append(router.policies, policy)
+		}
 	}
 
 	// Initialize supervision router if hierarchical routing is enabled
@@ -182,8 +264,12 @@ func NewTierRouter(
 			tierRouter:  router,
 			validator:   NewTaskValidator(),
 			costTracker: router.costs,
+			breaker:     NewRejectionBreaker(routingCfg.SupervisionRejectionLimit),
 			component:   "supervision-router",
 		}
+		if routingCfg.Speculative.Enabled {
+			router.supervisor.speculative = NewSpeculativeBudget(routingCfg.Speculative.MaxExtraCost)
+		}
 		// Set validation confidence threshold if specified
 		if routingCfg.ValidationConfidenceThreshold > 0 {
 			for i := range router.supervisor.validator.rules {
@@ -195,9 +281,63 @@ func NewTierRouter(
 	return router
 }
 
+// mostExpensiveTier returns the tier with the highest combined per-million
+// price, used as the "everything on the most powerful model" baseline for
+// savings reports. Ties resolve to the alphabetically first tier.
+func mostExpensiveTier(tiers map[string]config.TierConfig) (string, config.TierConfig, bool) {
+	bestName, best, bestPrice := "", config.TierConfig{}, 0.0
+	for name, tierCfg := range tiers {
+		price := tierCfg.CostPerM.Input + tierCfg.CostPerM.Output
+		if price > bestPrice || (price == bestPrice && price > 0 && name < bestName) {
+			bestName, best, bestPrice = name, tierCfg, price
+		}
+	}
+	return bestName, best, bestName != ""
+}
+
+// Tool output sizes (in estimated tokens) above which the next turn is
+// treated as parsing, and above which it is treated as summarization.
+const (
+	parsingOutputTokens = 800
+	summaryOutputTokens = 4000
+)
+
+// complexityModifiers adjust the estimated task complexity when a keyword
+// appears in the user message.
+var complexityModifiers = map[string]int{
+	"deep": 2, "thorough": 2, "comprehensive": 3,
+	"quick": -1, "simple": -1, "basic": -2,
+	"exploit": 3, "vulnerability": 3, "security": 2,
+	"analyze": 1, "review": 1, "test": 1,
+}
+
+// keywordRule maps user message keywords to a task type. Rules are
+// evaluated in order and the first match wins.
+type keywordRule struct {
+	name       string
+	keywords   []string
+	task       TaskType
+	confidence float64
+	supervise  bool // always require supervision
+}
+
+var keywordRules = []keywordRule{
+	{name: "analysis_keywords", keywords: []string{"analyze", "examine"}, task: TaskAnalysis, confidence: 0.7},
+	{name: "exploitation_keywords", keywords: []string{"test", "exploit", "vulnerability"}, task: TaskExploitation, confidence: 0.6, supervise: true},
+	{name: "js_keywords", keywords: []string{"javascript", "js file"}, task: TaskJSAnalysis, confidence: 0.75},
+	{name: "code_review_keywords", keywords: []string{"code", "review"}, task: TaskCodeReview, confidence: 0.7},
+	{name: "tool_selection_keywords", keywords: []string{"which tool", "what command"}, task: TaskToolSelection, confidence: 0.8},
+}
+
 // ClassifyTask determines the task type from the current agent context
-// Uses rule-based classification (fast, deterministic, zero-cost)
 func (tr *TierRouter) ClassifyTask(ctx AgentContext) TaskType {
+	return tr.classify(ctx).Task
+}
+
+// classify evaluates every classification rule against ctx. The first
+// matching rule decides the task; later matches are kept as the
+// alternatives ExplainRoute reports.
+func (tr *TierRouter) classify(ctx AgentContext) *RouteExplanation {
 	// Initialize default values
 	if ctx.ConfidenceScore == 0 {
 		ctx.ConfidenceScore = 0.5
@@ -206,90 +346,81 @@ func (tr *TierRouter) ClassifyTask(ctx AgentContext) TaskType {
 		ctx.TaskComplexity = 5 // Medium complexity by default
 	}
 
+	ex := &RouteExplanation{}
+	match := func(rule string, task TaskType, complexity int, confidence float64, supervise bool) {
+		ex.MatchedRules = append(ex.MatchedRules, RuleMatch{Rule: rule, Task: task})
+		if len(ex.MatchedRules) == 1 {
+			ex.Rule, ex.Task = rule, task
+			ex.Complexity, ex.Confidence, ex.RequiresSupervision = complexity, confidence, supervise
+		}
+	}
+
 	// Explicit report request
 	if ctx.ReportRequested {
-		return TaskReportWriting
+		match("report_requested", TaskReportWriting, ctx.TaskComplexity, ctx.ConfidenceScore, ctx.RequiresSupervision)
 	}
 
-	// Start of session or phase change = planning
+	// Start of session or phase change = planning (high complexity)
 	if ctx.TurnCount == 0 || ctx.SessionStarted || ctx.PhaseChanged {
-		ctx.TaskComplexity = 8 // High complexity for planning
-		return TaskPlanning
+		match("session_start", TaskPlanning, 8, ctx.ConfidenceScore, ctx.RequiresSupervision)
 	}
 
 	// Large tool output = parsing/summarizing
-	if len(ctx.LastToolOutput) > 2000 {
-		if len(ctx.LastToolOutput) > 10000 {
-			ctx.TaskComplexity = 7 // High complexity for large summaries
-			return TaskSummary
+	if outputTokens := tokens.Estimate("", ctx.LastToolOutput); outputTokens > parsingOutputTokens {
+		if outputTokens > summaryOutputTokens {
+			match("large_tool_output", TaskSummary, 7, ctx.ConfidenceScore, ctx.RequiresSupervision)
+		} else {
+			match("tool_output", TaskParsing, 4, ctx.ConfidenceScore, ctx.RequiresSupervision)
 		}
-		ctx.TaskComplexity = 4 // Medium complexity for parsing
-		return TaskParsing
 	}
 
 	// Keywords in user message - enhanced with complexity scoring
+	// (applied in keyword order so clamping is deterministic)
 	userLower := strings.ToLower(ctx.UserMessage)
-	complexityModifiers := map[string]int{
-		"deep": 2, "thorough": 2, "comprehensive": 3,
-		"quick": -1, "simple": -1, "basic": -2,
-		"exploit": 3, "vulnerability": 3, "security": 2,
-		"analyze": 1, "review": 1, "test": 1,
+	keywords := make([]string, 0, len(complexityModifiers))
+	for keyword := range complexityModifiers {
+		keywords = append(keywords, keyword)
 	}
-
-	// Calculate complexity from keywords
-	for keyword, modifier := range complexityModifiers {
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
 		if strings.Contains(userLower, keyword) {
-			ctx.TaskComplexity += modifier
+			modifier := complexityModifiers[keyword]
+			ex.KeywordHits = append(ex.KeywordHits, KeywordHit{Keyword: keyword, Modifier: modifier})
 			// Clamp complexity between 1-10
-			if ctx.TaskComplexity < 1 {
-				ctx.TaskComplexity = 1
-			} else if ctx.TaskComplexity > 10 {
-				ctx.TaskComplexity = 10
-			}
+			ctx.TaskComplexity = max(1, min(10, ctx.TaskComplexity+modifier))
 		}
 	}
 
 	// Determine if supervision is needed
-	ctx.RequiresSupervision = tr.requiresSupervision(ctx)
+	supervise := tr.requiresSupervision(ctx)
 
-	if strings.Contains(userLower, "analyze") || strings.Contains(userLower, "examine") {
-		ctx.ConfidenceScore = 0.7
-		return TaskAnalysis
-	}
-	if strings.Contains(userLower, "test") || strings.Contains(userLower, "exploit") || strings.Contains(userLower, "vulnerability") {
-		ctx.ConfidenceScore = 0.6
-		ctx.RequiresSupervision = true
-		return TaskExploitation
-	}
-	if strings.Contains(userLower, "javascript") || strings.Contains(userLower, "js file") {
-		ctx.ConfidenceScore = 0.75
-		return TaskJSAnalysis
-	}
-	if strings.Contains(userLower, "code") || strings.Contains(userLower, "review") {
-		ctx.ConfidenceScore = 0.7
-		return TaskCodeReview
-	}
-	if strings.Contains(userLower, "which tool") || strings.Contains(userLower, "what command") {
-		ctx.ConfidenceScore = 0.8
-		return TaskToolSelection
+	for _, rule := range keywordRules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(userLower, keyword) {
+				match(rule.name, rule.task, ctx.TaskComplexity, rule.confidence, supervise || rule.supervise)
+				break
+			}
+		}
 	}
 
 	// Default: analysis for reasoning tasks
-	ctx.ConfidenceScore = 0.6
-	return TaskAnalysis
+	match("default", TaskAnalysis, ctx.TaskComplexity, 0.6, supervise)
+
+	return ex
 }
 
 // requiresSupervision determines if a task needs supervision based on context
 func (tr *TierRouter) requiresSupervision(ctx AgentContext) bool {
 	// Check if supervision is enabled in config
-	if tr.config == nil || !tr.config.EnableSupervision {
+	routingCfg := tr.routingConfig()
+	if routingCfg == nil || !routingCfg.EnableSupervision {
 		return false
 	}
 
 	// Use configured minimum complexity if available
 	minComplexity := 7 // Default
-	if tr.config.MinTaskComplexityForSupervision > 0 {
-		minComplexity = tr.config.MinTaskComplexityForSupervision
+	if routingCfg.MinTaskComplexityForSupervision > 0 {
+		minComplexity = routingCfg.MinTaskComplexityForSupervision
 	}
 
 	// High complexity tasks always need supervision
@@ -321,18 +452,19 @@ func (tr *TierRouter) requiresSupervision(ctx AgentContext) bool {
 
 // SelectTier returns the tier configuration for a given task type
 func (tr *TierRouter) SelectTier(taskType TaskType) (string, *config.TierConfig, error) {
-	if !tr.config.Enabled {
+	routingCfg := tr.routingConfig()
+	if !routingCfg.Enabled {
 		// Routing disabled, use default tier
-		if tr.config.DefaultTier != "" {
-			if tier, ok := tr.config.Tiers[tr.config.DefaultTier]; ok {
-				return tr.config.DefaultTier, &tier, nil
+		if routingCfg.DefaultTier != "" {
+			if tier, ok := routingCfg.Tiers[routingCfg.DefaultTier]; ok {
+				return routingCfg.DefaultTier, &tier, nil
 			}
 		}
 		return "", nil, fmt.Errorf("routing disabled and no valid default tier")
 	}
 
 	// Find tier that handles this task type
-	for tierName, tierCfg := range tr.config.Tiers {
+	for tierName, tierCfg := range routingCfg.Tiers {
 		if strings.EqualFold(tierName, string(taskType)) {
 			return tierName, &tierCfg, nil
 		}
@@ -344,19 +476,23 @@ func (tr *TierRouter) SelectTier(taskType TaskType) (string, *config.TierConfig,
 	}
 
 	// Fallback to default tier
-	if tr.config.DefaultTier != "" && isKnownTaskType(taskType) {
-		if tier, ok := tr.config.Tiers[tr.config.DefaultTier]; ok {
+	if routingCfg.DefaultTier != "" && isKnownTaskType(taskType) {
+		if tier, ok := routingCfg.Tiers[routingCfg.DefaultTier]; ok {
 			logger.DebugCF(tr.component, "No tier found for task type, using default", map[string]any{
 				"task": taskType,
-				"tier": tr.config.DefaultTier,
+				"tier": routingCfg.DefaultTier,
 			})
-			return tr.config.DefaultTier, &tier, nil
+			return routingCfg.DefaultTier, &tier, nil
 		}
 	}
 
 	return "", nil, fmt.Errorf("no tier found for task type %s and no valid default tier", taskType)
 }
 
+// routedCacheKeyPrefix prefixes the prompt_cache_key given to routed requests
+// whose caller did not set one
+const routedCacheKeyPrefix = "routed"
+
 // RouteChat executes an LLM chat request with tier-based routing
 func (tr *TierRouter) RouteChat(
 	ctx context.Context,
@@ -366,12 +502,32 @@ func (tr *TierRouter) RouteChat(
 	options map[string]any,
 	sessionKey string,
 ) (*providers.LLMResponse, error) {
+	ctx = withCostTask(ctx, taskType)
+	options = providers.WithPromptCacheKey(options, providers.PromptCacheKey(routedCacheKeyPrefix, sessionKey))
 	tierName, tierCfg, err := tr.SelectTier(taskType)
 	if err != nil {
 		return nil, fmt.Errorf("tier selection failed: %w", err)
 	}
 
-	provider, ok := tr.providers[tierCfg.ModelName]
+	policyReq, tierName, tierCfg, err := tr.applyPolicies(ctx, taskType, tierName, tierCfg, messages, tools, options, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, tools, options = tr.applyTaskOverrides(taskType, messages, tools, options)
+
+	enforced := tr.toolEnforced(taskType, tools)
+	if enforced {
+		options = withToolEnforcement(options, tr.routingConfig().ToolEnforcement)
+	}
+
+	tierName, tierCfg, err = tr.fitContext(taskType, tierName, tierCfg, messages, tools, options)
+	if err != nil {
+		return nil, err
+	}
+	options = withTierReasoning(options, tierCfg)
+
+	provider, ok := tr.provider(tierCfg.ModelName)
 	if !ok {
 		return nil, fmt.Errorf("provider not found for model %s", tierCfg.ModelName)
 	}
@@ -382,9 +538,25 @@ func (tr *TierRouter) RouteChat(
 		"model": tierCfg.ModelName,
 	})
 
-	start := time.Now()
-	resp, err := provider.Chat(ctx, messages, tools, tierCfg.ModelName, options)
-	elapsed := time.Since(start)
+	cached, cache, cacheKey, ok := tr.cachedResponse(ctx, taskType, tierCfg, messages, tools, options, sessionKey)
+	if ok {
+		toolOutput := cache == tr.toolOutputCache
+		saved := 0.0
+		if cached.Usage != nil {
+			saved = EstimateUsageCost(*tierCfg, *cached.Usage)
+		}
+		tr.costs.RecordCacheHit(sessionKey, toolOutput, saved)
+		logger.InfoCF(tr.component, "Response cache hit", map[string]any{
+			"task":        taskType,
+			"tier":        tierName,
+			"model":       tierCfg.ModelName,
+			"cached":      true,
+			"tool_output": toolOutput,
+		})
+		return tr.applyPipeline(ctx, taskType, cached)
+	}
+
+	resp, elapsed, tierName, tierCfg, err := tr.chatWithHedge(ctx, provider, tierName, tierCfg, messages, tools, options, sessionKey)
 
 	if err != nil {
 		logger.ErrorCF(tr.component, "Tier routing chat failed", map[string]any{
@@ -393,22 +565,62 @@ func (tr *TierRouter) RouteChat(
 			"model": tierCfg.ModelName,
 			"error": err.Error(),
 		})
+		policyReq.Tier, policyReq.Model = tierName, tierCfg.ModelName
+		tr.policiesOnError(ctx, policyReq, err)
 		return nil, err
 	}
 
+	resp.Model = tierCfg.ModelName
+
 	// Track cost
-	tr.costs.Record(sessionKey, tierCfg.ModelName, tierName, *tierCfg, *resp.Usage, elapsed)
+	tr.costs.Record(sessionKey, tierCfg.ModelName, tierName, *tierCfg, responseUsage(resp), elapsed, costAttributionFrom(ctx))
 
 	logger.DebugCF(tr.component, "Tier routing chat complete", map[string]any{
 		"task":          taskType,
 		"tier":          tierName,
 		"model":         tierCfg.ModelName,
-		"input_tokens":  resp.Usage.PromptTokens,
-		"output_tokens": resp.Usage.CompletionTokens,
+		"input_tokens":  responseUsage(resp).PromptTokens,
+		"output_tokens": responseUsage(resp).CompletionTokens,
 		"latency":       elapsed.String(),
 	})
 
-	return resp, nil
+	if providers.IsRefusal(resp) {
+		resp = tr.handleRefusal(ctx, taskType, tierCfg.ModelName, resp, messages, tools, options, sessionKey)
+	}
+	complied := true
+	if enforced && !resp.Refused {
+		resp, complied = tr.enforceToolCall(ctx, taskType, provider, tierName, tierCfg, messages, tools, options, sessionKey, resp)
+	}
+	if !resp.Refused && complied {
+		if tr.responseCache != nil && cache == tr.responseCache {
+			// A hedge or refusal retry may have answered on another model
+			cacheKey = tr.responseKey(resp.Model, messages, tools, options, sessionKey)
+		}
+		cache.Put(cacheKey, resp)
+	}
+
+	policyReq.Tier, policyReq.Model = tierName, tierCfg.ModelName
+	tr.policiesPostRoute(ctx, policyReq, resp)
+	return tr.applyPipeline(ctx, taskType, resp)
+}
+
+// SetPreambleFunc sets a function that supplies a system prompt preamble for
+// the model a request is finally routed to.
+func (tr *TierRouter) SetPreambleFunc(fn func(sessionKey, modelName string) string) {
+	tr.preambleFunc = fn
+}
+
+// withPreamble applies the preamble for modelName to messages, if any.
+func (tr *TierRouter) withPreamble(sessionKey, modelName string, messages []providers.Message) []providers.Message {
+	if tr.preambleFunc == nil {
+		return messages
+	}
+	return providers.WithSystemPreamble(messages, tr.preambleFunc(sessionKey, modelName))
+}
+
+// GetResponseCache returns the response cache, or nil when caching is disabled
+func (tr *TierRouter) GetResponseCache() *ResponseCache {
+	return tr.responseCache
 }
 
 // GetCostTracker returns the cost tracker for session-level cost reporting
@@ -418,7 +630,8 @@ func (tr *TierRouter) GetCostTracker() *CostTracker {
 
 // IsEnabled returns whether tier routing is enabled
 func (tr *TierRouter) IsEnabled() bool {
-	return tr.config != nil && tr.config.Enabled
+	routingCfg := tr.routingConfig()
+	return routingCfg != nil && routingCfg.Enabled
 }
 
 // RouteWithSupervision executes a task with hierarchical oversight
@@ -432,6 +645,8 @@ func (tr *TierRouter) RouteWithSupervision(
 	sessionKey string,
 	agentCtx AgentContext,
 ) (*SupervisionResult, error) {
+	ctx = withCostTask(ctx, taskType)
+	options = providers.WithPromptCacheKey(options, providers.PromptCacheKey(routedCacheKeyPrefix, sessionKey))
 	if tr.supervisor == nil {
 		// Fallback to regular routing if supervision is disabled
 		resp, err := tr.RouteChat(ctx, taskType, messages, tools, options, sessionKey)
@@ -480,18 +695,42 @@ func (sr *SupervisionRouter) ExecuteWithSupervision(
 		}, nil
 	}
 
+	messages, tools, options = sr.tierRouter.applyTaskOverrides(taskType, messages, tools, options)
+
+	// Task types the worker keeps failing go straight to the supervisor
+	if sr.breaker.IsTripped(sessionKey, taskType) {
+		return sr.executeEscalated(ctx, taskType, messages, tools, options, sessionKey)
+	}
+
 	workerModel := sr.tierRouter.selectWorkerModel(taskType)
+	spec := sr.startSpeculation(ctx, taskType, workerModel, messages, tools, options, sessionKey)
+
 	resp, err := sr.routeToModel(ctx, workerModel, workerModel, messages, tools, options, sessionKey)
 	if err != nil {
+		if spec != nil {
+			return sr.useSpeculation(spec, taskType, workerModel, err)
+		}
 		return nil, err
 	}
 
 	// Now validate with supervisor model
 	supervisionResult, err := sr.validateOutput(ctx, taskType, workerModel, resp, messages, tools, options, sessionKey)
 	if err != nil {
-		return nil, fmt.Errorf("supervision validation failed: %w", err)
+		err = fmt.Errorf("supervision validation failed: %w", err)
+		if spec == nil {
+			return nil, err
+		}
+		result, specErr := sr.useSpeculation(spec, taskType, workerModel, err)
+		if specErr == nil {
+			sr.reviewCorrection(ctx, result, resp.Content)
+		}
+		return result, specErr
+	}
+	if spec != nil {
+		spec.discard()
 	}
 
+	sr.reviewCorrection(ctx, supervisionResult, resp.Content)
 	return supervisionResult, nil
 }
 
@@ -520,11 +759,12 @@ func (sr *SupervisionRouter) validateOutput(
 	var supervisorResp *providers.LLMResponse
 	var err error
 	supervisorModel := sr.tierRouter.selectSupervisorModel()
+	validationOptions, validationTools := sr.validationRequest(supervisorModel, options, tools)
 
 	maxRetries := 2
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		// Route to supervisor model
-		supervisorResp, err = sr.routeToModel(ctx, supervisorModel, supervisorModel, validationMessages, tools, options, sessionKey)
+		supervisorResp, err = sr.routeToModel(ctx, supervisorModel, supervisorModel, validationMessages, validationTools, validationOptions, sessionKey)
 		if err == nil {
 			break // Success, exit retry loop
 		}
@@ -536,8 +776,8 @@ func (sr *SupervisionRouter) validateOutput(
 			"task":        originalTask,
 		})
 
-		if attempt == maxRetries {
-			// All retries failed, use fallback strategy
+		if attempt == maxRetries || providers.IsPermanentError(err) {
+			// Retries exhausted (or pointless for this error), use fallback strategy
 			logger.ErrorCF(sr.component, "All supervisor validation attempts failed, using fallback", map[string]any{
 				"task":        originalTask,
 				"final_error": err.Error(),
@@ -551,7 +791,7 @@ func (sr *SupervisionRouter) validateOutput(
 	}
 
 	// Parse supervisor's decision
-	validationDecision, err := sr.parseValidationDecision(supervisorResp.Content)
+	validationDecision, err := sr.decodeDecision(supervisorModel, supervisorResp.Content)
 	if err != nil {
 		logger.WarnCF(sr.component, "Failed to parse validation decision, using fallback", map[string]any{
 			"error": err.Error(),
@@ -565,6 +805,8 @@ func (sr *SupervisionRouter) validateOutput(
 		validationDecision.FinalOutput = workerResp.Content
 	}
 	if validationDecision.Approved && validationDecision.Confidence >= 0.7 {
+		sr.breaker.RecordApproval(sessionKey, originalTask)
+		sr.costTracker.RecordReview(sessionKey, workerModel, true)
 		sr.costTracker.RecordSupervision(sessionKey, true, false, false, len(validationDecision.Corrections), sr.tierRouter.estimateCallCost(supervisorModel, supervisorResp.Usage), validationDecision.Confidence, sr.tierRouter.estimateSupervisionSavings(workerModel, supervisorModel, workerResp.Usage, supervisorResp.Usage))
 		// Validation successful
 		return &SupervisionResult{
@@ -585,6 +827,14 @@ func (sr *SupervisionRouter) validateOutput(
 			"confidence": validationDecision.Confidence,
 			"task":       originalTask,
 		})
+		sr.costTracker.RecordReview(sessionKey, workerModel, false)
+		if sr.breaker.RecordRejection(sessionKey, originalTask) {
+			logger.WarnCF(sr.component, "Repeated supervisor rejections, escalating task type to supervisor for the rest of the session", map[string]any{
+				"task":         originalTask,
+				"worker_model": workerModel,
+				"session":      sessionKey,
+			})
+		}
 
 		// For high-stakes tasks, we might want to escalate rather than fallback
 		if sr.isHighStakesTask(originalTask) {
@@ -646,12 +896,13 @@ func (sr *SupervisionRouter) validateCorrectedOutput(
 	supervisorModel := sr.tierRouter.selectSupervisorModel()
 	validationPrompt := sr.createValidationPrompt(originalTask, workerResp.Content)
 	validationMessages := append(originalMessages, providers.Message{Role: "user", Content: validationPrompt})
-	supervisorResp, err := sr.routeToModel(ctx, supervisorModel, supervisorModel, validationMessages, tools, options, sessionKey)
+	validationOptions, validationTools := sr.validationRequest(supervisorModel, options, tools)
+	supervisorResp, err := sr.routeToModel(ctx, supervisorModel, supervisorModel, validationMessages, validationTools, validationOptions, sessionKey)
 	if err != nil {
 		sr.recordSupervisionMetrics(sessionKey, false, true, true, len(corrections), 0, 0, 0)
 		return sr.createFallbackResult(originalTask, workerResp, "supervisor_unavailable")
 	}
-	decision, err := sr.parseValidationDecision(supervisorResp.Content)
+	decision, err := sr.decodeDecision(supervisorModel, supervisorResp.Content)
 	if err != nil {
 		decision = &ValidationDecision{
 			Approved:    true,
@@ -672,6 +923,45 @@ func (sr *SupervisionRouter) validateCorrectedOutput(
 	return &SupervisionResult{OriginalTask: originalTask, SupervisorTask: TaskSupervision, Validated: true, Corrections: corrections, FinalOutput: decision.FinalOutput, SupervisorModel: supervisorModel, WorkerModel: workerModel, ValidationScore: decision.Confidence, SupervisorConfidence: decision.Confidence}, nil
 }
 
+// executeEscalated sends a task directly to the supervisor model, bypassing the worker
+func (sr *SupervisionRouter) executeEscalated(
+	ctx context.Context,
+	taskType TaskType,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) (*SupervisionResult, error) {
+	supervisorModel := sr.tierRouter.selectSupervisorModel()
+	logger.DebugCF(sr.component, "Task type escalated, routing directly to supervisor", map[string]any{
+		"task":  taskType,
+		"model": supervisorModel,
+	})
+
+	resp, err := sr.routeToModel(ctx, supervisorModel, supervisorModel, messages, tools, options, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SupervisionResult{
+		OriginalTask:         taskType,
+		SupervisorTask:       TaskSupervision,
+		Validated:            true,
+		FinalOutput:          resp.Content,
+		SupervisorModel:      supervisorModel,
+		WorkerModel:          supervisorModel,
+		ValidationScore:      1.0,
+		SupervisorConfidence: 1.0,
+	}, nil
+}
+
+// EscalatedTaskTypes returns the task types routed straight to the supervisor for a session
+func (tr *TierRouter) EscalatedTaskTypes(sessionKey string) []TaskType {
+	if tr.supervisor == nil {
+		return nil
+	}
+	return tr.supervisor.breaker.Escalated(sessionKey)
+}
+
 // createValidationPrompt creates a prompt for the supervisor to validate worker output
 func (sr *SupervisionRouter) createValidationPrompt(taskType TaskType, workerOutput string) string {
 	return fmt.Sprintf(`Please validate the following %s task output:
@@ -694,16 +984,130 @@ Respond in JSON format:
 }`, taskType, workerOutput)
 }
 
+// validationDecisionSchema is the response schema requested from supervisors
+// that support structured output. Anthropic providers emulate it with a forced tool.
+var validationDecisionSchema = &providers.ResponseSchema{
+	Name:        "validation_decision",
+	Description: "Supervisor verdict on a worker model's output",
+	Strict:      true,
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"approved":     map[string]any{"type": "boolean"},
+			"confidence":   map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+			"corrections":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"final_output": map[string]any{"type": "string"},
+		},
+		"required":             []string{"approved", "confidence", "corrections", "final_output"},
+		"additionalProperties": false,
+	},
+}
+
+// structuredOutputProtocols lists protocols whose endpoints honour json_schema response formats
+var structuredOutputProtocols = map[string]bool{
+	"openai":     true,
+	"anthropic":  true,
+	"openrouter": true,
+	"gemini":     true,
+	"llamacpp":   true, // Enforced with a grammar built from the schema
+}
+
+// supportsStructuredOutput reports whether a model can be asked for schema-constrained JSON.
+// An explicit structured_output setting wins; otherwise it is inferred from the protocol prefix.
+// Bare model identifiers without a protocol are treated as unsupported.
+func (tr *TierRouter) supportsStructuredOutput(modelName string) bool {
+	for _, model := range tr.modelList {
+		if model.ModelName != modelName {
+			continue
+		}
+		if model.StructuredOutput != nil {
+			return *model.StructuredOutput
+		}
+		protocol, _, found := strings.Cut(model.Model, "/")
+		return found && structuredOutputProtocols[strings.ToLower(protocol)]
+	}
+	return false
+}
+
+// supportsVision reports whether a model has vision enabled in model_list.
+func (tr *TierRouter) supportsVision(modelName string) bool {
+	for _, model := range tr.modelList {
+		if model.ModelName == modelName {
+			return model.Vision != nil && *model.Vision
+		}
+	}
+	return false
+}
+
+// validationRequest returns the options and tools for a supervisor validation call.
+// Structured-output models get a response schema and no tools so the reply is pure JSON.
+func (sr *SupervisionRouter) validationRequest(
+	supervisorModel string,
+	options map[string]any,
+	tools []providers.ToolDefinition,
+) (map[string]any, []providers.ToolDefinition) {
+	if !sr.tierRouter.supportsStructuredOutput(supervisorModel) {
+		return options, tools
+	}
+	structured := make(map[string]any, len(options)+1)
+	for k, v := range options {
+		structured[k] = v
+	}
+	structured[providers.ResponseSchemaOption] = validationDecisionSchema
+	return structured, nil
+}
+
+// decodeDecision parses a supervisor reply, validating it against the decision schema when
+// structured output was requested and falling back to brace extraction otherwise
+func (sr *SupervisionRouter) decodeDecision(supervisorModel, content string) (*ValidationDecision, error) {
+	if sr.tierRouter.supportsStructuredOutput(supervisorModel) {
+		return parseStructuredDecision(content)
+	}
+	return sr.parseValidationDecision(content)
+}
+
+// parseStructuredDecision strictly decodes a schema-constrained supervisor reply. Some
+// providers still wrap the object in a code fence, which is unwrapped; prose is not.
+func parseStructuredDecision(content string) (*ValidationDecision, error) {
+	trimmed := strings.TrimSpace(content)
+	data := []byte(trimmed)
+	if strings.HasPrefix(trimmed, "```") && strings.HasSuffix(trimmed, "```") {
+		if obj := llmjson.ExtractObject(trimmed); obj != "" {
+			data = []byte(obj)
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("supervisor reply is not a JSON object: %w", err)
+	}
+	for _, required := range []string{"approved", "confidence"} {
+		if _, ok := fields[required]; !ok {
+			return nil, fmt.Errorf("supervisor decision missing required field %q", required)
+		}
+	}
+
+	var decision ValidationDecision
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return nil, fmt.Errorf("supervisor decision does not match schema: %w", err)
+	}
+	if decision.Confidence < 0 || decision.Confidence > 1 {
+		return nil, fmt.Errorf("supervisor confidence %.2f out of range [0,1]", decision.Confidence)
+	}
+	if decision.Corrections == nil {
+		decision.Corrections = []string{}
+	}
+	return &decision, nil
+}
+
 // parseValidationDecision parses the supervisor's validation decision
 func (sr *SupervisionRouter) parseValidationDecision(supervisorContent string) (*ValidationDecision, error) {
 	// Try to parse JSON response from supervisor
 	var decision ValidationDecision
 
 	// First, try to extract JSON from the response
-	jsonStart := strings.Index(supervisorContent, "{")
-	jsonEnd := strings.LastIndex(supervisorContent, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
+	jsonStr := llmjson.ExtractObject(supervisorContent)
+	if jsonStr == "" {
 		// No valid JSON found, use fallback approval
 		logger.WarnCF(sr.component, "No valid JSON found in supervisor response, using fallback", nil)
 		return &ValidationDecision{
@@ -714,7 +1118,6 @@ func (sr *SupervisionRouter) parseValidationDecision(supervisorContent string) (
 		}, nil
 	}
 
-	jsonStr := supervisorContent[jsonStart : jsonEnd+1]
 	err := json.Unmarshal([]byte(jsonStr), &decision)
 	if err != nil {
 		logger.WarnCF(sr.component, "Failed to parse supervisor JSON response, using fallback", map[string]any{
@@ -754,6 +1157,33 @@ func (sr *SupervisionRouter) getModelForTask(taskType TaskType) string {
 	return sr.tierRouter.selectWorkerModel(taskType)
 }
 
+// RouteToSupervisor sends a request straight to the configured supervisor
+// tier, for judgements the worker tiers shouldn't make. Without a
+// supervisor tier it is routed as a supervision task.
+func (tr *TierRouter) RouteToSupervisor(
+	ctx context.Context,
+	messages []providers.Message,
+	options map[string]any,
+	sessionKey string,
+) (*providers.LLMResponse, error) {
+	routingCfg := tr.routingConfig()
+	tier, ok := routingCfg.Tiers[routingCfg.SupervisorTier]
+	if !ok {
+		return tr.RouteChat(ctx, TaskSupervision, messages, nil, options, sessionKey)
+	}
+	ctx = withCostTask(ctx, TaskSupervision)
+	logger.InfoCF(tr.component, "Routing to supervisor tier", map[string]any{
+		"tier":  routingCfg.SupervisorTier,
+		"model": tier.ModelName,
+	})
+	resp, err := tr.routeToModel(ctx, tier.ModelName, tier.ModelName, messages, nil, options, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	resp.Model = tier.ModelName
+	return resp, nil
+}
+
 func (tr *TierRouter) selectSupervisorModel() string {
 	// Return most powerful model (typically GPT-4 or Claude 3 Opus)
 	for _, model := range tr.modelList {
@@ -813,7 +1243,7 @@ func (tv *TaskValidator) getValidationRule(taskType TaskType) *ValidationRule {
 
 func isKnownTaskType(taskType TaskType) bool {
 	switch taskType {
-	case TaskPlanning, TaskAnalysis, TaskExploitation, TaskReportWriting, TaskSupervision, TaskToolSelection, TaskCodeReview, TaskJSAnalysis, TaskValidation, TaskParsing, TaskSummary, TaskFormatting, TaskTriage:
+	case TaskPlanning, TaskAnalysis, TaskExploitation, TaskReportWriting, TaskSupervision, TaskToolSelection, TaskCodeReview, TaskJSAnalysis, TaskValidation, TaskReportSection, TaskParsing, TaskSummary, TaskFormatting, TaskTriage:
 		return true
 	default:
 		return false
@@ -821,7 +1251,7 @@ func isKnownTaskType(taskType TaskType) bool {
 }
 
 func (tr *TierRouter) routeToModel(ctx context.Context, providerKey, modelName string, messages []providers.Message, tools []providers.ToolDefinition, options map[string]any, sessionKey string) (*providers.LLMResponse, error) {
-	provider, ok := tr.providers[providerKey]
+	provider, ok := tr.provider(providerKey)
 	if !ok {
 		return nil, fmt.Errorf("provider not found for model %s", providerKey)
 	}
@@ -829,18 +1259,17 @@ func (tr *TierRouter) routeToModel(ctx context.Context, providerKey, modelName s
 	if err != nil {
 		return nil, err
 	}
-	start := time.Now()
-	resp, err := provider.Chat(ctx, messages, tools, modelName, options)
-	elapsed := time.Since(start)
+	options = withTierReasoning(options, tierCfg)
+	resp, elapsed, err := tr.chat(ctx, provider, providerKey, tr.withPreamble(sessionKey, providerKey, messages), tools, modelName, options, sessionKey)
 	if err != nil {
 		return nil, err
 	}
-	tr.costs.Record(sessionKey, providerKey, tierName, *tierCfg, *resp.Usage, elapsed)
+	tr.costs.Record(sessionKey, providerKey, tierName, *tierCfg, responseUsage(resp), elapsed, costAttributionFrom(ctx))
 	return resp, nil
 }
 
 func (sr *SupervisionRouter) routeToModel(ctx context.Context, providerKey, modelName string, messages []providers.Message, tools []providers.ToolDefinition, options map[string]any, sessionKey string) (*providers.LLMResponse, error) {
-	provider, ok := sr.tierRouter.providers[providerKey]
+	provider, ok := sr.tierRouter.provider(providerKey)
 	if !ok {
 		return nil, fmt.Errorf("provider not found for model %s", providerKey)
 	}
@@ -848,20 +1277,20 @@ func (sr *SupervisionRouter) routeToModel(ctx context.Context, providerKey, mode
 	if err != nil {
 		return nil, err
 	}
-	start := time.Now()
-	resp, err := provider.Chat(ctx, messages, tools, modelName, options)
-	elapsed := time.Since(start)
+	options = withTierReasoning(options, tierCfg)
+	resp, elapsed, err := sr.tierRouter.chat(ctx, provider, providerKey, sr.tierRouter.withPreamble(sessionKey, providerKey, messages), tools, modelName, options, sessionKey)
 	if err != nil {
 		return nil, err
 	}
 	if sr.costTracker != nil {
-		sr.costTracker.Record(sessionKey, providerKey, tierName, *tierCfg, *resp.Usage, elapsed)
+		sr.costTracker.Record(sessionKey, providerKey, tierName, *tierCfg, responseUsage(resp), elapsed, costAttributionFrom(ctx))
 	}
 	return resp, nil
 }
 
 func (tr *TierRouter) getTierForModel(modelName string) (string, *config.TierConfig, error) {
-	for tierName, tierCfg := range tr.config.Tiers {
+	routingCfg := tr.routingConfig()
+	for tierName, tierCfg := range routingCfg.Tiers {
 		if tierCfg.ModelName == modelName {
 			cfgCopy := tierCfg
 			return tierName, &cfgCopy, nil
@@ -870,6 +1299,15 @@ func (tr *TierRouter) getTierForModel(modelName string) (string, *config.TierCon
 	return "", nil, fmt.Errorf("no tier found for model %s", modelName)
 }
 
+// responseUsage returns the token usage of resp, zero when the provider
+// reported none
+func responseUsage(resp *providers.LLMResponse) providers.UsageInfo {
+	if resp.Usage == nil {
+		return providers.UsageInfo{}
+	}
+	return *resp.Usage
+}
+
 func (tr *TierRouter) estimateCallCost(modelName string, usage *providers.UsageInfo) float64 {
 	if usage == nil {
 		return 0
@@ -878,9 +1316,7 @@ func (tr *TierRouter) estimateCallCost(modelName string, usage *providers.UsageI
 	if err != nil {
 		return 0
 	}
-	inputCost := float64(usage.PromptTokens) / 1_000_000.0 * tierCfg.CostPerM.Input
-	outputCost := float64(usage.CompletionTokens) / 1_000_000.0 * tierCfg.CostPerM.Output
-	return inputCost + outputCost
+	return EstimateUsageCost(*tierCfg, *usage)
 }
 
 func (tr *TierRouter) estimateSupervisionSavings(workerModel, supervisorModel string, workerUsage, supervisorUsage *providers.UsageInfo) float64 {
@@ -2,11 +2,20 @@ package routing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/pipeline"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/replay"
 )
 
 // Mock provider for testing
@@ -14,6 +23,7 @@ type mockProvider struct {
 	responses map[string]*providers.LLMResponse
 	errors    map[string]error
 	callCount map[string]int
+	options   map[string]map[string]any
 }
 
 func newMockProvider() *mockProvider {
@@ -21,6 +31,7 @@ func newMockProvider() *mockProvider {
 		responses: make(map[string]*providers.LLMResponse),
 		errors:    make(map[string]error),
 		callCount: make(map[string]int),
+		options:   make(map[string]map[string]any),
 	}
 }
 
@@ -29,7 +40,7 @@ func (m *mockProvider) Chat(ctx context.Context, messages []providers.Message, t
 	if m.errors[key] != nil {
 		return nil, m.errors[key]
 	}
-	
+
 	resp := m.responses[key]
 	if resp == nil {
 		// Default response
@@ -38,12 +49,13 @@ func (m *mockProvider) Chat(ctx context.Context, messages []providers.Message, t
 			Usage: &providers.UsageInfo{
 				PromptTokens:     10,
 				CompletionTokens: 20,
-				TotalTokens:     30,
+				TotalTokens:      30,
 			},
 		}
 	}
-	
+
 	m.callCount[key]++
+	m.options[key] = opts
 	return resp, nil
 }
 
@@ -66,7 +78,7 @@ func (m *mockProvider) GetDefaultModel() string {
 // Helper to create test routing config
 func testRoutingConfig() *config.RoutingConfig {
 	return &config.RoutingConfig{
-		Enabled:    true,
+		Enabled:     true,
 		DefaultTier: "fast",
 		Tiers: map[string]config.TierConfig{
 			"fast": {
@@ -94,9 +106,9 @@ func testRoutingConfig() *config.RoutingConfig {
 				},
 			},
 		},
-		EnableSupervision: true,
-		SupervisorTier:    "powerful",
-		ValidationConfidenceThreshold: 0.8,
+		EnableSupervision:               true,
+		SupervisorTier:                  "powerful",
+		ValidationConfidenceThreshold:   0.8,
 		MinTaskComplexityForSupervision: 5,
 	}
 }
@@ -115,13 +127,13 @@ func TestTierRouter_Init(t *testing.T) {
 	cfg := testRoutingConfig()
 	models := testModelList()
 	provider := newMockProvider()
-	
+
 	router := NewTierRouter(cfg, models, map[string]providers.LLMProvider{"test": provider})
-	
+
 	if router == nil {
 		t.Fatal("Expected router to be created")
 	}
-	
+
 	if !router.IsEnabled() {
 		t.Error("Expected router to be enabled")
 	}
@@ -132,7 +144,7 @@ func TestTierRouter_ClassifyTask(t *testing.T) {
 	models := testModelList()
 	provider := newMockProvider()
 	router := NewTierRouter(cfg, models, map[string]providers.LLMProvider{"test": provider})
-	
+
 	tests := []struct {
 		name     string
 		ctx      AgentContext
@@ -150,9 +162,9 @@ func TestTierRouter_ClassifyTask(t *testing.T) {
 		{
 			name: "Security task should require supervision",
 			ctx: AgentContext{
-				TurnCount:      1,
-				UserMessage:    "Find security vulnerabilities in this code",
-				ToolsAvailable: 5,
+				TurnCount:           1,
+				UserMessage:         "Find security vulnerabilities in this code",
+				ToolsAvailable:      5,
 				RequiresSupervision: true,
 			},
 			expected: TaskCodeReview, // Security tasks typically code review
@@ -170,16 +182,16 @@ func TestTierRouter_ClassifyTask(t *testing.T) {
 		{
 			name: "Complex multi-turn task",
 			ctx: AgentContext{
-				TurnCount:      5,
-				UserMessage:    "Continue the analysis",
-				LastToolOutput: "Found potential issues",
-				ToolsAvailable: 8,
+				TurnCount:           5,
+				UserMessage:         "Continue the analysis",
+				LastToolOutput:      "Found potential issues",
+				ToolsAvailable:      8,
 				RequiresSupervision: true,
 			},
 			expected: TaskAnalysis, // Complex tasks also analysis for now
 		},
 	}
-	
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			taskType := router.ClassifyTask(tt.ctx)
@@ -194,39 +206,39 @@ func TestTierRouter_RouteChat_NoSupervision(t *testing.T) {
 	cfg := testRoutingConfig()
 	models := testModelList()
 	provider := newMockProvider()
-	
+
 	// Set up mock response
 	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
 		Content: "Hello! How can I help you?",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     10,
 			CompletionTokens: 5,
-			TotalTokens:     15,
+			TotalTokens:      15,
 		},
 	})
-	
+
 	// Create providers map with model name as key
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Hello"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	resp, err := router.RouteChat(context.Background(), "fast", messages, tools, opts, "test-session")
 	if err != nil {
 		t.Fatalf("RouteChat() failed: %v", err)
 	}
-	
+
 	if resp.Content != "Hello! How can I help you?" {
 		t.Errorf("Expected content to match mock response")
 	}
-	
+
 	if provider.getCallCount("claude-3-haiku") != 1 {
 		t.Errorf("Expected 1 call to claude-3-haiku, got %d", provider.getCallCount("claude-3-haiku"))
 	}
@@ -237,78 +249,78 @@ func TestTierRouter_RouteWithSupervision_Success(t *testing.T) {
 	models := testModelList()
 	provider := newMockProvider()
 	costTracker := NewCostTracker()
-	
+
 	// Set up mock responses
 	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
 		Content: "Here's the code analysis: no vulnerabilities found",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     20,
 			CompletionTokens: 30,
-			TotalTokens:     50,
+			TotalTokens:      50,
 		},
 	})
-	
+
 	provider.setResponse("claude-3-opus", &providers.LLMResponse{
 		Content: `{"decision": "approve", "confidence": 0.95, "reasoning": "Analysis is accurate and complete"}`,
 		Usage: &providers.UsageInfo{
 			PromptTokens:     30,
 			CompletionTokens: 20,
-			TotalTokens:     50,
+			TotalTokens:      50,
 		},
 	})
-	
+
 	// Create providers map with model names as keys
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 		"claude-3-opus":  provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
 	router.supervisor.costTracker = costTracker
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Analyze this code for security vulnerabilities"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	ctx := AgentContext{
-		TurnCount:      1,
-		UserMessage:    "Analyze this code for security vulnerabilities",
+		TurnCount:           1,
+		UserMessage:         "Analyze this code for security vulnerabilities",
 		RequiresSupervision: true,
 	}
-	
+
 	result, err := router.RouteWithSupervision(context.Background(), "balanced", messages, tools, opts, "test-session", ctx)
 	if err != nil {
 		t.Fatalf("RouteWithSupervision() failed: %v", err)
 	}
-	
+
 	if !result.Validated {
 		t.Error("Expected result to be validated")
 	}
-	
+
 	if result.SupervisorModel != "claude-3-opus" {
 		t.Errorf("Expected supervisor model claude-3-opus, got %q", result.SupervisorModel)
 	}
-	
+
 	if result.WorkerModel != "claude-3-haiku" {
 		t.Errorf("Expected worker model claude-3-haiku, got %q", result.WorkerModel)
 	}
-	
+
 	if provider.getCallCount("claude-3-haiku") != 1 {
 		t.Errorf("Expected 1 call to worker model, got %d", provider.getCallCount("claude-3-haiku"))
 	}
-	
+
 	if provider.getCallCount("claude-3-opus") != 1 {
 		t.Errorf("Expected 1 call to supervisor model, got %d", provider.getCallCount("claude-3-opus"))
 	}
-	
+
 	// Check cost tracking
 	sessionCost := costTracker.GetSessionCost("test-session")
 	if sessionCost == nil {
 		t.Fatal("Expected session cost to be tracked")
 	}
-	
+
 	if sessionCost.Supervision.TotalSupervisions == 0 {
 		t.Error("Expected supervision metrics to be tracked")
 	}
@@ -319,96 +331,96 @@ func TestTierRouter_RouteWithSupervision_Correction(t *testing.T) {
 	models := testModelList()
 	provider := newMockProvider()
 	costTracker := NewCostTracker()
-	
+
 	// Set up mock responses - first attempt fails validation
 	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
 		Content: "This code is perfectly safe, no issues at all",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     20,
 			CompletionTokens: 30,
-			TotalTokens:     50,
+			TotalTokens:      50,
 		},
 	})
-	
+
 	// Supervisor rejects first attempt
 	provider.setResponse("claude-3-opus", &providers.LLMResponse{
 		Content: `{"decision": "reject", "confidence": 0.9, "reasoning": "Analysis missed critical SQL injection vulnerability", "corrections": ["Add input validation", "Use parameterized queries"]}`,
 		Usage: &providers.UsageInfo{
 			PromptTokens:     30,
 			CompletionTokens: 40,
-			TotalTokens:     70,
+			TotalTokens:      70,
 		},
 	})
-	
+
 	// Second attempt after correction
 	provider.setResponse("claude-3-sonnet", &providers.LLMResponse{
 		Content: "Found SQL injection vulnerability. Fixed with parameterized queries and input validation.",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     25,
 			CompletionTokens: 35,
-			TotalTokens:     60,
+			TotalTokens:      60,
 		},
 	})
-	
+
 	// Supervisor approves corrected version
 	provider.responses["claude-3-opus-2"] = &providers.LLMResponse{
 		Content: `{"decision": "approve", "confidence": 0.98, "reasoning": "Corrections properly address the security issues"}`,
 		Usage: &providers.UsageInfo{
 			PromptTokens:     35,
 			CompletionTokens: 25,
-			TotalTokens:     60,
+			TotalTokens:      60,
 		},
 	}
-	
+
 	// Create providers map with model names as keys
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku":  provider,
 		"claude-3-sonnet": provider,
 		"claude-3-opus":   provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
 	router.supervisor.costTracker = costTracker
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Analyze this code for security vulnerabilities"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	ctx := AgentContext{
-		TurnCount:      1,
-		UserMessage:    "Analyze this code for security vulnerabilities",
+		TurnCount:           1,
+		UserMessage:         "Analyze this code for security vulnerabilities",
 		RequiresSupervision: true,
 	}
-	
+
 	result, err := router.RouteWithSupervision(context.Background(), "balanced", messages, tools, opts, "test-session", ctx)
 	if err != nil {
 		t.Fatalf("RouteWithSupervision() failed: %v", err)
 	}
-	
+
 	if !result.Validated {
 		t.Error("Expected final result to be validated after correction")
 	}
-	
+
 	if len(result.Corrections) == 0 {
 		t.Error("Expected corrections to be recorded")
 	}
-	
+
 	// Check that corrections were applied (len > 0 implies correction attempts)
 	if len(result.Corrections) == 0 {
 		t.Error("Expected correction attempts to be recorded via corrections")
 	}
-	
+
 	// Check that both models were called
 	if provider.getCallCount("claude-3-haiku") != 1 {
 		t.Errorf("Expected 1 call to initial worker model, got %d", provider.getCallCount("claude-3-haiku"))
 	}
-	
+
 	if provider.getCallCount("claude-3-sonnet") != 1 {
 		t.Errorf("Expected 1 call to corrected worker model, got %d", provider.getCallCount("claude-3-sonnet"))
 	}
-	
+
 	if provider.getCallCount("claude-3-opus") != 2 {
 		t.Errorf("Expected 2 calls to supervisor model, got %d", provider.getCallCount("claude-3-opus"))
 	}
@@ -419,61 +431,61 @@ func TestTierRouter_RouteWithSupervision_Fallback(t *testing.T) {
 	models := testModelList()
 	provider := newMockProvider()
 	costTracker := NewCostTracker()
-	
+
 	// Worker model succeeds
 	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
 		Content: "Analysis complete",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     20,
 			CompletionTokens: 30,
-			TotalTokens:     50,
+			TotalTokens:      50,
 		},
 	})
-	
+
 	// Supervisor fails
 	provider.setError("claude-3-opus", fmt.Errorf("supervisor unavailable"))
-	
+
 	// Create providers map with model names as keys
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 		"claude-3-opus":  provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
 	router.supervisor.costTracker = costTracker
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Analyze this code"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	ctx := AgentContext{
-		TurnCount:      1,
-		UserMessage:    "Analyze this code",
+		TurnCount:           1,
+		UserMessage:         "Analyze this code",
 		RequiresSupervision: true,
 	}
-	
+
 	result, err := router.RouteWithSupervision(context.Background(), "balanced", messages, tools, opts, "test-session", ctx)
 	if err != nil {
 		t.Fatalf("RouteWithSupervision() failed: %v", err)
 	}
-	
+
 	// Should fall back to original response
 	if result.FinalOutput != "Analysis complete" {
 		t.Errorf("Expected fallback to original response, got %q", result.FinalOutput)
 	}
-	
+
 	if result.Validated {
 		t.Error("Expected result not to be validated when supervisor fails")
 	}
-	
+
 	// Check cost tracking records the failure
 	sessionCost := costTracker.GetSessionCost("test-session")
 	if sessionCost == nil {
 		t.Fatal("Expected session cost to be tracked")
 	}
-	
+
 	if sessionCost.Supervision.FailedValidations == 0 {
 		t.Error("Expected supervision failure to be recorded")
 	}
@@ -484,72 +496,72 @@ func TestTierRouter_CostTrackingIntegration(t *testing.T) {
 	models := testModelList()
 	provider := newMockProvider()
 	costTracker := NewCostTracker()
-	
+
 	// Set up responses with different costs
 	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
 		Content: "Fast response",
 		Usage: &providers.UsageInfo{
 			PromptTokens:     10,
 			CompletionTokens: 20,
-			TotalTokens:     30,
+			TotalTokens:      30,
 		},
 	})
-	
+
 	provider.setResponse("claude-3-opus", &providers.LLMResponse{
 		Content: `{"decision": "approve", "confidence": 1.0}`,
 		Usage: &providers.UsageInfo{
 			PromptTokens:     50,
 			CompletionTokens: 30,
-			TotalTokens:     80,
+			TotalTokens:      80,
 		},
 	})
-	
+
 	// Create providers map with model names as keys
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 		"claude-3-opus":  provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
 	router.supervisor.costTracker = costTracker
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Test"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	ctx := AgentContext{
-		TurnCount:      1,
-		UserMessage:    "Test security analysis",
+		TurnCount:           1,
+		UserMessage:         "Test security analysis",
 		RequiresSupervision: true,
 	}
-	
+
 	// Execute supervised routing
 	_, err := router.RouteWithSupervision(context.Background(), "balanced", messages, tools, opts, "test-session", ctx)
 	if err != nil {
 		t.Fatalf("RouteWithSupervision() failed: %v", err)
 	}
-	
+
 	// Check cost tracking
 	sessionCost := costTracker.GetSessionCost("test-session")
 	if sessionCost == nil {
 		t.Fatal("Expected session cost to be tracked")
 	}
-	
+
 	// Should have both worker and supervisor costs
 	if sessionCost.TotalCost <= 0 {
 		t.Error("Expected total cost to be greater than 0")
 	}
-	
+
 	if sessionCost.Supervision.TotalSupervisions != 1 {
 		t.Errorf("Expected 1 supervised task, got %d", sessionCost.Supervision.TotalSupervisions)
 	}
-	
+
 	if sessionCost.Supervision.TotalSupervisionCost <= 0 {
 		t.Error("Expected supervision cost to be tracked")
 	}
-	
+
 	// Check cost savings
 	if sessionCost.Supervision.SupervisionSavings <= 0 {
 		t.Error("Expected estimated savings to be calculated")
@@ -561,26 +573,26 @@ func TestTierRouter_DisabledSupervision(t *testing.T) {
 	cfg.EnableSupervision = false
 	models := testModelList()
 	provider := newMockProvider()
-	
+
 	// Create providers map with model names as keys
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
-	
+
 	// Should route normally without supervision
 	messages := []providers.Message{
 		{Role: "user", Content: "Test"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	resp, err := router.RouteChat(context.Background(), "fast", messages, tools, opts, "test-session")
 	if err != nil {
 		t.Fatalf("RouteChat() failed with disabled supervision: %v", err)
 	}
-	
+
 	if resp == nil {
 		t.Error("Expected response from routing")
 	}
@@ -590,22 +602,1729 @@ func TestTierRouter_InvalidTier(t *testing.T) {
 	cfg := testRoutingConfig()
 	models := testModelList()
 	provider := newMockProvider()
-	
+
 	// Create providers map
 	providersMap := map[string]providers.LLMProvider{
 		"claude-3-haiku": provider,
 	}
-	
+
 	router := NewTierRouter(cfg, models, providersMap)
-	
+
 	messages := []providers.Message{
 		{Role: "user", Content: "Test"},
 	}
 	tools := []providers.ToolDefinition{}
 	opts := map[string]any{}
-	
+
 	_, err := router.RouteChat(context.Background(), "nonexistent-tier", messages, tools, opts, "test-session")
 	if err == nil {
 		t.Error("Expected error for invalid tier")
 	}
-}
\ No newline at end of file
+}
+
+func TestTierRouter_RejectionCircuitBreaker(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.SupervisionRejectionLimit = 2
+	models := testModelList()
+	provider := newMockProvider()
+
+	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
+		Content: "Looks fine",
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	})
+	provider.setResponse("claude-3-opus", &providers.LLMResponse{
+		Content: `{"approved": false, "confidence": 0.9}`,
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	})
+
+	providersMap := map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+		"claude-3-opus":  provider,
+	}
+	router := NewTierRouter(cfg, models, providersMap)
+
+	messages := []providers.Message{{Role: "user", Content: "Review this code"}}
+	ctx := AgentContext{TurnCount: 1, UserMessage: "Review this code", RequiresSupervision: true}
+
+	for i := 0; i < 2; i++ {
+		if _, err := router.RouteWithSupervision(context.Background(), TaskCodeReview, messages, nil, nil, "test-session", ctx); err != nil {
+			t.Fatalf("RouteWithSupervision() failed: %v", err)
+		}
+	}
+
+	escalated := router.EscalatedTaskTypes("test-session")
+	if len(escalated) != 1 || escalated[0] != TaskCodeReview {
+		t.Fatalf("Expected code_review to be escalated, got %v", escalated)
+	}
+	if worker := router.GetCostTracker().GetSessionCost("test-session").ByModel["claude-3-haiku"]; worker == nil || worker.Reviewed != 2 || worker.Rejected != 2 {
+		t.Errorf("Expected 2 of 2 worker outputs rejected, got %+v", worker)
+	}
+
+	result, err := router.RouteWithSupervision(context.Background(), TaskCodeReview, messages, nil, nil, "test-session", ctx)
+	if err != nil {
+		t.Fatalf("RouteWithSupervision() failed after escalation: %v", err)
+	}
+	if result.WorkerModel != "claude-3-opus" {
+		t.Errorf("Expected escalated task to run on supervisor, got worker %q", result.WorkerModel)
+	}
+	if provider.getCallCount("claude-3-haiku") != 2 {
+		t.Errorf("Expected worker to be skipped after escalation, got %d calls", provider.getCallCount("claude-3-haiku"))
+	}
+
+	// Other sessions are unaffected
+	if len(router.EscalatedTaskTypes("other-session")) != 0 {
+		t.Error("Expected escalation to be scoped to the session")
+	}
+}
+
+func TestTierRouter_StructuredSupervisorDecision(t *testing.T) {
+	models := []config.ModelConfig{
+		{ModelName: "claude-3-haiku", Model: "lmstudio/claude-3-haiku"},
+		{ModelName: "claude-3-opus", Model: "anthropic/claude-3-opus"},
+	}
+	messages := []providers.Message{{Role: "user", Content: "Review this code"}}
+	ctx := AgentContext{TurnCount: 1, UserMessage: "Review this code", RequiresSupervision: true}
+
+	tests := []struct {
+		name          string
+		supervisor    string
+		wantValidated bool
+	}{
+		{
+			name:          "schema-conformant reply is accepted",
+			supervisor:    `{"approved": true, "confidence": 0.9, "corrections": [], "final_output": "Reviewed"}`,
+			wantValidated: true,
+		},
+		{
+			name:          "prose around JSON is not brace-extracted",
+			supervisor:    `Sure! {"approved": true, "confidence": 0.9}`,
+			wantValidated: false,
+		},
+		{
+			name:          "fenced JSON is unwrapped",
+			supervisor:    "```json\n{\"approved\": true, \"confidence\": 0.9}\n```",
+			wantValidated: true,
+		},
+		{
+			name:          "missing required field is rejected",
+			supervisor:    `{"confidence": 0.9}`,
+			wantValidated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newMockProvider()
+			provider.setResponse("claude-3-opus", &providers.LLMResponse{
+				Content: tt.supervisor,
+				Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+			})
+			router := NewTierRouter(testRoutingConfig(), models, map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+				"claude-3-opus":  provider,
+			})
+
+			result, err := router.RouteWithSupervision(context.Background(), TaskCodeReview, messages, nil, map[string]any{"max_tokens": 512}, "test-session", ctx)
+			if err != nil {
+				t.Fatalf("RouteWithSupervision() failed: %v", err)
+			}
+			if result.Validated != tt.wantValidated {
+				t.Errorf("Validated = %v, want %v", result.Validated, tt.wantValidated)
+			}
+
+			opts := provider.options["claude-3-opus"]
+			if opts[providers.ResponseSchemaOption] == nil {
+				t.Error("Expected response schema to be sent to structured-output supervisor")
+			}
+			if opts["max_tokens"] != 512 {
+				t.Error("Expected caller options to be preserved")
+			}
+			if provider.options["claude-3-haiku"][providers.ResponseSchemaOption] != nil {
+				t.Error("Did not expect a response schema on the worker call")
+			}
+		})
+	}
+}
+
+func TestTierRouter_RefusalPolicy(t *testing.T) {
+	refusal := func() *providers.LLMResponse {
+		return &providers.LLMResponse{
+			Content: "I'm sorry, but I can't help with scanning that host.",
+			Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      config.RefusalConfig
+		wantContent string
+		wantRefused bool
+		wantLocal   int
+	}{
+		{
+			name:        "annotate only",
+			policy:      config.RefusalConfig{RetryModels: []string{"local-llama"}},
+			wantContent: providers.RefusalNotice + "I'm sorry, but I can't help with scanning that host.",
+			wantRefused: true,
+		},
+		{
+			name:        "retry on local model",
+			policy:      config.RefusalConfig{Retry: true, RetryModels: []string{"claude-3-haiku", "local-llama"}},
+			wantContent: "nmap -sV target",
+			wantLocal:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRoutingConfig()
+			cfg.Refusal = tt.policy
+			provider := newMockProvider()
+			provider.setResponse("claude-3-haiku", refusal())
+			provider.setResponse("local-llama", &providers.LLMResponse{
+				Content: "nmap -sV target",
+				Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			})
+			router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+				"local-llama":    provider,
+			})
+
+			resp, err := router.RouteChat(context.Background(), "fast",
+				[]providers.Message{{Role: "user", Content: "scan it"}}, nil, map[string]any{}, "test-session")
+			if err != nil {
+				t.Fatalf("RouteChat() failed: %v", err)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", resp.Content, tt.wantContent)
+			}
+			if resp.Refused != tt.wantRefused {
+				t.Errorf("refused = %v, want %v", resp.Refused, tt.wantRefused)
+			}
+			if got := provider.getCallCount("claude-3-haiku"); got != 1 {
+				t.Errorf("refusing model called %d times, want 1", got)
+			}
+			if got := provider.getCallCount("local-llama"); got != tt.wantLocal {
+				t.Errorf("local model called %d times, want %d", got, tt.wantLocal)
+			}
+		})
+	}
+}
+
+// rephraseProvider refuses unless the request was rephrased after a refusal
+// and answerRephrased is set. It records the messages of the last call.
+type rephraseProvider struct {
+	*mockProvider
+	answerRephrased bool
+	messages        []providers.Message
+}
+
+func (p *rephraseProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	if model != "claude-3-haiku" {
+		return p.mockProvider.Chat(ctx, messages, tools, model, opts)
+	}
+	p.callCount[model]++
+	p.messages = messages
+	usage := &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	if p.answerRephrased && messages[len(messages)-1].Content == refusalRephrase {
+		return &providers.LLMResponse{Content: "nmap -sV -p- target", Usage: usage}, nil
+	}
+	return &providers.LLMResponse{Content: "I'm sorry, but I can't help with scanning that host.", Usage: usage}, nil
+}
+
+func TestTierRouter_RefusalRephrase(t *testing.T) {
+	tests := []struct {
+		name            string
+		answerRephrased bool
+		wantContent     string
+		wantLocal       int
+	}{
+		{"rephrased request answered", true, "nmap -sV -p- target", 0},
+		{"retry model after rephrase refused", false, "nmap -sV target", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRoutingConfig()
+			cfg.Refusal = config.RefusalConfig{Rephrase: true, Retry: true, RetryModels: []string{"local-llama"}}
+			provider := &rephraseProvider{mockProvider: newMockProvider(), answerRephrased: tt.answerRephrased}
+			provider.setResponse("local-llama", &providers.LLMResponse{
+				Content: "nmap -sV target",
+				Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			})
+			router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+				"local-llama":    provider,
+			})
+
+			resp, err := router.RouteChat(context.Background(), "fast",
+				[]providers.Message{{Role: "user", Content: "scan it"}}, nil, map[string]any{}, "test-session")
+			if err != nil {
+				t.Fatalf("RouteChat() failed: %v", err)
+			}
+			if resp.Content != tt.wantContent || resp.Refused {
+				t.Errorf("response = %q (refused %v), want %q", resp.Content, resp.Refused, tt.wantContent)
+			}
+			if got := provider.getCallCount("claude-3-haiku"); got != 2 {
+				t.Errorf("refusing model called %d times, want 2", got)
+			}
+			if got := provider.getCallCount("local-llama"); got != tt.wantLocal {
+				t.Errorf("local model called %d times, want %d", got, tt.wantLocal)
+			}
+
+			// The rephrased request keeps the refusal before the rephrasing
+			sent := provider.messages
+			if n := len(sent); n < 3 || sent[n-2].Role != "assistant" || !strings.Contains(sent[n-2].Content, "I can't help") ||
+				sent[n-1].Role != "user" || sent[n-1].Content != refusalRephrase {
+				t.Errorf("rephrased messages = %+v", sent)
+			}
+		})
+	}
+}
+
+func TestTierRouter_ReplayWithoutUsage(t *testing.T) {
+	// Scripted steps without a usage block must route and bill as zero
+	provider, err := replay.NewScriptProvider("no-usage", &replay.Script{Steps: []replay.ScriptStep{
+		{Model: "claude-3-haiku", Content: "hello"},
+		{Model: "claude-3-haiku", Content: "Port 22 runs OpenSSH"},
+		{Model: "claude-3-opus", Content: `{"decision": "approve", "approved": true, "confidence": 0.9, "final_output": "Port 22 runs OpenSSH"}`},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+		"claude-3-opus":  provider,
+	})
+
+	messages := []providers.Message{{Role: "user", Content: "hi"}}
+	resp, err := router.RouteChat(context.Background(), "fast", messages, nil, nil, "test-session")
+	if err != nil || resp.Content != "hello" {
+		t.Fatalf("RouteChat() = %+v, %v", resp, err)
+	}
+	result, err := router.RouteWithSupervision(context.Background(), "balanced", messages, nil, nil, "test-session", AgentContext{RequiresSupervision: true})
+	if err != nil || result.FinalOutput != "Port 22 runs OpenSSH" {
+		t.Fatalf("RouteWithSupervision() = %+v, %v", result, err)
+	}
+	if cost := router.GetCostTracker().GetTotalCost(); cost != 0 {
+		t.Errorf("total cost = %g, want 0", cost)
+	}
+
+	// Providers that report no usage at all are billed as zero too
+	mock := newMockProvider()
+	mock.setResponse("claude-3-haiku", &providers.LLMResponse{Content: "no usage"})
+	router = NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{"claude-3-haiku": mock})
+	if resp, err := router.RouteChat(context.Background(), "fast", messages, nil, nil, "test-session"); err != nil || resp.Content != "no usage" {
+		t.Errorf("RouteChat() without usage = %+v, %v", resp, err)
+	}
+}
+
+func TestTierRouter_ContextWindowFit(t *testing.T) {
+	cfg := testRoutingConfig()
+	models := testModelList()
+	models[0].ContextWindow = 1000 // claude-3-haiku
+	models[1].ContextWindow = 8000 // claude-3-sonnet
+	models[2].ContextWindow = 4000 // claude-3-opus
+
+	provider := newMockProvider()
+	router := NewTierRouter(cfg, models, map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+		"claude-3-opus":   provider,
+	})
+
+	// ~1200 tokens of content plus a 500 token output budget.
+	messages := []providers.Message{{Role: "user", Content: strings.Repeat("a", 3000)}}
+	opts := map[string]any{"max_tokens": 500}
+
+	if _, err := router.RouteChat(context.Background(), "fast", messages, nil, opts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if got := provider.getCallCount("claude-3-haiku"); got != 0 {
+		t.Errorf("undersized tier called %d times, want 0", got)
+	}
+	if got := provider.getCallCount("claude-3-opus"); got != 1 {
+		t.Errorf("smallest fitting tier called %d times, want 1", got)
+	}
+
+	huge := []providers.Message{{Role: "user", Content: strings.Repeat("a", 30000)}}
+	_, err := router.RouteChat(context.Background(), "fast", huge, nil, opts, "test-session")
+	if !errors.Is(err, providers.ErrContextTooLong) {
+		t.Fatalf("err = %v, want ErrContextTooLong", err)
+	}
+}
+
+func TestTierRouter_ResponseCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature any
+		wantCalls   int
+	}{
+		{"deterministic requests are cached", 0.0, 1},
+		{"sampled requests are not cached", 0.7, 2},
+		{"missing temperature is not cached", nil, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRoutingConfig()
+			cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true}
+			provider := newMockProvider()
+			router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+			})
+
+			opts := map[string]any{}
+			if tt.temperature != nil {
+				opts["temperature"] = tt.temperature
+			}
+			messages := []providers.Message{{Role: "user", Content: "format this nmap output"}}
+			for i := 0; i < 2; i++ {
+				resp, err := router.RouteChat(context.Background(), "fast", messages, nil, opts, "test-session")
+				if err != nil {
+					t.Fatalf("RouteChat() failed: %v", err)
+				}
+				if resp.Content != "Mock response" {
+					t.Errorf("content = %q", resp.Content)
+				}
+			}
+			if got := provider.getCallCount("claude-3-haiku"); got != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheKey_Options(t *testing.T) {
+	messages := []providers.Message{{Role: "user", Content: "list the open ports"}}
+	base := map[string]any{"temperature": 0.0, "max_tokens": 1024}
+	key := responseCacheKey("claude-3-haiku", messages, nil, base)
+	if key == "" {
+		t.Fatal("deterministic request not cacheable")
+	}
+
+	with := func(k string, v any) map[string]any {
+		opts := map[string]any{"temperature": 0.0, "max_tokens": 1024}
+		opts[k] = v
+		return opts
+	}
+	for name, opts := range map[string]map[string]any{
+		"max_tokens":       with("max_tokens", 64),
+		"response_format":  with("response_format", map[string]any{"type": "json_object"}),
+		"reasoning_effort": with("reasoning_effort", "high"),
+		"thinking_budget":  with("thinking_budget", 2048),
+		"tool_choice":      with("tool_choice", "required"),
+	} {
+		if got := responseCacheKey("claude-3-haiku", messages, nil, opts); got == key || got == "" {
+			t.Errorf("%s: key = %q, want a different cacheable key", name, got)
+		}
+	}
+	if got := responseCacheKey("claude-3-haiku", messages, nil, with("prompt_cache_key", "session-a")); got != key {
+		t.Error("prompt_cache_key changed the key")
+	}
+	if got := responseCacheKey("claude-3-sonnet", messages, nil, base); got == key {
+		t.Error("another model shares the key")
+	}
+	if got := responseCacheKey("claude-3-haiku", messages, nil, with("stop", func() {})); got != "" {
+		t.Errorf("unhashable options key = %q, want not cacheable", got)
+	}
+}
+
+func TestTierRouter_NoResponseKeyWithoutCache(t *testing.T) {
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": newMockProvider(),
+	})
+	preambles := 0
+	router.SetPreambleFunc(func(sessionKey, modelName string) string {
+		preambles++
+		return "mission state"
+	})
+
+	messages := []providers.Message{{Role: "user", Content: "hi"}}
+	if _, err := router.RouteChat(context.Background(), "fast", messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	// Only the request itself builds the preamble; no cache key is hashed
+	if preambles != 1 {
+		t.Errorf("preamble built %d times, want 1", preambles)
+	}
+}
+
+func TestTierRouter_ResponseCacheKeysOnAnsweringModel(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true}
+	cfg.Refusal = config.RefusalConfig{Retry: true, RetryModels: []string{"local-llama"}}
+	provider := newMockProvider()
+	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
+		Content: "I'm sorry, but I can't help with scanning that host.",
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	})
+	provider.setResponse("local-llama", &providers.LLMResponse{
+		Content: "nmap -sV target",
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	})
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+		"local-llama":    provider,
+	})
+
+	messages := []providers.Message{{Role: "user", Content: "scan it"}}
+	opts := map[string]any{"temperature": 0.0}
+	for i := 0; i < 2; i++ {
+		resp, err := router.RouteChat(context.Background(), "fast", messages, nil, opts, "test-session")
+		if err != nil {
+			t.Fatalf("RouteChat() failed: %v", err)
+		}
+		if resp.Content != "nmap -sV target" || resp.Model != "local-llama" {
+			t.Errorf("response = %q from %s", resp.Content, resp.Model)
+		}
+	}
+	// The retry's answer isn't served as the refusing tier's own
+	if got := provider.getCallCount("claude-3-haiku"); got != 2 {
+		t.Errorf("refusing model called %d times, want 2", got)
+	}
+	if _, ok := router.GetResponseCache().Get(responseCacheKey("local-llama", messages, nil, opts)); !ok {
+		t.Error("retry answer not cached under the model that gave it")
+	}
+}
+
+func TestResponseCache_EvictsOldestAtCapacity(t *testing.T) {
+	cache := NewResponseCache(2, time.Minute)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Put(key, &providers.LLMResponse{Content: key})
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("len = %d, want 2", cache.Len())
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	resp, ok := cache.Get("c")
+	if !ok || resp.Content != "c" {
+		t.Fatalf("Get(c) = %+v, %v", resp, ok)
+	}
+	resp.Content = "mutated"
+	if again, _ := cache.Get("c"); again.Content != "c" {
+		t.Error("cached response was mutated through a returned copy")
+	}
+}
+
+func TestTierRouter_ToolOutputCache(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true}
+	provider := newMockProvider()
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+	})
+
+	output := "80/tcp open http nginx"
+	opts := map[string]any{"temperature": 0.1}
+	parse := func(ctx context.Context, task TaskType, prompt string) {
+		t.Helper()
+		messages := []providers.Message{{Role: "user", Content: prompt}}
+		if _, err := router.RouteChat(ctx, task, messages, nil, opts, "resumed"); err != nil {
+			t.Fatalf("RouteChat() failed: %v", err)
+		}
+	}
+
+	// A resumed mission re-parses the same output with a different prompt
+	ctx := WithToolOutputHash(context.Background(), HashToolOutput(output, "nmap"))
+	parse(ctx, TaskParsing, "Parse this nmap output (run 1): "+output)
+	parse(ctx, TaskParsing, "Parse this nmap output (run 2): "+output)
+	if got := provider.getCallCount("claude-3-haiku"); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+
+	// Other task types and other qualifiers are not served from the parse
+	parse(ctx, TaskFormatting, "Format: "+output)
+	parse(WithToolOutputHash(context.Background(), HashToolOutput(output, "httpx")), TaskParsing, "Parse: "+output)
+	if got := provider.getCallCount("claude-3-haiku"); got != 3 {
+		t.Errorf("provider called %d times, want 3", got)
+	}
+
+	session := router.GetCostTracker().GetSessionCost("resumed")
+	if session.Cache.Hits != 1 || session.Cache.ToolOutputHits != 1 {
+		t.Errorf("Cache metrics = %+v, want one tool output hit", session.Cache)
+	}
+	if !strings.Contains(router.GetCostTracker().FormatSessionReport("resumed"), "1 tool output re-parses") {
+		t.Error("Session report missing cache summary")
+	}
+}
+
+func TestTierRouter_CostAttribution(t *testing.T) {
+	provider := newMockProvider()
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "Test"}}
+
+	calls := []struct {
+		phase, tool string
+		task        TaskType
+	}{
+		{"recon", "exec", TaskParsing},
+		{"recon", "", TaskPlanning},
+		{"exploitation", "web_fetch", TaskAnalysis},
+		{"", "", TaskTriage},
+	}
+	for _, c := range calls {
+		ctx := WithCostAttribution(context.Background(), c.phase, c.tool)
+		if _, err := router.RouteChat(ctx, c.task, messages, nil, nil, "mission"); err != nil {
+			t.Fatalf("RouteChat(%s) failed: %v", c.task, err)
+		}
+	}
+
+	session := router.GetCostTracker().GetSessionCost("mission")
+	if got := session.ByPhase["recon"]; got == nil || got.Calls != 2 {
+		t.Errorf("ByPhase[recon] = %+v, want 2 calls", got)
+	}
+	if got := session.ByPhase["(none)"]; got == nil || got.Calls != 1 {
+		t.Errorf("ByPhase[(none)] = %+v, want 1 call", got)
+	}
+	if got := session.ByTool["web_fetch"]; got == nil || got.Calls != 1 {
+		t.Errorf("ByTool[web_fetch] = %+v, want 1 call", got)
+	}
+	if session.ByPurpose[PurposeSummarization].Calls != 1 || session.ByPurpose[PurposeReasoning].Calls != 3 {
+		t.Errorf("ByPurpose = summarization %d, reasoning %d calls; want 1 and 3",
+			session.ByPurpose[PurposeSummarization].Calls, session.ByPurpose[PurposeReasoning].Calls)
+	}
+
+	report := router.GetCostTracker().FormatSessionReport("mission")
+	for _, want := range []string{"By Purpose:", "By Phase:", "  exploitation: 1 calls", "By Tool:", "  exec: 1 calls"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestTierRouter_RouteToSupervisor(t *testing.T) {
+	provider := newMockProvider()
+	cfg := testRoutingConfig()
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+		"claude-3-opus":  provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "Is the phase complete?"}}
+
+	resp, err := router.RouteToSupervisor(context.Background(), messages, nil, "completion")
+	if err != nil {
+		t.Fatalf("RouteToSupervisor() failed: %v", err)
+	}
+	if resp.Model != "claude-3-opus" || provider.getCallCount("claude-3-opus") != 1 {
+		t.Errorf("model = %q, opus calls = %d; want the supervisor tier", resp.Model, provider.getCallCount("claude-3-opus"))
+	}
+	if got := router.GetCostTracker().GetSessionCost("completion").ByTier["powerful"]; got == nil || got.Calls != 1 {
+		t.Errorf("ByTier[powerful] = %+v, want 1 call", got)
+	}
+
+	// Without a supervisor tier the request is routed like any other
+	cfg.SupervisorTier = ""
+	if _, err := router.RouteToSupervisor(context.Background(), messages, nil, "completion"); err != nil {
+		t.Fatalf("RouteToSupervisor() without supervisor tier failed: %v", err)
+	}
+	if provider.getCallCount("claude-3-opus")+provider.getCallCount("claude-3-haiku") != 2 {
+		t.Errorf("calls = opus %d, haiku %d; want 2 in total",
+			provider.getCallCount("claude-3-opus"), provider.getCallCount("claude-3-haiku"))
+	}
+}
+
+func TestTierRouter_TaskOverrides(t *testing.T) {
+	zero := 0.0
+	cfg := testRoutingConfig()
+	cfg.Tiers["fast"] = config.TierConfig{ModelName: "claude-3-haiku", UseFor: []string{"parsing"}}
+	cfg.TaskOverrides = map[string]config.TaskOverride{
+		"parsing": {Temperature: &zero, MaxTokens: 512, SystemPrompt: "Return only JSON."},
+	}
+
+	var gotMessages []providers.Message
+	provider := newMockProvider()
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  &recordingProvider{mockProvider: provider, messages: &gotMessages},
+		"claude-3-sonnet": provider,
+	})
+
+	callerOpts := map[string]any{"temperature": 0.7, "max_tokens": 8192}
+	messages := []providers.Message{
+		{Role: "system", Content: "You are a pentest assistant."},
+		{Role: "user", Content: "parse this nmap output"},
+	}
+	if _, err := router.RouteChat(context.Background(), TaskParsing, messages, nil, callerOpts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+
+	opts := provider.options["claude-3-haiku"]
+	if opts["temperature"] != 0.0 || opts["max_tokens"] != 512 {
+		t.Errorf("options = %v, want temperature 0 and max_tokens 512", opts)
+	}
+	if callerOpts["temperature"] != 0.7 || callerOpts["max_tokens"] != 8192 {
+		t.Errorf("caller options were modified: %v", callerOpts)
+	}
+	if !strings.HasSuffix(gotMessages[0].Content, "Return only JSON.") {
+		t.Errorf("system prompt = %q, want override appended", gotMessages[0].Content)
+	}
+	if messages[0].Content != "You are a pentest assistant." {
+		t.Errorf("caller messages were modified: %q", messages[0].Content)
+	}
+
+	// Task types without overrides keep the caller's options
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, callerOpts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if opts := provider.options["claude-3-sonnet"]; opts["temperature"] != 0.7 || opts["max_tokens"] != 8192 {
+		t.Errorf("analysis options = %v, want caller options", opts)
+	}
+}
+
+func TestTierRouter_ReasoningOptions(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.Tiers["fast"] = config.TierConfig{
+		ModelName: "claude-3-haiku", UseFor: []string{"parsing", "planning"},
+		ReasoningEffort: "high", ThinkingBudget: 8000,
+	}
+	cfg.TaskOverrides = map[string]config.TaskOverride{
+		"parsing": {ReasoningEffort: "none", ThinkingBudget: -1},
+	}
+
+	provider := newMockProvider()
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "plan the next step"}}
+	callerOpts := map[string]any{"max_tokens": 4096}
+
+	if _, err := router.RouteChat(context.Background(), TaskPlanning, messages, nil, callerOpts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if opts := provider.options["claude-3-haiku"]; opts["reasoning_effort"] != "high" || opts["thinking_budget"] != 8000 {
+		t.Errorf("planning options = %v, want the tier's reasoning settings", opts)
+	}
+
+	// The task override wins over the tier
+	if _, err := router.RouteChat(context.Background(), TaskParsing, messages, nil, callerOpts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if opts := provider.options["claude-3-haiku"]; opts["reasoning_effort"] != "none" || opts["thinking_budget"] != -1 {
+		t.Errorf("parsing options = %v, want the override's reasoning settings", opts)
+	}
+	if _, ok := callerOpts["reasoning_effort"]; ok {
+		t.Errorf("caller options were modified: %v", callerOpts)
+	}
+
+	// Tiers without reasoning settings send none
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, callerOpts, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if opts := provider.options["claude-3-sonnet"]; opts["reasoning_effort"] != nil || opts["thinking_budget"] != nil {
+		t.Errorf("analysis options = %v, want no reasoning settings", opts)
+	}
+}
+
+func TestTierRouter_TaskOverrideTools(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.Tiers["fast"] = config.TierConfig{ModelName: "claude-3-haiku", UseFor: []string{"parsing", "summary"}}
+	cfg.TaskOverrides = map[string]config.TaskOverride{
+		"parsing": {Tools: []string{"read_file", "web_*"}},
+		"summary": {Tools: []string{"none"}},
+	}
+
+	var gotTools []providers.ToolDefinition
+	provider := &toolRecordingProvider{mockProvider: newMockProvider(), tools: &gotTools}
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+
+	var defs []providers.ToolDefinition
+	for _, name := range []string{"exec", "read_file", "web_fetch", "web_search", "workflow_add_finding"} {
+		defs = append(defs, providers.ToolDefinition{Type: "function", Function: providers.ToolFunctionDefinition{Name: name}})
+	}
+	toolNames := func() []string {
+		var names []string
+		for _, def := range gotTools {
+			names = append(names, def.Function.Name)
+		}
+		return names
+	}
+	messages := []providers.Message{{Role: "user", Content: "parse this"}}
+
+	if _, err := router.RouteChat(context.Background(), TaskParsing, messages, defs, nil, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if got, want := toolNames(), []string{"read_file", "web_fetch", "web_search", "workflow_add_finding"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsing tools = %v, want %v", got, want)
+	}
+	if len(defs) != 5 {
+		t.Errorf("caller tools were modified: %d", len(defs))
+	}
+
+	// Task types without a tools override get every tool
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, defs, nil, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if len(gotTools) != 5 {
+		t.Errorf("analysis tools = %v, want all 5", toolNames())
+	}
+
+	// With nothing left, the tools the conversation called are kept
+	history := []providers.Message{
+		{Role: "user", Content: "scan"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "c1", Name: "exec"}}},
+		{Role: "tool", ToolCallID: "c1", Content: "22/tcp open"},
+	}
+	if _, err := router.RouteChat(context.Background(), TaskSummary, history, defs[:4], nil, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if got := toolNames(); !reflect.DeepEqual(got, []string{"exec"}) {
+		t.Errorf("summary tools = %v, want [exec]", got)
+	}
+}
+
+func TestTierRouter_ToolEnforcement(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.ToolEnforcement = config.ToolEnforcementConfig{Enabled: true, StopSequences: []string{"\n\n"}}
+
+	provider := &proseProvider{mockProvider: newMockProvider(), complyAfter: 1}
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	tools := []providers.ToolDefinition{{Type: "function", Function: providers.ToolFunctionDefinition{Name: "exec"}}}
+	messages := []providers.Message{{Role: "user", Content: "which tool finds open ports?"}}
+
+	resp, err := router.RouteChat(context.Background(), TaskToolSelection, messages, tools, nil, "test-session")
+	if err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected the retry's tool call, got %+v", resp)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider calls = %d, want 2", provider.calls)
+	}
+	if provider.options["tool_choice"] != "required" {
+		t.Errorf("tool_choice = %v, want required", provider.options["tool_choice"])
+	}
+	if stop, _ := provider.options["stop"].([]string); len(stop) != 1 {
+		t.Errorf("stop = %v, want the configured stop sequences", provider.options["stop"])
+	}
+	if last := provider.messages[len(provider.messages)-1]; last.Content != toolCallReminder {
+		t.Errorf("retry did not end with the reminder: %+v", last)
+	}
+
+	// A model that never calls a tool is retried once, then given up on
+	provider.complyAfter = 10
+	resp, err = router.RouteChat(context.Background(), TaskToolSelection, messages, tools, nil, "test-session")
+	if err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 0 || resp.Content == "" {
+		t.Errorf("expected the last prose response, got %+v", resp)
+	}
+
+	// Other task types are not enforced
+	provider.calls = 0
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, tools, nil, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if provider.calls != 1 || provider.options["tool_choice"] != nil {
+		t.Errorf("analysis turn enforced: calls=%d tool_choice=%v", provider.calls, provider.options["tool_choice"])
+	}
+
+	m := router.GetCostTracker().GetSessionCost("test-session").ToolEnforcement
+	want := ToolEnforcementMetrics{Turns: 2, Violations: 3, Retries: 2, Recovered: 1, Failed: 1}
+	if m != want {
+		t.Errorf("ToolEnforcement = %+v, want %+v", m, want)
+	}
+	if report := router.GetCostTracker().FormatSessionReport("test-session"); !strings.Contains(report, "Tool enforcement: 3 violations in 2 tool-only turns") {
+		t.Errorf("report missing tool enforcement line:\n%s", report)
+	}
+}
+
+// proseProvider answers with prose until it has been called complyAfter
+// times in a row, then with a tool call.
+type proseProvider struct {
+	*mockProvider
+	complyAfter int
+	calls       int
+	streak      int
+	options     map[string]any
+	messages    []providers.Message
+}
+
+func (p *proseProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	p.calls++
+	p.options, p.messages = opts, messages
+	usage := &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	if p.streak >= p.complyAfter {
+		p.streak = 0
+		return &providers.LLMResponse{
+			ToolCalls:    []providers.ToolCall{{ID: "call_1", Name: "exec", Arguments: map[string]any{"command": "nmap"}}},
+			FinishReason: "tool_calls",
+			Usage:        usage,
+		}, nil
+	}
+	p.streak++
+	return &providers.LLMResponse{Content: "You could use nmap for that.", FinishReason: "stop", Usage: usage}, nil
+}
+
+// toolRecordingProvider captures the tools of the last call.
+type toolRecordingProvider struct {
+	*mockProvider
+	tools *[]providers.ToolDefinition
+}
+
+func (r *toolRecordingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	*r.tools = tools
+	return r.mockProvider.Chat(ctx, messages, tools, model, opts)
+}
+
+// recordingProvider captures the messages of the last call.
+type recordingProvider struct {
+	*mockProvider
+	messages *[]providers.Message
+}
+
+func (r *recordingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	*r.messages = messages
+	return r.mockProvider.Chat(ctx, messages, tools, model, opts)
+}
+
+func TestTierRouter_EstimatePreflight(t *testing.T) {
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": newMockProvider(),
+	})
+
+	messages := []providers.Message{{Role: "user", Content: strings.Repeat("a", 1000)}}
+	pf, err := router.EstimatePreflight("fast", messages, nil, map[string]any{"max_tokens": 1000})
+	if err != nil {
+		t.Fatalf("EstimatePreflight() failed: %v", err)
+	}
+	if pf.Tier != "fast" || pf.Model != "claude-3-haiku" {
+		t.Errorf("routed to %s/%s, want fast/claude-3-haiku", pf.Tier, pf.Model)
+	}
+	if pf.PromptTokens != 404 {
+		t.Errorf("PromptTokens = %d, want 404", pf.PromptTokens)
+	}
+	// 404 input tokens at $0.25/M plus 1000 output tokens at $1.25/M
+	if want := 404*0.25/1e6 + 1000*1.25/1e6; pf.MaxCost < want-1e-12 || pf.MaxCost > want+1e-12 {
+		t.Errorf("MaxCost = %g, want %g", pf.MaxCost, want)
+	}
+	if router.GetCostTracker().GetTotalCost() != 0 {
+		t.Error("preflight estimate should not record cost")
+	}
+
+	// Routing policies see the same estimate before the request is sent
+	policy := &recordingPolicy{}
+	router.AddPolicy(policy)
+	if _, err := router.RouteChat(context.Background(), "fast", messages, nil, map[string]any{"max_tokens": 1000}, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if len(policy.pre) != 1 || policy.pre[0].EstimatedCost != pf.MaxCost {
+		t.Errorf("policy requests = %+v, want EstimatedCost %g", policy.pre, pf.MaxCost)
+	}
+}
+
+// supervisorStub is a goroutine-safe provider whose supervisor answers depend
+// on whether it is asked to validate or to answer directly.
+type supervisorStub struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *supervisorStub) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	p.calls[model]++
+	p.mu.Unlock()
+
+	usage := &providers.UsageInfo{PromptTokens: 100, CompletionTokens: 100, TotalTokens: 200}
+	switch {
+	case model == "claude-3-haiku":
+		return &providers.LLMResponse{Content: "Worker exploit plan", Usage: usage}, nil
+	case strings.Contains(messages[len(messages)-1].Content, "WORKER OUTPUT"):
+		return &providers.LLMResponse{Content: `{"decision": "reject", "approved": false, "confidence": 0.2}`, Usage: usage}, nil
+	default:
+		return &providers.LLMResponse{Content: "Supervisor exploit plan", Usage: usage}, nil
+	}
+}
+
+func (p *supervisorStub) GetDefaultModel() string {
+	return "claude-3-haiku"
+}
+
+func TestTierRouter_SpeculativeSupervisor(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxExtraCost    float64
+		wantSpeculative bool
+	}{
+		{"rejected worker output falls back to speculative answer", 0, true},
+		{"speculation skipped once budget is exhausted", 0.000001, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRoutingConfig()
+			cfg.Speculative = config.SpeculativeConfig{Enabled: true, MaxExtraCost: tt.maxExtraCost}
+			provider := &supervisorStub{calls: make(map[string]int)}
+			router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+				"claude-3-opus":  provider,
+			})
+
+			messages := []providers.Message{{Role: "user", Content: "Plan exploitation of the login form"}}
+			agentCtx := AgentContext{RequiresSupervision: true}
+			result, err := router.RouteWithSupervision(context.Background(), TaskExploitation, messages, nil, map[string]any{"max_tokens": 1000}, "test-session", agentCtx)
+
+			if !tt.wantSpeculative {
+				if err == nil {
+					t.Fatalf("expected high-stakes validation failure, got %+v", result)
+				}
+				if provider.calls["claude-3-opus"] != 1 {
+					t.Errorf("supervisor called %d times, want only the validation call", provider.calls["claude-3-opus"])
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RouteWithSupervision() failed: %v", err)
+			}
+			if !result.Speculative || result.FinalOutput != "Supervisor exploit plan" {
+				t.Errorf("result = %+v, want speculative supervisor answer", result)
+			}
+			if provider.calls["claude-3-opus"] != 2 {
+				t.Errorf("supervisor called %d times, want speculative + validation", provider.calls["claude-3-opus"])
+			}
+			if spent := router.supervisor.speculative.Spent("test-session"); spent <= 0 {
+				t.Errorf("speculative spend = %g, want reserved estimate", spent)
+			}
+		})
+	}
+}
+
+func TestTierRouter_CounterfactualSavings(t *testing.T) {
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": newMockProvider(),
+	})
+
+	// 10 input + 20 output tokens on haiku vs the same usage on opus
+	if _, err := router.RouteChat(context.Background(), "fast", []providers.Message{{Role: "user", Content: "hi"}}, nil, nil, "test-session"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+
+	session := router.GetCostTracker().GetSessionCost("test-session")
+	if session.BaselineTier != "powerful" {
+		t.Errorf("BaselineTier = %q, want powerful", session.BaselineTier)
+	}
+	wantBaseline := 10*15.0/1e6 + 20*75.0/1e6
+	if diff := session.BaselineCost - wantBaseline; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("BaselineCost = %g, want %g", session.BaselineCost, wantBaseline)
+	}
+
+	saved, percent := session.Savings()
+	if wantSaved := wantBaseline - session.TotalCost; saved < wantSaved-1e-12 || saved > wantSaved+1e-12 {
+		t.Errorf("saved = %g, want %g", saved, wantSaved)
+	}
+	if percent < 98 || percent > 99 {
+		t.Errorf("percent = %.2f, want ~98.3", percent)
+	}
+
+	report := router.GetCostTracker().FormatSessionReport("test-session")
+	if !strings.Contains(report, "Saved: $0.00 (98%) vs all calls on powerful") {
+		t.Errorf("report missing savings line:\n%s", report)
+	}
+}
+
+func TestTierRouter_CorrectionReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		decision     CorrectionDecision
+		wantOutput   string
+		wantValid    bool
+		wantFeedback bool
+	}{
+		{"accept keeps correction", CorrectionDecision{Action: ReviewAccepted}, "Port 22 runs OpenSSH 8.9", true, true},
+		{"reject restores worker output", CorrectionDecision{Action: ReviewRejected}, "Port 22 runs telnet", false, false},
+		{"edit uses operator text", CorrectionDecision{Action: ReviewEdited, Output: "Port 22 runs OpenSSH 8.9p1"}, "Port 22 runs OpenSSH 8.9p1", true, true},
+		{"dismiss keeps worker output", CorrectionDecision{Action: ReviewDismissed}, "Port 22 runs telnet", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newMockProvider()
+			provider.setResponse("claude-3-haiku", &providers.LLMResponse{
+				Content: "Port 22 runs telnet",
+				Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10},
+			})
+			provider.setResponse("claude-3-opus", &providers.LLMResponse{
+				Content: `{"decision": "approve", "approved": true, "confidence": 0.9, "corrections": ["service is ssh"], "final_output": "Port 22 runs OpenSSH 8.9"}`,
+				Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 10},
+			})
+			router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+				"claude-3-haiku": provider,
+				"claude-3-opus":  provider,
+			})
+
+			var reviewed CorrectionReview
+			router.SetCorrectionReviewer(func(ctx context.Context, review CorrectionReview) CorrectionDecision {
+				reviewed = review
+				return tt.decision
+			})
+
+			messages := []providers.Message{{Role: "user", Content: "Summarize the nmap scan"}}
+			result, err := router.RouteWithSupervision(context.Background(), "balanced", messages, nil, nil, "test-session", AgentContext{RequiresSupervision: true})
+			if err != nil {
+				t.Fatalf("RouteWithSupervision() failed: %v", err)
+			}
+
+			if reviewed.WorkerOutput != "Port 22 runs telnet" || reviewed.CorrectedOutput != "Port 22 runs OpenSSH 8.9" {
+				t.Errorf("review = %+v", reviewed)
+			}
+			if result.FinalOutput != tt.wantOutput || result.Validated != tt.wantValid {
+				t.Errorf("result output=%q validated=%v, want %q/%v", result.FinalOutput, result.Validated, tt.wantOutput, tt.wantValid)
+			}
+			if result.OperatorDecision != tt.decision.Action || result.WorkerOutput != "Port 22 runs telnet" {
+				t.Errorf("decision=%q worker=%q", result.OperatorDecision, result.WorkerOutput)
+			}
+
+			feedback, ok := result.FeedbackMessage()
+			if ok != tt.wantFeedback {
+				t.Fatalf("FeedbackMessage() ok = %v, want %v", ok, tt.wantFeedback)
+			}
+			if ok && (feedback.Role != "user" || !strings.Contains(feedback.Content, "- service is ssh")) {
+				t.Errorf("feedback = %+v", feedback)
+			}
+		})
+	}
+}
+
+func TestTierRouter_ExplainRoute(t *testing.T) {
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{})
+
+	ctx := AgentContext{TurnCount: 3, UserMessage: "Analyze and review this code for a quick win"}
+	ex := router.ExplainRoute(ctx)
+
+	if ex.Task != router.ClassifyTask(ctx) {
+		t.Errorf("ExplainRoute task %q differs from ClassifyTask %q", ex.Task, router.ClassifyTask(ctx))
+	}
+	if ex.Task != TaskAnalysis || ex.Rule != "analysis_keywords" {
+		t.Errorf("Expected analysis via analysis_keywords, got %q via %q", ex.Task, ex.Rule)
+	}
+	if ex.Tier != "balanced" || ex.Model != "claude-3-sonnet" || ex.TierReason != "listed in tier use_for" {
+		t.Errorf("Unexpected tier choice: %+v", ex)
+	}
+
+	// analyze(+1) + review(+1) + quick(-1) on the default complexity of 5
+	if len(ex.KeywordHits) != 3 || ex.Complexity != 6 {
+		t.Errorf("Expected 3 keyword hits and complexity 6, got %v and %d", ex.KeywordHits, ex.Complexity)
+	}
+
+	// code_review lost to analysis; the catch-all default always matches
+	if len(ex.Alternatives) != 2 || ex.Alternatives[0].Task != TaskCodeReview || ex.Alternatives[1].Rule != "default" {
+		t.Fatalf("Unexpected alternatives: %+v", ex.Alternatives)
+	}
+	if ex.Alternatives[0].Tier != "fast" || ex.Alternatives[0].Model != "claude-3-haiku" {
+		t.Errorf("Expected code review alternative on default tier, got %+v", ex.Alternatives[0])
+	}
+}
+
+func TestRateLimiter_QueuesAndCancels(t *testing.T) {
+	models := []config.ModelConfig{
+		{ModelName: "limited", Model: "openai/gpt-4o-mini", RPM: 2},
+		{ModelName: "free", Model: "anthropic/claude-3-haiku"},
+	}
+	if NewRateLimiter(models[1:], nil) != nil {
+		t.Fatal("Expected nil limiter when no limits are configured")
+	}
+
+	rl := NewRateLimiter(models, map[string]config.RateLimitConfig{"openai": {TPM: 1000}})
+
+	// The first requests fit in the buckets and do not wait
+	start := time.Now()
+	res, err := rl.Wait(context.Background(), "limited", 600)
+	if err != nil || time.Since(start) > 100*time.Millisecond {
+		t.Fatalf("Expected immediate admission, got err=%v after %v", err, time.Since(start))
+	}
+
+	// The provider TPM bucket is short 200 tokens; the caller queues until ctx ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rl.Wait(ctx, "limited", 600); err != context.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded while queued, got %v", err)
+	}
+
+	// Settling the first request at its real usage frees the over-estimate
+	res.settle(100)
+	if _, err := rl.Wait(context.Background(), "limited", 600); err != nil {
+		t.Fatalf("Expected admission after settling, got %v", err)
+	}
+
+	// RPM is now exhausted (2 admitted, the cancelled one was refunded)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rl.Wait(ctx, "limited", 1); err != context.DeadlineExceeded {
+		t.Errorf("Expected third request to queue on RPM, got %v", err)
+	}
+
+	// Unlimited models are never held back
+	if _, err := rl.Wait(context.Background(), "free", 1_000_000); err != nil {
+		t.Errorf("Unlimited model waited: %v", err)
+	}
+}
+
+func TestSpendGovernor_PacesSharedAndSessionBudgets(t *testing.T) {
+	if NewSpendGovernor(config.SpendGovernorConfig{}) != nil {
+		t.Fatal("Expected nil governor without a daily budget")
+	}
+
+	// $86.40/day refills $0.001 per second; the burst holds $1
+	g := NewSpendGovernor(config.SpendGovernorConfig{DailyBudget: 86.4, Burst: 1, SessionShare: 0.01})
+	now := time.Now()
+
+	// A session may take its $0.864 share at once
+	res, wait, err := g.reserve("runaway", 0.8, now)
+	if err != nil || wait != 0 {
+		t.Fatalf("Expected immediate admission, got wait=%v err=%v", wait, err)
+	}
+
+	// Beyond its share the session waits for its own bucket to refill
+	// (0.0001/s): $0.036 short is a 360s wait, over the 60s default
+	if _, _, err := g.reserve("runaway", 0.1, now); !errors.Is(err, ErrSpendBudgetExhausted) {
+		t.Fatalf("Expected session budget refusal, got %v", err)
+	}
+
+	// Other sessions still draw on the shared $0.20 left
+	if _, wait, err := g.reserve("other", 0.15, now); err != nil || wait != 0 {
+		t.Fatalf("Expected other session admitted, got wait=%v err=%v", wait, err)
+	}
+
+	// The shared bucket is $0.01 short: paced 10s, not refused
+	_, wait, err = g.reserve("third", 0.06, now)
+	if err != nil || wait < 9*time.Second || wait > 10*time.Second {
+		t.Fatalf("Expected 10s pacing on the shared budget, got wait=%v err=%v", wait, err)
+	}
+
+	// Settling the first request at its real cost frees the over-estimate
+	res.settle(0.2)
+	if _, wait, err := g.reserve("runaway", 0.1, now); err != nil || wait != 0 {
+		t.Errorf("Expected admission after settling, got wait=%v err=%v", wait, err)
+	}
+}
+
+func TestTierRouter_SpendGovernorRefusesOverBudget(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.EnableSupervision = false
+	cfg.SpendGovernor = config.SpendGovernorConfig{DailyBudget: 0.001, MaxWaitSeconds: 1}
+	provider := newMockProvider()
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": provider,
+	})
+
+	// The first call empties the burst; the next would wait about an hour
+	messages := []providers.Message{{Role: "user", Content: "hello"}}
+	options := map[string]any{"max_tokens": 2000}
+	if _, err := router.RouteChat(context.Background(), TaskFormatting, messages, nil, options, "session"); err != nil {
+		t.Fatalf("First RouteChat() failed: %v", err)
+	}
+	_, err := router.RouteChat(context.Background(), TaskFormatting, messages, nil, options, "session")
+	if !errors.Is(err, ErrSpendBudgetExhausted) {
+		t.Fatalf("RouteChat() error = %v, want ErrSpendBudgetExhausted", err)
+	}
+	if provider.getCallCount("claude-3-haiku") != 1 {
+		t.Error("Provider called despite exhausted spend budget")
+	}
+}
+
+// delayProvider answers after a per-model delay, honouring cancellation.
+type delayProvider struct {
+	delays map[string]time.Duration
+}
+
+func (p *delayProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]any) (*providers.LLMResponse, error) {
+	select {
+	case <-time.After(p.delays[model]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &providers.LLMResponse{
+		Content: "answer from " + model,
+		Usage:   &providers.UsageInfo{PromptTokens: 1000, CompletionTokens: 100},
+	}, nil
+}
+
+func (p *delayProvider) GetDefaultModel() string {
+	return "claude-3-haiku"
+}
+
+func TestTierRouter_HedgedRequests(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.EnableSupervision = false
+	cfg.Hedging = config.HedgingConfig{Enabled: true, MinSamples: 3}
+	fast := cfg.Tiers["fast"]
+	fast.HedgeModel = "claude-3-sonnet"
+	cfg.Tiers["fast"] = fast
+
+	provider := &delayProvider{delays: map[string]time.Duration{
+		"claude-3-haiku":  time.Millisecond,
+		"claude-3-sonnet": time.Millisecond,
+	}}
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "Summarize"}}
+
+	// Warm up the primary's latency window; no hedging without a p95
+	for i := 0; i < 3; i++ {
+		if _, err := router.RouteChat(context.Background(), "fast", messages, nil, nil, "hedge"); err != nil {
+			t.Fatalf("RouteChat() failed: %v", err)
+		}
+	}
+	if session := router.GetCostTracker().GetSessionCost("hedge"); session.Hedging.Requests != 0 {
+		t.Errorf("Expected no hedging-eligible requests during warm-up, got %d", session.Hedging.Requests)
+	}
+
+	// The primary slows down far past its p95: the hedge answers first
+	provider.delays["claude-3-haiku"] = 2 * time.Second
+	start := time.Now()
+	resp, err := router.RouteChat(context.Background(), "fast", messages, nil, nil, "hedge")
+	if err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if resp.Content != "answer from claude-3-sonnet" {
+		t.Errorf("Expected hedge to win, got %q", resp.Content)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Hedged request took %v; slow primary was not raced", time.Since(start))
+	}
+
+	session := router.GetCostTracker().GetSessionCost("hedge")
+	h := session.Hedging
+	if h.Requests != 1 || h.Hedged != 1 || h.HedgeWins != 1 || h.HedgeRate() != 1 {
+		t.Errorf("Unexpected hedging metrics: %+v", h)
+	}
+	if h.WastedCost <= 0 {
+		t.Error("Expected the cancelled primary's prompt to be recorded as wasted cost")
+	}
+	if session.ByTier["balanced"] == nil || session.ByTier["balanced"].Calls != 1 {
+		t.Errorf("Expected the winning call billed to the hedge model's tier, got %+v", session.ByTier)
+	}
+	if !strings.Contains(router.GetCostTracker().FormatSessionReport("hedge"), "1 of 1 requests hedged") {
+		t.Error("Session report missing hedging summary")
+	}
+}
+
+func TestTierRouter_ReloadConfig(t *testing.T) {
+	provider := newMockProvider()
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "Is this worth a closer look?"}}
+
+	// Triage is claimed by no tier, so it falls back to the default tier.
+	if _, err := router.RouteChat(context.Background(), TaskTriage, messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if err := router.SetDefaultTier("balanced"); err != nil {
+		t.Fatalf("SetDefaultTier() failed: %v", err)
+	}
+	if _, err := router.RouteChat(context.Background(), TaskTriage, messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() after switch failed: %v", err)
+	}
+	if provider.getCallCount("claude-3-haiku") != 1 || provider.getCallCount("claude-3-sonnet") != 1 {
+		t.Errorf("calls: haiku=%d sonnet=%d, want 1 each",
+			provider.getCallCount("claude-3-haiku"), provider.getCallCount("claude-3-sonnet"))
+	}
+
+	if err := router.SetDefaultTier("missing"); err == nil {
+		t.Error("expected error switching to an undefined tier")
+	}
+	degraded := testRoutingConfig()
+	degraded.Tiers["fast"] = config.TierConfig{ModelName: "unknown-model"}
+	if err := router.ReloadConfig(degraded); err == nil {
+		t.Error("expected error for a tier whose model has no provider")
+	}
+	if got := router.DefaultTier(); got != "balanced" {
+		t.Errorf("DefaultTier() = %q after rejected reloads, want balanced", got)
+	}
+	if got := strings.Join(router.TierNames(), ","); got != "balanced,fast,powerful" {
+		t.Errorf("TierNames() = %s", got)
+	}
+}
+
+// closingProvider records whether it was closed.
+type closingProvider struct {
+	*mockProvider
+	closed bool
+}
+
+func (c *closingProvider) Close() { c.closed = true }
+
+func TestTierRouter_SetTierModel(t *testing.T) {
+	startup := newMockProvider()
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": startup,
+		"gpt-4":          startup,
+	})
+	var created []*closingProvider
+	router.newProvider = func(cfg *config.ModelConfig) (providers.LLMProvider, string, error) {
+		if cfg.ModelName == "gpt-4" {
+			p := &closingProvider{mockProvider: newMockProvider()}
+			created = append(created, p)
+			return p, "gpt-4-0613", nil
+		}
+		return nil, "", errors.New("unreachable endpoint")
+	}
+	var switched []string
+	router.SetModelSwitchHandler(func(tierName, modelName string) {
+		switched = append(switched, tierName+"="+modelName)
+	})
+	messages := []providers.Message{{Role: "user", Content: "Is this worth a closer look?"}}
+
+	if err := router.SetTierModel("fast", "gpt-4"); err != nil {
+		t.Fatalf("SetTierModel() failed: %v", err)
+	}
+	if model, _ := router.TierModel("fast"); model != "gpt-4" {
+		t.Errorf("TierModel(fast) = %q, want gpt-4", model)
+	}
+	if _, err := router.RouteChat(context.Background(), TaskTriage, messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	// The re-created provider is sent the model_list identifier
+	if created[0].getCallCount("gpt-4-0613") != 1 || startup.getCallCount("gpt-4") != 0 {
+		t.Errorf("calls: new=%d startup=%d, want the new provider to get the model ID",
+			created[0].getCallCount("gpt-4-0613"), startup.getCallCount("gpt-4"))
+	}
+
+	// Re-initializing closes the provider the router created before
+	if err := router.SetTierModel("fast", "gpt-4"); err != nil {
+		t.Fatalf("SetTierModel() re-init failed: %v", err)
+	}
+	if len(created) != 2 || !created[0].closed || created[1].closed {
+		t.Errorf("want the first swapped provider closed and the second open")
+	}
+	if got := strings.Join(switched, ","); got != "fast=gpt-4,fast=gpt-4" {
+		t.Errorf("switch events = %s", got)
+	}
+
+	if err := router.SetTierModel("fast", "claude-3-opus"); err == nil {
+		t.Error("expected error when the provider cannot be created")
+	}
+	if err := router.SetTierModel("fast", "missing"); err == nil {
+		t.Error("expected error for a model not in model_list")
+	}
+	if err := router.SetTierModel("missing", "gpt-4"); err == nil {
+		t.Error("expected error for an undefined tier")
+	}
+	if model, _ := router.TierModel("fast"); model != "gpt-4" {
+		t.Errorf("TierModel(fast) = %q after failed swaps, want gpt-4", model)
+	}
+	if model, _ := router.TierModel("balanced"); model != "claude-3-sonnet" {
+		t.Errorf("TierModel(balanced) = %q, want it unchanged", model)
+	}
+}
+
+// recordingPolicy returns a fixed decision and records the hooks it saw.
+type recordingPolicy struct {
+	decision PolicyDecision
+	pre      []PolicyRequest
+	post     []PolicyRequest
+	errors   []error
+}
+
+func (p *recordingPolicy) PreRoute(ctx context.Context, req PolicyRequest) (PolicyDecision, error) {
+	p.pre = append(p.pre, req)
+	return p.decision, nil
+}
+
+func (p *recordingPolicy) PostRoute(ctx context.Context, req PolicyRequest, resp *providers.LLMResponse) {
+	p.post = append(p.post, req)
+}
+
+func (p *recordingPolicy) OnError(ctx context.Context, req PolicyRequest, err error) {
+	p.errors = append(p.errors, err)
+}
+
+func TestTierRouter_RoutingPolicies(t *testing.T) {
+	provider := newMockProvider()
+	providerMap := map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+		"claude-3-opus":   provider,
+	}
+	messages := []providers.Message{{Role: "user", Content: "Analyze these results"}}
+
+	// Vetoing the selected tier falls back to the default tier.
+	router := NewTierRouter(testRoutingConfig(), testModelList(), providerMap)
+	veto := &recordingPolicy{decision: PolicyDecision{
+		VetoTiers:   []string{"balanced"},
+		Annotations: map[string]string{"policy": "no-sonnet"},
+	}}
+	router.AddPolicy(veto)
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if provider.getCallCount("claude-3-haiku") != 1 || provider.getCallCount("claude-3-sonnet") != 0 {
+		t.Errorf("vetoed tier was used: haiku=%d sonnet=%d",
+			provider.getCallCount("claude-3-haiku"), provider.getCallCount("claude-3-sonnet"))
+	}
+	if len(veto.post) != 1 || veto.post[0].Tier != "fast" || veto.post[0].Annotations["policy"] != "no-sonnet" {
+		t.Errorf("PostRoute saw %+v", veto.post)
+	}
+
+	// A later policy forcing a model wins over the tier's model.
+	router.AddPolicy(&recordingPolicy{decision: PolicyDecision{ForceModel: "claude-3-opus"}})
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "s"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if provider.getCallCount("claude-3-opus") != 1 {
+		t.Errorf("forced model not used: opus=%d", provider.getCallCount("claude-3-opus"))
+	}
+
+	// Errors reach OnError; vetoing every tier rejects the request.
+	provider.setError("claude-3-opus", errors.New("overloaded"))
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "s"); err == nil {
+		t.Error("expected provider error")
+	}
+	if len(veto.errors) != 1 {
+		t.Errorf("OnError calls = %d, want 1", len(veto.errors))
+	}
+	router.AddPolicy(&recordingPolicy{decision: PolicyDecision{VetoTiers: []string{"fast", "powerful"}}})
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "s"); err == nil {
+		t.Error("expected error when every tier is vetoed")
+	}
+}
+
+func TestExecPolicy(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	policy, err := NewExecPolicy(config.RoutingPolicyConfig{
+		Name: "after-hours",
+		// Force the cheap tier for analysis only.
+		Command: []string{"sh", "-c", `grep -q '"task":"analysis"' && echo '{"force_tier":"fast","annotations":{"why":"budget"}}' || true`},
+	})
+	if err != nil {
+		t.Fatalf("NewExecPolicy() failed: %v", err)
+	}
+
+	decision, err := policy.PreRoute(context.Background(), PolicyRequest{Task: TaskAnalysis, Tier: "balanced"})
+	if err != nil {
+		t.Fatalf("PreRoute() failed: %v", err)
+	}
+	if decision.ForceTier != "fast" || decision.Annotations["why"] != "budget" {
+		t.Errorf("decision = %+v", decision)
+	}
+	if decision, err := policy.PreRoute(context.Background(), PolicyRequest{Task: TaskParsing}); err != nil || decision.ForceTier != "" {
+		t.Errorf("PreRoute(parsing) = %+v, %v; want no change", decision, err)
+	}
+
+	failing, _ := NewExecPolicy(config.RoutingPolicyConfig{Command: []string{"sh", "-c", "echo denied >&2; exit 1"}})
+	if _, err := failing.PreRoute(context.Background(), PolicyRequest{}); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("PreRoute() error = %v, want script stderr", err)
+	}
+	if _, err := NewExecPolicy(config.RoutingPolicyConfig{Command: []string{"true"}, Hooks: []string{"before"}}); err == nil {
+		t.Error("expected error for unknown hook")
+	}
+}
+
+// mockEmbedder returns a fixed-size vector per input.
+type mockEmbedder struct {
+	mockProvider
+	model string
+}
+
+func (m *mockEmbedder) Embed(ctx context.Context, inputs []string, model string) (*providers.EmbeddingResponse, error) {
+	m.model = model
+	resp := &providers.EmbeddingResponse{Usage: &providers.UsageInfo{PromptTokens: 500_000}}
+	for range inputs {
+		resp.Embeddings = append(resp.Embeddings, []float32{1, 0, 0})
+	}
+	return resp, nil
+}
+
+func TestTierRouter_Embed(t *testing.T) {
+	routingCfg := testRoutingConfig()
+	router := NewTierRouter(routingCfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku": newMockProvider(),
+	})
+	if _, err := router.Embed(context.Background(), []string{"x"}, "s"); err == nil {
+		t.Error("expected error without an embeddings model")
+	}
+
+	embedder := &mockEmbedder{mockProvider: *newMockProvider()}
+	routingCfg.Embeddings = config.EmbeddingsConfig{ModelName: "embed-small", CostPerM: 0.02}
+	modelList := append(testModelList(), config.ModelConfig{ModelName: "embed-small", Model: "openai/text-embedding-3-small"})
+	router = NewTierRouter(routingCfg, modelList, map[string]providers.LLMProvider{"embed-small": embedder})
+
+	resp, err := router.Embed(context.Background(), []string{"port 22 open", "port 80 open"}, "s")
+	if err != nil {
+		t.Fatalf("Embed() failed: %v", err)
+	}
+	if len(resp.Embeddings) != 2 || embedder.model != "text-embedding-3-small" {
+		t.Errorf("embeddings = %d, model = %q", len(resp.Embeddings), embedder.model)
+	}
+
+	session := router.GetCostTracker().GetSessionCost("s")
+	if session == nil || session.ByModel["embed-small"] == nil {
+		t.Fatal("embedding spend not recorded")
+	}
+	if got := session.TotalCost; got < 0.0099 || got > 0.0101 {
+		t.Errorf("total cost = %f, want 0.01", got)
+	}
+	if session.ByPurpose[PurposeEmbedding].Calls != 1 {
+		t.Errorf("embedding purpose = %+v", session.ByPurpose[PurposeEmbedding])
+	}
+}
+
+func TestEstimateUsageCost_CachedAndReasoningRates(t *testing.T) {
+	tierCfg := config.TierConfig{CostPerM: config.CostPerMInfo{
+		Input:       3,
+		Output:      15,
+		CachedInput: 0.3,
+		CacheWrite:  3.75,
+		Reasoning:   15,
+	}}
+	usage := providers.UsageInfo{
+		PromptTokens:       1_000_000,
+		CompletionTokens:   1_000_000,
+		CachedPromptTokens: 600_000,
+		CacheWriteTokens:   200_000,
+		ReasoningTokens:    400_000,
+	}
+
+	// 0.2M uncached at $3, 0.6M cached at $0.30, 0.2M written at $3.75, 1M output at $15
+	want := 0.6 + 0.18 + 0.75 + 15.0
+	if got := EstimateUsageCost(tierCfg, usage); math.Abs(got-want) > 1e-9 {
+		t.Errorf("EstimateUsageCost() = %v, want %v", got, want)
+	}
+
+	// Without cache rates every prompt token bills at the input rate
+	plain := config.TierConfig{CostPerM: config.CostPerMInfo{Input: 3, Output: 15}}
+	if got, want := EstimateUsageCost(plain, usage), EstimateCallCost(plain, 1_000_000, 1_000_000); math.Abs(got-want) > 1e-9 {
+		t.Errorf("EstimateUsageCost() without cache rates = %v, want %v", got, want)
+	}
+
+	ct := NewCostTracker()
+	ct.Record("s", "sonnet", "heavy", tierCfg, usage, time.Second, CostAttribution{})
+	model := ct.GetSessionCost("s").ByModel["sonnet"]
+	if model.CachedTokens != 600_000 || model.ReasoningTokens != 400_000 {
+		t.Errorf("ModelCost = %+v, want cached and reasoning tokens", model)
+	}
+	if report := ct.FormatSessionReport("s"); !strings.Contains(report, "Cached input tokens: 600000 (60%)") {
+		t.Errorf("report missing cached tokens:\n%s", report)
+	}
+
+	// Uncached the call would have cost $3 + $15
+	pc := ct.GetSessionCost("s").PromptCache
+	if pc.Hits != 1 || pc.HitRate() != 0.6 || math.Abs(pc.SavedCost-(18-want)) > 1e-9 {
+		t.Errorf("PromptCache = %+v, want one hit at 60%% saving %v", pc, 18-want)
+	}
+	if report := ct.FormatSessionReport("s"); !strings.Contains(report, "Prompt cache: 60% of 1000000 prompt tokens read from cache (1 of 1 calls hit)") {
+		t.Errorf("report missing prompt cache summary:\n%s", report)
+	}
+}
+
+func TestTierRouter_PromptCacheKey(t *testing.T) {
+	provider := newMockProvider()
+	router := NewTierRouter(testRoutingConfig(), testModelList(), map[string]providers.LLMProvider{
+		"claude-3-sonnet": provider,
+	})
+	messages := []providers.Message{{Role: "user", Content: "analyze"}}
+
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "session-1"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	want := providers.PromptCacheKey("routed", "session-1")
+	if got := provider.options["claude-3-sonnet"]["prompt_cache_key"]; got != want {
+		t.Errorf("prompt_cache_key = %v, want %q", got, want)
+	}
+
+	// A key set by the caller is kept
+	opts := map[string]any{"prompt_cache_key": "agent-main"}
+	if _, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, opts, "session-1"); err != nil {
+		t.Fatalf("RouteChat() failed: %v", err)
+	}
+	if got := provider.options["claude-3-sonnet"]["prompt_cache_key"]; got != "agent-main" {
+		t.Errorf("prompt_cache_key = %v, want the caller's key", got)
+	}
+}
+
+func TestTierRouter_OutputPipeline(t *testing.T) {
+	cfg := testRoutingConfig()
+	cfg.Tiers["fast"] = config.TierConfig{ModelName: "claude-3-haiku", UseFor: []string{"parsing"}}
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true}
+	cfg.Pipelines = map[string][]config.PipelineStageConfig{
+		"ports": {
+			{Type: "strip_markdown"},
+			{Type: "extract_json"},
+			{Type: "validate_schema", Schema: map[string]any{
+				"type":     "object",
+				"required": []any{"ports"},
+				"properties": map[string]any{
+					"ports": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+				},
+			}},
+			{Type: "store_artifact"},
+		},
+	}
+	cfg.TaskOverrides = map[string]config.TaskOverride{
+		"parsing":    {Pipeline: "ports"},
+		"formatting": {Pipeline: "missing"},
+	}
+
+	workspace := t.TempDir()
+	provider := newMockProvider()
+	raw := "Here are the open ports:\n\n```json\n{\"ports\": [22, 443]}\n```"
+	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
+		Content: raw,
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+	})
+	router := NewTierRouter(cfg, testModelList(), map[string]providers.LLMProvider{
+		"claude-3-haiku":  provider,
+		"claude-3-sonnet": provider,
+	})
+	router.SetPipelines(pipeline.Build(cfg.Pipelines, workspace))
+
+	messages := []providers.Message{{Role: "user", Content: "parse this nmap output"}}
+	opts := map[string]any{"temperature": 0.0}
+	for _, run := range []string{"fresh", "cached"} {
+		resp, err := router.RouteChat(context.Background(), TaskParsing, messages, nil, opts, "test-session")
+		if err != nil {
+			t.Fatalf("%s: RouteChat() failed: %v", run, err)
+		}
+		if resp.Content != `{"ports": [22, 443]}` {
+			t.Errorf("%s: content = %q", run, resp.Content)
+		}
+		if len(resp.Artifacts) != 1 || !strings.HasPrefix(resp.Artifacts[0], workspace) || !strings.HasSuffix(resp.Artifacts[0], ".json") {
+			t.Errorf("%s: artifacts = %v", run, resp.Artifacts)
+		}
+	}
+	if got := provider.getCallCount("claude-3-haiku"); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+	if provider.responses["claude-3-haiku"].Content != raw {
+		t.Error("the provider's response was modified")
+	}
+
+	// Output that doesn't validate is an error, not a silently bad parse
+	provider.setResponse("claude-3-haiku", &providers.LLMResponse{
+		Content: `{"ports": ["ssh"]}`,
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+	})
+	_, err := router.RouteChat(context.Background(), TaskParsing, messages, nil, map[string]any{"temperature": 0.5}, "test-session")
+	if err == nil || !strings.Contains(err.Error(), "pipeline ports: validate_schema: $.ports[0]: expected integer, got string") {
+		t.Errorf("invalid output error = %v", err)
+	}
+
+	if _, err := router.RouteChat(context.Background(), TaskFormatting, messages, nil, nil, "test-session"); err == nil ||
+		!strings.Contains(err.Error(), "output pipeline missing, which isn't configured") {
+		t.Errorf("unknown pipeline error = %v", err)
+	}
+	// Task types without a pipeline get the response as it is
+	if resp, err := router.RouteChat(context.Background(), TaskAnalysis, messages, nil, nil, "test-session"); err != nil || resp.Content != "Mock response" {
+		t.Errorf("analysis response = %+v, %v", resp, err)
+	}
+}
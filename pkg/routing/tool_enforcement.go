@@ -0,0 +1,100 @@
+package routing
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// toolCallReminder is appended after a prose answer on an enforced turn.
+const toolCallReminder = "Your last reply did not call a tool. This turn must be answered " +
+	"with a tool call only: pick one of the available tools and call it, with no prose."
+
+// toolEnforced reports whether a turn of taskType offering tools must be
+// answered with a tool call.
+func (tr *TierRouter) toolEnforced(taskType TaskType, tools []providers.ToolDefinition) bool {
+	cfg := tr.routingConfig().ToolEnforcement
+	if !cfg.Enabled || len(tools) == 0 {
+		return false
+	}
+	if len(cfg.TaskTypes) == 0 {
+		return taskType == TaskToolSelection
+	}
+	return slices.Contains(cfg.TaskTypes, string(taskType))
+}
+
+// withToolEnforcement returns a copy of options that requires a tool call and
+// carries the configured stop sequences.
+func withToolEnforcement(options map[string]any, cfg config.ToolEnforcementConfig) map[string]any {
+	merged := make(map[string]any, len(options)+2)
+	maps.Copy(merged, options)
+	merged["tool_choice"] = "required"
+	if len(cfg.StopSequences) > 0 {
+		merged["stop"] = cfg.StopSequences
+	}
+	return merged
+}
+
+// enforceToolCall retries an enforced turn whose response carries no tool
+// call, reminding the model to call a tool. Providers that ignore
+// tool_choice (or emit prose anyway) get up to max_retries more attempts on
+// the same tier. It returns the first response with a tool call, or the last
+// violating one once the retries are spent, and whether the turn complied.
+func (tr *TierRouter) enforceToolCall(
+	ctx context.Context,
+	taskType TaskType,
+	provider providers.LLMProvider,
+	tierName string,
+	tierCfg *config.TierConfig,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+	resp *providers.LLMResponse,
+) (*providers.LLMResponse, bool) {
+	cfg := tr.routingConfig().ToolEnforcement
+	retries := cfg.MaxRetries
+	if retries == 0 {
+		retries = 1
+	}
+
+	violations, sent := 0, 0
+	for len(resp.ToolCalls) == 0 {
+		violations++
+		logger.WarnCF(tr.component, "Tool-only turn answered without a tool call", map[string]any{
+			"task":    taskType,
+			"tier":    tierName,
+			"model":   tierCfg.ModelName,
+			"attempt": violations,
+		})
+		if sent >= retries {
+			break
+		}
+
+		messages = append(slices.Clip(messages),
+			providers.Message{Role: "assistant", Content: resp.Content},
+			providers.Message{Role: "user", Content: toolCallReminder},
+		)
+		sent++
+		retry, elapsed, retryTier, retryCfg, err := tr.chatWithHedge(ctx, provider, tierName, tierCfg, messages, tools, options, sessionKey)
+		if err != nil {
+			logger.WarnCF(tr.component, "Tool enforcement retry failed", map[string]any{
+				"task":  taskType,
+				"model": tierCfg.ModelName,
+				"error": err.Error(),
+			})
+			break
+		}
+		retry.Model = retryCfg.ModelName
+		tr.costs.Record(sessionKey, retryCfg.ModelName, retryTier, *retryCfg, responseUsage(retry), elapsed, costAttributionFrom(ctx))
+		resp = retry
+	}
+
+	complied := len(resp.ToolCalls) > 0
+	tr.costs.RecordToolEnforcement(sessionKey, violations, sent, complied)
+	return resp, complied
+}
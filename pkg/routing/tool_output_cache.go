@@ -0,0 +1,102 @@
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// defaultToolOutputCacheTTL keeps parses long enough to survive a rerun or a
+// resumed mission.
+const defaultToolOutputCacheTTL = 24 * time.Hour
+
+type toolOutputHashKey struct{}
+
+// HashToolOutput returns the cache identity of a tool output. Qualifiers
+// distinguish parses of the same output that must not share a result, such
+// as the tool name or the expected artifact type.
+func HashToolOutput(output string, qualifiers ...string) string {
+	h := sha256.New()
+	for _, q := range qualifiers {
+		h.Write([]byte(q))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(output))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithToolOutputHash marks the request routed with ctx as a parse or summary
+// of the tool output with the given HashToolOutput hash. RouteChat then
+// serves repeated parsing and summary requests for that output from cache.
+func WithToolOutputHash(ctx context.Context, hash string) context.Context {
+	if hash == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, toolOutputHashKey{}, hash)
+}
+
+// toolOutputCacheKey returns the tool output cache key for a request, or ""
+// when the request is not a parse or summary of a hashed tool output. The
+// model is deliberately not part of the key: a parse is reusable whichever
+// tier produced it.
+func toolOutputCacheKey(ctx context.Context, taskType TaskType, tools []providers.ToolDefinition) string {
+	if taskType != TaskParsing && taskType != TaskSummary {
+		return ""
+	}
+	hash, _ := ctx.Value(toolOutputHashKey{}).(string)
+	if hash == "" {
+		return ""
+	}
+	toolsJSON, err := json.Marshal(tools)
+	if err != nil {
+		return ""
+	}
+	toolsHash := sha256.Sum256(toolsJSON)
+
+	h := sha256.New()
+	h.Write([]byte(taskType))
+	h.Write([]byte{0})
+	h.Write([]byte(hash))
+	h.Write(toolsHash[:])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResponse looks up a request in the tool output cache, then the
+// response cache, returning the key to store a fresh response under.
+func (tr *TierRouter) cachedResponse(
+	ctx context.Context,
+	taskType TaskType,
+	tierCfg *config.TierConfig,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) (*providers.LLMResponse, *ResponseCache, string, bool) {
+	if key := toolOutputCacheKey(ctx, taskType, tools); key != "" && tr.toolOutputCache != nil {
+		resp, ok := tr.toolOutputCache.Get(key)
+		return resp, tr.toolOutputCache, key, ok
+	}
+	if tr.responseCache == nil {
+		return nil, nil, "", false
+	}
+	key := tr.responseKey(tierCfg.ModelName, messages, tools, options, sessionKey)
+	resp, ok := tr.responseCache.Get(key)
+	return resp, tr.responseCache, key, ok
+}
+
+// responseKey is the response cache key for a request answered by model,
+// with the preamble that model gets
+func (tr *TierRouter) responseKey(
+	model string,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	options map[string]any,
+	sessionKey string,
+) string {
+	return responseCacheKey(model, tr.withPreamble(sessionKey, model, messages), tools, options)
+}
@@ -0,0 +1,243 @@
+// Package secrets keeps session-scoped variables such as AUTH_TOKEN or COOKIE
+// encrypted at rest. Tools receive the values through templating so they
+// never have to appear in model context.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// keySize is the AES-256 key length in bytes.
+const keySize = 32
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidName reports whether name can be used as a variable name: letters,
+// digits and underscores, not starting with a digit.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// DefaultKeyPath is where the encryption key lives, outside any workspace so
+// the encrypted store and its key are not copied together.
+func DefaultKeyPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".picoclaw", "session_vars.key")
+}
+
+// Store holds variables per session key, persisted as a single AES-GCM
+// encrypted file.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	keyPath string
+	loaded  bool
+	vars    map[string]map[string]string // session -> name -> value
+}
+
+// NewStore creates a store backed by the encrypted file at path, using the
+// key at keyPath (created on first write). Nothing is read until first use.
+func NewStore(path, keyPath string) *Store {
+	return &Store{
+		path:    path,
+		keyPath: keyPath,
+		vars:    make(map[string]map[string]string),
+	}
+}
+
+// Set stores a variable for a session.
+func (s *Store) Set(session, name, value string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if s.vars[session] == nil {
+		s.vars[session] = make(map[string]string)
+	}
+	s.vars[session][name] = value
+	return s.save()
+}
+
+// Unset removes a variable, reporting whether it existed.
+func (s *Store) Unset(session, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	if _, ok := s.vars[session][name]; !ok {
+		return false, nil
+	}
+	delete(s.vars[session], name)
+	if len(s.vars[session]) == 0 {
+		delete(s.vars, session)
+	}
+	return true, s.save()
+}
+
+// Names returns the session's variable names, sorted.
+func (s *Store) Names(session string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.load() != nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.vars[session]))
+	for name := range s.vars[session] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Vars returns a copy of the session's variables. A store that cannot be
+// decrypted yields no variables.
+func (s *Store) Vars(session string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.load() != nil || len(s.vars[session]) == 0 {
+		return nil
+	}
+	vars := make(map[string]string, len(s.vars[session]))
+	for name, value := range s.vars[session] {
+		vars[name] = value
+	}
+	return vars
+}
+
+// load decrypts the store file once. A missing file is an empty store.
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session variables: %w", err)
+	}
+
+	key, err := s.readKey(false)
+	if err != nil {
+		return err
+	}
+	plain, err := decrypt(key, data)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt session variables: %w", err)
+	}
+	if err := json.Unmarshal(plain, &s.vars); err != nil {
+		return fmt.Errorf("failed to parse session variables: %w", err)
+	}
+	s.loaded = true
+	return nil
+}
+
+// save encrypts and atomically rewrites the store file.
+func (s *Store) save() error {
+	key, err := s.readKey(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(s.vars)
+	if err != nil {
+		return err
+	}
+	data, err := encrypt(key, plain)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create session variables directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write session variables: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write session variables: %w", err)
+	}
+	return nil
+}
+
+// readKey loads the encryption key, generating it when create is set and
+// no key exists yet.
+func (s *Store) readKey(create bool) ([]byte, error) {
+	key, err := os.ReadFile(s.keyPath)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("session variables key %s has invalid length", s.keyPath)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("failed to read session variables key: %w", err)
+	}
+
+	key = make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate session variables key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(s.keyPath, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write session variables key: %w", err)
+	}
+	return key, nil
+}
+
+func encrypt(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_PersistsEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state", "session_vars.enc")
+	keyPath := filepath.Join(dir, "key")
+
+	store := NewStore(path, keyPath)
+	if err := store.Set("s1", "AUTH_TOKEN", "eyJhbGciOiJIUzI1NiJ9.secret"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := store.Set("s2", "COOKIE", "sid=abc123"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("store file not written: %v", err)
+	}
+	if bytes.Contains(data, []byte("eyJhbGciOiJIUzI1NiJ9")) || bytes.Contains(data, []byte("AUTH_TOKEN")) {
+		t.Error("store file contains plaintext")
+	}
+
+	// A fresh store with the same key reads the values back, scoped per session
+	reloaded := NewStore(path, keyPath)
+	if got := reloaded.Vars("s1")["AUTH_TOKEN"]; got != "eyJhbGciOiJIUzI1NiJ9.secret" {
+		t.Errorf("Vars(s1)[AUTH_TOKEN] = %q", got)
+	}
+	if _, ok := reloaded.Vars("s1")["COOKIE"]; ok {
+		t.Error("COOKIE leaked across sessions")
+	}
+
+	// A different key cannot decrypt it
+	if err := os.WriteFile(keyPath, bytes.Repeat([]byte{1}, keySize), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if vars := NewStore(path, keyPath).Vars("s1"); vars != nil {
+		t.Errorf("expected no variables with the wrong key, got %v", vars)
+	}
+}
+
+func TestStore_SetUnset(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "vars.enc"), filepath.Join(dir, "key"))
+
+	if err := store.Set("s", "1BAD", "x"); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+
+	store.Set("s", "COOKIE", "sid=1")
+	store.Set("s", "AUTH_TOKEN", "t")
+	if names := store.Names("s"); len(names) != 2 || names[0] != "AUTH_TOKEN" {
+		t.Errorf("Names() = %v, want sorted [AUTH_TOKEN COOKIE]", names)
+	}
+
+	removed, err := store.Unset("s", "COOKIE")
+	if err != nil || !removed {
+		t.Fatalf("Unset() = %v, %v", removed, err)
+	}
+	if removed, _ := store.Unset("s", "COOKIE"); removed {
+		t.Error("Unset() of missing variable reported removal")
+	}
+	if names := NewStore(filepath.Join(dir, "vars.enc"), filepath.Join(dir, "key")).Names("s"); len(names) != 1 {
+		t.Errorf("expected one variable after reload, got %v", names)
+	}
+}
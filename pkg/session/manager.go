@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,15 @@ type Session struct {
 	Updated  time.Time           `json:"updated"`
 }
 
+// SessionInfo describes a session without its message history.
+type SessionInfo struct {
+	Key          string
+	Summary      string
+	MessageCount int
+	Created      time.Time
+	Updated      time.Time
+}
+
 type SessionManager struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
@@ -103,6 +113,27 @@ func (sm *SessionManager) GetHistory(key string) []providers.Message {
 	return history
 }
 
+// List returns every session, most recently updated first.
+func (sm *SessionManager) List() []SessionInfo {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(sm.sessions))
+	for _, session := range sm.sessions {
+		infos = append(infos, SessionInfo{
+			Key:          session.Key,
+			Summary:      session.Summary,
+			MessageCount: len(session.Messages),
+			Created:      session.Created,
+			Updated:      session.Updated,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Updated.After(infos[j].Updated)
+	})
+	return infos
+}
+
 func (sm *SessionManager) GetSummary(key string) string {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -10,6 +10,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/utils"
 )
 
@@ -29,6 +30,7 @@ type ClawHubRegistry struct {
 	maxZipSize      int
 	maxResponseSize int
 	client          *http.Client
+	trust           *trust.Policy // ClawHub publishes no signatures, so require mode refuses its skills
 }
 
 // NewClawHubRegistry creates a new ClawHub registry client from config.
@@ -222,6 +224,10 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
 	}
 
+	if _, err := c.trust.Check(trust.KindSkill, slug, nil, nil); err != nil {
+		return nil, fmt.Errorf("%w (clawhub does not publish signatures)", err)
+	}
+
 	// Step 1: Fetch metadata (with fallback).
 	result := &InstallResult{}
 	meta, err := c.GetSkillMeta(ctx, slug)
@@ -4,6 +4,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +16,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/utils"
 )
 
@@ -137,6 +141,34 @@ func TestClawHubRegistryDownloadAndInstall(t *testing.T) {
 	assert.Contains(t, string(readmeContent), "# Test Skill")
 }
 
+func TestClawHubRegistryTrustPolicy(t *testing.T) {
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/download" {
+			downloads++
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	key := base64.StdEncoding.EncodeToString(append([]byte("Ed12345678"), pub...))
+	policy, err := trust.NewPolicy(config.TrustConfig{
+		Keys:   []config.TrustedKeyConfig{{PublicKey: key}},
+		Skills: "require",
+	})
+	require.NoError(t, err)
+
+	rm := NewRegistryManagerFromConfig(RegistryConfig{
+		ClawHub: ClawHubConfig{Enabled: true, BaseURL: srv.URL},
+		Trust:   policy,
+	})
+	_, err = rm.GetRegistry("clawhub").DownloadAndInstall(context.Background(), "test-skill", "", t.TempDir())
+	require.ErrorIs(t, err, trust.ErrUnsigned)
+	assert.Zero(t, downloads, "unsigned skill downloaded despite require mode")
+}
+
 func TestClawHubRegistryAuthToken(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
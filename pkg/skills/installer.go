@@ -9,10 +9,13 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 )
 
 type SkillInstaller struct {
 	workspace string
+	trust     *trust.Policy
 }
 
 type AvailableSkill struct {
@@ -29,6 +32,12 @@ func NewSkillInstaller(workspace string) *SkillInstaller {
 	}
 }
 
+// SetTrustPolicy makes installs check the skill's minisign signature
+// (SKILL.md.minisig in the repository) against the policy
+func (si *SkillInstaller) SetTrustPolicy(policy *trust.Policy) {
+	si.trust = policy
+}
+
 func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) error {
 	skillDir := filepath.Join(si.workspace, "skills", filepath.Base(repo))
 
@@ -38,25 +47,27 @@ func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) er
 
 	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/main/SKILL.md", repo)
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, status, err := fetchRaw(ctx, url)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch skill: %w", err)
+	if status != 200 {
+		return fmt.Errorf("failed to fetch skill: HTTP %d", status)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to fetch skill: HTTP %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+	if si.trust.Mode(trust.KindSkill) != trust.ModeOff {
+		sig, status, err := fetchRaw(ctx, url+trust.SignatureExt)
+		if err != nil {
+			return err
+		}
+		if status == http.StatusNotFound {
+			sig = nil
+		} else if status != 200 {
+			return fmt.Errorf("failed to fetch skill signature: HTTP %d", status)
+		}
+		if _, err := si.trust.Check(trust.KindSkill, filepath.Base(repo), body, sig); err != nil {
+			return err
+		}
 	}
 
 	if err := os.MkdirAll(skillDir, 0o755); err != nil {
@@ -71,6 +82,27 @@ func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) er
 	return nil
 }
 
+// fetchRaw GETs url and returns the body and status code
+func fetchRaw(ctx context.Context, url string) ([]byte, int, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch skill: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
 func (si *SkillInstaller) Uninstall(skillName string) error {
 	skillDir := filepath.Join(si.workspace, "skills", skillName)
 
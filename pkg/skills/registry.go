@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
 )
 
 const (
@@ -62,6 +64,7 @@ type SkillRegistry interface {
 type RegistryConfig struct {
 	ClawHub               ClawHubConfig
 	MaxConcurrentSearches int
+	Trust                 *trust.Policy // Signature policy for installs; nil = none
 }
 
 // ClawHubConfig configures the ClawHub registry.
@@ -101,7 +104,9 @@ func NewRegistryManagerFromConfig(cfg RegistryConfig) *RegistryManager {
 		rm.maxConcurrent = cfg.MaxConcurrentSearches
 	}
 	if cfg.ClawHub.Enabled {
-		rm.AddRegistry(NewClawHubRegistry(cfg.ClawHub))
+		hub := NewClawHubRegistry(cfg.ClawHub)
+		hub.trust = cfg.Trust
+		rm.AddRegistry(hub)
 	}
 	return rm
 }
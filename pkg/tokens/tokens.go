@@ -0,0 +1,189 @@
+// Package tokens estimates how many tokens text, messages and tool schemas
+// occupy for a given model. OpenAI models are estimated with a tiktoken-style
+// pre-tokenizer and per-piece BPE approximation; every other model falls back
+// to a conservative character heuristic.
+package tokens
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+// Encoding identifies the estimation strategy for a model.
+type Encoding string
+
+const (
+	EncodingCL100k    Encoding = "cl100k_base" // GPT-4, GPT-3.5, text-embedding-3
+	EncodingO200k     Encoding = "o200k_base"  // GPT-4o, GPT-4.1, GPT-5, o-series
+	EncodingHeuristic Encoding = "heuristic"   // everything else
+)
+
+// MessageOverhead approximates the per-message framing (role, separators)
+// that providers add on top of the content.
+const MessageOverhead = 4
+
+// pretokenizer mirrors the cl100k/o200k split pattern (minus the negative
+// lookahead Go's regexp lacks): contractions, words with an optional leading
+// non-letter, 1-3 digit runs, punctuation runs, newlines and other whitespace.
+var pretokenizer = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// EncodingForModel picks the encoding for a model ID such as "gpt-4o",
+// "openai/gpt-4.1-mini" or "anthropic/claude-sonnet-4".
+func EncodingForModel(model string) Encoding {
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(m, "gpt-4o"), strings.HasPrefix(m, "gpt-4.1"), strings.HasPrefix(m, "gpt-5"),
+		strings.HasPrefix(m, "chatgpt-4o"), strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"),
+		strings.HasPrefix(m, "o4"), strings.HasPrefix(m, "gpt-oss"):
+		return EncodingO200k
+	case strings.HasPrefix(m, "gpt-4"), strings.HasPrefix(m, "gpt-3.5"), strings.HasPrefix(m, "text-embedding"):
+		return EncodingCL100k
+	}
+	return EncodingHeuristic
+}
+
+// Estimate returns the estimated token count of text for model.
+func Estimate(model, text string) int {
+	if text == "" {
+		return 0
+	}
+	enc := EncodingForModel(model)
+	if enc == EncodingHeuristic {
+		return heuristic(text)
+	}
+	return estimateBPE(enc, text)
+}
+
+// EstimateMessage estimates the prompt tokens of one message, including
+// tool call names and arguments and the per-message overhead.
+func EstimateMessage(model string, m protocoltypes.Message) int {
+	var b strings.Builder
+	b.WriteString(m.Content)
+	for _, tc := range m.ToolCalls {
+		if tc.Function != nil {
+			b.WriteString(tc.Function.Name)
+			b.WriteString(tc.Function.Arguments)
+		} else if len(tc.Arguments) > 0 {
+			if raw, err := json.Marshal(tc.Arguments); err == nil {
+				b.Write(raw)
+			}
+		}
+	}
+	return Estimate(model, b.String()) + MessageOverhead
+}
+
+// EstimateMessages sums EstimateMessage over messages.
+func EstimateMessages(model string, messages []protocoltypes.Message) int {
+	total := 0
+	for _, m := range messages {
+		total += EstimateMessage(model, m)
+	}
+	return total
+}
+
+// EstimateTools estimates the tokens the tool schemas add to a request.
+func EstimateTools(model string, tools []protocoltypes.ToolDefinition) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	raw, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return Estimate(model, string(raw))
+}
+
+// heuristic uses 2.5 characters per token, which stays on the safe side for
+// CJK text and code where real tokenizers produce more tokens per character.
+func heuristic(text string) int {
+	return max(1, utf8.RuneCountInString(text)*2/5)
+}
+
+// estimateBPE splits text the way tiktoken does before BPE merging, then
+// approximates how many merged tokens each piece becomes.
+func estimateBPE(enc Encoding, text string) int {
+	// Characters per token for common ASCII words; o200k's larger
+	// vocabulary merges a little more aggressively.
+	wordChars := 4.0
+	if enc == EncodingO200k {
+		wordChars = 4.4
+	}
+
+	total := 0
+	for _, piece := range pretokenizer.FindAllString(text, -1) {
+		total += pieceTokens(piece, wordChars)
+	}
+	return total
+}
+
+func pieceTokens(piece string, wordChars float64) int {
+	runes := utf8.RuneCountInString(piece)
+	if runes == 0 {
+		return 0
+	}
+
+	ascii, han := 0, 0
+	for _, r := range piece {
+		switch {
+		case r < utf8.RuneSelf:
+			ascii++
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			han++
+		}
+	}
+
+	if ascii == runes {
+		trimmed := strings.TrimSpace(piece)
+		// A single leading symbol usually merges with the word ("(foo", ".com")
+		word := trimmed
+		if len(word) > 1 && !isLetters(word[:1]) {
+			word = word[1:]
+		}
+		switch {
+		case trimmed == "":
+			return 1 // whitespace runs merge into a single token
+		case isLetters(word) && len(word) <= 6:
+			return 1 // short words are almost always one token
+		case isLetters(word):
+			return ceilDiv(float64(len(word)), wordChars)
+		default:
+			// Punctuation and symbol runs merge poorly
+			return ceilDiv(float64(len(trimmed)), 2)
+		}
+	}
+
+	// CJK characters are roughly one token each; other non-ASCII scripts
+	// (Cyrillic, accented Latin, ...) average about two characters per token.
+	other := runes - ascii - han
+	return han + ceilDiv(float64(other), 2) + ceilDiv(float64(ascii), wordChars)
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func ceilDiv(n, d float64) int {
+	if n <= 0 {
+		return 0
+	}
+	q := int(n / d)
+	if float64(q)*d < n {
+		q++
+	}
+	return q
+}
@@ -0,0 +1,66 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestEncodingForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  Encoding
+	}{
+		{"gpt-4o-mini", EncodingO200k},
+		{"openai/gpt-4.1", EncodingO200k},
+		{"o3-mini", EncodingO200k},
+		{"gpt-4-turbo", EncodingCL100k},
+		{"openrouter/openai/gpt-3.5-turbo", EncodingCL100k},
+		{"anthropic/claude-sonnet-4", EncodingHeuristic},
+		{"", EncodingHeuristic},
+	}
+	for _, tt := range tests {
+		if got := EncodingForModel(tt.model); got != tt.want {
+			t.Errorf("EncodingForModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	// Known cl100k counts: "Hello, world!" = 4, the pangram = 10
+	if got := Estimate("gpt-4", "Hello, world!"); got < 3 || got > 5 {
+		t.Errorf("Estimate(hello world) = %d, want ~4", got)
+	}
+	if got := Estimate("gpt-4", "The quick brown fox jumps over the lazy dog."); got < 9 || got > 12 {
+		t.Errorf("Estimate(pangram) = %d, want ~10", got)
+	}
+
+	// CJK is roughly a token per character
+	if got := Estimate("gpt-4o", "扫描目标主机"); got != 6 {
+		t.Errorf("Estimate(CJK) = %d, want 6", got)
+	}
+
+	// Other models use the 2.5 chars/token heuristic
+	if got := Estimate("claude-sonnet-4", strings.Repeat("a", 1000)); got != 400 {
+		t.Errorf("heuristic estimate = %d, want 400", got)
+	}
+
+	if got := Estimate("gpt-4", ""); got != 0 {
+		t.Errorf("Estimate(\"\") = %d, want 0", got)
+	}
+}
+
+func TestEstimateMessages(t *testing.T) {
+	messages := []protocoltypes.Message{
+		{Role: "user", Content: strings.Repeat("a", 100)},
+		{Role: "assistant", ToolCalls: []protocoltypes.ToolCall{{
+			Function: &protocoltypes.FunctionCall{Name: "exec", Arguments: `{"cmd":"nmap -sV 10.0.0.1"}`},
+		}}},
+	}
+	got := EstimateMessages("local-model", messages)
+	want := 40 + MessageOverhead + Estimate("local-model", `exec{"cmd":"nmap -sV 10.0.0.1"}`) + MessageOverhead
+	if got != want {
+		t.Errorf("EstimateMessages() = %d, want %d", got, want)
+	}
+}
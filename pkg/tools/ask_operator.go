@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Answer types accepted by the ask_operator tool.
+const (
+	AnswerText   = "text"
+	AnswerYesNo  = "yes_no"
+	AnswerChoice = "choice"
+	AnswerNumber = "number"
+)
+
+// OperatorQuestion is a structured question the agent puts to the operator.
+type OperatorQuestion struct {
+	Question   string   `json:"question"`
+	AnswerType string   `json:"answer_type"`
+	Choices    []string `json:"choices,omitempty"`
+	Context    string   `json:"context,omitempty"`
+	Channel    string   `json:"channel,omitempty"`
+	ChatID     string   `json:"chat_id,omitempty"`
+}
+
+// OperatorAsker presents a question to the operator and blocks until it is
+// answered. It returns an error if the question was dismissed or ctx ended.
+type OperatorAsker func(ctx context.Context, q OperatorQuestion) (string, error)
+
+// OperatorTool is implemented by tools that ask the operator questions or
+// for approval, such as ask_operator, scope_change_request and MCP tools.
+// Frontends install their asker on every one.
+type OperatorTool interface {
+	Tool
+	SetAsker(asker OperatorAsker)
+}
+
+// AskOperatorTool lets the agent ask the operator a question with a typed
+// answer instead of burying it in assistant prose. Interactive frontends
+// install an asker; headless runs send the question to a webhook and tell
+// the agent to proceed without an answer.
+type AskOperatorTool struct {
+	webhookURL string
+	client     *http.Client
+
+	mu      sync.RWMutex
+	asker   OperatorAsker
+	channel string
+	chatID  string
+}
+
+func NewAskOperatorTool(webhookURL string) *AskOperatorTool {
+	return &AskOperatorTool{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetAsker installs the interactive asker. nil switches back to headless mode.
+func (t *AskOperatorTool) SetAsker(asker OperatorAsker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.asker = asker
+}
+
+func (t *AskOperatorTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *AskOperatorTool) Name() string {
+	return "ask_operator"
+}
+
+func (t *AskOperatorTool) Description() string {
+	return "Ask the human operator a question and wait for a typed answer. Use this instead of asking in your reply when you need a decision, confirmation or missing information to continue (e.g. whether a host is in scope). Prefer yes_no or choice answers where possible."
+}
+
+func (t *AskOperatorTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"question": map[string]any{
+				"type":        "string",
+				"description": "The question to ask, phrased so it can be answered without reading the transcript",
+			},
+			"answer_type": map[string]any{
+				"type":        "string",
+				"enum":        []string{AnswerText, AnswerYesNo, AnswerChoice, AnswerNumber},
+				"description": "Kind of answer expected (default: text)",
+			},
+			"choices": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Options to pick from; required when answer_type is choice",
+			},
+			"context": map[string]any{
+				"type":        "string",
+				"description": "Optional short background shown with the question",
+			},
+		},
+		"required": []string{"question"},
+	}
+}
+
+func (t *AskOperatorTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	q, err := parseOperatorQuestion(args)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	t.mu.RLock()
+	asker := t.asker
+	q.Channel, q.ChatID = t.channel, t.chatID
+	t.mu.RUnlock()
+
+	if asker == nil {
+		return t.notifyHeadless(ctx, q)
+	}
+
+	answer, err := asker(ctx, q)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("operator did not answer: %v", err)).WithError(err)
+	}
+	answer, err = NormalizeOperatorAnswer(q, answer)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid operator answer: %v", err)).WithError(err)
+	}
+	return NewToolResult(fmt.Sprintf("Operator answered: %s", answer))
+}
+
+// notifyHeadless forwards the question to the configured webhook. The agent
+// cannot block on an answer here, so it is told to continue conservatively.
+func (t *AskOperatorTool) notifyHeadless(ctx context.Context, q OperatorQuestion) *ToolResult {
+	const proceed = "No interactive operator is available. Proceed with the safest assumption and state it in your report."
+	if t.webhookURL == "" {
+		return NewToolResult(proceed)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"type":      "operator_question",
+		"question":  q,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode question: %v", err)).WithError(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create webhook request: %v", err)).WithError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to notify operator: %v", err)).WithError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		return ErrorResult(fmt.Sprintf("failed to notify operator: %v", err)).WithError(err)
+	}
+	return NewToolResult("Question sent to the operator via webhook. " + proceed)
+}
+
+func parseOperatorQuestion(args map[string]any) (OperatorQuestion, error) {
+	q := OperatorQuestion{AnswerType: AnswerText}
+	q.Question, _ = args["question"].(string)
+	q.Question = strings.TrimSpace(q.Question)
+	if q.Question == "" {
+		return q, fmt.Errorf("question is required")
+	}
+	q.Context, _ = args["context"].(string)
+
+	if at, _ := args["answer_type"].(string); at != "" {
+		q.AnswerType = at
+	}
+	switch q.AnswerType {
+	case AnswerText, AnswerYesNo, AnswerNumber:
+	case AnswerChoice:
+		raw, _ := args["choices"].([]any)
+		for _, c := range raw {
+			if s, ok := c.(string); ok && strings.TrimSpace(s) != "" {
+				q.Choices = append(q.Choices, strings.TrimSpace(s))
+			}
+		}
+		if len(q.Choices) < 2 {
+			return q, fmt.Errorf("choice questions need at least two choices")
+		}
+	default:
+		return q, fmt.Errorf("unknown answer_type %q", q.AnswerType)
+	}
+	return q, nil
+}
+
+// NormalizeOperatorAnswer validates answer against the question's answer type
+// and returns its canonical form: "yes"/"no", the selected choice text, or a
+// number. Choices may be given by 1-based index or (case-insensitive) text.
+func NormalizeOperatorAnswer(q OperatorQuestion, answer string) (string, error) {
+	answer = strings.TrimSpace(answer)
+	switch q.AnswerType {
+	case AnswerYesNo:
+		switch strings.ToLower(answer) {
+		case "y", "yes", "true":
+			return "yes", nil
+		case "n", "no", "false":
+			return "no", nil
+		}
+		return "", fmt.Errorf("expected yes or no, got %q", answer)
+	case AnswerChoice:
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(q.Choices) {
+			return q.Choices[i-1], nil
+		}
+		for _, c := range q.Choices {
+			if strings.EqualFold(c, answer) {
+				return c, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of the choices", answer)
+	case AnswerNumber:
+		if _, err := strconv.ParseFloat(answer, 64); err != nil {
+			return "", fmt.Errorf("expected a number, got %q", answer)
+		}
+		return answer, nil
+	}
+	if answer == "" {
+		return "", fmt.Errorf("answer is empty")
+	}
+	return answer, nil
+}
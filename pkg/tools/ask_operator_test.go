@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestAskOperator_Interactive verifies the asker receives the question and
+// choice answers are normalized to the choice text
+func TestAskOperator_Interactive(t *testing.T) {
+	tool := NewAskOperatorTool("")
+	tool.SetContext("cli", "direct")
+
+	var got OperatorQuestion
+	tool.SetAsker(func(ctx context.Context, q OperatorQuestion) (string, error) {
+		got = q
+		return "2", nil
+	})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"question":    "Which host should be tested first?",
+		"answer_type": "choice",
+		"choices":     []any{"api.example.com", "www.example.com"},
+	})
+
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.ForLLM)
+	}
+	if got.Channel != "cli" || len(got.Choices) != 2 {
+		t.Errorf("Asker got unexpected question: %+v", got)
+	}
+	if !strings.Contains(result.ForLLM, "www.example.com") {
+		t.Errorf("Expected selected choice in result, got: %s", result.ForLLM)
+	}
+}
+
+// TestAskOperator_Dismissed verifies a dismissed question is reported as an error
+func TestAskOperator_Dismissed(t *testing.T) {
+	tool := NewAskOperatorTool("")
+	tool.SetAsker(func(ctx context.Context, q OperatorQuestion) (string, error) {
+		return "", errors.New("dismissed")
+	})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"question":    "Continue?",
+		"answer_type": "yes_no",
+	})
+	if !result.IsError {
+		t.Errorf("Expected error for dismissed question, got: %s", result.ForLLM)
+	}
+}
+
+// TestAskOperator_Webhook verifies headless mode posts the question to the webhook
+func TestAskOperator_Webhook(t *testing.T) {
+	var payload struct {
+		Type     string           `json:"type"`
+		Question OperatorQuestion `json:"question"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tool := NewAskOperatorTool(server.URL)
+	result := tool.Execute(context.Background(), map[string]any{
+		"question":    "Is 10.0.0.5 in scope?",
+		"answer_type": "yes_no",
+	})
+
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.ForLLM)
+	}
+	if payload.Type != "operator_question" || payload.Question.Question != "Is 10.0.0.5 in scope?" {
+		t.Errorf("Unexpected webhook payload: %+v", payload)
+	}
+	if !strings.Contains(result.ForLLM, "safest assumption") {
+		t.Errorf("Expected headless guidance, got: %s", result.ForLLM)
+	}
+}
+
+// TestAskOperator_InvalidArgs verifies malformed questions are rejected
+func TestAskOperator_InvalidArgs(t *testing.T) {
+	tool := NewAskOperatorTool("")
+
+	tests := []map[string]any{
+		{"question": ""},
+		{"question": "Pick one", "answer_type": "choice", "choices": []any{"only"}},
+		{"question": "Rate it", "answer_type": "stars"},
+	}
+	for _, args := range tests {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Expected error for args %v, got: %s", args, result.ForLLM)
+		}
+	}
+}
+
+func TestNormalizeOperatorAnswer(t *testing.T) {
+	choice := OperatorQuestion{AnswerType: AnswerChoice, Choices: []string{"Skip", "Retry"}}
+
+	tests := []struct {
+		q       OperatorQuestion
+		answer  string
+		want    string
+		wantErr bool
+	}{
+		{OperatorQuestion{AnswerType: AnswerYesNo}, "Y", "yes", false},
+		{OperatorQuestion{AnswerType: AnswerYesNo}, "no", "no", false},
+		{OperatorQuestion{AnswerType: AnswerYesNo}, "maybe", "", true},
+		{choice, "1", "Skip", false},
+		{choice, "retry", "Retry", false},
+		{choice, "3", "", true},
+		{OperatorQuestion{AnswerType: AnswerNumber}, " 42.5 ", "42.5", false},
+		{OperatorQuestion{AnswerType: AnswerNumber}, "many", "", true},
+		{OperatorQuestion{AnswerType: AnswerText}, "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeOperatorAnswer(tt.q, tt.answer)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeOperatorAnswer(%s, %q) = %q, %v; want %q, err=%v",
+				tt.q.AnswerType, tt.answer, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+const (
+	defaultBatchPollInterval = time.Minute
+	maxBatchTasks            = 1000
+	// batchPollTimeout gives up on a batch a little after the providers'
+	// 24 hour processing window
+	batchPollTimeout = 25 * time.Hour
+)
+
+// BatchTool submits independent, non-interactive LLM tasks (drafting report
+// sections over many findings, bulk JS analysis) through the provider's
+// batch API at half price. It returns immediately; a poller announces the
+// results to the agent on the system channel when the batch finishes, which
+// resumes the workflow the same way a finished subagent does.
+type BatchTool struct {
+	provider      providers.BatchProvider
+	model         string
+	maxTokens     int
+	bus           *bus.MessageBus
+	pollInterval  time.Duration
+	originChannel string
+	originChatID  string
+	callback      AsyncCallback
+}
+
+func NewBatchTool(
+	provider providers.BatchProvider,
+	model string,
+	maxTokens int,
+	msgBus *bus.MessageBus,
+	pollInterval time.Duration,
+) *BatchTool {
+	if pollInterval <= 0 {
+		pollInterval = defaultBatchPollInterval
+	}
+	return &BatchTool{
+		provider:      provider,
+		model:         model,
+		maxTokens:     maxTokens,
+		bus:           msgBus,
+		pollInterval:  pollInterval,
+		originChannel: "cli",
+		originChatID:  "direct",
+	}
+}
+
+// SetCallback implements AsyncTool interface for async completion notification
+func (t *BatchTool) SetCallback(cb AsyncCallback) {
+	t.callback = cb
+}
+
+func (t *BatchTool) SetContext(channel, chatID string) {
+	t.originChannel = channel
+	t.originChatID = chatID
+}
+
+func (t *BatchTool) Name() string {
+	return "batch_submit"
+}
+
+func (t *BatchTool) Description() string {
+	return "Submit many independent LLM tasks as one offline batch at 50% of the normal cost. " +
+		"Use for non-interactive work that can wait minutes to hours, such as drafting report sections " +
+		"for each finding or analyzing a list of JavaScript files. Each task runs without tools or " +
+		"conversation history, so include everything it needs in its prompt. Results are delivered " +
+		"back to you as a system message when the batch finishes."
+}
+
+func (t *BatchTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"tasks": map[string]any{
+				"type":        "array",
+				"description": "Tasks to run, each with a unique id and a self-contained prompt",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id": map[string]any{
+							"type":        "string",
+							"description": "Unique task ID used to label its result (e.g. the finding ID or file name)",
+						},
+						"prompt": map[string]any{
+							"type":        "string",
+							"description": "Complete prompt for this task",
+						},
+					},
+					"required": []string{"id", "prompt"},
+				},
+			},
+			"instructions": map[string]any{
+				"type":        "string",
+				"description": "Optional system prompt shared by every task",
+			},
+			"label": map[string]any{
+				"type":        "string",
+				"description": "Optional short label for the batch (for display)",
+			},
+		},
+		"required": []string{"tasks"},
+	}
+}
+
+func (t *BatchTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	rawTasks, ok := args["tasks"].([]any)
+	if !ok || len(rawTasks) == 0 {
+		return ErrorResult("tasks is required and must be a non-empty array")
+	}
+	if len(rawTasks) > maxBatchTasks {
+		return ErrorResult(fmt.Sprintf("too many tasks: %d (max %d)", len(rawTasks), maxBatchTasks))
+	}
+	instructions, _ := args["instructions"].(string)
+	label, _ := args["label"].(string)
+
+	requests := make([]providers.BatchRequest, 0, len(rawTasks))
+	order := make([]string, 0, len(rawTasks))
+	seen := make(map[string]bool, len(rawTasks))
+	for i, raw := range rawTasks {
+		task, _ := raw.(map[string]any)
+		id, _ := task["id"].(string)
+		prompt, _ := task["prompt"].(string)
+		if strings.TrimSpace(id) == "" || strings.TrimSpace(prompt) == "" {
+			return ErrorResult(fmt.Sprintf("task %d needs a non-empty id and prompt", i))
+		}
+		if seen[id] {
+			return ErrorResult(fmt.Sprintf("duplicate task id %q", id))
+		}
+		seen[id] = true
+
+		messages := []providers.Message{{Role: "user", Content: prompt}}
+		if instructions != "" {
+			messages = append([]providers.Message{{Role: "system", Content: instructions}}, messages...)
+		}
+		requests = append(requests, providers.BatchRequest{
+			CustomID: batchCustomID(i),
+			Messages: messages,
+			Model:    t.model,
+			Options:  map[string]any{"max_tokens": t.maxTokens},
+		})
+		order = append(order, id)
+	}
+
+	batchID, err := t.provider.SubmitBatch(ctx, requests)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to submit batch: %v", err)).WithError(err)
+	}
+	if label == "" {
+		label = batchID
+	}
+
+	logger.InfoCF("tool", "Batch submitted", map[string]any{
+		"batch_id": batchID,
+		"label":    label,
+		"tasks":    len(requests),
+		"model":    t.model,
+	})
+
+	// The batch outlives the turn that submitted it, so polling must not end
+	// with the turn's context
+	pollCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), batchPollTimeout)
+	go func() {
+		defer cancel()
+		t.await(pollCtx, batchID, label, order, t.originChannel, t.originChatID, t.callback)
+	}()
+
+	return AsyncResult(fmt.Sprintf(
+		"Submitted batch '%s' (%s) with %d tasks. Results will arrive as a system message when it finishes, "+
+			"usually within an hour and at most 24 hours; continue with other work meanwhile.",
+		label, batchID, len(requests),
+	))
+}
+
+// await polls the batch until it finishes, then announces the results to
+// the agent that submitted it.
+func (t *BatchTool) await(
+	ctx context.Context,
+	batchID, label string,
+	order []string,
+	originChannel, originChatID string,
+	callback AsyncCallback,
+) {
+	results, err := providers.WaitForBatch(ctx, t.provider, batchID, t.pollInterval, nil)
+
+	var result *ToolResult
+	status, content := "completed", ""
+	if err != nil {
+		status, content = "failed", fmt.Sprintf("Error: %v", err)
+		result = ErrorResult(fmt.Sprintf("Batch '%s' failed: %v", label, err)).WithError(err)
+		logger.WarnCF("tool", "Batch failed", map[string]any{"batch_id": batchID, "error": err.Error()})
+	} else {
+		content = formatBatchResults(order, results)
+		result = &ToolResult{ForLLM: fmt.Sprintf("Batch '%s' completed: %s", label, content), ForUser: content}
+		logger.InfoCF("tool", "Batch completed", map[string]any{"batch_id": batchID, "results": len(results)})
+	}
+
+	if callback != nil {
+		callback(ctx, result)
+	}
+	if t.bus != nil {
+		t.bus.PublishInbound(bus.InboundMessage{
+			Channel:  "system",
+			SenderID: fmt.Sprintf("batch:%s", batchID),
+			// Format: "original_channel:original_chat_id" for routing back
+			ChatID:  fmt.Sprintf("%s:%s", originChannel, originChatID),
+			Content: fmt.Sprintf("Batch '%s' %s.\n\nResult:\n%s", label, status, content),
+		})
+	}
+}
+
+// batchCustomID returns the custom ID for the i-th task. Task IDs chosen by
+// the model may not meet the API's custom_id format, so results are mapped
+// back by position.
+func batchCustomID(i int) string {
+	return fmt.Sprintf("task-%d", i)
+}
+
+// formatBatchResults renders results in submission order under their task IDs.
+func formatBatchResults(order []string, results []providers.BatchResult) string {
+	byCustomID := make(map[string]providers.BatchResult, len(results))
+	for _, r := range results {
+		byCustomID[r.CustomID] = r
+	}
+
+	var sb strings.Builder
+	for i, id := range order {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n", id)
+		r, ok := byCustomID[batchCustomID(i)]
+		switch {
+		case !ok:
+			sb.WriteString("ERROR: no result returned")
+		case r.Response == nil:
+			fmt.Fprintf(&sb, "ERROR: %s", r.Error)
+		default:
+			sb.WriteString(r.Response.Content)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
+)
+
+// mockBatchProvider finishes a batch after a number of status checks and
+// answers each request with its prompt.
+type mockBatchProvider struct {
+	MockLLMProvider
+	mu        sync.Mutex
+	requests  []providers.BatchRequest
+	pollsLeft int
+}
+
+func (m *mockBatchProvider) SubmitBatch(ctx context.Context, requests []providers.BatchRequest) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = requests
+	return "msgbatch_1", nil
+}
+
+func (m *mockBatchProvider) BatchDone(ctx context.Context, batchID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pollsLeft--
+	return m.pollsLeft <= 0, nil
+}
+
+func (m *mockBatchProvider) BatchResults(
+	ctx context.Context, batchID string, options map[string]any,
+) ([]providers.BatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := []providers.BatchResult{{CustomID: m.requests[1].CustomID, Error: "request expired"}}
+	last := m.requests[0].Messages[len(m.requests[0].Messages)-1]
+	results = append(results, providers.BatchResult{
+		CustomID: m.requests[0].CustomID,
+		Response: &providers.LLMResponse{Content: "Drafted: " + last.Content},
+	})
+	return results, nil
+}
+
+func TestBatchTool_AnnouncesResults(t *testing.T) {
+	provider := &mockBatchProvider{pollsLeft: 2}
+	msgBus := bus.NewMessageBus()
+	tool := NewBatchTool(provider, "claude-sonnet-4.6", 1024, msgBus, time.Millisecond)
+	tool.SetContext("telegram", "chat-1")
+
+	callbackDone := make(chan *ToolResult, 1)
+	tool.SetCallback(func(ctx context.Context, result *ToolResult) { callbackDone <- result })
+
+	// Polling outlives the turn that submitted the batch
+	turnCtx, endTurn := context.WithCancel(context.Background())
+	result := tool.Execute(turnCtx, map[string]any{
+		"label":        "report",
+		"instructions": "Write one report section.",
+		"tasks": []any{
+			map[string]any{"id": "F-1", "prompt": "SQL injection in /login"},
+			map[string]any{"id": "F-2", "prompt": "Open redirect in /next"},
+		},
+	})
+	endTurn()
+	if result.IsError || !result.Async {
+		t.Fatalf("Execute() = %+v, want async success", result)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no system message announced")
+	}
+	if msg.Channel != "system" || msg.ChatID != "telegram:chat-1" || msg.SenderID != "batch:msgbatch_1" {
+		t.Errorf("message routing = %+v", msg)
+	}
+	want := "### F-1\nDrafted: SQL injection in /login\n\n### F-2\nERROR: request expired"
+	if !strings.Contains(msg.Content, "Batch 'report' completed.") || !strings.HasSuffix(msg.Content, want) {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if cbResult := <-callbackDone; cbResult.IsError {
+		t.Errorf("callback result = %+v", cbResult)
+	}
+
+	req := provider.requests[0]
+	if req.Model != "claude-sonnet-4.6" || req.Options["max_tokens"] != 1024 || req.Messages[0].Role != "system" {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestBatchTool_RejectsBadTasks(t *testing.T) {
+	tool := NewBatchTool(&mockBatchProvider{}, "m", 1024, nil, 0)
+	cases := []map[string]any{
+		{},
+		{"tasks": []any{}},
+		{"tasks": []any{map[string]any{"id": "a"}}},
+		{"tasks": []any{
+			map[string]any{"id": "a", "prompt": "x"},
+			map[string]any{"id": "a", "prompt": "y"},
+		}},
+	}
+	for _, args := range cases {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Execute(%v) succeeded, want error", args)
+		}
+	}
+}
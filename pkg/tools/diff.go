@@ -0,0 +1,357 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+const (
+	defaultDiffContext = 3
+	maxDiffContext     = 20
+	maxDiffChars       = 20000    // Diff text shown to the model; the rest stays in RawOutput
+	maxDiffFileSize    = 10 << 20 // Larger files are compared by hash only
+	maxDiffTreeFiles   = 5000
+)
+
+var resultRefPattern = regexp.MustCompile(`(?i)^(r\d+|last)$`)
+
+// DiffTool compares two stored tool results, files or directories and
+// returns a unified diff, to spot what changed between two responses or
+// configurations or to check that a fix changed behavior
+type DiffTool struct {
+	workspace string
+	restrict  bool
+	results   *ResultStore
+}
+
+func NewDiffTool(workspace string, restrict bool, results *ResultStore) *DiffTool {
+	return &DiffTool{workspace: workspace, restrict: restrict, results: results}
+}
+
+func (t *DiffTool) Name() string {
+	return "diff"
+}
+
+func (t *DiffTool) Description() string {
+	return "Compare two artifacts and return a unified diff. Each side is a stored result ID (r12, or 'last'), a file, or a directory in the workspace; two directories are compared file by file, such as two extracted firmware images. Use ignore_pattern to mask values that change on every request (dates, nonces, CSRF tokens) so only real changes show, for example to verify that a remediation changed a response."
+}
+
+func (t *DiffTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"before": map[string]any{
+				"type":        "string",
+				"description": "Old side: stored result ID, or file or directory path",
+			},
+			"after": map[string]any{
+				"type":        "string",
+				"description": "New side: stored result ID, or file or directory path",
+			},
+			"context": map[string]any{
+				"type":        "integer",
+				"description": "Unchanged lines shown around each change (default 3, max 20)",
+			},
+			"ignore_pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression for volatile text to ignore, e.g. '(?i)^date:.*|csrf_token=\\\\w+'",
+			},
+			"ignore_whitespace": map[string]any{
+				"type":        "boolean",
+				"description": "Ignore differences in spacing and indentation",
+			},
+		},
+		"required": []string{"before", "after"},
+	}
+}
+
+// diffOptions controls how two texts are compared
+type diffOptions struct {
+	context          int
+	ignore           *regexp.Regexp
+	ignoreWhitespace bool
+}
+
+// diffSide is one resolved side of a comparison
+type diffSide struct {
+	label   string
+	content []byte
+	dir     string // Set instead of content for directories
+}
+
+func (t *DiffTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	beforeRef, _ := args["before"].(string)
+	afterRef, _ := args["after"].(string)
+	if strings.TrimSpace(beforeRef) == "" || strings.TrimSpace(afterRef) == "" {
+		return ErrorResult("before and after are required")
+	}
+
+	opts := diffOptions{context: clampInt(intArg(args, "context", defaultDiffContext), 0, maxDiffContext)}
+	opts.ignoreWhitespace, _ = args["ignore_whitespace"].(bool)
+	if pattern, _ := args["ignore_pattern"].(string); pattern != "" {
+		re, err := regexp.Compile("(?m)" + pattern)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid ignore_pattern: %v", err))
+		}
+		opts.ignore = re
+	}
+
+	before, err := t.resolve(beforeRef)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	after, err := t.resolve(afterRef)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var output string
+	switch {
+	case before.dir != "" && after.dir != "":
+		output, err = diffTrees(before, after, opts)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+	case before.dir != "" || after.dir != "":
+		return ErrorResult("can't compare a directory with a file; give two directories or two files")
+	default:
+		diff, added, removed := diffContents(before.label, after.label, before.content, after.content, opts)
+		if diff == "" {
+			output = fmt.Sprintf("No differences between %s and %s.", before.label, after.label)
+		} else {
+			output = fmt.Sprintf("%s -> %s: %d lines added, %d removed\n\n%s", before.label, after.label, added, removed, diff)
+		}
+	}
+
+	shown := output
+	if len(shown) > maxDiffChars {
+		shown = strings.ToValidUTF8(shown[:maxDiffChars], "") +
+			fmt.Sprintf("\n[... diff truncated, %d more characters; narrow it with ignore_pattern or diff single files ...]", len(output)-maxDiffChars)
+	}
+	return &ToolResult{ForLLM: shown, RawOutput: output}
+}
+
+// resolve loads a side of the comparison: a stored result, or a file or
+// directory in the workspace
+func (t *DiffTool) resolve(ref string) (*diffSide, error) {
+	ref = strings.TrimSpace(ref)
+	if resultRefPattern.MatchString(ref) && t.results != nil {
+		result, err := t.results.Load(ref)
+		if err != nil {
+			return nil, err
+		}
+		return &diffSide{label: fmt.Sprintf("result %s (%s)", result.ID, result.Tool), content: []byte(result.Output)}, nil
+	}
+
+	path, err := validatePath(ref, t.workspace, t.restrict)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	if info.IsDir() {
+		return &diffSide{label: ref, dir: path}, nil
+	}
+	if info.Size() > maxDiffFileSize {
+		sum, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Too large to diff line by line; compared by hash instead
+		return &diffSide{label: ref, content: []byte(fmt.Sprintf("%d bytes, sha256 %x\n", info.Size(), sum))}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	return &diffSide{label: ref, content: content}, nil
+}
+
+// diffContents returns a unified diff of two texts and the number of lines
+// added and removed, or "" if they are the same once the options are
+// applied. Binary content is compared by size and hash.
+func diffContents(beforeLabel, afterLabel string, before, after []byte, opts diffOptions) (string, int, int) {
+	if isBinary(before) || isBinary(after) {
+		if bytes.Equal(before, after) {
+			return "", 0, 0
+		}
+		return fmt.Sprintf("Binary contents differ:\n  %s: %d bytes, sha256 %x\n  %s: %d bytes, sha256 %x\n",
+			beforeLabel, len(before), sha256.Sum256(before), afterLabel, len(after), sha256.Sum256(after)), 0, 0
+	}
+
+	a, b := normalizeForDiff(string(before), opts), normalizeForDiff(string(after), opts)
+	if a == b {
+		return "", 0, 0
+	}
+	diff, err := udiff.ToUnified(beforeLabel, afterLabel, a, udiff.Strings(a, b), opts.context)
+	if err != nil {
+		return fmt.Sprintf("failed to diff: %v\n", err), 0, 0
+	}
+
+	added, removed := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+			// The ---/+++ file header
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return diff, added, removed
+}
+
+// normalizeForDiff masks ignored text and, if asked, collapses whitespace
+func normalizeForDiff(s string, opts diffOptions) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if opts.ignore != nil {
+		s = opts.ignore.ReplaceAllString(s, "<ignored>")
+	}
+	if opts.ignoreWhitespace {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = strings.Join(strings.Fields(line), " ")
+		}
+		s = strings.Join(lines, "\n")
+	}
+	return s
+}
+
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b, 0) >= 0 || !utf8.Valid(b)
+}
+
+// diffTrees compares two directories file by file: files only on one side
+// are listed, and changed files are diffed
+func diffTrees(before, after *diffSide, opts diffOptions) (string, error) {
+	beforeFiles, err := listTree(before.dir)
+	if err != nil {
+		return "", err
+	}
+	afterFiles, err := listTree(after.dir)
+	if err != nil {
+		return "", err
+	}
+
+	var added, removed, changed []string
+	var diffs strings.Builder
+	for rel := range beforeFiles {
+		if _, ok := afterFiles[rel]; !ok {
+			removed = append(removed, rel)
+		}
+	}
+	for _, rel := range slices.Sorted(maps.Keys(afterFiles)) {
+		if _, ok := beforeFiles[rel]; !ok {
+			added = append(added, rel)
+			continue
+		}
+		a, err := readForDiff(filepath.Join(before.dir, rel))
+		if err != nil {
+			return "", err
+		}
+		b, err := readForDiff(filepath.Join(after.dir, rel))
+		if err != nil {
+			return "", err
+		}
+		if diff, _, _ := diffContents(filepath.ToSlash(filepath.Join(before.label, rel)), filepath.ToSlash(filepath.Join(after.label, rel)), a, b, opts); diff != "" {
+			changed = append(changed, rel)
+			diffs.WriteString(diff)
+			diffs.WriteString("\n")
+		}
+	}
+	sort.Strings(removed)
+
+	if len(added)+len(removed)+len(changed) == 0 {
+		return fmt.Sprintf("No differences between %s and %s (%d files).", before.label, after.label, len(afterFiles)), nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s -> %s: %d files added, %d removed, %d changed\n", before.label, after.label, len(added), len(removed), len(changed))
+	for _, group := range []struct {
+		name  string
+		files []string
+	}{{"Added", added}, {"Removed", removed}, {"Changed", changed}} {
+		if len(group.files) > 0 {
+			fmt.Fprintf(&sb, "\n%s:\n", group.name)
+			for _, rel := range group.files {
+				sb.WriteString("  " + filepath.ToSlash(rel) + "\n")
+			}
+		}
+	}
+	if diffs.Len() > 0 {
+		sb.WriteString("\n" + diffs.String())
+	}
+	return sb.String(), nil
+}
+
+// listTree returns the regular files under dir, relative to it
+func listTree(dir string) (map[string]struct{}, error) {
+	files := make(map[string]struct{})
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) == maxDiffTreeFiles {
+			return fmt.Errorf("%s has more than %d files; compare a subdirectory", dir, maxDiffTreeFiles)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = struct{}{}
+		return nil
+	})
+	return files, err
+}
+
+// readForDiff reads a file, or describes it by size and hash if it is too
+// large to diff
+func readForDiff(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxDiffFileSize {
+		sum, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(fmt.Sprintf("%d bytes, sha256 %x\n", info.Size(), sum)), nil
+	}
+	return os.ReadFile(path)
+}
+
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
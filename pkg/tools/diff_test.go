@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTool_StoredResults(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewResultStore(filepath.Join(workspace, ResultsDir))
+	_, err := store.Save("exec", "HTTP/1.1 200 OK\nDate: Mon, 12 Oct 2026 10:00:00 GMT\nServer: nginx/1.18.0\n\nhello\n")
+	require.NoError(t, err)
+	_, err = store.Save("exec", "HTTP/1.1 200 OK\nDate: Tue, 13 Oct 2026 11:30:00 GMT\nServer: nginx\n\nhello\n")
+	require.NoError(t, err)
+
+	tool := NewDiffTool(workspace, true, store)
+	result := tool.Execute(context.Background(), map[string]any{
+		"before":         "r1",
+		"after":          "last",
+		"ignore_pattern": "(?i)^date:.*$",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "result r1 (exec) -> result r2 (exec): 1 lines added, 1 removed")
+	assert.Contains(t, result.ForLLM, "-Server: nginx/1.18.0\n+Server: nginx\n")
+	assert.NotContains(t, result.ForLLM, "-Date:", "ignored lines don't show as changes")
+
+	result = tool.Execute(context.Background(), map[string]any{"before": "r1", "after": "r9"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "no stored result r9")
+
+	result = tool.Execute(context.Background(), map[string]any{"before": "r1", "after": "r2", "ignore_pattern": "("})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "invalid ignore_pattern")
+}
+
+func TestDiffTool_Files(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestFile(t, workspace, "a.conf", "listen 80;\r\nserver_tokens on;\n")
+	writeTestFile(t, workspace, "b.conf", "listen   80;\nserver_tokens on;\n")
+	tool := NewDiffTool(workspace, true, nil)
+
+	result := tool.Execute(context.Background(), map[string]any{"before": "a.conf", "after": "b.conf", "ignore_whitespace": true})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "No differences between a.conf and b.conf.", result.ForLLM)
+
+	result = tool.Execute(context.Background(), map[string]any{"before": "a.conf", "after": "b.conf"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "-listen 80;\n+listen   80;\n")
+
+	writeTestFile(t, workspace, "a.bin", "\x7fELF\x00\x01")
+	writeTestFile(t, workspace, "b.bin", "\x7fELF\x00\x02")
+	result = tool.Execute(context.Background(), map[string]any{"before": "a.bin", "after": "b.bin"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "Binary contents differ")
+
+	result = tool.Execute(context.Background(), map[string]any{"before": "../outside", "after": "b.conf"})
+	assert.True(t, result.IsError, "paths outside a restricted workspace are refused")
+}
+
+func TestDiffTool_Directories(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestFile(t, workspace, "v1/etc/passwd", "root:x:0:0\n")
+	writeTestFile(t, workspace, "v1/etc/shadow", "root:*:1\n")
+	writeTestFile(t, workspace, "v1/bin/telnetd", "telnetd\n")
+	writeTestFile(t, workspace, "v2/etc/passwd", "root:x:0:0\nadmin:x:1000:1000\n")
+	writeTestFile(t, workspace, "v2/etc/shadow", "root:*:1\n")
+	writeTestFile(t, workspace, "v2/bin/dropbear", "dropbear\n")
+	tool := NewDiffTool(workspace, true, nil)
+
+	result := tool.Execute(context.Background(), map[string]any{"before": "v1", "after": "v2"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "v1 -> v2: 1 files added, 1 removed, 1 changed")
+	assert.Contains(t, result.ForLLM, "Added:\n  bin/dropbear\n")
+	assert.Contains(t, result.ForLLM, "Removed:\n  bin/telnetd\n")
+	assert.Contains(t, result.ForLLM, "Changed:\n  etc/passwd\n")
+	assert.Contains(t, result.ForLLM, "+admin:x:1000:1000")
+	assert.NotContains(t, result.ForLLM, "shadow")
+
+	result = tool.Execute(context.Background(), map[string]any{"before": "v1", "after": "v2/etc/passwd"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "can't compare a directory with a file")
+}
+
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
@@ -0,0 +1,380 @@
+package tools
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/md4"
+)
+
+// maxEncodeOutput caps the bytes a decompression may produce, and how much
+// of the result is shown
+const maxEncodeOutput = 64 * 1024
+
+// encodeOp is one transformation the encode tool can apply
+type encodeOp struct {
+	help string
+	fn   func([]byte) ([]byte, error)
+}
+
+// encodeOps are the transformations, applied in the order given. Hashes
+// produce raw digests, shown as hex unless another operation follows, so
+// "sha256,base64_encode" gives a base64 digest.
+var encodeOps = map[string]encodeOp{
+	"base64_encode":    {"standard base64", func(b []byte) ([]byte, error) { return []byte(base64.StdEncoding.EncodeToString(b)), nil }},
+	"base64url_encode": {"URL-safe base64 without padding", func(b []byte) ([]byte, error) { return []byte(base64.RawURLEncoding.EncodeToString(b)), nil }},
+	"base64_decode":    {"standard or URL-safe base64, padded or not", decodeBase64Bytes},
+	"hex_encode":       {"lowercase hex", func(b []byte) ([]byte, error) { return []byte(hex.EncodeToString(b)), nil }},
+	"hex_decode":       {"hex, ignoring 0x, spaces, colons and \\x", decodeHexBytes},
+	"url_encode":       {"percent-encode reserved characters (query style, space as +)", func(b []byte) ([]byte, error) { return []byte(url.QueryEscape(string(b))), nil }},
+	"url_encode_all":   {"percent-encode every byte", urlEncodeAll},
+	"url_decode":       {"percent-decoding, + as space", urlDecode},
+	"html_encode":      {"escape <, >, &, ' and \"", func(b []byte) ([]byte, error) { return []byte(html.EscapeString(string(b))), nil }},
+	"html_decode":      {"unescape HTML entities", func(b []byte) ([]byte, error) { return []byte(html.UnescapeString(string(b))), nil }},
+	"utf16le":          {"UTF-16LE, as used by PowerShell -EncodedCommand and NTLM", encodeUTF16LE},
+	"rot13":            {"rotate letters by 13", rot13},
+	"gzip":             {"gzip compress", gzipBytes},
+	"gunzip":           {"gzip decompress", func(b []byte) ([]byte, error) { return decompress(gzip.NewReader(bytes.NewReader(b))) }},
+	"inflate":          {"zlib or raw DEFLATE decompress", inflateBytes},
+	"md5":              {"MD5 digest", digest(md5.New)},
+	"sha1":             {"SHA-1 digest", digest(sha1.New)},
+	"sha256":           {"SHA-256 digest", digest(sha256.New)},
+	"sha512":           {"SHA-512 digest", digest(sha512.New)},
+	"ntlm":             {"NTLM hash (MD4 of the UTF-16LE password)", ntlmHash},
+}
+
+// digestOps always show their result as hex when they come last
+var digestOps = map[string]bool{"md5": true, "sha1": true, "sha256": true, "sha512": true, "ntlm": true}
+
+// encodeReports are operations that describe their input rather than
+// transform it, so they must come last
+var encodeReports = map[string]func([]byte) string{
+	"hash":          hashAll,
+	"identify_hash": identifyHash,
+}
+
+// EncodeTool applies encodings, compression and hashes deterministically,
+// so the model computes transformations instead of guessing at them.
+type EncodeTool struct{}
+
+func NewEncodeTool() *EncodeTool {
+	return &EncodeTool{}
+}
+
+func (t *EncodeTool) Name() string {
+	return "encode"
+}
+
+func (t *EncodeTool) Description() string {
+	return "Encode, decode, compress or hash data exactly: base64, hex, URL, HTML, UTF-16LE, gzip/zlib, MD5/SHA/NTLM, and hash type identification. Chain operations with commas, e.g. \"url_decode,base64_decode,gunzip\". Always use this instead of transforming data in your head."
+}
+
+func (t *EncodeTool) Parameters() map[string]any {
+	ops := make([]string, 0, len(encodeOps)+len(encodeReports))
+	for _, name := range sortedOpNames() {
+		ops = append(ops, fmt.Sprintf("%s (%s)", name, encodeOps[name].help))
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"operations": map[string]any{
+				"type":        "string",
+				"description": "Comma-separated operations applied in order: " + strings.Join(ops, ", ") + ". May end with hash (all common digests) or identify_hash (guess the type of a hash)",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "The data to transform",
+			},
+			"input_format": map[string]any{
+				"type":        "string",
+				"enum":        []string{"text", "hex", "base64"},
+				"description": "How input is given: text (default), or hex/base64 for binary data",
+			},
+		},
+		"required": []string{"operations", "input"},
+	}
+}
+
+func (t *EncodeTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	opList, _ := args["operations"].(string)
+	input, ok := args["input"].(string)
+	if !ok {
+		return ErrorResult("input is required")
+	}
+
+	var names []string
+	for _, name := range strings.Split(opList, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ErrorResult("operations is required")
+	}
+
+	data := []byte(input)
+	switch format, _ := args["input_format"].(string); format {
+	case "", "text":
+	case "hex":
+		decoded, err := decodeHexBytes(data)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("input is not hex: %v", err))
+		}
+		data = decoded
+	case "base64":
+		decoded, err := decodeBase64Bytes(data)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("input is not base64: %v", err))
+		}
+		data = decoded
+	default:
+		return ErrorResult(fmt.Sprintf("unknown input_format %q (want text, hex or base64)", format))
+	}
+
+	for i, name := range names {
+		if report, ok := encodeReports[name]; ok {
+			if i != len(names)-1 {
+				return ErrorResult(fmt.Sprintf("%s must be the last operation", name))
+			}
+			return NewToolResult(report(data))
+		}
+		op, ok := encodeOps[name]
+		if !ok {
+			return ErrorResult(fmt.Sprintf("unknown operation %q; available: %s, hash, identify_hash", name, strings.Join(sortedOpNames(), ", ")))
+		}
+		out, err := op.fn(data)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("%s failed: %v", name, err))
+		}
+		data = out
+	}
+	if digestOps[names[len(names)-1]] {
+		return NewToolResult(hex.EncodeToString(data))
+	}
+	return NewToolResult(formatEncodeOutput(data))
+}
+
+func sortedOpNames() []string {
+	names := make([]string, 0, len(encodeOps))
+	for name := range encodeOps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatEncodeOutput shows text as is and anything else as hex
+func formatEncodeOutput(data []byte) string {
+	truncated := ""
+	if len(data) > maxEncodeOutput {
+		truncated = fmt.Sprintf("\n[output truncated: showing %d of %d bytes]", maxEncodeOutput, len(data))
+		data = data[:maxEncodeOutput]
+	}
+	if utf8.Valid(data) && isPrintable(string(data)) {
+		return string(data) + truncated
+	}
+	return fmt.Sprintf("%s\n[binary, %d bytes, shown as hex]%s", hex.EncodeToString(data), len(data), truncated)
+}
+
+func decodeBase64Bytes(b []byte) ([]byte, error) {
+	s := strings.Join(strings.Fields(string(b)), "")
+	s = strings.TrimRight(s, "=")
+	decoded, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		decoded, err = base64.RawURLEncoding.DecodeString(s)
+	}
+	return decoded, err
+}
+
+func decodeHexBytes(b []byte) ([]byte, error) {
+	s := strings.NewReplacer("0x", "", "0X", "", `\x`, "", " ", "", ":", "", "\n", "", "\t", "").Replace(string(b))
+	return hex.DecodeString(s)
+}
+
+func urlEncodeAll(b []byte) ([]byte, error) {
+	var sb strings.Builder
+	for _, c := range b {
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return []byte(sb.String()), nil
+}
+
+func urlDecode(b []byte) ([]byte, error) {
+	s, err := url.QueryUnescape(string(b))
+	return []byte(s), err
+}
+
+func encodeUTF16LE(b []byte) ([]byte, error) {
+	units := utf16.Encode([]rune(string(b)))
+	out := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(out[2*i:], u)
+	}
+	return out, nil
+}
+
+func rot13(b []byte) ([]byte, error) {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z':
+			c = 'a' + (c-'a'+13)%26
+		case c >= 'A' && c <= 'Z':
+			c = 'A' + (c-'A'+13)%26
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func inflateBytes(b []byte) ([]byte, error) {
+	if r, err := zlib.NewReader(bytes.NewReader(b)); err == nil {
+		return decompress(r, nil)
+	}
+	return decompress(flate.NewReader(bytes.NewReader(b)), nil)
+}
+
+// decompress reads r to the end, up to maxEncodeOutput bytes
+func decompress(r io.Reader, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(r, maxEncodeOutput+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxEncodeOutput {
+		return nil, fmt.Errorf("decompressed data exceeds %d bytes", maxEncodeOutput)
+	}
+	return out, nil
+}
+
+func digest(newHash func() hash.Hash) func([]byte) ([]byte, error) {
+	return func(b []byte) ([]byte, error) {
+		h := newHash()
+		h.Write(b)
+		return h.Sum(nil), nil
+	}
+}
+
+func ntlmHash(b []byte) ([]byte, error) {
+	password, _ := encodeUTF16LE(b)
+	return digest(md4.New)(password)
+}
+
+// hashAll lists every common digest of b
+func hashAll(b []byte) string {
+	digests := []struct {
+		name    string
+		newHash func() hash.Hash
+	}{
+		{"MD5", md5.New},
+		{"SHA-1", sha1.New},
+		{"SHA-224", sha256.New224},
+		{"SHA-256", sha256.New},
+		{"SHA-384", sha512.New384},
+		{"SHA-512", sha512.New},
+		{"SHA3-256", func() hash.Hash { return sha3.New256() }},
+		{"SHA3-512", func() hash.Hash { return sha3.New512() }},
+	}
+
+	var sb strings.Builder
+	for _, d := range digests {
+		sum, _ := digest(d.newHash)(b)
+		fmt.Fprintf(&sb, "%-9s %s\n", d.name+":", hex.EncodeToString(sum))
+	}
+	ntlm, _ := ntlmHash(b)
+	fmt.Fprintf(&sb, "%-9s %s\n", "NTLM:", hex.EncodeToString(ntlm))
+	fmt.Fprintf(&sb, "%-9s %08x", "CRC32:", crc32.ChecksumIEEE(b))
+	return sb.String()
+}
+
+// hashFormat recognizes one hash format and names its hashcat mode
+type hashFormat struct {
+	pattern *regexp.Regexp
+	names   []string // Most likely first, each "Name (hashcat -m N)"
+}
+
+var hashFormats = []hashFormat{
+	{regexp.MustCompile(`^\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}$`), []string{"bcrypt (hashcat -m 3200)"}},
+	{regexp.MustCompile(`^\$argon2(id|i|d)\$`), []string{"Argon2 (hashcat -m 34000 for argon2id)"}},
+	{regexp.MustCompile(`^\$1\$[^$]{0,8}\$[./A-Za-z0-9]{22}$`), []string{"md5crypt (hashcat -m 500)"}},
+	{regexp.MustCompile(`^\$apr1\$[^$]{0,8}\$[./A-Za-z0-9]{22}$`), []string{"Apache apr1 MD5 (hashcat -m 1600)"}},
+	{regexp.MustCompile(`^\$5\$(rounds=\d+\$)?[^$]{0,16}\$[./A-Za-z0-9]{43}$`), []string{"sha256crypt (hashcat -m 7400)"}},
+	{regexp.MustCompile(`^\$6\$(rounds=\d+\$)?[^$]{0,16}\$[./A-Za-z0-9]{86}$`), []string{"sha512crypt (hashcat -m 1800)"}},
+	{regexp.MustCompile(`^\$y\$`), []string{"yescrypt (not supported by hashcat; use john --format=crypt)"}},
+	{regexp.MustCompile(`^\$P\$[./A-Za-z0-9]{31}$`), []string{"phpass, WordPress (hashcat -m 400)"}},
+	{regexp.MustCompile(`^\$H\$[./A-Za-z0-9]{31}$`), []string{"phpass, phpBB3 (hashcat -m 400)"}},
+	{regexp.MustCompile(`^pbkdf2_sha256\$\d+\$`), []string{"Django PBKDF2-SHA256 (hashcat -m 10000)"}},
+	{regexp.MustCompile(`^\$pbkdf2-sha256\$`), []string{"PBKDF2-SHA256, passlib (hashcat -m 20300)"}},
+	{regexp.MustCompile(`^\$krb5tgs\$23\$`), []string{"Kerberos 5 TGS-REP etype 23, Kerberoasting (hashcat -m 13100)"}},
+	{regexp.MustCompile(`^\$krb5asrep\$23\$`), []string{"Kerberos 5 AS-REP etype 23, AS-REP roasting (hashcat -m 18200)"}},
+	{regexp.MustCompile(`(?i)^[^:]+::[^:]*:[0-9a-f]{16}:[0-9a-f]{32}:[0-9a-f]+$`), []string{"NetNTLMv2 (hashcat -m 5600)"}},
+	{regexp.MustCompile(`(?i)^[^:]+::[^:]*:[0-9a-f]{48}:[0-9a-f]{48}:[0-9a-f]{16}$`), []string{"NetNTLMv1 (hashcat -m 5500)"}},
+	{regexp.MustCompile(`(?i)^[^:]*:\d+:[0-9a-f]{32}:[0-9a-f]{32}:::$`), []string{"pwdump / secretsdump line: LM:NTLM, crack the second field (hashcat -m 1000)"}},
+	{regexp.MustCompile(`(?i)^\*[0-9a-f]{40}$`), []string{"MySQL 4.1+ (hashcat -m 300)"}},
+	{regexp.MustCompile(`^\{SSHA\}[A-Za-z0-9+/=]+$`), []string{"LDAP salted SHA-1 (hashcat -m 111)"}},
+	{regexp.MustCompile(`^\{SHA\}[A-Za-z0-9+/=]{28}$`), []string{"LDAP SHA-1 (hashcat -m 101)"}},
+	{regexp.MustCompile(`(?i)^0x0100[0-9a-f]{48}$`), []string{"MSSQL 2005 (hashcat -m 132)"}},
+	{regexp.MustCompile(`(?i)^0x0200[0-9a-f]{136}$`), []string{"MSSQL 2012+ (hashcat -m 1731)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{16}$`), []string{"MySQL 3.23 (hashcat -m 200)", "half an LM hash (hashcat -m 3000)", "CRC-64"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{32}$`), []string{"MD5 (hashcat -m 0)", "NTLM (hashcat -m 1000)", "MD4 (hashcat -m 900)", "LM (hashcat -m 3000)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{32}:.+$`), []string{"salted MD5, md5($pass.$salt) (hashcat -m 10) or md5($salt.$pass) (hashcat -m 20)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{40}$`), []string{"SHA-1 (hashcat -m 100)", "RIPEMD-160 (hashcat -m 6000)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{40}:.+$`), []string{"salted SHA-1, sha1($pass.$salt) (hashcat -m 110) or sha1($salt.$pass) (hashcat -m 120)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{56}$`), []string{"SHA-224 (hashcat -m 1300)", "SHA3-224 (hashcat -m 17300)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{64}$`), []string{"SHA-256 (hashcat -m 1400)", "SHA3-256 (hashcat -m 17400)", "Keccak-256 (hashcat -m 17800)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{96}$`), []string{"SHA-384 (hashcat -m 10800)", "SHA3-384 (hashcat -m 17500)"}},
+	{regexp.MustCompile(`(?i)^[0-9a-f]{128}$`), []string{"SHA-512 (hashcat -m 1700)", "SHA3-512 (hashcat -m 17600)", "Whirlpool (hashcat -m 6100)"}},
+	{regexp.MustCompile(`^[./A-Za-z0-9]{13}$`), []string{"DES crypt (hashcat -m 1500)"}},
+}
+
+// identifyHash names the hash formats b could be, most likely first
+func identifyHash(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	for _, format := range hashFormats {
+		if format.pattern.MatchString(s) {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Possible types (%d characters), most likely first:\n", len(s))
+			for _, name := range format.names {
+				sb.WriteString("- " + name + "\n")
+			}
+			return strings.TrimRight(sb.String(), "\n")
+		}
+	}
+	return fmt.Sprintf("Unrecognized hash format (%d characters). It may be encoded: try base64_decode or hex_decode first.", len(s))
+}
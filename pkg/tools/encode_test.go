@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTool_Operations(t *testing.T) {
+	tool := NewEncodeTool()
+	tests := []struct {
+		operations string
+		input      string
+		format     string
+		want       string
+	}{
+		{"base64_encode", "admin:admin", "", "YWRtaW46YWRtaW4="},
+		{"base64_decode", "YWRtaW46YWRtaW4", "", "admin:admin"},
+		{"url_decode,base64_decode", "eyJyb2xlIjoiYWRtaW4ifQ%3D%3D", "", `{"role":"admin"}`},
+		{"hex_decode", "0x68 0x69", "", "hi"},
+		{"url_encode", "a b&c=d", "", "a+b%26c%3Dd"},
+		{"url_encode_all", "<a>", "", "%3C%61%3E"},
+		{"html_decode", "&lt;script&gt;", "", "<script>"},
+		{"rot13", "Uryyb", "", "Hello"},
+		{"md5", "password", "", "5f4dcc3b5aa765d61d8327deb882cf99"},
+		{"sha256,base64_encode", "", "", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		{"ntlm", "password", "", "8846f7eaee8fb117ad06bdd830b7586c"},
+		{"utf16le,base64_encode", "whoami", "", "dwBoAG8AYQBtAGkA"},
+		{"gzip,gunzip", "round trip", "", "round trip"},
+		{"base64_decode,inflate", "eJzLSM3JyVcozy/KSQEAGgsEXQ==", "", "hello world"},
+		{"hex_encode", "AAEC", "base64", "000102"},
+		{"base64_decode", "AAEC", "", "000102\n[binary, 3 bytes, shown as hex]"},
+	}
+
+	for _, tt := range tests {
+		args := map[string]any{"operations": tt.operations, "input": tt.input}
+		if tt.format != "" {
+			args["input_format"] = tt.format
+		}
+		result := tool.Execute(context.Background(), args)
+		if result.IsError || result.ForLLM != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.operations, tt.input, result.ForLLM, tt.want)
+		}
+	}
+}
+
+func TestEncodeTool_HashReports(t *testing.T) {
+	tool := NewEncodeTool()
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"operations": "hash", "input": "abc"})
+	for _, want := range []string{
+		"MD5:      900150983cd24fb0d6963f7d28e17f72",
+		"SHA-256:  ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"CRC32:    352441c2",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("hash output missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+
+	for input, want := range map[string]string{
+		"5f4dcc3b5aa765d61d8327deb882cf99":                                         "- MD5 (hashcat -m 0)",
+		"$2b$12$KIXQJz0Vw1gK6L5a7uJ0ZeG9W0WzZpQ1tP8K9rJ0f1e2d3c4b5a6m":             "bcrypt (hashcat -m 3200)",
+		"$6$saltsalt$" + strings.Repeat("a", 86):                                   "sha512crypt (hashcat -m 1800)",
+		"alice::CORP:1122334455667788:" + strings.Repeat("ab", 16) + ":0101000000": "NetNTLMv2 (hashcat -m 5600)",
+		"not a hash!": "Unrecognized hash format",
+	} {
+		result := tool.Execute(ctx, map[string]any{"operations": "identify_hash", "input": input})
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("identify_hash(%q) = %q, want %q", input, result.ForLLM, want)
+		}
+	}
+
+	for _, ops := range []string{"hash,base64_encode", "frobnicate"} {
+		if result := tool.Execute(ctx, map[string]any{"operations": ops, "input": "x"}); !result.IsError {
+			t.Errorf("%s: expected an error, got %q", ops, result.ForLLM)
+		}
+	}
+}
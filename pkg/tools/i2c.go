@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -155,3 +156,26 @@ func parseI2CBus(args map[string]any) (string, *ToolResult) {
 	}
 	return bus, nil
 }
+
+// SelfTest checks that every I2C bus can be opened for reading and writing,
+// without addressing any device on it
+func (t *I2CTool) SelfTest(_ context.Context, _ SelfTestOptions) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", &NoHardwareError{Reason: "I2C is only supported on Linux"}
+	}
+	matches, err := filepath.Glob("/dev/i2c-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to scan for I2C buses: %w", err)
+	}
+	if len(matches) == 0 {
+		return "", &NoHardwareError{Reason: "no I2C buses found (load the i2c-dev module and enable I2C in the device tree)"}
+	}
+	for _, path := range matches {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return "", fmt.Errorf("%w (check permissions, e.g. the i2c group)", err)
+		}
+		f.Close()
+	}
+	return fmt.Sprintf("%d bus(es) accessible", len(matches)), nil
+}
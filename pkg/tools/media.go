@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// imagePathPattern matches absolute or relative file paths ending in a common
+// raster image extension inside free-form tool output.
+var imagePathPattern = regexp.MustCompile(`(?i)(?:file://)?((?:~|\.{1,2})?/?[\w./-]*[\w-]+\.(?:png|jpe?g|gif|webp))\b`)
+
+// IsImageFile reports whether path has a raster image extension.
+func IsImageFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+// ImageArtifacts returns the image files a tool result produced: any image
+// entries in Media, plus image paths mentioned in the output that exist
+// inside workspace. Relative paths are resolved against workspace; paths the
+// output mentions elsewhere (~/…, /etc/…) are ignored, so a model can't get
+// arbitrary files shown or uploaded by naming them. Duplicates are dropped.
+func ImageArtifacts(result *ToolResult, workspace string) []string {
+	if result == nil || result.IsError {
+		return nil
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if seen[path] {
+			return
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+
+	// Media is set by the tool itself, not by what it printed
+	for _, path := range result.Media {
+		if IsImageFile(path) {
+			if !filepath.IsAbs(path) && workspace != "" {
+				path = filepath.Join(workspace, path)
+			}
+			add(path)
+		}
+	}
+
+	if workspace == "" {
+		return paths
+	}
+	output := result.RawOutput
+	if output == "" {
+		output = result.ForLLM
+	}
+	for _, match := range imagePathPattern.FindAllStringSubmatch(output, -1) {
+		if strings.HasPrefix(match[1], "~") {
+			continue
+		}
+		if path, err := validatePath(match[1], workspace, true); err == nil {
+			add(path)
+		}
+	}
+
+	return paths
+}
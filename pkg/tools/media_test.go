@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageArtifacts(t *testing.T) {
+	workspace := t.TempDir()
+	shot := filepath.Join(workspace, "shots", "login.png")
+	if err := os.MkdirAll(filepath.Dir(shot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shot, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	capture := filepath.Join(workspace, "capture.jpg")
+	if err := os.WriteFile(capture, []byte("jpg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewToolResult("Saved screenshot to shots/login.png (missing.png not written)")
+	result.Media = []string{capture, filepath.Join(workspace, "report.txt")}
+
+	got := ImageArtifacts(result, workspace)
+	if len(got) != 2 || got[0] != capture || got[1] != shot {
+		t.Fatalf("ImageArtifacts = %v, want [%s %s]", got, capture, shot)
+	}
+
+	// Images the output names outside the workspace are not picked up
+	outside := filepath.Join(t.TempDir(), "secret.png")
+	if err := os.WriteFile(outside, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "link.png")); err != nil {
+		t.Fatal(err)
+	}
+	named := NewToolResult("Wrote " + outside + ", ../secret.png, ~/secret.png and link.png")
+	if got := ImageArtifacts(named, workspace); len(got) != 0 {
+		t.Errorf("ImageArtifacts = %v, want none outside the workspace", got)
+	}
+	named.Media = []string{outside}
+	if got := ImageArtifacts(named, workspace); len(got) != 1 || got[0] != outside {
+		t.Errorf("ImageArtifacts = %v, want the tool's own media %s", got, outside)
+	}
+
+	result.IsError = true
+	if got := ImageArtifacts(result, workspace); len(got) != 0 {
+		t.Errorf("Expected no images for error results, got %v", got)
+	}
+}
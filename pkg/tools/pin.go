@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PinStore persists pinned facts that must survive context compaction.
+type PinStore interface {
+	AddPin(text string) error
+	ReadPins() []string
+}
+
+// PinTool lets the agent pin critical constraints so they stay in the system
+// prompt for the entire mission.
+type PinTool struct {
+	store PinStore
+}
+
+func NewPinTool(store PinStore) *PinTool {
+	return &PinTool{store: store}
+}
+
+func (t *PinTool) Name() string {
+	return "pin"
+}
+
+func (t *PinTool) Description() string {
+	return "Pin a critical fact or constraint (scope boundaries, credentials policy, client contact rules) so it stays in context for the entire mission and is never dropped by compaction. Use sparingly; pinned items are sent with every request."
+}
+
+func (t *PinTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The fact or constraint to pin, as a single self-contained sentence",
+			},
+		},
+		"required": []string{"text"},
+	}
+}
+
+func (t *PinTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	text, _ := args["text"].(string)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrorResult("text is required")
+	}
+
+	for _, existing := range t.store.ReadPins() {
+		if strings.EqualFold(existing, text) {
+			return NewToolResult("Already pinned")
+		}
+	}
+
+	if err := t.store.AddPin(text); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to pin: %v", err)).WithError(err)
+	}
+	return NewToolResult(fmt.Sprintf("Pinned (%d total): %s", len(t.store.ReadPins()), text))
+}
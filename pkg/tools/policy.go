@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ToolPolicy restricts which tools a registry offers and runs, such as the
+// per-phase tool limits of an active mission. CheckCommand vets each command
+// an exec call would run.
+type ToolPolicy interface {
+	CheckTool(name string) error
+	CheckCommand(command string) error
+}
+
+// ScopePolicy is implemented by policies that also limit the hosts network
+// tools may reach, such as an active mission's scope. CheckHosts vets the
+// hosts named in text, a command line or URL; network is set when text is
+// the input of a network command or tool, so bare hostnames in it count.
+type ScopePolicy interface {
+	CheckHosts(text string, network bool) error
+}
+
+// NetworkTool is implemented by tools that can reach hosts on their own,
+// such as MCP tools or python with network access. ScopeText returns the
+// text naming the hosts a call would reach, checked against the scope like
+// a network command, or "" when the call can't reach the network.
+type NetworkTool interface {
+	Tool
+	ScopeText(args map[string]any) string
+}
+
+// networkPrograms are the programs that make an exec command a network
+// command, whose bare hostnames have to be in scope
+var networkPrograms = map[string]bool{
+	"nmap": true, "masscan": true, "naabu": true, "rustscan": true, "zmap": true,
+	"curl": true, "wget": true, "httpie": true, "http": true, "https": true,
+	"nc": true, "ncat": true, "netcat": true, "socat": true, "telnet": true,
+	"ssh": true, "scp": true, "sftp": true, "ftp": true, "rsync": true,
+	"dig": true, "host": true, "nslookup": true, "whois": true, "drill": true,
+	"ping": true, "ping6": true, "traceroute": true, "tracepath": true, "mtr": true, "hping3": true,
+	"nuclei": true, "httpx": true, "httprobe": true, "katana": true, "gospider": true, "hakrawler": true,
+	"ffuf": true, "gobuster": true, "feroxbuster": true, "dirsearch": true, "dirb": true, "wfuzz": true,
+	"nikto": true, "wpscan": true, "whatweb": true, "sqlmap": true, "dalfox": true, "arjun": true,
+	"subfinder": true, "amass": true, "assetfinder": true, "dnsx": true, "dnsrecon": true,
+	"shuffledns": true, "puredns": true, "fierce": true, "gau": true, "waybackurls": true, "subjs": true,
+	"sslscan": true, "sslyze": true, "testssl": true, "testssl.sh": true, "tlsx": true, "openssl": true,
+	"hydra": true, "medusa": true, "smbclient": true, "smbmap": true, "rpcclient": true, "enum4linux": true,
+	"crackmapexec": true, "netexec": true, "nxc": true, "ldapsearch": true, "snmpwalk": true,
+	"onesixtyone": true, "evil-winrm": true, "chromium": true, "chromium-browser": true, "google-chrome": true,
+}
+
+// networkCommand reports whether command runs a network program anywhere in
+// it, including inside sh -c strings and after xargs
+func networkCommand(command string) bool {
+	for _, word := range strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune(" \t\n|;&()`'\"", r)
+	}) {
+		name := filepath.Base(word)
+		if networkPrograms[name] || strings.HasPrefix(name, "impacket-") {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPolicy makes the registry consult the policy fn returns before offering
+// or running a tool. fn may return nil when nothing is restricted.
+func (r *ToolRegistry) SetPolicy(fn func() ToolPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = fn
+}
+
+// currentPolicy returns the policy in force, or nil. The caller must hold mu.
+func (r *ToolRegistry) currentPolicy() ToolPolicy {
+	if r.policy == nil {
+		return nil
+	}
+	return r.policy()
+}
+
+// offeredBy reports whether policy lets the model see the tool
+func offeredBy(policy ToolPolicy, name string) bool {
+	return policy == nil || policy.CheckTool(name) == nil
+}
+
+// checkPolicy returns why a call to tool may not run under the current
+// policy
+func (r *ToolRegistry) checkPolicy(tool Tool, args map[string]any) error {
+	r.mu.RLock()
+	policy := r.currentPolicy()
+	r.mu.RUnlock()
+	if policy == nil {
+		return nil
+	}
+
+	name := tool.Name()
+	if err := policy.CheckTool(name); err != nil {
+		return err
+	}
+	command, _ := args["command"].(string)
+	if name == "exec" {
+		for _, cmd := range CommandNames(command) {
+			if err := policy.CheckCommand(cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	scope, ok := policy.(ScopePolicy)
+	if !ok {
+		return nil
+	}
+	switch name {
+	case "exec":
+		return scope.CheckHosts(command, networkCommand(command))
+	case "web_fetch":
+		url, _ := args["url"].(string)
+		return scope.CheckHosts(url, true)
+	}
+	if networkTool, ok := tool.(NetworkTool); ok {
+		if text := networkTool.ScopeText(args); text != "" {
+			return scope.CheckHosts(text, true)
+		}
+	}
+	return nil
+}
+
+// ArgsScopeText joins the strings in args, nested ones included, one per
+// line. It is the ScopeText of tools whose every argument may name a host,
+// such as MCP tools.
+func ArgsScopeText(args map[string]any) string {
+	var sb strings.Builder
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			sb.WriteString(v)
+			sb.WriteString("\n")
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		}
+	}
+	walk(args)
+	return sb.String()
+}
+
+// shellWrappers run the command that follows them
+var shellWrappers = map[string]bool{
+	"sudo": true, "env": true, "nohup": true, "time": true, "nice": true, "timeout": true, "exec": true, "command": true,
+}
+
+// wrapperValueFlags take a value, as in sudo -u root or nice -n 10
+var wrapperValueFlags = map[string]bool{"-u": true, "-g": true, "-n": true, "-s": true, "-k": true}
+
+// CommandNames returns the programs a shell command line runs: the first
+// word of each pipeline, list and subshell segment, past variable
+// assignments and wrappers such as sudo or timeout.
+func CommandNames(command string) []string {
+	segments := strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune("|;&\n()`", r)
+	})
+
+	var names []string
+	for _, segment := range segments {
+		fields := strings.Fields(segment)
+		for i := 0; i < len(fields); i++ {
+			word := strings.Trim(fields[i], `"'{}`)
+			switch {
+			case word == "" || word == "$" || strings.Contains(word, "="):
+				continue
+			case strings.HasPrefix(word, "-"):
+				if wrapperValueFlags[word] {
+					i++
+				}
+				continue // Wrapper flags such as sudo -u root
+			case shellWrappers[word]:
+				if word == "timeout" && i+1 < len(fields) {
+					i++ // Skip the duration
+				}
+				continue
+			}
+			names = append(names, filepath.Base(word))
+			break
+		}
+	}
+	return names
+}
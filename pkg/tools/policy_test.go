@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const policyWorkflow = `---
+name: firmware
+phases: [recon, exploit]
+tools:
+  deny: [profile:port-scan]
+---
+
+## Phase: recon
+
+### Steps
+
+- inventory: Inventory the device (required)
+
+### Tools
+
+- allow: exec, read_file
+- deny: sqlmap, msf*
+
+## Phase: exploit
+
+### Steps
+
+- exploit: Exploit it (required)
+`
+
+func TestCommandNames(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"nmap -sS 10.0.0.5", []string{"nmap"}},
+		{"cat hosts.txt | /usr/bin/httpx -silent && echo done", []string{"cat", "httpx", "echo"}},
+		{"sudo -u root timeout 30 masscan -p80 10.0.0.0/24", []string{"masscan"}},
+		{"FOO=1 ./scan.sh; echo $(whoami)", []string{"scan.sh", "echo", "whoami"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := CommandNames(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommandNames(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestToolRegistry_MissionPolicy(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(policyWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	engine := workflow.NewEngine(wf, "device.local", t.TempDir())
+
+	r := NewToolRegistry()
+	for _, name := range []string{"exec", "read_file", "web_fetch", "workflow_add_finding"} {
+		r.Register(newMockTool(name, name))
+	}
+	var active *workflow.Engine
+	r.SetPolicy(func() ToolPolicy {
+		if active == nil {
+			return nil
+		}
+		return active
+	})
+
+	// Without a mission everything is on offer
+	if got := len(r.ToProviderDefs()); got != 4 {
+		t.Errorf("got %d tools without a mission, want 4", got)
+	}
+
+	active = engine
+	var names []string
+	for _, def := range r.ToProviderDefs() {
+		names = append(names, def.Function.Name)
+	}
+	if want := []string{"exec", "read_file", "workflow_add_finding"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("recon tools = %v, want %v", names, want)
+	}
+	if summaries := r.GetSummaries(); len(summaries) != 3 {
+		t.Errorf("summaries = %v", summaries)
+	}
+
+	ctx := context.Background()
+	blocked := []struct {
+		tool, command, reason string
+	}{
+		{"web_fetch", "", "phase recon"},
+		{"exec", "sqlmap -u http://device.local", "phase recon"},
+		{"exec", "ls && msfconsole -q", "phase recon"},
+		{"exec", "sudo nmap -sS device.local", "workflow firmware"},
+	}
+	for _, b := range blocked {
+		result := r.Execute(ctx, b.tool, map[string]any{"command": b.command})
+		if !result.IsError || !strings.Contains(result.ForLLM, b.reason) {
+			t.Errorf("%s %q = %+v, want blocked by %s", b.tool, b.command, result, b.reason)
+		}
+	}
+	if result := r.Execute(ctx, "exec", map[string]any{"command": "strings fw.bin | grep -i pass"}); result.IsError {
+		t.Errorf("allowed command blocked: %s", result.ForLLM)
+	}
+
+	// Later phases only keep the workflow-wide limits
+	if err := engine.AdvancePhase(); err != nil {
+		t.Fatal(err)
+	}
+	if result := r.Execute(ctx, "web_fetch", nil); result.IsError {
+		t.Errorf("web_fetch blocked in exploit phase: %s", result.ForLLM)
+	}
+	if result := r.Execute(ctx, "exec", map[string]any{"command": "masscan -p1-65535 device.local"}); !result.IsError {
+		t.Error("masscan allowed despite the workflow-wide deny")
+	}
+
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "Tool Limits") {
+		t.Errorf("mission context lacks tool limits:\n%s", prompt)
+	}
+}
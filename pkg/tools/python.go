@@ -0,0 +1,412 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// Python sandbox modes
+const (
+	PythonSandboxAuto       = "auto"       // docker, failing if it isn't installed (default)
+	PythonSandboxDocker     = "docker"     // A throwaway container with only the mission directory mounted
+	PythonSandboxSubprocess = "subprocess" // A local interpreter restricted by an audit hook; opt-in only
+)
+
+const (
+	defaultPythonImage    = "python:3.12-slim"
+	defaultPythonTimeout  = 120 * time.Second
+	defaultPythonMemoryMB = 512
+
+	// pythonMountPoint is where the sandbox directory appears in a container
+	pythonMountPoint = "/mission"
+)
+
+// pythonGuard runs a script under an audit hook that confines file writes
+// to the working directory, file reads to it and the Python installation,
+// and refuses process creation, native code loading and, unless allowed,
+// network access.
+//
+//go:embed python_guard.py
+var pythonGuard string
+
+// PythonTool runs Python scripts the agent writes in an isolated
+// interpreter. The mission directory (or {workspace}/sandbox without a
+// mission) is the script's working directory and the only place it can
+// write; scripts are kept under scripts/ so results can be reproduced.
+type PythonTool struct {
+	workspace   string
+	getEngine   func() *workflow.Engine
+	sandbox     string // PythonSandboxDocker or PythonSandboxSubprocess
+	image       string
+	interpreter string // Resolved interpreter path in subprocess mode
+	timeout     time.Duration
+	memoryMB    int
+	network     bool
+	truncator   *Truncator
+}
+
+// NewPythonTool configures the python tool. It fails if the configured
+// sandbox is unknown or nothing to run Python with is installed.
+func NewPythonTool(workspace string, getEngine func() *workflow.Engine, cfg *config.Config) (*PythonTool, error) {
+	var pc config.PythonConfig
+	var truncator *Truncator
+	if cfg != nil {
+		pc = cfg.Tools.Python
+		truncator = NewTruncator(cfg.Tools.Truncation)
+	}
+
+	t := &PythonTool{
+		workspace: workspace,
+		getEngine: getEngine,
+		image:     pc.Image,
+		timeout:   time.Duration(pc.TimeoutSeconds) * time.Second,
+		memoryMB:  pc.MemoryMB,
+		network:   pc.AllowNetwork,
+		truncator: truncator,
+	}
+	if t.image == "" {
+		t.image = defaultPythonImage
+	}
+	if t.timeout <= 0 {
+		t.timeout = defaultPythonTimeout
+	}
+	if t.memoryMB <= 0 {
+		t.memoryMB = defaultPythonMemoryMB
+	}
+
+	sandbox := pc.Sandbox
+	switch sandbox {
+	case "", PythonSandboxAuto:
+		// The subprocess sandbox is no security boundary, so it is never
+		// picked for the operator
+		if _, err := exec.LookPath("docker"); err != nil {
+			return nil, fmt.Errorf("python sandbox %s needs docker, which isn't installed; set tools.python.sandbox to %s to run scripts on this host under an audit hook, which is not a security boundary",
+				PythonSandboxAuto, PythonSandboxSubprocess)
+		}
+		sandbox = PythonSandboxDocker
+	case PythonSandboxDocker, PythonSandboxSubprocess:
+	default:
+		return nil, fmt.Errorf("unknown python sandbox %q (want auto, docker or subprocess)", pc.Sandbox)
+	}
+	t.sandbox = sandbox
+
+	if sandbox == PythonSandboxDocker {
+		if _, err := exec.LookPath("docker"); err != nil {
+			return nil, fmt.Errorf("python sandbox docker: %w", err)
+		}
+		return t, nil
+	}
+	logger.WarnCF("tool", "Python scripts run on this host under an audit hook, which is not a security boundary", map[string]any{
+		"sandbox": sandbox,
+		"network": t.network,
+	})
+
+	interpreter := pc.Interpreter
+	if interpreter == "" {
+		interpreter = "python3"
+	}
+	resolved, err := resolvePythonInterpreter(interpreter)
+	if err != nil {
+		return nil, err
+	}
+	t.interpreter = resolved
+	return t, nil
+}
+
+// resolvePythonInterpreter returns the real interpreter behind name, so
+// version-manager shims keep working in the sandbox's bare environment
+func resolvePythonInterpreter(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("python interpreter %q not found: %w", name, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "-I", "-c", "import sys; assert sys.version_info >= (3, 8); print(sys.executable)").Output()
+	if err != nil {
+		return "", fmt.Errorf("python interpreter %q is unusable (Python 3.8 or later is required): %w", name, err)
+	}
+	if resolved := strings.TrimSpace(string(out)); resolved != "" {
+		return resolved, nil
+	}
+	return path, nil
+}
+
+func (t *PythonTool) Name() string {
+	return "python"
+}
+
+func (t *PythonTool) Description() string {
+	network := "no network access"
+	if t.network {
+		network = "network access"
+	}
+	return fmt.Sprintf("Run a Python 3 script in a sandbox (%s, %s) for quick analysis: decoding tokens, parsing custom formats, crunching tool output. "+
+		"The working directory is the mission directory, the only place the script can write; write results there and read earlier ones back. "+
+		"It cannot run other programs. Only the standard library is guaranteed.", t.sandbox, network)
+}
+
+func (t *PythonTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"description": "The Python source to run. Print what you want to see",
+			},
+			"filename": map[string]any{
+				"type":        "string",
+				"description": "Optional name to save the script under in scripts/, e.g. decode_jwt.py, so it can be rerun",
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Optional command-line arguments (sys.argv[1:])",
+			},
+		},
+		"required": []string{"code"},
+	}
+}
+
+// pythonStringPattern matches Python string literals, where a script names
+// the hosts it connects to
+var pythonStringPattern = regexp.MustCompile(`"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'`)
+
+// ScopeText returns the string literals and arguments of a script that may
+// reach the network, so the hosts it names are checked against the scope.
+// Dotted names in the code itself are attributes, not hosts.
+func (t *PythonTool) ScopeText(args map[string]any) string {
+	if !t.network {
+		return ""
+	}
+	code, _ := args["code"].(string)
+	var sb strings.Builder
+	for _, literal := range pythonStringPattern.FindAllString(code, -1) {
+		sb.WriteString(literal[1 : len(literal)-1])
+		sb.WriteString("\n")
+	}
+	if raw, ok := args["args"].([]any); ok {
+		for _, a := range raw {
+			sb.WriteString(fmt.Sprint(a))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func (t *PythonTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	code, ok := args["code"].(string)
+	if !ok || strings.TrimSpace(code) == "" {
+		return ErrorResult("code is required")
+	}
+	var scriptArgs []string
+	if raw, ok := args["args"].([]any); ok {
+		for _, a := range raw {
+			scriptArgs = append(scriptArgs, fmt.Sprint(a))
+		}
+	}
+
+	dir, err := t.sandboxDir()
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	filename, _ := args["filename"].(string)
+	script, err := saveScript(dir, filename, code)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	rel, _ := filepath.Rel(dir, script)
+	rel = filepath.ToSlash(rel)
+
+	cmdCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	container := ""
+	if t.sandbox == PythonSandboxDocker {
+		container = fmt.Sprintf("picoclaw-python-%d", time.Now().UnixNano())
+		vars := SessionVars(ctx)
+		cmd = exec.CommandContext(cmdCtx, "docker", t.dockerArgs(container, dir, rel, sortedKeys(vars), scriptArgs)...)
+		cmd.Env = os.Environ()
+		for name, value := range vars {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	} else {
+		cmd = exec.CommandContext(cmdCtx, t.interpreter, append([]string{"-I", "-c", pythonGuard, rel}, scriptArgs...)...)
+		cmd.Dir = dir
+		cmd.Env = t.subprocessEnv(ctx, dir)
+	}
+	prepareCommandForTermination(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to start python: %v", err))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-cmdCtx.Done():
+		if container != "" {
+			// Killing the docker client leaves the container running
+			_ = exec.Command("docker", "kill", container).Run()
+		}
+		_ = terminateProcessTree(cmd)
+		select {
+		case err = <-done:
+		case <-time.After(2 * time.Second):
+			if cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+			err = <-done
+		}
+	}
+
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		msg := fmt.Sprintf("Script timed out after %v (saved as %s)", t.timeout, rel)
+		return &ToolResult{ForLLM: msg, ForUser: msg, IsError: true}
+	}
+
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\nSTDERR:\n" + stderr.String()
+	}
+	if err != nil {
+		output += fmt.Sprintf("\nExit code: %v", err)
+	}
+	if strings.TrimSpace(output) == "" {
+		output = "(no output)"
+	}
+	raw := output
+	output = t.truncator.Truncate(output, "python", t.Name()) + "\nScript: " + rel
+
+	return &ToolResult{ForLLM: output, ForUser: output, RawOutput: raw, IsError: err != nil}
+}
+
+// sandboxDir returns the directory scripts run in: the active mission's
+// directory, or {workspace}/sandbox without a mission
+func (t *PythonTool) sandboxDir() (string, error) {
+	dir := filepath.Join(t.workspace, "sandbox")
+	if t.getEngine != nil {
+		if engine := t.getEngine(); engine != nil {
+			dir = engine.MissionDir()
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	return dir, nil
+}
+
+// saveScript writes code to dir/scripts, under filename if given or a
+// timestamped name otherwise, and returns its path
+func saveScript(dir, filename, code string) (string, error) {
+	name := filepath.Base(filepath.Clean("/" + filename))
+	if name == "/" || name == "." {
+		name = "script_" + time.Now().Format("20060102-150405.000") + ".py"
+	} else if !strings.HasSuffix(name, ".py") {
+		name += ".py"
+	}
+	path := filepath.Join(dir, "scripts", name)
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		return "", fmt.Errorf("failed to save script: %w", err)
+	}
+	return path, nil
+}
+
+// dockerArgs runs script in a throwaway container: no network unless
+// allowed, a read-only root filesystem, capped memory and processes, and
+// dir mounted read-write as the working directory. Variables in env are
+// passed through from the docker client's environment.
+func (t *PythonTool) dockerArgs(container, dir, script string, env, scriptArgs []string) []string {
+	args := []string{
+		"run", "--rm", "--name", container,
+		"--read-only", "--tmpfs", "/tmp",
+		"--memory", fmt.Sprintf("%dm", t.memoryMB),
+		"--pids-limit", "64",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"-e", "HOME=" + pythonMountPoint,
+		"-e", "PYTHONDONTWRITEBYTECODE=1",
+		"-v", dir + ":" + pythonMountPoint + ":rw",
+		"-w", pythonMountPoint,
+	}
+	for _, name := range env {
+		args = append(args, "-e", name)
+	}
+	if !t.network {
+		args = append(args, "--network", "none")
+	}
+	if runtime.GOOS != "windows" {
+		// Files the script writes stay owned by the operator
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+	args = append(args, t.image, "python", "-I", script)
+	return append(args, scriptArgs...)
+}
+
+// subprocessEnv is the bare environment of a subprocess sandbox. Session
+// variables are exported as in exec, so scripts read tokens from os.environ
+// instead of having them written into the saved script.
+func (t *PythonTool) subprocessEnv(ctx context.Context, dir string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"LANG=C.UTF-8",
+		"PYTHONDONTWRITEBYTECODE=1",
+		"PICOCLAW_SANDBOX_MEMORY=" + strconv.Itoa(t.memoryMB*1024*1024),
+	}
+	if t.network {
+		env = append(env, "PICOCLAW_SANDBOX_NETWORK=1")
+	}
+	for name, value := range SessionVars(ctx) {
+		env = append(env, name+"="+value)
+	}
+	return env
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SelfTest runs a one-line script in the sandbox the agent's scripts use
+func (t *PythonTool) SelfTest(ctx context.Context, _ SelfTestOptions) (string, error) {
+	result := t.Execute(ctx, map[string]any{"code": "print(6 * 7)", "filename": "selftest.py"})
+	if result.IsError {
+		return "", errors.New(result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "42") {
+		return "", fmt.Errorf("unexpected output: %s", strings.TrimSpace(result.ForLLM))
+	}
+	if t.sandbox == PythonSandboxDocker {
+		return "docker sandbox (" + t.image + ")", nil
+	}
+	return "subprocess sandbox (" + t.interpreter + ")", nil
+}
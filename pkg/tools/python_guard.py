@@ -0,0 +1,86 @@
+# Runs a script from the python tool's subprocess sandbox:
+#   python3 -I -c <this file> script.py [args...]
+# An audit hook confines writes to the working directory (the mission
+# directory), reads to it and the Python installation, and refuses process
+# creation, native code loading and, unless PICOCLAW_SANDBOX_NETWORK=1,
+# network access. Audit hooks are a guard rail, not a security boundary; use
+# the docker sandbox for code you don't trust.
+import os
+import runpy
+import sys
+
+_root = os.path.realpath(os.getcwd())
+_network = os.environ.pop("PICOCLAW_SANDBOX_NETWORK", "") == "1"
+_memory = int(os.environ.pop("PICOCLAW_SANDBOX_MEMORY", "0") or 0)
+
+_readable = {_root, "/etc/ssl", "/usr/share/zoneinfo"}
+for _p in (sys.prefix, sys.base_prefix, sys.exec_prefix, sys.base_exec_prefix, *sys.path):
+    if _p:
+        _readable.add(os.path.realpath(_p))
+_readable = tuple(_readable)
+
+_write_flags = os.O_WRONLY | os.O_RDWR | os.O_CREAT | os.O_APPEND | os.O_TRUNC
+
+# Events whose first n arguments are paths the script changes
+_write_events = {
+    "os.chmod": 1, "os.chown": 1, "os.link": 2, "os.mkdir": 1, "os.remove": 1,
+    "os.rename": 2, "os.rmdir": 1, "os.symlink": 2, "os.truncate": 1,
+    "os.utime": 1, "shutil.chown": 1, "shutil.copyfile": 2, "shutil.copymode": 2,
+    "shutil.copystat": 2, "shutil.copytree": 2, "shutil.move": 2,
+    "shutil.rmtree": 1,
+}
+_read_events = {"os.listdir", "os.scandir", "glob.glob"}
+_blocked = {
+    "os.exec", "os.fork", "os.forkpty", "os.kill", "os.killpg", "os.posix_spawn",
+    "os.spawn", "os.startfile", "os.system", "pty.spawn", "subprocess.Popen",
+    "ctypes.dlopen", "ctypes.dlsym", "ctypes.addressof", "ctypes.cdata",
+}
+_blocked_imports = {"_posixsubprocess", "_ctypes"}
+_network_events = {
+    "socket.bind", "socket.connect", "socket.getaddrinfo", "socket.gethostbyname",
+    "socket.gethostbyaddr", "socket.sendto", "socket.sendmsg",
+}
+
+
+def _inside(path, roots):
+    return any(path == r or path.startswith(r.rstrip(os.sep) + os.sep) for r in roots)
+
+
+def _check(path, write):
+    if path is None or isinstance(path, int):
+        return
+    resolved = os.path.realpath(os.fsdecode(path))
+    if _inside(resolved, (_root,)) or (not write and _inside(resolved, _readable)):
+        return
+    action = "writing" if write else "reading"
+    raise PermissionError("sandbox: %s outside the mission directory is not allowed: %s" % (action, os.fsdecode(path)))
+
+
+def _audit(event, args):
+    if event == "open":
+        mode, flags = args[1], args[2]
+        write = (isinstance(mode, str) and any(c in mode for c in "wax+")) or bool((flags or 0) & _write_flags)
+        _check(args[0], write)
+    elif event in _write_events:
+        for path in args[:_write_events[event]]:
+            _check(path, True)
+    elif event in _read_events:
+        _check(args[0] if args else None, False)
+    elif event == "import" and args[0] in _blocked_imports:
+        raise ImportError("sandbox: %s is not available" % args[0])
+    elif event in _blocked or (not _network and event in _network_events):
+        raise PermissionError("sandbox: %s is not allowed" % event)
+
+
+if _memory:
+    try:
+        import resource
+
+        resource.setrlimit(resource.RLIMIT_AS, (_memory, _memory))
+    except (ImportError, ValueError, OSError):
+        pass
+
+del sys.argv[0]
+sys.path.insert(0, os.path.dirname(os.path.realpath(sys.argv[0])))
+sys.addaudithook(_audit)
+runpy.run_path(sys.argv[0], run_name="__main__")
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newSubprocessPythonTool(t *testing.T, getEngine func() *workflow.Engine) (*PythonTool, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Tools.Python.Sandbox = PythonSandboxSubprocess
+	tool, err := NewPythonTool(workspace, getEngine, cfg)
+	if err != nil {
+		t.Skipf("no usable python interpreter: %v", err)
+	}
+	return tool, workspace
+}
+
+func TestPythonTool_RunsInMissionDirectory(t *testing.T) {
+	var engine *workflow.Engine
+	tool, workspace := newSubprocessPythonTool(t, func() *workflow.Engine { return engine })
+	engine = workflow.NewEngine(&workflow.Workflow{Name: "wf", Phases: []workflow.Phase{{Name: "recon"}}}, "app.example.com", workspace)
+
+	ctx := WithSessionVars(context.Background(), map[string]string{"AUTH_TOKEN": "tok-123456"})
+	code := `import base64, os, sys
+open("decoded.txt", "w").write(base64.b64decode(sys.argv[1]).decode())
+print(open("decoded.txt").read(), os.environ["AUTH_TOKEN"])
+`
+	result := tool.Execute(ctx, map[string]any{"code": code, "filename": "../decode", "args": []any{"aGVsbG8="}})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.HasPrefix(result.ForLLM, "hello tok-123456\n") || !strings.HasSuffix(result.ForLLM, "\nScript: scripts/decode.py") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+
+	dir := engine.MissionDir()
+	if dir != filepath.Join(workspace, "missions", "app.example.com") {
+		t.Errorf("MissionDir() = %s", dir)
+	}
+	for _, name := range []string{"decoded.txt", "scripts/decode.py"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPythonTool_SubprocessSandbox(t *testing.T) {
+	tool, workspace := newSubprocessPythonTool(t, nil)
+	outside := filepath.Join(workspace, "outside.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, code := range map[string]string{
+		"read outside":  "print(open(" + pyQuote(outside) + ").read())",
+		"write outside": "open(" + pyQuote(filepath.Join(workspace, "new.txt")) + ", 'w')",
+		"traversal":     "open('../outside.txt').read()",
+		"subprocess":    "import subprocess; subprocess.run(['id'])",
+		"os.system":     "import os; os.system('id')",
+		"network":       "import socket; socket.create_connection(('127.0.0.1', 9))",
+		"ctypes":        "import ctypes",
+	} {
+		result := tool.Execute(context.Background(), map[string]any{"code": code})
+		if !result.IsError || !strings.Contains(result.ForLLM, "sandbox:") {
+			t.Errorf("%s: result = %q, want a sandbox error", name, result.ForLLM)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "new.txt")); !os.IsNotExist(err) {
+		t.Error("script wrote outside the sandbox")
+	}
+
+	// The standard library is still readable
+	result := tool.Execute(context.Background(), map[string]any{"code": "import json, hashlib; print(hashlib.sha256(json.dumps([1]).encode()).hexdigest()[:8])"})
+	if digest, _, _ := strings.Cut(result.ForLLM, "\n"); result.IsError || len(digest) != 8 {
+		t.Errorf("stdlib script: %q", result.ForLLM)
+	}
+}
+
+func TestPythonTool_DockerArgs(t *testing.T) {
+	tool := &PythonTool{sandbox: PythonSandboxDocker, image: "python:3.12-slim", memoryMB: 256}
+	args := tool.dockerArgs("c1", "/ws/missions/x", "scripts/a.py", []string{"TOKEN"}, []string{"--verbose"})
+
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm --name c1",
+		"--memory 256m",
+		"-v /ws/missions/x:/mission:rw -w /mission",
+		"-e TOKEN",
+		"--network none",
+		"python:3.12-slim python -I scripts/a.py --verbose",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("docker args %q lack %q", joined, want)
+		}
+	}
+
+	tool.network = true
+	if slices.Contains(tool.dockerArgs("c1", "/d", "a.py", nil, nil), "none") {
+		t.Error("network disabled although allowed")
+	}
+}
+
+func TestNewPythonTool_UnknownSandbox(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.Python.Sandbox = "chroot"
+	if _, err := NewPythonTool(t.TempDir(), nil, cfg); err == nil {
+		t.Error("unknown sandbox accepted")
+	}
+}
+
+func TestNewPythonTool_AutoNeedsDocker(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	for _, sandbox := range []string{"", PythonSandboxAuto} {
+		cfg := config.DefaultConfig()
+		cfg.Tools.Python.Sandbox = sandbox
+		_, err := NewPythonTool(t.TempDir(), nil, cfg)
+		if err == nil || !strings.Contains(err.Error(), "needs docker") || !strings.Contains(err.Error(), "not a security boundary") {
+			t.Errorf("sandbox %q without docker: error = %v", sandbox, err)
+		}
+	}
+}
+
+func pyQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
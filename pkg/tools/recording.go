@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordingsDir is where command recordings are stored, relative to the
+// workspace
+const RecordingsDir = "evidence/recordings"
+
+// Terminal size written to cast headers. Commands run without a TTY, so this
+// only sets the size of the replay window.
+const (
+	castWidth  = 120
+	castHeight = 40
+)
+
+// CastRecorder captures a command run as an asciinema v2 cast: the prompt,
+// the command as typed, and the output as it arrived. Replay a saved cast
+// with `asciinema play`.
+type CastRecorder struct {
+	mu      sync.Mutex
+	start   time.Time
+	command string
+	events  []castEvent
+}
+
+type castEvent struct {
+	at   time.Duration
+	kind string // "i" for input, "o" for output
+	data string
+}
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewCastRecorder starts recording command, typed at a "$ " prompt
+func NewCastRecorder(command string) *CastRecorder {
+	r := &CastRecorder{start: time.Now(), command: command}
+	r.record("o", "$ ")
+	r.record("i", command+"\r")
+	r.record("o", command+"\n")
+	return r
+}
+
+// Write records p as terminal output. It is safe to use the recorder as both
+// stdout and stderr of a command.
+func (r *CastRecorder) Write(p []byte) (int, error) {
+	r.record("o", string(p))
+	return len(p), nil
+}
+
+// Exit records the end of the command with its exit status
+func (r *CastRecorder) Exit(status string) {
+	r.record("o", fmt.Sprintf("\n[%s]\n", status))
+}
+
+func (r *CastRecorder) record(kind, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, castEvent{at: time.Since(r.start), kind: kind, data: data})
+}
+
+// WriteCast writes the recording in asciinema v2 format. redact, if not nil,
+// is applied to the command and every event before it is written.
+func (r *CastRecorder) WriteCast(w io.Writer, redact func(string) string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if redact == nil {
+		redact = func(s string) string { return s }
+	}
+	command := redact(r.command)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	header := castHeader{
+		Version:   2,
+		Width:     castWidth,
+		Height:    castHeight,
+		Timestamp: r.start.Unix(),
+		Command:   command,
+		Title:     command,
+		Env:       map[string]string{"SHELL": "/bin/sh", "TERM": "xterm-256color"},
+	}
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	for _, ev := range r.events {
+		data := redact(ev.data)
+		if ev.kind == "o" {
+			// Pipes carry bare newlines; a terminal needs a carriage return
+			// too or replayed lines run diagonally across the screen
+			data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\n", "\r\n")
+		}
+		if err := enc.Encode([]any{ev.at.Seconds(), ev.kind, data}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Save writes the recording to a new .cast file in dir, named after the
+// start time and the command, and returns its path
+func (r *CastRecorder) Save(dir string, redact func(string) string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	name := r.start.Format("20060102-150405.000")
+	if cmd := sanitizeCastName(commandName(r.command)); cmd != "" {
+		name += "_" + cmd
+	}
+	path := filepath.Join(dir, name+".cast")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create recording: %w", err)
+	}
+	if err := r.WriteCast(f, redact); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write recording: %w", err)
+	}
+	return path, nil
+}
+
+// sanitizeCastName keeps the characters of a command name that are safe in
+// a file name
+func sanitizeCastName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return -1
+	}, name)
+}
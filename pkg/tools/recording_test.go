@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExecTool_Record(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	workspace := t.TempDir()
+	tool := NewExecTool(workspace, false)
+	ctx := WithSessionVars(context.Background(), map[string]string{"TOKEN": "s3cr3t-token"})
+
+	result := tool.Execute(ctx, map[string]any{"command": "echo one; echo s3cr3t-token", "record": true})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	_, rel, ok := strings.Cut(result.ForLLM, "\nRecording: ")
+	if !ok || !strings.HasPrefix(rel, RecordingsDir+"/") || !strings.HasSuffix(rel, "_echo.cast") {
+		t.Fatalf("result = %q, want a recording path", result.ForLLM)
+	}
+
+	f, err := os.Open(filepath.Join(workspace, rel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+
+	scanner.Scan()
+	var header castHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Version != 2 || header.Width == 0 || header.Command != "echo one; echo {{TOKEN}}" {
+		t.Errorf("header = %+v", header)
+	}
+
+	var output, input strings.Builder
+	last := 0.0
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 {
+			t.Fatalf("event %s: %v", scanner.Text(), err)
+		}
+		at := event[0].(float64)
+		if at < last {
+			t.Errorf("event at %v after %v", at, last)
+		}
+		last = at
+		switch event[1] {
+		case "o":
+			output.WriteString(event[2].(string))
+		case "i":
+			input.WriteString(event[2].(string))
+		}
+	}
+	want := "$ echo one; echo {{TOKEN}}\r\none\r\n{{TOKEN}}\r\n\r\n[exit status 0]\r\n"
+	if output.String() != want {
+		t.Errorf("output = %q, want %q", output.String(), want)
+	}
+	if input.String() != "echo one; echo {{TOKEN}}\r" {
+		t.Errorf("input = %q", input.String())
+	}
+}
+
+func TestExecTool_NoRecordByDefault(t *testing.T) {
+	workspace := t.TempDir()
+	result := NewExecTool(workspace, false).Execute(context.Background(), map[string]any{"command": "echo hi"})
+	if strings.Contains(result.ForLLM, "Recording") {
+		t.Errorf("result = %q, want no recording", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, RecordingsDir)); !os.IsNotExist(err) {
+		t.Errorf("recordings directory created: %v", err)
+	}
+}
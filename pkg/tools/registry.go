@@ -2,8 +2,10 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,11 +13,13 @@ import (
 	"github.com/ResistanceIsUseless/picoclaw/pkg/providers"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools/filters"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/tools/profiles"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
 type ToolRegistry struct {
 	tools          map[string]Tool
 	filterRegistry *filters.FilterRegistry
+	policy         func() ToolPolicy
 	mu             sync.RWMutex
 }
 
@@ -126,6 +130,26 @@ func (r *ToolRegistry) ExecuteWithContext(
 		return ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
 	}
 
+	// Variable references are resolved here so exact targets and secrets
+	// never pass through the model. Workflow tools record what the model
+	// wrote: a finding's evidence keeps {{creds.admin}}, not the password.
+	if !strings.HasPrefix(name, "workflow_") {
+		args = expandArgs(ctx, args)
+	}
+
+	if err := r.checkPolicy(tool, args); err != nil {
+		logger.WarnCF("tool", "Tool call blocked by policy",
+			map[string]any{
+				"tool":  name,
+				"error": err.Error(),
+			})
+		hint := "Use a tool the current phase allows."
+		if errors.Is(err, workflow.ErrOutOfScope) {
+			hint = "Stay within the mission scope, or use scope_change_request to ask the operator to widen it."
+		}
+		return ErrorResult(fmt.Sprintf("Tool call blocked: %v. %s", err, hint)).WithError(err)
+	}
+
 	// If tool implements ContextualTool, set context
 	if contextualTool, ok := tool.(ContextualTool); ok && channel != "" && chatID != "" {
 		contextualTool.SetContext(channel, chatID)
@@ -143,6 +167,7 @@ func (r *ToolRegistry) ExecuteWithContext(
 	start := time.Now()
 	result := tool.Execute(ctx, args)
 	duration := time.Since(start)
+	redactResult(SessionVars(ctx), result)
 	if result != nil && result.RawOutput == "" {
 		result.RawOutput = result.ForLLM
 	}
@@ -205,9 +230,12 @@ func (r *ToolRegistry) GetDefinitions() []map[string]any {
 	defer r.mu.RUnlock()
 
 	sorted := r.sortedToolNames()
+	policy := r.currentPolicy()
 	definitions := make([]map[string]any, 0, len(sorted))
 	for _, name := range sorted {
-		definitions = append(definitions, ToolToSchema(r.tools[name]))
+		if offeredBy(policy, name) {
+			definitions = append(definitions, ToolToSchema(r.tools[name]))
+		}
 	}
 	return definitions
 }
@@ -219,8 +247,12 @@ func (r *ToolRegistry) ToProviderDefs() []providers.ToolDefinition {
 	defer r.mu.RUnlock()
 
 	sorted := r.sortedToolNames()
+	policy := r.currentPolicy()
 	definitions := make([]providers.ToolDefinition, 0, len(sorted))
 	for _, name := range sorted {
+		if !offeredBy(policy, name) {
+			continue
+		}
 		tool := r.tools[name]
 		schema := ToolToSchema(tool)
 
@@ -268,8 +300,12 @@ func (r *ToolRegistry) GetSummaries() []string {
 	defer r.mu.RUnlock()
 
 	sorted := r.sortedToolNames()
+	policy := r.currentPolicy()
 	summaries := make([]string, 0, len(sorted))
 	for _, name := range sorted {
+		if !offeredBy(policy, name) {
+			continue
+		}
 		tool := r.tools[name]
 		summaries = append(summaries, fmt.Sprintf("- `%s` - %s", tool.Name(), tool.Description()))
 	}
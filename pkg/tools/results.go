@@ -0,0 +1,339 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResultsDir is where large tool outputs are stored, relative to the
+// workspace
+const ResultsDir = "results"
+
+// MinStoredResultChars is the smallest tool output worth storing; shorter
+// output is cheap enough to run again
+const MinStoredResultChars = 2000
+
+const (
+	defaultExtractContext    = 2
+	maxExtractContext        = 20
+	defaultExtractMaxMatches = 50
+	maxExtractMaxMatches     = 500
+	maxExtractLineChars      = 400 // Longer lines are cut down around the match
+)
+
+var resultIDPattern = regexp.MustCompile(`^r(\d+)_`)
+
+// ResultStore keeps the full output of tool calls on disk under IDs such as
+// r12, so the model can search output that was truncated or has scrolled
+// out of context without running the tool again.
+type ResultStore struct {
+	mu   sync.Mutex
+	dir  string
+	next int // 0 until the directory has been scanned
+}
+
+func NewResultStore(dir string) *ResultStore {
+	return &ResultStore{dir: dir}
+}
+
+// Save stores output from tool and returns its ID
+func (s *ResultStore) Save(tool, output string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+	if s.next == 0 {
+		s.next = s.lastID() + 1
+	}
+	id := "r" + strconv.Itoa(s.next)
+	path := filepath.Join(s.dir, id+"_"+safeResultName(tool)+".txt")
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		return "", fmt.Errorf("failed to store result: %w", err)
+	}
+	s.next++
+	return id, nil
+}
+
+// StoredResult is a tool output kept by a ResultStore
+type StoredResult struct {
+	ID     string
+	Tool   string
+	Output string
+}
+
+// Load returns a stored result. "last" means the most recently stored one.
+func (s *ResultStore) Load(id string) (*StoredResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "last" {
+		n := s.next - 1
+		if s.next == 0 {
+			n = s.lastID()
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("no stored results")
+		}
+		id = "r" + strconv.Itoa(n)
+	}
+	if !strings.HasPrefix(id, "r") {
+		id = "r" + id
+	}
+	if _, err := strconv.Atoi(id[1:]); err != nil {
+		return nil, fmt.Errorf("invalid result ID %q (want e.g. r12)", id)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(s.dir, id+"_*.txt"))
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no stored result %s", id)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result %s: %w", id, err)
+	}
+	tool := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(matches[0]), id+"_"), ".txt")
+	return &StoredResult{ID: id, Tool: tool, Output: string(data)}, nil
+}
+
+// lastID returns the highest stored result number, or 0
+func (s *ResultStore) lastID() int {
+	entries, _ := os.ReadDir(s.dir)
+	last := 0
+	for _, entry := range entries {
+		if m := resultIDPattern.FindStringSubmatch(entry.Name()); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n > last {
+				last = n
+			}
+		}
+	}
+	return last
+}
+
+func safeResultName(tool string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, tool)
+}
+
+// ResultExtractTool runs a regex over a stored tool result and returns the
+// matches with the lines around them, or the distinct values of a capture
+// group
+type ResultExtractTool struct {
+	store *ResultStore
+}
+
+func NewResultExtractTool(store *ResultStore) *ResultExtractTool {
+	return &ResultExtractTool{store: store}
+}
+
+func (t *ResultExtractTool) Name() string {
+	return "result_extract"
+}
+
+func (t *ResultExtractTool) Description() string {
+	return "Search the full output of an earlier tool call by its result ID (shown as 'result r12' after large outputs) with a regular expression. Returns matching lines with surrounding context, or with 'group' the distinct values of a capture group and their counts. Use this instead of re-running a tool or reading a large output back into context."
+}
+
+func (t *ResultExtractTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"result_id": map[string]any{
+				"type":        "string",
+				"description": "Stored result ID, e.g. r12, or 'last' for the most recent",
+			},
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression (RE2 syntax, matched per line)",
+			},
+			"ignore_case": map[string]any{
+				"type":        "boolean",
+				"description": "Match case-insensitively",
+			},
+			"context": map[string]any{
+				"type":        "integer",
+				"description": "Lines of context before and after each match (default 2, max 20)",
+			},
+			"group": map[string]any{
+				"type":        "integer",
+				"description": "Return the distinct values of this capture group (0 for the whole match) with counts instead of lines",
+			},
+			"max_matches": map[string]any{
+				"type":        "integer",
+				"description": "Most matches to return (default 50, max 500)",
+			},
+		},
+		"required": []string{"result_id", "pattern"},
+	}
+}
+
+func (t *ResultExtractTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	id, _ := args["result_id"].(string)
+	pattern, _ := args["pattern"].(string)
+	if strings.TrimSpace(id) == "" || pattern == "" {
+		return ErrorResult("result_id and pattern are required")
+	}
+	if ignoreCase, _ := args["ignore_case"].(bool); ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid pattern: %v", err))
+	}
+
+	result, err := t.store.Load(id)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	maxMatches := clampInt(intArg(args, "max_matches", defaultExtractMaxMatches), 1, maxExtractMaxMatches)
+	lines := strings.Split(result.Output, "\n")
+	header := fmt.Sprintf("Result %s (%s, %d lines)", result.ID, result.Tool, len(lines))
+
+	if _, ok := args["group"]; ok {
+		group := intArg(args, "group", 0)
+		if group < 0 || group > re.NumSubexp() {
+			return ErrorResult(fmt.Sprintf("pattern has no capture group %d", group))
+		}
+		return NewToolResult(header + "\n" + extractGroups(lines, re, group, maxMatches))
+	}
+
+	window := clampInt(intArg(args, "context", defaultExtractContext), 0, maxExtractContext)
+	return NewToolResult(header + "\n" + extractLines(lines, re, window, maxMatches))
+}
+
+// extractLines prints each matching line with context lines around it,
+// merging windows that overlap, grep-style
+func extractLines(lines []string, re *regexp.Regexp, window, maxMatches int) string {
+	var matched []int
+	total := 0
+	for i, line := range lines {
+		if re.MatchString(line) {
+			total++
+			if len(matched) < maxMatches {
+				matched = append(matched, i)
+			}
+		}
+	}
+	if total == 0 {
+		return "No matches."
+	}
+
+	var sb strings.Builder
+	if total > len(matched) {
+		fmt.Fprintf(&sb, "%d matching lines, showing the first %d:\n", total, len(matched))
+	} else {
+		fmt.Fprintf(&sb, "%d matching lines:\n", total)
+	}
+
+	isMatch := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatch[i] = true
+	}
+	end := -1
+	for _, i := range matched {
+		from, to := max(i-window, 0), min(i+window, len(lines)-1)
+		if from <= end {
+			from = end + 1
+		} else {
+			sb.WriteString("--\n")
+		}
+		for j := from; j <= to; j++ {
+			sep := ":"
+			if isMatch[j] {
+				sep = ">"
+			}
+			fmt.Fprintf(&sb, "%d%s %s\n", j+1, sep, clipLine(lines[j], re))
+		}
+		end = max(end, to)
+	}
+	return sb.String()
+}
+
+// extractGroups counts the distinct values of a capture group, most
+// frequent first
+func extractGroups(lines []string, re *regexp.Regexp, group, maxValues int) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, line := range lines {
+		for _, m := range re.FindAllStringSubmatch(line, -1) {
+			value := m[group]
+			if value == "" {
+				continue
+			}
+			if counts[value] == 0 {
+				order = append(order, value)
+			}
+			counts[value]++
+		}
+	}
+	if len(order) == 0 {
+		return "No matches."
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	var sb strings.Builder
+	if len(order) > maxValues {
+		fmt.Fprintf(&sb, "%d distinct values, showing the %d most frequent:\n", len(order), maxValues)
+		order = order[:maxValues]
+	} else {
+		fmt.Fprintf(&sb, "%d distinct values:\n", len(order))
+	}
+	for _, value := range order {
+		fmt.Fprintf(&sb, "%6d  %s\n", counts[value], value)
+	}
+	return sb.String()
+}
+
+// clipLine cuts a long line down to a window around its first match, or its
+// start if it has none
+func clipLine(line string, re *regexp.Regexp) string {
+	if len(line) <= maxExtractLineChars {
+		return line
+	}
+	start := 0
+	if loc := re.FindStringIndex(line); loc != nil {
+		start = max(loc[0]-maxExtractLineChars/4, 0)
+	}
+	end := min(start+maxExtractLineChars, len(line))
+	clipped := strings.ToValidUTF8(line[start:end], "")
+	if start > 0 {
+		clipped = "…" + clipped
+	}
+	if end < len(line) {
+		clipped += "…"
+	}
+	return clipped
+}
+
+func intArg(args map[string]any, key string, def int) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func clampInt(n, lo, hi int) int {
+	return min(max(n, lo), hi)
+}
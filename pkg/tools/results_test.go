@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultStore_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	store := NewResultStore(dir)
+
+	_, err := store.Load("last")
+	assert.ErrorContains(t, err, "no stored results")
+
+	id, err := store.Save("exec", "first")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", id)
+	id, err = store.Save("web/fetch", "second")
+	require.NoError(t, err)
+	assert.Equal(t, "r2", id)
+
+	result, err := store.Load("R1")
+	require.NoError(t, err)
+	assert.Equal(t, &StoredResult{ID: "r1", Tool: "exec", Output: "first"}, result)
+
+	// A new store picks up numbering where the directory left off
+	store = NewResultStore(dir)
+	result, err = store.Load("last")
+	require.NoError(t, err)
+	assert.Equal(t, "r2", result.ID)
+	assert.Equal(t, "web-fetch", result.Tool)
+	id, err = store.Save("exec", "third")
+	require.NoError(t, err)
+	assert.Equal(t, "r3", id)
+
+	_, err = store.Load("r9")
+	assert.ErrorContains(t, err, "no stored result r9")
+	_, err = store.Load("../etc")
+	assert.ErrorContains(t, err, "invalid result ID")
+}
+
+func TestResultExtractTool(t *testing.T) {
+	store := NewResultStore(t.TempDir())
+	var sb strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&sb, "%d/tcp open http\n", 8000+i)
+	}
+	sb.WriteString("Host: 10.0.0.5 Server: nginx/1.18.0\nHost: 10.0.0.6 Server: nginx/1.18.0\nHost: 10.0.0.7 Server: Apache/2.4.41")
+	id, err := store.Save("exec", sb.String())
+	require.NoError(t, err)
+
+	tool := NewResultExtractTool(store)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"result_id": id, "pattern": "^8003/|^8005/", "context": float64(1)})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "Result r1 (exec, 33 lines)\n2 matching lines:\n--\n"+
+		"2: 8002/tcp open http\n3> 8003/tcp open http\n4: 8004/tcp open http\n5> 8005/tcp open http\n6: 8006/tcp open http\n",
+		result.ForLLM)
+
+	result = tool.Execute(ctx, map[string]any{"result_id": "last", "pattern": "OPEN", "ignore_case": true, "context": float64(0), "max_matches": float64(2)})
+	assert.Contains(t, result.ForLLM, "30 matching lines, showing the first 2:")
+
+	result = tool.Execute(ctx, map[string]any{"result_id": id, "pattern": `Server: (\S+)`, "group": float64(1)})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "2 distinct values:\n     2  nginx/1.18.0\n     1  Apache/2.4.41\n")
+
+	result = tool.Execute(ctx, map[string]any{"result_id": id, "pattern": "nothing here"})
+	assert.Contains(t, result.ForLLM, "No matches.")
+
+	result = tool.Execute(ctx, map[string]any{"result_id": id, "pattern": `Server: \S+`, "group": float64(1)})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "no capture group 1")
+
+	result = tool.Execute(ctx, map[string]any{"result_id": id, "pattern": "("})
+	assert.True(t, result.IsError)
+}
+
+func TestClipLine(t *testing.T) {
+	line := strings.Repeat("a", 1000) + "TOKEN=secret" + strings.Repeat("b", 1000)
+	clipped := clipLine(line, regexp.MustCompile("TOKEN=\\w+"))
+	assert.Contains(t, clipped, "TOKEN=secret")
+	assert.True(t, strings.HasPrefix(clipped, "…"))
+	assert.True(t, strings.HasSuffix(clipped, "…"))
+	assert.LessOrEqual(t, len(clipped), maxExtractLineChars+len("……"))
+}
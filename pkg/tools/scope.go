@@ -0,0 +1,317 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// ScopeQueryTool shows the mission scope and checks hosts against it. It
+// cannot change anything.
+type ScopeQueryTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewScopeQueryTool(getEngine func() *workflow.Engine) *ScopeQueryTool {
+	return &ScopeQueryTool{getEngine: getEngine}
+}
+
+func (t *ScopeQueryTool) Name() string {
+	return "scope_query"
+}
+
+func (t *ScopeQueryTool) Description() string {
+	return "Show the mission scope, or check whether hosts, URLs, IP addresses or CIDR ranges are in scope. Check before touching a host you discovered rather than one you were given."
+}
+
+func (t *ScopeQueryTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"hosts": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Hosts to check; omit to list the scope",
+			},
+		},
+	}
+}
+
+func (t *ScopeQueryTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	var hosts []string
+	switch v := args["hosts"].(type) {
+	case []any:
+		for _, h := range v {
+			if s, ok := h.(string); ok && strings.TrimSpace(s) != "" {
+				hosts = append(hosts, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			hosts = append(hosts, s)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return NewToolResult(FormatScope(engine.GetState()))
+	}
+	var sb strings.Builder
+	for _, host := range hosts {
+		inScope, reason := engine.CheckScope(host)
+		verdict := "OUT OF SCOPE"
+		if inScope {
+			verdict = "in scope"
+		}
+		fmt.Fprintf(&sb, "%s: %s (%s)\n", host, verdict, reason)
+	}
+	return NewToolResult(strings.TrimSpace(sb.String()))
+}
+
+// FormatScope renders the mission scope and its recent changes
+func FormatScope(state *workflow.MissionState) string {
+	var sb strings.Builder
+	sb.WriteString("Scope:")
+	if state.Target != "" {
+		fmt.Fprintf(&sb, "\n  target: %s", state.Target)
+	}
+	for _, target := range state.Targets {
+		fmt.Fprintf(&sb, "\n  target: %s", target)
+	}
+	for _, entry := range state.Scope.Include {
+		fmt.Fprintf(&sb, "\n  + %s", entry)
+	}
+	for _, entry := range state.Scope.Exclude {
+		fmt.Fprintf(&sb, "\n  - %s (excluded)", entry)
+	}
+	if state.Target == "" && len(state.Targets) == 0 && len(state.Scope.Include) == 0 {
+		sb.WriteString("\n  (nothing in scope yet)")
+	}
+
+	history := state.Scope.History
+	if len(history) > 5 {
+		history = history[len(history)-5:]
+	}
+	if len(history) > 0 {
+		sb.WriteString("\nRecent changes:")
+		for _, change := range history {
+			fmt.Fprintf(&sb, "\n  %s", change)
+		}
+	}
+	return sb.String()
+}
+
+// ScopeChangeRequestTool lets the agent ask the operator to widen or narrow
+// the mission scope. Changes only apply once the operator approves them;
+// without an interactive operator they are refused. Both outcomes are kept
+// in the scope's audit trail.
+type ScopeChangeRequestTool struct {
+	getEngine func() *workflow.Engine
+
+	mu      sync.RWMutex
+	asker   OperatorAsker
+	channel string
+	chatID  string
+}
+
+func NewScopeChangeRequestTool(getEngine func() *workflow.Engine) *ScopeChangeRequestTool {
+	return &ScopeChangeRequestTool{getEngine: getEngine}
+}
+
+// SetAsker installs the interactive asker that approves changes. nil refuses
+// every request.
+func (t *ScopeChangeRequestTool) SetAsker(asker OperatorAsker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.asker = asker
+}
+
+func (t *ScopeChangeRequestTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *ScopeChangeRequestTool) Name() string {
+	return "scope_change_request"
+}
+
+func (t *ScopeChangeRequestTool) Description() string {
+	return "Ask the operator to add or remove a scope entry (hostname, *.domain, IP address or CIDR range), e.g. when you find a related host that is not in scope. Nothing changes unless the operator approves. Do not touch the host until this returns approved."
+}
+
+func (t *ScopeChangeRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{string(workflow.ScopeAdd), string(workflow.ScopeRemove)},
+				"description": "Whether to add the entry or remove it",
+			},
+			"entry": map[string]any{
+				"type":        "string",
+				"description": "The hostname, *.domain, IP address or CIDR range",
+			},
+			"exclude": map[string]any{
+				"type":        "boolean",
+				"description": "Change the exclusions instead of the in-scope entries (default: false)",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Why the change is needed, shown to the operator and kept in the audit trail",
+			},
+		},
+		"required": []string{"action", "entry", "reason"},
+	}
+}
+
+func (t *ScopeChangeRequestTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	change, err := parseScopeChange(args)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	t.mu.RLock()
+	asker := t.asker
+	channel, chatID := t.channel, t.chatID
+	t.mu.RUnlock()
+
+	if asker == nil {
+		change.Denied = true
+		change.Reason = strings.TrimSpace(change.Reason + "; no operator available to approve")
+		if err := engine.ChangeScope(change); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to record scope request: %v", err)).WithError(err)
+		}
+		return NewToolResult("No interactive operator is available to approve scope changes, so the scope is unchanged. Stay within the current scope and note the request in your report.")
+	}
+
+	q := OperatorQuestion{
+		Question:   scopeChangeQuestion(change),
+		AnswerType: AnswerYesNo,
+		Context:    change.Reason,
+		Channel:    channel,
+		ChatID:     chatID,
+	}
+	answer, err := asker(ctx, q)
+	if err == nil {
+		answer, err = NormalizeOperatorAnswer(q, answer)
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("operator did not answer: %v", err)).WithError(err)
+	}
+
+	change.Denied = answer != "yes"
+	if err := engine.ChangeScope(change); err != nil {
+		return ErrorResult(fmt.Sprintf("scope change failed: %v", err)).WithError(err)
+	}
+	if change.Denied {
+		return NewToolResult("The operator denied the scope change. The scope is unchanged.")
+	}
+	return NewToolResult(fmt.Sprintf("Approved: %s", scopeChangeSummary(change)))
+}
+
+func parseScopeChange(args map[string]any) (workflow.ScopeChange, error) {
+	change := workflow.ScopeChange{By: workflow.ScopeByAgent}
+	action, _ := args["action"].(string)
+	change.Action = workflow.ScopeAction(strings.ToLower(strings.TrimSpace(action)))
+	if change.Action != workflow.ScopeAdd && change.Action != workflow.ScopeRemove {
+		return change, fmt.Errorf("action must be add or remove")
+	}
+	entry, _ := args["entry"].(string)
+	normalized, err := workflow.NormalizeScopeEntry(entry)
+	if err != nil {
+		return change, err
+	}
+	change.Entry = normalized
+	change.Exclude, _ = args["exclude"].(bool)
+	change.Reason, _ = args["reason"].(string)
+	change.Reason = strings.TrimSpace(change.Reason)
+	if change.Reason == "" {
+		return change, fmt.Errorf("reason is required")
+	}
+	return change, nil
+}
+
+func scopeChangeQuestion(change workflow.ScopeChange) string {
+	return fmt.Sprintf("The agent asks to change the scope: %s. Approve?", scopeChangeSummary(change))
+}
+
+func scopeChangeSummary(change workflow.ScopeChange) string {
+	switch {
+	case change.Action == workflow.ScopeAdd && change.Exclude:
+		return fmt.Sprintf("exclude %s from scope", change.Entry)
+	case change.Action == workflow.ScopeAdd:
+		return fmt.Sprintf("add %s to scope", change.Entry)
+	case change.Exclude:
+		return fmt.Sprintf("stop excluding %s", change.Entry)
+	default:
+		return fmt.Sprintf("remove %s from scope", change.Entry)
+	}
+}
+
+// WorkflowAddTargetTool adds a further target to the mission, such as a live
+// host found in an in-scope range. It cannot widen the scope: targets outside
+// it are refused.
+type WorkflowAddTargetTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowAddTargetTool(getEngine func() *workflow.Engine) *WorkflowAddTargetTool {
+	return &WorkflowAddTargetTool{getEngine: getEngine}
+}
+
+func (t *WorkflowAddTargetTool) Name() string {
+	return "workflow_add_target"
+}
+
+func (t *WorkflowAddTargetTool) Description() string {
+	return "Track an in-scope host, URL or range as a further mission target, e.g. a live host found in the target range or a subdomain of an in-scope domain. Targets outside the scope are refused; use scope_change_request for those."
+}
+
+func (t *WorkflowAddTargetTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"target": map[string]any{
+				"type":        "string",
+				"description": "Hostname, URL, IP address or CIDR range",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Why it is worth testing, e.g. what was found on it",
+			},
+		},
+		"required": []string{"target"},
+	}
+}
+
+func (t *WorkflowAddTargetTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	target, _ := args["target"].(string)
+	reason, _ := args["reason"].(string)
+	if strings.TrimSpace(target) == "" {
+		return ErrorResult("target is required")
+	}
+	if err := engine.AddTarget(target, workflow.ScopeByAgent, reason); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to add target: %v", err)).WithError(err)
+	}
+	return NewToolResult(fmt.Sprintf("Mission targets: %s", strings.Join(engine.Targets(), ", ")))
+}
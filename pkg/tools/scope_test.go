@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func newScopeEngine(t *testing.T) *workflow.Engine {
+	t.Helper()
+	wf := &workflow.Workflow{Name: "network-scan", Phases: []workflow.Phase{{Name: "discovery"}}}
+	engine := workflow.NewEngine(wf, "10.0.0.0/24", t.TempDir())
+	for _, change := range []workflow.ScopeChange{
+		{Action: workflow.ScopeAdd, Entry: "*.corp.example"},
+		{Action: workflow.ScopeAdd, Entry: "10.0.0.1", Exclude: true, Reason: "gateway"},
+	} {
+		if err := engine.ChangeScope(change); err != nil {
+			t.Fatalf("ChangeScope(%+v) error = %v", change, err)
+		}
+	}
+	return engine
+}
+
+func TestScopeQuery_ChecksHosts(t *testing.T) {
+	engine := newScopeEngine(t)
+	tool := NewScopeQueryTool(func() *workflow.Engine { return engine })
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"hosts": []any{"10.0.0.7", "https://app.corp.example:8443/login", "10.0.0.1", "10.0.0.0/25", "corp.example", "10.0.1.5"},
+	})
+	want := []string{
+		"10.0.0.7: in scope (matches the mission target 10.0.0.0/24)",
+		"https://app.corp.example:8443/login: in scope (included by *.corp.example)",
+		"10.0.0.1: OUT OF SCOPE (excluded by 10.0.0.1)",
+		"10.0.0.0/25: OUT OF SCOPE (excluded by 10.0.0.1)",
+		"corp.example: OUT OF SCOPE (matches no scope entry)",
+		"10.0.1.5: OUT OF SCOPE (matches no scope entry)",
+	}
+	if result.ForLLM != strings.Join(want, "\n") {
+		t.Errorf("result =\n%s\nwant\n%s", result.ForLLM, strings.Join(want, "\n"))
+	}
+
+	listing := tool.Execute(context.Background(), map[string]any{}).ForLLM
+	for _, want := range []string{"target: 10.0.0.0/24", "+ *.corp.example", "- 10.0.0.1 (excluded)", "by operator (gateway)"} {
+		if !strings.Contains(listing, want) {
+			t.Errorf("listing missing %q:\n%s", want, listing)
+		}
+	}
+}
+
+func TestScopeChangeRequest_RequiresApproval(t *testing.T) {
+	engine := newScopeEngine(t)
+	tool := NewScopeChangeRequestTool(func() *workflow.Engine { return engine })
+	args := map[string]any{"action": "add", "entry": "https://vpn.partner.example/", "reason": "linked from the login page"}
+
+	// Headless: refused and recorded
+	result := tool.Execute(context.Background(), args)
+	if result.IsError || !strings.Contains(result.ForLLM, "scope is unchanged") {
+		t.Fatalf("headless result = %+v", result)
+	}
+	if inScope, _ := engine.CheckScope("vpn.partner.example"); inScope {
+		t.Fatal("scope changed without approval")
+	}
+
+	var asked OperatorQuestion
+	answer := "no"
+	tool.SetContext("telegram", "42")
+	tool.SetAsker(func(ctx context.Context, q OperatorQuestion) (string, error) {
+		asked = q
+		return answer, nil
+	})
+
+	result = tool.Execute(context.Background(), args)
+	if !strings.Contains(result.ForLLM, "denied") {
+		t.Fatalf("denied result = %+v", result)
+	}
+	if asked.AnswerType != AnswerYesNo || asked.ChatID != "42" || !strings.Contains(asked.Question, "add vpn.partner.example to scope") {
+		t.Errorf("question = %+v", asked)
+	}
+
+	answer = "y"
+	result = tool.Execute(context.Background(), args)
+	if result.IsError || !strings.HasPrefix(result.ForLLM, "Approved") {
+		t.Fatalf("approved result = %+v", result)
+	}
+	if inScope, _ := engine.CheckScope("vpn.partner.example"); !inScope {
+		t.Error("approved entry not in scope")
+	}
+
+	history := engine.GetState().Scope.History
+	if len(history) != 5 {
+		t.Fatalf("history has %d entries, want 5: %+v", len(history), history)
+	}
+	for i, wantDenied := range []bool{true, true, false} {
+		change := history[2+i]
+		if change.By != workflow.ScopeByAgent || change.Denied != wantDenied || change.Entry != "vpn.partner.example" {
+			t.Errorf("history[%d] = %+v", 2+i, change)
+		}
+	}
+
+	if result := tool.Execute(context.Background(), map[string]any{"action": "add", "entry": "bad host"}); !result.IsError {
+		t.Errorf("invalid request accepted: %+v", result)
+	}
+}
+
+func TestWorkflowAddTarget(t *testing.T) {
+	engine := newScopeEngine(t)
+	tool := NewWorkflowAddTargetTool(func() *workflow.Engine { return engine })
+
+	result := tool.Execute(context.Background(), map[string]any{"target": "http://10.0.0.7:8080/", "reason": "Jenkins on 8080"})
+	if result.IsError || result.ForLLM != "Mission targets: 10.0.0.0/24, 10.0.0.7" {
+		t.Errorf("add in-scope target = %+v", result)
+	}
+	for _, target := range []string{"10.0.1.5", "10.0.0.1", "10.0.0.7"} {
+		if result := tool.Execute(context.Background(), map[string]any{"target": target}); !result.IsError {
+			t.Errorf("add %s = %q, want refused", target, result.ForLLM)
+		}
+	}
+
+	// The operator can add targets outside the scope, which brings them in
+	if err := engine.AddTarget("api.partner.example", workflow.ScopeByOperator, "second application"); err != nil {
+		t.Fatalf("operator AddTarget() error = %v", err)
+	}
+	if inScope, reason := engine.CheckScope("https://api.partner.example/v1"); !inScope {
+		t.Errorf("added target out of scope: %s", reason)
+	}
+	if err := engine.AddTarget("10.0.0.1", workflow.ScopeByOperator, ""); err == nil {
+		t.Error("operator added an excluded target")
+	}
+
+	state := engine.GetState()
+	if want := []string{"10.0.0.7", "api.partner.example"}; strings.Join(state.Targets, ",") != strings.Join(want, ",") {
+		t.Errorf("Targets = %v, want %v", state.Targets, want)
+	}
+	last := state.Scope.History[len(state.Scope.History)-1]
+	if !strings.Contains(last.String(), "api.partner.example added to targets by operator (second application)") {
+		t.Errorf("history = %q", last)
+	}
+}
+
+func TestToolRegistry_ScopePolicy(t *testing.T) {
+	engine := newScopeEngine(t)
+	r := NewToolRegistry()
+	for _, name := range []string{"exec", "web_fetch"} {
+		r.Register(newMockTool(name, name))
+	}
+	r.SetPolicy(func() ToolPolicy { return engine })
+
+	ctx := context.Background()
+	allowed := []struct{ tool, arg, value string }{
+		{"exec", "command", "nmap -sV 10.0.0.128/25 -oN scan.txt"},
+		{"exec", "command", "curl -sk https://app.corp.example/login"},
+		{"exec", "command", "curl http://127.0.0.1:8000/ && python3 exploit.py"},
+		{"web_fetch", "url", "http://10.0.0.7/robots.txt"},
+	}
+	for _, a := range allowed {
+		if result := r.Execute(ctx, a.tool, map[string]any{a.arg: a.value}); result.IsError {
+			t.Errorf("%s %q blocked: %s", a.tool, a.value, result.ForLLM)
+		}
+	}
+
+	blocked := []struct{ tool, arg, value, reason string }{
+		{"exec", "command", "nmap -sS 10.0.1.5", "10.0.1.5 is out of scope (matches no scope entry)"},
+		{"exec", "command", "nmap -p- 10.0.0.1", "excluded by 10.0.0.1"},
+		{"exec", "command", "nmap 10.0.0.0/24", "excluded by 10.0.0.1"},
+		{"exec", "command", "curl https://example.org/", "example.org is out of scope"},
+		{"web_fetch", "url", "https://vpn.partner.example/", "scope_change_request"},
+	}
+	for _, b := range blocked {
+		result := r.Execute(ctx, b.tool, map[string]any{b.arg: b.value})
+		if !result.IsError || !strings.Contains(result.ForLLM, b.reason) {
+			t.Errorf("%s %q = %q, want blocked: %s", b.tool, b.value, result.ForLLM, b.reason)
+		}
+	}
+
+	// Outside network commands, bare hostnames are only refused when excluded
+	if err := engine.ChangeScope(workflow.ScopeChange{Action: workflow.ScopeAdd, Entry: "*.prod.corp.example", Exclude: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range []string{"dig axfr db.prod.corp.example", "grep -r db.prod.corp.example ."} {
+		if result := r.Execute(ctx, "exec", map[string]any{"command": command}); !result.IsError {
+			t.Errorf("%q against an excluded hostname allowed", command)
+		}
+	}
+	if result := r.Execute(ctx, "exec", map[string]any{"command": "grep evil.example.org notes.txt"}); result.IsError {
+		t.Errorf("hostname outside a network command blocked: %s", result.ForLLM)
+	}
+}
+
+// scopedMockTool stands in for an MCP tool, whose arguments may all name hosts
+type scopedMockTool struct {
+	*mockRegistryTool
+}
+
+func (t scopedMockTool) ScopeText(args map[string]any) string {
+	return ArgsScopeText(args)
+}
+
+func TestToolRegistry_ScopePolicy_NetworkCommands(t *testing.T) {
+	engine := newScopeEngine(t)
+	if err := engine.ChangeScope(workflow.ScopeChange{Action: workflow.ScopeAdd, Entry: "2001:db8:0:1::/64"}); err != nil {
+		t.Fatal(err)
+	}
+	engine.SetHostResolver(func(name string) bool { return name == "dc01.corp.local" })
+
+	r := NewToolRegistry()
+	r.Register(newMockTool("exec", "exec"))
+	r.Register(scopedMockTool{newMockTool("mcp_scan", "mcp")})
+	r.SetPolicy(func() ToolPolicy { return engine })
+	ctx := context.Background()
+
+	allowed := []string{
+		"nmap -sV app.corp.example -oA scan && cat scan.nmap",
+		"dig axfr unknown.example",
+		"ssh -i id.pem admin@app.corp.example",
+		"curl -s app.corp.example:8443/api/v1 -o out.json",
+		"nmap -6 -sV 2001:db8:0:1::10",
+		"python3 parse.py scan.xml > hosts.txt",
+		"echo 12:30:45 | ping6 ::1",
+	}
+	for _, command := range allowed {
+		if result := r.Execute(ctx, "exec", map[string]any{"command": command}); result.IsError {
+			t.Errorf("%q blocked: %s", command, result.ForLLM)
+		}
+	}
+
+	blocked := map[string]string{
+		"nmap -sV evil.example.org":                         "evil.example.org is out of scope",
+		"nuclei -u evil.example.org -t cves/":               "evil.example.org is out of scope",
+		"dig +short mail.evil.io":                           "mail.evil.io is out of scope",
+		"ssh root@evil.example.org":                         "evil.example.org is out of scope",
+		"sh -c 'curl -s evil.example.org/x'":                "evil.example.org is out of scope",
+		"nmap --script smb-os-discovery dc01.corp.local":    "dc01.corp.local is out of scope",
+		"nmap -6 2001:db8:0:2::1":                           "2001:db8:0:2::1 is out of scope",
+		"curl http://[2001:db8:0:2::1]:8080/":               "2001:db8:0:2::1 is out of scope",
+		"cat hosts | xargs -n1 nc -zv 2001:DB8:0:2:0:0:0:1": "2001:db8:0:2::1 is out of scope",
+	}
+	for command, reason := range blocked {
+		result := r.Execute(ctx, "exec", map[string]any{"command": command})
+		if !result.IsError || !strings.Contains(result.ForLLM, reason) {
+			t.Errorf("%q = %q, want blocked: %s", command, result.ForLLM, reason)
+		}
+	}
+
+	result := r.Execute(ctx, "mcp_scan", map[string]any{"targets": []any{"app.corp.example", "evil.example.org"}})
+	if !result.IsError || !strings.Contains(result.ForLLM, "evil.example.org is out of scope") {
+		t.Errorf("MCP call = %q, want blocked", result.ForLLM)
+	}
+	if result := r.Execute(ctx, "mcp_scan", map[string]any{"target": "app.corp.example", "report": "findings.md"}); result.IsError {
+		t.Errorf("in-scope MCP call blocked: %s", result.ForLLM)
+	}
+}
+
+func TestPythonTool_ScopeText(t *testing.T) {
+	code := `import socket, logging
+logging.info("connecting")
+s = socket.create_connection(('evil.example.org', 443))`
+	tool := &PythonTool{network: true}
+	text := tool.ScopeText(map[string]any{"code": code, "args": []any{"api.corp.example"}})
+	if text != "connecting\nevil.example.org\napi.corp.example\n" {
+		t.Errorf("ScopeText() = %q", text)
+	}
+	if text := (&PythonTool{}).ScopeText(map[string]any{"code": code}); text != "" {
+		t.Errorf("ScopeText() without network = %q, want empty", text)
+	}
+}
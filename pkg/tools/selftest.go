@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const defaultSelfTestTimeout = 30 * time.Second
+
+// SelfTestOptions tunes tool self-tests
+type SelfTestOptions struct {
+	// Loopback means the hardware buses are wired back to themselves (SPI
+	// MOSI to MISO), so tools may send test data and expect it back
+	Loopback bool
+	Timeout  time.Duration // Per tool; 0 means 30 seconds
+}
+
+// SelfTester is an optional interface for tools that can check, before a
+// mission relies on them, that what they need is in place: binaries, device
+// nodes, a sandbox. Self-tests only touch mock targets and loopback
+// hardware, never a mission's targets. They return a short note on what
+// they checked.
+type SelfTester interface {
+	Tool
+	SelfTest(ctx context.Context, opts SelfTestOptions) (string, error)
+}
+
+// NoHardwareError is what hardware tools' self-tests return on hosts without
+// the hardware, which only matters to missions that need it
+type NoHardwareError struct {
+	Reason string
+}
+
+func (e *NoHardwareError) Error() string {
+	return e.Reason
+}
+
+// SelfTestResult is the outcome of one tool's self-test
+type SelfTestResult struct {
+	Tool    string
+	Tested  bool // False when the tool has no self-test
+	Note    string
+	Err     error
+	Elapsed time.Duration
+}
+
+// Passed reports whether the tool has nothing wrong with it as far as its
+// self-test can tell, which is trivially true without one
+func (r SelfTestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// NoHardware reports whether the self-test found no hardware to test
+func (r SelfTestResult) NoHardware() bool {
+	var noHardware *NoHardwareError
+	return errors.As(r.Err, &noHardware)
+}
+
+// SelfTest runs the self-tests of the named tools, or of every registered
+// tool without names, in name order. Tests run one at a time since several
+// tools share hardware.
+func (r *ToolRegistry) SelfTest(ctx context.Context, opts SelfTestOptions, names ...string) []SelfTestResult {
+	if len(names) == 0 {
+		names = r.List()
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultSelfTestTimeout
+	}
+
+	results := make([]SelfTestResult, 0, len(names))
+	for _, name := range names {
+		result := SelfTestResult{Tool: name}
+		tool, ok := r.Get(name)
+		if !ok {
+			result.Err = fmt.Errorf("tool %q not found", name)
+			results = append(results, result)
+			continue
+		}
+		tester, ok := tool.(SelfTester)
+		if !ok {
+			results = append(results, result)
+			continue
+		}
+
+		testCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		result.Tested = true
+		result.Note, result.Err = tester.SelfTest(testCtx, opts)
+		result.Elapsed = time.Since(start)
+		cancel()
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type mockSelfTestTool struct {
+	mockRegistryTool
+	note string
+	err  error
+	opts SelfTestOptions
+}
+
+func (m *mockSelfTestTool) SelfTest(_ context.Context, opts SelfTestOptions) (string, error) {
+	m.opts = opts
+	return m.note, m.err
+}
+
+func TestRegistrySelfTest(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(&mockSelfTestTool{mockRegistryTool: *newMockTool("scanner", "")})
+	r.Register(&mockSelfTestTool{mockRegistryTool: *newMockTool("bus", ""), err: &NoHardwareError{Reason: "no bus"}})
+	broken := &mockSelfTestTool{mockRegistryTool: *newMockTool("broken", ""), err: errors.New("binary not found")}
+	r.Register(broken)
+	r.Register(newMockTool("plain", ""))
+
+	results := r.SelfTest(context.Background(), SelfTestOptions{Loopback: true})
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Tool)
+	}
+	if strings.Join(names, ",") != "broken,bus,plain,scanner" {
+		t.Fatalf("tested %v, want name order", names)
+	}
+	if !broken.opts.Loopback {
+		t.Errorf("options not passed to the self-test")
+	}
+
+	checks := []struct {
+		tested, passed, noHardware bool
+	}{
+		{true, false, false}, // broken
+		{true, false, true},  // bus
+		{false, true, false}, // plain
+		{true, true, false},  // scanner
+	}
+	for i, want := range checks {
+		got := results[i]
+		if got.Tested != want.tested || got.Passed() != want.passed || got.NoHardware() != want.noHardware {
+			t.Errorf("%s: tested %t passed %t no hardware %t, want %+v",
+				got.Tool, got.Tested, got.Passed(), got.NoHardware(), want)
+		}
+	}
+
+	results = r.SelfTest(context.Background(), SelfTestOptions{}, "scanner", "missing")
+	if len(results) != 2 || results[0].Tool != "missing" || results[0].Err == nil || !results[1].Passed() {
+		t.Errorf("named self-tests = %+v", results)
+	}
+}
+
+func TestExecTool_SelfTest(t *testing.T) {
+	dir := t.TempDir()
+	note, err := NewExecTool(dir, true).SelfTest(context.Background(), SelfTestOptions{})
+	if err != nil {
+		t.Fatalf("SelfTest: %v", err)
+	}
+	if note != "shell runs commands in "+dir {
+		t.Errorf("note = %q", note)
+	}
+}
+
+func TestWebFetchTool_SelfTest(t *testing.T) {
+	note, err := NewWebFetchTool(1000).SelfTest(context.Background(), SelfTestOptions{})
+	if err != nil {
+		t.Fatalf("SelfTest: %v", err)
+	}
+	if note != "fetched a loopback page" {
+		t.Errorf("note = %q", note)
+	}
+
+	// An unreachable proxy fails the test, as it would every fetch
+	_, err = NewWebFetchToolWithProxy(1000, "http://127.0.0.1:1").SelfTest(context.Background(), SelfTestOptions{})
+	if err == nil {
+		t.Errorf("SelfTest through a dead proxy passed")
+	}
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactMinLength is the shortest variable value redacted from tool output;
+// shorter values would mangle unrelated text.
+const redactMinLength = 4
+
+type sessionVarsKey struct{}
+
+type missionVarsKey struct{}
+
+// credsPrefix references a session variable through the mission namespace,
+// e.g. {{creds.admin}} for the variable set with /set admin.
+const credsPrefix = "creds."
+
+// sessionVarRef matches a {{NAME}} reference to a session variable or a
+// {{dotted.name}} reference to a mission variable.
+var sessionVarRef = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+
+// WithSessionVars attaches the current session's variables (set with /set)
+// to ctx for tools that support templating.
+func WithSessionVars(ctx context.Context, vars map[string]string) context.Context {
+	if len(vars) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionVarsKey{}, vars)
+}
+
+// SessionVars returns the session variables attached to ctx, if any.
+func SessionVars(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(sessionVarsKey{}).(map[string]string)
+	return vars
+}
+
+// WithMissionVars attaches the active mission's variables (target, phase,
+// metadata such as scope.cidr, alias.NAME) to ctx for argument templating.
+func WithMissionVars(ctx context.Context, vars map[string]string) context.Context {
+	if len(vars) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, missionVarsKey{}, vars)
+}
+
+// MissionVars returns the mission variables attached to ctx, if any.
+func MissionVars(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(missionVarsKey{}).(map[string]string)
+	return vars
+}
+
+// ExpandSessionVars replaces {{NAME}} references with the session variable
+// values from ctx, {{creds.NAME}} with the same session variables, and other
+// references with the mission variables from ctx. Unknown names are left
+// untouched.
+func ExpandSessionVars(ctx context.Context, s string) string {
+	session, mission := SessionVars(ctx), MissionVars(ctx)
+	if (len(session) == 0 && len(mission) == 0) || !strings.Contains(s, "{{") {
+		return s
+	}
+	return sessionVarRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := sessionVarRef.FindStringSubmatch(ref)[1]
+		if value, ok := session[strings.TrimPrefix(name, credsPrefix)]; ok {
+			return value
+		}
+		if value, ok := mission[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// expandArgs returns a copy of args with variable references expanded in
+// every string, however deeply nested. args itself is left as the model
+// wrote it, since it is also kept in the session history.
+func expandArgs(ctx context.Context, args map[string]any) map[string]any {
+	if len(SessionVars(ctx)) == 0 && len(MissionVars(ctx)) == 0 {
+		return args
+	}
+	expanded, _ := expandValue(ctx, args).(map[string]any)
+	return expanded
+}
+
+func expandValue(ctx context.Context, v any) any {
+	switch v := v.(type) {
+	case string:
+		return ExpandSessionVars(ctx, v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = expandValue(ctx, value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = expandValue(ctx, value)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, value := range v {
+			out[i] = ExpandSessionVars(ctx, value)
+		}
+		return out
+	}
+	return v
+}
+
+// RedactSessionVars replaces session variable values in s with their {{NAME}}
+// reference, so tool output echoing a token (verbose curl, reflected
+// headers) does not put it into model context.
+func RedactSessionVars(vars map[string]string, s string) string {
+	if len(vars) == 0 || s == "" {
+		return s
+	}
+
+	// Longest values first so a value containing another is redacted whole
+	names := make([]string, 0, len(vars))
+	for name, value := range vars {
+		if len(value) >= redactMinLength {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(vars[names[i]]) > len(vars[names[j]])
+	})
+
+	for _, name := range names {
+		s = strings.ReplaceAll(s, vars[name], "{{"+name+"}}")
+	}
+	return s
+}
+
+// redactResult applies RedactSessionVars to every text field of result.
+func redactResult(vars map[string]string, result *ToolResult) {
+	if len(vars) == 0 || result == nil {
+		return
+	}
+	result.ForLLM = RedactSessionVars(vars, result.ForLLM)
+	result.ForUser = RedactSessionVars(vars, result.ForUser)
+	result.RawOutput = RedactSessionVars(vars, result.RawOutput)
+	if result.Err != nil {
+		if msg := RedactSessionVars(vars, result.Err.Error()); msg != result.Err.Error() {
+			result.Err = errors.New(msg)
+		}
+	}
+}
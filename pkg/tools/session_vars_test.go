@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExpandSessionVars(t *testing.T) {
+	ctx := WithSessionVars(context.Background(), map[string]string{"AUTH_TOKEN": "tok-123"})
+
+	got := ExpandSessionVars(ctx, "Bearer {{AUTH_TOKEN}} {{ AUTH_TOKEN }} {{UNKNOWN}}")
+	if got != "Bearer tok-123 tok-123 {{UNKNOWN}}" {
+		t.Errorf("ExpandSessionVars() = %q", got)
+	}
+	if got := ExpandSessionVars(context.Background(), "{{AUTH_TOKEN}}"); got != "{{AUTH_TOKEN}}" {
+		t.Errorf("expected no expansion without session vars, got %q", got)
+	}
+}
+
+func TestExpandArgs_MissionVars(t *testing.T) {
+	ctx := WithSessionVars(context.Background(), map[string]string{"admin": "hunter2"})
+	ctx = WithMissionVars(ctx, map[string]string{"target": "10.0.0.5", "scope.cidr": "10.0.0.0/24"})
+
+	args := map[string]any{
+		"command": "nmap {{scope.cidr}} && login {{target}} -p {{creds.admin}}",
+		"headers": map[string]any{"X-Host": "{{ target }}"},
+		"hosts":   []any{"{{target}}", 7},
+		"other":   "{{scope.ports}}",
+	}
+	got := expandArgs(ctx, args)
+	if got["command"] != "nmap 10.0.0.0/24 && login 10.0.0.5 -p hunter2" {
+		t.Errorf("command = %q", got["command"])
+	}
+	if got["headers"].(map[string]any)["X-Host"] != "10.0.0.5" {
+		t.Errorf("headers = %v", got["headers"])
+	}
+	if hosts := got["hosts"].([]any); hosts[0] != "10.0.0.5" || hosts[1] != 7 {
+		t.Errorf("hosts = %v", hosts)
+	}
+	if got["other"] != "{{scope.ports}}" {
+		t.Errorf("unknown reference expanded: %q", got["other"])
+	}
+	// The model's arguments stay as written, since they go to the history
+	if args["command"] != "nmap {{scope.cidr}} && login {{target}} -p {{creds.admin}}" {
+		t.Errorf("args modified in place: %q", args["command"])
+	}
+}
+
+// TestRegistry_ExpandsArgsExceptWorkflowTools verifies arguments are
+// resolved for regular tools but recorded verbatim by workflow tools
+func TestRegistry_ExpandsArgsExceptWorkflowTools(t *testing.T) {
+	ctx := WithMissionVars(context.Background(), map[string]string{"target": "10.0.0.5"})
+	registry := NewToolRegistry()
+	plain := &argRecordingTool{mockRegistryTool: mockRegistryTool{name: "echo", result: SilentResult("ok")}}
+	workflow := &argRecordingTool{mockRegistryTool: mockRegistryTool{name: "workflow_add_finding", result: SilentResult("ok")}}
+	registry.Register(plain)
+	registry.Register(workflow)
+
+	registry.Execute(ctx, "echo", map[string]any{"text": "{{target}}"})
+	registry.Execute(ctx, "workflow_add_finding", map[string]any{"text": "{{target}}"})
+	if plain.gotArgs["text"] != "10.0.0.5" {
+		t.Errorf("echo args = %v", plain.gotArgs)
+	}
+	if workflow.gotArgs["text"] != "{{target}}" {
+		t.Errorf("workflow tool args = %v", workflow.gotArgs)
+	}
+}
+
+type argRecordingTool struct {
+	mockRegistryTool
+	gotArgs map[string]any
+}
+
+func (m *argRecordingTool) Execute(_ context.Context, args map[string]any) *ToolResult {
+	m.gotArgs = args
+	return m.result
+}
+
+func TestRedactSessionVars(t *testing.T) {
+	vars := map[string]string{
+		"TOKEN":  "abcd",
+		"COOKIE": "sid=abcd1234",
+		"PIN":    "42",
+	}
+	got := RedactSessionVars(vars, "Cookie: sid=abcd1234; token=abcd; answer 42")
+	want := "Cookie: {{COOKIE}}; token={{TOKEN}}; answer 42"
+	if got != want {
+		t.Errorf("RedactSessionVars() = %q, want %q", got, want)
+	}
+}
+
+// TestExecTool_SessionVars verifies exec exports session variables, expands
+// references, and redacts values echoed back in the output
+func TestExecTool_SessionVars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	registry := NewToolRegistry()
+	registry.Register(NewExecTool("", false))
+
+	ctx := WithSessionVars(context.Background(), map[string]string{"AUTH_TOKEN": "s3cr3t-value"})
+	result := registry.Execute(ctx, "exec", map[string]any{
+		"command": `echo "env=$AUTH_TOKEN tpl={{AUTH_TOKEN}}"`,
+	})
+
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.ForLLM)
+	}
+	if strings.Contains(result.ForLLM, "s3cr3t-value") {
+		t.Errorf("secret leaked into ForLLM: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "env={{AUTH_TOKEN}} tpl={{AUTH_TOKEN}}") {
+		t.Errorf("expected both forms expanded then redacted, got: %s", result.ForLLM)
+	}
+}
+
+// TestWebFetch_SessionVarHeaders verifies header templating for web_fetch
+func TestWebFetch_SessionVarHeaders(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello " + gotAuth))
+	}))
+	defer server.Close()
+
+	ctx := WithSessionVars(context.Background(), map[string]string{"AUTH_TOKEN": "tok-987"})
+	registry := NewToolRegistry()
+	registry.Register(NewWebFetchTool(50000))
+	result := registry.Execute(ctx, "web_fetch", map[string]any{
+		"url":     server.URL,
+		"headers": map[string]any{"Authorization": "Bearer {{AUTH_TOKEN}}"},
+	})
+
+	if gotAuth != "Bearer tok-987" {
+		t.Errorf("Authorization header = %q", gotAuth)
+	}
+	if strings.Contains(result.ForLLM, "tok-987") || strings.Contains(result.ForUser, "tok-987") {
+		t.Error("secret leaked into tool result")
+	}
+}
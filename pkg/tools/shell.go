@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 )
 
 type ExecTool struct {
@@ -22,6 +24,9 @@ type ExecTool struct {
 	denyPatterns        []*regexp.Regexp
 	allowPatterns       []*regexp.Regexp
 	restrictToWorkspace bool
+	truncator           *Truncator
+	recordDir           string // Where recordings are saved; empty disables recording
+	recordAll           bool   // Record every command, not only those asking for it
 }
 
 var defaultDenyPatterns = []*regexp.Regexp{
@@ -114,12 +119,27 @@ func NewExecToolWithConfig(workingDir string, restrict bool, config *config.Conf
 		timeout = 300 * time.Second
 	}
 
+	var truncator *Truncator
+	recordAll := false
+	if config != nil {
+		truncator = NewTruncator(config.Tools.Truncation)
+		recordAll = config.Tools.Exec.Record
+	}
+
+	recordDir := ""
+	if workingDir != "" {
+		recordDir = filepath.Join(workingDir, RecordingsDir)
+	}
+
 	return &ExecTool{
 		workingDir:          workingDir,
 		timeout:             timeout,
 		denyPatterns:        denyPatterns,
 		allowPatterns:       nil,
 		restrictToWorkspace: restrict,
+		truncator:           truncator,
+		recordDir:           recordDir,
+		recordAll:           recordAll,
 	}
 }
 
@@ -137,12 +157,16 @@ func (t *ExecTool) Parameters() map[string]any {
 		"properties": map[string]any{
 			"command": map[string]any{
 				"type":        "string",
-				"description": "The shell command to execute",
+				"description": "The shell command to execute. Session variables are exported to its environment ($AUTH_TOKEN) and {{AUTH_TOKEN}} references are expanded",
 			},
 			"working_dir": map[string]any{
 				"type":        "string",
 				"description": "Optional working directory for the command",
 			},
+			"record": map[string]any{
+				"type":        "boolean",
+				"description": "Save a replayable terminal recording (asciinema cast) of the command and its output as evidence. Use for commands that demonstrate a finding",
+			},
 		},
 		"required": []string{"command"},
 	}
@@ -177,6 +201,7 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	if guardError := t.guardCommand(command, cwd); guardError != "" {
 		return ErrorResult(guardError)
 	}
+	command = ExpandSessionVars(ctx, command)
 
 	// timeout == 0 means no timeout
 	var cmdCtx context.Context
@@ -197,6 +222,12 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	if cwd != "" {
 		cmd.Dir = cwd
 	}
+	if vars := SessionVars(ctx); len(vars) > 0 {
+		cmd.Env = os.Environ()
+		for name, value := range vars {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
 
 	prepareCommandForTermination(cmd)
 
@@ -204,6 +235,13 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	var recorder *CastRecorder
+	if record, _ := args["record"].(bool); t.recordDir != "" && (record || t.recordAll) {
+		recorder = NewCastRecorder(command)
+		cmd.Stdout = io.MultiWriter(&stdout, recorder)
+		cmd.Stderr = io.MultiWriter(&stderr, recorder)
+	}
+
 	if err := cmd.Start(); err != nil {
 		return ErrorResult(fmt.Sprintf("failed to start command: %v", err))
 	}
@@ -233,9 +271,11 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		output += "\nSTDERR:\n" + stderr.String()
 	}
 
+	recording := t.saveRecording(ctx, recorder, cmdCtx, err)
+
 	if err != nil {
 		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
-			msg := fmt.Sprintf("Command timed out after %v", t.timeout)
+			msg := fmt.Sprintf("Command timed out after %v", t.timeout) + recording
 			return &ToolResult{
 				ForLLM:  msg,
 				ForUser: msg,
@@ -249,26 +289,56 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		output = "(no output)"
 	}
 
-	maxLen := 10000
-	if len(output) > maxLen {
-		output = output[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxLen)
-	}
+	// The untruncated output stays available for parsers and result_extract
+	raw := output
+	output = t.truncator.Truncate(output, commandName(command), t.Name()) + recording
 
 	if err != nil {
 		return &ToolResult{
-			ForLLM:  output,
-			ForUser: output,
-			IsError: true,
+			ForLLM:    output,
+			ForUser:   output,
+			RawOutput: raw,
+			IsError:   true,
 		}
 	}
 
 	return &ToolResult{
-		ForLLM:  output,
-		ForUser: output,
-		IsError: false,
+		ForLLM:    output,
+		ForUser:   output,
+		RawOutput: raw,
+		IsError:   false,
 	}
 }
 
+// saveRecording finishes and saves recorder, if any, and returns a note for
+// the result naming the cast. Session variable values are redacted, as in
+// the output the model sees.
+func (t *ExecTool) saveRecording(ctx context.Context, recorder *CastRecorder, cmdCtx context.Context, runErr error) string {
+	if recorder == nil {
+		return ""
+	}
+
+	switch {
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		recorder.Exit(fmt.Sprintf("timed out after %v", t.timeout))
+	case runErr != nil:
+		recorder.Exit(runErr.Error())
+	default:
+		recorder.Exit("exit status 0")
+	}
+
+	vars := SessionVars(ctx)
+	path, err := recorder.Save(t.recordDir, func(s string) string { return RedactSessionVars(vars, s) })
+	if err != nil {
+		logger.WarnCF("tool", "Failed to save command recording", map[string]any{"error": err.Error()})
+		return "\nRecording failed: " + err.Error()
+	}
+	if rel, err := filepath.Rel(t.workingDir, path); err == nil {
+		path = rel
+	}
+	return "\nRecording: " + filepath.ToSlash(path)
+}
+
 func (t *ExecTool) guardCommand(command, cwd string) string {
 	cmd := strings.TrimSpace(command)
 	lower := strings.ToLower(cmd)
@@ -344,3 +414,17 @@ func (t *ExecTool) SetAllowPatterns(patterns []string) error {
 	}
 	return nil
 }
+
+// SelfTest runs a harmless command through the same shell, guard and working
+// directory the agent's commands use
+func (t *ExecTool) SelfTest(ctx context.Context, _ SelfTestOptions) (string, error) {
+	const marker = "picoclaw-selftest"
+	result := t.Execute(ctx, map[string]any{"command": "echo " + marker})
+	if result.IsError {
+		return "", errors.New(result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, marker) {
+		return "", fmt.Errorf("unexpected output: %s", strings.TrimSpace(result.ForLLM))
+	}
+	return "shell runs commands in " + t.workingDir, nil
+}
@@ -1,9 +1,11 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -160,3 +162,42 @@ func parseSPIArgs(args map[string]any) (device string, speed uint32, mode uint8,
 
 	return dev, speed, mode, bits, ""
 }
+
+// spiLoopbackPattern is sent on loopback-wired SPI devices during self-tests
+var spiLoopbackPattern = []byte{0xA5, 0x5A, 0x00, 0xFF}
+
+// SelfTest checks that every SPI device can be opened. With loopback wiring
+// it also sends a test pattern through each device and expects it back.
+func (t *SPITool) SelfTest(_ context.Context, opts SelfTestOptions) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", &NoHardwareError{Reason: "SPI is only supported on Linux"}
+	}
+	matches, err := filepath.Glob("/dev/spidev*")
+	if err != nil {
+		return "", fmt.Errorf("failed to scan for SPI devices: %w", err)
+	}
+	if len(matches) == 0 {
+		return "", &NoHardwareError{Reason: "no SPI devices found (enable SPI in the device tree and load the spidev module)"}
+	}
+	for _, path := range matches {
+		if !opts.Loopback {
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			if err != nil {
+				return "", fmt.Errorf("%w (check permissions, e.g. the spi group)", err)
+			}
+			f.Close()
+			continue
+		}
+		received, err := spiLoopback(path, spiLoopbackPattern)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(received, spiLoopbackPattern) {
+			return "", fmt.Errorf("%s loopback sent % x, received % x (is MOSI wired to MISO?)", path, spiLoopbackPattern, received)
+		}
+	}
+	if opts.Loopback {
+		return fmt.Sprintf("%d device(s) echoed the loopback pattern", len(matches)), nil
+	}
+	return fmt.Sprintf("%d device(s) accessible", len(matches)), nil
+}
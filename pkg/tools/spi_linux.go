@@ -2,6 +2,7 @@ package tools
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"runtime"
 	"syscall"
@@ -196,3 +197,30 @@ func (t *SPITool) readDevice(args map[string]any) *ToolResult {
 	}, "", "  ")
 	return SilentResult(string(result))
 }
+
+// spiLoopback sends tx through the SPI device at devPath in mode 0 at
+// 500 kHz and returns what came back
+func spiLoopback(devPath string, tx []byte) ([]byte, error) {
+	const speed = 500000
+	fd, errResult := configureSPI(devPath, 0, 8, speed)
+	if errResult != nil {
+		return nil, errors.New(errResult.ForLLM)
+	}
+	defer syscall.Close(fd)
+
+	rx := make([]byte, len(tx))
+	xfer := spiTransfer{
+		txBuf:       uint64(uintptr(unsafe.Pointer(&tx[0]))),
+		rxBuf:       uint64(uintptr(unsafe.Pointer(&rx[0]))),
+		length:      uint32(len(tx)),
+		speedHz:     speed,
+		bitsPerWord: 8,
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), spiIocMessage1, uintptr(unsafe.Pointer(&xfer)))
+	runtime.KeepAlive(tx)
+	runtime.KeepAlive(rx)
+	if errno != 0 {
+		return nil, fmt.Errorf("SPI transfer on %s failed: %v", devPath, errno)
+	}
+	return rx, nil
+}
@@ -2,6 +2,8 @@
 
 package tools
 
+import "fmt"
+
 // transfer is a stub for non-Linux platforms.
 func (t *SPITool) transfer(_ map[string]any) *ToolResult {
 	return ErrorResult("SPI is only supported on Linux")
@@ -11,3 +13,8 @@ func (t *SPITool) transfer(_ map[string]any) *ToolResult {
 func (t *SPITool) readDevice(_ map[string]any) *ToolResult {
 	return ErrorResult("SPI is only supported on Linux")
 }
+
+// spiLoopback is a stub for non-Linux platforms.
+func spiLoopback(_ string, _ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("SPI is only supported on Linux")
+}
@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// tokenIssue is a weakness found in a token. With an active mission each
+// one is filed as a finding.
+type tokenIssue struct {
+	Check    string // alg_none, weak_secret, no_expiry, ...
+	Title    string
+	Severity workflow.Severity
+	Detail   string
+}
+
+// tokenReport is what analysis learned about one token
+type tokenReport struct {
+	Kind        string   // JWT, JWE, SAML or opaque
+	Fingerprint string   // Short SHA-256 of the token, to tell tokens apart without repeating them
+	Details     []string // Decoded contents shown to the model
+	Issues      []tokenIssue
+}
+
+func (r *tokenReport) detail(format string, args ...any) {
+	r.Details = append(r.Details, fmt.Sprintf(format, args...))
+}
+
+func (r *tokenReport) issue(check string, severity workflow.Severity, title, format string, args ...any) {
+	r.Issues = append(r.Issues, tokenIssue{Check: check, Title: title, Severity: severity, Detail: fmt.Sprintf(format, args...)})
+}
+
+// TokenAnalyzeTool decodes JWTs, SAML messages and opaque bearer tokens and
+// checks them for common weaknesses: alg=none, HMAC secrets found in a
+// wordlist, missing or long expiry, unsigned or SHA-1 signed SAML and
+// low-entropy tokens. Findings are filed by the tool itself, so their
+// severity and evidence don't depend on the model decoding base64 by hand.
+type TokenAnalyzeTool struct {
+	workspace string
+	getEngine func() *workflow.Engine
+}
+
+func NewTokenAnalyzeTool(workspace string, getEngine func() *workflow.Engine) *TokenAnalyzeTool {
+	return &TokenAnalyzeTool{workspace: workspace, getEngine: getEngine}
+}
+
+func (t *TokenAnalyzeTool) Name() string {
+	return "token_analyze"
+}
+
+func (t *TokenAnalyzeTool) Description() string {
+	return "Decode and assess a JWT, SAML response/assertion or opaque bearer token: algorithm, claims, expiry, signature, and weaknesses such as alg=none, a guessable HMAC secret (tried against common secrets and an optional wordlist) or low entropy. Weaknesses are recorded as mission findings automatically. Use this instead of decoding tokens by hand."
+}
+
+func (t *TokenAnalyzeTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"token": map[string]any{
+				"type":        "string",
+				"description": "The token: a JWT, a base64 (optionally deflated or URL-encoded) SAML message, or any bearer token. An Authorization header value is accepted",
+			},
+			"wordlist": map[string]any{
+				"type":        "string",
+				"description": "Optional file of candidate HMAC secrets, one per line, tried after the built-in list (relative paths are in the workspace)",
+			},
+			"record_findings": map[string]any{
+				"type":        "boolean",
+				"description": "Record weaknesses as mission findings (default true)",
+			},
+		},
+		"required": []string{"token"},
+	}
+}
+
+func (t *TokenAnalyzeTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	token, _ := args["token"].(string)
+	token = strings.TrimSpace(token)
+	for _, prefix := range []string{"Authorization:", "Bearer ", "bearer "} {
+		token = strings.TrimSpace(strings.TrimPrefix(token, prefix))
+	}
+	if token == "" {
+		return ErrorResult("token is required")
+	}
+
+	var secrets secretSource = builtinSecrets
+	if path, _ := args["wordlist"].(string); path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(t.workspace, path)
+		}
+		secrets = withWordlist(path)
+	}
+
+	report, err := analyzeToken(ctx, token, secrets)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s token (fingerprint %s)\n", report.Kind, report.Fingerprint)
+	for _, line := range report.Details {
+		sb.WriteString(line + "\n")
+	}
+	if len(report.Issues) == 0 {
+		sb.WriteString("\nNo weaknesses found.\n")
+	} else {
+		sb.WriteString("\nWeaknesses:\n")
+		for _, issue := range report.Issues {
+			fmt.Fprintf(&sb, "- [%s] %s: %s\n", issue.Severity, issue.Title, issue.Detail)
+		}
+	}
+
+	if record, ok := args["record_findings"].(bool); (!ok || record) && len(report.Issues) > 0 {
+		sb.WriteString(t.recordFindings(report))
+	}
+	return NewToolResult(strings.TrimRight(sb.String(), "\n"))
+}
+
+// recordFindings files report's issues with the active mission, skipping
+// any already recorded for the same token
+func (t *TokenAnalyzeTool) recordFindings(report *tokenReport) string {
+	if t.getEngine == nil {
+		return ""
+	}
+	engine := t.getEngine()
+	if engine == nil {
+		return ""
+	}
+
+	existing := make(map[string]bool)
+	for _, f := range engine.GetState().Findings {
+		existing[f.Title+"\x00"+f.Evidence] = true
+	}
+
+	filed, skipped := 0, 0
+	for _, issue := range report.Issues {
+		evidence := fmt.Sprintf("%s token %s: %s\n\n%s", report.Kind, report.Fingerprint, issue.Detail, strings.Join(report.Details, "\n"))
+		if existing[issue.Title+"\x00"+evidence] {
+			skipped++
+			continue
+		}
+		description := tokenIssueDescriptions[issue.Check]
+		if err := engine.AddFinding(issue.Title, description, issue.Severity, evidence); err != nil {
+			return fmt.Sprintf("\nFailed to record findings: %v\n", err)
+		}
+		filed++
+	}
+
+	msg := fmt.Sprintf("\nRecorded %d finding(s)", filed)
+	if skipped > 0 {
+		msg += fmt.Sprintf("; %d already recorded for this token", skipped)
+	}
+	return msg + ".\n"
+}
+
+// tokenIssueDescriptions explains each weakness in the finding
+var tokenIssueDescriptions = map[string]string{
+	"alg_none":         "The token declares alg=none and carries no signature. If the server accepts such tokens, anyone can forge claims such as the user or role.",
+	"weak_secret":      "The token's HMAC signing secret was found in a list of common secrets. Anyone who knows it can forge valid tokens for any user.",
+	"no_expiry":        "The token has no expiry, so a leaked token stays valid until the signing key is rotated.",
+	"long_lifetime":    "The token stays valid for a long time, widening the window in which a leaked token can be replayed.",
+	"sensitive_claims": "The token carries sensitive data in its claims. JWT claims are only encoded, so anyone holding the token can read them.",
+	"jwe_rsa1_5":       "The token is encrypted with RSA1_5 (RSAES-PKCS1-v1_5) key wrapping, which is vulnerable to padding oracle attacks.",
+	"saml_unsigned":    "Neither the SAML response nor its assertion is signed. A service provider accepting it would trust forged identities.",
+	"saml_sha1":        "The SAML signature or digest uses SHA-1, which is deprecated because of practical collision attacks.",
+	"saml_no_expiry":   "The SAML assertion has no NotOnOrAfter limit, so a captured assertion can be replayed indefinitely.",
+	"low_entropy":      "The token is short or drawn from a small alphabet, so it can be guessed by brute force.",
+	"encoded_data":     "The token is encoded plain text rather than a random value, so it can be read and likely forged.",
+}
+
+// analyzeToken works out what kind of token s is and analyzes it
+func analyzeToken(ctx context.Context, s string, secrets secretSource) (*tokenReport, error) {
+	sum := sha256.Sum256([]byte(s))
+	fingerprint := hex.EncodeToString(sum[:6])
+
+	parts := strings.Split(s, ".")
+	if (len(parts) == 3 || len(parts) == 5) && looksLikeJOSEHeader(parts[0]) {
+		report := &tokenReport{Fingerprint: fingerprint}
+		var err error
+		if len(parts) == 3 {
+			err = analyzeJWT(ctx, report, parts, secrets)
+		} else {
+			err = analyzeJWE(report, parts)
+		}
+		return report, err
+	}
+
+	if xml, ok := decodeSAML(s); ok {
+		report := &tokenReport{Kind: "SAML", Fingerprint: fingerprint}
+		return report, analyzeSAML(report, xml)
+	}
+
+	report := &tokenReport{Kind: "opaque", Fingerprint: fingerprint}
+	analyzeOpaque(report, s)
+	return report, nil
+}
+
+// analyzeOpaque estimates the entropy of a token that isn't self-describing
+// and checks whether it is merely encoded text
+func analyzeOpaque(report *tokenReport, s string) {
+	bits := float64(len(s)) * math.Log2(float64(alphabetSize(s)))
+	report.detail("Length: %d characters, alphabet of %d, about %.0f bits of entropy at most", len(s), alphabetSize(s), bits)
+
+	if decoded, ok := decodeBase64(s); ok && isPrintable(decoded) && len(decoded) >= 4 {
+		report.detail("Decodes to: %s", decoded)
+		report.issue("encoded_data", workflow.SeverityMedium, "Token is encoded data, not a random value",
+			"the token base64-decodes to readable text %q", decoded)
+		return
+	}
+	if bits < 64 {
+		report.issue("low_entropy", workflow.SeverityMedium, "Low-entropy token",
+			"at most %.0f bits of entropy (%d characters from an alphabet of %d); 128 bits is the usual minimum", bits, len(s), alphabetSize(s))
+	}
+}
+
+// alphabetSize is the smallest common alphabet s is drawn from: digits,
+// hex, or the letter cases and symbols it uses
+func alphabetSize(s string) int {
+	var digits, lower, upper, other, nonHex bool
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case r >= 'a' && r <= 'z':
+			lower, nonHex = true, nonHex || r > 'f'
+		case r >= 'A' && r <= 'Z':
+			upper, nonHex = true, nonHex || r > 'F'
+		default:
+			other = true
+		}
+	}
+	switch {
+	case !lower && !upper && !other:
+		return 10
+	case !nonHex && !other && !(lower && upper):
+		return 16
+	}
+
+	size := 2 // base64 and base64url add two symbols
+	if !other {
+		size = 0
+	}
+	if digits {
+		size += 10
+	}
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	return max(size, 2)
+}
+
+// decodeBase64 decodes standard or URL-safe base64, padded or not
+func decodeBase64(s string) (string, bool) {
+	s = strings.TrimRight(s, "=")
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return string(b), true
+		}
+	}
+	return "", false
+}
+
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// secretSource yields candidate HMAC secrets until yield returns false
+type secretSource func(yield func(secret string) bool) error
+
+// builtinSecrets are secrets seen in tutorials, framework defaults and
+// careless deployments
+var builtinSecrets secretSource = func(yield func(string) bool) error {
+	for _, s := range commonJWTSecrets {
+		if !yield(s) {
+			return nil
+		}
+	}
+	return nil
+}
+
+var commonJWTSecrets = []string{
+	"", "secret", "Secret", "SECRET", "secret123", "secretkey", "secret_key", "secret-key",
+	"your-256-bit-secret", "your-384-bit-secret", "your-512-bit-secret", "your_jwt_secret",
+	"jwt", "jwt_secret", "jwt-secret", "jwtsecret", "JWT_SECRET", "jwtkey", "jwt_key",
+	"key", "private", "password", "Password", "password123", "changeme", "changeit",
+	"default", "admin", "test", "testing", "dev", "development", "production", "qwerty",
+	"123456", "12345678", "1234567890", "letmein", "mysecret", "mysecretkey", "supersecret",
+	"super-secret", "topsecret", "s3cr3t", "shhhhh", "keyboard cat", "hello", "token",
+	"api", "apikey", "api_key", "app", "app_secret", "auth", "authkey", "access", "signing_key",
+	"HS256", "none", "null", "undefined", "example", "demo", "sample",
+}
+
+// withWordlist tries the built-in secrets, then every line of path
+func withWordlist(path string) secretSource {
+	return func(yield func(string) bool) error {
+		stopped := false
+		builtinSecrets(func(s string) bool {
+			stopped = !yield(s)
+			return !stopped
+		})
+		if stopped {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open wordlist: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if !yield(strings.TrimRight(scanner.Text(), "\r")) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+}
@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// maxTokenLifetime is the longest a JWT may stay valid before it is
+// reported as long-lived
+const maxTokenLifetime = 24 * time.Hour
+
+// sensitiveClaimWords mark claim names that should never travel in a
+// readable token
+var sensitiveClaimWords = []string{"password", "passwd", "pwd", "secret", "api_key", "apikey", "private_key", "ssn", "credit_card", "card_number"}
+
+// looksLikeJOSEHeader reports whether s is a base64url JSON object with an
+// "alg" member, the first segment of a JWS or JWE
+func looksLikeJOSEHeader(s string) bool {
+	decoded, ok := decodeBase64(s)
+	if !ok {
+		return false
+	}
+	var header map[string]any
+	if err := json.Unmarshal([]byte(decoded), &header); err != nil {
+		return false
+	}
+	_, ok = header["alg"]
+	return ok
+}
+
+// decodeJSONSegment decodes one base64url segment of a JOSE token
+func decodeJSONSegment(s string) (map[string]any, string, error) {
+	decoded, ok := decodeBase64(s)
+	if !ok {
+		return nil, "", fmt.Errorf("segment is not base64url")
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(decoded), &m); err != nil {
+		return nil, decoded, fmt.Errorf("segment is not a JSON object: %w", err)
+	}
+	return m, decoded, nil
+}
+
+// analyzeJWT checks a signed JWT: its algorithm, HMAC secret, expiry and
+// claims
+func analyzeJWT(ctx context.Context, report *tokenReport, parts []string, secrets secretSource) error {
+	report.Kind = "JWT"
+	header, rawHeader, err := decodeJSONSegment(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid JWT header: %w", err)
+	}
+	claims, rawClaims, err := decodeJSONSegment(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	report.detail("Header: %s", compactJSON(rawHeader))
+	report.detail("Claims: %s", compactJSON(rawClaims))
+
+	alg, _ := header["alg"].(string)
+	switch {
+	case strings.EqualFold(alg, "none"):
+		report.issue("alg_none", workflow.SeverityHigh, "JWT issued with alg=none (unsigned)",
+			"the header declares alg=%q and the signature is %d bytes", alg, len(parts[2]))
+	case parts[2] == "":
+		report.detail("Signature: missing although alg is %s", alg)
+	}
+
+	if newHash := hmacHash(alg); newHash != nil && parts[2] != "" {
+		secret, tried, found, err := crackHMAC(ctx, parts, newHash, secrets)
+		if err != nil {
+			return err
+		}
+		if found {
+			report.issue("weak_secret", workflow.SeverityCritical, "JWT signed with a guessable HMAC secret",
+				"%s signature verifies with the secret %q", alg, secret)
+		} else {
+			report.detail("HMAC secret: not among %d candidates tried", tried)
+		}
+	} else if strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS") || strings.HasPrefix(alg, "ES") || alg == "EdDSA" {
+		report.detail("Asymmetric signature (%s): test whether the server also accepts the token re-signed with HS256 using its public key, or with alg=none", alg)
+	}
+
+	for _, name := range []string{"jku", "x5u", "jwk"} {
+		if value, ok := header[name]; ok {
+			report.detail("Header %s = %v: test whether the server fetches or trusts keys named by the token", name, value)
+		}
+	}
+	if kid, ok := header["kid"].(string); ok && strings.ContainsAny(kid, "/\\'\"; ") {
+		report.detail("Header kid %q contains path or query characters: test it for injection", kid)
+	}
+
+	checkJWTTimes(report, claims)
+
+	var sensitive []string
+	for name := range claims {
+		lower := strings.ToLower(name)
+		for _, word := range sensitiveClaimWords {
+			if strings.Contains(lower, word) {
+				sensitive = append(sensitive, name)
+				break
+			}
+		}
+	}
+	if len(sensitive) > 0 {
+		sort.Strings(sensitive)
+		report.issue("sensitive_claims", workflow.SeverityMedium, "Sensitive data in JWT claims",
+			"the readable claims include %s", strings.Join(sensitive, ", "))
+	}
+	return nil
+}
+
+// checkJWTTimes reports the token's validity window and flags a missing or
+// overly long expiry
+func checkJWTTimes(report *tokenReport, claims map[string]any) {
+	now := determinism.Now()
+	iat, hasIat := numericDate(claims["iat"])
+	exp, hasExp := numericDate(claims["exp"])
+	if hasIat {
+		report.detail("Issued: %s", iat.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok {
+		report.detail("Not before: %s", nbf.UTC().Format(time.RFC3339))
+	}
+
+	if !hasExp {
+		report.issue("no_expiry", workflow.SeverityMedium, "JWT without expiry", "the token has no exp claim")
+		return
+	}
+	state := "valid"
+	if now.After(exp) {
+		state = "expired"
+	}
+	report.detail("Expires: %s (%s)", exp.UTC().Format(time.RFC3339), state)
+
+	if hasIat && exp.Sub(iat) > maxTokenLifetime {
+		report.issue("long_lifetime", workflow.SeverityLow, "Long-lived JWT",
+			"the token is valid for %s from issue (exp - iat)", formatLifetime(exp.Sub(iat)))
+	}
+}
+
+// numericDate reads a JWT NumericDate claim
+func numericDate(v any) (time.Time, bool) {
+	switch n := v.(type) {
+	case float64:
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)), true
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return time.Unix(int64(f), 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func formatLifetime(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	}
+	return d.Round(time.Minute).String()
+}
+
+// hmacHash returns the hash behind an HMAC JWS algorithm, or nil
+func hmacHash(alg string) func() hash.Hash {
+	switch alg {
+	case "HS256":
+		return sha256.New
+	case "HS384":
+		return sha512.New384
+	case "HS512":
+		return sha512.New
+	}
+	return nil
+}
+
+// crackHMAC tries each candidate secret against the token's signature
+func crackHMAC(ctx context.Context, parts []string, newHash func() hash.Hash, secrets secretSource) (secret string, tried int, found bool, err error) {
+	want, ok := decodeBase64(parts[2])
+	if !ok {
+		return "", 0, false, fmt.Errorf("invalid JWT signature encoding")
+	}
+	input := []byte(parts[0] + "." + parts[1])
+
+	err = secrets(func(candidate string) bool {
+		if tried%10000 == 0 && ctx.Err() != nil {
+			return false
+		}
+		tried++
+		mac := hmac.New(newHash, []byte(candidate))
+		mac.Write(input)
+		if hmac.Equal(mac.Sum(nil), []byte(want)) {
+			secret, found = candidate, true
+			return false
+		}
+		return true
+	})
+	if err == nil && !found {
+		err = ctx.Err()
+	}
+	return secret, tried, found, err
+}
+
+// analyzeJWE reports an encrypted token's algorithms; its payload can't be
+// read without the key
+func analyzeJWE(report *tokenReport, parts []string) error {
+	report.Kind = "JWE"
+	header, rawHeader, err := decodeJSONSegment(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid JWE header: %w", err)
+	}
+	report.detail("Header: %s", compactJSON(rawHeader))
+	report.detail("Payload: encrypted")
+
+	if alg, _ := header["alg"].(string); alg == "RSA1_5" {
+		report.issue("jwe_rsa1_5", workflow.SeverityLow, "JWE uses RSA1_5 key wrapping",
+			"the header declares alg=RSA1_5, enc=%v", header["enc"])
+	}
+	return nil
+}
+
+// compactJSON renders JSON on one line, or returns s unchanged if it isn't
+func compactJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+// maxSAMLSize caps an inflated SAML message
+const maxSAMLSize = 1 << 20
+
+// decodeSAML decodes a SAML message as carried by the POST binding
+// (base64) or the redirect binding (URL-encoded, deflated base64), or given
+// as raw XML
+func decodeSAML(s string) (string, bool) {
+	if strings.Contains(s, "%") {
+		// Only unescape the redirect binding: '+' is also a base64 symbol
+		if unescaped, err := url.QueryUnescape(s); err == nil {
+			s = unescaped
+		}
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<") {
+		return s, isSAMLXML(s)
+	}
+
+	decoded, ok := decodeBase64(strings.Join(strings.Fields(s), ""))
+	if !ok {
+		return "", false
+	}
+	if isSAMLXML(decoded) {
+		return decoded, true
+	}
+	inflated, err := io.ReadAll(io.LimitReader(flate.NewReader(strings.NewReader(decoded)), maxSAMLSize))
+	if err != nil {
+		return "", false
+	}
+	return string(inflated), isSAMLXML(string(inflated))
+}
+
+func isSAMLXML(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "<") && strings.Contains(s, "urn:oasis:names:tc:SAML")
+}
+
+// samlSummary is what analyzeSAML collects from a message
+type samlSummary struct {
+	root          string
+	issuer        string
+	nameID        string
+	audiences     []string
+	notBefore     string
+	notOnOrAfter  string
+	signedRoot    bool // The response or request itself is signed
+	signedAsserts int
+	assertions    int
+	algorithms    []string // SignatureMethod and DigestMethod algorithm URIs
+}
+
+// analyzeSAML reports a SAML message's issuer, subject and conditions and
+// checks its signatures and expiry
+func analyzeSAML(report *tokenReport, doc string) error {
+	summary, err := parseSAML(doc)
+	if err != nil {
+		return fmt.Errorf("invalid SAML message: %w", err)
+	}
+
+	report.detail("Message: %s", summary.root)
+	if summary.issuer != "" {
+		report.detail("Issuer: %s", summary.issuer)
+	}
+	if summary.nameID != "" {
+		report.detail("Subject: %s", summary.nameID)
+	}
+	if len(summary.audiences) > 0 {
+		report.detail("Audience: %s", strings.Join(summary.audiences, ", "))
+	}
+	if summary.notBefore != "" {
+		report.detail("Not before: %s", summary.notBefore)
+	}
+	if summary.notOnOrAfter != "" {
+		state := ""
+		if t, err := time.Parse(time.RFC3339, summary.notOnOrAfter); err == nil {
+			state = " (valid)"
+			if !determinism.Now().Before(t) {
+				state = " (expired)"
+			}
+		}
+		report.detail("Not on or after: %s%s", summary.notOnOrAfter, state)
+	}
+	report.detail("Signatures: message %s, %d of %d assertion(s)", signedWord(summary.signedRoot), summary.signedAsserts, summary.assertions)
+
+	if !summary.signedRoot && summary.signedAsserts == 0 {
+		report.issue("saml_unsigned", workflow.SeverityHigh, "Unsigned SAML message",
+			"neither the %s nor any of its %d assertion(s) carries a signature", summary.root, summary.assertions)
+	}
+	for _, alg := range summary.algorithms {
+		if strings.Contains(strings.ToLower(alg), "sha1") {
+			report.issue("saml_sha1", workflow.SeverityLow, "SAML signature uses SHA-1",
+				"the message is signed with %s", alg)
+			break
+		}
+	}
+	if summary.assertions > 0 && summary.notOnOrAfter == "" {
+		report.issue("saml_no_expiry", workflow.SeverityMedium, "SAML assertion without expiry",
+			"the assertion's Conditions and SubjectConfirmationData set no NotOnOrAfter")
+	}
+	return nil
+}
+
+func signedWord(signed bool) string {
+	if signed {
+		return "signed"
+	}
+	return "unsigned"
+}
+
+// parseSAML walks the XML token stream. A Signature counts for the element
+// it is a direct child of: the root message or an Assertion.
+func parseSAML(doc string) (*samlSummary, error) {
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(doc)))
+	decoder.Strict = false
+
+	summary := &samlSummary{}
+	var stack []string
+	var text strings.Builder
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			name := el.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			switch name {
+			case "Assertion":
+				summary.assertions++
+			case "Signature":
+				if len(stack) == 1 {
+					summary.signedRoot = true
+				} else if parent == "Assertion" {
+					summary.signedAsserts++
+				}
+			case "SignatureMethod", "DigestMethod":
+				if alg := xmlAttr(el, "Algorithm"); alg != "" && !slices.Contains(summary.algorithms, alg) {
+					summary.algorithms = append(summary.algorithms, alg)
+				}
+			case "Conditions", "SubjectConfirmationData":
+				if v := xmlAttr(el, "NotBefore"); v != "" && summary.notBefore == "" {
+					summary.notBefore = v
+				}
+				if v := xmlAttr(el, "NotOnOrAfter"); v != "" && summary.notOnOrAfter == "" {
+					summary.notOnOrAfter = v
+				}
+			}
+			if len(stack) == 0 {
+				summary.root = name
+			}
+			stack = append(stack, name)
+			text.Reset()
+		case xml.CharData:
+			text.Write(el)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			switch el.Name.Local {
+			case "Issuer":
+				if summary.issuer == "" {
+					summary.issuer = value
+				}
+			case "NameID":
+				if summary.nameID == "" {
+					summary.nameID = value
+				}
+			case "Audience":
+				if value != "" && !slices.Contains(summary.audiences, value) {
+					summary.audiences = append(summary.audiences, value)
+				}
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			text.Reset()
+		}
+	}
+	if summary.root == "" {
+		return nil, fmt.Errorf("no root element")
+	}
+	return summary, nil
+}
+
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
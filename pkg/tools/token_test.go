@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+func signHS256(t *testing.T, header, claims, secret string) string {
+	t.Helper()
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func issueChecks(report *tokenReport) []string {
+	var checks []string
+	for _, issue := range report.Issues {
+		checks = append(checks, issue.Check)
+	}
+	return checks
+}
+
+func TestTokenAnalyzeTool_CracksWeakSecretAndFilesFindings(t *testing.T) {
+	engine := workflow.NewEngine(&workflow.Workflow{Name: "wf", Phases: []workflow.Phase{{Name: "auth"}}}, "app.example.com", t.TempDir())
+	tool := NewTokenAnalyzeTool(t.TempDir(), func() *workflow.Engine { return engine })
+
+	token := signHS256(t, `{"alg":"HS256","typ":"JWT"}`, `{"sub":"alice","role":"user","iat":1700000000}`, "secret")
+	args := map[string]any{"token": "Bearer " + token}
+
+	result := tool.Execute(context.Background(), args)
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	for _, want := range []string{"JWT token", `"sub":"alice"`, `secret "secret"`, "Recorded 2 finding(s)"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("result missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+
+	findings := engine.GetState().Findings
+	if len(findings) != 2 {
+		t.Fatalf("findings = %d, want 2", len(findings))
+	}
+	if findings[0].Severity != workflow.SeverityCritical || !strings.Contains(findings[0].Evidence, `"role":"user"`) {
+		t.Errorf("finding = %+v", findings[0])
+	}
+
+	result = tool.Execute(context.Background(), args)
+	if !strings.Contains(result.ForLLM, "Recorded 0 finding(s); 2 already recorded") {
+		t.Errorf("rerun result = %s", result.ForLLM)
+	}
+	if n := len(engine.GetState().Findings); n != 2 {
+		t.Errorf("findings after rerun = %d, want 2", n)
+	}
+}
+
+func TestAnalyzeToken_JWT(t *testing.T) {
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte("nope\r\ncorrect horse battery staple\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		secrets secretSource
+		want    []string
+	}{
+		{
+			name:    "alg none",
+			token:   "eyJhbGciOiJub25lIn0.eyJzdWIiOiJhZG1pbiIsImV4cCI6NDEwMjQ0NDgwMH0.",
+			secrets: builtinSecrets,
+			want:    []string{"alg_none"},
+		},
+		{
+			name:    "strong secret, long lifetime",
+			token:   signHS256(t, `{"alg":"HS256"}`, `{"sub":"a","iat":1700000000,"exp":1710000000}`, "k7#Qv!93mZp0-x"),
+			secrets: builtinSecrets,
+			want:    []string{"long_lifetime"},
+		},
+		{
+			name:    "secret from wordlist, sensitive claims",
+			token:   signHS256(t, `{"alg":"HS256"}`, `{"sub":"a","password":"hunter2","exp":1700000600,"iat":1700000000}`, "correct horse battery staple"),
+			secrets: withWordlist(wordlist),
+			want:    []string{"weak_secret", "sensitive_claims"},
+		},
+		{
+			name:    "JWE with RSA1_5",
+			token:   base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA1_5","enc":"A128GCM"}`)) + ".a.b.c.d",
+			secrets: builtinSecrets,
+			want:    []string{"jwe_rsa1_5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := analyzeToken(context.Background(), tt.token, tt.secrets)
+			if err != nil {
+				t.Fatalf("analyzeToken() error: %v", err)
+			}
+			if got := issueChecks(report); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("issues = %v, want %v\n%s", got, tt.want, strings.Join(report.Details, "\n"))
+			}
+		})
+	}
+}
+
+func TestAnalyzeToken_SAML(t *testing.T) {
+	unsigned := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">
+  <saml:Issuer>https://idp.example.com</saml:Issuer>
+  <saml:Assertion>
+    <saml:Subject><saml:NameID>alice@example.com</saml:NameID></saml:Subject>
+    <saml:Conditions NotBefore="2024-01-01T00:00:00Z"/>
+  </saml:Assertion>
+</samlp:Response>`
+
+	report, err := analyzeToken(context.Background(), base64.StdEncoding.EncodeToString([]byte(unsigned)), builtinSecrets)
+	if err != nil {
+		t.Fatalf("analyzeToken() error: %v", err)
+	}
+	if report.Kind != "SAML" {
+		t.Fatalf("Kind = %s, want SAML", report.Kind)
+	}
+	if got := strings.Join(issueChecks(report), ","); got != "saml_unsigned,saml_no_expiry" {
+		t.Errorf("issues = %s", got)
+	}
+	if details := strings.Join(report.Details, "\n"); !strings.Contains(details, "Subject: alice@example.com") {
+		t.Errorf("details = %s", details)
+	}
+
+	signed := strings.Replace(unsigned, "<saml:Subject>", `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo><ds:SignatureMethod Algorithm="http://www.w3.org/2000/09/xmldsig#rsa-sha1"/></ds:SignedInfo></ds:Signature><saml:Subject>`, 1)
+	signed = strings.Replace(signed, `NotBefore="2024-01-01T00:00:00Z"`, `NotBefore="2024-01-01T00:00:00Z" NotOnOrAfter="2024-01-01T00:05:00Z"`, 1)
+	report, err = analyzeToken(context.Background(), signed, builtinSecrets)
+	if err != nil {
+		t.Fatalf("analyzeToken() error: %v", err)
+	}
+	if got := strings.Join(issueChecks(report), ","); got != "saml_sha1" {
+		t.Errorf("issues = %s", got)
+	}
+}
+
+func TestAnalyzeToken_Opaque(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"dXNlcj1hZG1pbjtyb2xlPWFkbWlu", "encoded_data"},
+		{"4f9a21c7", "low_entropy"},
+		{"9fK2xQ7mWp4LzR8vT1nB6cY3hJ5dG0sA", ""},
+	}
+	for _, tt := range tests {
+		report, err := analyzeToken(context.Background(), tt.token, builtinSecrets)
+		if err != nil {
+			t.Fatalf("%s: analyzeToken() error: %v", tt.token, err)
+		}
+		if report.Kind != "opaque" {
+			t.Errorf("%s: Kind = %s", tt.token, report.Kind)
+		}
+		if got := strings.Join(issueChecks(report), ","); got != tt.want {
+			t.Errorf("%s: issues = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package tools
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Truncation strategies for oversized tool output.
+const (
+	TruncateHead       = "head"       // Keep the beginning
+	TruncateHeadTail   = "head_tail"  // Keep the beginning and the (larger) end
+	TruncateStructured = "structured" // Sample whole JSON/JSONL/XML records
+	TruncateSections   = "sections"   // Give each section a share of the budget
+)
+
+const defaultTruncateMaxChars = 10000
+
+// headTailHeadShare is the fraction of the budget kept from the start of the
+// output under head_tail; the rest goes to the end, where scanners print
+// their summaries.
+const headTailHeadShare = 0.3
+
+// Truncator cuts oversized tool output down to a character budget using a
+// strategy chosen per tool.
+type Truncator struct {
+	defaults config.TruncationRule
+	tools    map[string]config.TruncationRule
+}
+
+// NewTruncator creates a truncator from tools.truncation. Unknown strategies
+// fall back to head_tail.
+func NewTruncator(cfg config.TruncationConfig) *Truncator {
+	t := &Truncator{
+		defaults: config.TruncationRule{Strategy: cfg.Strategy, MaxChars: cfg.MaxChars},
+		tools:    cfg.Tools,
+	}
+	if !validTruncateStrategy(t.defaults.Strategy) {
+		if t.defaults.Strategy != "" {
+			logger.WarnCF("tool", "Unknown truncation strategy, using head_tail",
+				map[string]any{"strategy": t.defaults.Strategy})
+		}
+		t.defaults.Strategy = TruncateHeadTail
+	}
+	if t.defaults.MaxChars <= 0 {
+		t.defaults.MaxChars = defaultTruncateMaxChars
+	}
+	for name, rule := range cfg.Tools {
+		if rule.Strategy != "" && !validTruncateStrategy(rule.Strategy) {
+			logger.WarnCF("tool", "Unknown truncation strategy, using default",
+				map[string]any{"tool": name, "strategy": rule.Strategy})
+		}
+	}
+	return t
+}
+
+func validTruncateStrategy(strategy string) bool {
+	switch strategy {
+	case TruncateHead, TruncateHeadTail, TruncateStructured, TruncateSections:
+		return true
+	}
+	return false
+}
+
+// Rule returns the strategy and budget for the first of names with a
+// configured rule, or the defaults. A nil Truncator uses head_tail.
+func (t *Truncator) Rule(names ...string) config.TruncationRule {
+	if t == nil {
+		return config.TruncationRule{Strategy: TruncateHeadTail, MaxChars: defaultTruncateMaxChars}
+	}
+	rule := t.defaults
+	for _, name := range names {
+		override, ok := t.tools[name]
+		if !ok {
+			continue
+		}
+		if validTruncateStrategy(override.Strategy) {
+			rule.Strategy = override.Strategy
+		}
+		if override.MaxChars > 0 {
+			rule.MaxChars = override.MaxChars
+		}
+		break
+	}
+	return rule
+}
+
+// Truncate cuts output to the budget of the first of names with a configured
+// rule (e.g. the exec'd command, then "exec"). Output within budget is
+// returned unchanged.
+func (t *Truncator) Truncate(output string, names ...string) string {
+	rule := t.Rule(names...)
+	if len(output) <= rule.MaxChars {
+		return output
+	}
+	return TruncateOutput(output, rule.Strategy, rule.MaxChars)
+}
+
+// TruncateOutput cuts output to roughly maxChars with the given strategy.
+// Structure-aware strategies fall back to head_tail when the output has no
+// structure they recognise.
+func TruncateOutput(output, strategy string, maxChars int) string {
+	if len(output) <= maxChars {
+		return output
+	}
+	switch strategy {
+	case TruncateHead:
+		head := cutHead(output, maxChars)
+		return head + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-len(head))
+	case TruncateStructured:
+		if out, ok := truncateStructured(output, maxChars); ok {
+			return out
+		}
+	case TruncateSections:
+		if out, ok := truncateSections(output, maxChars); ok {
+			return out
+		}
+	}
+	return truncateHeadTail(output, maxChars)
+}
+
+// truncateHeadTail keeps the start and the end of output, cut on line
+// boundaries where possible.
+func truncateHeadTail(output string, maxChars int) string {
+	head := cutHead(output, int(float64(maxChars)*headTailHeadShare))
+	tail := cutTail(output, maxChars-len(head))
+	omitted := len(output) - len(head) - len(tail)
+	return head + fmt.Sprintf("\n... (%d chars omitted) ...\n", omitted) + tail
+}
+
+// cutHead returns a prefix of s of at most n bytes, ending at a line break
+// if one falls in the second half of the prefix.
+func cutHead(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	if i := strings.LastIndexByte(s[:n], '\n'); i >= n/2 {
+		return s[:i]
+	}
+	return s[:n]
+}
+
+// cutTail returns a suffix of s of at most n bytes, starting after a line
+// break if one falls in the first half of the suffix.
+func cutTail(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	if i := strings.IndexByte(s[start:], '\n'); i >= 0 && i <= n/2 {
+		return s[start+i+1:]
+	}
+	return s[start:]
+}
+
+// truncateStructured samples whole records from JSON, JSON lines or XML
+// output, keeping the first and last records of each long list.
+func truncateStructured(output string, maxChars int) (string, bool) {
+	trimmed := strings.TrimSpace(output)
+	switch {
+	case strings.HasPrefix(trimmed, "<"):
+		return truncateXML(trimmed, maxChars)
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		if json.Valid([]byte(trimmed)) {
+			return truncateJSON(trimmed, maxChars)
+		}
+		return truncateJSONLines(trimmed, maxChars)
+	}
+	return "", false
+}
+
+// truncateJSON shrinks every array to its first and last keep elements,
+// lowering keep until the re-encoded document fits.
+func truncateJSON(doc string, maxChars int) (string, bool) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", false
+	}
+	for keep := 16; keep >= 1; keep /= 2 {
+		var out strings.Builder
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sampleJSON(v, keep, maxChars/8)); err != nil {
+			return "", false
+		}
+		if out.Len() <= maxChars {
+			return strings.TrimSuffix(out.String(), "\n"), true
+		}
+	}
+	return "", false
+}
+
+func sampleJSON(v any, keep, maxString int) any {
+	switch val := v.(type) {
+	case []any:
+		if len(val) <= 2*keep+1 {
+			out := make([]any, len(val))
+			for i, item := range val {
+				out[i] = sampleJSON(item, keep, maxString)
+			}
+			return out
+		}
+		out := make([]any, 0, 2*keep+1)
+		for _, item := range val[:keep] {
+			out = append(out, sampleJSON(item, keep, maxString))
+		}
+		out = append(out, fmt.Sprintf("... (%d items omitted) ...", len(val)-2*keep))
+		for _, item := range val[len(val)-keep:] {
+			out = append(out, sampleJSON(item, keep, maxString))
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = sampleJSON(item, keep, maxString)
+		}
+		return out
+	case string:
+		if len(val) > maxString {
+			return truncateHeadTail(val, maxString)
+		}
+	}
+	return v
+}
+
+// truncateJSONLines keeps the first and last records of JSON lines output
+// (nuclei -jsonl, ffuf, httpx -json), never splitting a record.
+func truncateJSONLines(output string, maxChars int) (string, bool) {
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" && !json.Valid([]byte(line)) {
+			return "", false
+		}
+	}
+	return sampleRecords(lines, "", "", "\n", maxChars, func(n int) string {
+		return fmt.Sprintf("... (%d records omitted) ...", n)
+	})
+}
+
+// truncateXML keeps the document's prolog, root element and first and last
+// children of the root (e.g. nmap's scan info, first and last <host>, and
+// <runstats>).
+func truncateXML(doc string, maxChars int) (string, bool) {
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	dec.Strict = false
+
+	var children []string
+	depth := 0
+	childStart, bodyStart, bodyEnd := int64(-1), int64(-1), int64(-1)
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				bodyStart = dec.InputOffset()
+			} else if depth == 2 {
+				childStart = offset
+			}
+		case xml.EndElement:
+			if depth == 2 && childStart >= 0 {
+				children = append(children, strings.TrimSpace(doc[childStart:dec.InputOffset()]))
+				childStart = -1
+			}
+			if depth == 1 {
+				bodyEnd = offset
+			}
+			depth--
+		}
+	}
+	if bodyStart < 0 || bodyEnd < bodyStart || len(children) < 3 {
+		return "", false
+	}
+
+	return sampleRecords(children, doc[:bodyStart]+"\n", "\n"+doc[bodyEnd:], "\n", maxChars, func(n int) string {
+		return fmt.Sprintf("<!-- %d elements omitted -->", n)
+	})
+}
+
+// sampleRecords joins the first and last keep records between prefix and
+// suffix, with an omission marker in between, lowering keep until the
+// result fits in maxChars.
+func sampleRecords(records []string, prefix, suffix, sep string, maxChars int, marker func(omitted int) string) (string, bool) {
+	nonEmpty := records[:0:0]
+	for _, r := range records {
+		if strings.TrimSpace(r) != "" {
+			nonEmpty = append(nonEmpty, r)
+		}
+	}
+	records = nonEmpty
+
+	for keep := len(records) / 2; keep >= 1; keep-- {
+		if 2*keep >= len(records) {
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(prefix)
+		b.WriteString(strings.Join(records[:keep], sep))
+		b.WriteString(sep + marker(len(records)-2*keep) + sep)
+		b.WriteString(strings.Join(records[len(records)-keep:], sep))
+		b.WriteString(suffix)
+		if b.Len() <= maxChars {
+			return b.String(), true
+		}
+		// Skip ahead when far over budget instead of shrinking one at a time
+		if over := b.Len() / maxChars; over > 1 {
+			keep = keep/over + 1
+		}
+	}
+	return "", false
+}
+
+// truncateSections splits output into blank-line separated sections and
+// shares the budget between them, so a long section (a verbose scan log)
+// cannot crowd out the ones after it (the summary).
+func truncateSections(output string, maxChars int) (string, bool) {
+	sections := splitSections(output)
+	if len(sections) < 2 {
+		return "", false
+	}
+
+	// Water-fill: short sections keep everything, long ones split the rest
+	budget := maxChars - 2*len(sections)
+	long := make([]bool, len(sections))
+	for i := range long {
+		long[i] = true
+	}
+	share := 0
+	for {
+		n := 0
+		for _, isLong := range long {
+			if isLong {
+				n++
+			}
+		}
+		if n == 0 {
+			break
+		}
+		share = budget / n
+		settled := false
+		for i, s := range sections {
+			if long[i] && len(s) <= share {
+				long[i] = false
+				budget -= len(s)
+				settled = true
+			}
+		}
+		if !settled {
+			break
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if long[i] {
+			s = truncateSection(s, share)
+		}
+		b.WriteString(s)
+	}
+	return b.String(), true
+}
+
+// truncateSection keeps a section's heading line and the head and tail of
+// its body.
+func truncateSection(section string, limit int) string {
+	heading, body, found := strings.Cut(section, "\n")
+	if !found || len(heading) >= limit {
+		return truncateHeadTail(section, limit)
+	}
+	return heading + "\n" + truncateHeadTail(body, limit-len(heading)-1)
+}
+
+func splitSections(output string) []string {
+	var sections []string
+	for _, s := range strings.Split(output, "\n\n") {
+		if s = strings.Trim(s, "\n"); s != "" {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// commandName returns the program an exec command runs, skipping sudo,
+// env and leading VAR=value assignments ("sudo nmap -sV x" -> "nmap").
+func commandName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if field == "sudo" || field == "env" || field == "time" || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
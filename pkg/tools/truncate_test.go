@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+func TestTruncateOutput_HeadTailKeepsSummary(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&sb, "progress line %d\n", i)
+	}
+	sb.WriteString("SUMMARY: 3 critical findings")
+
+	out := TruncateOutput(sb.String(), TruncateHeadTail, 1000)
+	if len(out) > 1100 {
+		t.Errorf("output is %d chars, want about 1000", len(out))
+	}
+	if !strings.HasPrefix(out, "progress line 0\n") || !strings.HasSuffix(out, "SUMMARY: 3 critical findings") {
+		t.Errorf("head_tail lost the head or the tail:\n%s", out)
+	}
+	if !strings.Contains(out, "chars omitted") {
+		t.Error("missing omission marker")
+	}
+
+	head := TruncateOutput(sb.String(), TruncateHead, 1000)
+	if strings.Contains(head, "SUMMARY") || !strings.Contains(head, "truncated") {
+		t.Errorf("head strategy kept the tail:\n%s", head[len(head)-100:])
+	}
+}
+
+func TestTruncateOutput_Structured(t *testing.T) {
+	t.Run("nmap xml", func(t *testing.T) {
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0"?>` + "\n<nmaprun scanner=\"nmap\">\n<scaninfo type=\"syn\"/>\n")
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&sb, "<host><address addr=\"10.0.0.%d\"/><ports><port portid=\"80\"/></ports></host>\n", i)
+		}
+		sb.WriteString("<runstats><hosts up=\"200\"/></runstats>\n</nmaprun>\n")
+
+		out := TruncateOutput(sb.String(), TruncateStructured, 2000)
+		if len(out) > 2000 {
+			t.Errorf("output is %d chars, want at most 2000", len(out))
+		}
+		for _, want := range []string{"<nmaprun", "<scaninfo", "10.0.0.1\"", "<runstats>", "</nmaprun>", "elements omitted"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("missing %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("json document", func(t *testing.T) {
+		items := make([]map[string]any, 500)
+		for i := range items {
+			items[i] = map[string]any{"url": fmt.Sprintf("https://example.com/%d?a=<b>", i), "status": 200}
+		}
+		doc, _ := json.Marshal(map[string]any{"target": "example.com", "results": items})
+
+		out := TruncateOutput(string(doc), TruncateStructured, 3000)
+		if !json.Valid([]byte(out)) {
+			t.Fatalf("sampled JSON is invalid:\n%s", out)
+		}
+		for _, want := range []string{`"target": "example.com"`, "example.com/0?a=<b>", "example.com/499", "items omitted"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("missing %q", want)
+			}
+		}
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 300; i++ {
+			lines = append(lines, fmt.Sprintf(`{"template-id":"t-%d","severity":"info"}`, i))
+		}
+		out := TruncateOutput(strings.Join(lines, "\n"), TruncateStructured, 1000)
+		for _, line := range strings.Split(out, "\n") {
+			if !json.Valid([]byte(line)) && !strings.Contains(line, "records omitted") {
+				t.Errorf("record was split: %q", line)
+			}
+		}
+		if !strings.Contains(out, `"t-299"`) {
+			t.Error("last record dropped")
+		}
+	})
+
+	t.Run("plain text falls back to head_tail", func(t *testing.T) {
+		out := TruncateOutput(strings.Repeat("x\n", 1000)+"done", TruncateStructured, 200)
+		if !strings.HasSuffix(out, "done") {
+			t.Errorf("fallback lost the tail: %q", out)
+		}
+	})
+}
+
+func TestTruncateOutput_Sections(t *testing.T) {
+	output := "Starting scan\n" + strings.Repeat("verbose line\n", 500) +
+		"\nOpen ports:\n22/tcp open ssh\n80/tcp open http\n" +
+		"\nResults:\n" + strings.Repeat("finding\n", 100)
+
+	out := TruncateOutput(output, TruncateSections, 800)
+	if len(out) > 900 {
+		t.Errorf("output is %d chars, want about 800", len(out))
+	}
+	for _, want := range []string{"Starting scan", "Open ports:\n22/tcp open ssh\n80/tcp open http", "Results:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestTruncator_PerToolRules(t *testing.T) {
+	tr := NewTruncator(config.TruncationConfig{
+		MaxChars: 500,
+		Tools: map[string]config.TruncationRule{
+			"nmap": {Strategy: TruncateStructured, MaxChars: 2000},
+			"exec": {Strategy: TruncateHead},
+		},
+	})
+
+	tests := []struct {
+		names        []string
+		wantStrategy string
+		wantMax      int
+	}{
+		{[]string{"nmap", "exec"}, TruncateStructured, 2000},
+		{[]string{"ffuf", "exec"}, TruncateHead, 500},
+		{[]string{"web_fetch"}, TruncateHeadTail, 500},
+	}
+	for _, tt := range tests {
+		rule := tr.Rule(tt.names...)
+		if rule.Strategy != tt.wantStrategy || rule.MaxChars != tt.wantMax {
+			t.Errorf("Rule(%v) = %+v, want %s/%d", tt.names, rule, tt.wantStrategy, tt.wantMax)
+		}
+	}
+
+	if got := tr.Truncate("short", "nmap"); got != "short" {
+		t.Errorf("output within budget changed: %q", got)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := map[string]string{
+		"nmap -sV 10.0.0.1":                  "nmap",
+		"sudo /usr/bin/nmap -sS x":           "nmap",
+		"NUCLEI_TOKEN=x nuclei -u https://x": "nuclei",
+		"":                                   "",
+	}
+	for command, want := range tests {
+		if got := commandName(command); got != want {
+			t.Errorf("commandName(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"regexp"
 	"strings"
@@ -540,6 +542,11 @@ func (t *WebFetchTool) Parameters() map[string]any {
 				"description": "Maximum characters to extract",
 				"minimum":     100.0,
 			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Extra request headers. Reference session variables as {{NAME}}, e.g. {\"Authorization\": \"Bearer {{AUTH_TOKEN}}\"}",
+			},
 		},
 		"required": []string{"url"},
 	}
@@ -550,6 +557,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	if !ok {
 		return ErrorResult("url is required")
 	}
+	urlStr = ExpandSessionVars(ctx, urlStr)
 
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
@@ -577,6 +585,13 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 
 	req.Header.Set("User-Agent", userAgent)
+	if headers, ok := args["headers"].(map[string]any); ok {
+		for name, value := range headers {
+			if v, ok := value.(string); ok {
+				req.Header.Set(name, ExpandSessionVars(ctx, v))
+			}
+		}
+	}
 
 	client, err := createHTTPClient(t.proxy, 60*time.Second)
 	if err != nil {
@@ -679,3 +694,26 @@ func (t *WebFetchTool) extractText(htmlContent string) string {
 
 	return strings.Join(cleanLines, "\n")
 }
+
+// SelfTest fetches a page from a loopback server, through the proxy if one
+// is configured
+func (t *WebFetchTool) SelfTest(ctx context.Context, _ SelfTestOptions) (string, error) {
+	const marker = "picoclaw self-test page"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><body><p>%s</p></body></html>", marker)
+	}))
+	defer server.Close()
+
+	result := t.Execute(ctx, map[string]any{"url": server.URL})
+	if result.IsError {
+		return "", errors.New(result.ForLLM)
+	}
+	if !strings.Contains(result.ForUser, marker) {
+		return "", fmt.Errorf("page text not extracted: %s", result.ForLLM)
+	}
+	if t.proxy != "" {
+		return "fetched a loopback page through " + t.proxy, nil
+	}
+	return "fetched a loopback page", nil
+}
@@ -2,8 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/bus"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
 )
 
@@ -69,7 +76,7 @@ func (t *WorkflowCreateBranchTool) Name() string {
 }
 
 func (t *WorkflowCreateBranchTool) Description() string {
-	return "Create a new investigation branch when you discover something that requires deeper exploration (e.g., found web service, discovered vulnerability, etc.)"
+	return "Create a new investigation branch when you discover something that requires deeper exploration (e.g., found web service, discovered vulnerability, etc.). Workflow branches that continue in another phase move the mission there until the branch is completed."
 }
 
 func (t *WorkflowCreateBranchTool) Parameters() map[string]any {
@@ -105,11 +112,16 @@ func (t *WorkflowCreateBranchTool) Execute(ctx context.Context, args map[string]
 		return NewToolResult("Missing or invalid description parameter")
 	}
 
+	before := engine.CurrentPhaseName()
 	if err := engine.CreateBranch(condition, description); err != nil {
 		return NewToolResult(fmt.Sprintf("Failed to create branch: %v", err))
 	}
 
-	return NewToolResult(fmt.Sprintf("Created branch: %s - %s", condition, description))
+	result := fmt.Sprintf("Created branch: %s - %s", condition, description)
+	if after := engine.CurrentPhaseName(); after != before {
+		result += fmt.Sprintf("\nNow in phase %s. Complete the branch to return to %s.", after, before)
+	}
+	return NewToolResult(result)
 }
 
 // WorkflowCompleteBranchTool allows marking branches as complete
@@ -126,7 +138,7 @@ func (t *WorkflowCompleteBranchTool) Name() string {
 }
 
 func (t *WorkflowCompleteBranchTool) Description() string {
-	return "Mark an investigation branch as complete when you have finished exploring it."
+	return "Mark an investigation branch as complete when you have finished exploring it. If the branch moved the mission to another phase, the mission returns to the phase it left."
 }
 
 func (t *WorkflowCompleteBranchTool) Parameters() map[string]any {
@@ -153,28 +165,40 @@ func (t *WorkflowCompleteBranchTool) Execute(ctx context.Context, args map[strin
 		return NewToolResult("Missing or invalid condition parameter")
 	}
 
+	before := engine.CurrentPhaseName()
 	if err := engine.CompleteBranch(condition); err != nil {
 		return NewToolResult(fmt.Sprintf("Failed to complete branch: %v", err))
 	}
 
-	return NewToolResult(fmt.Sprintf("Branch '%s' marked complete", condition))
+	result := fmt.Sprintf("Branch '%s' marked complete", condition)
+	if after := engine.CurrentPhaseName(); after != before {
+		result += fmt.Sprintf("\nReturned to phase: %s", after)
+	}
+	return NewToolResult(result)
 }
 
 // WorkflowAddFindingTool allows recording findings
 type WorkflowAddFindingTool struct {
 	getEngine func() *workflow.Engine
+	branch    string // Delegated branch the findings are held in until it completes
 }
 
 func NewWorkflowAddFindingTool(getEngine func() *workflow.Engine) *WorkflowAddFindingTool {
 	return &WorkflowAddFindingTool{getEngine: getEngine}
 }
 
+// NewWorkflowBranchFindingTool records findings for a sub-agent investigating
+// a delegated branch. They join the mission when the branch completes.
+func NewWorkflowBranchFindingTool(getEngine func() *workflow.Engine, branch string) *WorkflowAddFindingTool {
+	return &WorkflowAddFindingTool{getEngine: getEngine, branch: branch}
+}
+
 func (t *WorkflowAddFindingTool) Name() string {
 	return "workflow_add_finding"
 }
 
 func (t *WorkflowAddFindingTool) Description() string {
-	return "Record a security finding or discovery in the mission report. Use this when you find vulnerabilities, misconfigurations, or other notable security issues."
+	return "Record a security finding or discovery in the mission report. Use this when you find vulnerabilities, misconfigurations, or other notable security issues. Give a CVSS vector where one applies: it is scored automatically, and findings are ranked by CVSS score."
 }
 
 func (t *WorkflowAddFindingTool) Parameters() map[string]any {
@@ -191,15 +215,45 @@ func (t *WorkflowAddFindingTool) Parameters() map[string]any {
 			},
 			"severity": map[string]any{
 				"type":        "string",
-				"description": "Severity level: critical, high, medium, low, or info",
+				"description": "Severity level: critical, high, medium, low, or info. Optional with a CVSS vector or score, which rates the finding.",
 				"enum":        []string{"critical", "high", "medium", "low", "info"},
 			},
 			"evidence": map[string]any{
 				"type":        "string",
-				"description": "Evidence or proof (tool output, logs, etc.)",
+				"description": "Evidence or proof (tool output, logs, recording paths, etc.)",
+			},
+			"redaction": map[string]any{
+				"type":        "string",
+				"description": "What the client report shows: full (default), partial (withhold evidence, evidence artifacts and metadata, e.g. working exploits or credentials; title, description and remediation are still shown, so keep secrets out of them), or internal (leave the finding out)",
+				"enum":        []string{"full", "partial", "internal"},
+			},
+			"cvss_vector": map[string]any{
+				"type":        "string",
+				"description": "CVSS base vector, e.g. CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H. CVSS 3.x vectors are scored automatically; CVSS 4.0 vectors need cvss_score as well.",
+			},
+			"cvss_score": map[string]any{
+				"type":        "number",
+				"description": "CVSS base score, 0.0 to 10.0, when there is no CVSS 3.x vector",
+			},
+			"cwe": map[string]any{
+				"type":        "string",
+				"description": "CWE weakness ID, e.g. CWE-79",
+			},
+			"asset": map[string]any{
+				"type":        "string",
+				"description": "Affected asset: host, URL, endpoint or file (defaults to the mission target)",
+			},
+			"remediation": map[string]any{
+				"type":        "string",
+				"description": "How to fix it: patch version, configuration change or code fix",
+			},
+			"references": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Advisory URLs, CVE IDs or vendor guidance",
 			},
 		},
-		"required": []string{"title", "description", "severity", "evidence"},
+		"required": []string{"title", "description", "evidence"},
 	}
 }
 
@@ -219,19 +273,16 @@ func (t *WorkflowAddFindingTool) Execute(ctx context.Context, args map[string]an
 		return NewToolResult("Missing or invalid description parameter")
 	}
 
-	severityStr, ok := args["severity"].(string)
-	if !ok {
-		return NewToolResult("Missing or invalid severity parameter")
-	}
-
 	evidence, ok := args["evidence"].(string)
 	if !ok {
 		return NewToolResult("Missing or invalid evidence parameter")
 	}
 
-	// Convert severity string to enum
+	// Convert severity string to enum; without one, the CVSS score rates it
+	severityStr, _ := args["severity"].(string)
 	var severity workflow.Severity
 	switch severityStr {
+	case "":
 	case "critical":
 		severity = workflow.SeverityCritical
 	case "high":
@@ -246,22 +297,76 @@ func (t *WorkflowAddFindingTool) Execute(ctx context.Context, args map[string]an
 		return NewToolResult(fmt.Sprintf("Invalid severity: %s", severityStr))
 	}
 
-	if err := engine.AddFinding(title, description, severity, evidence); err != nil {
+	redactionStr, _ := args["redaction"].(string)
+	redaction, err := workflow.ParseRedactionLevel(redactionStr)
+	if err != nil {
+		return NewToolResult(err.Error())
+	}
+
+	cweStr, _ := args["cwe"].(string)
+	cwe, err := workflow.NormalizeCWE(cweStr)
+	if err != nil {
+		return NewToolResult(err.Error())
+	}
+
+	cvssScore, _ := args["cvss_score"].(float64)
+	cvssVector, _ := args["cvss_vector"].(string)
+	asset, _ := args["asset"].(string)
+	remediation, _ := args["remediation"].(string)
+
+	var references []string
+	if refs, ok := args["references"].([]any); ok {
+		for _, ref := range refs {
+			if s, ok := ref.(string); ok && strings.TrimSpace(s) != "" {
+				references = append(references, strings.TrimSpace(s))
+			}
+		}
+	}
+
+	finding := workflow.Finding{
+		Title:       title,
+		Description: description,
+		Severity:    severity,
+		Evidence:    evidence,
+		Redaction:   redaction,
+		CWE:         cwe,
+		CVSSScore:   cvssScore,
+		CVSSVector:  cvssVector,
+		Asset:       strings.TrimSpace(asset),
+		Remediation: strings.TrimSpace(remediation),
+		References:  references,
+	}
+	if t.branch != "" {
+		finding, err = engine.RecordBranchFinding(t.branch, finding)
+	} else {
+		finding, err = engine.RecordFinding(finding)
+	}
+	if err != nil {
 		return NewToolResult(fmt.Sprintf("Failed to add finding: %v", err))
 	}
 
-	return NewToolResult(fmt.Sprintf("Added %s finding: %s", severityStr, title))
+	if finding.CVSSScore > 0 {
+		return NewToolResult(fmt.Sprintf("Added %s finding (CVSS %.1f): %s", finding.Severity, finding.CVSSScore, title))
+	}
+	return NewToolResult(fmt.Sprintf("Added %s finding: %s", finding.Severity, title))
 }
 
 // WorkflowAdvancePhaseTool allows advancing to the next phase
 type WorkflowAdvancePhaseTool struct {
 	getEngine func() *workflow.Engine
+	evaluator *workflow.CompletionEvaluator // Judges custom completion criteria; nil leaves them unmet
 }
 
 func NewWorkflowAdvancePhaseTool(getEngine func() *workflow.Engine) *WorkflowAdvancePhaseTool {
 	return &WorkflowAdvancePhaseTool{getEngine: getEngine}
 }
 
+// SetCompletionEvaluator lets the tool leave phases with custom completion
+// criteria once a model judges them met
+func (t *WorkflowAdvancePhaseTool) SetCompletionEvaluator(evaluator *workflow.CompletionEvaluator) {
+	t.evaluator = evaluator
+}
+
 func (t *WorkflowAdvancePhaseTool) Name() string {
 	return "workflow_advance_phase"
 }
@@ -283,23 +388,812 @@ func (t *WorkflowAdvancePhaseTool) Execute(ctx context.Context, args map[string]
 		return NewToolResult("No active mission/workflow")
 	}
 
-	// Check if phase is complete
+	// Check if phase is complete; custom criteria need the evaluator's verdict
 	if !engine.IsPhaseComplete() {
 		wf := engine.GetWorkflow()
 		state := engine.GetState()
 		if state.CurrentPhase < len(wf.Phases) {
 			phase := wf.Phases[state.CurrentPhase]
-			return NewToolResult(fmt.Sprintf("Phase '%s' completion criteria not yet met. Review the phase steps and completion requirements.", phase.Name))
+			var verdict *workflow.CompletionVerdict
+			if t.evaluator != nil {
+				var err error
+				if verdict, err = t.evaluator.Evaluate(ctx, engine); err != nil {
+					return NewToolResult(fmt.Sprintf("Phase '%s' completion could not be judged: %v", phase.Name, err))
+				}
+			}
+			switch {
+			case verdict == nil:
+				return NewToolResult(fmt.Sprintf("Phase '%s' completion criteria not yet met. Review the phase steps and completion requirements.", phase.Name))
+			case !verdict.Accepted:
+				return NewToolResult(fmt.Sprintf("Phase '%s' completion criteria not yet met (judged complete: %t, confidence %.2f): %s",
+					phase.Name, verdict.Complete, verdict.Confidence, verdict.Reason))
+			}
 		}
 	}
 
+	returning := len(engine.GetState().PhaseStack) > 0
 	if err := engine.AdvancePhase(); err != nil {
 		return NewToolResult(fmt.Sprintf("Failed to advance phase: %v", err))
 	}
 
-	wf := engine.GetWorkflow()
-	state := engine.GetState()
-	newPhaseName := wf.Phases[state.CurrentPhase].Name
+	if returning {
+		return NewToolResult(fmt.Sprintf("Branch complete. Returned to phase: %s", engine.CurrentPhaseName()))
+	}
+	return NewToolResult(fmt.Sprintf("Advanced to phase: %s", engine.CurrentPhaseName()))
+}
+
+// WorkflowSetAliasTool maintains the mission's target alias map
+type WorkflowSetAliasTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowSetAliasTool(getEngine func() *workflow.Engine) *WorkflowSetAliasTool {
+	return &WorkflowSetAliasTool{getEngine: getEngine}
+}
+
+func (t *WorkflowSetAliasTool) Name() string {
+	return "workflow_set_alias"
+}
+
+func (t *WorkflowSetAliasTool) Description() string {
+	return "Map a human-readable name (e.g., 'the admin portal') to an exact target (e.g., 'https://admin.example.com') so similar hosts are never mixed up. Omit target to remove an alias."
+}
+
+func (t *WorkflowSetAliasTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"alias": map[string]any{
+				"type":        "string",
+				"description": "The name used in conversation (e.g., 'the admin portal', 'staging db')",
+			},
+			"target": map[string]any{
+				"type":        "string",
+				"description": "The exact URL, hostname or IP the alias refers to; omit to remove the alias",
+			},
+		},
+		"required": []string{"alias"},
+	}
+}
+
+func (t *WorkflowSetAliasTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
 
-	return NewToolResult(fmt.Sprintf("Advanced to phase: %s", newPhaseName))
+	alias, ok := args["alias"].(string)
+	if !ok || alias == "" {
+		return NewToolResult("Missing or invalid alias parameter")
+	}
+
+	target, _ := args["target"].(string)
+	if target == "" {
+		if err := engine.RemoveAlias(alias); err != nil {
+			return NewToolResult(fmt.Sprintf("Failed to remove alias: %v", err))
+		}
+		return NewToolResult(fmt.Sprintf("Removed alias: %s", alias))
+	}
+
+	if err := engine.SetAlias(alias, target); err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to set alias: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Alias set: %s → %s", alias, target))
+}
+
+// WorkflowSetVariableTool records a mission variable that tool arguments
+// reference and phase and branch conditions test
+type WorkflowSetVariableTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowSetVariableTool(getEngine func() *workflow.Engine) *WorkflowSetVariableTool {
+	return &WorkflowSetVariableTool{getEngine: getEngine}
+}
+
+func (t *WorkflowSetVariableTool) Name() string {
+	return "workflow_set_variable"
+}
+
+func (t *WorkflowSetVariableTool) Description() string {
+	return "Record a mission variable, e.g. open_ports = [22, 80, 443] or http_service = true. Tool arguments can reference it as {{name}}, and conditional phases and branches test it, so record what you discover that the workflow depends on."
+}
+
+func (t *WorkflowSetVariableTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Variable name (letters, digits and underscores; dots for nesting, e.g. creds_found or web.login_url)",
+			},
+			"value": map[string]any{
+				"type":        "string",
+				"description": "The value. JSON numbers, true/false and arrays such as [80, 443] are stored as such; anything else as text",
+			},
+		},
+		"required": []string{"name", "value"},
+	}
+}
+
+func (t *WorkflowSetVariableTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewToolResult("Missing or invalid name parameter")
+	}
+	raw, ok := args["value"].(string)
+	if !ok {
+		return NewToolResult("Missing or invalid value parameter")
+	}
+
+	var value any = raw
+	var decoded any
+	if err := json.Unmarshal([]byte(raw), &decoded); err == nil && decoded != nil {
+		value = decoded
+	}
+
+	phase := engine.CurrentPhaseName()
+	if err := engine.SetVariable(name, value); err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to set variable: %v", err))
+	}
+
+	msg := fmt.Sprintf("Variable set: %s. Reference it as {{%s}}.", name, name)
+	if now := engine.CurrentPhaseName(); now != phase {
+		msg += fmt.Sprintf(" A branch it activated moved the mission to phase %s.", now)
+	}
+	return NewToolResult(msg)
+}
+
+// WorkflowAddNoteTool records an observation in the mission journal
+type WorkflowAddNoteTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowAddNoteTool(getEngine func() *workflow.Engine) *WorkflowAddNoteTool {
+	return &WorkflowAddNoteTool{getEngine: getEngine}
+}
+
+func (t *WorkflowAddNoteTool) Name() string {
+	return "workflow_add_note"
+}
+
+func (t *WorkflowAddNoteTool) Description() string {
+	return "Add a note to the current phase and the mission journal: what you tried, why you dropped a lead, what a result means. Notes are kept with the mission and shown in the internal report's timeline, so the operator can follow your reasoning later."
+}
+
+func (t *WorkflowAddNoteTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The note, one or two sentences",
+			},
+		},
+		"required": []string{"text"},
+	}
+}
+
+func (t *WorkflowAddNoteTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	text, ok := args["text"].(string)
+	if !ok || text == "" {
+		return NewToolResult("Missing or invalid text parameter")
+	}
+
+	if err := engine.AddNote(text); err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to add note: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Note added to phase %s", engine.CurrentPhaseName()))
+}
+
+// WorkflowSetRedactionTool changes how much of a finding the client report shows
+type WorkflowSetRedactionTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowSetRedactionTool(getEngine func() *workflow.Engine) *WorkflowSetRedactionTool {
+	return &WorkflowSetRedactionTool{getEngine: getEngine}
+}
+
+func (t *WorkflowSetRedactionTool) Name() string {
+	return "workflow_set_redaction"
+}
+
+func (t *WorkflowSetRedactionTool) Description() string {
+	return "Change the redaction level of a recorded finding. The internal report always shows everything; the client report shows full findings as-is, partial findings without their evidence, evidence artifacts and metadata (the title, description and remediation are still shown), and omits internal findings."
+}
+
+func (t *WorkflowSetRedactionTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"finding": map[string]any{
+				"type":        "string",
+				"description": "The finding's ID or exact title",
+			},
+			"redaction": map[string]any{
+				"type":        "string",
+				"description": "Redaction level: full, partial, or internal",
+				"enum":        []string{"full", "partial", "internal"},
+			},
+		},
+		"required": []string{"finding", "redaction"},
+	}
+}
+
+func (t *WorkflowSetRedactionTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	finding, ok := args["finding"].(string)
+	if !ok || finding == "" {
+		return NewToolResult("Missing or invalid finding parameter")
+	}
+
+	redactionStr, ok := args["redaction"].(string)
+	if !ok {
+		return NewToolResult("Missing or invalid redaction parameter")
+	}
+	redaction, err := workflow.ParseRedactionLevel(redactionStr)
+	if err != nil {
+		return NewToolResult(err.Error())
+	}
+
+	if err := engine.SetFindingRedaction(finding, redaction); err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to set redaction: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Redaction for %s set to %s", finding, redaction))
+}
+
+// WorkflowAttachEvidenceTool stores a file, text or stored tool result as an
+// evidence artifact and attaches it to a finding
+type WorkflowAttachEvidenceTool struct {
+	getEngine func() *workflow.Engine
+	workspace string
+	restrict  bool
+	results   *ResultStore
+}
+
+func NewWorkflowAttachEvidenceTool(getEngine func() *workflow.Engine, workspace string, restrict bool, results *ResultStore) *WorkflowAttachEvidenceTool {
+	return &WorkflowAttachEvidenceTool{getEngine: getEngine, workspace: workspace, restrict: restrict, results: results}
+}
+
+func (t *WorkflowAttachEvidenceTool) Name() string {
+	return "workflow_attach_evidence"
+}
+
+func (t *WorkflowAttachEvidenceTool) Description() string {
+	return "Attach an evidence artifact to a recorded finding: a file in the workspace (screenshot, pcap, saved response), the full output of an earlier tool call by result ID, or text. The artifact is copied into the mission's evidence store under its SHA-256 hash, and reports link it, with screenshots shown inline. Give exactly one of path, result_id or content."
+}
+
+func (t *WorkflowAttachEvidenceTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"finding": map[string]any{
+				"type":        "string",
+				"description": "The finding's ID or exact title",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File to attach",
+			},
+			"result_id": map[string]any{
+				"type":        "string",
+				"description": "Stored tool result to attach, e.g. r12, or 'last'",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "Text to attach, such as a request and response",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "File name for result_id or content, e.g. login-response.txt",
+			},
+			"kind": map[string]any{
+				"type":        "string",
+				"description": "Artifact kind (guessed from the file name when omitted)",
+				"enum":        []string{"file", "screenshot", "pcap", "tool_output"},
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "What the artifact shows",
+			},
+		},
+		"required": []string{"finding"},
+	}
+}
+
+func (t *WorkflowAttachEvidenceTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	finding, _ := args["finding"].(string)
+	if finding == "" {
+		return NewToolResult("Missing or invalid finding parameter")
+	}
+	if _, ok := engine.FindFinding(finding); !ok {
+		return NewToolResult(fmt.Sprintf("Failed to attach evidence: finding %q not found", finding))
+	}
+	kindStr, _ := args["kind"].(string)
+	kind, err := workflow.ParseEvidenceKind(kindStr)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	path, _ := args["path"].(string)
+	resultID, _ := args["result_id"].(string)
+	content, _ := args["content"].(string)
+	name, _ := args["name"].(string)
+
+	sources := 0
+	for _, source := range []string{path, resultID, content} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return ErrorResult("give exactly one of path, result_id or content")
+	}
+
+	var artifact workflow.EvidenceArtifact
+	switch {
+	case path != "":
+		resolved, err := validatePath(path, t.workspace, t.restrict)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		artifact, err = workflow.StoreEvidenceFile(t.workspace, resolved, kind)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+	case resultID != "":
+		if t.results == nil {
+			return ErrorResult("no stored tool results")
+		}
+		result, err := t.results.Load(resultID)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		if name == "" {
+			name = result.ID + "_" + result.Tool + ".txt"
+		}
+		if kind == "" {
+			kind = workflow.EvidenceToolOutput
+		}
+		artifact, err = workflow.StoreEvidence(t.workspace, name, strings.NewReader(result.Output), kind)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+	default:
+		if name == "" {
+			name = "evidence.txt"
+		}
+		artifact, err = workflow.StoreEvidence(t.workspace, name, strings.NewReader(content), kind)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+	artifact.Description, _ = args["description"].(string)
+
+	f, err := engine.AttachEvidence(finding, artifact)
+	if err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to attach evidence: %v", err))
+	}
+	return NewToolResult(fmt.Sprintf("Attached %s (%s, %d bytes, sha256 %s) to %q; %d artifact(s) on this finding",
+		artifact.Path, artifact.Kind, artifact.Size, artifact.SHA256[:12], f.Title, len(workflow.FindingEvidence(f))))
+}
+
+// WorkflowGenerateReportTool writes the internal and client mission reports
+type WorkflowGenerateReportTool struct {
+	getEngine func() *workflow.Engine
+}
+
+func NewWorkflowGenerateReportTool(getEngine func() *workflow.Engine) *WorkflowGenerateReportTool {
+	return &WorkflowGenerateReportTool{getEngine: getEngine}
+}
+
+func (t *WorkflowGenerateReportTool) Name() string {
+	return "workflow_generate_report"
+}
+
+func (t *WorkflowGenerateReportTool) Description() string {
+	return "Generate the mission report from the current findings, timeline, phases and cost: an internal version with all evidence and a client-facing version that honors each finding's redaction level. Written as Markdown by default, or as HTML or PDF."
+}
+
+func (t *WorkflowGenerateReportTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"format": map[string]any{
+				"type":        "string",
+				"enum":        []string{"markdown", "html", "pdf"},
+				"description": "Report file format (default markdown). PDF needs wkhtmltopdf, Chrome or Chromium installed.",
+			},
+		},
+	}
+}
+
+func (t *WorkflowGenerateReportTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	format, _ := args["format"].(string)
+	reportFormat, err := workflow.ParseReportFormat(format)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	internalPath, clientPath, err := engine.GenerateReport(reportFormat)
+	if err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to generate reports: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Reports written:\n- Internal: %s\n- Client: %s", internalPath, clientPath))
+}
+
+// WorkflowDraftReportTool drafts a prose mission report through the report
+// pipeline, routing each section to a model tier suited to it
+type WorkflowDraftReportTool struct {
+	getEngine func() *workflow.Engine
+	draft     workflow.ReportDrafter
+}
+
+func NewWorkflowDraftReportTool(getEngine func() *workflow.Engine, draft workflow.ReportDrafter) *WorkflowDraftReportTool {
+	return &WorkflowDraftReportTool{getEngine: getEngine, draft: draft}
+}
+
+func (t *WorkflowDraftReportTool) Name() string {
+	return "workflow_draft_report"
+}
+
+func (t *WorkflowDraftReportTool) Description() string {
+	return "Draft the final written report from the mission findings. Findings are written up in chunks by a mid-tier model while the executive summary and risk narrative are written by the strongest model. Client drafts honor each finding's redaction level."
+}
+
+func (t *WorkflowDraftReportTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"audience": map[string]any{
+				"type":        "string",
+				"description": "Who the report is for: client (default, redacted) or internal (all evidence)",
+				"enum":        []string{"client", "internal"},
+			},
+		},
+	}
+}
+
+func (t *WorkflowDraftReportTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	audience := workflow.ReportClient
+	if a, _ := args["audience"].(string); a != "" {
+		switch workflow.ReportAudience(a) {
+		case workflow.ReportClient, workflow.ReportInternal:
+			audience = workflow.ReportAudience(a)
+		default:
+			return NewToolResult(fmt.Sprintf("Invalid audience: %s", a))
+		}
+	}
+
+	path, err := engine.DraftReport(ctx, workflow.NewReportPipeline(t.draft), audience)
+	if err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to draft report: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Drafted %s report: %s", audience, path))
+}
+
+// WorkflowTranslateReportTool translates the mission report into the
+// client's language through the report pipeline
+type WorkflowTranslateReportTool struct {
+	getEngine func() *workflow.Engine
+	draft     workflow.ReportDrafter
+}
+
+func NewWorkflowTranslateReportTool(getEngine func() *workflow.Engine, draft workflow.ReportDrafter) *WorkflowTranslateReportTool {
+	return &WorkflowTranslateReportTool{getEngine: getEngine, draft: draft}
+}
+
+func (t *WorkflowTranslateReportTool) Name() string {
+	return "workflow_translate_report"
+}
+
+func (t *WorkflowTranslateReportTool) Description() string {
+	return "Write the mission report in the client's language. Labels, severity names and dates are localized; finding text is translated by the strongest model under supervision. Client versions honor each finding's redaction level."
+}
+
+func (t *WorkflowTranslateReportTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"language": map[string]any{
+				"type":        "string",
+				"description": "Language code to translate into (default: the mission's report language)",
+				"enum":        workflow.ReportLanguages(),
+			},
+			"audience": map[string]any{
+				"type":        "string",
+				"description": "Who the report is for: client (default, redacted) or internal (all evidence)",
+				"enum":        []string{"client", "internal"},
+			},
+		},
+	}
+}
+
+func (t *WorkflowTranslateReportTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+
+	audience := workflow.ReportClient
+	if a, _ := args["audience"].(string); a != "" {
+		switch workflow.ReportAudience(a) {
+		case workflow.ReportClient, workflow.ReportInternal:
+			audience = workflow.ReportAudience(a)
+		default:
+			return NewToolResult(fmt.Sprintf("Invalid audience: %s", a))
+		}
+	}
+	language, _ := args["language"].(string)
+
+	path, err := engine.TranslateReport(ctx, workflow.NewReportPipeline(t.draft), audience, language)
+	if err != nil {
+		return NewToolResult(fmt.Sprintf("Failed to translate report: %v", err))
+	}
+
+	return NewToolResult(fmt.Sprintf("Translated %s report: %s", audience, path))
+}
+
+const (
+	defaultMaxParallelBranches = 3
+	defaultBranchTimeout       = 30 * time.Minute
+)
+
+// BranchRun is what a sub-agent reports after investigating a branch
+type BranchRun struct {
+	Summary string  // Its final answer
+	CostUSD float64 // Model spend in its session
+	Tokens  int     // Model tokens in its session
+}
+
+// BranchAgent investigates one delegated branch in its own session, starting
+// from brief, and records its findings for the branch as it goes
+type BranchAgent func(ctx context.Context, condition, session, brief string) (BranchRun, error)
+
+// WorkflowInvestigateBranchTool hands a branch to a sub-agent with its own
+// context and cost sub-account, so several branches (e.g. SMB and HTTP) are
+// investigated in parallel with the main agent. When a sub-agent finishes,
+// its branch completes, its findings and spend join the mission, and the
+// agent is told on the system channel. Sub-agents outlive the turn that
+// started them; each is stopped by its timeout or by StopBranches.
+type WorkflowInvestigateBranchTool struct {
+	getEngine     func() *workflow.Engine
+	run           BranchAgent
+	bus           *bus.MessageBus
+	maxParallel   int
+	timeout       time.Duration
+	originChannel string
+	originChatID  string
+	callback      AsyncCallback
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc // By condition
+}
+
+func NewWorkflowInvestigateBranchTool(
+	getEngine func() *workflow.Engine,
+	run BranchAgent,
+	msgBus *bus.MessageBus,
+	maxParallel int,
+	timeout time.Duration,
+) *WorkflowInvestigateBranchTool {
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallelBranches
+	}
+	if timeout <= 0 {
+		timeout = defaultBranchTimeout
+	}
+	return &WorkflowInvestigateBranchTool{
+		getEngine:     getEngine,
+		run:           run,
+		bus:           msgBus,
+		maxParallel:   maxParallel,
+		timeout:       timeout,
+		originChannel: "cli",
+		originChatID:  "direct",
+		running:       make(map[string]context.CancelFunc),
+	}
+}
+
+// StopBranches cancels every running sub-agent, e.g. when the mission is
+// unloaded. Each still merges what it recorded and reports as stopped.
+func (t *WorkflowInvestigateBranchTool) StopBranches() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, cancel := range t.running {
+		cancel()
+	}
+	return len(t.running)
+}
+
+// SetCallback implements AsyncTool interface for async completion notification
+func (t *WorkflowInvestigateBranchTool) SetCallback(cb AsyncCallback) {
+	t.callback = cb
+}
+
+func (t *WorkflowInvestigateBranchTool) SetContext(channel, chatID string) {
+	t.originChannel = channel
+	t.originChatID = chatID
+}
+
+func (t *WorkflowInvestigateBranchTool) Name() string {
+	return "workflow_investigate_branch"
+}
+
+func (t *WorkflowInvestigateBranchTool) Description() string {
+	return fmt.Sprintf("Investigate a branch in parallel with a sub-agent that has its own context and budget, "+
+		"e.g. SMB and HTTP at the same time. The branch is created if needed. The sub-agent's findings join the "+
+		"mission when it finishes, and its summary arrives as a system message; continue with other work meanwhile. "+
+		"At most %d branches run at once, each for up to %s. Branches that continue in another phase can't run in parallel.",
+		t.maxParallel, t.timeout)
+}
+
+func (t *WorkflowInvestigateBranchTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"condition": map[string]any{
+				"type":        "string",
+				"description": "Branch condition, e.g. 'smb_open' or 'http_found'",
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "What the sub-agent should investigate (required for a new branch)",
+			},
+		},
+		"required": []string{"condition"},
+	}
+}
+
+func (t *WorkflowInvestigateBranchTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	engine := t.getEngine()
+	if engine == nil {
+		return NewToolResult("No active mission/workflow")
+	}
+	if t.run == nil {
+		return ErrorResult("Branch sub-agents not configured")
+	}
+
+	condition, _ := args["condition"].(string)
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return NewToolResult("Missing or invalid condition parameter")
+	}
+	description, _ := args["description"].(string)
+
+	t.mu.Lock()
+	if _, ok := t.running[condition]; ok {
+		t.mu.Unlock()
+		return NewToolResult(fmt.Sprintf("Branch '%s' is already being investigated", condition))
+	}
+	if len(t.running) >= t.maxParallel {
+		t.mu.Unlock()
+		return NewToolResult(fmt.Sprintf("%d branches are already running; wait for one to finish", len(t.running)))
+	}
+	// The sub-agent outlives this turn, so it mustn't share its deadline
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), t.timeout)
+	t.running[condition] = cancel
+	t.mu.Unlock()
+
+	session := "branch:" + condition
+	brief, err := t.delegate(engine, condition, description, session)
+	if err != nil {
+		t.release(condition)
+		return NewToolResult(fmt.Sprintf("Failed to delegate branch: %v", err))
+	}
+
+	go t.investigate(runCtx, engine, condition, session, brief,
+		t.originChannel, t.originChatID, t.callback)
+
+	return AsyncResult(fmt.Sprintf(
+		"Sub-agent investigating branch '%s' in parallel. Its findings join the mission when it finishes; "+
+			"continue with other work meanwhile.", condition))
+}
+
+func (t *WorkflowInvestigateBranchTool) delegate(engine *workflow.Engine, condition, description, session string) (string, error) {
+	if err := engine.DelegateBranch(condition, description, session); err != nil {
+		return "", err
+	}
+	return engine.BranchBrief(condition)
+}
+
+func (t *WorkflowInvestigateBranchTool) release(condition string) {
+	t.mu.Lock()
+	if cancel, ok := t.running[condition]; ok {
+		cancel()
+		delete(t.running, condition)
+	}
+	t.mu.Unlock()
+}
+
+// investigate runs the sub-agent, merges what it found into the mission and
+// announces the result to the agent that delegated the branch
+func (t *WorkflowInvestigateBranchTool) investigate(
+	ctx context.Context,
+	engine *workflow.Engine,
+	condition, session, brief string,
+	originChannel, originChatID string,
+	callback AsyncCallback,
+) {
+	defer t.release(condition)
+
+	run, err := t.run(ctx, condition, session, brief)
+	status, summary := "completed", run.Summary
+	if err != nil {
+		// Whatever it recorded before failing still counts
+		status, summary = "failed", fmt.Sprintf("Error: %v", err)
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			status = fmt.Sprintf("timed out after %s", t.timeout)
+		case errors.Is(ctx.Err(), context.Canceled):
+			status = "stopped"
+		}
+		logger.WarnCF("tool", "Branch sub-agent failed", map[string]any{"branch": condition, "error": err.Error()})
+	}
+
+	merged, mergeErr := engine.FinishBranch(condition, summary, run.CostUSD, run.Tokens)
+	if mergeErr != nil {
+		logger.WarnCF("tool", "Branch merge failed", map[string]any{"branch": condition, "error": mergeErr.Error()})
+	}
+	logger.InfoCF("tool", "Branch sub-agent finished", map[string]any{
+		"branch":   condition,
+		"status":   status,
+		"findings": merged,
+		"cost_usd": run.CostUSD,
+	})
+
+	content := fmt.Sprintf("Branch '%s' %s: %d findings merged into the mission ($%.2f, %d tokens).\n\nSummary:\n%s",
+		condition, status, merged, run.CostUSD, run.Tokens, summary)
+	result := &ToolResult{ForLLM: content, ForUser: content}
+	if err != nil {
+		result = ErrorResult(content).WithError(err)
+	}
+
+	if callback != nil {
+		// ctx may be the one that stopped the sub-agent
+		callback(context.WithoutCancel(ctx), result)
+	}
+	if t.bus != nil {
+		t.bus.PublishInbound(bus.InboundMessage{
+			Channel:  "system",
+			SenderID: session,
+			// Format: "original_channel:original_chat_id" for routing back
+			ChatID:  fmt.Sprintf("%s:%s", originChannel, originChatID),
+			Content: content,
+		})
+	}
 }
@@ -0,0 +1,1214 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/workflow"
+)
+
+const jumpWorkflow = `---
+name: web-assessment
+phases: [recon, webapp, auth, report]
+---
+
+## Phase: recon
+
+### Steps
+
+- ports: Scan ports (required)
+- vhosts: Enumerate virtual hosts (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Branches
+
+- web_found → Test the web application
+  phase: webapp
+
+## Phase: webapp
+
+### Steps
+
+- crawl: Crawl the app (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Branches
+
+- login_found → Test authentication
+  phase: auth
+
+## Phase: auth
+
+### Steps
+
+- bruteforce: Check for weak credentials
+
+### Completion Criteria
+
+All required steps complete
+
+## Phase: report
+
+### Steps
+
+- write: Write the report (required)
+
+### Completion Criteria
+
+All required steps complete
+`
+
+func TestWorkflowBranches_JumpAndReturn(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(jumpWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+
+	create := NewWorkflowCreateBranchTool(getEngine)
+	complete := NewWorkflowCompleteBranchTool(getEngine)
+	advance := NewWorkflowAdvancePhaseTool(getEngine)
+	step := NewWorkflowStepCompleteTool(getEngine)
+
+	step.Execute(ctx, map[string]any{"step_id": "ports"})
+
+	// recon -> webapp
+	result := create.Execute(ctx, map[string]any{"condition": "web_found", "description": "nginx on 443"})
+	if !strings.Contains(result.ForLLM, "Now in phase webapp. Complete the branch to return to recon.") {
+		t.Errorf("create result = %q", result.ForLLM)
+	}
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "investigates branch **web_found** from phase **recon**") {
+		t.Errorf("context prompt has no branch investigation:\n%s", prompt)
+	}
+
+	// webapp -> auth, nested
+	create.Execute(ctx, map[string]any{"condition": "login_found", "description": "/login"})
+	state := engine.GetState()
+	if state.CurrentPhase != 2 || len(state.PhaseStack) != 2 {
+		t.Fatalf("phase = %d, stack = %+v; want auth two levels deep", state.CurrentPhase, state.PhaseStack)
+	}
+
+	// Advancing the nested phase returns to webapp
+	result = advance.Execute(ctx, map[string]any{})
+	if result.ForLLM != "Branch complete. Returned to phase: webapp" {
+		t.Errorf("advance result = %q", result.ForLLM)
+	}
+
+	// Completing the outer branch resumes recon with its progress
+	result = complete.Execute(ctx, map[string]any{"condition": "web_found"})
+	if !strings.Contains(result.ForLLM, "Returned to phase: recon") {
+		t.Errorf("complete result = %q", result.ForLLM)
+	}
+	state = engine.GetState()
+	if state.CurrentPhase != 0 || len(state.PhaseStack) != 0 {
+		t.Fatalf("phase = %d, stack = %+v; want recon with an empty stack", state.CurrentPhase, state.PhaseStack)
+	}
+	current := state.PhaseHistory[len(state.PhaseHistory)-1]
+	if !reflect.DeepEqual(current.StepsComplete, []string{"ports"}) {
+		t.Errorf("resumed steps = %v, want [ports]", current.StepsComplete)
+	}
+	for _, branch := range state.ActiveBranches {
+		if branch.CompletedAt == nil {
+			t.Errorf("branch %s still open", branch.Condition)
+		}
+	}
+	var phases []string
+	for _, exec := range state.PhaseHistory {
+		phases = append(phases, exec.PhaseName)
+	}
+	if want := []string{"recon", "webapp", "auth", "webapp", "recon"}; !reflect.DeepEqual(phases, want) {
+		t.Errorf("phase history = %v, want %v", phases, want)
+	}
+
+	// Back in recon, advancing moves on normally
+	step.Execute(ctx, map[string]any{"step_id": "vhosts"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: webapp" {
+		t.Errorf("advance result = %q", result.ForLLM)
+	}
+}
+
+const conditionalWorkflow = `---
+name: external
+phases: [discovery, webapp, smb, report]
+---
+
+## Phase: discovery
+
+### Steps
+
+- scan: Scan {{target}} (required)
+
+### Completion Criteria
+
+All required steps complete
+
+## Phase: webapp
+
+when: ` + "`80 in vars.get(\"open_ports\", []) or 443 in vars.get(\"open_ports\", [])`" + `
+
+### Steps
+
+- crawl: Crawl port {{open_ports}} (required)
+
+### Completion Criteria
+
+All required steps complete
+
+## Phase: smb
+
+when: ` + "`445 in vars.get(\"open_ports\", [])`" + `
+
+### Steps
+
+- shares: List shares (required)
+
+### Completion Criteria
+
+All required steps complete
+
+## Phase: report
+
+### Steps
+
+- write: Write the report (required)
+
+### Completion Criteria
+
+All required steps complete
+`
+
+func TestWorkflowSetVariable_ConditionalPhases(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(conditionalWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if wf.Phases[1].When == "" || wf.Phases[3].When != "" {
+		t.Fatalf("when conditions = %q, %q", wf.Phases[1].When, wf.Phases[3].When)
+	}
+	engine := workflow.NewEngine(wf, "corp.example", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "- Scan corp.example (required)") ||
+		!strings.Contains(prompt, "### Conditional Phases") || !strings.Contains(prompt, "- **smb**: `445 in") {
+		t.Errorf("context prompt:\n%s", prompt)
+	}
+
+	result := NewWorkflowSetVariableTool(getEngine).Execute(ctx, map[string]any{"name": "open_ports", "value": "[22, 443]"})
+	if result.ForLLM != "Variable set: open_ports. Reference it as {{open_ports}}." {
+		t.Errorf("set result = %q", result.ForLLM)
+	}
+	if result := NewWorkflowSetVariableTool(getEngine).Execute(ctx, map[string]any{"name": "target", "value": "x"}); !strings.Contains(result.ForLLM, "set by the mission itself") {
+		t.Errorf("setting target = %q", result.ForLLM)
+	}
+	if vars := engine.TemplateVars(); vars["open_ports"] != "22,443" {
+		t.Errorf("{{open_ports}} = %q", vars["open_ports"])
+	}
+
+	// webapp applies, smb is skipped
+	advance := NewWorkflowAdvancePhaseTool(getEngine)
+	step := NewWorkflowStepCompleteTool(getEngine)
+	step.Execute(ctx, map[string]any{"step_id": "scan"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: webapp" {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "- Crawl port 22,443 (required)") {
+		t.Errorf("context prompt:\n%s", prompt)
+	}
+	step.Execute(ctx, map[string]any{"step_id": "crawl"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: report" {
+		t.Errorf("advance result = %q", result.ForLLM)
+	}
+	if got := engine.GetState().Variables["open_ports"]; !reflect.DeepEqual(got, []any{float64(22), float64(443)}) {
+		t.Errorf("stored open_ports = %#v", got)
+	}
+}
+
+const scriptedWorkflow = `---
+name: scripted
+phases: [recon, webapp]
+---
+
+## Phase: recon
+
+### Steps
+
+- ports: Scan ports (required)
+- vhosts: Enumerate virtual hosts
+
+### Completion Criteria
+
+A high finding and every required step done.
+script: ` + "`" + `len([f for f in findings if f.severity == "high"]) > 0 and all([s.completed for s in steps if s.required])` + "`" + `
+
+### Branches
+
+- web_found → Test the web application
+  when: ` + "`" + `vars.get("http_ports")` + "`" + `
+
+### Hooks
+
+- on_step_complete: ` + "`" + `note("step " + event.step)` + "`" + `
+- on_finding: ` + "`" + `if "HTTP" in event.title: set_variable("http_ports", [80, 443])` + "`" + `
+- on_branch: ` + "`" + `note("branch " + event.condition); create_branch("nested_" + event.condition)` + "`" + `
+
+## Phase: webapp
+
+### Steps
+
+- crawl: Crawl the app (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Hooks
+
+- on_phase_start: ` + "`" + `set_metadata("entered", {"phase": phase, "index": phase_index, "ratio": 0.5, "tags": ["web", None, True]})` + "`" + `
+`
+
+func TestWorkflowScripts_ConditionsAndHooks(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(scriptedWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if wf.Phases[0].Completion.Type != workflow.CompletionScript || len(wf.Phases[0].Hooks) != 3 {
+		t.Fatalf("recon = %+v", wf.Phases[0])
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+
+	// on_step_complete sees the step; the completion script still wants a high finding
+	NewWorkflowStepCompleteTool(getEngine).Execute(ctx, map[string]any{"step_id": "ports"})
+	if engine.IsPhaseComplete() {
+		t.Error("phase complete without a high finding")
+	}
+	if len(engine.GetState().ActiveBranches) != 0 {
+		t.Errorf("branch activated before its condition held: %+v", engine.GetState().ActiveBranches)
+	}
+
+	// on_finding sets the variable the branch condition waits for, and the
+	// branch's on_branch hook creates a second branch without re-running itself
+	if err := engine.AddFinding("HTTP service on 80", "nginx", workflow.SeverityHigh, ""); err != nil {
+		t.Fatalf("AddFinding: %v", err)
+	}
+	state := engine.GetState()
+	if got := state.Variables["http_ports"]; !reflect.DeepEqual(got, []any{int64(80), int64(443)}) {
+		t.Errorf("http_ports = %#v", got)
+	}
+	var branches []string
+	for _, b := range state.ActiveBranches {
+		branches = append(branches, b.Condition)
+	}
+	if !reflect.DeepEqual(branches, []string{"web_found", "nested_web_found"}) {
+		t.Errorf("branches = %v, want web_found and the one its hook created", branches)
+	}
+	if notes := state.PhaseHistory[0].Notes; !reflect.DeepEqual(notes, []string{"step ports", "branch web_found"}) {
+		t.Errorf("hook notes = %q", notes)
+	}
+	if !engine.IsPhaseComplete() {
+		t.Error("completion script false with a high finding and every required step done")
+	}
+
+	// on_phase_start stores metadata, which scripts read back
+	if result := NewWorkflowAdvancePhaseTool(getEngine).Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: webapp" {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	want := map[string]any{"phase": "webapp", "index": int64(1), "ratio": 0.5, "tags": []any{"web", nil, true}}
+	if got := engine.GetState().Metadata["entered"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("entered = %#v, want %#v", got, want)
+	}
+	ok, err := engine.EvalCondition(`metadata["entered"]["phase"] == phase and metadata["entered"]["index"] == 1 and metadata["entered"]["ratio"] == 0.5 and metadata["entered"]["tags"][2]`)
+	if err != nil || !ok {
+		t.Errorf("metadata round trip = %v, %v", ok, err)
+	}
+
+	// JSON numbers come back from the state file as float64; whole ones read as ints
+	if err := engine.SetMetadata("counts", map[string]any{"open": float64(3), "ratio": 0.25}); err != nil {
+		t.Fatalf("SetMetadata: %v", err)
+	}
+	if ok, err := engine.EvalCondition(`type(metadata["counts"]["open"]) == "int" and metadata["counts"]["ratio"] == 0.25`); err != nil || !ok {
+		t.Errorf("float metadata = %v, %v", ok, err)
+	}
+}
+
+func TestWorkflowScripts_Errors(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(scriptedWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+
+	// A runaway script is stopped rather than stalling the agent
+	_, err = engine.EvalCondition(`len([i for i in range(1000000)]) > 0`)
+	if err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Errorf("runaway script error = %v", err)
+	}
+	if _, err := engine.EvalCondition(`undefined_name`); err == nil {
+		t.Error("undefined name evaluated without error")
+	}
+
+	// Unparseable scripts are reported when the workflow loads
+	broken := strings.Replace(scriptedWorkflow, `note("step " + event.step)`, `note("step " +`, 1)
+	if _, err := workflow.NewParser().Parse(broken); err == nil || !strings.Contains(err.Error(), "invalid on_step_complete hook") {
+		t.Errorf("broken hook error = %v", err)
+	}
+}
+
+const dependencyWorkflow = `---
+name: web-dag
+phases: [recon]
+---
+
+## Phase: recon
+
+### Steps
+
+- ports: Scan ports (required)
+- services: Fingerprint services (required)
+  depends_on: ports
+- vhosts: Enumerate virtual hosts
+- exploit: Test known CVEs (required)
+  depends_on: services, vhosts
+
+### Completion Criteria
+
+All required steps complete
+`
+
+func TestWorkflowStepComplete_Dependencies(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(dependencyWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := wf.Phases[0].Steps[3].DependsOn; !reflect.DeepEqual(got, []string{"services", "vhosts"}) {
+		t.Fatalf("exploit depends_on = %v", got)
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	step := NewWorkflowStepCompleteTool(func() *workflow.Engine { return engine })
+	ctx := context.Background()
+
+	prompt := engine.GetContextPrompt()
+	if !strings.Contains(prompt, "### Next Action\n- Scan ports (required)") || !strings.Contains(prompt, "- vhosts: Enumerate virtual hosts") ||
+		!strings.Contains(prompt, "- Blocked steps: 2") || strings.Contains(prompt, "CVEs") {
+		t.Errorf("context prompt:\n%s", prompt)
+	}
+
+	if result := step.Execute(ctx, map[string]any{"step_id": "exploit"}); result.ForLLM != "Failed to mark step complete: step exploit is blocked: complete services, vhosts first" {
+		t.Errorf("blocked step result = %q", result.ForLLM)
+	}
+	for _, id := range []string{"ports", "services", "vhosts"} {
+		step.Execute(ctx, map[string]any{"step_id": id})
+	}
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "### Next Action\n- Test known CVEs (required)") || strings.Contains(prompt, "Blocked steps") {
+		t.Errorf("context prompt:\n%s", prompt)
+	}
+	if result := step.Execute(ctx, map[string]any{"step_id": "exploit"}); result.ForLLM != "Step 'exploit' marked complete" {
+		t.Errorf("unblocked step result = %q", result.ForLLM)
+	}
+
+	cyclic := strings.Replace(dependencyWorkflow, "- ports: Scan ports (required)", "- ports: Scan ports (required)\n  depends_on: exploit", 1)
+	if _, err := workflow.NewParser().Parse(cyclic); err == nil || !strings.Contains(err.Error(), "cycle: ports -> exploit -> services -> ports") {
+		t.Errorf("cyclic workflow error = %v", err)
+	}
+}
+
+func TestWorkflowAddNote_Journal(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(dependencyWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	note := NewWorkflowAddNoteTool(getEngine)
+	ctx := context.Background()
+
+	if result := note.Execute(ctx, map[string]any{"text": "Only 443 answers; skipping the UDP sweep"}); result.ForLLM != "Note added to phase recon" {
+		t.Errorf("note result = %q", result.ForLLM)
+	}
+	if result := note.Execute(ctx, map[string]any{}); result.ForLLM != "Missing or invalid text parameter" {
+		t.Errorf("missing text result = %q", result.ForLLM)
+	}
+	NewWorkflowStepCompleteTool(getEngine).Execute(ctx, map[string]any{"step_id": "ports"})
+	if err := engine.RecordToolCall(`exec {"command":"nmap -p- {{target}}"}`, true); err != nil {
+		t.Fatalf("RecordToolCall: %v", err)
+	}
+
+	state := engine.GetState()
+	if notes := state.PhaseHistory[0].Notes; len(notes) != 1 || notes[0] != "Only 443 answers; skipping the UDP sweep" {
+		t.Errorf("phase notes = %v", notes)
+	}
+	var events []string
+	for _, entry := range workflow.Journal(state, state.Findings, workflow.English) {
+		events = append(events, entry.Event+": "+entry.Details)
+	}
+	want := []string{
+		"Phase started: recon",
+		"Note: Only 443 answers; skipping the UDP sweep",
+		"Step completed: ports",
+		`Tool call failed: exec {"command":"nmap -p- {{target}}"}`,
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("journal = %q, want %q", events, want)
+	}
+
+	// Notes and tool calls are working material for the internal report only
+	if report := workflow.RenderReport(wf, state, workflow.ReportInternal); !strings.Contains(report, "| Note | Only 443 answers; skipping the UDP sweep |") {
+		t.Errorf("internal report lacks the note:\n%s", report)
+	}
+	if report := workflow.RenderReport(wf, state, workflow.ReportClient); strings.Contains(report, "UDP sweep") || strings.Contains(report, "nmap") {
+		t.Errorf("client report shows the journal:\n%s", report)
+	}
+}
+
+func TestWorkflowAddFinding_CVSS(t *testing.T) {
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{{Name: "testing"}}}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	add := NewWorkflowAddFindingTool(func() *workflow.Engine { return engine })
+	ctx := context.Background()
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"title": "SQL injection", "cvss_vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			"Added critical finding (CVSS 9.8): SQL injection"},
+		{map[string]any{"title": "Stored XSS", "cvss_vector": "CVSS:3.1/AV:N/AC:L/PR:L/UI:R/S:C/C:L/I:L/A:N"},
+			"Added medium finding (CVSS 5.4): Stored XSS"},
+		{map[string]any{"title": "Local privilege escalation", "cvss_vector": "CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H"},
+			"Added high finding (CVSS 7.8): Local privilege escalation"},
+		{map[string]any{"title": "Scope-changing RCE", "severity": "high", "cvss_vector": "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H"},
+			"Added high finding (CVSS 9.9): Scope-changing RCE"},
+		{map[string]any{"title": "Weak TLS", "cvss_score": 3.7, "cvss_vector": "CVSS:4.0/AV:N/AC:H/AT:N/PR:N/UI:N/VC:L/VI:N/VA:N/SC:N/SI:N/SA:N"},
+			"Added low finding (CVSS 3.7): Weak TLS"},
+		{map[string]any{"title": "Banner", "severity": "info"},
+			"Added informational finding: Banner"},
+		{map[string]any{"title": "No rating"},
+			"Failed to add finding: a finding needs a severity or a CVSS score"},
+		{map[string]any{"title": "Bad vector", "cvss_vector": "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			"Failed to add finding: CVSS vector is missing or has an invalid AV metric"},
+		{map[string]any{"title": "Unscored 4.0", "cvss_vector": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+			"Failed to add finding: CVSS 4.0 vectors can't be scored automatically; give the score too"},
+	}
+	for _, tt := range tests {
+		tt.args["description"] = "details"
+		tt.args["evidence"] = "proof"
+		if result := add.Execute(ctx, tt.args); result.ForLLM != tt.want {
+			t.Errorf("%s: result = %q, want %q", tt.args["title"], result.ForLLM, tt.want)
+		}
+	}
+
+	add.Execute(ctx, map[string]any{
+		"title": "Outdated jQuery", "description": "details", "evidence": "proof", "severity": "medium",
+		"cwe": "1104", "asset": "https://app.example.com/js/jquery.js", "remediation": "Upgrade to jQuery 3.7.",
+		"references": []any{"CVE-2020-11022", " "},
+	})
+	state := engine.GetState()
+	last := state.Findings[len(state.Findings)-1]
+	if last.CWE != "CWE-1104" || last.Asset != "https://app.example.com/js/jquery.js" ||
+		last.Remediation != "Upgrade to jQuery 3.7." || !reflect.DeepEqual(last.References, []string{"CVE-2020-11022"}) {
+		t.Errorf("enriched finding = %+v", last)
+	}
+
+	var order []string
+	for _, f := range workflow.ReportFindings(state, workflow.ReportInternal) {
+		order = append(order, f.Title)
+	}
+	want := []string{"Scope-changing RCE", "SQL injection", "Local privilege escalation", "Outdated jQuery", "Stored XSS", "Weak TLS", "Banner"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("report order = %v, want %v", order, want)
+	}
+
+	report := workflow.RenderReport(wf, state, workflow.ReportClient)
+	for _, want := range []string{
+		"- **CVSS**: 9.8 (`CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H`)\n",
+		"- **CWE**: CWE-1104\n- **Affected asset**: https://app.example.com/js/jquery.js\n",
+		"**Remediation**\n\nUpgrade to jQuery 3.7.\n\n**References**\n\n- CVE-2020-11022\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestRenderReport_RedactionLevels(t *testing.T) {
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{{Name: "testing"}}}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	artifact := func(name string) map[string]any {
+		return map[string]any{workflow.MetaEvidence: []workflow.EvidenceArtifact{{
+			SHA256: name, Name: name + ".png", Path: "evidence/" + name + ".png", Kind: workflow.EvidenceScreenshot, MediaType: "image/png",
+		}}}
+	}
+	for _, f := range []workflow.Finding{
+		{Title: "Open redirect", Severity: workflow.SeverityLow, Description: "full-description",
+			Evidence: "full-evidence", Metadata: artifact("full-artifact")},
+		{Title: "Default credentials", Severity: workflow.SeverityHigh, Description: "partial-description",
+			Evidence: "partial-evidence", Remediation: "partial-remediation", Redaction: workflow.RedactionPartial,
+			Metadata: map[string]any{workflow.MetaEvidence: artifact("partial-artifact")[workflow.MetaEvidence], "location": "partial-location"}},
+		{Title: "Internal only", Severity: workflow.SeverityCritical, Description: "internal-description",
+			Evidence: "internal-evidence", Redaction: workflow.RedactionInternal},
+	} {
+		if _, err := engine.RecordFinding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := engine.GetState()
+
+	internal := workflow.RenderReport(wf, state, workflow.ReportInternal)
+	for _, want := range []string{
+		"full-evidence", "full-artifact.png", "partial-evidence", "partial-artifact.png", "- **Redaction**: partial",
+		"Internal only", "internal-evidence", "- **Redaction**: internal",
+	} {
+		if !strings.Contains(internal, want) {
+			t.Errorf("internal report is missing %q:\n%s", want, internal)
+		}
+	}
+
+	client := workflow.RenderReport(wf, state, workflow.ReportClient)
+	for _, want := range []string{
+		// full: everything
+		"full-description", "full-evidence", "full-artifact.png",
+		// partial: the write-up without evidence
+		"### 1. Default credentials", "partial-description", "partial-remediation", "_Technical evidence withheld; available on request._",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client report is missing %q:\n%s", want, client)
+		}
+	}
+	for _, leaked := range []string{"partial-evidence", "partial-artifact", "Internal only", "internal-", "**Redaction**"} {
+		if strings.Contains(client, leaked) {
+			t.Errorf("client report shows %q:\n%s", leaked, client)
+		}
+	}
+
+	for _, f := range workflow.ReportFindings(state, workflow.ReportClient) {
+		if f.Redaction == workflow.RedactionPartial && f.Metadata != nil {
+			t.Errorf("partial finding keeps its metadata for clients: %v", f.Metadata)
+		}
+	}
+	if got := workflow.FindingEvidence(state.Findings[1]); len(got) != 1 || state.Findings[1].Metadata["location"] != "partial-location" {
+		t.Errorf("redacting the client view changed the stored finding: %+v", state.Findings[1])
+	}
+}
+
+func TestWorkflowAttachEvidence(t *testing.T) {
+	workspace := t.TempDir()
+	wf := &workflow.Workflow{Name: "web-assessment", Phases: []workflow.Phase{{Name: "testing"}}}
+	engine := workflow.NewEngine(wf, "app.example.com", workspace)
+	if err := engine.AddFinding("Default credentials", "admin/admin works.", workflow.SeverityHigh, "POST /login"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.RecordFinding(workflow.Finding{
+		Title: "Exposed .git", Severity: workflow.SeverityMedium, Redaction: workflow.RedactionPartial,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "login.png"), []byte("\x89PNG fake"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results := NewResultStore(filepath.Join(workspace, ResultsDir))
+	if _, err := results.Save("exec", "git-dumper output"); err != nil {
+		t.Fatal(err)
+	}
+	attach := NewWorkflowAttachEvidenceTool(func() *workflow.Engine { return engine }, workspace, true, results)
+	ctx := context.Background()
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"finding": "Default credentials", "path": "login.png", "description": "Admin dashboard"},
+			"Attached evidence/"},
+		{map[string]any{"finding": "Default credentials", "path": "login.png"},
+			"1 artifact(s) on this finding"},
+		{map[string]any{"finding": "Default credentials", "content": "HTTP/1.1 302 Found", "name": "login.http"},
+			"2 artifact(s) on this finding"},
+		{map[string]any{"finding": "Exposed .git", "result_id": "last"},
+			"(tool_output,"},
+		{map[string]any{"finding": "Default credentials"},
+			"give exactly one of path, result_id or content"},
+		{map[string]any{"finding": "Default credentials", "path": "login.png", "content": "x"},
+			"give exactly one of path, result_id or content"},
+		{map[string]any{"finding": "Default credentials", "path": "../outside.png"},
+			"outside"},
+		{map[string]any{"finding": "No such finding", "content": "x"},
+			`Failed to attach evidence: finding "No such finding" not found`},
+	}
+	for _, tt := range tests {
+		if result := attach.Execute(ctx, tt.args); !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("%v: result = %q, want it to contain %q", tt.args, result.ForLLM, tt.want)
+		}
+	}
+
+	// Artifacts survive a reload and are stored once, under their hash
+	loaded, err := workflow.LoadEngine(wf, workflow.MissionStatePath(workspace, "app.example.com"), workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts := workflow.FindingEvidence(loaded.GetState().Findings[0])
+	if len(artifacts) != 2 {
+		t.Fatalf("artifacts = %+v, want 2", artifacts)
+	}
+	png := artifacts[0]
+	if png.Kind != workflow.EvidenceScreenshot || !png.IsImage() || png.Description != "Admin dashboard" || png.Size != 9 {
+		t.Errorf("artifact = %+v", png)
+	}
+	if data, err := os.ReadFile(filepath.Join(workspace, png.Path)); err != nil || string(data) != "\x89PNG fake" {
+		t.Errorf("stored artifact = %q, %v", data, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(workspace, workflow.EvidenceDir, "*")); len(files) != 3 {
+		t.Errorf("evidence store holds %v, want 3 files", files)
+	}
+
+	internal := workflow.RenderReport(wf, loaded.GetState(), workflow.ReportInternal)
+	client := workflow.RenderReport(wf, loaded.GetState(), workflow.ReportClient)
+	if !strings.Contains(internal, "![login.png](../"+png.Path+")") || !strings.Contains(internal, "r1_exec.txt") {
+		t.Errorf("internal report doesn't show the artifacts:\n%s", internal)
+	}
+	if strings.Contains(client, "r1_exec.txt") {
+		t.Errorf("client report shows the artifacts of a partial finding:\n%s", client)
+	}
+}
+
+const triageWorkflow = `---
+name: web-service-triage
+phases: [fingerprint, content]
+tools:
+  deny: [sqlmap]
+---
+
+## Phase: fingerprint
+
+### Steps
+
+- headers: Grab headers from {{target}} (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Branches
+
+- no_content → Nothing served
+  phase: content
+
+## Phase: content
+
+### Steps
+
+- dirs: Brute-force directories (required)
+
+### Completion Criteria
+
+All required steps complete
+`
+
+const includingWorkflow = `---
+name: external
+phases: [recon, web, report]
+---
+
+## Phase: recon
+
+### Steps
+
+- ports: Scan ports (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Branches
+
+- web_found → Triage the web service
+  phase: web
+
+## Phase: web
+
+include: web-service-triage
+
+### Tools
+
+- deny: hydra
+
+## Phase: report
+
+### Steps
+
+- write: Write the report (required)
+
+### Completion Criteria
+
+All required steps complete
+`
+
+func TestWorkflowAdvancePhase_SubWorkflow(t *testing.T) {
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"web-service-triage": triageWorkflow, "external": includingWorkflow} {
+		if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wf, err := workflow.LoadWorkflow(workspace, "external")
+	if err != nil {
+		t.Fatalf("LoadWorkflow: %v", err)
+	}
+	var names []string
+	for _, phase := range wf.Phases {
+		names = append(names, phase.Name)
+	}
+	if want := []string{"recon", "web/fingerprint", "web/content", "report"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("phases = %v, want %v", names, want)
+	}
+	// Branch targets follow the phases they name
+	if got := wf.Phases[0].Branches[0].TargetPhase; got != "web/fingerprint" {
+		t.Errorf("recon branch target = %q", got)
+	}
+	if got := wf.Phases[1].Branches[0].TargetPhase; got != "web/content" {
+		t.Errorf("fingerprint branch target = %q", got)
+	}
+
+	engine := workflow.NewEngine(wf, "corp.example", workspace)
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+	step := NewWorkflowStepCompleteTool(getEngine)
+	advance := NewWorkflowAdvancePhaseTool(getEngine)
+
+	step.Execute(ctx, map[string]any{"step_id": "ports"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: web/fingerprint" {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	prompt := engine.GetContextPrompt()
+	for _, want := range []string{
+		"### Sub-workflow: web-service-triage",
+		"Phase **web** runs workflow web-service-triage: phase 1 of 2, 0 done.",
+		"- → fingerprint (current)\n- ○ content\n",
+		"- Grab headers from corp.example (required)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("context prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	// The included workflow's and the including phase's tool limits apply
+	if engine.CheckTool("sqlmap") == nil || engine.CheckTool("hydra") == nil || engine.CheckTool("exec") != nil {
+		t.Errorf("tool limits not inherited")
+	}
+
+	step.Execute(ctx, map[string]any{"step_id": "headers"})
+	advance.Execute(ctx, map[string]any{})
+	step.Execute(ctx, map[string]any{"step_id": "dirs"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: report" {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	if engine.CheckTool("sqlmap") != nil {
+		t.Errorf("sqlmap still denied after the sub-workflow")
+	}
+
+	subs := engine.GetState().SubWorkflows
+	if len(subs) != 1 || subs[0].Phase != "web" || subs[0].Workflow != "web-service-triage" || subs[0].EndTime == nil {
+		t.Errorf("sub-workflows = %+v", subs)
+	}
+}
+
+func TestResolveIncludes_Cycle(t *testing.T) {
+	loop := strings.Replace(includingWorkflow, "include: web-service-triage", "include: external", 1)
+	wf, err := workflow.NewParser().Parse(loop)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	err = workflow.ResolveIncludes(wf, func(name string) (*workflow.Workflow, error) {
+		return workflow.NewParser().Parse(loop)
+	})
+	if err == nil || !strings.Contains(err.Error(), "workflow external includes itself (external → external)") {
+		t.Errorf("ResolveIncludes() error = %v", err)
+	}
+
+	withSteps := strings.Replace(includingWorkflow, "include: web-service-triage", "include: web-service-triage\n\n### Steps\n\n- crawl: Crawl", 1)
+	if _, err := workflow.NewParser().Parse(withSteps); err == nil || !strings.Contains(err.Error(), "can't have steps") {
+		t.Errorf("Parse() error = %v", err)
+	}
+}
+
+const customWorkflow = `---
+name: ad-assessment
+phases: [foothold, report]
+---
+
+## Phase: foothold
+
+### Steps
+
+- spray: Spray seasonal passwords
+
+### Completion Criteria
+
+A domain user account on {{target}} is compromised
+
+## Phase: report
+
+### Steps
+
+- write: Write the report (required)
+
+### Completion Criteria
+
+All required steps complete
+`
+
+func TestWorkflowAdvancePhase_CompletionJudge(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(customWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	engine := workflow.NewEngine(wf, "corp.example", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+	advance := NewWorkflowAdvancePhaseTool(getEngine)
+	note := NewWorkflowAddNoteTool(getEngine)
+
+	// Without an evaluator custom criteria are never met
+	if result := advance.Execute(ctx, map[string]any{}); !strings.Contains(result.ForLLM, "completion criteria not yet met.") {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+
+	var prompts []string
+	answer := `{"complete": true, "confidence": 0.6, "reason": "Credentials are unverified"}`
+	advance.SetCompletionEvaluator(workflow.NewCompletionEvaluator(func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "Verdict:\n```json\n" + answer + "\n```", nil
+	}, 0))
+
+	want := "Phase 'foothold' completion criteria not yet met (judged complete: true, confidence 0.60): Credentials are unverified"
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != want {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "Completion criteria: A domain user account on corp.example is compromised") {
+		t.Fatalf("prompts = %q", prompts)
+	}
+	// Nothing changed, so the cached verdict stands
+	advance.Execute(ctx, map[string]any{})
+	if len(prompts) != 1 {
+		t.Errorf("judge asked %d times for one state", len(prompts))
+	}
+
+	answer = `{"complete": true, "confidence": 0.95, "reason": "jdoe's password was verified over LDAP"}`
+	note.Execute(ctx, map[string]any{"text": "jdoe:Autumn2026! works against LDAP"})
+	if result := advance.Execute(ctx, map[string]any{}); result.ForLLM != "Advanced to phase: report" {
+		t.Fatalf("advance result = %q", result.ForLLM)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[1], "- jdoe:Autumn2026! works against LDAP") {
+		t.Errorf("prompts = %q", prompts)
+	}
+
+	// Phases with other criteria never reach the judge
+	if result := advance.Execute(ctx, map[string]any{}); !strings.Contains(result.ForLLM, "completion criteria not yet met.") {
+		t.Errorf("advance result = %q", result.ForLLM)
+	}
+	if len(prompts) != 2 {
+		t.Errorf("judge asked about phase report")
+	}
+}
+
+func TestWorkflowInvestigateBranch_Parallel(t *testing.T) {
+	wf := &workflow.Workflow{Name: "network-pentest", Phases: []workflow.Phase{{
+		Name:     "enumeration",
+		Branches: []workflow.Branch{{Condition: "web_found", Description: "Test the web app", TargetPhase: "web"}},
+	}, {Name: "web"}}}
+	engine := workflow.NewEngine(wf, "10.0.0.5", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx := context.Background()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	var briefs sync.Map
+	run := func(ctx context.Context, condition, session, brief string) (BranchRun, error) {
+		briefs.Store(condition, brief)
+		add := NewWorkflowBranchFindingTool(getEngine, condition)
+		add.Execute(ctx, map[string]any{
+			"title": condition + " weakness", "description": "details", "evidence": "proof", "severity": "high",
+		})
+		started <- condition
+		<-release
+		return BranchRun{Summary: "Checked " + condition, CostUSD: 0.25, Tokens: 1000}, nil
+	}
+	investigate := NewWorkflowInvestigateBranchTool(getEngine, run, nil, 2, 0)
+	done := make(chan *ToolResult, 2)
+	investigate.SetCallback(func(ctx context.Context, result *ToolResult) { done <- result })
+
+	for _, condition := range []string{"smb_open", "http_open"} {
+		result := investigate.Execute(ctx, map[string]any{"condition": condition, "description": "Enumerate " + condition})
+		if !result.Async {
+			t.Fatalf("%s: result = %q, want async", condition, result.ForLLM)
+		}
+	}
+	<-started
+	<-started
+
+	if result := investigate.Execute(ctx, map[string]any{"condition": "ftp_open", "description": "Try anonymous FTP"}); result.ForLLM != "2 branches are already running; wait for one to finish" {
+		t.Errorf("third branch result = %q", result.ForLLM)
+	}
+	if result := investigate.Execute(ctx, map[string]any{"condition": "smb_open"}); result.ForLLM != "Branch 'smb_open' is already being investigated" {
+		t.Errorf("repeat result = %q", result.ForLLM)
+	}
+	if brief, _ := briefs.Load("smb_open"); !strings.Contains(brief.(string), "## Your Task\nEnumerate smb_open") {
+		t.Errorf("brief = %q", brief)
+	}
+
+	// Branch findings stay out of the mission until the branch completes
+	state := engine.GetState()
+	if len(state.Findings) != 0 {
+		t.Errorf("findings before merge = %+v", state.Findings)
+	}
+	for _, branch := range state.ActiveBranches {
+		if branch.Session != "branch:"+branch.Condition || len(branch.Findings) != 1 {
+			t.Errorf("running branch = %+v", branch)
+		}
+	}
+	if prompt := engine.GetContextPrompt(); !strings.Contains(prompt, "- **smb_open**: Enumerate smb_open - 🤖 Sub-agent investigating (1 findings so far)") {
+		t.Errorf("context prompt lacks the running branch:\n%s", prompt)
+	}
+
+	close(release)
+	for range 2 {
+		if result := <-done; !strings.Contains(result.ForLLM, "completed: 1 findings merged into the mission ($0.25, 1000 tokens)") {
+			t.Errorf("completion = %q", result.ForLLM)
+		}
+	}
+
+	state = engine.GetState()
+	var titles []string
+	for _, f := range state.Findings {
+		titles = append(titles, f.Title)
+	}
+	sort.Strings(titles)
+	if want := []string{"http_open weakness", "smb_open weakness"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("merged findings = %v, want %v", titles, want)
+	}
+	for _, branch := range state.ActiveBranches {
+		if branch.CompletedAt == nil || branch.Summary != "Checked "+branch.Condition || branch.CostUSD != 0.25 {
+			t.Errorf("finished branch = %+v", branch)
+		}
+	}
+	if total := workflow.TotalEffort(state.Effort()); total.Cost != 0.5 || total.Tokens != 2000 {
+		t.Errorf("mission spend = $%.2f, %d tokens; want $0.50, 2000 tokens", total.Cost, total.Tokens)
+	}
+
+	// Branches that jump to another phase run in the main agent
+	if result := investigate.Execute(ctx, map[string]any{"condition": "web_found"}); result.ForLLM != "Failed to delegate branch: branch web_found continues in phase web and can't run in parallel" {
+		t.Errorf("jumping branch result = %q", result.ForLLM)
+	}
+}
+
+func TestWorkflowInvestigateBranch_StopAndTimeout(t *testing.T) {
+	wf := &workflow.Workflow{Name: "network-pentest", Phases: []workflow.Phase{{Name: "enumeration"}}}
+	engine := workflow.NewEngine(wf, "10.0.0.5", t.TempDir())
+	getEngine := func() *workflow.Engine { return engine }
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The sub-agent runs until its context ends, like a model stuck in a loop
+	started := make(chan string, 1)
+	run := func(ctx context.Context, condition, session, brief string) (BranchRun, error) {
+		started <- condition
+		<-ctx.Done()
+		return BranchRun{Tokens: 10}, ctx.Err()
+	}
+	done := make(chan *ToolResult, 1)
+
+	timed := NewWorkflowInvestigateBranchTool(getEngine, run, nil, 2, 50*time.Millisecond)
+	timed.SetCallback(func(ctx context.Context, result *ToolResult) { done <- result })
+	timed.Execute(ctx, map[string]any{"condition": "smb_open", "description": "Enumerate shares"})
+	<-started
+	// Ending the turn that started it doesn't stop the sub-agent
+	cancel()
+	if result := <-done; !result.IsError || !strings.Contains(result.ForLLM, "Branch 'smb_open' timed out after 50ms") {
+		t.Errorf("timed out branch = %q", result.ForLLM)
+	}
+
+	stopped := NewWorkflowInvestigateBranchTool(getEngine, run, nil, 1, time.Hour)
+	stopped.SetCallback(func(ctx context.Context, result *ToolResult) { done <- result })
+	stopped.Execute(context.Background(), map[string]any{"condition": "http_open", "description": "Crawl the site"})
+	<-started
+	if n := stopped.StopBranches(); n != 1 {
+		t.Errorf("StopBranches() = %d, want 1", n)
+	}
+	if result := <-done; !strings.Contains(result.ForLLM, "Branch 'http_open' stopped") {
+		t.Errorf("stopped branch = %q", result.ForLLM)
+	}
+	for _, branch := range engine.GetState().ActiveBranches {
+		if branch.CompletedAt == nil || !strings.HasPrefix(branch.Summary, "Error: context") {
+			t.Errorf("branch not finished = %+v", branch)
+		}
+	}
+
+	// Stopped branches free their slots
+	if result := stopped.Execute(context.Background(), map[string]any{"condition": "ftp_open", "description": "Try anonymous FTP"}); !result.Async {
+		t.Errorf("rerun = %q", result.ForLLM)
+	}
+	<-started
+	stopped.StopBranches()
+	<-done
+}
+
+const fallbackWorkflow = `---
+name: degraded
+phases: [scan]
+---
+
+## Phase: scan
+
+### Steps
+
+- vulns: Run ` + "`nuclei -u {{target}}`" + ` against every live host (required)
+- shots: Screenshot the login pages
+  tools: gowitness
+- ports: Scan ports with nmap (required)
+
+### Completion Criteria
+
+All required steps complete
+
+### Tools
+
+- deny: curl
+`
+
+func TestWorkflowMissingTools_Fallbacks(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(fallbackWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := wf.Phases[0].Steps[1].Tools; !reflect.DeepEqual(got, []string{"gowitness"}) {
+		t.Fatalf("shots tools = %v", got)
+	}
+	engine := workflow.NewEngine(wf, "app.example.com", t.TempDir())
+	if prompt := engine.GetContextPrompt(); strings.Contains(prompt, "Missing Tools") {
+		t.Errorf("missing tools reported before fallbacks are set:\n%s", prompt)
+	}
+
+	installed := map[string]bool{"nmap": true, "curl": true}
+	engine.SetToolFallbacks(workflow.DefaultFallbacks().Merge(workflow.FallbackMatrix{
+		"gowitness": {{Tool: "curl"}},
+	}), func(name string) bool { return installed[name] })
+
+	missing := engine.MissingTools()
+	if len(missing) != 2 || missing[0].Tool != "gowitness" || missing[1].Tool != "nuclei" {
+		t.Fatalf("missing tools = %+v", missing)
+	}
+	// curl stands in for gowitness, but the workflow denies it
+	if missing[0].Fallback != nil || missing[1].Fallback == nil || missing[1].Fallback.Tool != "nmap" {
+		t.Errorf("fallbacks = %+v, %+v", missing[0].Fallback, missing[1].Fallback)
+	}
+	prompt := engine.GetContextPrompt()
+	if !strings.Contains(prompt, "### Missing Tools\n") ||
+		!strings.Contains(prompt, "- **nuclei** is not available. Use **nmap** instead: `nmap -sV --script vuln <target>` covers known CVEs") ||
+		!strings.Contains(prompt, "**gowitness** is not available and nothing stands in for it") ||
+		!strings.Contains(prompt, "(steps: vulns)") {
+		t.Errorf("context prompt:\n%s", prompt)
+	}
+
+	// Completed steps no longer need their tools
+	step := NewWorkflowStepCompleteTool(func() *workflow.Engine { return engine })
+	step.Execute(context.Background(), map[string]any{"step_id": "vulns"})
+	if missing := engine.MissingTools(); len(missing) != 1 || missing[0].Tool != "gowitness" {
+		t.Errorf("missing tools after vulns = %+v", missing)
+	}
+
+	if checked := engine.CheckTools("nmap", "NUCLEI", "nuclei"); len(checked) != 1 || checked[0].Fallback.Tool != "nmap" {
+		t.Errorf("checked tools = %+v", checked)
+	}
+}
+
+func TestWorkflowState_RecoversFromDamage(t *testing.T) {
+	wf, err := workflow.NewParser().Parse(dependencyWorkflow)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	workspace := t.TempDir()
+	engine := workflow.NewEngine(wf, "app.example.com", workspace)
+	for _, id := range []string{"ports", "services"} {
+		if err := engine.MarkStepComplete(id); err != nil {
+			t.Fatalf("MarkStepComplete(%s): %v", id, err)
+		}
+	}
+
+	statePath := workflow.MissionStatePath(workspace, "app.example.com")
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"checksum\": \"sha256:") {
+		t.Errorf("state file isn't sealed:\n%.80s", data)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(workspace, "missions", "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	stepsOf := func(state *workflow.MissionState) []string {
+		return state.PhaseHistory[len(state.PhaseHistory)-1].StepsComplete
+	}
+
+	// A write cut short, and a hand edit, both fall back to the last revision
+	for name, damaged := range map[string][]byte{
+		"truncated": data[:len(data)/2],
+		"edited":    []byte(strings.Replace(string(data), `"services"`, `"vhosts"`, 1)),
+	} {
+		if err := os.WriteFile(statePath, damaged, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		state, err := workflow.ReadMissionState(statePath)
+		if err != nil {
+			t.Fatalf("%s: ReadMissionState: %v", name, err)
+		}
+		if got := stepsOf(state); !reflect.DeepEqual(got, []string{"ports", "services"}) {
+			t.Errorf("%s: recovered steps = %v", name, got)
+		}
+	}
+
+	loaded, err := workflow.LoadEngine(wf, statePath, workspace)
+	if err != nil {
+		t.Fatalf("LoadEngine: %v", err)
+	}
+	if err := loaded.MarkStepComplete("vhosts"); err != nil {
+		t.Fatalf("MarkStepComplete(vhosts): %v", err)
+	}
+	state, err := workflow.ReadMissionState(statePath)
+	if err != nil || !reflect.DeepEqual(stepsOf(state), []string{"ports", "services", "vhosts"}) {
+		t.Errorf("state after saving the recovered mission = %v, %v", state, err)
+	}
+
+	// State files from before checksums still load
+	legacy := filepath.Join(t.TempDir(), "old_state.json")
+	if err := os.WriteFile(legacy, []byte(`{"workflow_name": "web-dag", "target": "old.example.com"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := workflow.ReadMissionState(legacy); err != nil || state.Target != "old.example.com" {
+		t.Errorf("legacy state = %v, %v", state, err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"workflow_name": "web-d`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workflow.ReadMissionState(legacy); err == nil || !strings.Contains(err.Error(), "no intact revision to recover from") {
+		t.Errorf("damaged state without revisions: err = %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package trust verifies signatures on workflows and skills fetched from
+// outside the workspace before the agent is allowed to act on them.
+package trust
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// SignatureExt is appended to an artifact's URL or path to find its
+// minisign signature, e.g. web.md -> web.md.minisig
+const SignatureExt = ".minisig"
+
+var (
+	algLegacy    = [2]byte{'E', 'd'} // Signature over the content itself
+	algPrehashed = [2]byte{'E', 'D'} // Signature over the content's BLAKE2b-512 hash
+)
+
+// PublicKey is a minisign Ed25519 public key
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign public key: either the base64 line alone
+// or the whole .pub file including its untrusted comment
+func ParsePublicKey(s string) (PublicKey, error) {
+	line := lastLine(s)
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize {
+		return PublicKey{}, fmt.Errorf("invalid minisign public key")
+	}
+	if [2]byte(raw[:2]) != algLegacy {
+		return PublicKey{}, fmt.Errorf("unsupported minisign key algorithm %q", raw[:2])
+	}
+	return PublicKey{ID: [8]byte(raw[2:10]), Key: ed25519.PublicKey(raw[10:])}, nil
+}
+
+// KeyID renders the key ID the way minisign prints it
+func (k PublicKey) KeyID() string {
+	var b strings.Builder
+	for i := len(k.ID) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "%02X", k.ID[i])
+	}
+	return b.String()
+}
+
+// Signature is a parsed .minisig file
+type Signature struct {
+	Algorithm      [2]byte
+	KeyID          [8]byte
+	Sig            []byte
+	TrustedComment string
+	GlobalSig      []byte
+}
+
+// ParseSignature parses the contents of a .minisig file
+func ParseSignature(data []byte) (Signature, error) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment:") ||
+		!strings.HasPrefix(lines[2], "trusted comment: ") {
+		return Signature{}, fmt.Errorf("invalid minisign signature file")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return Signature{}, fmt.Errorf("invalid minisign signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return Signature{}, fmt.Errorf("invalid minisign global signature")
+	}
+	sig := Signature{
+		Algorithm:      [2]byte(raw[:2]),
+		KeyID:          [8]byte(raw[2:10]),
+		Sig:            raw[10:],
+		TrustedComment: strings.TrimPrefix(lines[2], "trusted comment: "),
+		GlobalSig:      global,
+	}
+	if sig.Algorithm != algLegacy && sig.Algorithm != algPrehashed {
+		return Signature{}, fmt.Errorf("unsupported minisign signature algorithm %q", sig.Algorithm[:])
+	}
+	return sig, nil
+}
+
+// ErrKeyMismatch is returned when a signature was made by another key
+var ErrKeyMismatch = errors.New("signed by a different key")
+
+// Verify checks that sig is k's signature of content, including the
+// signature over the trusted comment
+func (k PublicKey) Verify(content []byte, sig Signature) error {
+	if sig.KeyID != k.ID {
+		return ErrKeyMismatch
+	}
+	message := content
+	if sig.Algorithm == algPrehashed {
+		sum := blake2b.Sum512(content)
+		message = sum[:]
+	}
+	if !ed25519.Verify(k.Key, message, sig.Sig) {
+		return fmt.Errorf("signature verification failed")
+	}
+	global := append(bytes.Clone(sig.Sig), sig.TrustedComment...)
+	if !ed25519.Verify(k.Key, global, sig.GlobalSig) {
+		return fmt.Errorf("trusted comment signature verification failed")
+	}
+	return nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
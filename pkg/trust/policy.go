@@ -0,0 +1,150 @@
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Kind is a class of artifact the trust policy covers
+type Kind string
+
+const (
+	KindWorkflow Kind = "workflows"
+	KindSkill    Kind = "skills"
+	KindPlugin   Kind = "plugins" // MCP tool server binaries
+)
+
+// Mode is how strictly signatures are enforced for a kind
+type Mode string
+
+const (
+	ModeOff     Mode = "off"     // Signatures are not checked
+	ModeWarn    Mode = "warn"    // Unsigned or untrusted artifacts are installed with a warning
+	ModeRequire Mode = "require" // Only artifacts signed by a trusted key are installed
+)
+
+var (
+	// ErrUnsigned is returned when an artifact has no signature
+	ErrUnsigned = errors.New("artifact is not signed")
+	// ErrUntrusted is returned when no trusted key verifies the signature
+	ErrUntrusted = errors.New("artifact is not signed by a trusted key")
+)
+
+type trustedKey struct {
+	name   string
+	key    PublicKey
+	scopes []Kind // Empty = every kind
+}
+
+// Policy decides which fetched workflows and skills may be installed, and
+// which MCP servers may be started, based on the trust section of the config
+type Policy struct {
+	keys  []trustedKey
+	modes map[Kind]Mode
+}
+
+// NewPolicy builds the policy from the config. A nil policy enforces nothing.
+func NewPolicy(cfg config.TrustConfig) (*Policy, error) {
+	p := &Policy{modes: make(map[Kind]Mode)}
+	for kind, mode := range map[Kind]string{KindWorkflow: cfg.Workflows, KindSkill: cfg.Skills, KindPlugin: cfg.Plugins} {
+		switch Mode(mode) {
+		case "", ModeOff:
+			p.modes[kind] = ModeOff
+		case ModeWarn, ModeRequire:
+			p.modes[kind] = Mode(mode)
+		default:
+			return nil, fmt.Errorf("trust.%s: unknown mode %q (expected off, warn or require)", kind, mode)
+		}
+	}
+	for i, k := range cfg.Keys {
+		key, err := ParsePublicKey(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("trust.keys[%d] (%s): %w", i, k.Name, err)
+		}
+		tk := trustedKey{name: k.Name, key: key}
+		if tk.name == "" {
+			tk.name = key.KeyID()
+		}
+		for _, scope := range k.Scopes {
+			if kind := Kind(scope); kind != KindWorkflow && kind != KindSkill && kind != KindPlugin {
+				return nil, fmt.Errorf("trust.keys[%d] (%s): unknown scope %q (expected workflows, skills or plugins)", i, tk.name, scope)
+			}
+			tk.scopes = append(tk.scopes, Kind(scope))
+		}
+		p.keys = append(p.keys, tk)
+	}
+	for kind, mode := range p.modes {
+		if mode == ModeRequire && !p.hasKey(kind) {
+			return nil, fmt.Errorf("trust.%s is require but no trusted key covers %s", kind, kind)
+		}
+	}
+	return p, nil
+}
+
+// Mode returns how signatures are enforced for kind
+func (p *Policy) Mode(kind Kind) Mode {
+	if p == nil || p.modes[kind] == "" {
+		return ModeOff
+	}
+	return p.modes[kind]
+}
+
+func (p *Policy) hasKey(kind Kind) bool {
+	for _, k := range p.keys {
+		if len(k.scopes) == 0 || slices.Contains(k.scopes, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check applies the policy to an artifact about to be installed. sig is the
+// raw .minisig file, or nil if the artifact has none. It returns the name of
+// the trusted key that signed the artifact, if any. Only in require mode does
+// a missing or untrusted signature return an error; warn mode logs it.
+func (p *Policy) Check(kind Kind, name string, content, sig []byte) (string, error) {
+	mode := p.Mode(kind)
+	if mode == ModeOff {
+		return "", nil
+	}
+
+	signer, err := p.verify(kind, content, sig)
+	if err == nil {
+		return signer, nil
+	}
+	err = fmt.Errorf("%s %s: %w", kind, name, err)
+	if mode == ModeRequire {
+		return "", err
+	}
+	logger.WarnCF("trust", "Installing artifact without a trusted signature", map[string]any{
+		"kind":  string(kind),
+		"name":  name,
+		"error": err.Error(),
+	})
+	return "", nil
+}
+
+func (p *Policy) verify(kind Kind, content, sigData []byte) (string, error) {
+	if sigData == nil {
+		return "", ErrUnsigned
+	}
+	sig, err := ParseSignature(sigData)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUntrusted, err)
+	}
+	for _, k := range p.keys {
+		if len(k.scopes) > 0 && !slices.Contains(k.scopes, kind) {
+			continue
+		}
+		if err := k.key.Verify(content, sig); err == nil {
+			return k.name, nil
+		} else if !errors.Is(err, ErrKeyMismatch) {
+			return "", fmt.Errorf("%w: key %s: %v", ErrUntrusted, k.name, err)
+		}
+	}
+	return "", fmt.Errorf("%w: signed by unknown key %s", ErrUntrusted, PublicKey{ID: sig.KeyID}.KeyID())
+}
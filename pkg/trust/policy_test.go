@@ -0,0 +1,154 @@
+package trust
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/config"
+)
+
+// testKey is a minisign key pair made the way minisign makes them
+type testKey struct {
+	id   [8]byte
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestKey(t *testing.T, id byte) testKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{id: [8]byte{id, 1, 2, 3, 4, 5, 6, 7}, pub: pub, priv: priv}
+}
+
+func (k testKey) publicKey() string {
+	raw := append([]byte("Ed"), k.id[:]...)
+	return base64.StdEncoding.EncodeToString(append(raw, k.pub...))
+}
+
+// sign returns a .minisig file; prehashed selects the default minisign
+// algorithm, which signs the BLAKE2b-512 hash of the content
+func (k testKey) sign(content []byte, prehashed bool) []byte {
+	alg, message := "Ed", content
+	if prehashed {
+		sum := blake2b.Sum512(content)
+		alg, message = "ED", sum[:]
+	}
+	sig := ed25519.Sign(k.priv, message)
+	comment := "timestamp:1760000000\tfile:web.md"
+	global := ed25519.Sign(k.priv, append(append([]byte{}, sig...), comment...))
+	raw := append(append([]byte(alg), k.id[:]...), sig...)
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(raw), comment, base64.StdEncoding.EncodeToString(global)))
+}
+
+func TestMinisignVerify(t *testing.T) {
+	key := newTestKey(t, 0xAB)
+	pub, err := ParsePublicKey("untrusted comment: minisign public key\n" + key.publicKey() + "\n")
+	if err != nil {
+		t.Fatalf("ParsePublicKey() failed: %v", err)
+	}
+	if got := pub.KeyID(); got != "07060504030201AB" {
+		t.Errorf("KeyID() = %s, want 07060504030201AB", got)
+	}
+
+	content := []byte("workflow content")
+	for _, prehashed := range []bool{false, true} {
+		sig, err := ParseSignature(key.sign(content, prehashed))
+		if err != nil {
+			t.Fatalf("ParseSignature() failed: %v", err)
+		}
+		if err := pub.Verify(content, sig); err != nil {
+			t.Errorf("prehashed=%v: Verify() failed: %v", prehashed, err)
+		}
+		if err := pub.Verify([]byte("tampered"), sig); err == nil {
+			t.Errorf("prehashed=%v: Verify() accepted tampered content", prehashed)
+		}
+
+		// The trusted comment is covered by the global signature
+		sig.TrustedComment += " extra"
+		if err := pub.Verify(content, sig); err == nil {
+			t.Errorf("prehashed=%v: Verify() accepted a tampered trusted comment", prehashed)
+		}
+	}
+
+	other, _ := ParseSignature(newTestKey(t, 0xCD).sign(content, true))
+	if err := pub.Verify(content, other); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Verify() with another key's signature = %v, want ErrKeyMismatch", err)
+	}
+
+	if _, err := ParsePublicKey("not a key"); err == nil {
+		t.Error("ParsePublicKey() accepted garbage")
+	}
+	if _, err := ParseSignature([]byte("untrusted comment: x\nAAAA\n")); err == nil {
+		t.Error("ParseSignature() accepted a truncated file")
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	workflowKey := newTestKey(t, 1)
+	skillKey := newTestKey(t, 2)
+	cfg := config.TrustConfig{
+		Keys: []config.TrustedKeyConfig{
+			{Name: "methodology-team", PublicKey: workflowKey.publicKey(), Scopes: []string{"workflows"}},
+			{PublicKey: skillKey.publicKey(), Scopes: []string{"skills"}},
+		},
+		Workflows: "require",
+		Skills:    "warn",
+	}
+	policy, err := NewPolicy(cfg)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+	content := []byte("---\nname: web\n---\n")
+
+	signer, err := policy.Check(KindWorkflow, "web", content, workflowKey.sign(content, true))
+	if err != nil || signer != "methodology-team" {
+		t.Errorf("Check(signed workflow) = %q, %v; want methodology-team", signer, err)
+	}
+	if _, err := policy.Check(KindWorkflow, "web", content, nil); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Check(unsigned workflow) = %v, want ErrUnsigned", err)
+	}
+	// A key trusted only for skills does not vouch for workflows
+	if _, err := policy.Check(KindWorkflow, "web", content, skillKey.sign(content, true)); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("Check(workflow signed by skill key) = %v, want ErrUntrusted", err)
+	}
+	if _, err := policy.Check(KindWorkflow, "web", []byte("tampered"), workflowKey.sign(content, true)); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("Check(tampered workflow) = %v, want ErrUntrusted", err)
+	}
+
+	// Warn mode installs anyway; an unnamed key is reported by its ID
+	if _, err := policy.Check(KindSkill, "github", content, nil); err != nil {
+		t.Errorf("Check(unsigned skill, warn) = %v, want nil", err)
+	}
+	signer, err = policy.Check(KindSkill, "github", content, skillKey.sign(content, false))
+	if err != nil || signer != "0706050403020102" {
+		t.Errorf("Check(signed skill) = %q, %v; want key ID", signer, err)
+	}
+
+	var nilPolicy *Policy
+	if nilPolicy.Mode(KindWorkflow) != ModeOff {
+		t.Error("nil policy should enforce nothing")
+	}
+
+	for _, bad := range []config.TrustConfig{
+		{Workflows: "strict"},
+		{Workflows: "require"},
+		{Keys: []config.TrustedKeyConfig{{PublicKey: "nope"}}},
+		{Keys: []config.TrustedKeyConfig{{PublicKey: workflowKey.publicKey(), Scopes: []string{"models"}}}},
+	} {
+		if _, err := NewPolicy(bad); err == nil {
+			t.Errorf("NewPolicy(%+v) accepted an invalid config", bad)
+		} else if !strings.HasPrefix(err.Error(), "trust.") {
+			t.Errorf("NewPolicy() error %q does not name the config key", err)
+		}
+	}
+}
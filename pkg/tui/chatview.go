@@ -2,11 +2,13 @@ package tui
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/glamour"
+	"github.com/rivo/uniseg"
 )
 
 // ChatView displays the conversation history
@@ -14,6 +16,11 @@ type ChatView struct {
 	messages []ChatMessageMsg
 	scroll   int
 	renderer *glamour.TermRenderer
+	graphics graphicsProtocol
+	previews map[string]string // path@width -> sixel preview
+
+	kittyImages map[string]*kittyImage // path -> stored image, nil while transmitting or after failing
+	terminal    io.Writer              // The program's output, where kitty images are transmitted
 }
 
 // NewChatView creates a new chat view
@@ -25,9 +32,12 @@ func NewChatView() *ChatView {
 	)
 
 	return &ChatView{
-		messages: make([]ChatMessageMsg, 0),
-		scroll:   0,
-		renderer: renderer,
+		messages:    make([]ChatMessageMsg, 0),
+		scroll:      0,
+		renderer:    renderer,
+		graphics:    detectGraphicsProtocol(),
+		previews:    make(map[string]string),
+		kittyImages: make(map[string]*kittyImage),
 	}
 }
 
@@ -146,7 +156,7 @@ func (c *ChatView) View(width, height int) string {
 			// Plain text for other messages
 			contentLines := strings.Split(msg.Content, "\n")
 			for _, line := range contentLines {
-				if len(line) > width-4 {
+				if uniseg.StringWidth(line) > width-4 {
 					// Word wrap
 					wrapped := wordWrap(line, width-4)
 					lines = append(lines, wrapped...)
@@ -156,6 +166,14 @@ func (c *ChatView) View(width, height int) string {
 			}
 		}
 
+		// Inline image previews, each followed by a link line
+		for _, path := range msg.Images {
+			if preview := c.imagePreview(path, width); preview != "" {
+				lines = append(lines, preview)
+			}
+			lines = append(lines, timestampStyle.Render(imageLinkLine(path)))
+		}
+
 		// Spacing between messages
 		lines = append(lines, "")
 	}
@@ -172,7 +190,72 @@ func (c *ChatView) View(width, height int) string {
 	return strings.Join(lines, "\n")
 }
 
-// wordWrap wraps text to the specified width
+// imagePreview returns the inline preview for path, or "" when the
+// terminal has no graphics support or the image can't be shown.
+//
+// Kitty images are only placed by id here, once transmitImages has stored
+// them. Sixel has no stored images, so the encoded image stays in the view;
+// it is cached per width and the renderer only rewrites it when its line
+// moves.
+func (c *ChatView) imagePreview(path string, width int) string {
+	switch c.graphics {
+	case graphicsKitty:
+		img := c.kittyImages[path]
+		if img == nil {
+			return ""
+		}
+		cols, rows, ok := previewSize(img.width, img.height, width)
+		if !ok {
+			return ""
+		}
+		return reserveRows(encodeKittyPlace(img.id, cols, rows), rows)
+
+	case graphicsSixel:
+		key := fmt.Sprintf("%s@%d", path, width)
+		if preview, ok := c.previews[key]; ok {
+			return preview
+		}
+		var preview string
+		if img := loadPreviewImage(path, width); img != nil {
+			preview = reserveRows(encodeSixel(scaleImage(img.img, img.cols*previewCellPxW, img.rows*previewCellPxH)), img.rows)
+		}
+		c.previews[key] = preview
+		return preview
+	}
+	return ""
+}
+
+// kittyImageMsg reports an image stored in the terminal, nil if it failed
+type kittyImageMsg struct {
+	path  string
+	image *kittyImage
+}
+
+// transmitImages returns a command that stores the kitty images among paths
+// in the terminal, each once. The transmission runs outside View and goes
+// through the program's output, so it never interleaves with a frame.
+func (c *ChatView) transmitImages(paths []string) tea.Cmd {
+	if c.graphics != graphicsKitty || c.terminal == nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for _, path := range paths {
+		if _, ok := c.kittyImages[path]; ok {
+			continue
+		}
+		c.kittyImages[path] = nil
+		id, terminal := len(c.kittyImages), c.terminal
+		cmds = append(cmds, func() tea.Msg {
+			return kittyImageMsg{path: path, image: transmitKittyImage(terminal, id, path)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// wordWrap wraps text to the specified display width. Widths are measured
+// per grapheme cluster so wide (CJK) and combining characters are counted
+// correctly, and words wider than the line are broken between clusters.
 func wordWrap(text string, width int) []string {
 	if width <= 0 {
 		return []string{text}
@@ -180,21 +263,42 @@ func wordWrap(text string, width int) []string {
 
 	var lines []string
 	var currentLine strings.Builder
+	currentWidth := 0
 
-	words := strings.Fields(text)
-	for i, word := range words {
-		if i > 0 {
-			// Check if adding this word would exceed width
-			if currentLine.Len()+1+len(word) > width {
-				lines = append(lines, currentLine.String())
-				currentLine.Reset()
-				currentLine.WriteString(word)
-			} else {
-				currentLine.WriteString(" ")
-				currentLine.WriteString(word)
-			}
-		} else {
+	flush := func() {
+		lines = append(lines, currentLine.String())
+		currentLine.Reset()
+		currentWidth = 0
+	}
+
+	for _, word := range strings.Fields(text) {
+		wordWidth := uniseg.StringWidth(word)
+
+		// Check if adding this word would exceed width
+		if currentWidth > 0 && currentWidth+1+wordWidth <= width {
+			currentLine.WriteString(" ")
+			currentLine.WriteString(word)
+			currentWidth += 1 + wordWidth
+			continue
+		}
+		if currentWidth > 0 {
+			flush()
+		}
+		if wordWidth <= width {
 			currentLine.WriteString(word)
+			currentWidth = wordWidth
+			continue
+		}
+
+		// Hard-break words wider than the line (CJK runs, long URLs)
+		g := uniseg.NewGraphemes(word)
+		for g.Next() {
+			clusterWidth := g.Width()
+			if currentWidth > 0 && currentWidth+clusterWidth > width {
+				flush()
+			}
+			currentLine.WriteString(g.Str())
+			currentWidth += clusterWidth
 		}
 	}
 
@@ -0,0 +1,71 @@
+package tui
+
+import "strings"
+
+// diffLine is one line of a line-based diff: op is ' ' (unchanged),
+// '-' (only in the old text) or '+' (only in the new text).
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffMaxCells bounds the LCS table; larger inputs degrade to a plain
+// remove-all/add-all diff.
+const diffMaxCells = 250_000
+
+// lineDiff computes a minimal line diff between oldText and newText using a
+// longest-common-subsequence table.
+func lineDiff(oldText, newText string) []diffLine {
+	a := strings.Split(oldText, "\n")
+	b := strings.Split(newText, "\n")
+
+	if len(a)*len(b) > diffMaxCells {
+		out := make([]diffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			out = append(out, diffLine{'-', line})
+		}
+		for _, line := range b {
+			out = append(out, diffLine{'+', line})
+		}
+		return out
+	}
+
+	// lcs[i][j] = LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out
+}
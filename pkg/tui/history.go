@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// historyMaxEntries bounds how many past inputs are kept in memory.
+const historyMaxEntries = 1000
+
+// DefaultHistoryPath is the input history file shared by the TUI and the
+// readline-based interactive mode.
+func DefaultHistoryPath() string {
+	return filepath.Join(os.TempDir(), ".picoclaw_history")
+}
+
+// History is the persistent list of submitted inputs, oldest first.
+type History struct {
+	path    string
+	entries []string
+}
+
+// LoadHistory reads the history file at path. A missing file yields an empty
+// history that will be created on the first Add.
+func LoadHistory(path string) *History {
+	h := &History{path: path}
+	if path == "" {
+		return h
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > historyMaxEntries {
+		h.entries = h.entries[len(h.entries)-historyMaxEntries:]
+	}
+	return h
+}
+
+// Len returns the number of entries
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns the entry at index i (0 = oldest)
+func (h *History) At(i int) string {
+	return h.entries[i]
+}
+
+// IsSensitiveInput reports whether input carries a secret (a /set session
+// variable) and must not be written to history.
+func IsSensitiveInput(input string) bool {
+	return strings.HasPrefix(strings.TrimSpace(input), "/set ")
+}
+
+// Add records a submitted input and appends it to the history file.
+// Repeats of the most recent entry and sensitive inputs are skipped.
+func (h *History) Add(entry string) {
+	entry = strings.TrimSpace(strings.ReplaceAll(entry, "\n", " "))
+	if entry == "" || IsSensitiveInput(entry) || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > historyMaxEntries {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(entry + "\n")
+}
+
+// Search returns distinct entries fuzzily matching query, best first. An
+// entry matches when the query's characters appear in it in order; tighter,
+// earlier and more recent matches rank higher.
+func (h *History) Search(query string) []string {
+	type match struct {
+		entry string
+		score int
+		index int
+	}
+
+	seen := make(map[string]bool)
+	var matches []match
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		if score, ok := fuzzyScore(query, entry); ok {
+			matches = append(matches, match{entry: entry, score: score, index: i})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return matches[a].index > matches[b].index
+	})
+
+	results := make([]string, len(matches))
+	for i, m := range matches {
+		results[i] = m.entry
+	}
+	return results
+}
+
+// fuzzyScore reports whether query is a case-insensitive subsequence of
+// candidate and scores the match: consecutive characters and matches at word
+// starts earn bonuses, gaps cost points.
+func fuzzyScore(query, candidate string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	c := []rune(strings.ToLower(candidate))
+	score, qi, last := 0, 0, -1
+	for ci := 0; ci < len(c) && qi < len(q); ci++ {
+		if c[ci] != q[qi] {
+			continue
+		}
+		score += 10
+		switch {
+		case last == ci-1:
+			score += 15 // consecutive
+		case last >= 0:
+			score -= min(ci-last-1, 10) // gap
+		}
+		if ci == 0 || !unicode.IsLetter(c[ci-1]) && !unicode.IsDigit(c[ci-1]) {
+			score += 8 // word start
+		}
+		last = ci
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
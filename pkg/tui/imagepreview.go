@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graphicsProtocol identifies how the terminal can display inline images.
+type graphicsProtocol string
+
+const (
+	graphicsNone  graphicsProtocol = ""
+	graphicsKitty graphicsProtocol = "kitty"
+	graphicsSixel graphicsProtocol = "sixel"
+)
+
+const (
+	previewMaxCols  = 48
+	previewMaxRows  = 16
+	previewCellPxW  = 10 // approximate cell size used to size sixel output
+	previewCellPxH  = 20
+	kittyChunkBytes = 4096
+
+	// Larger images are skipped rather than decoded for a preview
+	previewMaxPixels = 4096 * 4096
+)
+
+// detectGraphicsProtocol inspects the environment for inline image support.
+// PICOCLAW_TUI_IMAGES=kitty|sixel|off overrides detection.
+func detectGraphicsProtocol() graphicsProtocol {
+	switch strings.ToLower(os.Getenv("PICOCLAW_TUI_IMAGES")) {
+	case "kitty":
+		return graphicsKitty
+	case "sixel":
+		return graphicsSixel
+	case "off", "none", "0", "false":
+		return graphicsNone
+	}
+
+	term := strings.ToLower(os.Getenv("TERM"))
+	termProgram := strings.ToLower(os.Getenv("TERM_PROGRAM"))
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", strings.Contains(term, "kitty"):
+		return graphicsKitty
+	case termProgram == "wezterm", termProgram == "ghostty", strings.Contains(term, "ghostty"):
+		return graphicsKitty
+	case strings.Contains(term, "foot"), strings.Contains(term, "mlterm"),
+		strings.Contains(term, "sixel"), termProgram == "iterm.app":
+		return graphicsSixel
+	}
+	return graphicsNone
+}
+
+// imageLinkLine is the fallback shown for every image, and the only output
+// on terminals without graphics support.
+func imageLinkLine(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return fmt.Sprintf("🖼  %s  file://%s", filepath.Base(path), abs)
+}
+
+// previewImage is an image decoded and sized for an inline preview
+type previewImage struct {
+	img        image.Image
+	cols, rows int // Cells the preview occupies
+}
+
+// loadPreviewImage decodes path and sizes it to fit width columns. It
+// returns nil when the image can't be shown.
+func loadPreviewImage(path string, width int) *previewImage {
+	img, _, _, ok := decodePreviewImage(path)
+	if !ok {
+		return nil
+	}
+	bounds := img.Bounds()
+	cols, rows, ok := previewSize(bounds.Dx(), bounds.Dy(), width)
+	if !ok {
+		return nil
+	}
+	return &previewImage{img: img, cols: cols, rows: rows}
+}
+
+// decodePreviewImage reads and decodes the image at path, returning its
+// format and raw bytes too. Images over previewMaxPixels are rejected from
+// their header, before anything is decoded.
+func decodePreviewImage(path string) (image.Image, string, []byte, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", nil, false
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > previewMaxPixels {
+		return nil, "", nil, false
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", nil, false
+	}
+	return img, format, data, true
+}
+
+// previewSize fits a w x h pixel image into width columns, returning the
+// cells the preview occupies
+func previewSize(w, h, width int) (cols, rows int, ok bool) {
+	cols = min(previewMaxCols, width-4)
+	if cols <= 0 || w <= 0 || h <= 0 {
+		return 0, 0, false
+	}
+	// Terminal cells are roughly twice as tall as they are wide.
+	rows = cols * h / w / 2
+	if rows > previewMaxRows {
+		cols = cols * previewMaxRows / rows
+		rows = previewMaxRows
+	}
+	return max(cols, 1), max(rows, 1), true
+}
+
+// reserveRows follows an inline image with the blank lines it covers
+func reserveRows(seq string, rows int) string {
+	return seq + strings.Repeat("\n", rows-1)
+}
+
+// kittyImage is an image stored in the terminal under id
+type kittyImage struct {
+	id            int
+	width, height int // Pixels, for sizing placements
+}
+
+// transmitKittyImage decodes path and stores it in the terminal as image
+// id. It returns nil when the image can't be shown.
+func transmitKittyImage(terminal io.Writer, id int, path string) *kittyImage {
+	img, format, data, ok := decodePreviewImage(path)
+	if !ok {
+		return nil
+	}
+	if format != "png" {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil
+		}
+		data = buf.Bytes()
+	}
+	if _, err := io.WriteString(terminal, encodeKittyTransmit(id, data)); err != nil {
+		return nil
+	}
+	bounds := img.Bounds()
+	return &kittyImage{id: id, width: bounds.Dx(), height: bounds.Dy()}
+}
+
+// encodeKittyTransmit stores PNG data in the terminal as image id without
+// displaying it, split into the chunks the protocol requires. The image is
+// then shown with encodeKittyPlace as often as needed.
+func encodeKittyTransmit(id int, pngData []byte) string {
+	payload := base64.StdEncoding.EncodeToString(pngData)
+
+	var b strings.Builder
+	for first := true; len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkBytes {
+			chunk = chunk[:kittyChunkBytes]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=t,f=100,q=2,i=%d,m=%d;%s\x1b\\", id, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// encodeKittyPlace displays the stored image id at the cursor, scaled to
+// cols x rows cells
+func encodeKittyPlace(id, cols, rows int) string {
+	return fmt.Sprintf("\x1b_Ga=p,q=2,i=%d,p=1,c=%d,r=%d\x1b\\", id, cols, rows)
+}
+
+// scaleImage downsamples img with nearest-neighbour sampling so it fits in
+// maxW x maxH pixels, preserving aspect ratio. Smaller images are returned as is.
+func scaleImage(img image.Image, maxW, maxH int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxW && h <= maxH {
+		return img
+	}
+
+	scale := math.Min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	dw, dh := max(int(float64(w)*scale), 1), max(int(float64(h)*scale), 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy := bounds.Min.Y + y*h/dh
+		for x := 0; x < dw; x++ {
+			dst.Set(x, y, img.At(bounds.Min.X+x*w/dw, sy))
+		}
+	}
+	return dst
+}
+
+// sixelLevels is the per-channel resolution of the fixed 6x6x6 sixel palette.
+const sixelLevels = 6
+
+// encodeSixel renders img as a DEC sixel image using a fixed 216-colour
+// palette. Fully transparent pixels are left unpainted.
+func encodeSixel(img image.Image) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Quantize every pixel to a palette index, -1 for transparent.
+	indices := make([]int, w*h)
+	used := make([]bool, sixelLevels*sixelLevels*sixelLevels)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			idx := sixelPaletteIndex(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			indices[y*w+x] = idx
+			if idx >= 0 {
+				used[idx] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for idx, ok := range used {
+		if !ok {
+			continue
+		}
+		r, g, bl := idx/(sixelLevels*sixelLevels), idx/sixelLevels%sixelLevels, idx%sixelLevels
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", idx, r*100/(sixelLevels-1), g*100/(sixelLevels-1), bl*100/(sixelLevels-1))
+	}
+
+	band := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		firstColor := true
+		for idx, ok := range used {
+			if !ok {
+				continue
+			}
+			painted := false
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if indices[(top+dy)*w+x] == idx {
+						bits |= 1 << dy
+					}
+				}
+				band[x] = bits
+				painted = painted || bits != 0
+			}
+			if !painted {
+				continue
+			}
+			if !firstColor {
+				b.WriteByte('$')
+			}
+			firstColor = false
+			fmt.Fprintf(&b, "#%d", idx)
+			writeSixelRun(&b, band)
+		}
+		b.WriteByte('-')
+	}
+
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRun writes one colour's band using run-length encoding.
+func writeSixelRun(b *strings.Builder, band []byte) {
+	for x := 0; x < len(band); {
+		run := 1
+		for x+run < len(band) && band[x+run] == band[x] {
+			run++
+		}
+		ch := byte(63 + band[x])
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				b.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
+
+// sixelPaletteIndex maps a colour to the nearest 6x6x6 palette entry.
+func sixelPaletteIndex(c color.Color) int {
+	r, g, b, a := c.RGBA()
+	if a < 0x8000 {
+		return -1
+	}
+	level := func(v uint32) int {
+		return int((v*(sixelLevels-1) + 0x7fff) / 0xffff)
+	}
+	return level(r)*sixelLevels*sixelLevels + level(g)*sixelLevels + level(b)
+}
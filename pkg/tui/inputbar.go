@@ -1,27 +1,82 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
 )
 
-// InputBar handles user input at the bottom
+// InputBar handles user input at the bottom. The input is kept as grapheme
+// clusters so the cursor never splits a multi-byte or combined character.
 type InputBar struct {
-	input    string
-	cursor   int
+	input    []string // grapheme clusters
+	cursor   int      // index into input
 	focused  bool
 	onSubmit func(string)
+
+	// History recall (up/down) and Ctrl+R fuzzy search
+	history       *History
+	historyPos    int    // index into history while browsing, -1 otherwise
+	draft         string // input before browsing started
+	searching     bool
+	searchQuery   string
+	searchMatches []string
+	searchSel     int
 }
 
 // NewInputBar creates a new input bar
 func NewInputBar() *InputBar {
 	return &InputBar{
-		input:   "",
-		cursor:  0,
-		focused: true,
+		input:      nil,
+		cursor:     0,
+		focused:    true,
+		historyPos: -1,
+	}
+}
+
+// SetHistory sets the input history used for recall and search
+func (i *InputBar) SetHistory(h *History) {
+	i.history = h
+	i.historyPos = -1
+}
+
+// Searching reports whether Ctrl+R history search is active
+func (i *InputBar) Searching() bool {
+	return i.searching
+}
+
+// setValue replaces the input and moves the cursor to the end
+func (i *InputBar) setValue(value string) {
+	i.input = graphemes(value)
+	i.cursor = len(i.input)
+}
+
+// Value returns the current input text
+func (i *InputBar) Value() string {
+	return strings.Join(i.input, "")
+}
+
+// insert adds text at the cursor, re-segmenting so combining marks join the
+// preceding cluster.
+func (i *InputBar) insert(text string) {
+	before := strings.Join(i.input[:i.cursor], "") + text
+	after := strings.Join(i.input[i.cursor:], "")
+	i.cursor = len(graphemes(before))
+	i.input = graphemes(before + after)
+	i.cursor = min(i.cursor, len(i.input))
+}
+
+// graphemes splits s into user-perceived characters
+func graphemes(s string) []string {
+	var clusters []string
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		clusters = append(clusters, g.Str())
 	}
+	return clusters
 }
 
 // SetOnSubmit sets the callback for when input is submitted
@@ -37,25 +92,66 @@ func (i *InputBar) Update(msg tea.Msg) (*InputBar, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if i.searching {
+			i.updateSearch(msg)
+			return i, nil
+		}
+
 		switch msg.String() {
 		case "enter":
-			if len(strings.TrimSpace(i.input)) > 0 {
+			if value := i.Value(); len(strings.TrimSpace(value)) > 0 {
+				if i.history != nil {
+					i.history.Add(value)
+				}
 				if i.onSubmit != nil {
-					i.onSubmit(i.input)
+					i.onSubmit(value)
 				}
-				i.input = ""
+				i.input = nil
 				i.cursor = 0
+				i.historyPos = -1
+			}
+
+		case "up":
+			if i.history == nil || i.history.Len() == 0 {
+				break
+			}
+			if i.historyPos == -1 {
+				i.draft = i.Value()
+				i.historyPos = i.history.Len()
+			}
+			if i.historyPos > 0 {
+				i.historyPos--
+				i.setValue(i.history.At(i.historyPos))
+			}
+
+		case "down":
+			if i.historyPos == -1 {
+				break
+			}
+			i.historyPos++
+			if i.historyPos >= i.history.Len() {
+				i.historyPos = -1
+				i.setValue(i.draft)
+			} else {
+				i.setValue(i.history.At(i.historyPos))
+			}
+
+		case "ctrl+r":
+			if i.history != nil {
+				i.searching = true
+				i.searchQuery = ""
+				i.refreshSearch()
 			}
 
 		case "backspace":
 			if i.cursor > 0 {
-				i.input = i.input[:i.cursor-1] + i.input[i.cursor:]
+				i.input = append(i.input[:i.cursor-1], i.input[i.cursor:]...)
 				i.cursor--
 			}
 
 		case "delete":
 			if i.cursor < len(i.input) {
-				i.input = i.input[:i.cursor] + i.input[i.cursor+1:]
+				i.input = append(i.input[:i.cursor], i.input[i.cursor+1:]...)
 			}
 
 		case "left":
@@ -76,17 +172,16 @@ func (i *InputBar) Update(msg tea.Msg) (*InputBar, tea.Cmd) {
 
 		case "ctrl+u":
 			// Clear line
-			i.input = ""
+			i.input = nil
 			i.cursor = 0
 
 		default:
-			// Regular character input
-			if len(msg.String()) == 1 {
-				runes := []rune(i.input)
-				before := string(runes[:i.cursor])
-				after := string(runes[i.cursor:])
-				i.input = before + msg.String() + after
-				i.cursor++
+			// Regular character input, including IME commits and pastes
+			switch msg.Type {
+			case tea.KeyRunes:
+				i.insert(string(msg.Runes))
+			case tea.KeySpace:
+				i.insert(" ")
 			}
 		}
 	}
@@ -94,8 +189,85 @@ func (i *InputBar) Update(msg tea.Msg) (*InputBar, tea.Cmd) {
 	return i, nil
 }
 
+// updateSearch handles keys while Ctrl+R search is active. Enter accepts the
+// selected match into the input (without submitting), Ctrl+R cycles through
+// matches and Esc/Ctrl+G cancel.
+func (i *InputBar) updateSearch(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "enter", "right", "end", "ctrl+e":
+		if len(i.searchMatches) > 0 {
+			i.setValue(i.searchMatches[i.searchSel])
+		}
+		i.searching = false
+	case "esc", "ctrl+g":
+		i.searching = false
+	case "ctrl+r", "up":
+		if len(i.searchMatches) > 0 {
+			i.searchSel = (i.searchSel + 1) % len(i.searchMatches)
+		}
+	case "down":
+		if len(i.searchMatches) > 0 {
+			i.searchSel = (i.searchSel - 1 + len(i.searchMatches)) % len(i.searchMatches)
+		}
+	case "backspace":
+		if clusters := graphemes(i.searchQuery); len(clusters) > 0 {
+			i.searchQuery = strings.Join(clusters[:len(clusters)-1], "")
+			i.refreshSearch()
+		}
+	default:
+		switch msg.Type {
+		case tea.KeyRunes:
+			i.searchQuery += string(msg.Runes)
+			i.refreshSearch()
+		case tea.KeySpace:
+			i.searchQuery += " "
+			i.refreshSearch()
+		}
+	}
+}
+
+func (i *InputBar) refreshSearch() {
+	i.searchMatches = i.history.Search(i.searchQuery)
+	i.searchSel = 0
+}
+
+// viewSearch renders the Ctrl+R search prompt
+func (i *InputBar) viewSearch(width int) string {
+	promptStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	matchStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15"))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	prompt := promptStyle.Render(fmt.Sprintf("(history search)'%s': ", i.searchQuery))
+	var match string
+	switch {
+	case len(i.searchMatches) == 0:
+		match = dimStyle.Render("no matches")
+	default:
+		match = matchStyle.Render(i.searchMatches[i.searchSel])
+		if len(i.searchMatches) > 1 {
+			match += dimStyle.Render(fmt.Sprintf("  [%d/%d]", i.searchSel+1, len(i.searchMatches)))
+		}
+	}
+
+	line := prompt + match
+	if lineWidth := lipgloss.Width(line); lineWidth < width {
+		line += strings.Repeat(" ", width-lineWidth)
+	}
+	return line
+}
+
 // View renders the input bar
 func (i *InputBar) View(width int) string {
+	if i.searching {
+		return i.viewSearch(width)
+	}
+
 	// Style definitions
 	promptStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("86")).
@@ -113,29 +285,30 @@ func (i *InputBar) View(width int) string {
 
 	// Build input with cursor
 	var displayInput string
+	value := i.Value()
 	if i.focused && i.cursor < len(i.input) {
 		// Show cursor
-		before := i.input[:i.cursor]
-		cursor := string(i.input[i.cursor])
-		after := i.input[i.cursor+1:]
+		before := strings.Join(i.input[:i.cursor], "")
+		cursor := i.input[i.cursor]
+		after := strings.Join(i.input[i.cursor+1:], "")
 		displayInput = inputStyle.Render(before) +
 			cursorStyle.Render(cursor) +
 			inputStyle.Render(after)
 	} else if i.focused && i.cursor == len(i.input) {
 		// Cursor at end
-		displayInput = inputStyle.Render(i.input) + cursorStyle.Render(" ")
+		displayInput = inputStyle.Render(value) + cursorStyle.Render(" ")
 	} else {
 		// Not focused
-		displayInput = inputStyle.Render(i.input)
+		displayInput = inputStyle.Render(value)
 	}
 
 	// Combine
 	line := prompt + displayInput
 
 	// Pad to full width
-	lineWidth := lipgloss.Width(prompt) + len(i.input)
-	if i.focused {
-		lineWidth++ // cursor
+	lineWidth := lipgloss.Width(prompt) + uniseg.StringWidth(value)
+	if i.focused && i.cursor == len(i.input) {
+		lineWidth++ // cursor cell past the end
 	}
 	if lineWidth < width {
 		line += strings.Repeat(" ", width-lineWidth)
@@ -151,6 +324,6 @@ func (i *InputBar) SetFocused(focused bool) {
 
 // Clear clears the input
 func (i *InputBar) Clear() {
-	i.input = ""
+	i.input = nil
 	i.cursor = 0
 }
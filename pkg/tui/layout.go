@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Optional third pane shown next to the mission panel.
+const (
+	PaneNone  = ""
+	PaneTools = "tools"
+	PaneLogs  = "logs"
+)
+
+const (
+	defaultChatRatio = 2.0 / 3.0
+	minChatRatio     = 0.3
+	maxChatRatio     = 0.85
+	chatRatioStep    = 0.05
+)
+
+// Layout holds the user's pane preferences, persisted across sessions.
+type Layout struct {
+	ChatRatio   float64 `json:"chat_ratio"`   // fraction of the width given to chat when side panes are open
+	HideMission bool    `json:"hide_mission"` // keep the mission panel closed even when a workflow is loaded
+	ExtraPane   string  `json:"extra_pane"`   // "", "tools" or "logs"
+}
+
+// DefaultLayout returns the classic 2/3 chat, 1/3 mission split.
+func DefaultLayout() Layout {
+	return Layout{ChatRatio: defaultChatRatio}
+}
+
+// DefaultLayoutPath returns ~/.picoclaw/tui_layout.json.
+func DefaultLayoutPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".picoclaw", "tui_layout.json")
+}
+
+// LoadLayout reads layout preferences from path, falling back to the
+// defaults when the file is missing or unreadable.
+func LoadLayout(path string) Layout {
+	layout := DefaultLayout()
+	if path == "" {
+		return layout
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return layout
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return DefaultLayout()
+	}
+	layout.normalize()
+	return layout
+}
+
+// Save writes the layout preferences to path.
+func (l Layout) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Resize grows (positive steps) or shrinks the chat pane.
+func (l *Layout) Resize(steps int) {
+	l.ChatRatio += float64(steps) * chatRatioStep
+	l.normalize()
+}
+
+// CycleExtraPane switches the third pane: none -> tools -> logs -> none.
+func (l *Layout) CycleExtraPane() {
+	switch l.ExtraPane {
+	case PaneNone:
+		l.ExtraPane = PaneTools
+	case PaneTools:
+		l.ExtraPane = PaneLogs
+	default:
+		l.ExtraPane = PaneNone
+	}
+}
+
+func (l *Layout) normalize() {
+	if l.ChatRatio == 0 {
+		l.ChatRatio = defaultChatRatio
+	}
+	l.ChatRatio = math.Max(minChatRatio, math.Min(maxChatRatio, l.ChatRatio))
+	if l.ExtraPane != PaneTools && l.ExtraPane != PaneLogs {
+		l.ExtraPane = PaneNone
+	}
+}
+
+// joinColumns places rendered panes side by side, padding each to its width
+// and separating them with a vertical rule.
+func joinColumns(contents []string, widths []int) []string {
+	columns := make([][]string, len(contents))
+	maxLines := 0
+	for i, content := range contents {
+		columns[i] = strings.Split(content, "\n")
+		maxLines = max(maxLines, len(columns[i]))
+	}
+
+	rows := make([]string, 0, maxLines)
+	for row := 0; row < maxLines; row++ {
+		var b strings.Builder
+		for i, lines := range columns {
+			var line string
+			if row < len(lines) {
+				line = lines[row]
+			}
+			if i < len(columns)-1 {
+				line += strings.Repeat(" ", max(0, widths[i]-lipgloss.Width(line)))
+				line += "│"
+			}
+			b.WriteString(line)
+		}
+		rows = append(rows, b.String())
+	}
+	return rows
+}
@@ -8,9 +8,14 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// MissionView displays workflow/mission state
+// journalLines is how many of the latest journal entries the mission view shows
+const journalLines = 5
+
+// MissionView displays workflow/mission state. It renders a snapshot taken
+// on Update, so drawing never races the agent changing the mission.
 type MissionView struct {
-	engine *workflow.Engine
+	workflow *workflow.Workflow
+	state    *workflow.MissionState
 }
 
 // NewMissionView creates a new mission view
@@ -20,20 +25,25 @@ func NewMissionView() *MissionView {
 
 // Update updates the mission view with new workflow state
 func (m *MissionView) Update(engine *workflow.Engine) {
-	m.engine = engine
+	if engine == nil {
+		m.workflow, m.state = nil, nil
+		return
+	}
+	m.workflow = engine.GetWorkflow()
+	m.state = engine.GetState()
 }
 
 // View renders the mission view
 func (m *MissionView) View(width, height int) string {
-	if m.engine == nil {
+	if m.workflow == nil || m.state == nil {
 		emptyStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")).
 			Padding(1, 1)
 		return emptyStyle.Render("No active mission")
 	}
 
-	wf := m.engine.GetWorkflow()
-	state := m.engine.GetState()
+	wf := m.workflow
+	state := m.state
 
 	// Style definitions
 	titleStyle := lipgloss.NewStyle().
@@ -180,24 +190,27 @@ func (m *MissionView) View(width, height int) string {
 			lines = append(lines, fmt.Sprintf("  ● Info: %d", infoCount))
 		}
 
-		// Show last 3 findings
+		// Show the 3 highest scored findings
 		lines = append(lines, "")
-		lines = append(lines, "Recent:")
-		start := max(0, len(state.Findings)-3)
-		for i := start; i < len(state.Findings); i++ {
-			f := state.Findings[i]
+		lines = append(lines, "Top:")
+		top := workflow.ReportFindings(state, workflow.ReportInternal)
+		for _, f := range top[:min(3, len(top))] {
+			label := fmt.Sprintf("[%s]", f.Severity)
+			if f.CVSSScore > 0 {
+				label = fmt.Sprintf("[%.1f %s]", f.CVSSScore, f.Severity)
+			}
 			var severityLabel string
 			switch f.Severity {
 			case workflow.SeverityCritical:
-				severityLabel = criticalStyle.Render(fmt.Sprintf("[%s]", f.Severity))
+				severityLabel = criticalStyle.Render(label)
 			case workflow.SeverityHigh:
-				severityLabel = highStyle.Render(fmt.Sprintf("[%s]", f.Severity))
+				severityLabel = highStyle.Render(label)
 			case workflow.SeverityMedium:
-				severityLabel = mediumStyle.Render(fmt.Sprintf("[%s]", f.Severity))
+				severityLabel = mediumStyle.Render(label)
 			case workflow.SeverityLow:
-				severityLabel = lowStyle.Render(fmt.Sprintf("[%s]", f.Severity))
+				severityLabel = lowStyle.Render(label)
 			default:
-				severityLabel = fmt.Sprintf("[%s]", f.Severity)
+				severityLabel = label
 			}
 
 			title := f.Title
@@ -209,6 +222,19 @@ func (m *MissionView) View(width, height int) string {
 		}
 	}
 
+	// The latest journal entries, oldest first
+	if journal := workflow.Journal(state, state.Findings, workflow.English); len(journal) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, headerStyle.Render("Journal:"))
+		for _, entry := range journal[max(0, len(journal)-journalLines):] {
+			details := entry.Details
+			if len(details) > 40 {
+				details = details[:37] + "..."
+			}
+			lines = append(lines, pendingStyle.Render(entry.Time.Format("15:04"))+" "+entry.Event+": "+details)
+		}
+	}
+
 	// Truncate to height
 	if len(lines) > height {
 		lines = lines[:height-1]
@@ -221,13 +247,13 @@ func (m *MissionView) View(width, height int) string {
 func nextActionableStep(phase workflow.Phase, exec *workflow.PhaseExecution) *workflow.Step {
 	for i := range phase.Steps {
 		step := &phase.Steps[i]
-		if step.Required && !isStepComplete(step.ID, exec) {
+		if step.Required && !isStepComplete(step.ID, exec) && len(step.PendingDependencies(exec)) == 0 {
 			return step
 		}
 	}
 	for i := range phase.Steps {
 		step := &phase.Steps[i]
-		if !isStepComplete(step.ID, exec) {
+		if !isStepComplete(step.ID, exec) && len(step.PendingDependencies(exec)) == 0 {
 			return step
 		}
 	}
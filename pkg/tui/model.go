@@ -1,8 +1,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
@@ -21,6 +25,8 @@ type Model struct {
 	chatView    *ChatView
 	missionView *MissionView
 	inputBar    *InputBar
+	toolsPane   *SidePane
+	logsPane    *SidePane
 
 	// Current state
 	currentModel   string
@@ -34,17 +40,35 @@ type Model struct {
 	// Layout
 	showMissionPanel bool
 	focusedView      string // "chat" or "input"
+	layout           Layout
+	layoutPath       string
+
+	// Pending supervisor correction awaiting operator approval, and the
+	// ones that arrived while it was shown
+	review        *CorrectionReviewMsg
+	reviewQueue   []*CorrectionReviewMsg
+	reviewEditing bool
+
+	// Pending ask_operator question
+	question *OperatorQuestionMsg
 }
 
 // NewModel creates a new TUI model
 func NewModel() *Model {
+	inputBar := NewInputBar()
+	inputBar.SetHistory(LoadHistory(DefaultHistoryPath()))
+
 	return &Model{
 		statusBar:        NewStatusBar(),
 		chatView:         NewChatView(),
 		missionView:      NewMissionView(),
-		inputBar:         NewInputBar(),
+		inputBar:         inputBar,
+		toolsPane:        NewSidePane("Tools"),
+		logsPane:         NewSidePane("Logs"),
 		showMissionPanel: false,
 		focusedView:      "input",
+		layout:           LoadLayout(DefaultLayoutPath()),
+		layoutPath:       DefaultLayoutPath(),
 	}
 }
 
@@ -64,12 +88,36 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateLayout()
 
 	case tea.KeyMsg:
+		if m.review != nil && msg.String() != "ctrl+c" && m.updateReview(msg.String()) {
+			return m, nil
+		}
+		if m.review == nil && m.question != nil && msg.String() != "ctrl+c" && m.updateQuestion(msg.String()) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
+			if msg.String() == "esc" && m.inputBar.Searching() {
+				break // esc cancels history search instead of quitting
+			}
+			m.dismissReviews()
+			if m.question != nil {
+				m.resolveQuestion(operatorAnswer{err: errQuestionDismissed})
+			}
 			return m, tea.Quit
 		case "ctrl+m":
 			m.showMissionPanel = !m.showMissionPanel
-			m.updateLayout()
+			m.layout.HideMission = !m.showMissionPanel
+			m.saveLayout()
+		case "ctrl+left":
+			m.layout.Resize(-1)
+			m.saveLayout()
+		case "ctrl+right":
+			m.layout.Resize(1)
+			m.saveLayout()
+		case "ctrl+t":
+			m.layout.CycleExtraPane()
+			m.saveLayout()
 		case "tab":
 			if m.focusedView == "chat" {
 				m.focusedView = "input"
@@ -86,6 +134,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case CostUpdateMsg:
 		m.sessionCost = msg.Total
 		m.statusBar.SetCost(msg.Total)
+		m.statusBar.SetSavings(msg.Saved, msg.SavedPercent)
 
 	case ProfileReadinessMsg:
 		m.profilesReady = msg.Ready
@@ -94,6 +143,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ChatMessageMsg:
 		m.chatView.AddMessage(msg)
+		cmds = append(cmds, m.chatView.transmitImages(msg.Images))
+		if msg.Role == "tool" {
+			m.toolsPane.Append(toolActivityLine(msg))
+		}
+
+	case kittyImageMsg:
+		m.chatView.kittyImages[msg.path] = msg.image
+
+	case CorrectionReviewMsg:
+		if m.review != nil {
+			// One review at a time; the newer one waits its turn
+			m.reviewQueue = append(m.reviewQueue, &msg)
+			break
+		}
+		m.review = &msg
+
+	case OperatorQuestionMsg:
+		if m.question != nil {
+			// Only one question at a time; dismiss the older one
+			m.resolveQuestion(operatorAnswer{err: errQuestionDismissed})
+		}
+		m.question = &msg
+		m.focusedView = "input"
 
 	case WorkflowUpdateMsg:
 		if m.workflowEngine != nil {
@@ -128,40 +200,37 @@ func (m *Model) View() string {
 	// Main content area
 	contentHeight := m.height - 3 // Reserve space for status bar and input bar
 
+	// Side panes: mission panel and the optional tools/logs pane
+	var sideViews []func(width, height int) string
 	if m.showMissionPanel {
-		// Split view: chat on left, mission panel on right
-		chatWidth := m.width * 2 / 3
-		missionWidth := m.width - chatWidth - 1
-
-		chatContent := m.chatView.View(chatWidth, contentHeight-2)
-		missionContent := m.missionView.View(missionWidth, contentHeight-2)
-
-		// Combine horizontally
-		chatLines := strings.Split(chatContent, "\n")
-		missionLines := strings.Split(missionContent, "\n")
-
-		maxLines := len(chatLines)
-		if len(missionLines) > maxLines {
-			maxLines = len(missionLines)
-		}
+		sideViews = append(sideViews, m.missionView.View)
+	}
+	switch m.layout.ExtraPane {
+	case PaneTools:
+		sideViews = append(sideViews, m.toolsPane.View)
+	case PaneLogs:
+		sideViews = append(sideViews, m.logsPane.View)
+	}
 
-		for i := 0; i < maxLines; i++ {
-			var chatLine, missionLine string
-			if i < len(chatLines) {
-				chatLine = chatLines[i]
-			}
-			if i < len(missionLines) {
-				missionLine = missionLines[i]
+	if len(sideViews) > 0 {
+		// Split view: chat on the left, side panes share the rest
+		chatWidth := int(float64(m.width) * m.layout.ChatRatio)
+		remaining := m.width - chatWidth - len(sideViews)
+
+		contents := []string{m.mainView(chatWidth, contentHeight-2)}
+		widths := []int{chatWidth}
+		for i, view := range sideViews {
+			paneWidth := remaining / len(sideViews)
+			if i == len(sideViews)-1 {
+				paneWidth = remaining - paneWidth*(len(sideViews)-1)
 			}
-
-			// Pad chat line to full width
-			chatLine = chatLine + strings.Repeat(" ", chatWidth-lipgloss.Width(chatLine))
-
-			sections = append(sections, chatLine+"│"+missionLine)
+			contents = append(contents, view(paneWidth, contentHeight-2))
+			widths = append(widths, paneWidth)
 		}
+		sections = append(sections, joinColumns(contents, widths)...)
 	} else {
 		// Full width chat view
-		sections = append(sections, m.chatView.View(m.width, contentHeight-2))
+		sections = append(sections, m.mainView(m.width, contentHeight-2))
 	}
 
 	// Input bar at bottom
@@ -170,16 +239,44 @@ func (m *Model) View() string {
 	return strings.Join(sections, "\n")
 }
 
+// mainView renders the chat, or the pending correction review in its place.
+// A pending operator question is pinned below the chat.
+func (m *Model) mainView(width, height int) string {
+	if m.review != nil {
+		return renderReview(m.review, len(m.reviewQueue), m.reviewEditing, width, height)
+	}
+	if m.question != nil {
+		card := renderQuestion(m.question, width)
+		chatHeight := max(1, height-lipgloss.Height(card))
+		return m.chatView.View(width, chatHeight) + "\n" + card
+	}
+	return m.chatView.View(width, height)
+}
+
 // updateLayout recalculates component sizes based on window size
 func (m *Model) updateLayout() {
 	// Components will use sizes passed in View() calls
 }
 
+// saveLayout persists the current pane preferences.
+func (m *Model) saveLayout() {
+	_ = m.layout.Save(m.layoutPath)
+}
+
+// toolActivityLine summarizes a tool message for the tools pane.
+func toolActivityLine(msg ChatMessageMsg) string {
+	summary := strings.TrimSpace(strings.SplitN(msg.Content, "\n", 2)[0])
+	if summary == "" && len(msg.Images) > 0 {
+		summary = fmt.Sprintf("%d image(s)", len(msg.Images))
+	}
+	return fmt.Sprintf("%s %s: %s", msg.Timestamp.Format("15:04:05"), msg.ToolName, summary)
+}
+
 // SetWorkflowEngine sets the workflow engine for mission tracking
 func (m *Model) SetWorkflowEngine(engine *workflow.Engine) {
 	m.workflowEngine = engine
 	if engine != nil {
-		m.showMissionPanel = true
+		m.showMissionPanel = !m.layout.HideMission
 		m.missionView.Update(engine)
 	}
 }
@@ -199,7 +296,9 @@ type ModelSwitchMsg struct {
 
 // CostUpdateMsg indicates session cost updated
 type CostUpdateMsg struct {
-	Total float64
+	Total        float64
+	Saved        float64 // Versus sending every call to the baseline tier
+	SavedPercent float64
 }
 
 // ProfileReadinessMsg indicates capability readiness counts.
@@ -213,10 +312,12 @@ type ChatMessageMsg struct {
 	Role      string // "user", "assistant", "tool"
 	Content   string
 	Timestamp time.Time
-	ToolName  string // For tool messages
+	ToolName  string   // For tool messages
+	Images    []string // Image files to preview inline (tool artifacts)
 }
 
-// WorkflowUpdateMsg indicates workflow state changed
+// WorkflowUpdateMsg indicates workflow state changed. Program sends one for
+// every engine event so the mission panel redraws as the mission moves.
 type WorkflowUpdateMsg struct{}
 
 // Helper to send messages to the TUI
@@ -228,6 +329,10 @@ func SendCostUpdate(total float64) tea.Msg {
 	return CostUpdateMsg{Total: total}
 }
 
+func SendCostSavings(total, saved, savedPercent float64) tea.Msg {
+	return CostUpdateMsg{Total: total, Saved: saved, SavedPercent: savedPercent}
+}
+
 func SendProfileReadiness(ready, total int) tea.Msg {
 	return ProfileReadinessMsg{Ready: ready, Total: total}
 }
@@ -241,6 +346,16 @@ func SendChatMessage(role, content, toolName string) tea.Msg {
 	}
 }
 
+// SendToolImages creates a tool message previewing the given image files.
+func SendToolImages(toolName string, paths []string) tea.Msg {
+	return ChatMessageMsg{
+		Role:      "tool",
+		Timestamp: time.Now(),
+		ToolName:  toolName,
+		Images:    paths,
+	}
+}
+
 func SendWorkflowUpdate() tea.Msg {
 	return WorkflowUpdateMsg{}
 }
@@ -249,12 +364,14 @@ func SendWorkflowUpdate() tea.Msg {
 type Program struct {
 	program *tea.Program
 	model   *Model
+
+	stopWorkflowEvents context.CancelFunc // Ends the mission event subscription
 }
 
 // NewProgram creates a new TUI program
 func NewProgram() *Program {
 	model := NewModel()
-	program := tea.NewProgram(model, tea.WithAltScreen())
+	program := newTeaProgram(model)
 
 	return &Program{
 		program: program,
@@ -266,7 +383,7 @@ func NewProgram() *Program {
 func NewProgramWithHandler(onSubmit func(string)) *Program {
 	model := NewModel()
 	model.inputBar.SetOnSubmit(onSubmit)
-	program := tea.NewProgram(model, tea.WithAltScreen())
+	program := newTeaProgram(model)
 
 	return &Program{
 		program: program,
@@ -274,9 +391,34 @@ func NewProgramWithHandler(onSubmit func(string)) *Program {
 	}
 }
 
+// newTeaProgram renders model to stdout and shares that output with the
+// chat view for kitty image transmissions
+func newTeaProgram(model *Model) *tea.Program {
+	out := &programOutput{File: os.Stdout}
+	model.chatView.terminal = out
+	return tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(out))
+}
+
+// programOutput serializes writes to the terminal so an image transmission
+// never lands inside a frame. It keeps the *os.File methods, so bubbletea
+// still detects the terminal and its size.
+type programOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (o *programOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
 // Run starts the TUI
 func (p *Program) Run() error {
 	_, err := p.program.Run()
+	if p.stopWorkflowEvents != nil {
+		p.stopWorkflowEvents()
+	}
 	return err
 }
 
@@ -285,9 +427,26 @@ func (p *Program) Send(msg tea.Msg) {
 	p.program.Send(msg)
 }
 
-// SetWorkflowEngine sets the workflow engine
+// SetWorkflowEngine sets the workflow engine and refreshes the mission
+// panel whenever its state changes
 func (p *Program) SetWorkflowEngine(engine *workflow.Engine) {
 	p.model.SetWorkflowEngine(engine)
+
+	if p.stopWorkflowEvents != nil {
+		p.stopWorkflowEvents()
+		p.stopWorkflowEvents = nil
+	}
+	if engine == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopWorkflowEvents = cancel
+	events := engine.Subscribe(ctx)
+	go func() {
+		for range events {
+			p.program.Send(SendWorkflowUpdate())
+		}
+	}()
 }
 
 // SetTierRouter sets the tier router
@@ -309,9 +468,18 @@ func (p *Program) SetInputHandler(handler func(string)) {
 
 // Quit quits the TUI
 func (p *Program) Quit() {
+	if p.stopWorkflowEvents != nil {
+		p.stopWorkflowEvents()
+	}
 	p.program.Quit()
 }
 
+// LogWriter returns a writer that feeds the logs pane. Point the standard
+// logger at it while the TUI runs so log lines don't tear the alt screen.
+func (p *Program) LogWriter() io.Writer {
+	return p.model.logsPane
+}
+
 // Printf sends a formatted message to the chat
 func (p *Program) Printf(format string, args ...interface{}) {
 	content := fmt.Sprintf(format, args...)
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// errQuestionDismissed is returned to the agent when the operator skips a question.
+var errQuestionDismissed = errors.New("question dismissed by operator")
+
+// OperatorQuestionMsg shows an ask_operator question as an answer card. The
+// answer is sent on reply exactly once.
+type OperatorQuestionMsg struct {
+	Question tools.OperatorQuestion
+	reply    chan operatorAnswer
+	invalid  string // validation error for the last typed answer
+}
+
+type operatorAnswer struct {
+	text string
+	err  error
+}
+
+// AskOperator shows q in the TUI and blocks until the operator answers or
+// dismisses it, or ctx is cancelled. Its signature matches tools.OperatorAsker.
+func (p *Program) AskOperator(ctx context.Context, q tools.OperatorQuestion) (string, error) {
+	reply := make(chan operatorAnswer, 1)
+	p.Send(OperatorQuestionMsg{Question: q, reply: reply})
+
+	select {
+	case a := <-reply:
+		return a.text, a.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// updateQuestion handles keys while a question is pending. Yes/no and choice
+// questions answer on a single key; text and number answers are typed into
+// the input bar and validated on enter. It reports whether the key was consumed.
+func (m *Model) updateQuestion(key string) bool {
+	q := m.question.Question
+
+	switch key {
+	case "esc":
+		m.resolveQuestion(operatorAnswer{err: errQuestionDismissed})
+		return true
+	case "enter":
+		answer, err := tools.NormalizeOperatorAnswer(q, m.inputBar.Value())
+		if err != nil {
+			m.question.invalid = err.Error()
+			return true
+		}
+		m.inputBar.Clear()
+		m.resolveQuestion(operatorAnswer{text: answer})
+		return true
+	}
+
+	switch q.AnswerType {
+	case tools.AnswerYesNo:
+		if answer, err := tools.NormalizeOperatorAnswer(q, key); err == nil {
+			m.resolveQuestion(operatorAnswer{text: answer})
+		}
+		return true // nothing else to type
+	case tools.AnswerChoice:
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(q.Choices) && m.inputBar.Value() == "" {
+			m.resolveQuestion(operatorAnswer{text: q.Choices[n-1]})
+			return true
+		}
+	}
+
+	m.question.invalid = ""
+	m.focusedView = "input"
+	return false // let the input bar edit the answer
+}
+
+func (m *Model) resolveQuestion(answer operatorAnswer) {
+	m.question.reply <- answer
+
+	content := "Operator answered: " + answer.text
+	if answer.err != nil {
+		content = "Operator dismissed the question"
+	}
+	m.chatView.AddMessage(ChatMessageMsg{
+		Role:      "system",
+		Content:   fmt.Sprintf("%s\n%s", m.question.Question.Question, content),
+		Timestamp: time.Now(),
+	})
+	m.question = nil
+}
+
+// renderQuestion draws the highlighted question card with the answer form for
+// its type.
+func renderQuestion(msg *OperatorQuestionMsg, width int) string {
+	q := msg.Question
+
+	cardStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(0, 1).
+		Width(max(10, width-2))
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86")).
+		Bold(true)
+
+	errStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
+	inner := width - 6
+	lines := []string{titleStyle.Render("❓ The agent needs your input")}
+	lines = append(lines, wordWrap(q.Question, inner)...)
+	if q.Context != "" {
+		for _, l := range wordWrap(q.Context, inner) {
+			lines = append(lines, dimStyle.Render(l))
+		}
+	}
+	lines = append(lines, "")
+
+	var form string
+	switch q.AnswerType {
+	case tools.AnswerYesNo:
+		form = keyStyle.Render("[y]") + " yes  " + keyStyle.Render("[n]") + " no"
+	case tools.AnswerChoice:
+		for i, c := range q.Choices {
+			lines = append(lines, keyStyle.Render(fmt.Sprintf("[%d]", i+1))+" "+c)
+		}
+		form = "Press a number or type a choice, " + keyStyle.Render("enter") + " to answer"
+	case tools.AnswerNumber:
+		form = "Type a number below, " + keyStyle.Render("enter") + " to answer"
+	default:
+		form = "Type your answer below, " + keyStyle.Render("enter") + " to answer"
+	}
+	lines = append(lines, form+"  "+keyStyle.Render("esc")+" skip")
+	if msg.invalid != "" {
+		lines = append(lines, errStyle.Render(msg.invalid))
+	}
+
+	return cardStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/routing"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CorrectionReviewMsg asks the operator to approve a supervisor correction.
+// The decision is sent on reply exactly once.
+type CorrectionReviewMsg struct {
+	Review routing.CorrectionReview
+	reply  chan routing.CorrectionDecision
+}
+
+// ReviewCorrection shows a supervisor correction in the TUI and blocks until
+// the operator accepts, rejects, or edits it. If ctx is cancelled first the
+// review is dismissed. Its signature matches routing.CorrectionReviewer.
+func (p *Program) ReviewCorrection(ctx context.Context, review routing.CorrectionReview) routing.CorrectionDecision {
+	reply := make(chan routing.CorrectionDecision, 1)
+	p.Send(CorrectionReviewMsg{Review: review, reply: reply})
+
+	select {
+	case decision := <-reply:
+		return decision
+	case <-ctx.Done():
+		return routing.CorrectionDecision{Action: routing.ReviewDismissed}
+	}
+}
+
+// updateReview handles keys while a correction review is pending. It
+// reports whether the key was consumed.
+func (m *Model) updateReview(key string) bool {
+	if m.reviewEditing {
+		switch key {
+		case "enter":
+			m.resolveReview(routing.CorrectionDecision{Action: routing.ReviewEdited, Output: m.inputBar.Value()})
+			m.inputBar.Clear()
+			return true
+		case "esc":
+			m.reviewEditing = false
+			m.inputBar.Clear()
+			return true
+		}
+		return false // let the input bar edit the text
+	}
+
+	switch key {
+	case "a", "y":
+		m.resolveReview(routing.CorrectionDecision{Action: routing.ReviewAccepted})
+	case "r", "n":
+		m.resolveReview(routing.CorrectionDecision{Action: routing.ReviewRejected})
+	case "e":
+		m.reviewEditing = true
+		m.focusedView = "input"
+		m.inputBar.setValue(m.review.Review.CorrectedOutput)
+	}
+	return true
+}
+
+// resolveReview answers the shown review and shows the next queued one
+func (m *Model) resolveReview(decision routing.CorrectionDecision) {
+	m.review.reply <- decision
+	m.chatView.AddMessage(ChatMessageMsg{
+		Role:      "system",
+		Content:   fmt.Sprintf("Supervisor correction %s", decision.Action),
+		Timestamp: time.Now(),
+	})
+	m.review = nil
+	m.reviewEditing = false
+	if len(m.reviewQueue) > 0 {
+		m.review = m.reviewQueue[0]
+		m.reviewQueue = m.reviewQueue[1:]
+	}
+}
+
+// dismissReviews answers the shown review and every queued one as
+// dismissed, so quitting never approves a correction
+func (m *Model) dismissReviews() {
+	for m.review != nil {
+		m.resolveReview(routing.CorrectionDecision{Action: routing.ReviewDismissed})
+	}
+}
+
+// renderReview draws the correction card: corrections followed by a diff of
+// the worker output against the corrected output. queued is the number of
+// reviews waiting behind this one.
+func renderReview(msg *CorrectionReviewMsg, queued int, editing bool, width, height int) string {
+	r := msg.Review
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	removedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
+	addedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("42"))
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86")).
+		Bold(true)
+
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("Supervisor correction · %s", r.Task)),
+		dimStyle.Render(fmt.Sprintf("worker %s → supervisor %s", r.WorkerModel, r.SupervisorModel)),
+		"",
+	}
+	for _, c := range r.Corrections {
+		lines = append(lines, wordWrap("• "+c, width-2)...)
+	}
+	if len(r.Corrections) > 0 {
+		lines = append(lines, "")
+	}
+
+	var diff []string
+	for _, d := range lineDiff(r.WorkerOutput, r.CorrectedOutput) {
+		style := dimStyle
+		switch d.op {
+		case '-':
+			style = removedStyle
+		case '+':
+			style = addedStyle
+		}
+		wrapped := wordWrap(d.text, width-4)
+		if len(wrapped) == 0 {
+			wrapped = []string{""}
+		}
+		for _, w := range wrapped {
+			diff = append(diff, style.Render(fmt.Sprintf("%c %s", d.op, w)))
+		}
+	}
+
+	footer := keyStyle.Render("[a]") + " accept  " + keyStyle.Render("[r]") + " reject  " + keyStyle.Render("[e]") + " edit"
+	if queued > 0 {
+		footer += dimStyle.Render(fmt.Sprintf("  · %d more waiting", queued))
+	}
+	if editing {
+		footer = "Editing corrected output below · " + keyStyle.Render("enter") + " save  " + keyStyle.Render("esc") + " back"
+	}
+
+	// Keep the footer visible; trim the diff to the space left.
+	room := max(1, height-len(lines)-2)
+	if len(diff) > room {
+		diff = append(diff[:room-1], dimStyle.Render(fmt.Sprintf("… %d more lines", len(diff)-room+1)))
+	}
+	lines = append(lines, diff...)
+	lines = append(lines, "", footer)
+	return strings.Join(lines, "\n")
+}
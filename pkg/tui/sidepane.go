@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sidePaneMaxLines bounds the scrollback kept by a side pane.
+const sidePaneMaxLines = 500
+
+// SidePane is a titled, append-only list of lines (tool activity or logs).
+// It is safe for concurrent use so log output can be appended from any
+// goroutine without going through the tea event loop.
+type SidePane struct {
+	title string
+	mu    sync.Mutex
+	lines []string
+}
+
+// NewSidePane creates an empty side pane with the given title.
+func NewSidePane(title string) *SidePane {
+	return &SidePane{title: title}
+}
+
+// Append adds a line, dropping the oldest once the scrollback is full.
+func (s *SidePane) Append(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, line)
+	if len(s.lines) > sidePaneMaxLines {
+		s.lines = s.lines[len(s.lines)-sidePaneMaxLines:]
+	}
+}
+
+// Write implements io.Writer, appending each non-empty line of p.
+func (s *SidePane) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line != "" {
+			s.Append(line)
+		}
+	}
+	return len(p), nil
+}
+
+// View renders the most recent lines that fit in width x height.
+func (s *SidePane) View(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("170")).
+		Bold(true).
+		Underline(true)
+
+	lineStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("250"))
+
+	out := []string{titleStyle.Render(s.title), ""}
+
+	s.mu.Lock()
+	var wrapped []string
+	for _, line := range s.lines {
+		if lipgloss.Width(line) > width-1 {
+			wrapped = append(wrapped, wordWrap(line, width-1)...)
+		} else {
+			wrapped = append(wrapped, line)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(wrapped) == 0 {
+		out = append(out, lineStyle.Foreground(lipgloss.Color("240")).Render("Nothing yet"))
+		return strings.Join(out, "\n")
+	}
+
+	room := max(1, height-len(out))
+	if len(wrapped) > room {
+		wrapped = wrapped[len(wrapped)-room:]
+	}
+	for _, line := range wrapped {
+		out = append(out, lineStyle.Render(line))
+	}
+	return strings.Join(out, "\n")
+}
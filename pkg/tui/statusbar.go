@@ -12,6 +12,8 @@ type StatusBar struct {
 	model         string
 	tier          string
 	cost          float64
+	saved         float64
+	savedPercent  float64
 	profilesReady int
 	profilesTotal int
 }
@@ -36,6 +38,12 @@ func (s *StatusBar) SetCost(cost float64) {
 	s.cost = cost
 }
 
+// SetSavings sets how much routing saved versus the baseline tier
+func (s *StatusBar) SetSavings(saved, percent float64) {
+	s.saved = saved
+	s.savedPercent = percent
+}
+
 // SetProfileReadiness sets capability readiness counts.
 func (s *StatusBar) SetProfileReadiness(ready, total int) {
 	s.profilesReady = ready
@@ -67,6 +75,9 @@ func (s *StatusBar) View(width int) string {
 	}
 
 	costText := fmt.Sprintf("Cost: $%.4f", s.cost)
+	if s.saved > 0 {
+		costText += fmt.Sprintf(" · saved $%.2f (%.0f%%)", s.saved, s.savedPercent)
+	}
 	readinessText := "Capabilities: n/a"
 	if s.profilesTotal > 0 {
 		readinessText = fmt.Sprintf("Capabilities: %d/%d", s.profilesReady, s.profilesTotal)
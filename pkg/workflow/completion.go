@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/llmjson"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// CompletionJudge sends a completion prompt to a model and returns its
+// answer
+type CompletionJudge func(ctx context.Context, prompt string) (string, error)
+
+// CompletionVerdict is a model's judgement of whether custom completion
+// criteria are met
+type CompletionVerdict struct {
+	Complete   bool    `json:"complete"`
+	Confidence float64 `json:"confidence"` // 0.0-1.0
+	Reason     string  `json:"reason"`
+	Accepted   bool    `json:"-"` // Complete with enough confidence for the evaluator
+}
+
+const (
+	DefaultCompletionConfidence = 0.8 // Verdicts below it don't count
+	maxCompletionVerdicts       = 256 // Cached verdicts per evaluator
+)
+
+// CompletionEvaluator asks a model whether a phase's custom completion
+// criteria, which IsPhaseComplete can't check, are met by the mission so
+// far. Verdicts are cached per mission state, so asking again before
+// anything changed costs nothing.
+type CompletionEvaluator struct {
+	judge         CompletionJudge
+	minConfidence float64
+
+	mu       sync.Mutex
+	verdicts map[string]CompletionVerdict // By state hash
+}
+
+// NewCompletionEvaluator creates an evaluator that counts a phase as
+// complete when judge says so with at least minConfidence; 0 means
+// DefaultCompletionConfidence
+func NewCompletionEvaluator(judge CompletionJudge, minConfidence float64) *CompletionEvaluator {
+	if minConfidence <= 0 {
+		minConfidence = DefaultCompletionConfidence
+	}
+	return &CompletionEvaluator{
+		judge:         judge,
+		minConfidence: minConfidence,
+		verdicts:      make(map[string]CompletionVerdict),
+	}
+}
+
+// Evaluate judges the custom completion criteria of the engine's current
+// phase; the phase counts as complete if the verdict is Accepted. Phases
+// with any other kind of criteria are left to IsPhaseComplete and get a
+// nil verdict.
+func (ev *CompletionEvaluator) Evaluate(ctx context.Context, e *Engine) (*CompletionVerdict, error) {
+	prompt, phase, ok := e.completionPrompt()
+	if !ok {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(prompt))
+	key := hex.EncodeToString(sum[:])
+
+	ev.mu.Lock()
+	verdict, cached := ev.verdicts[key]
+	ev.mu.Unlock()
+
+	if !cached {
+		answer, err := ev.judge(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("completion judge failed: %w", err)
+		}
+		if err := llmjson.Decode(answer, &verdict); err != nil {
+			return nil, fmt.Errorf("completion judge gave no verdict: %w", err)
+		}
+		ev.mu.Lock()
+		if len(ev.verdicts) >= maxCompletionVerdicts {
+			clear(ev.verdicts)
+		}
+		ev.verdicts[key] = verdict
+		ev.mu.Unlock()
+	}
+
+	verdict.Accepted = verdict.Complete && verdict.Confidence >= ev.minConfidence
+	logger.InfoCF(e.component, "Completion judged", map[string]any{
+		"phase":      phase,
+		"complete":   verdict.Complete,
+		"confidence": verdict.Confidence,
+		"accepted":   verdict.Accepted,
+		"cached":     cached,
+	})
+	return &verdict, nil
+}
+
+// completionPrompt describes the current phase's custom completion criteria
+// and what the mission has done towards them. It reports false unless the
+// phase has custom criteria.
+func (e *Engine) completionPrompt() (prompt, phaseName string, ok bool) {
+	if e.workflow == nil {
+		return "", "", false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return "", "", false
+	}
+	phase := e.workflow.Phases[e.state.CurrentPhase]
+	if phase.Completion.Type != CompletionCustom {
+		return "", "", false
+	}
+	vars := e.templateVars()
+	exec := e.getCurrentPhaseExecution()
+
+	var sb strings.Builder
+	sb.WriteString("Decide whether a penetration test phase is complete. Judge only from the mission state below; ")
+	sb.WriteString("if it doesn't show that the criteria are met, the phase is not complete.\n")
+	sb.WriteString(`Answer with only a JSON object: {"complete": true or false, "confidence": 0.0 to 1.0, "reason": "one sentence"}` + "\n\n")
+	fmt.Fprintf(&sb, "Workflow: %s\n", e.workflow.Name)
+	if e.state.Target != "" {
+		fmt.Fprintf(&sb, "Target: %s\n", e.state.Target)
+	}
+	fmt.Fprintf(&sb, "Phase: %s\n", phase.Name)
+	fmt.Fprintf(&sb, "Completion criteria: %s\n", expandTemplate(phase.Completion.Description, vars))
+
+	if len(phase.Steps) > 0 {
+		sb.WriteString("\nSteps:\n")
+		for _, step := range phase.Steps {
+			mark := "[ ]"
+			if exec != nil && e.isStepComplete(step.ID, exec) {
+				mark = "[x]"
+			}
+			fmt.Fprintf(&sb, "- %s %s\n", mark, expandTemplate(step.Name, vars))
+		}
+	}
+	if exec != nil && len(exec.Notes) > 0 {
+		sb.WriteString("\nNotes from this phase:\n")
+		for _, note := range exec.Notes {
+			sb.WriteString("- " + note + "\n")
+		}
+	}
+	if len(e.state.ActiveBranches) > 0 {
+		sb.WriteString("\nBranches:\n")
+		for _, branch := range e.state.ActiveBranches {
+			status := "active"
+			if branch.CompletedAt != nil {
+				status = "complete"
+			}
+			fmt.Fprintf(&sb, "- %s: %s (%s)\n", branch.Condition, branch.Description, status)
+		}
+	}
+	if len(e.state.Variables) > 0 {
+		sb.WriteString("\nVariables:\n")
+		names := make([]string, 0, len(e.state.Variables))
+		for name := range e.state.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&sb, "- %s = %v\n", name, e.state.Variables[name])
+		}
+	}
+	sb.WriteString("\nFindings:\n")
+	sb.WriteString(findingsOverview(e.state.Findings))
+	return sb.String(), phase.Name, true
+}
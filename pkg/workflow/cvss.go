@@ -0,0 +1,160 @@
+package workflow
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// cvss3Weights are the CVSS 3.x base metric weights. PR's weights depend on
+// scope and are handled separately.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+var cvss3Privileges = map[string][2]float64{ // Scope unchanged, changed
+	"N": {0.85, 0.85},
+	"L": {0.62, 0.68},
+	"H": {0.27, 0.5},
+}
+
+// CVSSBaseScore computes the base score of a CVSS 3.0 or 3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". Temporal and environmental
+// metrics may be present but are ignored. CVSS 4.0 vectors can't be scored
+// here; pass their score along with them.
+func CVSSBaseScore(vector string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(vector), "/")
+	switch parts[0] {
+	case "CVSS:3.0", "CVSS:3.1":
+	case "CVSS:4.0":
+		return 0, fmt.Errorf("CVSS 4.0 vectors can't be scored automatically; give the score too")
+	default:
+		return 0, fmt.Errorf("invalid CVSS vector %q (want e.g. CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H)", vector)
+	}
+
+	metrics := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("invalid CVSS metric %q", part)
+		}
+		if _, dup := metrics[name]; dup {
+			return 0, fmt.Errorf("CVSS metric %s given twice", name)
+		}
+		metrics[name] = value
+	}
+
+	weight := make(map[string]float64, len(cvss3Weights))
+	for name, values := range cvss3Weights {
+		w, ok := values[metrics[name]]
+		if !ok {
+			return 0, fmt.Errorf("CVSS vector is missing or has an invalid %s metric", name)
+		}
+		weight[name] = w
+	}
+	changed := false
+	switch metrics["S"] {
+	case "U":
+	case "C":
+		changed = true
+	default:
+		return 0, fmt.Errorf("CVSS vector is missing or has an invalid S metric")
+	}
+	pr, ok := cvss3Privileges[metrics["PR"]]
+	if !ok {
+		return 0, fmt.Errorf("CVSS vector is missing or has an invalid PR metric")
+	}
+	privileges := pr[0]
+	if changed {
+		privileges = pr[1]
+	}
+
+	iss := 1 - (1-weight["C"])*(1-weight["I"])*(1-weight["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * weight["AV"] * weight["AC"] * privileges * weight["UI"]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp rounds up to one decimal the way CVSS 3.1 specifies, avoiding
+// floating point errors such as 4.000000001 becoming 4.1
+func cvssRoundUp(x float64) float64 {
+	n := int(math.Round(x * 100000))
+	if n%10000 == 0 {
+		return float64(n) / 100000
+	}
+	return float64(n/10000+1) / 10
+}
+
+// CVSSSeverity is the qualitative rating of a CVSS score
+func CVSSSeverity(score float64) Severity {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+	return SeverityInformational
+}
+
+// RiskScore ranks the finding: its CVSS score, or a score in the middle of
+// its severity's CVSS range when it has none
+func (f Finding) RiskScore() float64 {
+	if f.CVSSScore > 0 {
+		return f.CVSSScore
+	}
+	switch f.Severity {
+	case SeverityCritical:
+		return 9.5
+	case SeverityHigh:
+		return 8.0
+	case SeverityMedium:
+		return 5.5
+	case SeverityLow:
+		return 2.0
+	}
+	return 0
+}
+
+// scoreFinding checks a finding's CVSS details, scores 3.x vectors, and
+// rates findings that have a score but no severity
+func scoreFinding(f *Finding) error {
+	if f.CVSSScore < 0 || f.CVSSScore > 10 {
+		return fmt.Errorf("invalid CVSS score %v (want 0.0 to 10.0)", f.CVSSScore)
+	}
+	f.CVSSVector = strings.TrimSpace(f.CVSSVector)
+	if f.CVSSVector != "" {
+		score, err := CVSSBaseScore(f.CVSSVector)
+		switch {
+		case err == nil:
+			f.CVSSScore = score
+		case strings.HasPrefix(f.CVSSVector, "CVSS:4.0/") && f.CVSSScore > 0:
+		default:
+			return err
+		}
+	}
+	if f.Severity == "" {
+		if f.CVSSScore == 0 && f.CVSSVector == "" {
+			return fmt.Errorf("a finding needs a severity or a CVSS score")
+		}
+		f.Severity = CVSSSeverity(f.CVSSScore)
+	}
+	return nil
+}
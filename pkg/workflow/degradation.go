@@ -0,0 +1,262 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ToolFallback is a tool that can stand in for a missing one, with a hint on
+// how to use it for the same job
+type ToolFallback struct {
+	Tool string `json:"tool"`
+	Hint string `json:"hint,omitempty"`
+}
+
+// FallbackMatrix maps a tool to its stand-ins, best first. A tool with an
+// empty list has none, so the agent is told to stop trying it.
+type FallbackMatrix map[string][]ToolFallback
+
+// DefaultFallbacks is the built-in matrix for the tools the bundled
+// workflows lean on
+func DefaultFallbacks() FallbackMatrix {
+	return FallbackMatrix{
+		"nuclei": {
+			{Tool: "nmap", Hint: "`nmap -sV --script vuln <target>` covers known CVEs for the services it identifies"},
+		},
+		"chromium": {
+			{Tool: "web_fetch", Hint: "plain HTTP fetches don't run JavaScript, so pages rendered client-side look empty"},
+			{Tool: "curl", Hint: "`curl -sL <url>` fetches the raw page; JavaScript doesn't run"},
+		},
+		"katana": {
+			{Tool: "web_fetch", Hint: "follow links by hand; routes only built by JavaScript stay hidden"},
+		},
+		"ffuf": {
+			{Tool: "gobuster", Hint: "`gobuster dir -u <url> -w <wordlist>`"},
+			{Tool: "feroxbuster", Hint: "`feroxbuster -u <url> -w <wordlist>`"},
+			{Tool: "dirsearch", Hint: "`dirsearch -u <url> -w <wordlist>`"},
+		},
+		"gobuster": {
+			{Tool: "ffuf", Hint: "`ffuf -u <url>/FUZZ -w <wordlist>`"},
+			{Tool: "feroxbuster", Hint: "`feroxbuster -u <url> -w <wordlist>`"},
+		},
+		"subfinder": {
+			{Tool: "amass", Hint: "`amass enum -passive -d <domain>`"},
+			{Tool: "assetfinder", Hint: "`assetfinder --subs-only <domain>`"},
+		},
+		"amass": {
+			{Tool: "subfinder", Hint: "`subfinder -d <domain>`"},
+			{Tool: "assetfinder", Hint: "`assetfinder --subs-only <domain>`"},
+		},
+		"httpx": {
+			{Tool: "httprobe", Hint: "`cat hosts.txt | httprobe` finds live hosts but not titles or technologies"},
+			{Tool: "curl", Hint: "`curl -sI <url>` one host at a time"},
+		},
+		"masscan": {
+			{Tool: "naabu", Hint: "`naabu -host <target>`"},
+			{Tool: "nmap", Hint: "`nmap -T4 --top-ports 1000 <target>` is slower on large ranges"},
+		},
+		"naabu": {
+			{Tool: "nmap", Hint: "`nmap -T4 --top-ports 1000 <target>`"},
+		},
+		"rustscan": {
+			{Tool: "nmap", Hint: "`nmap -T4 -p- <target>`"},
+		},
+		"sslyze": {
+			{Tool: "sslscan", Hint: "`sslscan <host:port>`"},
+			{Tool: "nmap", Hint: "`nmap --script ssl-enum-ciphers -p 443 <target>`"},
+		},
+		"testssl": {
+			{Tool: "sslscan", Hint: "`sslscan <host:port>`"},
+			{Tool: "nmap", Hint: "`nmap --script ssl-enum-ciphers -p 443 <target>`"},
+		},
+		"whatweb": {
+			{Tool: "httpx", Hint: "`httpx -u <url> -tech-detect`"},
+			{Tool: "curl", Hint: "`curl -sI <url>` and read the Server and X-Powered-By headers"},
+		},
+		"nikto": {
+			{Tool: "nuclei", Hint: "`nuclei -u <url> -tags misconfig,exposure`"},
+		},
+		"wpscan": {
+			{Tool: "nuclei", Hint: "`nuclei -u <url> -tags wordpress`"},
+		},
+	}
+}
+
+// Merge returns m with the tools in overrides replaced by their lists there
+func (m FallbackMatrix) Merge(overrides FallbackMatrix) FallbackMatrix {
+	merged := make(FallbackMatrix, len(m)+len(overrides))
+	for tool, fallbacks := range m {
+		merged[tool] = fallbacks
+	}
+	for tool, fallbacks := range overrides {
+		merged[strings.ToLower(tool)] = fallbacks
+	}
+	return merged
+}
+
+// MissingTool is a tool the mission needs that isn't available
+type MissingTool struct {
+	Tool     string
+	Steps    []string      // IDs of the current phase's steps that use it
+	Fallback *ToolFallback // First stand-in that is available and allowed; nil if none is
+}
+
+// Advice tells the agent what to do instead of running the missing tool
+func (m MissingTool) Advice() string {
+	if m.Fallback == nil {
+		return fmt.Sprintf("**%s** is not available and nothing stands in for it. Don't retry it; cover the step by other means or skip it and note why.", m.Tool)
+	}
+	advice := fmt.Sprintf("**%s** is not available. Use **%s** instead", m.Tool, m.Fallback.Tool)
+	if m.Fallback.Hint != "" {
+		advice += ": " + m.Fallback.Hint
+	}
+	return strings.TrimSuffix(advice, ".") + ". Coverage is reduced, so say so in findings and notes."
+}
+
+// SetToolFallbacks lets the mission notice missing tools: available reports
+// whether a tool (registered or on the PATH) can be used, and matrix names
+// what to use instead. Without it the mission assumes every tool exists.
+func (e *Engine) SetToolFallbacks(matrix FallbackMatrix, available func(string) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fallbacks = matrix
+	e.toolAvailable = available
+}
+
+// MissingTools returns the unavailable tools the current phase's incomplete
+// steps use, in name order. A step uses the tools listed under it, or else
+// the tools of the fallback matrix its name or description mentions.
+func (e *Engine) MissingTools() []MissingTool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.missingTools()
+}
+
+// CheckTools returns the ones among names that aren't available, such as
+// the commands of a failed exec call
+func (e *Engine) CheckTools(names ...string) []MissingTool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.toolAvailable == nil {
+		return nil
+	}
+
+	var missing []MissingTool
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if seen[name] || e.toolAvailable(name) {
+			continue
+		}
+		seen[name] = true
+		missing = append(missing, MissingTool{Tool: name, Fallback: e.fallbackFor(name)})
+	}
+	return missing
+}
+
+func (e *Engine) missingTools() []MissingTool {
+	if e.toolAvailable == nil || e.workflow == nil || e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return nil
+	}
+	phase := e.workflow.Phases[e.state.CurrentPhase]
+	exec := e.getCurrentPhaseExecution()
+
+	steps := make(map[string][]string)
+	for _, step := range phase.Steps {
+		if e.isStepComplete(step.ID, exec) {
+			continue
+		}
+		for _, tool := range e.stepTools(step) {
+			steps[tool] = append(steps[tool], step.ID)
+		}
+	}
+
+	tools := make([]string, 0, len(steps))
+	for tool := range steps {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	var missing []MissingTool
+	for _, tool := range tools {
+		if e.toolAvailable(tool) {
+			continue
+		}
+		missing = append(missing, MissingTool{Tool: tool, Steps: steps[tool], Fallback: e.fallbackFor(tool)})
+	}
+	return missing
+}
+
+// stepTools returns the tools step uses
+func (e *Engine) stepTools(step Step) []string {
+	if len(step.Tools) > 0 {
+		tools := make([]string, len(step.Tools))
+		for i, tool := range step.Tools {
+			tools[i] = strings.ToLower(tool)
+		}
+		return tools
+	}
+
+	var tools []string
+	text := strings.ToLower(step.Name + " " + step.Description)
+	for tool := range e.fallbacks {
+		if mentionsTool(text, tool) {
+			tools = append(tools, tool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// mentionsTool reports whether text names tool as a whole word, so httpx
+// isn't found in "httpx-toolkit" but nmap is in "`nmap -sV`"
+func mentionsTool(text, tool string) bool {
+	re := regexp.MustCompile(`(^|[^a-z0-9_-])` + regexp.QuoteMeta(tool) + `($|[^a-z0-9_-])`)
+	return re.MatchString(text)
+}
+
+// fallbackFor returns tool's first stand-in that is available and that the
+// mission's tool policy doesn't deny
+func (e *Engine) fallbackFor(tool string) *ToolFallback {
+	for _, fallback := range e.fallbacks[tool] {
+		if !e.toolAvailable(fallback.Tool) || e.deniedTool(fallback.Tool) {
+			continue
+		}
+		found := fallback
+		return &found
+	}
+	return nil
+}
+
+// deniedTool reports whether the workflow or the current phase denies tool
+func (e *Engine) deniedTool(tool string) bool {
+	policies := []ToolPolicy{e.workflow.Tools}
+	if e.state.CurrentPhase < len(e.workflow.Phases) {
+		policies = append(policies, e.workflow.Phases[e.state.CurrentPhase].policies()...)
+	}
+	for _, policy := range policies {
+		if policy.denies(tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingToolsPrompt tells the agent which of the phase's tools are missing
+// and what to use instead, so steps don't fail over and over
+func (e *Engine) missingToolsPrompt() string {
+	missing := e.missingTools()
+	if len(missing) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Missing Tools\n")
+	for _, m := range missing {
+		sb.WriteString(fmt.Sprintf("- %s (steps: %s)\n", m.Advice(), strings.Join(m.Steps, ", ")))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
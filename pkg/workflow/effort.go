@@ -0,0 +1,200 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OperatorIdleTimeout is the longest gap between the agent's reply and the
+// operator's next message that still counts as operator time. Longer gaps
+// are treated as the operator being away.
+const OperatorIdleTimeout = 15 * time.Minute
+
+// PhaseEffort is the time spent in one phase of a mission
+type PhaseEffort struct {
+	Phase    string
+	Operator time.Duration // Operator reading, typing and answering questions
+	Agent    time.Duration // Agent working on its own
+	Cost     float64       // Model spend in USD
+	Tokens   int           // Model tokens, input and output
+}
+
+// Total is the combined operator and agent time
+func (p PhaseEffort) Total() time.Duration {
+	return p.Operator + p.Agent
+}
+
+// Effort returns the time and model spend per phase in the order phases
+// were first entered. A phase entered more than once is reported once with
+// its totals added up.
+func (s *MissionState) Effort() []PhaseEffort {
+	var efforts []PhaseEffort
+	index := make(map[string]int)
+	for _, exec := range s.PhaseHistory {
+		i, ok := index[exec.PhaseName]
+		if !ok {
+			i = len(efforts)
+			index[exec.PhaseName] = i
+			efforts = append(efforts, PhaseEffort{Phase: exec.PhaseName})
+		}
+		efforts[i].Operator += exec.OperatorTime
+		efforts[i].Agent += exec.AgentTime
+		efforts[i].Cost += exec.CostUSD
+		efforts[i].Tokens += exec.Tokens
+	}
+	return efforts
+}
+
+// TotalEffort adds up the time and spend across all phases
+func TotalEffort(efforts []PhaseEffort) PhaseEffort {
+	total := PhaseEffort{Phase: "Total"}
+	for _, e := range efforts {
+		total.Operator += e.Operator
+		total.Agent += e.Agent
+		total.Cost += e.Cost
+		total.Tokens += e.Tokens
+	}
+	return total
+}
+
+// ModelReviews counts how often the supervisor checked and rejected a
+// worker model's output
+type ModelReviews struct {
+	Reviewed int `json:"reviewed"`
+	Rejected int `json:"rejected"`
+}
+
+// RejectionRate is the share of reviewed outputs that were rejected
+func (r ModelReviews) RejectionRate() float64 {
+	if r.Reviewed == 0 {
+		return 0
+	}
+	return float64(r.Rejected) / float64(r.Reviewed)
+}
+
+// FormatEffortDuration renders d as hours and minutes, e.g. "1h05m"
+func FormatEffortDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// OperatorMessage records that the operator sent a message at. The gap
+// since the agent last replied counts as operator time unless it is longer
+// than OperatorIdleTimeout.
+func (e *Engine) OperatorMessage(at time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	last := e.lastActivity
+	e.lastActivity = at
+	gap := at.Sub(last)
+	if last.IsZero() || gap <= 0 || gap > OperatorIdleTimeout {
+		return nil
+	}
+	exec := e.getCurrentPhaseExecution()
+	if exec == nil {
+		return nil
+	}
+	exec.OperatorTime += gap
+	return e.saveState()
+}
+
+// OperatorWaited records time the agent spent blocked on the operator, such
+// as an ask_operator question. It counts as operator time and is taken out
+// of the agent time of the turn it happened in.
+func (e *Engine) OperatorWaited(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.operatorWait += d
+	exec := e.getCurrentPhaseExecution()
+	if exec == nil {
+		return nil
+	}
+	exec.OperatorTime += d
+	return e.saveState()
+}
+
+// AgentTurn records an agent turn that ran from start to end. The time goes
+// to the phase the mission is in when the turn ends.
+func (e *Engine) AgentTurn(start, end time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d := end.Sub(start) - e.operatorWait
+	e.operatorWait = 0
+	e.lastActivity = end
+	exec := e.getCurrentPhaseExecution()
+	if exec == nil || d <= 0 {
+		return nil
+	}
+	exec.AgentTime += d
+	return e.saveState()
+}
+
+// RecordCost adds model spend to the phase the mission is in
+func (e *Engine) RecordCost(usd float64, tokens int) error {
+	if usd <= 0 && tokens <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	exec := e.getCurrentPhaseExecution()
+	if exec == nil {
+		return nil
+	}
+	exec.CostUSD += usd
+	exec.Tokens += tokens
+	return e.saveState()
+}
+
+// RecordReviews adds supervisor verdicts, by worker model, to the mission
+func (e *Engine) RecordReviews(reviews map[string]ModelReviews) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Reviews == nil {
+		e.state.Reviews = make(map[string]ModelReviews)
+	}
+	for model, r := range reviews {
+		total := e.state.Reviews[model]
+		total.Reviewed += r.Reviewed
+		total.Rejected += r.Rejected
+		e.state.Reviews[model] = total
+	}
+	return e.saveState()
+}
+
+// writeEffortTable writes a Markdown table of the time spent per phase
+func writeEffortTable(sb *strings.Builder, efforts []PhaseEffort, loc *ReportLocale) {
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n|---|---|---|---|\n", loc.Phase, loc.Operator, loc.Agent, loc.Total))
+	total := TotalEffort(efforts)
+	total.Phase = loc.Total
+	for _, e := range append(efforts, total) {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", e.Phase,
+			FormatEffortDuration(e.Operator), FormatEffortDuration(e.Agent), FormatEffortDuration(e.Total())))
+	}
+	sb.WriteString("\n")
+}
+
+// writeCostTable writes a Markdown table of the model spend per phase
+func writeCostTable(sb *strings.Builder, efforts []PhaseEffort, loc *ReportLocale) {
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n|---|---|---|\n", loc.Phase, loc.Cost, loc.Tokens))
+	total := TotalEffort(efforts)
+	total.Phase = loc.Total
+	for _, e := range append(efforts, total) {
+		sb.WriteString(fmt.Sprintf("| %s | $%.2f | %d |\n", e.Phase, e.Cost, e.Tokens))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,390 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Engagement is everything needed to start a client mission consistently,
+// kept in one engagement.yaml:
+//
+//	client: ACME Corp
+//	id: ACME-2026-014
+//	workflow: network-scan
+//	target: 10.0.0.0/24
+//	scope:
+//	  include: [10.0.1.0/24, "*.acme.example"]
+//	  exclude: [10.0.0.1]
+//	roe:
+//	  authorization: SOW-2026-014 signed by J. Doe
+//	  rules: [No denial of service, No social engineering]
+//	  deny_tools: [profile:fuzz]
+//	schedule:
+//	  start: 2026-10-19
+//	  end: 2026-10-30
+//	  days: [mon, tue, wed, thu, fri]
+//	  hours: "09:00-17:00"
+//	  timezone: Europe/London
+//	budget:
+//	  max_cost_usd: 40
+//	report:
+//	  template: templates/acme.md
+type Engagement struct {
+	Client   string             `yaml:"client"`
+	ID       string             `yaml:"id"`
+	Workflow string             `yaml:"workflow"`
+	Target   string             `yaml:"target"`
+	Scope    EngagementScope    `yaml:"scope"`
+	ROE      RulesOfEngagement  `yaml:"roe"`
+	Schedule EngagementSchedule `yaml:"schedule"`
+	Budget   EngagementBudget   `yaml:"budget"`
+	Report   EngagementReport   `yaml:"report"`
+	Kickoff  string             `yaml:"kickoff"` // First message to the agent; a default is used when empty
+
+	location *time.Location
+	from, to int // Daily window in minutes after midnight; from == to means all day
+}
+
+// EngagementScope lists scope entries on top of the mission target
+type EngagementScope struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// RulesOfEngagement are the client's constraints on the testing
+type RulesOfEngagement struct {
+	Authorization string   `yaml:"authorization"` // Who authorized the testing, e.g. a signed SOW reference
+	Contacts      []string `yaml:"contacts"`      // Who to call when something breaks
+	Rules         []string `yaml:"rules"`
+	DenyTools     []string `yaml:"deny_tools"` // Added to the workflow's tool deny list
+}
+
+// EngagementSchedule is the testing window. Dates are inclusive; days and
+// hours restrict testing to a daily window in the timezone.
+type EngagementSchedule struct {
+	Start    string   `yaml:"start"` // YYYY-MM-DD
+	End      string   `yaml:"end"`   // YYYY-MM-DD
+	Days     []string `yaml:"days"`  // mon ... sun; empty means every day
+	Hours    string   `yaml:"hours"` // HH:MM-HH:MM; empty means all day
+	Timezone string   `yaml:"timezone"`
+}
+
+// EngagementBudget caps model spend for the engagement
+type EngagementBudget struct {
+	MaxCostUSD float64 `yaml:"max_cost_usd"` // 0 means no cap
+}
+
+// EngagementReport selects how reports are produced
+type EngagementReport struct {
+	Template string `yaml:"template"` // Markdown template, relative to the engagement file
+	Language string `yaml:"language"` // Report language code, e.g. "de"; default English
+}
+
+// Metadata keys an engagement sets on the mission
+const (
+	MetaClient         = "client"
+	MetaEngagementID   = "engagement_id"
+	MetaAuthorization  = "authorization"
+	MetaContacts       = "contacts"
+	MetaROE            = "roe"
+	MetaTestingWindow  = "testing_window"
+	MetaBudget         = "budget_usd"
+	MetaReportTemplate = "report_template"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// LoadEngagement reads and validates an engagement file. Relative paths in
+// it are resolved against the file's directory.
+func LoadEngagement(path string) (*Engagement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read engagement file: %w", err)
+	}
+
+	var eng Engagement
+	if err := yaml.Unmarshal(data, &eng); err != nil {
+		return nil, fmt.Errorf("failed to parse engagement file: %w", err)
+	}
+	if eng.Report.Template != "" && !filepath.IsAbs(eng.Report.Template) {
+		eng.Report.Template = filepath.Join(filepath.Dir(path), eng.Report.Template)
+	}
+	if err := eng.validate(); err != nil {
+		return nil, fmt.Errorf("invalid engagement file %s: %w", path, err)
+	}
+	return &eng, nil
+}
+
+func (eng *Engagement) validate() error {
+	eng.Workflow = strings.TrimSpace(eng.Workflow)
+	eng.Target = strings.TrimSpace(eng.Target)
+	if eng.Workflow == "" {
+		return fmt.Errorf("workflow is required")
+	}
+	if eng.Target == "" {
+		return fmt.Errorf("target is required")
+	}
+	if _, err := NormalizeScopeEntry(eng.Target); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	var err error
+	if eng.Scope.Include, err = normalizeScopeList(eng.Scope.Include); err != nil {
+		return fmt.Errorf("scope.include: %w", err)
+	}
+	if eng.Scope.Exclude, err = normalizeScopeList(eng.Scope.Exclude); err != nil {
+		return fmt.Errorf("scope.exclude: %w", err)
+	}
+
+	if eng.Budget.MaxCostUSD < 0 {
+		return fmt.Errorf("budget.max_cost_usd must not be negative")
+	}
+	if eng.Report.Template != "" {
+		if _, err := os.Stat(eng.Report.Template); err != nil {
+			return fmt.Errorf("report.template: %w", err)
+		}
+	}
+	if _, err := LookupReportLocale(eng.Report.Language); err != nil {
+		return fmt.Errorf("report.language: %w", err)
+	}
+	return eng.parseSchedule()
+}
+
+func normalizeScopeList(entries []string) ([]string, error) {
+	var out []string
+	for _, entry := range entries {
+		normalized, err := NormalizeScopeEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, normalized) {
+			out = append(out, normalized)
+		}
+	}
+	return out, nil
+}
+
+func (eng *Engagement) parseSchedule() error {
+	s := eng.Schedule
+	eng.location = time.Local
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return fmt.Errorf("schedule.timezone: %w", err)
+		}
+		eng.location = loc
+	}
+	for _, date := range []struct{ name, value string }{{"start", s.Start}, {"end", s.End}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.ParseInLocation(time.DateOnly, date.value, eng.location); err != nil {
+			return fmt.Errorf("schedule.%s must be YYYY-MM-DD: %w", date.name, err)
+		}
+	}
+	if s.Start != "" && s.End != "" && s.End < s.Start {
+		return fmt.Errorf("schedule.end is before schedule.start")
+	}
+	for _, day := range s.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return fmt.Errorf("schedule.days: unknown day %q", day)
+		}
+	}
+	if s.Hours != "" {
+		fromStr, toStr, ok := strings.Cut(s.Hours, "-")
+		from, errFrom := parseClock(fromStr)
+		to, errTo := parseClock(toStr)
+		if !ok || errFrom != nil || errTo != nil {
+			return fmt.Errorf("schedule.hours must be HH:MM-HH:MM, got %q", s.Hours)
+		}
+		eng.from, eng.to = from, to
+	}
+	return nil
+}
+
+// parseWeekday accepts a day name or its first three letters
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if len(day) < 3 {
+		return 0, false
+	}
+	weekday, ok := weekdays[day[:3]]
+	return weekday, ok
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hour, errH := strconv.Atoi(h)
+	minute, errM := strconv.Atoi(m)
+	if !ok || errH != nil || errM != nil || hour < 0 || hour > 24 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// CheckSchedule returns an error explaining why testing is not allowed at
+// now, or nil inside the testing window.
+func (eng *Engagement) CheckSchedule(now time.Time) error {
+	s := eng.Schedule
+	local := now.In(eng.location)
+	date := local.Format(time.DateOnly)
+	if s.Start != "" && date < s.Start {
+		return fmt.Errorf("the engagement starts on %s", s.Start)
+	}
+	if s.End != "" && date > s.End {
+		return fmt.Errorf("the engagement ended on %s", s.End)
+	}
+	if len(s.Days) > 0 {
+		allowed := false
+		for _, day := range s.Days {
+			if weekday, _ := parseWeekday(day); weekday == local.Weekday() {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("testing is not allowed on %s", local.Weekday())
+		}
+	}
+	if eng.from != eng.to {
+		minute := local.Hour()*60 + local.Minute()
+		inWindow := minute >= eng.from && minute < eng.to
+		if eng.from > eng.to { // Overnight window, e.g. 22:00-06:00
+			inWindow = minute >= eng.from || minute < eng.to
+		}
+		if !inWindow {
+			return fmt.Errorf("testing is only allowed %s (%s)", s.Hours, eng.location)
+		}
+	}
+	return nil
+}
+
+// Window describes the testing window on one line, or "" when testing is
+// allowed at any time
+func (eng *Engagement) Window() string {
+	s := eng.Schedule
+	var parts []string
+	switch {
+	case s.Start != "" && s.End != "":
+		parts = append(parts, s.Start+" to "+s.End)
+	case s.Start != "":
+		parts = append(parts, "from "+s.Start)
+	case s.End != "":
+		parts = append(parts, "until "+s.End)
+	}
+	if len(s.Days) > 0 {
+		parts = append(parts, strings.Join(s.Days, ", "))
+	}
+	if s.Hours != "" {
+		parts = append(parts, s.Hours)
+	}
+	if len(parts) > 0 && s.Timezone != "" {
+		parts = append(parts, s.Timezone)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ApplyEngagement records the engagement on the mission: its details as
+// metadata, its scope entries and its tool restrictions. Call it on a fresh
+// mission, before the agent starts work.
+func (e *Engine) ApplyEngagement(eng *Engagement) error {
+	for _, entry := range eng.Scope.Include {
+		if err := e.ChangeScope(ScopeChange{Action: ScopeAdd, Entry: entry, Reason: "engagement file"}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range eng.Scope.Exclude {
+		if err := e.ChangeScope(ScopeChange{Action: ScopeAdd, Entry: entry, Exclude: true, Reason: "engagement file"}); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, value := range map[string]any{
+		MetaClient:         eng.Client,
+		MetaEngagementID:   eng.ID,
+		MetaAuthorization:  eng.ROE.Authorization,
+		MetaTestingWindow:  eng.Window(),
+		MetaReportTemplate: eng.Report.Template,
+		MetaReportLanguage: eng.Report.Language,
+	} {
+		if s := strings.TrimSpace(value.(string)); s != "" {
+			e.setMetadata(key, s)
+		}
+	}
+	if len(eng.ROE.Rules) > 0 {
+		e.setMetadata(MetaROE, slices.Clone(eng.ROE.Rules))
+	}
+	if len(eng.ROE.Contacts) > 0 {
+		e.setMetadata(MetaContacts, strings.Join(eng.ROE.Contacts, ", "))
+	}
+	if eng.Budget.MaxCostUSD > 0 {
+		e.setMetadata(MetaBudget, eng.Budget.MaxCostUSD)
+	}
+	for _, tool := range eng.ROE.DenyTools {
+		if !slices.Contains(e.workflow.Tools.Deny, tool) {
+			e.workflow.Tools.Deny = append(e.workflow.Tools.Deny, tool)
+		}
+	}
+
+	logger.InfoCF(e.component, "Engagement applied", map[string]any{
+		"client":     eng.Client,
+		"id":         eng.ID,
+		"scope":      len(eng.Scope.Include) + len(eng.Scope.Exclude),
+		"rules":      len(eng.ROE.Rules),
+		"deny_tools": len(eng.ROE.DenyTools),
+	})
+	return e.saveState()
+}
+
+// engagementPrompt renders the rules of engagement for the mission context
+// prompt
+func (e *Engine) engagementPrompt() string {
+	meta := e.state.Metadata
+	var rules []string
+	switch v := meta[MetaROE].(type) {
+	case []string:
+		rules = v
+	case []any: // Reloaded from JSON
+		for _, r := range v {
+			rules = append(rules, fmt.Sprint(r))
+		}
+	}
+	window, _ := meta[MetaTestingWindow].(string)
+	contacts, _ := meta[MetaContacts].(string)
+	if len(rules) == 0 && window == "" && contacts == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Rules of Engagement\n")
+	if client, _ := meta[MetaClient].(string); client != "" {
+		sb.WriteString(fmt.Sprintf("- Client: %s\n", client))
+	}
+	if window != "" {
+		sb.WriteString(fmt.Sprintf("- Testing window: %s\n", window))
+	}
+	if contacts != "" {
+		sb.WriteString(fmt.Sprintf("- Emergency contacts: %s\n", contacts))
+	}
+	for _, rule := range rules {
+		sb.WriteString(fmt.Sprintf("- %s\n", rule))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -5,19 +5,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
 	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
-	"github.com/google/uuid"
+	"go.starlark.net/starlark"
 )
 
-// Engine manages workflow execution and state
+// Engine manages workflow execution and state. It is safe for concurrent
+// use: sub-agents, background supervision and the TUI may all share one.
 type Engine struct {
+	mu        sync.Mutex // Guards state; held while hook scripts run
 	workflow  *Workflow
 	state     *MissionState
 	workspace string
 	component string
+	inHook    bool // Set while hook scripts run so they can't re-trigger hooks
+
+	pendingJump string // Branch activated during an event whose target phase is entered after it
+
+	lastActivity time.Time     // End of the last agent turn or operator message
+	operatorWait time.Duration // Operator time within the running agent turn
+
+	fallbacks     FallbackMatrix         // Stand-ins for missing tools
+	toolAvailable func(name string) bool // Nil until SetToolFallbacks; every tool is assumed present
+	keepRevisions int                    // State snapshots kept for rollback; 0 = DefaultKeepRevisions
+	resolveHost   func(name string) bool // Nil means a DNS lookup; see SetHostResolver
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan Event]struct{}
 }
 
 // NewEngine creates a new workflow engine
@@ -25,7 +45,7 @@ func NewEngine(workflow *Workflow, target string, workspace string) *Engine {
 	state := &MissionState{
 		WorkflowName:   workflow.Name,
 		Target:         target,
-		StartTime:      time.Now(),
+		StartTime:      determinism.Now(),
 		CurrentPhase:   0,
 		PhaseHistory:   make([]PhaseExecution, 0),
 		ActiveBranches: make([]ActiveBranch, 0),
@@ -34,30 +54,27 @@ func NewEngine(workflow *Workflow, target string, workspace string) *Engine {
 	}
 
 	return &Engine{
-		workflow:  workflow,
-		state:     state,
-		workspace: workspace,
-		component: "workflow",
+		workflow:    workflow,
+		state:       state,
+		workspace:   workspace,
+		component:   "workflow",
+		subscribers: make(map[chan Event]struct{}),
 	}
 }
 
 // LoadEngine loads an existing workflow engine from state
 func LoadEngine(workflow *Workflow, stateFile string, workspace string) (*Engine, error) {
-	data, err := os.ReadFile(stateFile)
+	state, err := ReadMissionState(stateFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	var state MissionState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse state: %w", err)
-	}
-
 	return &Engine{
-		workflow:  workflow,
-		state:     &state,
-		workspace: workspace,
-		component: "workflow",
+		workflow:    workflow,
+		state:       state,
+		workspace:   workspace,
+		component:   "workflow",
+		subscribers: make(map[chan Event]struct{}),
 	}, nil
 }
 
@@ -67,7 +84,11 @@ func (e *Engine) GetContextPrompt() string {
 		return ""
 	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	var sb strings.Builder
+	vars := e.templateVars()
 
 	sb.WriteString("# Active Mission Context\n\n")
 	sb.WriteString(fmt.Sprintf("**Workflow**: %s\n", e.workflow.Name))
@@ -80,19 +101,30 @@ func (e *Engine) GetContextPrompt() string {
 	if e.state.CurrentPhase < len(e.workflow.Phases) {
 		phase := e.workflow.Phases[e.state.CurrentPhase]
 		sb.WriteString(fmt.Sprintf("## Current Phase: %s\n\n", phase.Name))
+		sb.WriteString(e.subWorkflowPrompt(phase))
 
 		// Steps
 		exec := e.getCurrentPhaseExecution()
 		if exec != nil {
-			nextStep := e.getNextActionableStep(phase, exec)
-			if nextStep != nil {
+			unblocked, blocked := e.unblockedSteps(phase, exec)
+			if len(unblocked) > 0 {
+				nextStep := unblocked[0]
 				required := ""
 				if nextStep.Required {
 					required = " (required)"
 				}
-				sb.WriteString(fmt.Sprintf("### Next Action\n- %s%s\n", nextStep.Name, required))
+				sb.WriteString(fmt.Sprintf("### Next Action\n- %s%s\n", expandTemplate(nextStep.Name, vars), required))
 				if nextStep.Description != "" {
-					sb.WriteString(fmt.Sprintf("  %s\n", nextStep.Description))
+					sb.WriteString(fmt.Sprintf("  %s\n", expandTemplate(nextStep.Description, vars)))
+				}
+				sb.WriteString("\n")
+			}
+			// With dependencies, name every step that can be done now; the
+			// rest stay hidden until what they depend on is complete
+			if blocked > 0 && len(unblocked) > 1 {
+				sb.WriteString("### Also Available\n")
+				for _, step := range unblocked[1:] {
+					sb.WriteString(fmt.Sprintf("- %s: %s\n", step.ID, expandTemplate(step.Name, vars)))
 				}
 				sb.WriteString("\n")
 			}
@@ -101,21 +133,35 @@ func (e *Engine) GetContextPrompt() string {
 			sb.WriteString("### Phase Progress\n")
 			sb.WriteString(fmt.Sprintf("- Remaining required steps: %d\n", remainingRequired))
 			sb.WriteString(fmt.Sprintf("- Remaining optional steps: %d\n", remainingOptional))
+			if blocked > 0 {
+				sb.WriteString(fmt.Sprintf("- Blocked steps: %d (unlocked as the steps they depend on are completed)\n", blocked))
+			}
 			sb.WriteString("\n")
 		}
 
 		// Completion criteria
-		sb.WriteString(fmt.Sprintf("### Completion: %s\n", phase.Completion.Description))
+		sb.WriteString(fmt.Sprintf("### Completion: %s\n", expandTemplate(phase.Completion.Description, vars)))
+		if phase.Completion.Script != "" {
+			sb.WriteString(fmt.Sprintf("Checked automatically: `%s`\n", phase.Completion.Script))
+		}
 		sb.WriteString("\n")
+		sb.WriteString(e.toolPolicyPrompt(phase))
+		sb.WriteString(e.missingToolsPrompt())
+		sb.WriteString(e.branchPrompt())
 
 		// Possible branches
 		if len(phase.Branches) > 0 {
 			sb.WriteString("### Possible Branches:\n")
 			for _, branch := range phase.Branches {
-				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", branch.Condition, branch.Description))
+				sb.WriteString(fmt.Sprintf("- **%s**: %s", branch.Condition, expandTemplate(branch.Description, vars)))
+				if branch.TargetPhase != "" {
+					sb.WriteString(fmt.Sprintf(" (continues in phase %s)", branch.TargetPhase))
+				}
+				sb.WriteString("\n")
 			}
 			sb.WriteString("\n")
 		}
+		sb.WriteString(e.conditionalPhasePrompt())
 	}
 
 	// Active branches
@@ -125,12 +171,32 @@ func (e *Engine) GetContextPrompt() string {
 			status := "🔍 Active"
 			if branch.CompletedAt != nil {
 				status = "✓ Complete"
+			} else if branch.Session != "" {
+				status = fmt.Sprintf("🤖 Sub-agent investigating (%d findings so far)", len(branch.Findings))
 			}
 			sb.WriteString(fmt.Sprintf("- **%s**: %s - %s\n", branch.Condition, branch.Description, status))
 		}
 		sb.WriteString("\n")
 	}
 
+	// Target aliases
+	if len(e.state.Aliases) > 0 {
+		sb.WriteString("## Target Aliases\n")
+		sb.WriteString("Always use the exact target on the right when one of these names comes up:\n")
+		aliases := make([]string, 0, len(e.state.Aliases))
+		for alias := range e.state.Aliases {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		for _, alias := range aliases {
+			sb.WriteString(fmt.Sprintf("- %s → %s\n", alias, e.state.Aliases[alias]))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(e.engagementPrompt())
+	sb.WriteString(e.scopePrompt())
+
 	// Recent findings
 	if len(e.state.Findings) > 0 {
 		sb.WriteString(fmt.Sprintf("## Findings: %d total\n", len(e.state.Findings)))
@@ -150,22 +216,6 @@ func (e *Engine) GetContextPrompt() string {
 	return sb.String()
 }
 
-func (e *Engine) getNextActionableStep(phase Phase, exec *PhaseExecution) *Step {
-	for i := range phase.Steps {
-		step := &phase.Steps[i]
-		if step.Required && !e.isStepComplete(step.ID, exec) {
-			return step
-		}
-	}
-	for i := range phase.Steps {
-		step := &phase.Steps[i]
-		if !e.isStepComplete(step.ID, exec) {
-			return step
-		}
-	}
-	return nil
-}
-
 func (e *Engine) getRemainingStepCounts(phase Phase, exec *PhaseExecution) (int, int) {
 	remainingRequired := 0
 	remainingOptional := 0
@@ -182,8 +232,12 @@ func (e *Engine) getRemainingStepCounts(phase Phase, exec *PhaseExecution) (int,
 	return remainingRequired, remainingOptional
 }
 
-// MarkStepComplete marks a step as complete in the current phase
+// MarkStepComplete marks a step as complete in the current phase. A step
+// can't be completed before the steps it depends on.
 func (e *Engine) MarkStepComplete(stepID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	exec := e.getCurrentPhaseExecution()
 	if exec == nil {
 		return fmt.Errorf("no active phase execution")
@@ -195,25 +249,46 @@ func (e *Engine) MarkStepComplete(stepID string) error {
 			return nil // Already complete
 		}
 	}
+	if err := e.checkStepUnblocked(stepID, exec); err != nil {
+		return err
+	}
 
 	exec.StepsComplete = append(exec.StepsComplete, stepID)
+	e.journal(JournalStep, stepID, false)
 
 	logger.InfoCF(e.component, "Step complete", map[string]any{
 		"phase": exec.PhaseName,
 		"step":  stepID,
 	})
+	e.publish(Event{Type: EventStepComplete, Phase: exec.PhaseName, Step: stepID})
 
-	return e.SaveState()
+	e.onEvent(HookStepComplete, starlark.StringDict{"step": starlark.String(stepID)})
+	return e.saveState()
 }
 
 // CreateBranch creates a new investigation branch
 func (e *Engine) CreateBranch(condition, description string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.activateBranch(condition, description)
+	e.onEvent(HookBranch, starlark.StringDict{"condition": starlark.String(condition)})
+	return e.saveState()
+}
+
+func (e *Engine) activateBranch(condition, description string) {
 	branch := ActiveBranch{
 		Condition:   condition,
 		Description: description,
-		CreatedAt:   time.Now(),
+		CreatedAt:   determinism.Now(),
 		Findings:    make([]Finding, 0),
 	}
+	if def, ok := e.branchDefinition(condition); ok && def.TargetPhase != "" {
+		branch.TargetPhase = def.TargetPhase
+		if e.pendingJump == "" {
+			e.pendingJump = condition
+		}
+	}
 
 	e.state.ActiveBranches = append(e.state.ActiveBranches, branch)
 
@@ -221,66 +296,282 @@ func (e *Engine) CreateBranch(condition, description string) error {
 		"condition":   condition,
 		"description": description,
 	})
+	e.publish(Event{Type: EventBranchCreated, Phase: e.currentPhaseName(), Branch: condition})
+}
+
+func (e *Engine) hasBranch(condition string) bool {
+	for _, branch := range e.state.ActiveBranches {
+		if branch.Condition == condition {
+			return true
+		}
+	}
+	return false
+}
 
-	return e.SaveState()
+// onEvent runs the current phase's hooks for event, activates any scripted
+// branches whose condition now holds, then enters the phase a new branch
+// jumps to
+func (e *Engine) onEvent(event string, fields starlark.StringDict) {
+	e.runHooks(event, fields)
+	e.activateScriptedBranches()
+	e.followPendingJump()
 }
 
-// CompleteBranch marks a branch as complete
+// CompleteBranch marks a branch as complete. If the branch jumped to
+// another phase, the mission returns to the phase it left.
 func (e *Engine) CompleteBranch(condition string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.completeBranch(condition) {
+		return fmt.Errorf("branch not found: %s", condition)
+	}
+	e.returnFromBranch(condition)
+	return e.saveState()
+}
+
+func (e *Engine) completeBranch(condition string) bool {
 	for i := range e.state.ActiveBranches {
 		if e.state.ActiveBranches[i].Condition == condition {
-			now := time.Now()
+			if e.state.ActiveBranches[i].CompletedAt != nil {
+				return true
+			}
+			now := determinism.Now()
 			e.state.ActiveBranches[i].CompletedAt = &now
+			e.mergeBranch(&e.state.ActiveBranches[i])
 
 			logger.InfoCF(e.component, "Branch completed", map[string]any{
 				"condition": condition,
 			})
-
-			return e.SaveState()
+			e.publish(Event{Type: EventBranchCompleted, Phase: e.currentPhaseName(), Branch: condition})
+			return true
 		}
 	}
-	return fmt.Errorf("branch not found: %s", condition)
+	return false
 }
 
-// AddFinding adds a finding to the mission
+// AddFinding adds a finding to the mission, shown in full in client reports
 func (e *Engine) AddFinding(title, description string, severity Severity, evidence string) error {
-	finding := Finding{
-		ID:          uuid.New().String(),
+	return e.AddRedactedFinding(title, description, severity, evidence, RedactionFull)
+}
+
+// AddRedactedFinding adds a finding whose client report visibility is
+// limited by redaction
+func (e *Engine) AddRedactedFinding(title, description string, severity Severity, evidence string, redaction RedactionLevel) error {
+	_, err := e.RecordFinding(Finding{
 		Title:       title,
 		Description: description,
 		Severity:    severity,
-		Phase:       e.workflow.Phases[e.state.CurrentPhase].Name,
-		CreatedAt:   time.Now(),
 		Evidence:    evidence,
-		Metadata:    make(map[string]interface{}),
+		Redaction:   redaction,
+	})
+	return err
+}
+
+// RecordFinding adds a finding with optional details such as its CWE, CVSS
+// vector and remediation, and returns it as recorded. The ID, phase and time
+// are filled in. CVSS 3.x vectors are scored, and a finding with a score but
+// no severity is rated from it.
+func (e *Engine) RecordFinding(finding Finding) (Finding, error) {
+	if err := scoreFinding(&finding); err != nil {
+		return Finding{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	finding.ID = determinism.NewUUID().String()
+	finding.Phase = e.workflow.Phases[e.state.CurrentPhase].Name
+	finding.CreatedAt = determinism.Now()
+	if finding.Redaction == "" {
+		finding.Redaction = RedactionFull
+	}
+	if finding.Metadata == nil {
+		finding.Metadata = make(map[string]interface{})
 	}
 
 	e.state.Findings = append(e.state.Findings, finding)
 
 	logger.InfoCF(e.component, "Finding added", map[string]any{
-		"title":    title,
-		"severity": severity,
+		"title":    finding.Title,
+		"severity": finding.Severity,
 		"phase":    finding.Phase,
 	})
+	published := finding
+	e.publish(Event{Type: EventFinding, Phase: finding.Phase, Finding: &published})
+
+	e.onEvent(HookFinding, findingFields(finding))
+	return cloneFindings([]Finding{finding})[0], e.saveState()
+}
+
+// findingFields are the fields finding hooks see
+func findingFields(finding Finding) starlark.StringDict {
+	return starlark.StringDict{
+		"id":          starlark.String(finding.ID),
+		"title":       starlark.String(finding.Title),
+		"description": starlark.String(finding.Description),
+		"severity":    starlark.String(string(finding.Severity)),
+		"cvss_score":  starlark.Float(finding.CVSSScore),
+	}
+}
+
+// SetFindingRedaction changes how much of a finding the client report shows.
+// The finding is matched by ID or, failing that, by exact title.
+func (e *Engine) SetFindingRedaction(idOrTitle string, redaction RedactionLevel) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index := e.findFinding(idOrTitle)
+	if index < 0 {
+		return fmt.Errorf("finding %q not found", idOrTitle)
+	}
+
+	e.state.Findings[index].Redaction = redaction
+	logger.InfoCF(e.component, "Finding redaction changed", map[string]any{
+		"title":     e.state.Findings[index].Title,
+		"redaction": redaction,
+	})
+	return e.saveState()
+}
+
+// FindFinding returns the finding with the given ID or, failing that, exact
+// title
+func (e *Engine) FindFinding(idOrTitle string) (Finding, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	index := e.findFinding(idOrTitle)
+	if index < 0 {
+		return Finding{}, false
+	}
+	return cloneFindings(e.state.Findings[index : index+1])[0], true
+}
+
+// findFinding returns the index of the finding with the given ID or, failing
+// that, exact title, or -1
+func (e *Engine) findFinding(idOrTitle string) int {
+	for i, f := range e.state.Findings {
+		if f.ID == idOrTitle {
+			return i
+		}
+	}
+	for i, f := range e.state.Findings {
+		if f.Title == idOrTitle {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetAlias maps a human-readable name (e.g. "the admin portal") to an exact
+// target so every model refers to the same host. Aliases are case-insensitive.
+func (e *Engine) SetAlias(alias, target string) error {
+	alias = normalizeAlias(alias)
+	target = strings.TrimSpace(target)
+	if alias == "" || target == "" {
+		return fmt.Errorf("alias and target are required")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Aliases == nil {
+		e.state.Aliases = make(map[string]string)
+	}
+	e.state.Aliases[alias] = target
+
+	logger.InfoCF(e.component, "Alias set", map[string]any{
+		"alias":  alias,
+		"target": target,
+	})
+	e.publish(Event{Type: EventAliasChanged, Phase: e.currentPhaseName(), Key: alias})
 
-	return e.SaveState()
+	return e.saveState()
 }
 
-// AdvancePhase moves to the next phase
+// RemoveAlias deletes an alias
+func (e *Engine) RemoveAlias(alias string) error {
+	alias = normalizeAlias(alias)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.state.Aliases[alias]; !ok {
+		return fmt.Errorf("alias not found: %s", alias)
+	}
+	delete(e.state.Aliases, alias)
+	e.publish(Event{Type: EventAliasChanged, Phase: e.currentPhaseName(), Key: alias})
+	return e.saveState()
+}
+
+// ResolveAlias returns the target for an alias, or name unchanged if it is
+// not an alias
+func (e *Engine) ResolveAlias(name string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if target, ok := e.state.Aliases[normalizeAlias(name)]; ok {
+		return target
+	}
+	return name
+}
+
+func normalizeAlias(alias string) string {
+	return strings.ToLower(strings.Join(strings.Fields(alias), " "))
+}
+
+// SetMetadata sets a mission metadata value, such as an engagement ID that
+// preambles and reports refer to
+func (e *Engine) SetMetadata(key string, value interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.setMetadata(key, value)
+	return e.saveState()
+}
+
+func (e *Engine) setMetadata(key string, value interface{}) {
+	if e.state.Metadata == nil {
+		e.state.Metadata = make(map[string]interface{})
+	}
+	e.state.Metadata[key] = value
+	e.publish(Event{Type: EventMetadataChanged, Phase: e.currentPhaseName(), Key: key})
+}
+
+// AdvancePhase moves to the next phase, skipping phases whose when
+// condition does not hold. In a phase a branch jumped to, it completes the
+// branch and returns to the phase the branch left instead.
 func (e *Engine) AdvancePhase() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if n := len(e.state.PhaseStack); n > 0 {
+		branch := e.state.PhaseStack[n-1].Branch
+		e.completeBranch(branch)
+		e.returnFromBranch(branch)
+		return e.saveState()
+	}
+
 	// Close current phase
 	exec := e.getCurrentPhaseExecution()
 	if exec != nil {
-		now := time.Now()
+		now := determinism.Now()
 		exec.EndTime = &now
 	}
 
-	// Move to next phase
-	if e.state.CurrentPhase >= len(e.workflow.Phases)-1 {
+	// Move to the next phase whose condition holds
+	next, skipped := e.nextPhase()
+	if next < 0 {
+		if len(skipped) > 0 {
+			return fmt.Errorf("already at final phase: the conditions of %s do not hold", strings.Join(skipped, ", "))
+		}
 		return fmt.Errorf("already at final phase")
 	}
+	for _, name := range skipped {
+		logger.InfoCF(e.component, "Phase skipped", map[string]any{"phase": name})
+		e.publish(Event{Type: EventPhaseSkipped, Phase: name})
+	}
 
-	e.state.CurrentPhase++
+	e.state.CurrentPhase = next
 
 	// Create new phase execution
 	e.startPhaseExecution()
@@ -289,12 +580,17 @@ func (e *Engine) AdvancePhase() error {
 		"new_phase": e.workflow.Phases[e.state.CurrentPhase].Name,
 		"phase_num": e.state.CurrentPhase,
 	})
+	e.publish(Event{Type: EventPhaseAdvanced, Phase: e.currentPhaseName()})
 
-	return e.SaveState()
+	e.onEvent(HookPhaseStart, starlark.StringDict{"phase": starlark.String(e.workflow.Phases[e.state.CurrentPhase].Name)})
+	return e.saveState()
 }
 
 // IsPhaseComplete checks if current phase completion criteria are met
 func (e *Engine) IsPhaseComplete() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if e.state.CurrentPhase >= len(e.workflow.Phases) {
 		return false
 	}
@@ -319,6 +615,17 @@ func (e *Engine) IsPhaseComplete() bool {
 		// At least one branch must be created
 		return len(e.state.ActiveBranches) > 0
 
+	case CompletionScript:
+		complete, err := e.evalCondition(phase.Completion.Script)
+		if err != nil {
+			logger.WarnCF(e.component, "Completion script failed", map[string]any{
+				"phase": phase.Name,
+				"error": err.Error(),
+			})
+			return false
+		}
+		return complete
+
 	case CompletionCustom:
 		// Cannot auto-determine, return false
 		return false
@@ -330,34 +637,87 @@ func (e *Engine) IsPhaseComplete() bool {
 
 // SaveState persists mission state to disk
 func (e *Engine) SaveState() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.saveState()
+}
+
+func (e *Engine) saveState() error {
 	stateDir := filepath.Join(e.workspace, "missions")
 	if err := os.MkdirAll(stateDir, 0755); err != nil {
 		return fmt.Errorf("failed to create missions directory: %w", err)
 	}
 
-	// Sanitize target for filename, fall back to workflow name if no target
-	safeName := e.state.Target
-	if safeName == "" {
-		safeName = e.state.WorkflowName + "_" + e.state.StartTime.Format("20060102_150405")
-	}
-	safeName = strings.ReplaceAll(safeName, "/", "_")
-	safeName = strings.ReplaceAll(safeName, ":", "_")
-	stateFile := filepath.Join(stateDir, fmt.Sprintf("%s_state.json", safeName))
+	stateFile := filepath.Join(stateDir, fmt.Sprintf("%s_state.json", e.missionFileName()))
 
+	e.state.Revision++
 	data, err := json.MarshalIndent(e.state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(stateFile, data, 0644); err != nil {
+	data = sealState(data)
+	if err := writeFileAtomic(stateFile, data); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
+	e.saveRevision(data)
 
 	return nil
 }
 
 // Helper methods
 
+// missionFileName is the base name of the mission's state and report files
+func (e *Engine) missionFileName() string {
+	// Sanitize target for filename, fall back to workflow name if no target
+	safeName := e.state.Target
+	if safeName == "" {
+		safeName = e.state.WorkflowName + "_" + e.state.StartTime.Format("20060102_150405")
+	}
+	return safeFileName(safeName)
+}
+
+// MissionDir is the mission's working directory in the workspace, where
+// sandboxed scripts run and keep their files
+func (e *Engine) MissionDir() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return filepath.Join(e.workspace, "missions", e.missionFileName())
+}
+
+// MissionStatePath is where the mission for target saves its state
+func MissionStatePath(workspace, target string) string {
+	return filepath.Join(workspace, "missions", safeFileName(target)+"_state.json")
+}
+
+// MissionStatePaths lists the saved mission states in workspace, most
+// recently saved first
+func MissionStatePaths(workspace string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(workspace, "missions", "*_state.json"))
+	if err != nil {
+		return nil, err
+	}
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		modTimes[path] = info.ModTime()
+	}
+	paths = slices.DeleteFunc(paths, func(path string) bool {
+		_, ok := modTimes[path]
+		return !ok
+	})
+	sort.SliceStable(paths, func(i, j int) bool { return modTimes[paths[i]].After(modTimes[paths[j]]) })
+	return paths, nil
+}
+
+func safeFileName(name string) string {
+	name = strings.ReplaceAll(name, "/", "_")
+	return strings.ReplaceAll(name, ":", "_")
+}
+
 func (e *Engine) getCurrentPhaseExecution() *PhaseExecution {
 	if len(e.state.PhaseHistory) == 0 {
 		e.startPhaseExecution()
@@ -374,9 +734,10 @@ func (e *Engine) startPhaseExecution() {
 	}
 
 	phase := e.workflow.Phases[e.state.CurrentPhase]
+	e.trackSubWorkflow(phase)
 	exec := PhaseExecution{
 		PhaseName:     phase.Name,
-		StartTime:     time.Now(),
+		StartTime:     determinism.Now(),
 		StepsComplete: make([]string, 0),
 		Notes:         make([]string, 0),
 	}
@@ -385,17 +746,31 @@ func (e *Engine) startPhaseExecution() {
 }
 
 func (e *Engine) isStepComplete(stepID string, exec *PhaseExecution) bool {
-	for _, id := range exec.StepsComplete {
-		if id == stepID {
-			return true
-		}
+	return exec.isComplete(stepID)
+}
+
+func (e *Engine) currentPhaseName() string {
+	if e.state.CurrentPhase < len(e.workflow.Phases) {
+		return e.workflow.Phases[e.state.CurrentPhase].Name
 	}
-	return false
+	return ""
 }
 
-// GetState returns the current mission state
+// GetState returns a snapshot of the current mission state. The snapshot is
+// a deep copy, so callers may read it freely while the mission runs; changes
+// to it are not written back. Use the Engine's methods to change state.
 func (e *Engine) GetState() *MissionState {
-	return e.state
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state.clone()
+}
+
+// CurrentPhaseName returns the name of the current phase, or "" once the
+// workflow is past its last phase
+func (e *Engine) CurrentPhaseName() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.currentPhaseName()
 }
 
 // GetWorkflow returns the workflow definition
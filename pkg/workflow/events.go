@@ -0,0 +1,81 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies a mission state change
+type EventType string
+
+const (
+	EventStepComplete    EventType = "step_complete"    // A step in the current phase was completed
+	EventBranchCreated   EventType = "branch_created"   // An investigation branch was activated
+	EventBranchCompleted EventType = "branch_completed" // An investigation branch was closed
+	EventFinding         EventType = "finding"          // A finding was recorded
+	EventPhaseAdvanced   EventType = "phase_advanced"   // The mission moved to the next phase
+	EventPhaseJumped     EventType = "phase_jumped"     // A branch moved the mission to another phase, or back
+	EventAliasChanged    EventType = "alias_changed"    // A target alias was set or removed
+	EventMetadataChanged EventType = "metadata_changed" // A mission metadata value was set
+	EventVariableSet     EventType = "variable_set"     // A mission variable was set
+	EventPhaseSkipped    EventType = "phase_skipped"    // A phase whose when condition was false was passed over
+	EventScopeChanged    EventType = "scope_changed"    // A scope entry was added or removed, or a change was denied
+	EventNoteAdded       EventType = "note_added"       // A note was added to the journal
+	EventToolCalled      EventType = "tool_called"      // A tool call was added to the journal
+	EventRolledBack      EventType = "rolled_back"      // The mission was restored to an earlier revision
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before
+// further events are dropped for it
+const subscriberBuffer = 64
+
+// Event is published to subscribers after the Engine changes mission state.
+// Subscribers that need the full picture call GetState.
+type Event struct {
+	Type  EventType
+	Time  time.Time
+	Phase string // Phase the change happened in
+
+	Step     string   // EventStepComplete
+	Branch   string   // EventBranchCreated, EventBranchCompleted
+	Finding  *Finding // EventFinding
+	Key      string   // EventAliasChanged, EventMetadataChanged, EventVariableSet, EventScopeChanged
+	Text     string   // EventNoteAdded, EventToolCalled
+	Revision int      // EventRolledBack: the revision restored
+}
+
+// Subscribe returns a channel of mission state changes. The channel is
+// closed when ctx is done. Events are dropped for subscribers that fall too
+// far behind, so a slow UI never stalls the mission.
+func (e *Engine) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	e.subscribersMu.Lock()
+	e.subscribers[ch] = struct{}{}
+	e.subscribersMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.subscribersMu.Lock()
+		defer e.subscribersMu.Unlock()
+		delete(e.subscribers, ch)
+		close(ch)
+	}()
+	return ch
+}
+
+// publish delivers event to every subscriber without blocking
+func (e *Engine) publish(event Event) {
+	// Wall clock on purpose: events aren't persisted, and reading the
+	// deterministic clock here would shift every later mission timestamp
+	event.Time = time.Now()
+
+	e.subscribersMu.Lock()
+	defer e.subscribersMu.Unlock()
+	for ch := range e.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// EvidenceDir is where evidence artifacts are stored, relative to the
+// workspace
+const EvidenceDir = "evidence"
+
+// MetaEvidence is the Finding.Metadata key listing the finding's evidence
+// artifacts
+const MetaEvidence = "evidence"
+
+// EvidenceKind says what an evidence artifact is
+type EvidenceKind string
+
+const (
+	EvidenceFile       EvidenceKind = "file"
+	EvidenceScreenshot EvidenceKind = "screenshot"
+	EvidencePCAP       EvidenceKind = "pcap"
+	EvidenceToolOutput EvidenceKind = "tool_output"
+)
+
+// EvidenceArtifact is a file kept as evidence for a finding. Artifacts are
+// stored once per content under their SHA-256, so attaching the same file
+// twice, or to two findings, keeps one copy.
+type EvidenceArtifact struct {
+	SHA256      string       `json:"sha256"`
+	Name        string       `json:"name"` // Original file name
+	Path        string       `json:"path"` // Relative to the workspace
+	Kind        EvidenceKind `json:"kind"`
+	MediaType   string       `json:"media_type,omitempty"`
+	Size        int64        `json:"size"`
+	Description string       `json:"description,omitempty"`
+	AddedAt     time.Time    `json:"added_at"`
+}
+
+// IsImage reports whether the artifact can be shown inline in a report
+func (a EvidenceArtifact) IsImage() bool {
+	return strings.HasPrefix(a.MediaType, "image/")
+}
+
+// ParseEvidenceKind reads an evidence kind; empty means it is guessed from
+// the file name
+func ParseEvidenceKind(s string) (EvidenceKind, error) {
+	switch kind := EvidenceKind(strings.ToLower(strings.TrimSpace(s))); kind {
+	case "", EvidenceFile, EvidenceScreenshot, EvidencePCAP, EvidenceToolOutput:
+		return kind, nil
+	}
+	return "", fmt.Errorf("invalid evidence kind %q (want file, screenshot, pcap or tool_output)", s)
+}
+
+// StoreEvidence copies r into the workspace's evidence store under its
+// content hash and describes it. kind is guessed from name when empty.
+func StoreEvidence(workspace, name string, r io.Reader, kind EvidenceKind) (EvidenceArtifact, error) {
+	dir := filepath.Join(workspace, EvidenceDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return EvidenceArtifact{}, fmt.Errorf("failed to create evidence directory: %w", err)
+	}
+
+	// Hash while copying to a temporary file, then move it into place
+	tmp, err := os.CreateTemp(dir, ".incoming-*")
+	if err != nil {
+		return EvidenceArtifact{}, fmt.Errorf("failed to store evidence: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return EvidenceArtifact{}, fmt.Errorf("failed to store evidence: %w", err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	ext := strings.ToLower(filepath.Ext(name))
+	rel := filepath.ToSlash(filepath.Join(EvidenceDir, sum+ext))
+	path := filepath.Join(workspace, filepath.FromSlash(rel))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return EvidenceArtifact{}, fmt.Errorf("failed to store evidence: %w", err)
+		}
+	}
+
+	if kind == "" {
+		kind = guessEvidenceKind(ext)
+	}
+	return EvidenceArtifact{
+		SHA256:    sum,
+		Name:      filepath.Base(name),
+		Path:      rel,
+		Kind:      kind,
+		MediaType: mime.TypeByExtension(ext),
+		Size:      size,
+		AddedAt:   determinism.Now(),
+	}, nil
+}
+
+// StoreEvidenceFile stores a copy of the file at path as evidence
+func StoreEvidenceFile(workspace, path string, kind EvidenceKind) (EvidenceArtifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return EvidenceArtifact{}, fmt.Errorf("failed to read evidence: %w", err)
+	}
+	defer f.Close()
+	return StoreEvidence(workspace, path, f, kind)
+}
+
+func guessEvidenceKind(ext string) EvidenceKind {
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return EvidenceScreenshot
+	case ".pcap", ".pcapng", ".cap":
+		return EvidencePCAP
+	}
+	return EvidenceFile
+}
+
+// FindingEvidence returns the evidence artifacts attached to a finding
+func FindingEvidence(f Finding) []EvidenceArtifact {
+	raw, ok := f.Metadata[MetaEvidence]
+	if !ok {
+		return nil
+	}
+	if artifacts, ok := raw.([]EvidenceArtifact); ok {
+		return artifacts
+	}
+	// Loaded from saved state as plain JSON values
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var artifacts []EvidenceArtifact
+	if err := json.Unmarshal(data, &artifacts); err != nil {
+		return nil
+	}
+	return artifacts
+}
+
+// AttachEvidence adds an artifact from the evidence store to a finding,
+// matched by ID or exact title. An artifact with the same content is only
+// listed once.
+func (e *Engine) AttachEvidence(idOrTitle string, artifact EvidenceArtifact) (Finding, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index := e.findFinding(idOrTitle)
+	if index < 0 {
+		return Finding{}, fmt.Errorf("finding %q not found", idOrTitle)
+	}
+	f := &e.state.Findings[index]
+	artifacts := FindingEvidence(*f)
+	for _, existing := range artifacts {
+		if existing.SHA256 == artifact.SHA256 {
+			return cloneFindings([]Finding{*f})[0], nil
+		}
+	}
+	// Kept JSON-shaped like the rest of the metadata, so clones are deep
+	data, err := json.Marshal(append(artifacts, artifact))
+	if err != nil {
+		return Finding{}, err
+	}
+	var list []interface{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return Finding{}, err
+	}
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]interface{})
+	}
+	f.Metadata[MetaEvidence] = list
+
+	logger.InfoCF(e.component, "Evidence attached", map[string]any{
+		"finding": f.Title,
+		"name":    artifact.Name,
+		"kind":    artifact.Kind,
+		"sha256":  artifact.SHA256,
+	})
+	return cloneFindings([]Finding{*f})[0], e.saveState()
+}
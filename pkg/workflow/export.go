@@ -0,0 +1,364 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+)
+
+// ExportFormat is a machine-readable format findings can be exported in
+type ExportFormat string
+
+const (
+	ExportJSON  ExportFormat = "json"  // picoclaw's own schema, see FindingsExport
+	ExportSARIF ExportFormat = "sarif" // SARIF 2.1.0, for GitHub code scanning and DefectDojo
+)
+
+// FindingsSchema identifies the JSON export schema. It changes only when a
+// field is removed or changes meaning; new fields may appear at any time.
+const FindingsSchema = "picoclaw-findings/v1"
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolURI      = "https://github.com/ResistanceIsUseless/picoclaw"
+)
+
+// ParseExportFormat reads an export format name
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return ExportJSON, nil
+	case "sarif":
+		return ExportSARIF, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want json or sarif)", s)
+}
+
+// Extension returns the file extension for exports in the format
+func (f ExportFormat) Extension() string {
+	if f == ExportSARIF {
+		return ".sarif"
+	}
+	return ".json"
+}
+
+// ExportOptions controls what an export contains
+type ExportOptions struct {
+	Audience    ReportAudience // Client exports honor each finding's redaction level
+	ToolVersion string         // picoclaw version recorded in the export, if known
+}
+
+// ExportFindings renders the mission's findings in format
+func ExportFindings(state *MissionState, format ExportFormat, opts ExportOptions) ([]byte, error) {
+	if opts.Audience == "" {
+		opts.Audience = ReportInternal
+	}
+	findings := ReportFindings(state, opts.Audience)
+
+	var doc any
+	switch format {
+	case ExportJSON:
+		doc = findingsExport(state, findings, opts)
+	case ExportSARIF:
+		doc = sarifLog(state, findings, opts)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode findings: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// FindingsExport is the JSON export of a mission's findings
+type FindingsExport struct {
+	Schema      string          `json:"schema"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Tool        ExportedTool    `json:"tool"`
+	Mission     ExportedMission `json:"mission"`
+	Findings    []Finding       `json:"findings"`
+}
+
+type ExportedTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type ExportedMission struct {
+	Workflow     string         `json:"workflow"`
+	Target       string         `json:"target"`
+	StartTime    time.Time      `json:"start_time"`
+	Client       string         `json:"client,omitempty"`
+	EngagementID string         `json:"engagement_id,omitempty"`
+	Audience     ReportAudience `json:"audience"`
+}
+
+func findingsExport(state *MissionState, findings []Finding, opts ExportOptions) *FindingsExport {
+	client, _ := state.Metadata[MetaClient].(string)
+	id, _ := state.Metadata[MetaEngagementID].(string)
+	for i := range findings {
+		// Metadata is free-form agent notes, not part of the schema, apart
+		// from the evidence artifacts
+		evidence, ok := findings[i].Metadata[MetaEvidence]
+		findings[i].Metadata = nil
+		if ok {
+			findings[i].Metadata = map[string]interface{}{MetaEvidence: evidence}
+		}
+	}
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return &FindingsExport{
+		Schema:      FindingsSchema,
+		GeneratedAt: determinism.Now().UTC(),
+		Tool:        ExportedTool{Name: "picoclaw", Version: opts.ToolVersion},
+		Mission: ExportedMission{
+			Workflow:     state.WorkflowName,
+			Target:       state.Target,
+			StartTime:    state.StartTime,
+			Client:       client,
+			EngagementID: id,
+			Audience:     opts.Audience,
+		},
+		Findings: findings,
+	}
+}
+
+// SARIF 2.1.0, limited to the parts code scanning tools read
+type sarifDoc struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails *sarifAutomation       `json:"automationDetails,omitempty"`
+	Results           []sarifResult          `json:"results"`
+	Properties        map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifAutomation struct {
+	ID string `json:"id"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name,omitempty"`
+	ShortDescription sarifText              `json:"shortDescription"`
+	HelpURI          string                 `json:"helpUri,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifText              `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func sarifLog(state *MissionState, findings []Finding, opts ExportOptions) *sarifDoc {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "picoclaw",
+			Version:        opts.ToolVersion,
+			InformationURI: toolURI,
+			Rules:          []sarifRule{},
+		}},
+		AutomationDetails: &sarifAutomation{ID: state.WorkflowName + "/" + reportTitle(state) + "/"},
+		Results:           []sarifResult{},
+		Properties: map[string]interface{}{
+			"workflow": state.WorkflowName,
+			"target":   state.Target,
+			"audience": opts.Audience,
+		},
+	}
+
+	ruleIndex := make(map[string]int)
+	ruleScore := make(map[int]float64)
+	for _, f := range findings {
+		ruleID := sarifRuleID(f)
+		index, ok := ruleIndex[ruleID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[ruleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSarifRule(ruleID, f))
+		}
+		// A rule is as severe as its worst finding
+		ruleScore[index] = max(ruleScore[index], f.RiskScore())
+
+		location := findingLocation(state, f)
+		message := f.Title
+		if f.Description != "" {
+			message += "\n\n" + f.Description
+		}
+		properties := map[string]interface{}{
+			"severity": f.Severity,
+			"phase":    f.Phase,
+		}
+		if f.CVSSScore > 0 {
+			properties["cvss_score"] = f.CVSSScore
+		}
+		if f.CVSSVector != "" {
+			properties["cvss_vector"] = f.CVSSVector
+		}
+		if f.Evidence != "" {
+			properties["evidence"] = f.Evidence
+		}
+		if f.Remediation != "" {
+			properties["remediation"] = f.Remediation
+		}
+		if len(f.References) > 0 {
+			properties["references"] = f.References
+		}
+		if artifacts := FindingEvidence(f); len(artifacts) > 0 {
+			properties["evidence_artifacts"] = artifacts
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifText{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: location},
+			}}},
+			PartialFingerprints: map[string]string{"picoclawFinding/v1": findingFingerprint(ruleID, f.Title, location)},
+			Properties:          properties,
+		})
+	}
+
+	for index, score := range ruleScore {
+		if score > 0 {
+			run.Tool.Driver.Rules[index].Properties["security-severity"] = strconv.FormatFloat(score, 'f', 1, 64)
+		}
+	}
+
+	return &sarifDoc{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// sarifRuleID groups findings by CWE, or by title when they have none
+func sarifRuleID(f Finding) string {
+	if f.CWE != "" {
+		return f.CWE
+	}
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(f.Title), "-"), "-")
+	if slug == "" {
+		slug = "finding"
+	}
+	return "picoclaw/" + slug
+}
+
+func newSarifRule(id string, f Finding) sarifRule {
+	rule := sarifRule{
+		ID:               id,
+		ShortDescription: sarifText{Text: f.Title},
+		Properties:       map[string]interface{}{"tags": []string{"security"}},
+	}
+	if f.CWE != "" {
+		number := strings.TrimPrefix(f.CWE, "CWE-")
+		rule.Name = f.CWE
+		rule.HelpURI = "https://cwe.mitre.org/data/definitions/" + number + ".html"
+		rule.Properties["tags"] = []string{"security", "external/cwe/cwe-" + number}
+	}
+	return rule
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	}
+	return "note"
+}
+
+// findingLocation is what the finding affects: its asset, a "location" the
+// agent put in its metadata, or the mission target
+func findingLocation(state *MissionState, f Finding) string {
+	if f.Asset != "" {
+		return f.Asset
+	}
+	if location, _ := f.Metadata["location"].(string); location != "" {
+		return location
+	}
+	return reportTitle(state)
+}
+
+// findingFingerprint stays the same when a finding is reported again in a
+// later run, so importers can track it instead of opening a duplicate
+func findingFingerprint(ruleID, title, location string) string {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + strings.ToLower(strings.TrimSpace(title)) + "\x00" + location))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ExportFindings writes the mission's findings to
+// <workspace>/reports/<mission>_findings.{json,sarif} and returns the path
+func (e *Engine) ExportFindings(format ExportFormat, opts ExportOptions) (string, error) {
+	e.mu.Lock()
+	state := e.state.clone()
+	name := e.missionFileName()
+	e.mu.Unlock()
+
+	data, err := ExportFindings(state, format, opts)
+	if err != nil {
+		return "", err
+	}
+
+	reportDir := filepath.Join(e.workspace, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+	suffix := "_findings"
+	if opts.Audience == ReportClient {
+		suffix += "_client"
+	}
+	path := filepath.Join(reportDir, name+suffix+format.Extension())
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write findings: %w", err)
+	}
+	return path, nil
+}
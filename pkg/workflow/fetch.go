@@ -0,0 +1,456 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/trust"
+)
+
+// LockfileName is the workspace file pinning fetched workflows to the exact
+// version and content that was installed. Commit it next to the workflows so
+// 'picoclaw workflow fetch' without arguments reproduces them elsewhere.
+const LockfileName = "workflows.lock.json"
+
+// maxWorkflowSize caps a downloaded workflow or registry index
+const maxWorkflowSize = 1 << 20
+
+// LockEntry pins one fetched workflow
+type LockEntry struct {
+	Name      string    `json:"name"`              // Installed as workflows/<name>.md
+	Source    string    `json:"source"`            // Registry name, URL or git ref as given
+	Version   string    `json:"version,omitempty"` // Registry version or git commit
+	URL       string    `json:"url"`               // Where the content was downloaded from
+	SHA256    string    `json:"sha256"`
+	SignedBy  string    `json:"signed_by,omitempty"` // Trusted key that signed it, when signatures are checked
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Lockfile lists the fetched workflows of a workspace
+type Lockfile struct {
+	Workflows []LockEntry `json:"workflows"`
+}
+
+// ReadLockfile loads the workspace lockfile. A missing lockfile is empty.
+func ReadLockfile(workspace string) (*Lockfile, error) {
+	data, err := os.ReadFile(filepath.Join(workspace, LockfileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Lockfile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockfileName, err)
+	}
+	return &lock, nil
+}
+
+// Save writes the lockfile to the workspace, sorted by name
+func (l *Lockfile) Save(workspace string) error {
+	sort.Slice(l.Workflows, func(i, j int) bool { return l.Workflows[i].Name < l.Workflows[j].Name })
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(workspace, LockfileName), append(data, '\n'), 0644)
+}
+
+// Entry returns the lock entry for an installed workflow name
+func (l *Lockfile) Entry(name string) (LockEntry, bool) {
+	for _, e := range l.Workflows {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return LockEntry{}, false
+}
+
+func (l *Lockfile) put(entry LockEntry) {
+	for i, e := range l.Workflows {
+		if e.Name == entry.Name {
+			l.Workflows[i] = entry
+			return
+		}
+	}
+	l.Workflows = append(l.Workflows, entry)
+}
+
+// RegistryEntry is one workflow version in a registry index
+type RegistryEntry struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	SHA256      string `json:"sha256"`
+}
+
+// RegistryIndex is the JSON document a workflow registry serves
+type RegistryIndex struct {
+	Workflows []RegistryEntry `json:"workflows"`
+}
+
+// FetchOptions adjust a single fetch
+type FetchOptions struct {
+	Name   string // Install name; default from the registry entry or file name
+	SHA256 string // Expected checksum, for URLs and git refs
+	Update bool   // Fetch the latest version instead of the locked one
+	Force  bool   // Overwrite a workflow file that is not in the lockfile
+}
+
+// Fetcher downloads workflows from registries, URLs and git repositories
+// into a workspace. Refs take three forms:
+//
+//	web-app-assessment[@1.2.0]                          registry name, optionally pinned
+//	https://example.com/workflows/api.md                plain URL
+//	https://github.com/org/repo.git//workflows/api.md@v2  file in a git repository, at a tag, branch or commit
+//
+// Every download is checked against a checksum: the registry's, the one
+// given in FetchOptions, or the lockfile's for a workflow fetched before.
+// A URL or git ref with none of these is trusted on first use and pinned.
+// With a trust policy, the workflow's minisign signature (the file with
+// trust.SignatureExt appended, next to it) is checked as well.
+type Fetcher struct {
+	Registry string // Registry index URL; needed for registry refs
+	Client   *http.Client
+	Git      string        // git binary; default "git"
+	Trust    *trust.Policy // nil = signatures are not checked
+}
+
+// Fetch downloads ref, verifies it and installs it as workflows/<name>.md,
+// recording it in the lockfile.
+func (f *Fetcher) Fetch(ctx context.Context, workspace, ref string, opts FetchOptions) (LockEntry, error) {
+	lock, err := ReadLockfile(workspace)
+	if err != nil {
+		return LockEntry{}, err
+	}
+	entry, err := f.fetch(ctx, workspace, lock, ref, opts)
+	if err != nil {
+		return LockEntry{}, err
+	}
+	lock.put(entry)
+	if err := lock.Save(workspace); err != nil {
+		return entry, fmt.Errorf("installed %s but failed to update %s: %w", entry.Name, LockfileName, err)
+	}
+	return entry, nil
+}
+
+// Sync fetches every workflow in the lockfile at its locked version,
+// verifying its checksum, e.g. after cloning a team's workspace
+func (f *Fetcher) Sync(ctx context.Context, workspace string) ([]LockEntry, error) {
+	lock, err := ReadLockfile(workspace)
+	if err != nil {
+		return nil, err
+	}
+	var synced []LockEntry
+	for _, locked := range lock.Workflows {
+		entry, err := f.fetch(ctx, workspace, lock, locked.Source, FetchOptions{Name: locked.Name, Force: true})
+		if err != nil {
+			return synced, fmt.Errorf("%s: %w", locked.Name, err)
+		}
+		synced = append(synced, entry)
+	}
+	return synced, nil
+}
+
+func (f *Fetcher) fetch(ctx context.Context, workspace string, lock *Lockfile, ref string, opts FetchOptions) (LockEntry, error) {
+	entry, content, sig, err := f.download(ctx, lock, ref, opts)
+	if err != nil {
+		return LockEntry{}, err
+	}
+
+	sum := sha256.Sum256(content)
+	entry.SHA256 = hex.EncodeToString(sum[:])
+	if entry.Name == "" {
+		return LockEntry{}, fmt.Errorf("cannot tell the workflow name from %q; pass a name", ref)
+	}
+	if entry.Name != safeFileName(entry.Name) || entry.Name == "." || entry.Name == ".." {
+		return LockEntry{}, fmt.Errorf("invalid workflow name %q", entry.Name)
+	}
+
+	// Checksums the download must match, most specific first
+	expected := opts.SHA256
+	if locked, ok := lock.Entry(entry.Name); expected == "" && ok && !opts.Update &&
+		locked.Source == entry.Source && locked.Version == entry.Version {
+		expected = locked.SHA256
+	}
+	if expected != "" && !strings.EqualFold(expected, entry.SHA256) {
+		return LockEntry{}, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", ref, expected, entry.SHA256)
+	}
+
+	if entry.SignedBy, err = f.Trust.Check(trust.KindWorkflow, entry.Name, content, sig); err != nil {
+		return LockEntry{}, err
+	}
+
+	// Parse rejects workflows with lint errors
+	if _, err := NewParser().Parse(string(content)); err != nil {
+		return LockEntry{}, fmt.Errorf("%s is not a valid workflow: %w", ref, err)
+	}
+
+	dir := filepath.Join(workspace, "workflows")
+	dest := filepath.Join(dir, entry.Name+".md")
+	if _, err := os.Stat(dest); err == nil && !opts.Force {
+		if _, ok := lock.Entry(entry.Name); !ok {
+			return LockEntry{}, fmt.Errorf("%s exists and was not fetched; pass --force to overwrite it", dest)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return LockEntry{}, fmt.Errorf("failed to create workflows directory: %w", err)
+	}
+	if err := os.WriteFile(dest, content, 0644); err != nil {
+		return LockEntry{}, fmt.Errorf("failed to write workflow: %w", err)
+	}
+	entry.FetchedAt = determinism.Now().UTC()
+	return entry, nil
+}
+
+// download resolves ref and returns its lock entry, without checksum or
+// time, its content and its signature (nil if it has none)
+func (f *Fetcher) download(ctx context.Context, lock *Lockfile, ref string, opts FetchOptions) (LockEntry, []byte, []byte, error) {
+	if repo, file, rev, ok := parseGitRef(ref); ok {
+		entry := LockEntry{Name: opts.Name, Source: ref, URL: repo + "//" + file}
+		if entry.Name == "" {
+			entry.Name = strings.TrimSuffix(path.Base(file), ".md")
+		}
+		if locked, ok := lock.Entry(entry.Name); ok && !opts.Update && locked.Source == ref {
+			rev = locked.Version // The commit fetched before, even if the branch moved
+		}
+		content, sig, commit, err := f.gitShow(ctx, repo, file, rev)
+		if err != nil {
+			return LockEntry{}, nil, nil, err
+		}
+		entry.Version = commit
+		return entry, content, sig, nil
+	}
+
+	if strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://") {
+		entry := LockEntry{Name: opts.Name, Source: ref, URL: ref}
+		if entry.Name == "" {
+			entry.Name = strings.TrimSuffix(path.Base(ref), ".md")
+		}
+		content, sig, err := f.getSigned(ctx, ref)
+		return entry, content, sig, err
+	}
+
+	name, version, _ := strings.Cut(ref, "@")
+	if version == "" && !opts.Update {
+		if locked, ok := lock.Entry(firstNonEmpty(opts.Name, name)); ok && locked.Source == name {
+			version = locked.Version
+		}
+	}
+	reg, err := f.resolve(ctx, name, version)
+	if err != nil {
+		return LockEntry{}, nil, nil, err
+	}
+	content, sig, err := f.getSigned(ctx, reg.URL)
+	if err != nil {
+		return LockEntry{}, nil, nil, err
+	}
+	if sum := sha256.Sum256(content); !strings.EqualFold(reg.SHA256, hex.EncodeToString(sum[:])) {
+		return LockEntry{}, nil, nil, fmt.Errorf("checksum mismatch for %s@%s: the registry lists %s, got %s",
+			name, reg.Version, reg.SHA256, hex.EncodeToString(sum[:]))
+	}
+	return LockEntry{Name: firstNonEmpty(opts.Name, name), Source: name, Version: reg.Version, URL: reg.URL}, content, sig, nil
+}
+
+// resolve finds a workflow version in the registry index, the highest
+// version when none is given. Entries without a checksum are rejected.
+func (f *Fetcher) resolve(ctx context.Context, name, version string) (RegistryEntry, error) {
+	if f.Registry == "" {
+		return RegistryEntry{}, fmt.Errorf("%q looks like a registry ref but no workflow registry is configured", name)
+	}
+	data, err := f.get(ctx, f.Registry)
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+	var index RegistryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return RegistryEntry{}, fmt.Errorf("failed to parse workflow registry: %w", err)
+	}
+
+	var best RegistryEntry
+	found := false
+	for _, e := range index.Workflows {
+		if e.Name != name || (version != "" && strings.TrimPrefix(e.Version, "v") != strings.TrimPrefix(version, "v")) {
+			continue
+		}
+		if !found || compareVersions(e.Version, best.Version) > 0 {
+			best, found = e, true
+		}
+	}
+	switch {
+	case !found && version != "":
+		return RegistryEntry{}, fmt.Errorf("workflow %s@%s not found in the registry", name, version)
+	case !found:
+		return RegistryEntry{}, fmt.Errorf("workflow %s not found in the registry", name)
+	case best.SHA256 == "":
+		return RegistryEntry{}, fmt.Errorf("registry entry %s@%s has no sha256", name, best.Version)
+	}
+	return best, nil
+}
+
+// errNotFound is returned by get for HTTP 404
+var errNotFound = errors.New("not found")
+
+// getSigned downloads url and, when signatures are checked, its signature
+func (f *Fetcher) getSigned(ctx context.Context, url string) ([]byte, []byte, error) {
+	content, err := f.get(ctx, url)
+	if err != nil || f.Trust.Mode(trust.KindWorkflow) == trust.ModeOff {
+		return content, nil, err
+	}
+	sig, err := f.get(ctx, url+trust.SignatureExt)
+	if errors.Is(err, errNotFound) {
+		return content, nil, nil
+	}
+	return content, sig, err
+}
+
+func (f *Fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWorkflowSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > maxWorkflowSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxWorkflowSize)
+	}
+	return data, nil
+}
+
+// gitShow fetches rev (default HEAD) of repo shallowly and returns file's
+// content, its signature from the same commit if there is one, and the commit
+func (f *Fetcher) gitShow(ctx context.Context, repo, file, rev string) ([]byte, []byte, string, error) {
+	git := f.Git
+	if git == "" {
+		git = "git"
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	dir, err := os.MkdirTemp("", "picoclaw-workflow-")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, git, append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		out, err := cmd.Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return nil, fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return out, nil
+	}
+
+	if _, err := run("init", "--quiet"); err != nil {
+		return nil, nil, "", err
+	}
+	if _, err := run("fetch", "--quiet", "--depth", "1", "--", repo, rev); err != nil {
+		return nil, nil, "", err
+	}
+	commit, err := run("rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	content, err := run("show", "FETCH_HEAD:"+file)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(content) > maxWorkflowSize {
+		return nil, nil, "", fmt.Errorf("%s is larger than %d bytes", file, maxWorkflowSize)
+	}
+	var sig []byte
+	if f.Trust.Mode(trust.KindWorkflow) != trust.ModeOff {
+		sig, _ = run("show", "FETCH_HEAD:"+file+trust.SignatureExt) // Missing = unsigned
+	}
+	return content, sig, strings.TrimSpace(string(commit)), nil
+}
+
+// parseGitRef splits "<repo>.git//<file>[@<rev>]" into its parts
+func parseGitRef(ref string) (repo, file, rev string, ok bool) {
+	i := strings.Index(ref, ".git//")
+	if i < 0 {
+		return "", "", "", false
+	}
+	repo, file = ref[:i+len(".git")], ref[i+len(".git//"):]
+	if j := strings.LastIndex(file, "@"); j >= 0 {
+		file, rev = file[:j], file[j+1:]
+	}
+	return repo, file, rev, file != ""
+}
+
+// compareVersions orders dotted versions numerically ("1.10" > "1.9"),
+// falling back to string order for non-numeric parts
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case (xerr != nil || yerr != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// IncludeSeparator joins an include phase's name to the names of the phases
+// it includes, as in "web-triage/fingerprint"
+const IncludeSeparator = "/"
+
+// ResolveIncludes replaces every phase that includes another workflow with
+// that workflow's phases, named "<phase>/<included phase>", so one module
+// such as a web service triage can serve several methodologies. load finds
+// an included workflow by name. Included workflows may include others, but
+// never one they are part of.
+//
+// Included phases keep their steps, branches and hooks. They run only when
+// the including phase's when condition holds as well as their own, and
+// under the tool policies of the included workflow and the including phase.
+// Branches that jump to an include phase enter its first included phase.
+func ResolveIncludes(wf *Workflow, load func(name string) (*Workflow, error)) error {
+	return resolveIncludes(wf, load, []string{wf.Name})
+}
+
+func resolveIncludes(wf *Workflow, load func(name string) (*Workflow, error), chain []string) error {
+	if !slices.ContainsFunc(wf.Phases, func(p Phase) bool { return p.Include != "" }) {
+		return nil
+	}
+
+	entries := make(map[string]string) // Include phase -> its first included phase
+	phases := make([]Phase, 0, len(wf.Phases))
+	for _, phase := range wf.Phases {
+		if phase.Include == "" {
+			phases = append(phases, phase)
+			continue
+		}
+		sub, err := load(phase.Include)
+		if err != nil {
+			return fmt.Errorf("phase %s: %w", phase.Name, err)
+		}
+		if slices.Contains(chain, sub.Name) {
+			return fmt.Errorf("phase %s: workflow %s includes itself (%s)",
+				phase.Name, sub.Name, strings.Join(append(chain, sub.Name), " → "))
+		}
+		if err := resolveIncludes(sub, load, append(slices.Clone(chain), sub.Name)); err != nil {
+			return fmt.Errorf("phase %s: %w", phase.Name, err)
+		}
+
+		local := make(map[string]bool, len(sub.Phases))
+		for _, p := range sub.Phases {
+			local[p.Name] = true
+		}
+		var inherited []ToolPolicy
+		for _, policy := range []ToolPolicy{sub.Tools, phase.Tools} {
+			if !policy.IsEmpty() {
+				inherited = append(inherited, policy)
+			}
+		}
+
+		for i, p := range sub.Phases {
+			p.Name = phase.Name + IncludeSeparator + p.Name
+			p.Branches = slices.Clone(p.Branches)
+			for j, branch := range p.Branches {
+				if local[branch.TargetPhase] {
+					p.Branches[j].TargetPhase = phase.Name + IncludeSeparator + branch.TargetPhase
+				}
+			}
+			p.When = joinConditions(phase.When, p.When)
+			p.Inherited = append(slices.Clone(inherited), p.Inherited...)
+			// Phases nested deeper roll up into the outermost include
+			p.Parent = phase.Name
+			p.Source = sub.Name
+			if i == 0 {
+				entries[phase.Name] = p.Name
+			}
+			phases = append(phases, p)
+		}
+	}
+
+	for i := range phases {
+		for j, branch := range phases[i].Branches {
+			if first, ok := entries[branch.TargetPhase]; ok {
+				phases[i].Branches[j].TargetPhase = first
+			}
+		}
+	}
+	wf.Phases = phases
+	return nil
+}
+
+// joinConditions returns a condition that holds when both a and b do
+func joinConditions(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return "(" + a + ") and (" + b + ")"
+}
+
+// policies returns every tool policy that applies in the phase
+func (p Phase) policies() []ToolPolicy {
+	return append([]ToolPolicy{p.Tools}, p.Inherited...)
+}
+
+// trackSubWorkflow records entering and leaving included workflows as the
+// mission enters phase
+func (e *Engine) trackSubWorkflow(phase Phase) {
+	now := determinism.Now()
+	if n := len(e.state.SubWorkflows); n > 0 && e.state.SubWorkflows[n-1].EndTime == nil {
+		open := &e.state.SubWorkflows[n-1]
+		if open.Phase == phase.Parent {
+			return
+		}
+		open.EndTime = &now
+		logger.InfoCF(e.component, "Sub-workflow left", map[string]any{
+			"phase":    open.Phase,
+			"workflow": open.Workflow,
+		})
+	}
+	if phase.Parent == "" {
+		return
+	}
+	e.state.SubWorkflows = append(e.state.SubWorkflows, SubWorkflowExecution{
+		Phase:     phase.Parent,
+		Workflow:  phase.Source,
+		StartTime: now,
+	})
+	logger.InfoCF(e.component, "Sub-workflow entered", map[string]any{
+		"phase":    phase.Parent,
+		"workflow": phase.Source,
+	})
+}
+
+// subWorkflowPrompt rolls the progress of the workflow the current phase
+// was included from up into the mission context
+func (e *Engine) subWorkflowPrompt(phase Phase) string {
+	if phase.Parent == "" {
+		return ""
+	}
+	var names []string
+	current, done := 0, 0
+	for i, p := range e.workflow.Phases {
+		if p.Parent != phase.Parent {
+			continue
+		}
+		name := strings.TrimPrefix(p.Name, phase.Parent+IncludeSeparator)
+		switch {
+		case i == e.state.CurrentPhase:
+			current = len(names) + 1
+			name = "→ " + name + " (current)"
+		case i < e.state.CurrentPhase:
+			done++
+			name = "✓ " + name
+		default:
+			name = "○ " + name
+		}
+		names = append(names, name)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Sub-workflow: %s\n", phase.Source)
+	fmt.Fprintf(&sb, "Phase **%s** runs workflow %s: phase %d of %d, %d done.\n", phase.Parent, phase.Source, current, len(names), done)
+	for _, name := range names {
+		sb.WriteString("- " + name + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+)
+
+// AddNote records an observation in the current phase and the mission
+// journal, e.g. why a lead was dropped or what a scan result means
+func (e *Engine) AddNote(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("note is empty")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.addNote(text)
+	return e.saveState()
+}
+
+func (e *Engine) addNote(text string) {
+	if exec := e.getCurrentPhaseExecution(); exec != nil {
+		exec.Notes = append(exec.Notes, text)
+	}
+	e.journal(JournalNote, text, false)
+	e.publish(Event{Type: EventNoteAdded, Phase: e.currentPhaseName(), Text: text})
+}
+
+// RecordToolCall adds a tool call to the mission journal. summary is a
+// short rendering of the call, such as the tool name and its arguments.
+func (e *Engine) RecordToolCall(summary string, failed bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.journal(JournalTool, summary, failed)
+	e.publish(Event{Type: EventToolCalled, Phase: e.currentPhaseName(), Text: summary})
+	return e.saveState()
+}
+
+func (e *Engine) journal(kind JournalKind, text string, failed bool) {
+	e.state.Journal = append(e.state.Journal, JournalEntry{
+		Time:   determinism.Now(),
+		Kind:   kind,
+		Phase:  e.currentPhaseName(),
+		Text:   text,
+		Failed: failed,
+	})
+}
+
+// Journal is the mission's full chronological record: the Timeline plus
+// notes, completed steps and tool calls, oldest first
+func Journal(state *MissionState, findings []Finding, loc *ReportLocale) []TimelineEntry {
+	entries := Timeline(state, findings, loc)
+	for _, j := range state.Journal {
+		entry := TimelineEntry{Time: j.Time, Details: j.Text}
+		switch j.Kind {
+		case JournalNote:
+			entry.Event = loc.Note
+		case JournalStep:
+			entry.Event = loc.StepCompleted
+		case JournalTool:
+			entry.Event = loc.ToolCall
+			if j.Failed {
+				entry.Event = loc.ToolCallFailed
+			}
+		default:
+			entry.Event = string(j.Kind)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries
+}
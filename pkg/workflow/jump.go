@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"slices"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"go.starlark.net/starlark"
+)
+
+// phaseIndex returns the index of the named phase, or -1
+func (e *Engine) phaseIndex(name string) int {
+	for i, phase := range e.workflow.Phases {
+		if phase.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// branchDefinition returns the current phase's definition of the branch
+// with condition, if the workflow has one
+func (e *Engine) branchDefinition(condition string) (Branch, bool) {
+	if e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return Branch{}, false
+	}
+	for _, branch := range e.workflow.Phases[e.state.CurrentPhase].Branches {
+		if branch.Condition == condition {
+			return branch, true
+		}
+	}
+	return Branch{}, false
+}
+
+// followPendingJump moves the mission into the phase a newly activated
+// branch targets, suspending the current phase on the phase stack. Only one
+// branch jumps per event; others activated alongside it stay where they are.
+func (e *Engine) followPendingJump() {
+	condition := e.pendingJump
+	e.pendingJump = ""
+	if condition == "" {
+		return
+	}
+	var target string
+	for _, branch := range e.state.ActiveBranches {
+		if branch.Condition == condition {
+			target = branch.TargetPhase
+		}
+	}
+	index := e.phaseIndex(target)
+	if index < 0 || index == e.state.CurrentPhase {
+		if index < 0 {
+			logger.WarnCF(e.component, "Branch target phase not found", map[string]any{
+				"condition": condition,
+				"phase":     target,
+			})
+		}
+		return
+	}
+
+	frame := PhaseFrame{Phase: e.state.CurrentPhase, Branch: condition}
+	if exec := e.getCurrentPhaseExecution(); exec != nil {
+		now := determinism.Now()
+		exec.EndTime = &now
+		frame.StepsComplete = slices.Clone(exec.StepsComplete)
+	}
+	e.state.PhaseStack = append(e.state.PhaseStack, frame)
+	e.state.CurrentPhase = index
+	e.startPhaseExecution()
+
+	logger.InfoCF(e.component, "Branch jumped to phase", map[string]any{
+		"condition":  condition,
+		"from_phase": e.workflow.Phases[frame.Phase].Name,
+		"to_phase":   target,
+		"depth":      len(e.state.PhaseStack),
+	})
+	e.publish(Event{Type: EventPhaseJumped, Phase: target, Branch: condition})
+
+	e.onEvent(HookPhaseStart, starlark.StringDict{"phase": starlark.String(target)})
+}
+
+// returnFromBranch resumes the phase that the branch with condition
+// suspended, with the steps it had completed. Branches nested inside it are
+// completed on the way out. It reports whether there was anything to return
+// from.
+func (e *Engine) returnFromBranch(condition string) bool {
+	i := -1
+	for j := len(e.state.PhaseStack) - 1; j >= 0; j-- {
+		if e.state.PhaseStack[j].Branch == condition {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return false
+	}
+
+	if exec := e.getCurrentPhaseExecution(); exec != nil {
+		now := determinism.Now()
+		exec.EndTime = &now
+	}
+	for _, inner := range e.state.PhaseStack[i+1:] {
+		e.completeBranch(inner.Branch)
+	}
+
+	frame := e.state.PhaseStack[i]
+	e.state.PhaseStack = e.state.PhaseStack[:i]
+	e.state.CurrentPhase = frame.Phase
+	e.startPhaseExecution()
+	if exec := e.getCurrentPhaseExecution(); exec != nil && len(frame.StepsComplete) > 0 {
+		exec.StepsComplete = slices.Clone(frame.StepsComplete)
+	}
+
+	logger.InfoCF(e.component, "Returned from branch", map[string]any{
+		"condition": condition,
+		"phase":     e.currentPhaseName(),
+		"depth":     len(e.state.PhaseStack),
+	})
+	e.publish(Event{Type: EventPhaseJumped, Phase: e.currentPhaseName(), Branch: condition})
+	return true
+}
+
+// branchPrompt tells the agent which branch it is investigating and where
+// finishing it will return to
+func (e *Engine) branchPrompt() string {
+	n := len(e.state.PhaseStack)
+	if n == 0 {
+		return ""
+	}
+	frame := e.state.PhaseStack[n-1]
+	suspended := e.workflow.Phases[frame.Phase].Name
+	return "### Branch Investigation\n" +
+		"This phase investigates branch **" + frame.Branch + "** from phase **" + suspended + "**. " +
+		"Complete the branch, or advance once this phase is done, to resume " + suspended + " where it left off.\n\n"
+}
@@ -0,0 +1,358 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetaReportLanguage is the metadata key holding the report language code
+const MetaReportLanguage = "report_language"
+
+// ReportLocale holds the fixed text and date formats of a rendered report.
+// Finding text is written by the agent and stays as recorded; translate it
+// with Engine.TranslateReport.
+type ReportLocale struct {
+	Code     string // ISO 639-1 language code
+	Language string // English name, used in model prompts
+
+	Title                string
+	Client               string
+	Engagement           string
+	Methodology          string
+	Started              string
+	Distribution         string
+	DistributionInternal string
+	DistributionClient   string
+	Summary              string
+	Severity             string
+	Count                string
+	NoFindings           string
+	Findings             string
+	Phase                string
+	Redaction            string
+	Evidence             string
+	EvidenceWithheld     string
+	ExecutiveSummary     string
+	RiskNarrative        string
+	Effort               string
+	Operator             string
+	Agent                string
+	Total                string
+	Phases               string
+	Timeline             string
+	Time                 string
+	Event                string
+	Details              string
+	Ended                string
+	StepsCompleted       string
+	InProgress           string
+	PhaseStarted         string
+	PhaseEnded           string
+	BranchOpened         string
+	BranchClosed         string
+	Finding              string
+	Note                 string
+	StepCompleted        string
+	ToolCall             string
+	ToolCallFailed       string
+	Cost                 string
+	Tokens               string
+	Asset                string
+	Remediation          string
+	References           string
+	Artifacts            string
+
+	Severities map[Severity]string
+
+	DateLayout     string // Go layout for dates
+	DateTimeLayout string // Go layout for timestamps
+}
+
+// SeverityName returns the localized name of s, or s itself if the locale
+// has none
+func (l *ReportLocale) SeverityName(s Severity) string {
+	if name := l.Severities[s]; name != "" {
+		return name
+	}
+	return string(s)
+}
+
+// English is the default report locale
+var English = &ReportLocale{
+	Code:                 "en",
+	Language:             "English",
+	Title:                "Security Assessment Report",
+	Client:               "Client",
+	Engagement:           "Engagement",
+	Methodology:          "Methodology",
+	Started:              "Started",
+	Distribution:         "Distribution",
+	DistributionInternal: "INTERNAL ONLY - contains unredacted evidence",
+	DistributionClient:   "Client",
+	Summary:              "Summary",
+	Severity:             "Severity",
+	Count:                "Count",
+	NoFindings:           "No findings were recorded.",
+	Findings:             "Findings",
+	Phase:                "Phase",
+	Redaction:            "Redaction",
+	Evidence:             "Evidence",
+	EvidenceWithheld:     "Technical evidence withheld; available on request.",
+	ExecutiveSummary:     "Executive Summary",
+	RiskNarrative:        "Risk Narrative",
+	Effort:               "Effort",
+	Operator:             "Operator",
+	Agent:                "Agent",
+	Total:                "Total",
+	Phases:               "Phases",
+	Timeline:             "Timeline",
+	Time:                 "Time",
+	Event:                "Event",
+	Details:              "Details",
+	Ended:                "Ended",
+	StepsCompleted:       "Steps completed",
+	InProgress:           "in progress",
+	PhaseStarted:         "Phase started",
+	PhaseEnded:           "Phase ended",
+	BranchOpened:         "Branch opened",
+	BranchClosed:         "Branch closed",
+	Finding:              "Finding",
+	Note:                 "Note",
+	StepCompleted:        "Step completed",
+	ToolCall:             "Tool call",
+	ToolCallFailed:       "Tool call failed",
+	Cost:                 "Model Cost",
+	Tokens:               "Tokens",
+	Asset:                "Affected asset",
+	Remediation:          "Remediation",
+	References:           "References",
+	Artifacts:            "Artifacts",
+	Severities: map[Severity]string{
+		SeverityCritical:      "critical",
+		SeverityHigh:          "high",
+		SeverityMedium:        "medium",
+		SeverityLow:           "low",
+		SeverityInformational: "informational",
+	},
+	DateLayout:     "2006-01-02",
+	DateTimeLayout: "2006-01-02 15:04 MST",
+}
+
+var reportLocales = map[string]*ReportLocale{
+	"en": English,
+	"de": {
+		Code:                 "de",
+		Language:             "German",
+		Title:                "Bericht zur Sicherheitsüberprüfung",
+		Client:               "Auftraggeber",
+		Engagement:           "Auftrag",
+		Methodology:          "Methodik",
+		Started:              "Beginn",
+		Distribution:         "Verteiler",
+		DistributionInternal: "NUR INTERN - enthält ungeschwärzte Nachweise",
+		DistributionClient:   "Auftraggeber",
+		Summary:              "Zusammenfassung",
+		Severity:             "Schweregrad",
+		Count:                "Anzahl",
+		NoFindings:           "Es wurden keine Schwachstellen festgestellt.",
+		Findings:             "Schwachstellen",
+		Phase:                "Phase",
+		Redaction:            "Schwärzung",
+		Evidence:             "Nachweis",
+		EvidenceWithheld:     "Technische Nachweise zurückgehalten; auf Anfrage erhältlich.",
+		ExecutiveSummary:     "Management-Zusammenfassung",
+		RiskNarrative:        "Risikobewertung",
+		Effort:               "Aufwand",
+		Operator:             "Tester",
+		Agent:                "Agent",
+		Total:                "Gesamt",
+		Phases:               "Phasen",
+		Timeline:             "Zeitverlauf",
+		Time:                 "Zeit",
+		Event:                "Ereignis",
+		Details:              "Details",
+		Ended:                "Ende",
+		StepsCompleted:       "Abgeschlossene Schritte",
+		InProgress:           "läuft",
+		PhaseStarted:         "Phase begonnen",
+		PhaseEnded:           "Phase beendet",
+		BranchOpened:         "Untersuchung eröffnet",
+		BranchClosed:         "Untersuchung abgeschlossen",
+		Finding:              "Schwachstelle",
+		Note:                 "Notiz",
+		StepCompleted:        "Schritt abgeschlossen",
+		ToolCall:             "Werkzeugaufruf",
+		ToolCallFailed:       "Werkzeugaufruf fehlgeschlagen",
+		Cost:                 "Modellkosten",
+		Tokens:               "Tokens",
+		Asset:                "Betroffenes System",
+		Remediation:          "Behebung",
+		References:           "Referenzen",
+		Artifacts:            "Artefakte",
+		Severities: map[Severity]string{
+			SeverityCritical:      "kritisch",
+			SeverityHigh:          "hoch",
+			SeverityMedium:        "mittel",
+			SeverityLow:           "niedrig",
+			SeverityInformational: "informativ",
+		},
+		DateLayout:     "02.01.2006",
+		DateTimeLayout: "02.01.2006 15:04 MST",
+	},
+	"fr": {
+		Code:                 "fr",
+		Language:             "French",
+		Title:                "Rapport d'audit de sécurité",
+		Client:               "Client",
+		Engagement:           "Mission",
+		Methodology:          "Méthodologie",
+		Started:              "Début",
+		Distribution:         "Diffusion",
+		DistributionInternal: "USAGE INTERNE UNIQUEMENT - contient des preuves non expurgées",
+		DistributionClient:   "Client",
+		Summary:              "Synthèse",
+		Severity:             "Criticité",
+		Count:                "Nombre",
+		NoFindings:           "Aucune vulnérabilité n'a été relevée.",
+		Findings:             "Vulnérabilités",
+		Phase:                "Phase",
+		Redaction:            "Expurgation",
+		Evidence:             "Preuve",
+		EvidenceWithheld:     "Preuves techniques retenues ; disponibles sur demande.",
+		ExecutiveSummary:     "Synthèse managériale",
+		RiskNarrative:        "Analyse des risques",
+		Effort:               "Charge",
+		Operator:             "Auditeur",
+		Agent:                "Agent",
+		Total:                "Total",
+		Phases:               "Phases",
+		Timeline:             "Chronologie",
+		Time:                 "Heure",
+		Event:                "Événement",
+		Details:              "Détails",
+		Ended:                "Fin",
+		StepsCompleted:       "Étapes terminées",
+		InProgress:           "en cours",
+		PhaseStarted:         "Début de phase",
+		PhaseEnded:           "Fin de phase",
+		BranchOpened:         "Piste ouverte",
+		BranchClosed:         "Piste clôturée",
+		Finding:              "Vulnérabilité",
+		Note:                 "Note",
+		StepCompleted:        "Étape terminée",
+		ToolCall:             "Appel d'outil",
+		ToolCallFailed:       "Échec d'appel d'outil",
+		Cost:                 "Coût des modèles",
+		Tokens:               "Jetons",
+		Asset:                "Actif concerné",
+		Remediation:          "Correction",
+		References:           "Références",
+		Artifacts:            "Artefacts",
+		Severities: map[Severity]string{
+			SeverityCritical:      "critique",
+			SeverityHigh:          "élevée",
+			SeverityMedium:        "moyenne",
+			SeverityLow:           "faible",
+			SeverityInformational: "informative",
+		},
+		DateLayout:     "02/01/2006",
+		DateTimeLayout: "02/01/2006 15:04 MST",
+	},
+	"es": {
+		Code:                 "es",
+		Language:             "Spanish",
+		Title:                "Informe de evaluación de seguridad",
+		Client:               "Cliente",
+		Engagement:           "Proyecto",
+		Methodology:          "Metodología",
+		Started:              "Inicio",
+		Distribution:         "Distribución",
+		DistributionInternal: "SOLO USO INTERNO - contiene evidencias sin censurar",
+		DistributionClient:   "Cliente",
+		Summary:              "Resumen",
+		Severity:             "Severidad",
+		Count:                "Cantidad",
+		NoFindings:           "No se registraron hallazgos.",
+		Findings:             "Hallazgos",
+		Phase:                "Fase",
+		Redaction:            "Censura",
+		Evidence:             "Evidencia",
+		EvidenceWithheld:     "Evidencia técnica retenida; disponible bajo petición.",
+		ExecutiveSummary:     "Resumen ejecutivo",
+		RiskNarrative:        "Análisis de riesgos",
+		Effort:               "Esfuerzo",
+		Operator:             "Auditor",
+		Agent:                "Agente",
+		Total:                "Total",
+		Phases:               "Fases",
+		Timeline:             "Cronología",
+		Time:                 "Hora",
+		Event:                "Evento",
+		Details:              "Detalles",
+		Ended:                "Fin",
+		StepsCompleted:       "Pasos completados",
+		InProgress:           "en curso",
+		PhaseStarted:         "Fase iniciada",
+		PhaseEnded:           "Fase finalizada",
+		BranchOpened:         "Línea abierta",
+		BranchClosed:         "Línea cerrada",
+		Finding:              "Hallazgo",
+		Note:                 "Nota",
+		StepCompleted:        "Paso completado",
+		ToolCall:             "Llamada a herramienta",
+		ToolCallFailed:       "Llamada a herramienta fallida",
+		Cost:                 "Coste de modelos",
+		Tokens:               "Tokens",
+		Asset:                "Activo afectado",
+		Remediation:          "Remediación",
+		References:           "Referencias",
+		Artifacts:            "Artefactos",
+		Severities: map[Severity]string{
+			SeverityCritical:      "crítica",
+			SeverityHigh:          "alta",
+			SeverityMedium:        "media",
+			SeverityLow:           "baja",
+			SeverityInformational: "informativa",
+		},
+		DateLayout:     "02/01/2006",
+		DateTimeLayout: "02/01/2006 15:04 MST",
+	},
+}
+
+// LookupReportLocale returns the report locale for a language code such as
+// "de", "de-AT" or "fr_FR". Empty means English.
+func LookupReportLocale(code string) (*ReportLocale, error) {
+	lang := strings.ToLower(strings.TrimSpace(code))
+	if lang == "" {
+		return English, nil
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if loc, ok := reportLocales[lang]; ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("unsupported report language %q (want one of %s)", code, strings.Join(ReportLanguages(), ", "))
+}
+
+// ReportLanguages lists the language codes with a built-in report locale
+func ReportLanguages() []string {
+	codes := make([]string, 0, len(reportLocales))
+	for code := range reportLocales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// reportLocale returns the locale for the mission's report language,
+// falling back to English
+func reportLocale(state *MissionState) *ReportLocale {
+	code, _ := state.Metadata[MetaReportLanguage].(string)
+	loc, err := LookupReportLocale(code)
+	if err != nil {
+		return English
+	}
+	return loc
+}
@@ -0,0 +1,167 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+)
+
+// MetaSessionKey is the mission metadata key of the agent session the
+// mission runs in, so resuming it continues the same conversation
+const MetaSessionKey = "session_key"
+
+// MissionArchiveDir is where archived missions are moved, relative to the
+// workspace. Archived missions are left out of listings and of the "most
+// recently saved mission" that commands default to.
+const MissionArchiveDir = "missions/archive"
+
+// MissionSummary describes a saved mission for listings
+type MissionSummary struct {
+	StatePath  string
+	Target     string
+	Workflow   string
+	Phase      string // Current phase
+	PhaseIndex int    // 0-based index of the current phase
+	Phases     int    // Phases in the workflow, 0 if its file is gone
+	Findings   map[Severity]int
+	StartTime  time.Time
+	SavedAt    time.Time
+	Archived   bool
+}
+
+// FindingCount is the number of findings of any severity
+func (s MissionSummary) FindingCount() int {
+	n := 0
+	for _, count := range s.Findings {
+		n += count
+	}
+	return n
+}
+
+// FindingBreakdown renders the finding counts worst severity first, e.g.
+// "1 critical, 2 high"
+func (s MissionSummary) FindingBreakdown() string {
+	var parts []string
+	for _, sev := range severityOrder {
+		if n := s.Findings[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, sev))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ListMissions summarizes the saved missions in workspace, most recently
+// saved first, or the archived ones when archived is set. Unreadable state
+// files are skipped.
+func ListMissions(workspace string, archived bool) ([]MissionSummary, error) {
+	paths, err := missionPaths(workspace, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]MissionSummary, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		state, err := ReadMissionState(path)
+		if err != nil {
+			continue
+		}
+		summary := SummarizeMission(workspace, state)
+		summary.StatePath = path
+		summary.SavedAt = info.ModTime()
+		summary.Archived = archived
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].SavedAt.After(summaries[j].SavedAt) })
+	return summaries, nil
+}
+
+// missionPaths returns the state files of the saved missions in workspace,
+// or of the archived ones when archived is set
+func missionPaths(workspace string, archived bool) ([]string, error) {
+	if archived {
+		return filepath.Glob(filepath.Join(workspace, filepath.FromSlash(MissionArchiveDir), "*_state.json"))
+	}
+	return MissionStatePaths(workspace)
+}
+
+// LoadMissionStates reads the saved missions in workspace, or the archived
+// ones when archived is set. Unreadable state files are skipped.
+func LoadMissionStates(workspace string, archived bool) ([]*MissionState, error) {
+	paths, err := missionPaths(workspace, archived)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*MissionState, 0, len(paths))
+	for _, path := range paths {
+		state, err := ReadMissionState(path)
+		if err != nil {
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// SummarizeMission describes a mission state. The workflow is loaded from
+// workspace for the phase count and names, if it still exists.
+func SummarizeMission(workspace string, state *MissionState) MissionSummary {
+	summary := MissionSummary{
+		Target:     state.Target,
+		Workflow:   state.WorkflowName,
+		PhaseIndex: state.CurrentPhase,
+		Findings:   make(map[Severity]int),
+		StartTime:  state.StartTime,
+	}
+	if wf, err := LoadWorkflow(workspace, state.WorkflowName); err == nil {
+		summary.Phases = len(wf.Phases)
+		if state.CurrentPhase >= 0 && state.CurrentPhase < len(wf.Phases) {
+			summary.Phase = wf.Phases[state.CurrentPhase].Name
+		}
+	}
+	if summary.Phase == "" && len(state.PhaseHistory) > 0 {
+		summary.Phase = state.PhaseHistory[len(state.PhaseHistory)-1].PhaseName
+	}
+	for _, f := range state.Findings {
+		summary.Findings[f.Severity]++
+	}
+	return summary
+}
+
+// ArchiveMission moves a saved mission's state file, and its working
+// directory if it has one, into the archive. The archived copies get a
+// timestamp so a target can be archived more than once. It returns the
+// archived state file's path.
+func ArchiveMission(workspace, statePath string) (string, error) {
+	if _, err := os.Stat(statePath); err != nil {
+		return "", fmt.Errorf("no saved mission at %s", statePath)
+	}
+	archiveDir := filepath.Join(workspace, filepath.FromSlash(MissionArchiveDir))
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(statePath), "_state.json")
+	archivedName := name + "_" + determinism.Now().Format("20060102_150405")
+	archivedPath := filepath.Join(archiveDir, archivedName+"_state.json")
+	if err := os.Rename(statePath, archivedPath); err != nil {
+		return "", fmt.Errorf("failed to archive mission: %w", err)
+	}
+
+	// Scripts' working files live in the mission directory next to the state
+	missionDir := filepath.Join(filepath.Dir(statePath), name)
+	if info, err := os.Stat(missionDir); err == nil && info.IsDir() {
+		if err := os.Rename(missionDir, filepath.Join(archiveDir, archivedName)); err != nil {
+			return archivedPath, fmt.Errorf("archived the mission state but not its directory: %w", err)
+		}
+	}
+	return archivedPath, nil
+}
@@ -0,0 +1,203 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"go.starlark.net/starlark"
+)
+
+// DelegateBranch hands the branch with condition to a sub-agent running in
+// session, creating the branch if needed. The sub-agent's findings and spend
+// stay with the branch until it completes, then join the mission. Branches
+// that jump to another phase can't be delegated, since the mission can only
+// be in one phase at a time.
+func (e *Engine) DelegateBranch(condition, description, session string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if def, ok := e.branchDefinition(condition); ok && def.TargetPhase != "" {
+		return fmt.Errorf("branch %s continues in phase %s and can't run in parallel", condition, def.TargetPhase)
+	}
+
+	branch := e.findBranch(condition)
+	if branch == nil {
+		if strings.TrimSpace(description) == "" {
+			return fmt.Errorf("branch %s doesn't exist yet and needs a description", condition)
+		}
+		e.activateBranch(condition, description)
+		branch = e.findBranch(condition)
+		branch.Session = session
+		e.onEvent(HookBranch, starlark.StringDict{"condition": starlark.String(condition)})
+		// Hooks may have changed the branch list
+		if branch = e.findBranch(condition); branch == nil {
+			return fmt.Errorf("branch not found: %s", condition)
+		}
+	}
+	if branch.CompletedAt != nil {
+		return fmt.Errorf("branch %s is already complete", condition)
+	}
+	if branch.Session != "" && branch.Session != session {
+		return fmt.Errorf("branch %s is already delegated to session %s", condition, branch.Session)
+	}
+	branch.Session = session
+
+	logger.InfoCF(e.component, "Branch delegated", map[string]any{
+		"condition": condition,
+		"session":   session,
+	})
+	return e.saveState()
+}
+
+// BranchBrief is the sub-agent's view of the mission: the engagement, the
+// phase it runs in and the branch it investigates, with the findings already
+// recorded so it doesn't report them again
+func (e *Engine) BranchBrief(condition string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	branch := e.findBranch(condition)
+	if branch == nil {
+		return "", fmt.Errorf("branch not found: %s", condition)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Branch Investigation: %s\n\n", branch.Condition))
+	sb.WriteString(fmt.Sprintf("You are investigating one branch of the %s mission, in parallel with the main agent.\n\n", e.workflow.Name))
+	if e.state.Target != "" {
+		sb.WriteString(fmt.Sprintf("**Target**: %s\n", e.state.Target))
+	}
+	sb.WriteString(fmt.Sprintf("**Phase**: %s\n\n", e.currentPhaseName()))
+	sb.WriteString(fmt.Sprintf("## Your Task\n%s\n\n", expandTemplate(branch.Description, e.templateVars())))
+	sb.WriteString(e.engagementPrompt())
+	sb.WriteString(e.scopePrompt())
+
+	if len(e.state.Findings) > 0 {
+		sb.WriteString("## Already Reported\nDon't report these again:\n")
+		for _, f := range e.state.Findings {
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n", f.Severity, f.Title))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Rules\n")
+	sb.WriteString("- Stay within this branch; the main agent covers everything else.\n")
+	sb.WriteString("- Record each confirmed issue with workflow_add_finding as you go.\n")
+	sb.WriteString("- Finish with a short summary of what you checked and what you found.\n")
+	return sb.String(), nil
+}
+
+// RecordBranchFinding adds a finding to a delegated branch. The finding is
+// scored and filled in like RecordFinding's, but joins the mission only when
+// the branch completes, or at once if it already has.
+func (e *Engine) RecordBranchFinding(condition string, finding Finding) (Finding, error) {
+	if err := scoreFinding(&finding); err != nil {
+		return Finding{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	branch := e.findBranch(condition)
+	if branch == nil {
+		return Finding{}, fmt.Errorf("branch not found: %s", condition)
+	}
+
+	finding.ID = determinism.NewUUID().String()
+	finding.Phase = e.currentPhaseName()
+	finding.CreatedAt = determinism.Now()
+	if finding.Redaction == "" {
+		finding.Redaction = RedactionFull
+	}
+	if finding.Metadata == nil {
+		finding.Metadata = make(map[string]interface{})
+	}
+	finding.Metadata["branch"] = condition
+
+	branch.Findings = append(branch.Findings, finding)
+	logger.InfoCF(e.component, "Branch finding added", map[string]any{
+		"branch":   condition,
+		"title":    finding.Title,
+		"severity": finding.Severity,
+	})
+	if branch.CompletedAt != nil {
+		e.mergeFinding(finding)
+	}
+	return cloneFindings([]Finding{finding})[0], e.saveState()
+}
+
+// FinishBranch records what a sub-agent reported and spent on a delegated
+// branch, then completes the branch, merging its findings and spend into the
+// mission. It returns how many findings the branch contributed.
+func (e *Engine) FinishBranch(condition, summary string, usd float64, tokens int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	branch := e.findBranch(condition)
+	if branch == nil {
+		return 0, fmt.Errorf("branch not found: %s", condition)
+	}
+	branch.Summary = summary
+	branch.CostUSD += usd
+	branch.Tokens += tokens
+	if branch.CompletedAt != nil {
+		// Completed early by the main agent: its findings are merged, the
+		// spend isn't yet
+		e.addBranchCost(usd, tokens)
+	} else {
+		e.completeBranch(condition)
+	}
+	// Hooks may have changed the branch list
+	merged := 0
+	if branch = e.findBranch(condition); branch != nil {
+		merged = len(branch.Findings)
+	}
+	return merged, e.saveState()
+}
+
+// mergeBranch moves what a delegated branch found and spent into the mission
+func (e *Engine) mergeBranch(branch *ActiveBranch) {
+	if branch.Session == "" {
+		return
+	}
+	e.addBranchCost(branch.CostUSD, branch.Tokens)
+	findings := cloneFindings(branch.Findings)
+	condition := branch.Condition
+	logger.InfoCF(e.component, "Branch merged", map[string]any{
+		"branch":   condition,
+		"findings": len(findings),
+		"cost_usd": branch.CostUSD,
+	})
+	// Finding hooks can add branches, which moves branch, so only the
+	// copies are used from here on
+	for _, f := range findings {
+		e.mergeFinding(f)
+	}
+}
+
+// mergeFinding adds a branch finding to the mission as RecordFinding would
+func (e *Engine) mergeFinding(finding Finding) {
+	e.state.Findings = append(e.state.Findings, finding)
+	published := finding
+	e.publish(Event{Type: EventFinding, Phase: finding.Phase, Finding: &published})
+	e.onEvent(HookFinding, findingFields(finding))
+}
+
+// addBranchCost adds sub-agent spend to the phase the mission is in
+func (e *Engine) addBranchCost(usd float64, tokens int) {
+	if exec := e.getCurrentPhaseExecution(); exec != nil {
+		exec.CostUSD += usd
+		exec.Tokens += tokens
+	}
+}
+
+func (e *Engine) findBranch(condition string) *ActiveBranch {
+	for i := range e.state.ActiveBranches {
+		if e.state.ActiveBranches[i].Condition == condition {
+			return &e.state.ActiveBranches[i]
+		}
+	}
+	return nil
+}
@@ -2,12 +2,15 @@ package workflow
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
 )
 
 // Parser parses workflow definitions from markdown files
@@ -38,9 +41,10 @@ func (p *Parser) Parse(content string) (*Workflow, error) {
 
 	// Parse frontmatter
 	var metadata struct {
-		Name        string   `yaml:"name"`
-		Description string   `yaml:"description"`
-		Phases      []string `yaml:"phases"`
+		Name        string     `yaml:"name"`
+		Description string     `yaml:"description"`
+		Phases      []string   `yaml:"phases"`
+		Tools       ToolPolicy `yaml:"tools"`
 	}
 
 	if err := yaml.Unmarshal([]byte(parts[1]), &metadata); err != nil {
@@ -52,6 +56,7 @@ func (p *Parser) Parse(content string) (*Workflow, error) {
 		Name:        metadata.Name,
 		Description: metadata.Description,
 		Phases:      make([]Phase, 0),
+		Tools:       metadata.Tools,
 	}
 
 	phases, err := p.parseBody(parts[2])
@@ -60,6 +65,14 @@ func (p *Parser) Parse(content string) (*Workflow, error) {
 	}
 
 	workflow.Phases = phases
+
+	// Errors stop the workflow from loading; warnings don't
+	if err := Validate(workflow); err != nil {
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.HasErrors() {
+			return nil, err
+		}
+	}
 	return workflow, nil
 }
 
@@ -100,6 +113,18 @@ func (p *Parser) parseBody(body string) ([]Phase, error) {
 			continue
 		}
 
+		// "when: <expr>" right under the phase header makes the phase
+		// conditional; "include: <workflow>" runs another workflow's phases
+		// in its place
+		if currentSection == "" {
+			if when, ok := scriptLine(trimmed, "when:"); ok {
+				currentPhase.When = when
+			} else if include, ok := scriptLine(trimmed, "include:"); ok {
+				currentPhase.Include = include
+			}
+			continue
+		}
+
 		// Parse content based on current section
 		switch currentSection {
 		case "steps":
@@ -108,9 +133,31 @@ func (p *Parser) parseBody(body string) ([]Phase, error) {
 				if step != nil {
 					currentPhase.Steps = append(currentPhase.Steps, *step)
 				}
+			} else if deps, ok := scriptLine(trimmed, "depends_on:"); ok && len(currentPhase.Steps) > 0 {
+				// "depends_on: a, b" under a step blocks it until steps a and b are complete
+				step := &currentPhase.Steps[len(currentPhase.Steps)-1]
+				for _, dep := range strings.Split(deps, ",") {
+					if dep = strings.TrimSpace(dep); dep != "" {
+						step.DependsOn = append(step.DependsOn, dep)
+					}
+				}
+			} else if names, ok := scriptLine(trimmed, "tools:"); ok && len(currentPhase.Steps) > 0 {
+				// "tools: nuclei, httpx" under a step names the tools it needs
+				step := &currentPhase.Steps[len(currentPhase.Steps)-1]
+				for _, name := range strings.Split(names, ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						step.Tools = append(step.Tools, name)
+					}
+				}
 			}
 
 		case "completion criteria", "completion":
+			// "script: <expr>" makes completion a Starlark expression
+			if script, ok := scriptLine(trimmed, "script:"); ok {
+				currentPhase.Completion.Script = script
+				currentPhase.Completion.Type = CompletionScript
+				continue
+			}
 			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
 				// Accumulate completion description
 				if currentPhase.Completion.Description != "" {
@@ -118,6 +165,11 @@ func (p *Parser) parseBody(body string) ([]Phase, error) {
 				}
 				currentPhase.Completion.Description += trimmed
 
+				// Prose next to a script only describes it
+				if currentPhase.Completion.Script != "" {
+					continue
+				}
+
 				// Determine completion type
 				if strings.Contains(strings.ToLower(trimmed), "all") && strings.Contains(strings.ToLower(trimmed), "required") {
 					currentPhase.Completion.Type = CompletionAllRequired
@@ -134,6 +186,27 @@ func (p *Parser) parseBody(body string) ([]Phase, error) {
 				if branch != nil {
 					currentPhase.Branches = append(currentPhase.Branches, *branch)
 				}
+			} else if when, ok := scriptLine(trimmed, "when:"); ok && len(currentPhase.Branches) > 0 {
+				// "when: <expr>" under a branch activates it automatically
+				currentPhase.Branches[len(currentPhase.Branches)-1].When = when
+			} else if target, ok := scriptLine(trimmed, "phase:"); ok && len(currentPhase.Branches) > 0 {
+				// "phase: <name>" under a branch names the phase it jumps to
+				currentPhase.Branches[len(currentPhase.Branches)-1].TargetPhase = target
+			}
+
+		case "tools":
+			if strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "*") {
+				if err := parseToolPolicyLine(trimmed, &currentPhase.Tools); err != nil {
+					return nil, fmt.Errorf("phase %s: %w", currentPhase.Name, err)
+				}
+			}
+
+		case "hooks":
+			if strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "*") {
+				hook := p.parseHook(trimmed)
+				if hook != nil {
+					currentPhase.Hooks = append(currentPhase.Hooks, *hook)
+				}
 			}
 		}
 	}
@@ -223,8 +296,73 @@ func (p *Parser) parseBranch(line string) *Branch {
 	}
 }
 
-// LoadWorkflow loads a workflow from the workspace
+// parseHook parses a hook line
+// Format: "- on_finding: <starlark statements>"
+func (p *Parser) parseHook(line string) *Hook {
+	line = strings.TrimPrefix(line, "-")
+	line = strings.TrimPrefix(line, "*")
+	line = strings.TrimSpace(line)
+
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	script := strings.TrimSpace(strings.Trim(strings.TrimSpace(parts[1]), "`"))
+	if script == "" {
+		return nil
+	}
+
+	return &Hook{
+		Event:  strings.TrimSpace(parts[0]),
+		Script: script,
+	}
+}
+
+// scriptLine returns the script after prefix, dropping inline-code backticks
+func scriptLine(line, prefix string) (string, bool) {
+	if !strings.HasPrefix(strings.ToLower(line), prefix) {
+		return "", false
+	}
+	script := strings.TrimSpace(line[len(prefix):])
+	script = strings.TrimSpace(strings.Trim(script, "`"))
+	return script, script != ""
+}
+
+// LoadWorkflow loads a workflow from the workspace, with the workflows its
+// phases include
 func LoadWorkflow(workspace, name string) (*Workflow, error) {
+	wf, err := loadWorkflowFile(workspace, name)
+	if err != nil {
+		return nil, err
+	}
+	err = ResolveIncludes(wf, func(include string) (*Workflow, error) {
+		return loadWorkflowFile(workspace, include)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", wf.Name, err)
+	}
+
+	// Parse already rejected errors in each file, so only warnings are
+	// left unless the included phases clash with the workflow's own
+	var verr *ValidationError
+	if errors.As(Validate(wf), &verr) {
+		if verr.HasErrors() {
+			return nil, verr
+		}
+		for _, issue := range verr.Issues {
+			logger.WarnCF("workflow", "Workflow lint warning", map[string]any{
+				"workflow": wf.Name,
+				"phase":    issue.Phase,
+				"check":    issue.Check,
+				"message":  issue.Message,
+			})
+		}
+	}
+	return wf, nil
+}
+
+// loadWorkflowFile finds and parses a single workflow file in the workspace
+func loadWorkflowFile(workspace, name string) (*Workflow, error) {
 	parser := NewParser()
 
 	// Try various locations
@@ -236,9 +374,10 @@ func LoadWorkflow(workspace, name string) (*Workflow, error) {
 	}
 
 	for _, path := range locations {
-		if _, err := os.Stat(path); err == nil {
-			return parser.ParseFile(path)
+		if _, err := os.Stat(path); err != nil {
+			continue
 		}
+		return parser.ParseFile(path)
 	}
 
 	return nil, fmt.Errorf("workflow not found: %s", name)
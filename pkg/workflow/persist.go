@@ -0,0 +1,143 @@
+package workflow
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// checksumPrefix starts a sealed state file: its first field is a SHA-256 of
+// the file without that line
+const checksumPrefix = "{\n  \"checksum\": \"sha256:"
+
+// sealState adds a checksum line to data, indented JSON of a mission state
+func sealState(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	sealed := make([]byte, 0, len(data)+len(checksumPrefix)+68)
+	sealed = append(sealed, checksumPrefix...)
+	sealed = append(sealed, hex.EncodeToString(sum[:])...)
+	sealed = append(sealed, "\",\n"...)
+	return append(sealed, bytes.TrimPrefix(data, []byte("{\n"))...)
+}
+
+// decodeMissionState checks a state file's checksum and parses it. Files
+// saved before checksums were added have none and are only parsed.
+func decodeMissionState(data []byte) (*MissionState, error) {
+	if rest, ok := bytes.CutPrefix(data, []byte(checksumPrefix)); ok {
+		sum, body, ok := bytes.Cut(rest, []byte("\",\n"))
+		if !ok {
+			return nil, fmt.Errorf("malformed checksum")
+		}
+		actual := sha256.Sum256(append([]byte("{\n"), body...))
+		if hex.EncodeToString(actual[:]) != string(sum) {
+			return nil, fmt.Errorf("checksum mismatch: the file is damaged or was edited by hand")
+		}
+	}
+
+	var state MissionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse mission state: %w", err)
+	}
+	return &state, nil
+}
+
+// ReadMissionState reads a saved mission state without loading its workflow.
+// A damaged state file is read from the newest intact revision instead,
+// which the next save writes back.
+func ReadMissionState(path string) (*MissionState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	state, err := decodeMissionState(data)
+	if err == nil {
+		return state, nil
+	}
+
+	recovered, revision, recoverErr := recoverMissionState(path)
+	if recoverErr != nil {
+		return nil, fmt.Errorf("%w (%v)", err, recoverErr)
+	}
+	logger.WarnCF("workflow", "Mission state damaged, recovered from revision", map[string]any{
+		"path":     path,
+		"error":    err.Error(),
+		"revision": revision,
+	})
+	return recovered, nil
+}
+
+// recoverMissionState returns the newest intact revision of the mission
+// saved at path
+func recoverMissionState(path string) (*MissionState, int, error) {
+	name := strings.TrimSuffix(filepath.Base(path), "_state.json")
+	dir := filepath.Join(filepath.Dir(path), name, "revisions")
+	numbers, err := revisionNumbers(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := len(numbers) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(filepath.Join(dir, revisionFileName(numbers[i])))
+		if err != nil {
+			continue
+		}
+		if state, err := decodeMissionState(data); err == nil {
+			return state, numbers[i], nil
+		}
+	}
+	return nil, 0, fmt.Errorf("no intact revision to recover from")
+}
+
+// writeFileAtomic replaces path with data so that a crash leaves either the
+// old file or the new one, never a mix: data goes to a temporary file in
+// the same directory, is synced to disk, then renamed over path.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmpFile.Name()
+	cleanup := true
+	defer func() {
+		if cleanup {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(0o644); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	cleanup = false
+
+	// Sync the directory so the rename itself survives a crash; not every
+	// platform can, so failing here isn't an error
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+)
+
+// ReportAudience selects which version of a mission report to render
+type ReportAudience string
+
+const (
+	ReportInternal ReportAudience = "internal" // Every finding with its evidence
+	ReportClient   ReportAudience = "client"   // Findings filtered by their redaction level
+)
+
+var severityOrder = []Severity{
+	SeverityCritical,
+	SeverityHigh,
+	SeverityMedium,
+	SeverityLow,
+	SeverityInformational,
+}
+
+func severityRank(s Severity) int {
+	for i, sev := range severityOrder {
+		if sev == s {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// ReportFindings returns the findings the audience may see, highest CVSS
+// score first; findings without one rank by their severity. Client reports
+// drop internal findings and the evidence and metadata of partial ones;
+// their title, description, remediation and references are shown as written.
+func ReportFindings(state *MissionState, audience ReportAudience) []Finding {
+	findings := make([]Finding, 0, len(state.Findings))
+	for _, f := range state.Findings {
+		if audience == ReportClient {
+			switch f.Redaction {
+			case RedactionInternal:
+				continue
+			case RedactionPartial:
+				// Metadata holds evidence artifacts and whatever else the
+				// agent recorded, so none of it reaches the client
+				f.Evidence = ""
+				f.Metadata = nil
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if si, sj := findings[i].RiskScore(), findings[j].RiskScore(); si != sj {
+			return si > sj
+		}
+		ri, rj := severityRank(findings[i].Severity), severityRank(findings[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return findings[i].CreatedAt.Before(findings[j].CreatedAt)
+	})
+	return findings
+}
+
+// RenderReport renders a Markdown mission report for the given audience in
+// the mission's report language. Both versions come from the same state so
+// they never drift apart.
+func RenderReport(wf *Workflow, state *MissionState, audience ReportAudience) string {
+	findings := ReportFindings(state, audience)
+	loc := reportLocale(state)
+
+	var sb strings.Builder
+	writeReportHeader(&sb, wf, state, audience)
+
+	sb.WriteString("## " + loc.Summary + "\n\n")
+	if len(findings) == 0 {
+		sb.WriteString(loc.NoFindings + "\n\n")
+	} else {
+		counts := make(map[Severity]int)
+		for _, f := range findings {
+			counts[f.Severity]++
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n|---|---|\n", loc.Severity, loc.Count))
+		for _, sev := range severityOrder {
+			if counts[sev] > 0 {
+				sb.WriteString(fmt.Sprintf("| %s | %d |\n", loc.SeverityName(sev), counts[sev]))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	efforts := state.Effort()
+	if TotalEffort(efforts).Total() > 0 {
+		sb.WriteString("## " + loc.Effort + "\n\n")
+		writeEffortTable(&sb, efforts, loc)
+	}
+	// What the mission cost to run is for the tester, not the client
+	if total := TotalEffort(efforts); audience == ReportInternal && (total.Cost > 0 || total.Tokens > 0) {
+		sb.WriteString("## " + loc.Cost + "\n\n")
+		writeCostTable(&sb, efforts, loc)
+	}
+
+	writeFindings(&sb, findings, audience, loc)
+	writePhaseTable(&sb, wf, state, loc)
+	writeTimeline(&sb, state, findings, audience, loc)
+	return sb.String()
+}
+
+// writeFindings writes each finding with its evidence, as far as the
+// audience may see it
+func writeFindings(sb *strings.Builder, findings []Finding, audience ReportAudience, loc *ReportLocale) {
+	if len(findings) == 0 {
+		return
+	}
+
+	sb.WriteString("## " + loc.Findings + "\n\n")
+	for i, f := range findings {
+		sb.WriteString(fmt.Sprintf("### %d. %s\n\n", i+1, f.Title))
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Severity, loc.SeverityName(f.Severity)))
+		if f.CVSSScore > 0 || f.CVSSVector != "" {
+			cvss := fmt.Sprintf("%.1f", f.CVSSScore)
+			if f.CVSSVector != "" {
+				cvss += " (`" + f.CVSSVector + "`)"
+			}
+			sb.WriteString("- **CVSS**: " + cvss + "\n")
+		}
+		if f.CWE != "" {
+			sb.WriteString("- **CWE**: " + f.CWE + "\n")
+		}
+		if f.Asset != "" {
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Asset, f.Asset))
+		}
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Phase, f.Phase))
+		if audience == ReportInternal && f.Redaction != "" && f.Redaction != RedactionFull {
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Redaction, f.Redaction))
+		}
+		sb.WriteString("\n")
+		if f.Description != "" {
+			sb.WriteString(f.Description + "\n\n")
+		}
+		switch {
+		case f.Evidence != "":
+			sb.WriteString("**" + loc.Evidence + "**\n\n```\n" + strings.TrimRight(f.Evidence, "\n") + "\n```\n\n")
+		case audience == ReportClient && f.Redaction == RedactionPartial:
+			sb.WriteString("_" + loc.EvidenceWithheld + "_\n\n")
+		}
+		writeArtifacts(sb, FindingEvidence(f), loc)
+		if f.Remediation != "" {
+			sb.WriteString("**" + loc.Remediation + "**\n\n" + f.Remediation + "\n\n")
+		}
+		if len(f.References) > 0 {
+			sb.WriteString("**" + loc.References + "**\n\n")
+			for _, ref := range f.References {
+				sb.WriteString("- " + ref + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+// writeArtifacts lists a finding's evidence artifacts, with screenshots
+// shown inline. Links are relative to the reports directory.
+func writeArtifacts(sb *strings.Builder, artifacts []EvidenceArtifact, loc *ReportLocale) {
+	if len(artifacts) == 0 {
+		return
+	}
+	sb.WriteString("**" + loc.Artifacts + "**\n\n")
+	for _, a := range artifacts {
+		link := "../" + a.Path
+		label := a.Name
+		if a.Description != "" {
+			label += ": " + a.Description
+		}
+		sb.WriteString(fmt.Sprintf("- [%s](%s) (%s, %d bytes, SHA-256 `%s`)\n", label, link, a.Kind, a.Size, a.SHA256))
+	}
+	sb.WriteString("\n")
+	for _, a := range artifacts {
+		if a.IsImage() {
+			sb.WriteString(fmt.Sprintf("![%s](%s)\n\n", a.Name, "../"+a.Path))
+		}
+	}
+}
+
+func reportTitle(state *MissionState) string {
+	if state.Target != "" {
+		return state.Target
+	}
+	return state.WorkflowName
+}
+
+func writeReportHeader(sb *strings.Builder, wf *Workflow, state *MissionState, audience ReportAudience) {
+	loc := reportLocale(state)
+	sb.WriteString(fmt.Sprintf("# %s: %s\n\n", loc.Title, reportTitle(state)))
+	if client, _ := state.Metadata[MetaClient].(string); client != "" {
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Client, client))
+	}
+	if id, _ := state.Metadata[MetaEngagementID].(string); id != "" {
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Engagement, id))
+	}
+	sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Methodology, wf.Name))
+	sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Started, state.StartTime.Format(loc.DateTimeLayout)))
+	if audience == ReportInternal {
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Distribution, loc.DistributionInternal))
+	} else {
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", loc.Distribution, loc.DistributionClient))
+	}
+	sb.WriteString("\n")
+}
+
+// ApplyReportTemplate renders a client's Markdown report template. {report}
+// is replaced with the rendered report, and {target}, {workflow}, {audience},
+// {date} (in the report language's format) and mission metadata such as
+// {client} with their values. Unknown placeholders are left as written.
+func ApplyReportTemplate(tmpl string, wf *Workflow, state *MissionState, audience ReportAudience) string {
+	values := map[string]string{
+		"report":   RenderReport(wf, state, audience),
+		"target":   state.Target,
+		"workflow": wf.Name,
+		"audience": string(audience),
+		"date":     determinism.Now().Format(reportLocale(state).DateLayout),
+	}
+	for key, value := range state.Metadata {
+		if _, ok := values[key]; ok || value == nil {
+			continue
+		}
+		switch v := value.(type) {
+		case []string:
+			values[key] = strings.Join(v, "; ")
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, "; ")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	pairs := make([]string, 0, 2*len(values))
+	for key, value := range values {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// GenerateReports writes the internal and client versions of the mission
+// report to <workspace>/reports as Markdown and returns their paths
+func (e *Engine) GenerateReports() (internalPath, clientPath string, err error) {
+	return e.GenerateReport(ReportMarkdown)
+}
+
+// GenerateReport writes the internal and client versions of the mission
+// report to <workspace>/reports in format and returns their paths. PDF
+// reports keep the HTML they were printed from next to them.
+func (e *Engine) GenerateReport(format ReportFormat) (internalPath, clientPath string, err error) {
+	e.mu.Lock()
+	state := e.state.clone()
+	name := e.missionFileName()
+	e.mu.Unlock()
+
+	reportDir := filepath.Join(e.workspace, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	render, err := e.reportRenderer(state)
+	if err != nil {
+		return "", "", err
+	}
+
+	loc := reportLocale(state)
+	title := fmt.Sprintf("%s: %s", loc.Title, reportTitle(state))
+	paths := make(map[ReportAudience]string, 2)
+	for _, audience := range []ReportAudience{ReportInternal, ReportClient} {
+		base := filepath.Join(reportDir, name+"_"+string(audience))
+		path, err := writeReport(base, format, render(audience), title, loc.Code)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to write %s report: %w", audience, err)
+		}
+		paths[audience] = path
+	}
+
+	return paths[ReportInternal], paths[ReportClient], nil
+}
+
+// reportRenderer renders state with the mission's report template, if any
+func (e *Engine) reportRenderer(state *MissionState) (func(ReportAudience) string, error) {
+	path, _ := state.Metadata[MetaReportTemplate].(string)
+	if path == "" {
+		return func(audience ReportAudience) string { return RenderReport(e.workflow, state, audience) }, nil
+	}
+	tmpl, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report template: %w", err)
+	}
+	return func(audience ReportAudience) string {
+		return ApplyReportTemplate(string(tmpl), e.workflow, state, audience)
+	}, nil
+}
+
+// TranslateReport renders the report for the audience with language's
+// labels and date formats, has pipeline translate the finding text, and
+// writes it to <workspace>/reports/<mission>_<audience>_<language>.md.
+// An empty language means the mission's report language.
+func (e *Engine) TranslateReport(ctx context.Context, pipeline *ReportPipeline, audience ReportAudience, language string) (string, error) {
+	e.mu.Lock()
+	state := e.state.clone()
+	name := e.missionFileName()
+	e.mu.Unlock()
+
+	if language == "" {
+		language, _ = state.Metadata[MetaReportLanguage].(string)
+	}
+	loc, err := LookupReportLocale(language)
+	if err != nil {
+		return "", err
+	}
+	if loc == English {
+		return "", fmt.Errorf("no report language to translate into; set %s or pass a language", MetaReportLanguage)
+	}
+	if state.Metadata == nil {
+		state.Metadata = make(map[string]interface{})
+	}
+	state.Metadata[MetaReportLanguage] = loc.Code
+
+	render, err := e.reportRenderer(state)
+	if err != nil {
+		return "", err
+	}
+	report, err := pipeline.Translate(ctx, render(audience), loc)
+	if err != nil {
+		return "", err
+	}
+
+	reportDir := filepath.Join(e.workspace, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	path := filepath.Join(reportDir, fmt.Sprintf("%s_%s_%s.md", name, audience, loc.Code))
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		return "", fmt.Errorf("failed to write translated report: %w", err)
+	}
+	return path, nil
+}
+
+// DraftReport drafts a prose report for the audience with pipeline and
+// writes it to <workspace>/reports/<mission>_<audience>_draft.md
+func (e *Engine) DraftReport(ctx context.Context, pipeline *ReportPipeline, audience ReportAudience) (string, error) {
+	e.mu.Lock()
+	state := e.state.clone()
+	name := e.missionFileName()
+	e.mu.Unlock()
+
+	report, err := pipeline.Run(ctx, e.workflow, state, audience)
+	if err != nil {
+		return "", err
+	}
+
+	reportDir := filepath.Join(e.workspace, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	path := filepath.Join(reportDir, fmt.Sprintf("%s_%s_draft.md", name, audience))
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		return "", fmt.Errorf("failed to write report draft: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// ReportFormat is the file format a mission report is written in
+type ReportFormat string
+
+const (
+	ReportMarkdown ReportFormat = "markdown"
+	ReportHTML     ReportFormat = "html" // A standalone page with inline styles
+	ReportPDF      ReportFormat = "pdf"  // The HTML page printed by wkhtmltopdf or headless Chrome
+)
+
+// pdfTimeout bounds printing one report to PDF
+const pdfTimeout = 2 * time.Minute
+
+// ParseReportFormat reads a report format name. Empty means Markdown.
+func ParseReportFormat(s string) (ReportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "md", "markdown":
+		return ReportMarkdown, nil
+	case "html", "htm":
+		return ReportHTML, nil
+	case "pdf":
+		return ReportPDF, nil
+	}
+	return "", fmt.Errorf("unknown report format %q (want markdown, html or pdf)", s)
+}
+
+//go:embed report.html
+var reportPageSource string
+
+var reportPage = template.Must(template.New("report").Parse(reportPageSource))
+
+// RenderHTMLReport renders a Markdown report as a standalone HTML page.
+// Raw HTML in the Markdown, such as a payload quoted in a finding, is
+// dropped rather than rendered.
+func RenderHTMLReport(markdown, title, lang string) (string, error) {
+	var body bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.Table, extension.Strikethrough))
+	if err := md.Convert([]byte(markdown), &body); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+
+	var page bytes.Buffer
+	err := reportPage.Execute(&page, map[string]any{
+		"Title": title,
+		"Lang":  lang,
+		"Body":  template.HTML(body.String()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return page.String(), nil
+}
+
+// writeReport writes a rendered Markdown report to base plus the format's
+// extension and returns the path
+func writeReport(base string, format ReportFormat, markdown, title, lang string) (string, error) {
+	if format == ReportMarkdown {
+		path := base + ".md"
+		return path, os.WriteFile(path, []byte(markdown), 0644)
+	}
+
+	page, err := RenderHTMLReport(markdown, title, lang)
+	if err != nil {
+		return "", err
+	}
+	htmlPath := base + ".html"
+	if err := os.WriteFile(htmlPath, []byte(page), 0644); err != nil {
+		return "", err
+	}
+	if format == ReportHTML {
+		return htmlPath, nil
+	}
+
+	pdfPath := base + ".pdf"
+	if err := printPDF(htmlPath, pdfPath); err != nil {
+		return "", err
+	}
+	return pdfPath, nil
+}
+
+// printPDF prints an HTML file to PDF with wkhtmltopdf or, failing that,
+// headless Chrome or Chromium
+func printPDF(htmlPath, pdfPath string) error {
+	abs, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdfTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		cmd = exec.CommandContext(ctx, path, "--quiet", "--enable-local-file-access", abs, pdfPath)
+	} else {
+		for _, name := range []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome"} {
+			if path, err := exec.LookPath(name); err == nil {
+				cmd = exec.CommandContext(ctx, path, "--headless", "--disable-gpu", "--no-pdf-header-footer",
+					"--print-to-pdf="+pdfPath, "file://"+filepath.ToSlash(abs))
+				break
+			}
+		}
+	}
+	if cmd == nil {
+		return fmt.Errorf("PDF reports need wkhtmltopdf, Chrome or Chromium installed; the HTML report is at %s", htmlPath)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(cmd.Path), err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(pdfPath); err != nil {
+		return fmt.Errorf("%s wrote no PDF: %w", filepath.Base(cmd.Path), err)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// ReportSection identifies a part of a drafted mission report
+type ReportSection string
+
+const (
+	SectionExecutiveSummary ReportSection = "executive_summary" // Business-level overview for leadership
+	SectionRiskNarrative    ReportSection = "risk_narrative"    // How the findings combine into real risk
+	SectionMethodology      ReportSection = "methodology"       // What was tested and how
+	SectionFindings         ReportSection = "findings"          // Detailed write-up of a chunk of findings
+	SectionTranslation      ReportSection = "translation"       // A chunk of a finished report in another language
+)
+
+// Routine reports whether a section is mechanical enough for a mid-tier
+// model. The executive summary, risk narrative and translations need the
+// heavy tier.
+func (s ReportSection) Routine() bool {
+	return s == SectionMethodology || s == SectionFindings
+}
+
+// ReportDrafter sends one section prompt to a model and returns its Markdown.
+// Callers pick the model tier from the section, see ReportSection.Routine.
+type ReportDrafter func(ctx context.Context, section ReportSection, prompt string) (string, error)
+
+const (
+	defaultReportChunkChars = 12000 // Finding text per findings-section prompt
+	maxReportEvidenceChars  = 3000  // Evidence kept per finding in prompts
+)
+
+// ReportPipeline drafts a mission report section by section instead of in
+// one giant prompt. Findings are chunked so each routine prompt stays small,
+// and only the compact summary prompts reach the expensive tier.
+type ReportPipeline struct {
+	draft      ReportDrafter
+	chunkChars int
+}
+
+// NewReportPipeline creates a pipeline drafting sections with draft
+func NewReportPipeline(draft ReportDrafter) *ReportPipeline {
+	return &ReportPipeline{
+		draft:      draft,
+		chunkChars: defaultReportChunkChars,
+	}
+}
+
+// Run drafts the report for the given audience. Client drafts only ever see
+// findings and evidence the client report may show, so redacted details
+// never reach the prompt.
+func (p *ReportPipeline) Run(ctx context.Context, wf *Workflow, state *MissionState, audience ReportAudience) (string, error) {
+	findings := ReportFindings(state, audience)
+	loc := reportLocale(state)
+
+	draft := p.draft
+	if loc != English {
+		draft = func(ctx context.Context, section ReportSection, prompt string) (string, error) {
+			return p.draft(ctx, section, prompt+languageInstruction(loc))
+		}
+	}
+
+	chunks := chunkFindings(findings, p.chunkChars)
+	detailed := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		text, err := draft(ctx, SectionFindings, findingsPrompt(chunk, audience))
+		if err != nil {
+			return "", fmt.Errorf("drafting findings %d/%d: %w", i+1, len(chunks), err)
+		}
+		detailed = append(detailed, strings.TrimSpace(text))
+	}
+
+	methodology, err := draft(ctx, SectionMethodology, methodologyPrompt(wf, state))
+	if err != nil {
+		return "", fmt.Errorf("drafting methodology: %w", err)
+	}
+
+	overview := findingsOverview(findings)
+	summary, err := draft(ctx, SectionExecutiveSummary, executiveSummaryPrompt(state, overview, audience))
+	if err != nil {
+		return "", fmt.Errorf("drafting executive summary: %w", err)
+	}
+
+	narrative, err := draft(ctx, SectionRiskNarrative, riskNarrativePrompt(state, overview))
+	if err != nil {
+		return "", fmt.Errorf("drafting risk narrative: %w", err)
+	}
+
+	logger.InfoCF("workflow", "Report drafted", map[string]any{
+		"audience":       audience,
+		"findings":       len(findings),
+		"finding_chunks": len(chunks),
+		"language":       loc.Code,
+	})
+
+	var sb strings.Builder
+	writeReportHeader(&sb, wf, state, audience)
+	sb.WriteString("## " + loc.ExecutiveSummary + "\n\n" + strings.TrimSpace(summary) + "\n\n")
+	sb.WriteString("## " + loc.RiskNarrative + "\n\n" + strings.TrimSpace(narrative) + "\n\n")
+	sb.WriteString("## " + loc.Methodology + "\n\n" + strings.TrimSpace(methodology) + "\n\n")
+	sb.WriteString("## " + loc.Findings + "\n\n")
+	if len(detailed) == 0 {
+		sb.WriteString(loc.NoFindings + "\n")
+	}
+	for _, text := range detailed {
+		sb.WriteString(text + "\n\n")
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n", nil
+}
+
+// Translate translates a finished Markdown report into loc's language,
+// splitting it at headings so each prompt stays small
+func (p *ReportPipeline) Translate(ctx context.Context, report string, loc *ReportLocale) (string, error) {
+	chunks := chunkMarkdown(report, p.chunkChars)
+	translated := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		text, err := p.draft(ctx, SectionTranslation, translationPrompt(chunk, loc))
+		if err != nil {
+			return "", fmt.Errorf("translating part %d/%d: %w", i+1, len(chunks), err)
+		}
+		translated = append(translated, strings.TrimSpace(text))
+	}
+
+	logger.InfoCF("workflow", "Report translated", map[string]any{
+		"language": loc.Code,
+		"parts":    len(chunks),
+	})
+	return strings.Join(translated, "\n\n") + "\n", nil
+}
+
+// chunkMarkdown splits Markdown into parts under limit characters, cutting
+// only before headings outside code blocks. A section larger than limit
+// becomes a part of its own.
+func chunkMarkdown(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	inCode := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		heading := !inCode && strings.HasPrefix(line, "#")
+		if heading && current.Len() > 0 && current.Len()+len(line) > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+func translationPrompt(chunk string, loc *ReportLocale) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Translate this part of a penetration test report into %s for the client.\n", loc.Language))
+	sb.WriteString("Keep the Markdown structure, tables and numbering exactly. Do not translate code blocks, commands, hostnames, URLs, file paths, CVE or CWE identifiers, or product names.\n")
+	sb.WriteString("Use the established security terminology of the target language. Do not add, drop or summarize anything.\n")
+	sb.WriteString("Return only the translated Markdown.\n\n")
+	sb.WriteString(chunk)
+	return sb.String()
+}
+
+// languageInstruction asks a drafting prompt for the report language
+func languageInstruction(loc *ReportLocale) string {
+	return fmt.Sprintf("\nWrite the section in %s, using its established security terminology. Keep hostnames, commands, code and evidence unchanged.\n", loc.Language)
+}
+
+// chunkFindings splits findings into groups whose prompt text stays under
+// limit characters. A finding larger than limit gets a chunk of its own.
+func chunkFindings(findings []Finding, limit int) [][]Finding {
+	var chunks [][]Finding
+	var current []Finding
+	size := 0
+	for _, f := range findings {
+		n := len(f.Title) + len(f.Description) + min(len(f.Evidence), maxReportEvidenceChars)
+		if len(current) > 0 && size+n > limit {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, f)
+		size += n
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+func findingsPrompt(findings []Finding, audience ReportAudience) string {
+	var sb strings.Builder
+	sb.WriteString("Write the detailed findings section of a penetration test report for the findings below.\n")
+	sb.WriteString("For each finding write a level-3 Markdown heading with the title, then the severity, a clear description, the impact, the evidence (summarized, quoting the essential lines) and concrete remediation steps.\n")
+	sb.WriteString("Keep the given order. Only use facts from the findings; do not invent hosts, versions or results.\n")
+	if audience == ReportClient {
+		sb.WriteString("This copy goes to the client. Where a finding has no evidence, say that technical evidence is available on request.\n")
+	}
+	sb.WriteString("Return only the Markdown.\n\n")
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("### %s\nSeverity: %s\n", f.Title, f.Severity))
+		if f.CVSSScore > 0 {
+			sb.WriteString(fmt.Sprintf("CVSS: %.1f %s\n", f.CVSSScore, f.CVSSVector))
+		}
+		if f.CWE != "" {
+			sb.WriteString("CWE: " + f.CWE + "\n")
+		}
+		if f.Asset != "" {
+			sb.WriteString("Affected asset: " + f.Asset + "\n")
+		}
+		sb.WriteString(fmt.Sprintf("Phase: %s\n%s\n", f.Phase, f.Description))
+		if f.Evidence != "" {
+			sb.WriteString("Evidence:\n```\n" + truncateEvidence(f.Evidence) + "\n```\n")
+		}
+		if f.Remediation != "" {
+			sb.WriteString("Remediation: " + f.Remediation + "\n")
+		}
+		if len(f.References) > 0 {
+			sb.WriteString("References: " + strings.Join(f.References, ", ") + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func methodologyPrompt(wf *Workflow, state *MissionState) string {
+	var sb strings.Builder
+	sb.WriteString("Write the methodology section of a penetration test report: what was tested and how, as a short Markdown section.\n")
+	sb.WriteString("Only describe the phases and steps listed below. Return only the Markdown.\n\n")
+	sb.WriteString(fmt.Sprintf("Methodology: %s\n", wf.Name))
+	if wf.Description != "" {
+		sb.WriteString(wf.Description + "\n")
+	}
+	for _, exec := range state.PhaseHistory {
+		sb.WriteString(fmt.Sprintf("\nPhase %s (started %s)", exec.PhaseName, exec.StartTime.Format(time.DateOnly)))
+		if len(exec.StepsComplete) > 0 {
+			sb.WriteString(": completed " + strings.Join(exec.StepsComplete, ", "))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// findingsOverview lists findings without evidence, for the summary prompts
+func findingsOverview(findings []Finding) string {
+	if len(findings) == 0 {
+		return "No findings were recorded.\n"
+	}
+	var sb strings.Builder
+	for _, f := range findings {
+		severity := string(f.Severity)
+		if f.CVSSScore > 0 {
+			severity += fmt.Sprintf(", CVSS %.1f", f.CVSSScore)
+		}
+		sb.WriteString(fmt.Sprintf("- [%s] %s: %s\n", severity, f.Title, firstLine(f.Description)))
+	}
+	return sb.String()
+}
+
+func executiveSummaryPrompt(state *MissionState, overview string, audience ReportAudience) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write the executive summary of a penetration test report for %s.\n", reportTitle(state)))
+	sb.WriteString("The readers are non-technical leaders: state the overall security posture, the most important issues and what to prioritize, in a few short paragraphs.\n")
+	if audience == ReportInternal {
+		sb.WriteString("This is the internal copy for the testing team.\n")
+	}
+	sb.WriteString("Only use the findings below. Return only the Markdown.\n\nFindings:\n")
+	sb.WriteString(overview)
+	return sb.String()
+}
+
+func riskNarrativePrompt(state *MissionState, overview string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write the risk narrative of a penetration test report for %s.\n", reportTitle(state)))
+	sb.WriteString("Explain how the findings below could be chained by a realistic attacker, what business impact that would have, and which fixes break the most likely attack paths.\n")
+	sb.WriteString("Only use the findings below. Return only the Markdown.\n\nFindings:\n")
+	sb.WriteString(overview)
+	return sb.String()
+}
+
+func truncateEvidence(evidence string) string {
+	evidence = strings.TrimRight(evidence, "\n")
+	if len(evidence) <= maxReportEvidenceChars {
+		return evidence
+	}
+	return evidence[:maxReportEvidenceChars] + "\n[... evidence truncated ...]"
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,191 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// DefaultKeepRevisions is how many snapshots of its state a mission keeps
+// for rollback
+const DefaultKeepRevisions = 50
+
+// Revision describes a saved snapshot of mission state
+type Revision struct {
+	Revision int
+	SavedAt  time.Time
+	Phase    string // Current phase at the time, "" past the last one
+	Steps    int    // Steps completed in that phase
+	Findings int
+	Branches int // Open branches
+}
+
+// SetKeepRevisions sets how many state snapshots the mission keeps; 0 or
+// less means DefaultKeepRevisions
+func (e *Engine) SetKeepRevisions(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.keepRevisions = n
+}
+
+// Revisions lists the snapshots the mission keeps, newest first. The newest
+// is the state as last saved.
+func (e *Engine) Revisions() ([]Revision, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	numbers, err := revisionNumbers(e.revisionDir())
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]Revision, 0, len(numbers))
+	for i := len(numbers) - 1; i >= 0; i-- {
+		path := e.revisionPath(numbers[i])
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		state, err := decodeMissionState(data)
+		if err != nil {
+			logger.WarnCF(e.component, "Skipping unreadable revision", map[string]any{
+				"path":  path,
+				"error": err.Error(),
+			})
+			continue
+		}
+		revision := Revision{
+			Revision: numbers[i],
+			Findings: len(state.Findings),
+		}
+		if info, err := os.Stat(path); err == nil {
+			revision.SavedAt = info.ModTime()
+		}
+		if state.CurrentPhase < len(e.workflow.Phases) {
+			revision.Phase = e.workflow.Phases[state.CurrentPhase].Name
+		}
+		if n := len(state.PhaseHistory); n > 0 {
+			revision.Steps = len(state.PhaseHistory[n-1].StepsComplete)
+		}
+		for _, branch := range state.ActiveBranches {
+			if branch.CompletedAt == nil {
+				revision.Branches++
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// Rollback restores the mission to a kept revision, to undo a bad phase
+// advance or a branch completed by mistake. The restored state is saved as
+// a new revision, so the rollback can itself be undone, and the journal
+// records it.
+func (e *Engine) Rollback(revision int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if revision >= e.state.Revision {
+		return fmt.Errorf("revision %d is not before the current revision %d", revision, e.state.Revision)
+	}
+	data, err := os.ReadFile(e.revisionPath(revision))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("revision %d is no longer kept", revision)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read revision %d: %w", revision, err)
+	}
+	state, err := decodeMissionState(data)
+	if err != nil {
+		return fmt.Errorf("revision %d: %w", revision, err)
+	}
+	if state.WorkflowName != e.state.WorkflowName {
+		return fmt.Errorf("revision %d belongs to workflow %s, not %s", revision, state.WorkflowName, e.state.WorkflowName)
+	}
+
+	current := e.state.Revision
+	state.Revision = current
+	e.state = state
+	e.pendingJump = ""
+	e.journal(JournalNote, fmt.Sprintf("Rolled back from revision %d to revision %d", current, revision), false)
+
+	logger.InfoCF(e.component, "Mission rolled back", map[string]any{
+		"from": current,
+		"to":   revision,
+	})
+	e.publish(Event{Type: EventRolledBack, Phase: e.currentPhaseName(), Revision: revision})
+	return e.saveState()
+}
+
+// saveRevision keeps data, the state just saved, as a snapshot and drops
+// the oldest beyond the limit. Snapshots are a safety net, so failing to
+// write one doesn't fail the save, and they aren't synced: recovery skips
+// any a crash damaged.
+func (e *Engine) saveRevision(data []byte) {
+	dir := e.revisionDir()
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		err = os.WriteFile(e.revisionPath(e.state.Revision), data, 0644)
+	}
+	if err != nil {
+		logger.WarnCF(e.component, "Failed to save state revision", map[string]any{
+			"revision": e.state.Revision,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	keep := e.keepRevisions
+	if keep <= 0 {
+		keep = DefaultKeepRevisions
+	}
+	numbers, err := revisionNumbers(dir)
+	if err != nil || len(numbers) <= keep {
+		return
+	}
+	for _, n := range numbers[:len(numbers)-keep] {
+		os.Remove(e.revisionPath(n))
+	}
+}
+
+// revisionNumbers returns the revisions kept in dir, oldest first
+func revisionNumbers(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var numbers []int
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(name); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// revisionDir keeps the snapshots in the mission directory, so they are
+// archived with the mission
+func (e *Engine) revisionDir() string {
+	return filepath.Join(e.workspace, "missions", e.missionFileName(), "revisions")
+}
+
+func (e *Engine) revisionPath(revision int) string {
+	return filepath.Join(e.revisionDir(), revisionFileName(revision))
+}
+
+func revisionFileName(revision int) string {
+	return fmt.Sprintf("%06d.json", revision)
+}
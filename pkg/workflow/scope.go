@@ -0,0 +1,519 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/determinism"
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// Scope is what the mission is authorized to touch. Entries are hostnames,
+// *.domain wildcards (subdomains only), IP addresses or CIDR ranges. The
+// mission target is always in scope unless excluded, and exclusions win over
+// inclusions.
+type Scope struct {
+	Include []string      `json:"include,omitempty"`
+	Exclude []string      `json:"exclude,omitempty"`
+	History []ScopeChange `json:"history,omitempty"` // Audit trail of every change and refused request
+}
+
+// ScopeAction is the kind of change made to the scope
+type ScopeAction string
+
+const (
+	ScopeAdd       ScopeAction = "add"
+	ScopeRemove    ScopeAction = "remove"
+	ScopeAddTarget ScopeAction = "add_target" // A further mission target
+)
+
+// ErrOutOfScope is wrapped by the errors of scope checks that refuse a host
+var ErrOutOfScope = errors.New("out of scope")
+
+// Who changed the scope
+const (
+	ScopeByOperator = "operator"
+	ScopeByAgent    = "agent" // Requested by the agent; applied only with operator approval
+)
+
+// ScopeChange is one entry in the scope audit trail
+type ScopeChange struct {
+	Action  ScopeAction `json:"action"`
+	Entry   string      `json:"entry"`
+	Exclude bool        `json:"exclude,omitempty"` // The entry is (or was) an exclusion
+	By      string      `json:"by"`
+	Reason  string      `json:"reason,omitempty"`
+	Denied  bool        `json:"denied,omitempty"` // Requested but not approved, so not applied
+	At      time.Time   `json:"at"`
+}
+
+// String renders the change as a line of the audit trail
+func (c ScopeChange) String() string {
+	list := "scope"
+	if c.Exclude {
+		list = "exclusions"
+	}
+	verb := "added to"
+	switch c.Action {
+	case ScopeRemove:
+		verb = "removed from"
+	case ScopeAddTarget:
+		list = "targets"
+	}
+	s := fmt.Sprintf("%s %s %s %s by %s", c.At.Format("2006-01-02 15:04"), c.Entry, verb, list, c.By)
+	if c.Denied {
+		s = fmt.Sprintf("%s %s request by %s denied: %s %s", c.At.Format("2006-01-02 15:04"), c.Action, c.By, c.Entry, list)
+	}
+	if c.Reason != "" {
+		s += " (" + c.Reason + ")"
+	}
+	return s
+}
+
+// NormalizeScopeEntry returns the canonical form of a scope entry. URLs and
+// host:port are reduced to their host and CIDR ranges to their network.
+func NormalizeScopeEntry(entry string) (string, error) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "" {
+		return "", fmt.Errorf("scope entry is empty")
+	}
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return network.String(), nil
+	}
+	host := scopeHost(entry)
+	if host == "" || strings.ContainsAny(host, " \t/\\@") {
+		return "", fmt.Errorf("invalid scope entry %q (want a hostname, *.domain, IP address or CIDR range)", entry)
+	}
+	if strings.Contains(host, "*") && (!strings.HasPrefix(host, "*.") || strings.Count(host, "*") > 1) {
+		return "", fmt.Errorf("invalid wildcard %q (only a leading *. is supported)", entry)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil // One spelling per address, e.g. for IPv6
+	}
+	return host, nil
+}
+
+// scopeHost extracts the host from a URL, host:port or host/path
+func scopeHost(s string) string {
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil {
+			return strings.TrimSuffix(u.Hostname(), ".")
+		}
+	}
+	s, _, _ = strings.Cut(s, "/")
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return strings.TrimSuffix(strings.Trim(s, "[]"), ".")
+}
+
+// scopeMatches reports whether the scope entry covers host, which may itself
+// be a CIDR range
+func scopeMatches(entry, host string) bool {
+	if entry == host {
+		return true
+	}
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			return network.Contains(ip)
+		}
+		if _, inner, err := net.ParseCIDR(host); err == nil {
+			entryOnes, _ := network.Mask.Size()
+			innerOnes, _ := inner.Mask.Size()
+			return innerOnes >= entryOnes && network.Contains(inner.IP)
+		}
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return false
+}
+
+// scopeOverlaps reports whether an exclusion touches host: it covers host,
+// or host is a range containing the excluded address or range
+func scopeOverlaps(exclusion, host string) bool {
+	if scopeMatches(exclusion, host) {
+		return true
+	}
+	if _, network, err := net.ParseCIDR(host); err == nil {
+		if ip := net.ParseIP(exclusion); ip != nil {
+			return network.Contains(ip)
+		}
+		if _, inner, err := net.ParseCIDR(exclusion); err == nil {
+			return network.Contains(inner.IP)
+		}
+	}
+	return false
+}
+
+// ChangeScope applies change to the mission scope and records it in the
+// audit trail. Denied changes are recorded without being applied.
+func (e *Engine) ChangeScope(change ScopeChange) error {
+	entry, err := NormalizeScopeEntry(change.Entry)
+	if err != nil {
+		return err
+	}
+	change.Entry = entry
+	change.Reason = strings.TrimSpace(change.Reason)
+	if change.By == "" {
+		change.By = ScopeByOperator
+	}
+	change.At = determinism.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !change.Denied {
+		list := &e.state.Scope.Include
+		listName := "in scope"
+		if change.Exclude {
+			list = &e.state.Scope.Exclude
+			listName = "excluded"
+		}
+		switch change.Action {
+		case ScopeAdd:
+			if slices.Contains(*list, entry) {
+				return fmt.Errorf("%s is already %s", entry, listName)
+			}
+			*list = append(*list, entry)
+		case ScopeRemove:
+			i := slices.Index(*list, entry)
+			if i < 0 {
+				return fmt.Errorf("%s is not %s", entry, listName)
+			}
+			*list = slices.Delete(*list, i, i+1)
+		default:
+			return fmt.Errorf("unknown scope action %q", change.Action)
+		}
+	}
+	e.state.Scope.History = append(e.state.Scope.History, change)
+
+	logger.InfoCF(e.component, "Scope changed", map[string]any{
+		"action":  change.Action,
+		"entry":   entry,
+		"exclude": change.Exclude,
+		"by":      change.By,
+		"denied":  change.Denied,
+	})
+	e.publish(Event{Type: EventScopeChanged, Phase: e.currentPhaseName(), Key: entry})
+
+	return e.saveState()
+}
+
+// CheckScope reports whether host (a hostname, URL, IP address or CIDR
+// range) is in the mission scope, and why.
+func (e *Engine) CheckScope(host string) (bool, string) {
+	candidate, err := NormalizeScopeEntry(host)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, exclusion := range e.state.Scope.Exclude {
+		if scopeOverlaps(exclusion, candidate) {
+			return false, fmt.Sprintf("excluded by %s", exclusion)
+		}
+	}
+	for _, t := range e.targets() {
+		if target, err := NormalizeScopeEntry(t); err == nil && scopeMatches(target, candidate) {
+			return true, fmt.Sprintf("matches the mission target %s", target)
+		}
+	}
+	for _, inclusion := range e.state.Scope.Include {
+		if scopeMatches(inclusion, candidate) {
+			return true, fmt.Sprintf("included by %s", inclusion)
+		}
+	}
+	return false, "matches no scope entry"
+}
+
+// targets returns the primary target followed by the further targets. The
+// caller must hold mu.
+func (e *Engine) targets() []string {
+	var targets []string
+	if e.state.Target != "" {
+		targets = append(targets, e.state.Target)
+	}
+	return append(targets, e.state.Targets...)
+}
+
+// Targets returns every mission target, the primary target first
+func (e *Engine) Targets() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.targets()
+}
+
+// AddTarget adds a further target to the mission. Targets are in scope like
+// the primary target, so only the operator can add one outside the current
+// scope; the agent can only pick out targets within it, such as a live host
+// in an in-scope range. Excluded targets are refused either way.
+func (e *Engine) AddTarget(target, by, reason string) error {
+	entry, err := NormalizeScopeEntry(target)
+	if err != nil {
+		return err
+	}
+	if by == "" {
+		by = ScopeByOperator
+	}
+
+	inScope, why := e.CheckScope(entry)
+	if !inScope && (by != ScopeByOperator || strings.HasPrefix(why, "excluded")) {
+		return fmt.Errorf("%s is %w (%s)", entry, ErrOutOfScope, why)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, t := range e.targets() {
+		if existing, err := NormalizeScopeEntry(t); err == nil && existing == entry {
+			return fmt.Errorf("%s is already a mission target", entry)
+		}
+	}
+	e.state.Targets = append(e.state.Targets, entry)
+	e.state.Scope.History = append(e.state.Scope.History, ScopeChange{
+		Action: ScopeAddTarget,
+		Entry:  entry,
+		By:     by,
+		Reason: strings.TrimSpace(reason),
+		At:     determinism.Now(),
+	})
+
+	logger.InfoCF(e.component, "Target added", map[string]any{
+		"target": entry,
+		"by":     by,
+	})
+	e.publish(Event{Type: EventScopeChanged, Phase: e.currentPhaseName(), Key: entry})
+
+	return e.saveState()
+}
+
+// scopeDefined reports whether there is a scope to enforce: a target or an
+// in-scope entry. Missions without one, such as code reviews, aren't
+// limited. The caller must hold mu.
+func (e *Engine) scopeDefined() bool {
+	if len(e.state.Scope.Include) > 0 || len(e.state.Targets) > 0 {
+		return true
+	}
+	_, err := NormalizeScopeEntry(e.state.Target)
+	return err == nil
+}
+
+var (
+	scopeURLPattern  = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>|;]+`)
+	scopeIPPattern   = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?:/\d{1,2})?\b`)
+	scopeIPv6Pattern = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}(?:\d{1,3}(?:\.\d{1,3}){3})?(?:%[0-9a-z]+)?(?:/\d{1,3})?`)
+	scopeNamePattern = regexp.MustCompile(`(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)+\b`)
+
+	// scopeWordSeparators split a command line into the words that may each
+	// name a host
+	scopeWordSeparators = " \t\r\n\"'`=,;|&()[]{}<>"
+)
+
+// fileExtensions are name endings that mark a file rather than a host. The
+// ones that are also top-level domains, such as .sh, .py and .zip, are
+// taken as files too: a host with one of them must be named in a URL.
+var fileExtensions = map[string]bool{
+	"txt": true, "log": true, "out": true, "xml": true, "json": true, "jsonl": true, "csv": true,
+	"yaml": true, "yml": true, "toml": true, "conf": true, "cfg": true, "ini": true, "html": true,
+	"htm": true, "gnmap": true, "nmap": true, "pcap": true, "har": true, "bak": true, "tmp": true,
+	"sh": true, "py": true, "pl": true, "pm": true, "rb": true, "go": true, "rs": true, "md": true,
+	"cc": true, "ml": true, "in": true, "am": true, "ac": true, "mk": true, "ps": true, "so": true,
+	"zip": true, "mov": true, "gz": true, "tar": true, "tgz": true, "pem": true, "key": true,
+	"crt": true, "der": true, "pdf": true, "png": true, "jpg": true, "db": true, "sqlite": true,
+}
+
+// SetHostResolver replaces how CheckHosts tells whether a bare name without
+// a public suffix, such as dc01.corp.local, is a host: resolve reports
+// whether it resolves. By default it is looked up in DNS with a short
+// timeout.
+func (e *Engine) SetHostResolver(resolve func(name string) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resolveHost = resolve
+}
+
+// resolves looks name up in DNS
+func resolves(name string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, name)
+	return err == nil && len(addrs) > 0
+}
+
+// CheckHosts vets the hosts that text, such as a shell command or a URL,
+// would reach. URL hosts and IPv4 and IPv6 addresses and ranges must be in
+// scope. When network is set, text is the input of a network command or
+// tool, and every word that names a host must be in scope too: a name with
+// a public suffix, such as example.org, or one that resolves. Otherwise
+// bare names can't be told apart from file names and are only refused when
+// excluded. Loopback addresses are always allowed, and nothing is refused
+// while the mission has no scope. The error wraps ErrOutOfScope.
+func (e *Engine) CheckHosts(text string, network bool) error {
+	e.mu.Lock()
+	defined := e.scopeDefined()
+	exclusions := slices.Clone(e.state.Scope.Exclude)
+	resolve := e.resolveHost
+	e.mu.Unlock()
+	if !defined {
+		return nil
+	}
+	if resolve == nil {
+		resolve = resolves
+	}
+
+	var strict []string
+	for _, u := range scopeURLPattern.FindAllString(text, -1) {
+		strict = append(strict, scopeHost(strings.ToLower(u)))
+	}
+	bare := scopeURLPattern.ReplaceAllString(text, " ")
+	strict = append(strict, scopeIPPattern.FindAllString(bare, -1)...)
+	for _, candidate := range scopeIPv6Pattern.FindAllString(bare, -1) {
+		if ip := scopeIPv6(candidate); ip != "" {
+			strict = append(strict, ip)
+		}
+	}
+	if network {
+		for _, word := range strings.FieldsFunc(bare, func(r rune) bool {
+			return strings.ContainsRune(scopeWordSeparators, r)
+		}) {
+			if name := scopeHostWord(word); name != "" && namesHost(name, resolve) {
+				strict = append(strict, name)
+			}
+		}
+	}
+
+	for _, host := range strict {
+		if host == "" || isLoopbackHost(host) {
+			continue
+		}
+		if _, err := NormalizeScopeEntry(host); err != nil {
+			continue // Not an address after all, e.g. 999.1.1.1
+		}
+		if inScope, why := e.CheckScope(host); !inScope {
+			return fmt.Errorf("%s is %w (%s)", host, ErrOutOfScope, why)
+		}
+	}
+
+	for _, name := range scopeNamePattern.FindAllString(text, -1) {
+		name = strings.ToLower(name)
+		for _, exclusion := range exclusions {
+			if scopeOverlaps(exclusion, name) {
+				return fmt.Errorf("%s is %w (excluded by %s)", name, ErrOutOfScope, exclusion)
+			}
+		}
+	}
+	return nil
+}
+
+// scopeIPv6 returns the IPv6 address or range candidate is, without
+// brackets or zone, or "" if it is none, e.g. a time such as 12:30:45
+func scopeIPv6(candidate string) string {
+	addr, prefix, hasPrefix := strings.Cut(candidate, "/")
+	addr, _, _ = strings.Cut(addr, "%")
+	ip := net.ParseIP(addr)
+	if ip == nil || ip.To4() != nil && !strings.Contains(addr, "::") {
+		return ""
+	}
+	if hasPrefix {
+		return ip.String() + "/" + prefix
+	}
+	return ip.String()
+}
+
+// scopeHostWord returns the name a command-line word would reach, past a
+// user@ and before a :port or /path, or "" if the word is a path, option
+// or anything else that isn't a hostname
+func scopeHostWord(word string) string {
+	if strings.HasPrefix(word, "-") || strings.HasPrefix(word, "/") || strings.HasPrefix(word, ".") || strings.HasPrefix(word, "~") {
+		return ""
+	}
+	if _, host, ok := strings.Cut(word, "@"); ok {
+		word = host
+	}
+	word, _, _ = strings.Cut(word, "/")
+	if host, _, err := net.SplitHostPort(word); err == nil {
+		word = host
+	}
+	word = strings.ToLower(strings.TrimSuffix(word, "."))
+	if scopeNamePattern.FindString(word) != word {
+		return ""
+	}
+	return word
+}
+
+// namesHost reports whether name, a dotted word, is a hostname rather than
+// a file name, version number or attribute: it ends in a public suffix, or
+// it resolves
+func namesHost(name string, resolve func(string) bool) bool {
+	last := name[strings.LastIndex(name, ".")+1:]
+	if fileExtensions[last] || strings.Trim(last, "0123456789") == "" {
+		return false
+	}
+	if suffix, icann := publicsuffix.PublicSuffix(name); icann && suffix != name {
+		return true
+	}
+	return resolve(name)
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsUnspecified()
+	}
+	return false
+}
+
+// scopePrompt renders the scope for the mission context prompt
+func (e *Engine) scopePrompt() string {
+	scope := e.state.Scope
+	if len(scope.Include) == 0 && len(scope.Exclude) == 0 && len(e.state.Targets) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Scope\n")
+	if len(e.state.Targets) > 0 {
+		sb.WriteString(fmt.Sprintf("- Targets: %s\n", strings.Join(e.targets(), ", ")))
+	}
+	included := append(e.targets(), scope.Include...)
+	if len(included) > 0 {
+		sb.WriteString(fmt.Sprintf("- In scope: %s\n", strings.Join(included, ", ")))
+	}
+	if len(scope.Exclude) > 0 {
+		sb.WriteString(fmt.Sprintf("- Out of scope: %s\n", strings.Join(scope.Exclude, ", ")))
+	}
+	sb.WriteString("Network commands, fetches and other network tools naming a host outside the scope are refused. The check only sees hosts named in the call, not ones a script or input file supplies, so keep those in scope yourself. Check unfamiliar hosts with scope_query; use scope_change_request to ask the operator to change the scope, and workflow_add_target to track an in-scope host as a further target.\n\n")
+	return sb.String()
+}
+
+// Summary renders the scope on one line, e.g. for a preamble. targets are
+// the mission targets, which are always in scope.
+func (s Scope) Summary(targets ...string) string {
+	var included []string
+	for _, target := range targets {
+		if target != "" {
+			included = append(included, target)
+		}
+	}
+	included = append(included, s.Include...)
+	summary := strings.Join(included, ", ")
+	if len(s.Exclude) > 0 {
+		summary += " excluding " + strings.Join(s.Exclude, ", ")
+	}
+	return strings.TrimSpace(summary)
+}
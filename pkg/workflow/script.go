@@ -0,0 +1,383 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// Hook events a phase can attach scripts to
+const (
+	HookPhaseStart   = "on_phase_start"   // The phase became current
+	HookStepComplete = "on_step_complete" // A step in the phase was marked complete
+	HookFinding      = "on_finding"       // A finding was recorded during the phase
+	HookBranch       = "on_branch"        // An investigation branch was created
+)
+
+// scriptMaxSteps bounds every script so a bad loop can't stall the agent
+const scriptMaxSteps = 100000
+
+// scriptOptions lets one-line hooks use if/for at top level
+var scriptOptions = &syntax.FileOptions{Set: true, TopLevelControl: true, GlobalReassign: true}
+
+var hookEvents = []string{HookPhaseStart, HookStepComplete, HookFinding, HookBranch}
+
+// EvalCondition evaluates a Starlark expression against the mission state
+// and reports whether it is truthy. Completion scripts and branch "when"
+// conditions go through here.
+func (e *Engine) EvalCondition(expr string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.evalCondition(expr)
+}
+
+func (e *Engine) evalCondition(expr string) (bool, error) {
+	thread := e.scriptThread()
+	value, err := starlark.EvalOptions(scriptOptions, thread, "condition", expr, e.scriptGlobals())
+	if err != nil {
+		return false, fmt.Errorf("evaluating %q: %w", expr, err)
+	}
+	return bool(value.Truth()), nil
+}
+
+// runHooks runs the current phase's scripts for event. Hook scripts may
+// call note(), create_branch(), set_metadata() and set_variable(); they do
+// not trigger further hooks. Failures are logged and never block the mission.
+func (e *Engine) runHooks(event string, fields starlark.StringDict) {
+	if e.inHook || e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return
+	}
+	phase := e.workflow.Phases[e.state.CurrentPhase]
+
+	e.inHook = true
+	defer func() { e.inHook = false }()
+
+	for _, hook := range phase.Hooks {
+		if hook.Event != event {
+			continue
+		}
+		globals := e.scriptGlobals()
+		for name, fn := range e.hookBuiltins() {
+			globals[name] = fn
+		}
+		eventFields := starlark.StringDict{"name": starlark.String(event)}
+		for k, v := range fields {
+			eventFields[k] = v
+		}
+		globals["event"] = starlarkstruct.FromStringDict(starlarkstruct.Default, eventFields)
+
+		if _, err := starlark.ExecFileOptions(scriptOptions, e.scriptThread(), event, hook.Script, globals); err != nil {
+			logger.WarnCF(e.component, "Workflow hook failed", map[string]any{
+				"phase": phase.Name,
+				"hook":  event,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// activateScriptedBranches creates every branch of the current phase whose
+// "when" condition holds and that is not active yet.
+func (e *Engine) activateScriptedBranches() {
+	if e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return
+	}
+	phase := e.workflow.Phases[e.state.CurrentPhase]
+	for _, branch := range phase.Branches {
+		if branch.When == "" || e.hasBranch(branch.Condition) {
+			continue
+		}
+		ok, err := e.evalCondition(branch.When)
+		if err != nil {
+			logger.WarnCF(e.component, "Branch condition failed", map[string]any{
+				"condition": branch.Condition,
+				"error":     err.Error(),
+			})
+			continue
+		}
+		if ok {
+			e.activateBranch(branch.Condition, branch.Description)
+			e.runHooks(HookBranch, starlark.StringDict{"condition": starlark.String(branch.Condition)})
+		}
+	}
+}
+
+func (e *Engine) scriptThread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "workflow",
+		Print: func(_ *starlark.Thread, msg string) {
+			logger.InfoCF(e.component, "Workflow script", map[string]any{"message": msg})
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	return thread
+}
+
+// scriptGlobals exposes a read-only snapshot of the mission to scripts:
+//
+//	target, workflow, phase, phase_index
+//	steps     list of struct(id, name, required, completed, blocked) for the current phase
+//	findings  list of struct(id, title, description, severity, phase, evidence)
+//	branches  list of struct(condition, description, completed)
+//	metadata, vars, aliases  dicts
+func (e *Engine) scriptGlobals() starlark.StringDict {
+	phaseName := ""
+	steps := make([]starlark.Value, 0)
+	if e.state.CurrentPhase < len(e.workflow.Phases) {
+		phase := e.workflow.Phases[e.state.CurrentPhase]
+		phaseName = phase.Name
+		exec := e.getCurrentPhaseExecution()
+		for _, step := range phase.Steps {
+			steps = append(steps, starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+				"id":        starlark.String(step.ID),
+				"name":      starlark.String(step.Name),
+				"required":  starlark.Bool(step.Required),
+				"completed": starlark.Bool(exec != nil && e.isStepComplete(step.ID, exec)),
+				"blocked":   starlark.Bool(len(step.PendingDependencies(exec)) > 0),
+			}))
+		}
+	}
+
+	findings := make([]starlark.Value, 0, len(e.state.Findings))
+	for _, f := range e.state.Findings {
+		findings = append(findings, starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"id":          starlark.String(f.ID),
+			"title":       starlark.String(f.Title),
+			"description": starlark.String(f.Description),
+			"severity":    starlark.String(string(f.Severity)),
+			"phase":       starlark.String(f.Phase),
+			"evidence":    starlark.String(f.Evidence),
+		}))
+	}
+
+	branches := make([]starlark.Value, 0, len(e.state.ActiveBranches))
+	for _, b := range e.state.ActiveBranches {
+		branches = append(branches, starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"condition":   starlark.String(b.Condition),
+			"description": starlark.String(b.Description),
+			"completed":   starlark.Bool(b.CompletedAt != nil),
+		}))
+	}
+
+	aliases := starlark.NewDict(len(e.state.Aliases))
+	for alias, target := range e.state.Aliases {
+		aliases.SetKey(starlark.String(alias), starlark.String(target))
+	}
+
+	metadata := starlark.NewDict(len(e.state.Metadata))
+	for key, value := range e.state.Metadata {
+		metadata.SetKey(starlark.String(key), toStarlark(value))
+	}
+
+	vars := starlark.NewDict(len(e.state.Variables))
+	for name, value := range e.state.Variables {
+		vars.SetKey(starlark.String(name), toStarlark(value))
+	}
+
+	return starlark.StringDict{
+		"target":      starlark.String(e.state.Target),
+		"workflow":    starlark.String(e.workflow.Name),
+		"phase":       starlark.String(phaseName),
+		"phase_index": starlark.MakeInt(e.state.CurrentPhase),
+		"steps":       starlark.NewList(steps),
+		"findings":    starlark.NewList(findings),
+		"branches":    starlark.NewList(branches),
+		"aliases":     aliases,
+		"metadata":    metadata,
+		"vars":        vars,
+	}
+}
+
+// hookBuiltins are the functions hook scripts use to act on the mission
+func (e *Engine) hookBuiltins() starlark.StringDict {
+	return starlark.StringDict{
+		"note": starlark.NewBuiltin("note", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var text string
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "text", &text); err != nil {
+				return nil, err
+			}
+			e.addNote(text)
+			return starlark.None, nil
+		}),
+		"create_branch": starlark.NewBuiltin("create_branch", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var condition, description string
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "condition", &condition, "description?", &description); err != nil {
+				return nil, err
+			}
+			if description == "" {
+				description = condition
+			}
+			if !e.hasBranch(condition) {
+				e.activateBranch(condition, description)
+			}
+			return starlark.None, nil
+		}),
+		"set_metadata": starlark.NewBuiltin("set_metadata", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var key string
+			var value starlark.Value
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value); err != nil {
+				return nil, err
+			}
+			converted, err := fromStarlark(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			e.setMetadata(key, converted)
+			return starlark.None, nil
+		}),
+		"set_variable": starlark.NewBuiltin("set_variable", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var name string
+			var value starlark.Value
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "value", &value); err != nil {
+				return nil, err
+			}
+			if err := checkVariableName(name); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			converted, err := fromStarlark(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			e.setVariable(name, converted)
+			return starlark.None, nil
+		}),
+	}
+}
+
+// toStarlark converts JSON-shaped metadata into Starlark values
+func toStarlark(v interface{}) starlark.Value {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None
+	case bool:
+		return starlark.Bool(v)
+	case string:
+		return starlark.String(v)
+	case int:
+		return starlark.MakeInt(v)
+	case int64:
+		return starlark.MakeInt64(v)
+	case float64:
+		if v == float64(int64(v)) {
+			return starlark.MakeInt64(int64(v))
+		}
+		return starlark.Float(v)
+	case []interface{}:
+		items := make([]starlark.Value, 0, len(v))
+		for _, item := range v {
+			items = append(items, toStarlark(item))
+		}
+		return starlark.NewList(items)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		dict := starlark.NewDict(len(v))
+		for _, k := range keys {
+			dict.SetKey(starlark.String(k), toStarlark(v[k]))
+		}
+		return dict
+	default:
+		return starlark.String(fmt.Sprint(v))
+	}
+}
+
+// fromStarlark converts a script value into something that survives the
+// JSON state file
+func fromStarlark(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		return v.String(), nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.List:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := fromStarlark(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case *starlark.Dict:
+		m := make(map[string]interface{}, v.Len())
+		for _, kv := range v.Items() {
+			key, ok := starlark.AsString(kv[0])
+			if !ok {
+				return nil, fmt.Errorf("metadata keys must be strings, got %s", kv[0].Type())
+			}
+			item, err := fromStarlark(kv[1])
+			if err != nil {
+				return nil, err
+			}
+			m[key] = item
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported metadata value of type %s", v.Type())
+	}
+}
+
+// scriptIssues checks that every script in phase parses, so a typo is
+// reported when the workflow loads rather than mid-mission
+func scriptIssues(phase Phase) []ValidationIssue {
+	var issues []ValidationIssue
+	report := func(check, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Level: LevelError, Check: check, Phase: phase.Name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if phase.When != "" {
+		if _, err := scriptOptions.ParseExpr("condition", phase.When, 0); err != nil {
+			report("invalid_script", "invalid phase condition: %v", err)
+		}
+	}
+	if phase.Completion.Script != "" {
+		if _, err := scriptOptions.ParseExpr("completion", phase.Completion.Script, 0); err != nil {
+			report("invalid_script", "invalid completion script: %v", err)
+		}
+	}
+	for _, branch := range phase.Branches {
+		if branch.When == "" {
+			continue
+		}
+		if _, err := scriptOptions.ParseExpr("condition", branch.When, 0); err != nil {
+			report("invalid_script", "invalid condition for branch %s: %v", branch.Condition, err)
+		}
+	}
+	for _, hook := range phase.Hooks {
+		if !isHookEvent(hook.Event) {
+			report("unknown_hook", "unknown hook %q (expected one of %s)", hook.Event, strings.Join(hookEvents, ", "))
+			continue
+		}
+		if _, err := scriptOptions.Parse(hook.Event, hook.Script, 0); err != nil {
+			report("invalid_script", "invalid %s hook: %v", hook.Event, err)
+		}
+	}
+	return issues
+}
+
+func isHookEvent(event string) bool {
+	for _, e := range hookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
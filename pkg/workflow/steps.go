@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PendingDependencies returns the IDs of the steps s depends on that exec
+// hasn't completed yet. A step with none left is unblocked.
+func (s Step) PendingDependencies(exec *PhaseExecution) []string {
+	var pending []string
+	for _, dep := range s.DependsOn {
+		if exec == nil || !exec.isComplete(dep) {
+			pending = append(pending, dep)
+		}
+	}
+	return pending
+}
+
+func (p *PhaseExecution) isComplete(stepID string) bool {
+	for _, id := range p.StepsComplete {
+		if id == stepID {
+			return true
+		}
+	}
+	return false
+}
+
+// unblockedSteps returns the phase's incomplete steps whose dependencies
+// are all complete, required steps first, and how many are still blocked
+func (e *Engine) unblockedSteps(phase Phase, exec *PhaseExecution) ([]*Step, int) {
+	var required, optional []*Step
+	blocked := 0
+	for i := range phase.Steps {
+		step := &phase.Steps[i]
+		switch {
+		case e.isStepComplete(step.ID, exec):
+		case len(step.PendingDependencies(exec)) > 0:
+			blocked++
+		case step.Required:
+			required = append(required, step)
+		default:
+			optional = append(optional, step)
+		}
+	}
+	return append(required, optional...), blocked
+}
+
+// checkStepUnblocked rejects completing a step of the current phase before
+// the steps it depends on
+func (e *Engine) checkStepUnblocked(stepID string, exec *PhaseExecution) error {
+	if e.state.CurrentPhase >= len(e.workflow.Phases) {
+		return nil
+	}
+	for _, step := range e.workflow.Phases[e.state.CurrentPhase].Steps {
+		if step.ID != stepID {
+			continue
+		}
+		if pending := step.PendingDependencies(exec); len(pending) > 0 {
+			return fmt.Errorf("step %s is blocked: complete %s first", stepID, strings.Join(pending, ", "))
+		}
+		return nil
+	}
+	return nil
+}
+
+// stepDependencyIssues reports dependencies on steps the phase doesn't
+// have and dependency cycles, which would leave steps blocked forever
+func stepDependencyIssues(phase Phase) []ValidationIssue {
+	var issues []ValidationIssue
+	byID := make(map[string]Step, len(phase.Steps))
+	for _, step := range phase.Steps {
+		byID[step.ID] = step
+	}
+	for _, step := range phase.Steps {
+		for _, dep := range step.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				issues = append(issues, ValidationIssue{Level: LevelError, Check: "unknown_step_dependency", Phase: phase.Name,
+					Message: fmt.Sprintf("step %s depends on %q, which is not a step of this phase", step.ID, dep)})
+			}
+		}
+	}
+
+	// Depth-first search; a step met again while still on the path closes a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int, len(phase.Steps))
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case onPath:
+			for i, p := range path {
+				if p == id {
+					return append(append([]string{}, path[i:]...), id)
+				}
+			}
+		case done:
+			return nil
+		}
+		state[id] = onPath
+		path = append(path, id)
+		for _, dep := range byID[id].DependsOn {
+			if _, ok := byID[dep]; !ok {
+				continue
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for _, step := range phase.Steps {
+		if state[step.ID] != unvisited {
+			continue
+		}
+		path = path[:0]
+		if cycle := visit(step.ID); cycle != nil {
+			issues = append(issues, ValidationIssue{Level: LevelError, Check: "step_dependency_cycle", Phase: phase.Name,
+				Message: fmt.Sprintf("steps depend on each other in a cycle: %s", strings.Join(cycle, " -> "))})
+			break
+		}
+	}
+	return issues
+}
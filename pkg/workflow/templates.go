@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed templates/*.md
+var templateFiles embed.FS
+
+// TemplateInfo describes a built-in workflow template
+type TemplateInfo struct {
+	Name        string
+	Description string
+	Phases      int
+}
+
+// Templates lists the built-in workflow templates by name
+func Templates() ([]TemplateInfo, error) {
+	paths, err := fs.Glob(templateFiles, "templates/*.md")
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]TemplateInfo, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		content, err := Template(name)
+		if err != nil {
+			return nil, err
+		}
+		wf, err := NewParser().Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		infos = append(infos, TemplateInfo{Name: name, Description: wf.Description, Phases: len(wf.Phases)})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Template returns the Markdown source of the built-in template name
+func Template(name string) ([]byte, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("unknown workflow template %q", name)
+	}
+	content, err := templateFiles.ReadFile("templates/" + name + ".md")
+	if err != nil {
+		return nil, fmt.Errorf("unknown workflow template %q", name)
+	}
+	return content, nil
+}
+
+// frontmatterName matches the name line of a workflow's frontmatter
+var frontmatterName = regexp.MustCompile(`(?m)^name:.*$`)
+
+// InitTemplate copies the built-in template into the workspace's workflows
+// directory as name, or under the template's own name if name is empty,
+// and returns the path written. An existing file is only replaced if force
+// is set.
+func InitTemplate(workspace, template, name string, force bool) (string, error) {
+	content, err := Template(template)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = template
+	} else if loc := frontmatterName.FindIndex(content); loc != nil {
+		content = append(append(append([]byte{}, content[:loc[0]]...), "name: "+name...), content[loc[1]:]...)
+	}
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid workflow name %q", name)
+	}
+
+	dir := filepath.Join(workspace, "workflows")
+	dest := filepath.Join(dir, name+".md")
+	if _, err := os.Stat(dest); err == nil && !force {
+		return "", fmt.Errorf("%s already exists; pass --force to overwrite it", dest)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create workflows directory: %w", err)
+	}
+	if err := os.WriteFile(dest, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write workflow: %w", err)
+	}
+	return dest, nil
+}
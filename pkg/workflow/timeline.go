@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimelineEntry is one dated event in a mission report's timeline
+type TimelineEntry struct {
+	Time    time.Time
+	Event   string // Localized event name, e.g. "Phase started"
+	Details string
+}
+
+// Timeline lists when phases started and ended, branches were opened and
+// closed, and the given findings were recorded, oldest first
+func Timeline(state *MissionState, findings []Finding, loc *ReportLocale) []TimelineEntry {
+	var entries []TimelineEntry
+	for _, exec := range state.PhaseHistory {
+		entries = append(entries, TimelineEntry{exec.StartTime, loc.PhaseStarted, exec.PhaseName})
+		if exec.EndTime != nil {
+			entries = append(entries, TimelineEntry{*exec.EndTime, loc.PhaseEnded, exec.PhaseName})
+		}
+	}
+	for _, branch := range state.ActiveBranches {
+		details := branch.Condition
+		if branch.Description != "" {
+			details += ": " + branch.Description
+		}
+		entries = append(entries, TimelineEntry{branch.CreatedAt, loc.BranchOpened, details})
+		if branch.CompletedAt != nil {
+			entries = append(entries, TimelineEntry{*branch.CompletedAt, loc.BranchClosed, branch.Condition})
+		}
+	}
+	for _, f := range findings {
+		entries = append(entries, TimelineEntry{f.CreatedAt, loc.Finding, fmt.Sprintf("[%s] %s", loc.SeverityName(f.Severity), f.Title)})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries
+}
+
+// writePhaseTable writes a Markdown table of the phases the mission went
+// through, in order, with their times and completed steps
+func writePhaseTable(sb *strings.Builder, wf *Workflow, state *MissionState, loc *ReportLocale) {
+	if len(state.PhaseHistory) == 0 {
+		return
+	}
+
+	steps := make(map[string]int, len(wf.Phases))
+	for _, phase := range wf.Phases {
+		steps[phase.Name] = len(phase.Steps)
+	}
+
+	sb.WriteString("## " + loc.Phases + "\n\n")
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n|---|---|---|---|\n", loc.Phase, loc.Started, loc.Ended, loc.StepsCompleted))
+	for _, exec := range state.PhaseHistory {
+		ended := loc.InProgress
+		if exec.EndTime != nil {
+			ended = exec.EndTime.Format(loc.DateTimeLayout)
+		}
+		completed := fmt.Sprint(len(exec.StepsComplete))
+		if total := steps[exec.PhaseName]; total > 0 {
+			completed = fmt.Sprintf("%d/%d", len(exec.StepsComplete), total)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", markdownCell(exec.PhaseName),
+			exec.StartTime.Format(loc.DateTimeLayout), ended, completed))
+	}
+	sb.WriteString("\n")
+}
+
+// writeTimeline writes the mission timeline as a Markdown table. The
+// internal report gets the full journal; notes and tool calls are working
+// material the client report leaves out.
+func writeTimeline(sb *strings.Builder, state *MissionState, findings []Finding, audience ReportAudience, loc *ReportLocale) {
+	entries := Timeline(state, findings, loc)
+	if audience == ReportInternal {
+		entries = Journal(state, findings, loc)
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	sb.WriteString("## " + loc.Timeline + "\n\n")
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n|---|---|---|\n", loc.Time, loc.Event, loc.Details))
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", entry.Time.Format(loc.DateTimeLayout), entry.Event, markdownCell(entry.Details)))
+	}
+	sb.WriteString("\n")
+}
+
+// markdownCell keeps text on one line and its pipes from ending the cell
+func markdownCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/tools/profiles"
+)
+
+// ToolPolicy limits the tools a mission may use. Entries name a registered
+// tool (exec, web_fetch), a command run through exec (nmap), or a tool
+// profile as "profile:port-scan". A trailing * matches any suffix. Allow
+// lists the registered tools on offer; with an empty Allow list everything
+// not denied is. Deny also blocks commands run through exec, so a recon
+// phase can keep exec but rule out exploitation tools.
+type ToolPolicy struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow"`
+	Deny  []string `json:"deny,omitempty"  yaml:"deny"`
+}
+
+// IsEmpty reports whether the policy restricts nothing
+func (p ToolPolicy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Allows reports whether the policy permits the registered tool name
+func (p ToolPolicy) Allows(name string) bool {
+	if p.denies(name) {
+		return false
+	}
+	return len(p.Allow) == 0 ||
+		slices.ContainsFunc(p.Allow, func(entry string) bool { return matchToolEntry(entry, name) })
+}
+
+// AllowsCommand reports whether the policy permits running command through
+// exec. Only Deny applies, since shell pipelines lean on many small
+// utilities no allowlist would name.
+func (p ToolPolicy) AllowsCommand(command string) bool {
+	return !p.denies(command)
+}
+
+func (p ToolPolicy) denies(name string) bool {
+	return slices.ContainsFunc(p.Deny, func(entry string) bool { return matchToolEntry(entry, name) })
+}
+
+func matchToolEntry(entry, name string) bool {
+	entry = strings.TrimSpace(entry)
+	if profile, ok := strings.CutPrefix(entry, "profile:"); ok {
+		return slices.Contains(profiles.ToolsForProfile(strings.TrimSpace(profile)), name)
+	}
+	if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return entry == name
+}
+
+// isMissionTool reports whether name is one of the workflow_* tools, which
+// every phase keeps so the mission can always make progress, or one of the
+// scope_* tools, so every phase can check and request scope
+func isMissionTool(name string) bool {
+	return strings.HasPrefix(name, "workflow_") || strings.HasPrefix(name, "scope_")
+}
+
+// CheckTool returns an error when the workflow or the current phase does not
+// permit the registered tool name. Missions past their last phase only
+// apply the workflow-wide policy.
+func (e *Engine) CheckTool(name string) error {
+	if isMissionTool(name) {
+		return nil
+	}
+	return e.checkPolicy(name, ToolPolicy.Allows)
+}
+
+// CheckCommand returns an error when the workflow or the current phase
+// denies running command through exec
+func (e *Engine) CheckCommand(command string) error {
+	return e.checkPolicy(command, ToolPolicy.AllowsCommand)
+}
+
+func (e *Engine) checkPolicy(name string, allows func(ToolPolicy, string) bool) error {
+	if e.workflow == nil {
+		return nil
+	}
+	if !allows(e.workflow.Tools, name) {
+		return fmt.Errorf("%q is not allowed by workflow %s", name, e.workflow.Name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state.CurrentPhase < len(e.workflow.Phases) {
+		phase := e.workflow.Phases[e.state.CurrentPhase]
+		for _, policy := range phase.policies() {
+			if !allows(policy, name) {
+				return fmt.Errorf("%q is not allowed in phase %s", name, phase.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// toolPolicyPrompt describes the tool limits of phase for the mission context
+func (e *Engine) toolPolicyPrompt(phase Phase) string {
+	var allow, deny []string
+	for _, p := range append([]ToolPolicy{e.workflow.Tools}, phase.policies()...) {
+		allow = append(allow, p.Allow...)
+		deny = append(deny, p.Deny...)
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Tool Limits\n")
+	if len(allow) > 0 {
+		fmt.Fprintf(&sb, "- Only these tools are available: %s\n", strings.Join(allow, ", "))
+	}
+	if len(deny) > 0 {
+		fmt.Fprintf(&sb, "- Never use: %s\n", strings.Join(deny, ", "))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// parseToolPolicyLine adds a "- allow: a, b" or "- deny: c" line to policy
+func parseToolPolicyLine(line string, policy *ToolPolicy) error {
+	line = strings.TrimSpace(strings.TrimLeft(line, "-*"))
+	kind, list, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("invalid tools line %q (expected allow: or deny:)", line)
+	}
+
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.Trim(strings.TrimSpace(name), "`"); name != "" {
+			names = append(names, name)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "allow":
+		policy.Allow = append(policy.Allow, names...)
+	case "deny":
+		policy.Deny = append(policy.Deny, names...)
+	default:
+		return fmt.Errorf("invalid tools line %q (expected allow: or deny:)", line)
+	}
+	return nil
+}
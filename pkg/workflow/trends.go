@@ -0,0 +1,246 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrendInterval is the period Trends groups missions by
+type TrendInterval string
+
+const (
+	TrendMonthly TrendInterval = "month"
+	TrendWeekly  TrendInterval = "week"
+)
+
+// ParseTrendInterval validates an interval name
+func ParseTrendInterval(s string) (TrendInterval, error) {
+	switch interval := TrendInterval(strings.ToLower(strings.TrimSpace(s))); interval {
+	case TrendMonthly, TrendWeekly:
+		return interval, nil
+	}
+	return "", fmt.Errorf("invalid interval %q (want month or week)", s)
+}
+
+// period labels the period t falls in, e.g. "2026-10" or "2026-W42"
+func (i TrendInterval) period(t time.Time) string {
+	if i == TrendWeekly {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.Format("2006-01")
+}
+
+// Trends is what a set of missions, usually the archived ones, shows about
+// how engagements go: what they find, where the time goes, what the
+// findings cost and how often the supervisor rejects each worker model
+type Trends struct {
+	Missions  int
+	Findings  int
+	Cost      float64         // Model spend in USD
+	Periods   []PeriodTrend   // Oldest first
+	Phases    []PhaseTrend    // By workflow, phases in the order missions enter them
+	Workflows []WorkflowTrend // Most missions first
+	Models    []ModelTrend    // Most reviewed first
+}
+
+// CostPerFinding is the model spend per finding, 0 without findings
+func (t *Trends) CostPerFinding() float64 {
+	return costPerFinding(t.Cost, t.Findings)
+}
+
+// PeriodTrend covers the missions started in one period
+type PeriodTrend struct {
+	Period   string
+	Missions int
+	Findings map[Severity]int
+	Cost     float64
+}
+
+// FindingCount is the number of findings of any severity
+func (p PeriodTrend) FindingCount() int {
+	n := 0
+	for _, count := range p.Findings {
+		n += count
+	}
+	return n
+}
+
+// PhaseTrend covers one phase of a workflow across the missions that
+// entered it
+type PhaseTrend struct {
+	Workflow string
+	Phase    string
+	Missions int           // Missions that entered the phase
+	Elapsed  time.Duration // Mean wall-clock time in the phase, of missions that left it
+	Effort   time.Duration // Mean operator and agent time
+	Cost     float64       // Mean model spend
+}
+
+// WorkflowTrend covers the missions run with one workflow
+type WorkflowTrend struct {
+	Workflow string
+	Missions int
+	Findings int
+	Cost     float64
+}
+
+// CostPerFinding is the model spend per finding, 0 without findings
+func (w WorkflowTrend) CostPerFinding() float64 {
+	return costPerFinding(w.Cost, w.Findings)
+}
+
+// ModelTrend is the supervisor's verdicts on one worker model
+type ModelTrend struct {
+	Model string
+	ModelReviews
+}
+
+func costPerFinding(cost float64, findings int) float64 {
+	if findings == 0 {
+		return 0
+	}
+	return cost / float64(findings)
+}
+
+// phaseTotals adds up one phase across missions before averaging
+type phaseTotals struct {
+	trend    PhaseTrend
+	elapsed  time.Duration
+	left     int // Missions that left the phase
+	effort   time.Duration
+	cost     float64
+	position int // Latest position a mission entered the phase at
+}
+
+// AnalyzeTrends summarizes states. Missions count towards the period they
+// started in, findings and spend included.
+func AnalyzeTrends(states []*MissionState, interval TrendInterval) *Trends {
+	trends := &Trends{}
+	periods := make(map[string]*PeriodTrend)
+	workflows := make(map[string]*WorkflowTrend)
+	phases := make(map[string]*phaseTotals) // By workflow and phase
+	models := make(map[string]*ModelTrend)
+
+	for _, state := range states {
+		cost := TotalEffort(state.Effort()).Cost
+		trends.Missions++
+		trends.Findings += len(state.Findings)
+		trends.Cost += cost
+
+		label := interval.period(state.StartTime)
+		period, ok := periods[label]
+		if !ok {
+			period = &PeriodTrend{Period: label, Findings: make(map[Severity]int)}
+			periods[label] = period
+		}
+		period.Missions++
+		period.Cost += cost
+		for _, f := range state.Findings {
+			period.Findings[f.Severity]++
+		}
+
+		wf, ok := workflows[state.WorkflowName]
+		if !ok {
+			wf = &WorkflowTrend{Workflow: state.WorkflowName}
+			workflows[state.WorkflowName] = wf
+		}
+		wf.Missions++
+		wf.Findings += len(state.Findings)
+		wf.Cost += cost
+
+		addPhaseTrends(phases, state)
+
+		for name, r := range state.Reviews {
+			model, ok := models[name]
+			if !ok {
+				model = &ModelTrend{Model: name}
+				models[name] = model
+			}
+			model.Reviewed += r.Reviewed
+			model.Rejected += r.Rejected
+		}
+	}
+
+	for _, p := range periods {
+		trends.Periods = append(trends.Periods, *p)
+	}
+	sort.Slice(trends.Periods, func(i, j int) bool { return trends.Periods[i].Period < trends.Periods[j].Period })
+
+	for _, w := range workflows {
+		trends.Workflows = append(trends.Workflows, *w)
+	}
+	sort.Slice(trends.Workflows, func(i, j int) bool {
+		a, b := trends.Workflows[i], trends.Workflows[j]
+		if a.Missions != b.Missions {
+			return a.Missions > b.Missions
+		}
+		return a.Workflow < b.Workflow
+	})
+
+	totals := make([]*phaseTotals, 0, len(phases))
+	for _, p := range phases {
+		n := time.Duration(p.trend.Missions)
+		p.trend.Effort = p.effort / n
+		p.trend.Cost = p.cost / float64(p.trend.Missions)
+		if p.left > 0 {
+			p.trend.Elapsed = p.elapsed / time.Duration(p.left)
+		}
+		totals = append(totals, p)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.trend.Workflow != b.trend.Workflow {
+			return a.trend.Workflow < b.trend.Workflow
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		return a.trend.Phase < b.trend.Phase
+	})
+	for _, p := range totals {
+		trends.Phases = append(trends.Phases, p.trend)
+	}
+
+	for _, m := range models {
+		trends.Models = append(trends.Models, *m)
+	}
+	sort.Slice(trends.Models, func(i, j int) bool {
+		a, b := trends.Models[i], trends.Models[j]
+		if a.Reviewed != b.Reviewed {
+			return a.Reviewed > b.Reviewed
+		}
+		return a.Model < b.Model
+	})
+	return trends
+}
+
+// addPhaseTrends adds the time and spend of each phase state entered. A
+// phase entered more than once counts once, with its passes added up.
+func addPhaseTrends(phases map[string]*phaseTotals, state *MissionState) {
+	elapsed := make(map[string]time.Duration)
+	for _, exec := range state.PhaseHistory {
+		if exec.EndTime != nil {
+			elapsed[exec.PhaseName] += exec.EndTime.Sub(exec.StartTime)
+		}
+	}
+	for i, e := range state.Effort() {
+		key := state.WorkflowName + "\x00" + e.Phase
+		p, ok := phases[key]
+		if !ok {
+			p = &phaseTotals{trend: PhaseTrend{Workflow: state.WorkflowName, Phase: e.Phase}}
+			phases[key] = p
+		}
+		p.trend.Missions++
+		p.effort += e.Total()
+		p.cost += e.Cost
+		if d, ok := elapsed[e.Phase]; ok {
+			p.elapsed += d
+			p.left++
+		}
+		// Missions that skip conditional phases enter later ones earlier
+		p.position = max(p.position, i)
+	}
+}
@@ -1,37 +1,56 @@
 package workflow
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Workflow represents a multi-phase methodology
 type Workflow struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Phases      []Phase `json:"phases"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Phases      []Phase    `json:"phases"`
+	Tools       ToolPolicy `json:"tools,omitempty"` // Applies to every phase
 }
 
 // Phase represents a stage in the workflow
 type Phase struct {
-	Name       string              `json:"name"`
-	Steps      []Step              `json:"steps"`
-	Completion CompletionCriteria  `json:"completion"`
-	Branches   []Branch            `json:"branches,omitempty"`
+	Name       string             `json:"name"`
+	Steps      []Step             `json:"steps"`
+	Completion CompletionCriteria `json:"completion"`
+	Branches   []Branch           `json:"branches,omitempty"`
+	Hooks      []Hook             `json:"hooks,omitempty"`
+	Tools      ToolPolicy         `json:"tools,omitempty"` // Narrows the workflow's tool policy for this phase
+	When       string             `json:"when,omitempty"`  // Starlark expression; the phase is skipped unless true when the mission reaches it
+	Include    string             `json:"include,omitempty"` // Workflow whose phases run in place of this one, see ResolveIncludes
+
+	// Set on phases taken from an included workflow
+	Parent    string       `json:"parent,omitempty"`          // The including phase
+	Source    string       `json:"source,omitempty"`          // The included workflow
+	Inherited []ToolPolicy `json:"inherited_tools,omitempty"` // Tool policies of the included workflow and the including phase
 }
 
 // Step represents an action within a phase
 type Step struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required"`
-	Completed   bool   `json:"completed"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required"`
+	Completed   bool     `json:"completed"`
+	DependsOn   []string `json:"depends_on,omitempty"` // IDs of steps in the same phase that must be complete first
+	Tools       []string `json:"tools,omitempty"`      // Tools the step needs, checked against the fallback matrix when missing
 }
 
 // CompletionCriteria defines when a phase is considered complete
 type CompletionCriteria struct {
 	Type        CompletionType `json:"type"`
 	Description string         `json:"description"`
+	Script      string         `json:"script,omitempty"`
 	// For "all_required" type: phase completes when all required steps are done
 	// For "any_branch" type: phase completes when any branch is created
+	// For "script" type: phase completes when the Starlark expression in Script is true
 	// For "custom" type: use Description for manual evaluation
 }
 
@@ -41,6 +60,7 @@ type CompletionType string
 const (
 	CompletionAllRequired CompletionType = "all_required" // All required steps must be complete
 	CompletionAnyBranch   CompletionType = "any_branch"   // At least one branch must be created
+	CompletionScript      CompletionType = "script"       // Starlark expression over mission state
 	CompletionCustom      CompletionType = "custom"       // Custom criteria (evaluated manually)
 )
 
@@ -50,18 +70,50 @@ type Branch struct {
 	Description string `json:"description"` // Human-readable description
 	TargetPhase string `json:"target_phase,omitempty"` // Phase to jump to (optional)
 	Steps       []Step `json:"steps,omitempty"`        // Additional steps for this branch
+	When        string `json:"when,omitempty"`         // Starlark expression; the branch activates itself once true
+}
+
+// Hook is a Starlark script run when a mission event happens during a phase
+type Hook struct {
+	Event  string `json:"event"` // e.g., "on_finding", "on_step_complete"
+	Script string `json:"script"`
 }
 
 // MissionState tracks the current state of a workflow execution
 type MissionState struct {
 	WorkflowName  string                 `json:"workflow_name"`
+	Revision      int                    `json:"revision,omitempty"` // Incremented by every save; snapshots are kept for rollback
 	Target        string                 `json:"target"`
+	Targets       []string               `json:"targets,omitempty"` // Further targets, in scope like Target
 	StartTime     time.Time              `json:"start_time"`
 	CurrentPhase  int                    `json:"current_phase"`
 	PhaseHistory  []PhaseExecution       `json:"phase_history"`
 	ActiveBranches []ActiveBranch        `json:"active_branches"`
 	Findings      []Finding              `json:"findings"`
+	Aliases       map[string]string      `json:"aliases,omitempty"` // Human-readable name -> exact target
+	Scope         Scope                  `json:"scope"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"` // Set by the agent, hooks and /mission set; referenced as {{name}}
+	PhaseStack    []PhaseFrame           `json:"phase_stack,omitempty"` // Phases suspended by branch jumps, innermost last
+	Journal       []JournalEntry         `json:"journal,omitempty"`     // Notes, completed steps and tool calls, oldest first
+	SubWorkflows  []SubWorkflowExecution `json:"sub_workflows,omitempty"` // Included workflows the mission went through, oldest first
+	Reviews       map[string]ModelReviews `json:"reviews,omitempty"`      // Supervisor verdicts on worker output, by worker model
+}
+
+// SubWorkflowExecution tracks a pass through the phases an include phase
+// took from another workflow
+type SubWorkflowExecution struct {
+	Phase     string     `json:"phase"`    // The including phase
+	Workflow  string     `json:"workflow"` // The included workflow
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+}
+
+// PhaseFrame is a phase suspended while a branch investigates another phase
+type PhaseFrame struct {
+	Phase         int      `json:"phase"`                    // Index of the suspended phase
+	Branch        string   `json:"branch"`                   // Condition of the branch that jumped away
+	StepsComplete []string `json:"steps_complete,omitempty"` // Progress restored on return
 }
 
 // PhaseExecution tracks execution of a phase
@@ -71,6 +123,30 @@ type PhaseExecution struct {
 	EndTime      *time.Time        `json:"end_time,omitempty"`
 	StepsComplete []string          `json:"steps_complete"`
 	Notes        []string           `json:"notes,omitempty"`
+	OperatorTime time.Duration      `json:"operator_time,omitempty"` // Active operator time, see Engine.OperatorMessage
+	AgentTime    time.Duration      `json:"agent_time,omitempty"`    // Autonomous agent time, see Engine.AgentTurn
+	CostUSD      float64            `json:"cost_usd,omitempty"`      // Model spend, see Engine.RecordCost
+	Tokens       int                `json:"tokens,omitempty"`        // Model tokens, input and output
+}
+
+// JournalKind is what a journal entry records
+type JournalKind string
+
+const (
+	JournalNote JournalKind = "note" // Written by the agent, a hook or the operator
+	JournalStep JournalKind = "step" // A step was completed
+	JournalTool JournalKind = "tool" // A tool was called
+)
+
+// JournalEntry is a dated record of something done during the mission.
+// Phases, branches and findings carry their own times, so Journal merges
+// them with these entries rather than recording them twice.
+type JournalEntry struct {
+	Time   time.Time   `json:"time"`
+	Kind   JournalKind `json:"kind"`
+	Phase  string      `json:"phase,omitempty"`
+	Text   string      `json:"text"`
+	Failed bool        `json:"failed,omitempty"` // JournalTool: the call returned an error
 }
 
 // ActiveBranch tracks a branch that has been activated
@@ -80,6 +156,11 @@ type ActiveBranch struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Findings    []Finding  `json:"findings,omitempty"`
+	TargetPhase string     `json:"target_phase,omitempty"` // Phase the branch jumped to, if any
+	Session     string     `json:"session,omitempty"`      // Sub-agent session investigating the branch, if delegated
+	Summary     string     `json:"summary,omitempty"`      // What the sub-agent reported
+	CostUSD     float64    `json:"cost_usd,omitempty"`     // Sub-agent model spend
+	Tokens      int        `json:"tokens,omitempty"`       // Sub-agent model tokens
 }
 
 // Finding represents a discovery made during workflow execution
@@ -91,9 +172,54 @@ type Finding struct {
 	Phase       string                 `json:"phase"`
 	CreatedAt   time.Time              `json:"created_at"`
 	Evidence    string                 `json:"evidence,omitempty"`
+	Redaction   RedactionLevel         `json:"redaction,omitempty"` // How much of the finding the client report shows
+	CWE         string                 `json:"cwe,omitempty"`         // Weakness ID, e.g. "CWE-79"
+	CVSSScore   float64                `json:"cvss_score,omitempty"`  // CVSS base score, 0.0-10.0
+	CVSSVector  string                 `json:"cvss_vector,omitempty"` // e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	Asset       string                 `json:"asset,omitempty"`       // Affected host, URL, endpoint or file
+	Remediation string                 `json:"remediation,omitempty"`
+	References  []string               `json:"references,omitempty"` // Advisories, CVEs and vendor guidance
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// RedactionLevel controls what the client-facing report shows of a finding.
+// The internal report always shows everything.
+type RedactionLevel string
+
+const (
+	RedactionFull     RedactionLevel = "full"     // Shown in full (the default)
+	RedactionPartial  RedactionLevel = "partial"  // Shown without its evidence
+	RedactionInternal RedactionLevel = "internal" // Left out of the client report
+)
+
+// ParseRedactionLevel validates a redaction level; empty means full
+func ParseRedactionLevel(s string) (RedactionLevel, error) {
+	switch level := RedactionLevel(strings.ToLower(strings.TrimSpace(s))); level {
+	case "", RedactionFull:
+		return RedactionFull, nil
+	case RedactionPartial, RedactionInternal:
+		return level, nil
+	case "internal-only", "internal_only":
+		return RedactionInternal, nil
+	default:
+		return "", fmt.Errorf("invalid redaction level %q (want full, partial or internal)", s)
+	}
+}
+
+// NormalizeCWE turns "79", "cwe-79" or "CWE-79" into "CWE-79". Empty stays
+// empty.
+func NormalizeCWE(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	id := strings.TrimPrefix(strings.ToUpper(s), "CWE-")
+	if n, err := strconv.Atoi(id); err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid CWE %q (want e.g. CWE-79)", s)
+	}
+	return "CWE-" + id, nil
+}
+
 // Severity levels for findings
 type Severity string
 
@@ -104,3 +230,114 @@ const (
 	SeverityLow           Severity = "low"
 	SeverityInformational Severity = "informational"
 )
+
+// clone returns a deep copy of the state
+func (s *MissionState) clone() *MissionState {
+	if s == nil {
+		return nil
+	}
+	c := *s
+
+	c.PhaseHistory = make([]PhaseExecution, len(s.PhaseHistory))
+	for i, exec := range s.PhaseHistory {
+		exec.EndTime = cloneTime(exec.EndTime)
+		exec.StepsComplete = append([]string(nil), exec.StepsComplete...)
+		exec.Notes = append([]string(nil), exec.Notes...)
+		c.PhaseHistory[i] = exec
+	}
+
+	c.ActiveBranches = make([]ActiveBranch, len(s.ActiveBranches))
+	for i, branch := range s.ActiveBranches {
+		branch.CompletedAt = cloneTime(branch.CompletedAt)
+		branch.Findings = cloneFindings(branch.Findings)
+		c.ActiveBranches[i] = branch
+	}
+
+	c.Findings = cloneFindings(s.Findings)
+
+	if s.PhaseStack != nil {
+		c.PhaseStack = make([]PhaseFrame, len(s.PhaseStack))
+		for i, frame := range s.PhaseStack {
+			frame.StepsComplete = append([]string(nil), frame.StepsComplete...)
+			c.PhaseStack[i] = frame
+		}
+	}
+
+	if s.Aliases != nil {
+		c.Aliases = make(map[string]string, len(s.Aliases))
+		for k, v := range s.Aliases {
+			c.Aliases[k] = v
+		}
+	}
+	c.Targets = append([]string(nil), s.Targets...)
+	c.Journal = append([]JournalEntry(nil), s.Journal...)
+	if s.SubWorkflows != nil {
+		c.SubWorkflows = make([]SubWorkflowExecution, len(s.SubWorkflows))
+		for i, sub := range s.SubWorkflows {
+			sub.EndTime = cloneTime(sub.EndTime)
+			c.SubWorkflows[i] = sub
+		}
+	}
+	if s.Reviews != nil {
+		c.Reviews = make(map[string]ModelReviews, len(s.Reviews))
+		for model, reviews := range s.Reviews {
+			c.Reviews[model] = reviews
+		}
+	}
+	c.Scope = Scope{
+		Include: append([]string(nil), s.Scope.Include...),
+		Exclude: append([]string(nil), s.Scope.Exclude...),
+		History: append([]ScopeChange(nil), s.Scope.History...),
+	}
+	c.Metadata = cloneMetadata(s.Metadata)
+	c.Variables = cloneMetadata(s.Variables)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func cloneFindings(findings []Finding) []Finding {
+	if findings == nil {
+		return nil
+	}
+	c := make([]Finding, len(findings))
+	for i, f := range findings {
+		f.References = append([]string(nil), f.References...)
+		f.Metadata = cloneMetadata(f.Metadata)
+		c[i] = f
+	}
+	return c
+}
+
+// cloneMetadata deep-copies JSON-shaped metadata
+func cloneMetadata(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = cloneValue(v)
+	}
+	return c
+}
+
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return cloneMetadata(v)
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = cloneValue(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IssueLevel is how serious a workflow problem is
+type IssueLevel string
+
+const (
+	LevelError   IssueLevel = "error"   // The workflow can't run as written
+	LevelWarning IssueLevel = "warning" // The workflow runs, but probably not as intended
+)
+
+// ValidationIssue is one problem Validate found in a workflow
+type ValidationIssue struct {
+	Level   IssueLevel `json:"level"`
+	Check   string     `json:"check"` // e.g. duplicate_step_id, step_dependency_cycle, unknown_target_phase, no_completion, empty_phase
+	Phase   string     `json:"phase,omitempty"`
+	Message string     `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Phase == "" {
+		return fmt.Sprintf("%s: %s", i.Level, i.Message)
+	}
+	return fmt.Sprintf("%s: phase %s: %s", i.Level, i.Phase, i.Message)
+}
+
+// ValidationError lists every problem found in a workflow
+type ValidationError struct {
+	Workflow string
+	Issues   []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.String()
+	}
+	return fmt.Sprintf("workflow %s: %s", e.Workflow, strings.Join(parts, "; "))
+}
+
+// HasErrors reports whether any issue is an error rather than a warning
+func (e *ValidationError) HasErrors() bool {
+	for _, issue := range e.Issues {
+		if issue.Level == LevelError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a workflow for duplicate phases and step IDs, step
+// dependencies that are missing or cyclic, branches that jump to phases
+// that don't exist, phases with no completion criteria or nothing to do,
+// include phases with content of their own, and scripts that don't parse. It returns nil or a *ValidationError
+// listing every issue, warnings included.
+func Validate(wf *Workflow) error {
+	var issues []ValidationIssue
+	report := func(level IssueLevel, check, phase, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Level: level, Check: check, Phase: phase, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(wf.Name) == "" {
+		report(LevelError, "missing_name", "", "no name in the frontmatter")
+	}
+	if len(wf.Phases) == 0 {
+		report(LevelError, "no_phases", "", "no phases; add a \"## Phase: <name>\" section")
+	}
+
+	phases := make(map[string]bool, len(wf.Phases))
+	for _, phase := range wf.Phases {
+		if phase.Name == "" {
+			report(LevelError, "missing_name", "", "a phase has no name")
+		} else if phases[phase.Name] {
+			report(LevelError, "duplicate_phase", phase.Name, "defined more than once")
+		}
+		phases[phase.Name] = true
+	}
+
+	for i, phase := range wf.Phases {
+		if i == 0 && phase.When != "" {
+			report(LevelWarning, "conditional_first_phase", phase.Name, "the first phase always runs, so its when condition is ignored")
+		}
+		if phase.Include != "" {
+			// The included workflow's phases replace it, and are checked
+			// once it is resolved
+			if len(phase.Steps) > 0 || len(phase.Branches) > 0 || len(phase.Hooks) > 0 || phase.Completion.Type != "" || phase.Completion.Description != "" {
+				report(LevelError, "include_with_content", phase.Name, "includes workflow %s, so it can't have steps, branches, hooks or completion criteria of its own", phase.Include)
+			}
+			continue
+		}
+
+		steps := make(map[string]bool, len(phase.Steps))
+		for _, step := range phase.Steps {
+			switch {
+			case step.ID == "":
+				report(LevelError, "missing_step_id", phase.Name, "step %q has no ID", step.Name)
+			case steps[step.ID]:
+				report(LevelError, "duplicate_step_id", phase.Name, "step ID %q is used more than once", step.ID)
+			}
+			steps[step.ID] = true
+		}
+		issues = append(issues, stepDependencyIssues(phase)...)
+
+		for _, branch := range phase.Branches {
+			if branch.TargetPhase != "" && !phases[branch.TargetPhase] {
+				report(LevelError, "unknown_target_phase", phase.Name, "branch %s jumps to phase %q, which does not exist", branch.Condition, branch.TargetPhase)
+			}
+		}
+
+		if phase.Completion.Type == "" && phase.Completion.Description == "" {
+			report(LevelWarning, "no_completion", phase.Name, "no completion criteria; the phase only ends when advanced by hand")
+		}
+		if len(phase.Steps) == 0 && len(phase.Branches) == 0 {
+			report(LevelWarning, "empty_phase", phase.Name, "no steps or branches")
+		}
+		if phase.Completion.Type == CompletionAllRequired && !hasRequiredStep(phase) {
+			report(LevelWarning, "no_required_steps", phase.Name, "completion needs all required steps, but no step is required")
+		}
+
+		issues = append(issues, scriptIssues(phase)...)
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Workflow: wf.Name, Issues: issues}
+}
+
+func hasRequiredStep(phase Phase) bool {
+	for _, step := range phase.Steps {
+		if step.Required {
+			return true
+		}
+	}
+	return false
+}
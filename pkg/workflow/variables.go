@@ -0,0 +1,190 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/picoclaw/pkg/logger"
+)
+
+// variableName matches a mission variable name: an identifier, optionally
+// followed by dotted parts such as scope.cidr
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_-]+)*$`)
+
+// templateRef matches a {{name}} reference in workflow text
+var templateRef = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+
+// checkVariableName rejects names that can't be referenced as {{name}} and
+// the names the mission sets itself
+func checkVariableName(name string) error {
+	if !variableName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	switch name {
+	case "target", "workflow", "phase":
+		return fmt.Errorf("%s is set by the mission itself", name)
+	}
+	return nil
+}
+
+// SetVariable sets a mission variable, such as open_ports, that tool
+// arguments and workflow text reference as {{name}} and scripts read from
+// vars. Branch and phase conditions that depend on it see the new value.
+func (e *Engine) SetVariable(name string, value interface{}) error {
+	if err := checkVariableName(name); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.setVariable(name, value)
+	e.activateScriptedBranches()
+	e.followPendingJump()
+	return e.saveState()
+}
+
+func (e *Engine) setVariable(name string, value interface{}) {
+	if e.state.Variables == nil {
+		e.state.Variables = make(map[string]interface{})
+	}
+	e.state.Variables[name] = value
+
+	logger.InfoCF(e.component, "Variable set", map[string]any{"name": name})
+	e.publish(Event{Type: EventVariableSet, Phase: e.currentPhaseName(), Key: name})
+}
+
+// TemplateVars returns the values {{name}} references resolve to: target,
+// workflow and phase, every metadata key and variable (nested maps
+// flattened to dotted names such as scope.cidr, lists joined with commas)
+// and alias.NAME for each target alias. Variables win over metadata keys of
+// the same name.
+func (e *Engine) TemplateVars() map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.templateVars()
+}
+
+func (e *Engine) templateVars() map[string]string {
+	vars := make(map[string]string)
+	for key, value := range e.state.Metadata {
+		flattenValue(vars, key, value)
+	}
+	for name, value := range e.state.Variables {
+		flattenValue(vars, name, value)
+	}
+	for alias, target := range e.state.Aliases {
+		vars["alias."+strings.ReplaceAll(alias, " ", "_")] = target
+	}
+	if e.state.Target != "" {
+		vars["target"] = e.state.Target
+	}
+	if e.workflow != nil {
+		vars["workflow"] = e.workflow.Name
+	}
+	if phase := e.currentPhaseName(); phase != "" {
+		vars["phase"] = phase
+	}
+	return vars
+}
+
+// flattenValue stores value under name, with nested maps stored under
+// name.key and lists joined with commas
+func flattenValue(vars map[string]string, name string, value any) {
+	switch v := value.(type) {
+	case nil:
+	case map[string]any:
+		for key, nested := range v {
+			flattenValue(vars, name+"."+key, nested)
+		}
+	case map[string]string:
+		for key, nested := range v {
+			vars[name+"."+key] = nested
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		vars[name] = strings.Join(items, ",")
+	case []string:
+		vars[name] = strings.Join(v, ",")
+	default:
+		if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+			vars[name] = s
+		}
+	}
+}
+
+// expandTemplate fills {{name}} references in workflow text with the
+// mission's values. Unknown names are left as written.
+func expandTemplate(s string, vars map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return templateRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := vars[templateRef.FindStringSubmatch(ref)[1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// nextPhase returns the index of the first phase after the current one
+// whose when condition holds, or -1, and the conditional phases passed over
+// on the way. Conditions see the mission as the current phase ends; one
+// that fails to evaluate is logged and counts as false.
+func (e *Engine) nextPhase() (int, []string) {
+	var skipped []string
+	for i := e.state.CurrentPhase + 1; i < len(e.workflow.Phases); i++ {
+		phase := e.workflow.Phases[i]
+		if phase.When == "" {
+			return i, skipped
+		}
+		ok, err := e.evalCondition(phase.When)
+		if err != nil {
+			logger.WarnCF(e.component, "Phase condition failed", map[string]any{
+				"phase": phase.Name,
+				"error": err.Error(),
+			})
+		}
+		if ok {
+			return i, skipped
+		}
+		skipped = append(skipped, phase.Name)
+	}
+	return -1, skipped
+}
+
+// conditionalPhasePrompt lists the later phases that only run if their
+// condition holds, so the agent knows which variables decide them
+func (e *Engine) conditionalPhasePrompt() string {
+	var lines []string
+	for i := e.state.CurrentPhase + 1; i < len(e.workflow.Phases); i++ {
+		if phase := e.workflow.Phases[i]; phase.When != "" {
+			lines = append(lines, fmt.Sprintf("- **%s**: `%s`\n", phase.Name, phase.When))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Conditional Phases\n")
+	sb.WriteString("These phases are skipped unless their condition holds when the mission reaches them. Record what you discover with workflow_set_variable.\n")
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+	if len(e.state.Variables) > 0 {
+		names := make([]string, 0, len(e.state.Variables))
+		for name := range e.state.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("Variables set so far: %s\n", strings.Join(names, ", ")))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}